@@ -0,0 +1,337 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"threat-intel-backend/domain"
+	"threat-intel-backend/infrastructure/jwt"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidResetToken        = errors.New("invalid or expired password reset token")
+	ErrInvalidVerificationToken = errors.New("invalid or expired email verification token")
+	ErrEmailNotVerified         = errors.New("email address is not verified")
+	ErrWrongPassword            = errors.New("current password is incorrect")
+	ErrNoPassword               = errors.New("account has no password; it signs in with single sign-on")
+)
+
+// AccountService lets people register and verify their email, recover a
+// forgotten password, and manage their own profile and password. The
+// registration and recovery flows send a link with a signed token; the token
+// ID is also kept in an AccountTokenStore so that only the latest link of a
+// user works, and only once.
+type AccountService struct {
+	userRepo   domain.UserRepository
+	jwtService *jwt.Service
+	tokens     domain.AccountTokenStore
+	mailer     domain.Mailer
+	sessions   SessionRevoker
+	loginGuard *LoginGuard
+	passwords  PasswordPolicy
+	appURL     string
+}
+
+// RegisterRequest is used for public self-service sign-up, which always
+// creates viewers. Elevated roles are granted through invitations.
+type RegisterRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+type RegisterResponse struct {
+	User    *UserResponse `json:"user"`
+	Message string        `json:"message"`
+}
+
+// EmailRequest names the account a reset or verification link is sent to.
+type EmailRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// UpdateProfileRequest changes the fields that are set.
+type UpdateProfileRequest struct {
+	Name *string `json:"name" binding:"omitempty,max=100"`
+}
+
+// ChangePasswordRequest carries, set by the handler, the client IP that
+// wrong current passwords are counted against like failed logins.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+	ClientIP        string `json:"-"`
+}
+
+// NewAccountService returns a service whose emails link to pages of the web
+// application at appURL, which post the token back to the API.
+func NewAccountService(userRepo domain.UserRepository, jwtService *jwt.Service, tokens domain.AccountTokenStore, mailer domain.Mailer, sessions SessionRevoker, loginGuard *LoginGuard, passwords PasswordPolicy, appURL string) *AccountService {
+	return &AccountService{
+		userRepo:   userRepo,
+		jwtService: jwtService,
+		tokens:     tokens,
+		mailer:     mailer,
+		sessions:   sessions,
+		loginGuard: loginGuard,
+		passwords:  passwords,
+		appURL:     strings.TrimSuffix(appURL, "/"),
+	}
+}
+
+// Register creates a viewer who stays inactive until they follow the link
+// of the verification email.
+func (s *AccountService) Register(req RegisterRequest) (*RegisterResponse, error) {
+	existingUser, _ := s.userRepo.FindByEmail(req.Email)
+	if existingUser != nil {
+		return nil, ErrEmailExists
+	}
+
+	if err := s.passwords.Check(req.Email, req.Password); err != nil {
+		return nil, err
+	}
+
+	user, err := domain.NewPendingUser(req.Email, req.Password, domain.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.Save(user); err != nil {
+		return nil, err
+	}
+
+	if err := s.sendVerification(user); err != nil {
+		return nil, err
+	}
+
+	return &RegisterResponse{
+		User:    NewUserResponse(user),
+		Message: "Check your email to verify your account",
+	}, nil
+}
+
+// ResendVerification sends a new verification link, voiding the previous
+// one. Like ForgotPassword it does not tell whether the account exists.
+func (s *AccountService) ResendVerification(req EmailRequest) error {
+	user, err := s.userRepo.FindByEmail(req.Email)
+	if err != nil || !user.AwaitingVerification() {
+		return nil
+	}
+	return s.sendVerification(user)
+}
+
+// VerifyEmail confirms the email address of the token and activates the
+// user. The user then logs in as usual, so MFA policies still apply.
+func (s *AccountService) VerifyEmail(req VerifyEmailRequest) (*UserResponse, error) {
+	claims, err := s.jwtService.ValidateEmailVerificationToken(req.Token)
+	if err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	user, err := s.consume(jwt.TokenTypeEmailVerification, claims)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	user.VerifyEmail()
+	if err := s.userRepo.Save(user); err != nil {
+		return nil, err
+	}
+	return NewUserResponse(user), nil
+}
+
+// ForgotPassword emails a password reset link to an active user with a
+// password. It succeeds whether or not such a user exists, so that it
+// cannot be used to find out which emails have accounts.
+func (s *AccountService) ForgotPassword(req EmailRequest) error {
+	user, err := s.userRepo.FindByEmail(req.Email)
+	if err != nil || !user.IsActive || user.PasswordHash == "" {
+		return nil
+	}
+
+	token, claims, err := s.jwtService.GeneratePasswordResetToken(user.ID, user.Email)
+	if err != nil {
+		return err
+	}
+	if err := s.tokens.Save(jwt.TokenTypePasswordReset, user.ID, claims.ID, time.Until(claims.ExpiresAt.Time)); err != nil {
+		return err
+	}
+
+	return s.mailer.Send(&domain.Email{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Someone asked to reset the password of your Zentara Threat Intelligence account.\n\n"+
+			"Choose a new password within %s:\n\n%s\n\n"+
+			"If it was not you, ignore this email; your password has not been changed.",
+			validFor(claims), s.link("/reset-password", token)),
+	})
+}
+
+// ResetPassword sets a new password with a reset token, ends every session
+// of the user and lifts a lockout of their account.
+func (s *AccountService) ResetPassword(req ResetPasswordRequest) error {
+	claims, err := s.jwtService.ValidatePasswordResetToken(req.Token)
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+
+	// Checked before the token is used up, so that it can be retried with
+	// a better password.
+	if err := s.passwords.Check(claims.Email, req.NewPassword); err != nil {
+		return err
+	}
+
+	user, err := s.consume(jwt.TokenTypePasswordReset, claims)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrInvalidResetToken
+	}
+	if !user.IsActive {
+		return ErrAccountInactive
+	}
+
+	if err := user.SetPassword(req.NewPassword); err != nil {
+		return err
+	}
+	if err := s.userRepo.Save(user); err != nil {
+		return err
+	}
+
+	if err := s.sessions.LogoutAll(user.ID); err != nil {
+		return err
+	}
+	return s.loginGuard.Succeed(user.Email)
+}
+
+// Profile returns the user themselves.
+func (s *AccountService) Profile(userID uuid.UUID) (*UserResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	return NewUserResponse(user), nil
+}
+
+func (s *AccountService) UpdateProfile(userID uuid.UUID, req UpdateProfileRequest) (*UserResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	if req.Name != nil {
+		user.Rename(strings.TrimSpace(*req.Name))
+	}
+
+	if err := s.userRepo.Save(user); err != nil {
+		return nil, err
+	}
+	return NewUserResponse(user), nil
+}
+
+// ChangePassword replaces the password of a user who knows the current one,
+// and ends every session of the user, this one included. Wrong current
+// passwords count as failed logins, so that a stolen session cannot be used
+// to guess the password.
+func (s *AccountService) ChangePassword(userID uuid.UUID, req ChangePasswordRequest) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if user.PasswordHash == "" {
+		return ErrNoPassword
+	}
+
+	if err := s.loginGuard.Check(user.Email, req.ClientIP); err != nil {
+		return err
+	}
+	if !user.ValidatePassword(req.CurrentPassword) {
+		if err := s.loginGuard.Fail(user, user.Email, req.ClientIP); err != nil {
+			return err
+		}
+		return ErrWrongPassword
+	}
+
+	if err := s.passwords.Check(user.Email, req.NewPassword); err != nil {
+		return err
+	}
+	if err := user.SetPassword(req.NewPassword); err != nil {
+		return err
+	}
+	if err := s.userRepo.Save(user); err != nil {
+		return err
+	}
+
+	if err := s.sessions.LogoutAll(user.ID); err != nil {
+		return err
+	}
+	return s.loginGuard.Succeed(user.Email)
+}
+
+func (s *AccountService) sendVerification(user *domain.User) error {
+	token, claims, err := s.jwtService.GenerateEmailVerificationToken(user.ID, user.Email)
+	if err != nil {
+		return err
+	}
+	if err := s.tokens.Save(jwt.TokenTypeEmailVerification, user.ID, claims.ID, time.Until(claims.ExpiresAt.Time)); err != nil {
+		return err
+	}
+
+	return s.mailer.Send(&domain.Email{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Welcome to Zentara Threat Intelligence.\n\n"+
+			"Verify your email address within %s to activate your account:\n\n%s\n\n"+
+			"If you did not create an account, ignore this email.",
+			validFor(claims), s.link("/verify-email", token)),
+	})
+}
+
+// consume redeems the token of claims and returns its user, or nil when the
+// token is not the outstanding one or no longer names the user's email.
+func (s *AccountService) consume(purpose string, claims *jwt.AccountClaims) (*domain.User, error) {
+	userID, err := claims.UserID()
+	if err != nil {
+		return nil, nil
+	}
+
+	ok, err := s.tokens.Consume(purpose, userID, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil || user.Email != claims.Email {
+		return nil, nil
+	}
+	return user, nil
+}
+
+func (s *AccountService) link(path, token string) string {
+	return s.appURL + path + "?token=" + url.QueryEscape(token)
+}
+
+func validFor(claims *jwt.AccountClaims) string {
+	hours := int(claims.ExpiresAt.Sub(claims.IssuedAt.Time).Round(time.Hour).Hours())
+	if hours == 1 {
+		return "1 hour"
+	}
+	return fmt.Sprintf("%d hours", hours)
+}
@@ -0,0 +1,414 @@
+package application
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"testing"
+	"threat-intel-backend/domain"
+	"threat-intel-backend/infrastructure/jwt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAccountTokenStore struct {
+	mock.Mock
+}
+
+func (m *MockAccountTokenStore) Save(purpose string, userID uuid.UUID, tokenID string, ttl time.Duration) error {
+	args := m.Called(purpose, userID, tokenID, ttl)
+	return args.Error(0)
+}
+
+func (m *MockAccountTokenStore) Consume(purpose string, userID uuid.UUID, tokenID string) (bool, error) {
+	args := m.Called(purpose, userID, tokenID)
+	return args.Bool(0), args.Error(1)
+}
+
+// recordingMailer keeps the emails it was asked to send.
+type recordingMailer struct {
+	sent []*domain.Email
+	err  error
+}
+
+func (m *recordingMailer) Send(email *domain.Email) error {
+	m.sent = append(m.sent, email)
+	return m.err
+}
+
+// linkToken extracts the token of the link in the last email sent.
+func (m *recordingMailer) linkToken(t *testing.T) string {
+	if !assert.NotEmpty(t, m.sent) {
+		return ""
+	}
+	link := regexp.MustCompile(`https://app\.example\.com/\S+`).FindString(m.sent[len(m.sent)-1].Body)
+	parsed, err := url.Parse(link)
+	assert.NoError(t, err)
+	return parsed.Query().Get("token")
+}
+
+func setupAccountService() (*AccountService, *MockUserRepository, *MockAccountTokenStore, *recordingMailer, *MockSessionRevoker, *jwt.Service) {
+	mockRepo := new(MockUserRepository)
+	mockTokens := new(MockAccountTokenStore)
+	mailer := &recordingMailer{}
+	mockSessions := new(MockSessionRevoker)
+	jwtService := jwt.NewService("test-secret")
+
+	service := NewAccountService(mockRepo, jwtService, mockTokens, mailer, mockSessions, permissiveLoginGuard(), DefaultPasswordPolicy, "https://app.example.com/")
+	return service, mockRepo, mockTokens, mailer, mockSessions, jwtService
+}
+
+func TestAccountService_Register(t *testing.T) {
+	t.Run("creates an inactive user and sends a verification link", func(t *testing.T) {
+		service, mockRepo, mockTokens, mailer, _, jwtService := setupAccountService()
+		mockRepo.On("FindByEmail", "new@example.com").Return(nil, errors.New("not found")).Once()
+		mockRepo.On("Save", mock.MatchedBy(func(user *domain.User) bool {
+			return !user.IsActive && user.Role == domain.RoleViewer
+		})).Return(nil).Once()
+		mockTokens.On("Save", jwt.TokenTypeEmailVerification, mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+		resp, err := service.Register(RegisterRequest{Email: "new@example.com", Password: "password123"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "new@example.com", resp.User.Email)
+		assert.False(t, resp.User.IsActive)
+		assert.Nil(t, resp.User.EmailVerifiedAt)
+		assert.Len(t, mailer.sent, 1)
+		assert.Equal(t, "new@example.com", mailer.sent[0].To)
+		assert.Contains(t, mailer.sent[0].Body, "https://app.example.com/verify-email?token=")
+		assert.Contains(t, mailer.sent[0].Body, "24 hours")
+
+		claims, err := jwtService.ValidateEmailVerificationToken(mailer.linkToken(t))
+		assert.NoError(t, err)
+		assert.Equal(t, "new@example.com", claims.Email)
+		mockTokens.AssertCalled(t, "Save", jwt.TokenTypeEmailVerification, resp.User.ID, claims.ID, mock.Anything)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("email already exists", func(t *testing.T) {
+		service, mockRepo, _, mailer, _, _ := setupAccountService()
+		existingUser, _ := domain.NewUser("existing@example.com", "password123", domain.RoleViewer)
+		mockRepo.On("FindByEmail", "existing@example.com").Return(existingUser, nil).Once()
+
+		resp, err := service.Register(RegisterRequest{Email: "existing@example.com", Password: "password123"})
+
+		assert.Equal(t, ErrEmailExists, err)
+		assert.Nil(t, resp)
+		assert.Empty(t, mailer.sent)
+	})
+
+	t.Run("weak password", func(t *testing.T) {
+		service, mockRepo, _, mailer, _, _ := setupAccountService()
+		mockRepo.On("FindByEmail", "new@example.com").Return(nil, errors.New("not found")).Once()
+
+		resp, err := service.Register(RegisterRequest{Email: "new@example.com", Password: "new@example.com"})
+
+		assert.ErrorIs(t, err, ErrWeakPassword)
+		assert.Nil(t, resp)
+		assert.Empty(t, mailer.sent)
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+
+	t.Run("save user fails", func(t *testing.T) {
+		service, mockRepo, _, _, _, _ := setupAccountService()
+		mockRepo.On("FindByEmail", "new@example.com").Return(nil, errors.New("not found")).Once()
+		mockRepo.On("Save", mock.AnythingOfType("*domain.User")).Return(errors.New("save failed")).Once()
+
+		resp, err := service.Register(RegisterRequest{Email: "new@example.com", Password: "password123"})
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+		assert.Equal(t, "save failed", err.Error())
+	})
+}
+
+func TestAccountService_VerifyEmail(t *testing.T) {
+	pending := func() *domain.User {
+		user, _ := domain.NewPendingUser("new@example.com", "password123", domain.RoleViewer)
+		return user
+	}
+
+	t.Run("activates the user", func(t *testing.T) {
+		service, mockRepo, mockTokens, _, _, jwtService := setupAccountService()
+		user := pending()
+		token, claims, _ := jwtService.GenerateEmailVerificationToken(user.ID, user.Email)
+		mockTokens.On("Consume", jwt.TokenTypeEmailVerification, user.ID, claims.ID).Return(true, nil).Once()
+		mockRepo.On("FindByID", user.ID).Return(user, nil).Once()
+		mockRepo.On("Save", user).Return(nil).Once()
+
+		verified, err := service.VerifyEmail(VerifyEmailRequest{Token: token})
+
+		assert.NoError(t, err)
+		assert.True(t, verified.IsActive)
+		assert.NotNil(t, verified.EmailVerifiedAt)
+	})
+
+	t.Run("rejects a used or replaced token", func(t *testing.T) {
+		service, _, mockTokens, _, _, jwtService := setupAccountService()
+		user := pending()
+		token, claims, _ := jwtService.GenerateEmailVerificationToken(user.ID, user.Email)
+		mockTokens.On("Consume", jwt.TokenTypeEmailVerification, user.ID, claims.ID).Return(false, nil).Once()
+
+		_, err := service.VerifyEmail(VerifyEmailRequest{Token: token})
+
+		assert.Equal(t, ErrInvalidVerificationToken, err)
+	})
+
+	t.Run("rejects a token for a previous email", func(t *testing.T) {
+		service, mockRepo, mockTokens, _, _, jwtService := setupAccountService()
+		user := pending()
+		token, claims, _ := jwtService.GenerateEmailVerificationToken(user.ID, "old@example.com")
+		mockTokens.On("Consume", jwt.TokenTypeEmailVerification, user.ID, claims.ID).Return(true, nil).Once()
+		mockRepo.On("FindByID", user.ID).Return(user, nil).Once()
+
+		_, err := service.VerifyEmail(VerifyEmailRequest{Token: token})
+
+		assert.Equal(t, ErrInvalidVerificationToken, err)
+		assert.False(t, user.IsActive)
+	})
+
+	t.Run("rejects a reset token", func(t *testing.T) {
+		service, _, _, _, _, jwtService := setupAccountService()
+		token, _, _ := jwtService.GeneratePasswordResetToken(uuid.New(), "new@example.com")
+
+		_, err := service.VerifyEmail(VerifyEmailRequest{Token: token})
+
+		assert.Equal(t, ErrInvalidVerificationToken, err)
+	})
+
+	t.Run("does not reactivate a deactivated user", func(t *testing.T) {
+		service, mockRepo, mockTokens, mailer, _, jwtService := setupAccountService()
+		user, _ := domain.NewUser("invited@example.com", "password123", domain.RoleAnalyst)
+		user.Deactivate()
+		mockRepo.On("FindByEmail", user.Email).Return(user, nil).Once()
+
+		assert.NoError(t, service.ResendVerification(EmailRequest{Email: user.Email}))
+		assert.Empty(t, mailer.sent)
+
+		token, claims, _ := jwtService.GenerateEmailVerificationToken(user.ID, user.Email)
+		mockTokens.On("Consume", jwt.TokenTypeEmailVerification, user.ID, claims.ID).Return(true, nil).Once()
+		mockRepo.On("FindByID", user.ID).Return(user, nil).Once()
+		mockRepo.On("Save", user).Return(nil).Maybe()
+
+		verified, err := service.VerifyEmail(VerifyEmailRequest{Token: token})
+
+		assert.NoError(t, err)
+		assert.False(t, verified.IsActive)
+		assert.False(t, user.IsActive)
+	})
+}
+
+func TestAccountService_ResendVerification(t *testing.T) {
+	t.Run("resends to a pending user", func(t *testing.T) {
+		service, mockRepo, mockTokens, mailer, _, _ := setupAccountService()
+		user, _ := domain.NewPendingUser("new@example.com", "password123", domain.RoleViewer)
+		mockRepo.On("FindByEmail", "new@example.com").Return(user, nil).Once()
+		mockTokens.On("Save", jwt.TokenTypeEmailVerification, user.ID, mock.Anything, mock.Anything).Return(nil).Once()
+
+		assert.NoError(t, service.ResendVerification(EmailRequest{Email: "new@example.com"}))
+		assert.Len(t, mailer.sent, 1)
+	})
+
+	t.Run("ignores active and unknown users", func(t *testing.T) {
+		service, mockRepo, _, mailer, _, _ := setupAccountService()
+		user, _ := domain.NewUser("user@example.com", "password123", domain.RoleViewer)
+		mockRepo.On("FindByEmail", "user@example.com").Return(user, nil).Once()
+		mockRepo.On("FindByEmail", "nobody@example.com").Return(nil, errors.New("not found")).Once()
+
+		assert.NoError(t, service.ResendVerification(EmailRequest{Email: "user@example.com"}))
+		assert.NoError(t, service.ResendVerification(EmailRequest{Email: "nobody@example.com"}))
+		assert.Empty(t, mailer.sent)
+	})
+}
+
+func TestAccountService_ForgotPassword(t *testing.T) {
+	t.Run("sends a reset link", func(t *testing.T) {
+		service, mockRepo, mockTokens, mailer, _, jwtService := setupAccountService()
+		user, _ := domain.NewUser("user@example.com", "password123", domain.RoleViewer)
+		mockRepo.On("FindByEmail", "user@example.com").Return(user, nil).Once()
+		mockTokens.On("Save", jwt.TokenTypePasswordReset, user.ID, mock.Anything, mock.MatchedBy(func(ttl time.Duration) bool {
+			return ttl > 59*time.Minute && ttl <= time.Hour
+		})).Return(nil).Once()
+
+		assert.NoError(t, service.ForgotPassword(EmailRequest{Email: "user@example.com"}))
+
+		assert.Len(t, mailer.sent, 1)
+		assert.Contains(t, mailer.sent[0].Body, "https://app.example.com/reset-password?token=")
+		assert.Contains(t, mailer.sent[0].Body, "1 hour")
+		_, err := jwtService.ValidatePasswordResetToken(mailer.linkToken(t))
+		assert.NoError(t, err)
+	})
+
+	t.Run("does not reveal unknown, inactive or SSO-only users", func(t *testing.T) {
+		service, mockRepo, _, mailer, _, _ := setupAccountService()
+		inactive, _ := domain.NewUser("inactive@example.com", "password123", domain.RoleViewer)
+		inactive.Deactivate()
+		mockRepo.On("FindByEmail", "nobody@example.com").Return(nil, errors.New("not found")).Once()
+		mockRepo.On("FindByEmail", "inactive@example.com").Return(inactive, nil).Once()
+		mockRepo.On("FindByEmail", "sso@example.com").Return(domain.NewSSOUser("sso@example.com", domain.RoleViewer), nil).Once()
+
+		for _, email := range []string{"nobody@example.com", "inactive@example.com", "sso@example.com"} {
+			assert.NoError(t, service.ForgotPassword(EmailRequest{Email: email}))
+		}
+		assert.Empty(t, mailer.sent)
+	})
+}
+
+func TestAccountService_ResetPassword(t *testing.T) {
+	t.Run("sets the password and ends all sessions", func(t *testing.T) {
+		service, mockRepo, mockTokens, _, mockSessions, jwtService := setupAccountService()
+		user, _ := domain.NewUser("user@example.com", "password123", domain.RoleViewer)
+		token, claims, _ := jwtService.GeneratePasswordResetToken(user.ID, user.Email)
+		mockTokens.On("Consume", jwt.TokenTypePasswordReset, user.ID, claims.ID).Return(true, nil).Once()
+		mockRepo.On("FindByID", user.ID).Return(user, nil).Once()
+		mockRepo.On("Save", user).Return(nil).Once()
+		mockSessions.On("LogoutAll", user.ID).Return(nil).Once()
+
+		err := service.ResetPassword(ResetPasswordRequest{Token: token, NewPassword: "newpassword456"})
+
+		assert.NoError(t, err)
+		assert.True(t, user.ValidatePassword("newpassword456"))
+		mockSessions.AssertExpectations(t)
+	})
+
+	t.Run("rejects a used token", func(t *testing.T) {
+		service, _, mockTokens, _, _, jwtService := setupAccountService()
+		userID := uuid.New()
+		token, claims, _ := jwtService.GeneratePasswordResetToken(userID, "user@example.com")
+		mockTokens.On("Consume", jwt.TokenTypePasswordReset, userID, claims.ID).Return(false, nil).Once()
+
+		err := service.ResetPassword(ResetPasswordRequest{Token: token, NewPassword: "newpassword456"})
+
+		assert.Equal(t, ErrInvalidResetToken, err)
+	})
+
+	t.Run("rejects a deactivated user", func(t *testing.T) {
+		service, mockRepo, mockTokens, _, _, jwtService := setupAccountService()
+		user, _ := domain.NewUser("user@example.com", "password123", domain.RoleViewer)
+		user.Deactivate()
+		token, claims, _ := jwtService.GeneratePasswordResetToken(user.ID, user.Email)
+		mockTokens.On("Consume", jwt.TokenTypePasswordReset, user.ID, claims.ID).Return(true, nil).Once()
+		mockRepo.On("FindByID", user.ID).Return(user, nil).Once()
+
+		err := service.ResetPassword(ResetPasswordRequest{Token: token, NewPassword: "newpassword456"})
+
+		assert.Equal(t, ErrAccountInactive, err)
+		assert.True(t, user.ValidatePassword("password123"))
+	})
+
+	t.Run("rejects a weak password without using the token", func(t *testing.T) {
+		service, _, mockTokens, _, _, jwtService := setupAccountService()
+		token, _, _ := jwtService.GeneratePasswordResetToken(uuid.New(), "user@example.com")
+
+		err := service.ResetPassword(ResetPasswordRequest{Token: token, NewPassword: "short"})
+
+		assert.ErrorIs(t, err, ErrWeakPassword)
+		mockTokens.AssertNotCalled(t, "Consume", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects a malformed token", func(t *testing.T) {
+		service, _, _, _, _, _ := setupAccountService()
+
+		err := service.ResetPassword(ResetPasswordRequest{Token: "not-a-token", NewPassword: "newpassword456"})
+
+		assert.Equal(t, ErrInvalidResetToken, err)
+	})
+}
+
+func TestAccountService_Profile(t *testing.T) {
+	service, mockRepo, _, _, _, _ := setupAccountService()
+	user, _ := domain.NewUser("user@example.com", "password123", domain.RoleViewer)
+	mockRepo.On("FindByID", user.ID).Return(user, nil).Once()
+
+	profile, err := service.Profile(user.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, NewUserResponse(user), profile)
+}
+
+func TestAccountService_UpdateProfile(t *testing.T) {
+	t.Run("renames the user", func(t *testing.T) {
+		service, mockRepo, _, _, _, _ := setupAccountService()
+		user, _ := domain.NewUser("user@example.com", "password123", domain.RoleViewer)
+		mockRepo.On("FindByID", user.ID).Return(user, nil).Once()
+		mockRepo.On("Save", user).Return(nil).Once()
+		name := "  Ada Lovelace "
+
+		profile, err := service.UpdateProfile(user.ID, UpdateProfileRequest{Name: &name})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Ada Lovelace", profile.Name)
+		assert.Equal(t, "Ada Lovelace", user.Name)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		service, mockRepo, _, _, _, _ := setupAccountService()
+		userID := uuid.New()
+		mockRepo.On("FindByID", userID).Return(nil, errors.New("not found")).Once()
+
+		_, err := service.UpdateProfile(userID, UpdateProfileRequest{})
+
+		assert.Equal(t, ErrUserNotFound, err)
+	})
+}
+
+func TestAccountService_ChangePassword(t *testing.T) {
+	req := ChangePasswordRequest{CurrentPassword: "password123", NewPassword: "newpassword456", ClientIP: "192.0.2.1"}
+
+	t.Run("sets the password and ends all sessions", func(t *testing.T) {
+		service, mockRepo, _, _, mockSessions, _ := setupAccountService()
+		user, _ := domain.NewUser("user@example.com", "password123", domain.RoleViewer)
+		mockRepo.On("FindByID", user.ID).Return(user, nil).Once()
+		mockRepo.On("Save", user).Return(nil).Once()
+		mockSessions.On("LogoutAll", user.ID).Return(nil).Once()
+
+		err := service.ChangePassword(user.ID, req)
+
+		assert.NoError(t, err)
+		assert.True(t, user.ValidatePassword("newpassword456"))
+		mockSessions.AssertExpectations(t)
+	})
+
+	t.Run("wrong current password counts as a failed login", func(t *testing.T) {
+		service, mockRepo, _, _, mockSessions, _ := setupAccountService()
+		attempts := new(MockLoginAttemptStore)
+		attempts.On("LockedUntil", mock.Anything).Return(time.Time{}, nil).Twice()
+		attempts.On("RecordFailure", "account:user@example.com", DefaultLoginPolicy.Window).Return(1, nil).Once()
+		attempts.On("RecordFailure", "ip:192.0.2.1", DefaultLoginPolicy.Window).Return(1, nil).Once()
+		service.loginGuard = NewLoginGuard(attempts, new(MockSecurityEventRepository), DefaultLoginPolicy)
+		user, _ := domain.NewUser("user@example.com", "password123", domain.RoleViewer)
+		mockRepo.On("FindByID", user.ID).Return(user, nil).Once()
+
+		err := service.ChangePassword(user.ID, ChangePasswordRequest{CurrentPassword: "guess", NewPassword: "newpassword456", ClientIP: "192.0.2.1"})
+
+		assert.Equal(t, ErrWrongPassword, err)
+		assert.True(t, user.ValidatePassword("password123"))
+		attempts.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "Save", user)
+		mockSessions.AssertNotCalled(t, "LogoutAll", user.ID)
+	})
+
+	t.Run("rejects a weak password", func(t *testing.T) {
+		service, mockRepo, _, _, _, _ := setupAccountService()
+		user, _ := domain.NewUser("user@example.com", "password123", domain.RoleViewer)
+		mockRepo.On("FindByID", user.ID).Return(user, nil).Once()
+
+		err := service.ChangePassword(user.ID, ChangePasswordRequest{CurrentPassword: "password123", NewPassword: "short"})
+
+		assert.ErrorIs(t, err, ErrWeakPassword)
+		assert.True(t, user.ValidatePassword("password123"))
+	})
+
+	t.Run("single sign-on user has no password", func(t *testing.T) {
+		service, mockRepo, _, _, _, _ := setupAccountService()
+		user := domain.NewSSOUser("sso@example.com", domain.RoleViewer)
+		mockRepo.On("FindByID", user.ID).Return(user, nil).Once()
+
+		err := service.ChangePassword(user.ID, req)
+
+		assert.Equal(t, ErrNoPassword, err)
+	})
+}
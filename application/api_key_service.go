@@ -0,0 +1,181 @@
+package application
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+	"threat-intel-backend/domain"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAPIKeyNotFound = errors.New("API key not found")
+	ErrInvalidAPIKey  = errors.New("invalid or expired API key")
+	ErrInvalidScope   = errors.New("invalid scope")
+	ErrInvalidExpiry  = errors.New("expires_at must be in the future")
+)
+
+const (
+	// apiKeyPrefix marks the keys of this service, so that leaked keys are
+	// easy to recognise by secret scanners.
+	apiKeyPrefix = "tik"
+
+	apiKeyIDBytes     = 6
+	apiKeySecretBytes = 32
+
+	// apiKeyTouchInterval bounds how often the last-used timestamp of a key
+	// is written, so that busy integrations do not write on every request.
+	apiKeyTouchInterval = time.Minute
+)
+
+type APIKeyService struct {
+	keyRepo  domain.APIKeyRepository
+	userRepo domain.UserRepository
+}
+
+type CreateAPIKeyRequest struct {
+	Name      string               `json:"name" binding:"required,max=100"`
+	Scopes    []domain.APIKeyScope `json:"scopes" binding:"required,min=1"`
+	ExpiresAt *time.Time           `json:"expires_at"`
+}
+
+// CreateAPIKeyResponse returns the key in clear text. It is only shown once;
+// the server keeps a hash of its secret.
+type CreateAPIKeyResponse struct {
+	*domain.APIKey
+	Key string `json:"key"`
+}
+
+func NewAPIKeyService(keyRepo domain.APIKeyRepository, userRepo domain.UserRepository) *APIKeyService {
+	return &APIKeyService{
+		keyRepo:  keyRepo,
+		userRepo: userRepo,
+	}
+}
+
+// CreateAPIKey mints a key acting as the user. Only analysts and admins may
+// mint keys with the intel:write scope.
+func (s *APIKeyService) CreateAPIKey(userID uuid.UUID, req CreateAPIKeyRequest) (*CreateAPIKeyResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	for _, scope := range req.Scopes {
+		if !scope.IsValid() {
+			return nil, ErrInvalidScope
+		}
+		if scope == domain.ScopeIntelWrite && !user.HasPermission(domain.RoleAnalyst) {
+			return nil, ErrInsufficientPermissions
+		}
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, ErrInvalidExpiry
+	}
+
+	prefix, secret, err := newAPIKeySecret()
+	if err != nil {
+		return nil, err
+	}
+
+	key := domain.NewAPIKey(userID, strings.TrimSpace(req.Name), prefix, hashAPIKeySecret(secret), req.Scopes, req.ExpiresAt)
+	if err := s.keyRepo.Save(key); err != nil {
+		return nil, err
+	}
+
+	return &CreateAPIKeyResponse{
+		APIKey: key,
+		Key:    apiKeyPrefix + "_" + prefix + "_" + secret,
+	}, nil
+}
+
+func (s *APIKeyService) ListAPIKeys(userID uuid.UUID) ([]*domain.APIKey, error) {
+	return s.keyRepo.FindByUserID(userID)
+}
+
+// RevokeAPIKey revokes a key of the user. Keys of other users are reported as
+// not found.
+func (s *APIKeyService) RevokeAPIKey(userID, keyID uuid.UUID) error {
+	key, err := s.keyRepo.FindByID(keyID)
+	if err != nil || key.UserID != userID {
+		return ErrAPIKeyNotFound
+	}
+
+	if key.RevokedAt != nil {
+		return nil
+	}
+
+	key.Revoke()
+	return s.keyRepo.Save(key)
+}
+
+// Authenticate resolves a key presented by a client to its owner. Keys of
+// deactivated users are rejected, and the owner's current role applies.
+func (s *APIKeyService) Authenticate(rawKey string) (*domain.User, *domain.APIKey, error) {
+	prefix, secret, ok := parseAPIKey(rawKey)
+	if !ok {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.keyRepo.FindByPrefix(prefix)
+	if err != nil {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashAPIKeySecret(secret)), []byte(key.SecretHash)) != 1 {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if !key.IsActive(now) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	user, err := s.userRepo.FindByID(key.UserID)
+	if err != nil || !user.IsActive {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.keyRepo.TouchLastUsed(key.ID, now); err == nil {
+			key.LastUsedAt = &now
+		}
+	}
+
+	return user, key, nil
+}
+
+func newAPIKeySecret() (string, string, error) {
+	id := make([]byte, apiKeyIDBytes)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+
+	secret := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+
+	return hex.EncodeToString(id), base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// parseAPIKey splits a key of the form tik_<prefix>_<secret>. The secret is
+// base64url and may itself contain underscores.
+func parseAPIKey(rawKey string) (string, string, bool) {
+	parts := strings.SplitN(rawKey, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyPrefix || parts[1] == "" || parts[2] == "" {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+func hashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
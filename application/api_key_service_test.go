@@ -0,0 +1,178 @@
+package application
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"threat-intel-backend/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) Save(key *domain.APIKey) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) FindByID(id uuid.UUID) (*domain.APIKey, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) FindByPrefix(prefix string) (*domain.APIKey, error) {
+	args := m.Called(prefix)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) FindByUserID(userID uuid.UUID) ([]*domain.APIKey, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]*domain.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) TouchLastUsed(id uuid.UUID, usedAt time.Time) error {
+	args := m.Called(id, usedAt)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) DeleteByUserID(userID uuid.UUID) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func setupAPIKeyService() (*APIKeyService, *MockAPIKeyRepository, *domain.User, *domain.User) {
+	mockKeys := new(MockAPIKeyRepository)
+	mockUsers := new(MockUserRepository)
+	analyst, _ := domain.NewUser("analyst@example.com", "password123", domain.RoleAnalyst)
+	viewer, _ := domain.NewUser("viewer@example.com", "password123", domain.RoleViewer)
+	mockUsers.On("FindByID", analyst.ID).Return(analyst, nil)
+	mockUsers.On("FindByID", viewer.ID).Return(viewer, nil)
+
+	return NewAPIKeyService(mockKeys, mockUsers), mockKeys, analyst, viewer
+}
+
+func TestAPIKeyService_CreateAndAuthenticate(t *testing.T) {
+	service, mockKeys, analyst, _ := setupAPIKeyService()
+
+	var saved *domain.APIKey
+	mockKeys.On("Save", mock.MatchedBy(func(key *domain.APIKey) bool {
+		saved = key
+		return true
+	})).Return(nil).Once()
+
+	created, err := service.CreateAPIKey(analyst.ID, CreateAPIKeyRequest{
+		Name:   "SIEM",
+		Scopes: []domain.APIKeyScope{domain.ScopeIntelRead, domain.ScopeIntelWrite},
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Key, "tik_"+saved.Prefix+"_"))
+	assert.NotContains(t, created.Key, saved.SecretHash)
+	assert.True(t, saved.HasScope(domain.ScopeIntelWrite))
+
+	t.Run("authenticates the key", func(t *testing.T) {
+		mockKeys.On("FindByPrefix", saved.Prefix).Return(saved, nil).Once()
+		mockKeys.On("TouchLastUsed", saved.ID, mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+		user, key, err := service.Authenticate(created.Key)
+
+		assert.NoError(t, err)
+		assert.Equal(t, analyst.ID, user.ID)
+		assert.Equal(t, saved.ID, key.ID)
+		assert.NotNil(t, key.LastUsedAt)
+	})
+
+	t.Run("does not touch a recently used key", func(t *testing.T) {
+		mockKeys.On("FindByPrefix", saved.Prefix).Return(saved, nil).Once()
+
+		_, _, err := service.Authenticate(created.Key)
+
+		assert.NoError(t, err)
+		mockKeys.AssertNumberOfCalls(t, "TouchLastUsed", 1)
+	})
+
+	t.Run("rejects a wrong secret", func(t *testing.T) {
+		mockKeys.On("FindByPrefix", saved.Prefix).Return(saved, nil).Once()
+
+		_, _, err := service.Authenticate("tik_" + saved.Prefix + "_wrong")
+
+		assert.Equal(t, ErrInvalidAPIKey, err)
+	})
+
+	t.Run("rejects a revoked key", func(t *testing.T) {
+		saved.Revoke()
+		mockKeys.On("FindByPrefix", saved.Prefix).Return(saved, nil).Once()
+
+		_, _, err := service.Authenticate(created.Key)
+
+		assert.Equal(t, ErrInvalidAPIKey, err)
+	})
+
+	t.Run("rejects malformed keys", func(t *testing.T) {
+		_, _, err := service.Authenticate("not-a-key")
+
+		assert.Equal(t, ErrInvalidAPIKey, err)
+	})
+}
+
+func TestAPIKeyService_CreateAPIKey(t *testing.T) {
+	service, _, analyst, viewer := setupAPIKeyService()
+
+	t.Run("viewers cannot mint write keys", func(t *testing.T) {
+		_, err := service.CreateAPIKey(viewer.ID, CreateAPIKeyRequest{Name: "SOAR", Scopes: []domain.APIKeyScope{domain.ScopeIntelWrite}})
+
+		assert.Equal(t, ErrInsufficientPermissions, err)
+	})
+
+	t.Run("rejects unknown scopes", func(t *testing.T) {
+		_, err := service.CreateAPIKey(analyst.ID, CreateAPIKeyRequest{Name: "SOAR", Scopes: []domain.APIKeyScope{"admin"}})
+
+		assert.Equal(t, ErrInvalidScope, err)
+	})
+
+	t.Run("rejects past expiry", func(t *testing.T) {
+		expiresAt := time.Now().Add(-time.Hour)
+
+		_, err := service.CreateAPIKey(analyst.ID, CreateAPIKeyRequest{Name: "SOAR", Scopes: []domain.APIKeyScope{domain.ScopeIntelRead}, ExpiresAt: &expiresAt})
+
+		assert.Equal(t, ErrInvalidExpiry, err)
+	})
+}
+
+func TestAPIKeyService_RevokeAPIKey(t *testing.T) {
+	service, mockKeys, analyst, viewer := setupAPIKeyService()
+	key := domain.NewAPIKey(analyst.ID, "SIEM", "a1b2c3d4e5f6", "hash", []domain.APIKeyScope{domain.ScopeIntelRead}, nil)
+
+	t.Run("owner revokes", func(t *testing.T) {
+		mockKeys.On("FindByID", key.ID).Return(key, nil).Once()
+		mockKeys.On("Save", key).Return(nil).Once()
+
+		assert.NoError(t, service.RevokeAPIKey(analyst.ID, key.ID))
+		assert.NotNil(t, key.RevokedAt)
+	})
+
+	t.Run("other users get not found", func(t *testing.T) {
+		mockKeys.On("FindByID", key.ID).Return(key, nil).Once()
+
+		assert.Equal(t, ErrAPIKeyNotFound, service.RevokeAPIKey(viewer.ID, key.ID))
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		id := uuid.New()
+		mockKeys.On("FindByID", id).Return(nil, errors.New("record not found")).Once()
+
+		assert.Equal(t, ErrAPIKeyNotFound, service.RevokeAPIKey(analyst.ID, id))
+	})
+}
@@ -2,104 +2,332 @@ package application
 
 import (
 	"errors"
+	"time"
 	"threat-intel-backend/domain"
 	"threat-intel-backend/infrastructure/jwt"
+	"github.com/google/uuid"
 )
 
 type AuthService struct {
 	userRepo   domain.UserRepository
 	jwtService *jwt.Service
+	tokenStore domain.RefreshTokenStore
+	denylist   domain.AccessTokenDenylist
+	loginGuard *LoginGuard
+	mfa        *MFAService
+	// unknownUser is compared against when a login names an unknown email,
+	// so that it takes as long as a wrong password for an existing account.
+	// Its hash is made with the configured hashing for the same reason.
+	unknownUser *domain.User
 }
 
+// LoginRequest carries the credentials and, set by the handler, the client IP
+// that failed attempts are also counted against.
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
+	ClientIP string `json:"-"`
 }
 
-type RegisterRequest struct {
-	Email    string          `json:"email" binding:"required,email"`
-	Password string          `json:"password" binding:"required,min=6"`
-	Role     domain.UserRole `json:"role" binding:"required"`
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
 }
 
 type AuthResponse struct {
-	AccessToken  string       `json:"access_token"`
-	RefreshToken string       `json:"refresh_token"`
-	User         *domain.User `json:"user"`
+	AccessToken  string        `json:"access_token"`
+	RefreshToken string        `json:"refresh_token"`
+	User         *UserResponse `json:"user"`
 }
 
-func NewAuthService(userRepo domain.UserRepository, jwtService *jwt.Service) *AuthService {
+// UserResponse is what clients see of a user, so that fields added to
+// domain.User are not exposed by accident.
+type UserResponse struct {
+	ID              uuid.UUID       `json:"id"`
+	Email           string          `json:"email"`
+	Name            string          `json:"name"`
+	Role            domain.UserRole `json:"role"`
+	IsActive        bool            `json:"is_active"`
+	EmailVerifiedAt *time.Time      `json:"email_verified_at,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+}
+
+func NewUserResponse(user *domain.User) *UserResponse {
+	return &UserResponse{
+		ID:              user.ID,
+		Email:           user.Email,
+		Name:            user.Name,
+		Role:            user.Role,
+		IsActive:        user.IsActive,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+	}
+}
+
+// MFAChallenge is returned by Login instead of tokens when the password was
+// accepted but a second factor is still needed. EnrollmentRequired is set
+// when the role of the user requires MFA and they have not enrolled yet.
+type MFAChallenge struct {
+	MFARequired        bool   `json:"mfa_required"`
+	EnrollmentRequired bool   `json:"mfa_enrollment_required,omitempty"`
+	MFAToken           string `json:"mfa_token"`
+	ExpiresIn          int    `json:"expires_in"`
+}
+
+// LoginResponse holds either the tokens of a completed login or an MFA
+// challenge.
+type LoginResponse struct {
+	*AuthResponse
+	*MFAChallenge
+}
+
+// VerifyMFARequest completes a login that was answered with an MFA
+// challenge. Code is a TOTP code or, for verify challenges, a recovery code.
+type VerifyMFARequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+	ClientIP string `json:"-"`
+}
+
+type MFATokenRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+}
+
+// MFAEnrolledResponse completes a login that required enrollment. The
+// recovery codes are only shown this once.
+type MFAEnrolledResponse struct {
+	*AuthResponse
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func NewAuthService(userRepo domain.UserRepository, jwtService *jwt.Service, tokenStore domain.RefreshTokenStore, denylist domain.AccessTokenDenylist, loginGuard *LoginGuard, mfa *MFAService) *AuthService {
+	unknownUser := &domain.User{}
+	_ = unknownUser.SetPassword("unknown-user-password")
+
 	return &AuthService{
-		userRepo:   userRepo,
-		jwtService: jwtService,
+		userRepo:    userRepo,
+		jwtService:  jwtService,
+		tokenStore:  tokenStore,
+		denylist:    denylist,
+		loginGuard:  loginGuard,
+		mfa:         mfa,
+		unknownUser: unknownUser,
 	}
 }
 
-func (s *AuthService) Login(req LoginRequest) (*AuthResponse, error) {
+// Login answers unknown emails and wrong passwords alike, and only reports an
+// inactive account once the password has been verified. Users with MFA, or
+// whose role requires it, get an MFA challenge instead of tokens; their
+// failed attempts are only reset once the second step succeeds.
+func (s *AuthService) Login(req LoginRequest) (*LoginResponse, error) {
+	if err := s.loginGuard.Check(req.Email, req.ClientIP); err != nil {
+		return nil, err
+	}
+
 	user, err := s.userRepo.FindByEmail(req.Email)
 	if err != nil {
-		return nil, errors.New("invalid credentials")
+		s.unknownUser.ValidatePassword(req.Password)
+		return nil, s.loginFailed(nil, req)
 	}
 
+	if !user.ValidatePassword(req.Password) {
+		return nil, s.loginFailed(user, req)
+	}
+	if user.PasswordNeedsRehash() {
+		s.rehashPassword(user, req.Password)
+	}
+
+	if user.AwaitingVerification() {
+		return nil, ErrEmailNotVerified
+	}
 	if !user.IsActive {
 		return nil, errors.New("account is inactive")
 	}
 
-	if !user.ValidatePassword(req.Password) {
-		return nil, errors.New("invalid credentials")
+	challenge, err := s.requireMFA(user)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return &LoginResponse{MFAChallenge: challenge}, nil
 	}
 
-	accessToken, err := s.jwtService.GenerateAccessToken(user.ID, user.Role)
+	resp, err := s.completeLogin(user, req.Email)
 	if err != nil {
 		return nil, err
 	}
+	return &LoginResponse{AuthResponse: resp}, nil
+}
 
-	refreshToken, err := s.jwtService.GenerateRefreshToken(user.ID)
+// StartSession signs in a user who was authenticated by other means, such as
+// an accepted invitation or an SSO login. Like Login, it answers with an MFA
+// challenge instead of tokens when the user is enrolled or their role
+// requires MFA.
+func (s *AuthService) StartSession(user *domain.User) (*LoginResponse, error) {
+	challenge, err := s.requireMFA(user)
 	if err != nil {
 		return nil, err
 	}
+	if challenge != nil {
+		return &LoginResponse{MFAChallenge: challenge}, nil
+	}
 
-	return &AuthResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		User:         user,
-	}, nil
+	resp, err := s.IssueTokens(user)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResponse{AuthResponse: resp}, nil
 }
 
-func (s *AuthService) Register(req RegisterRequest) (*AuthResponse, error) {
-	existingUser, _ := s.userRepo.FindByEmail(req.Email)
-	if existingUser != nil {
-		return nil, errors.New("email already exists")
+// rehashPassword replaces a password hash made with weaker parameters than
+// the configured ones. A failure does not fail the login; the hash is
+// replaced on a later one.
+func (s *AuthService) rehashPassword(user *domain.User, password string) {
+	if err := user.SetPassword(password); err == nil {
+		_ = s.userRepo.Save(user)
 	}
+}
 
-	user, err := domain.NewUser(req.Email, req.Password, req.Role)
+// VerifyMFA completes a login with a TOTP or recovery code. Wrong codes count
+// as failed logins of the account.
+func (s *AuthService) VerifyMFA(req VerifyMFARequest) (*AuthResponse, error) {
+	user, err := s.mfaUser(req.MFAToken, jwt.MFAPurposeVerify)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := s.userRepo.Save(user); err != nil {
+	if err := s.loginGuard.Check(user.Email, req.ClientIP); err != nil {
 		return nil, err
 	}
 
-	accessToken, err := s.jwtService.GenerateAccessToken(user.ID, user.Role)
+	if err := s.mfa.Verify(user.ID, req.Code); err != nil {
+		return nil, s.mfaFailed(user, req.ClientIP, err)
+	}
+
+	return s.completeLogin(user, user.Email)
+}
+
+// BeginMFAEnrollment starts enrollment for a user whose login was answered
+// with an enrollment challenge.
+func (s *AuthService) BeginMFAEnrollment(req MFATokenRequest) (*MFAEnrollmentResponse, error) {
+	user, err := s.mfaUser(req.MFAToken, jwt.MFAPurposeEnroll)
 	if err != nil {
 		return nil, err
 	}
+	return s.mfa.BeginEnrollment(user.ID)
+}
 
-	refreshToken, err := s.jwtService.GenerateRefreshToken(user.ID)
+// CompleteMFAEnrollment confirms the factor started with BeginMFAEnrollment
+// and completes the login.
+func (s *AuthService) CompleteMFAEnrollment(req VerifyMFARequest) (*MFAEnrolledResponse, error) {
+	user, err := s.mfaUser(req.MFAToken, jwt.MFAPurposeEnroll)
 	if err != nil {
 		return nil, err
 	}
 
-	return &AuthResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		User:         user,
+	if err := s.loginGuard.Check(user.Email, req.ClientIP); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.mfa.ConfirmEnrollment(user.ID, MFACodeRequest{Code: req.Code})
+	if err != nil {
+		return nil, s.mfaFailed(user, req.ClientIP, err)
+	}
+
+	resp, err := s.completeLogin(user, user.Email)
+	if err != nil {
+		return nil, err
+	}
+	return &MFAEnrolledResponse{AuthResponse: resp, RecoveryCodes: codes.RecoveryCodes}, nil
+}
+
+// requireMFA returns the challenge the user must answer before tokens are
+// issued, or nil when the user is not enrolled and their role does not
+// require MFA.
+func (s *AuthService) requireMFA(user *domain.User) (*MFAChallenge, error) {
+	enrolled, required := s.mfa.Requirement(user)
+	if !enrolled && !required {
+		return nil, nil
+	}
+
+	purpose := jwt.MFAPurposeVerify
+	if !enrolled {
+		purpose = jwt.MFAPurposeEnroll
+	}
+	return s.mfaChallenge(user, purpose)
+}
+
+func (s *AuthService) mfaChallenge(user *domain.User, purpose string) (*MFAChallenge, error) {
+	token, err := s.jwtService.GenerateMFAToken(user.ID, purpose)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MFAChallenge{
+		MFARequired:        true,
+		EnrollmentRequired: purpose == jwt.MFAPurposeEnroll,
+		MFAToken:           token,
+		ExpiresIn:          int(s.jwtService.MFATokenTTL().Seconds()),
 	}, nil
 }
 
+func (s *AuthService) mfaUser(token, purpose string) (*domain.User, error) {
+	claims, err := s.jwtService.ValidateMFAToken(token, purpose)
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+
+	userID, err := claims.UserID()
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+
+	if !user.IsActive {
+		return nil, errors.New("account is inactive")
+	}
+	return user, nil
+}
+
+func (s *AuthService) mfaFailed(user *domain.User, clientIP string, err error) error {
+	if !errors.Is(err, ErrInvalidMFACode) {
+		return err
+	}
+	if err := s.loginGuard.Fail(user, user.Email, clientIP); err != nil {
+		return err
+	}
+	return ErrInvalidMFACode
+}
+
+func (s *AuthService) completeLogin(user *domain.User, email string) (*AuthResponse, error) {
+	if err := s.loginGuard.Succeed(email); err != nil {
+		return nil, err
+	}
+	return s.IssueTokens(user)
+}
+
+func (s *AuthService) loginFailed(user *domain.User, req LoginRequest) error {
+	if err := s.loginGuard.Fail(user, req.Email, req.ClientIP); err != nil {
+		return err
+	}
+	return ErrInvalidCredentials
+}
+
+// RefreshToken rotates a refresh token. Every token of a family that was
+// alive when the user's sessions were revoked was issued before the cut-off,
+// so checking the presented token is enough to reject the whole family.
 func (s *AuthService) RefreshToken(refreshToken string) (*AuthResponse, error) {
-	userID, err := s.jwtService.ValidateRefreshToken(refreshToken)
+	claims, err := s.jwtService.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return nil, errors.New("invalid refresh token")
+	}
+
+	userID, err := claims.UserID()
 	if err != nil {
 		return nil, errors.New("invalid refresh token")
 	}
@@ -109,12 +337,37 @@ func (s *AuthService) RefreshToken(refreshToken string) (*AuthResponse, error) {
 		return nil, errors.New("user not found")
 	}
 
-	accessToken, err := s.jwtService.GenerateAccessToken(user.ID, user.Role)
+	if !user.IsActive {
+		_ = s.tokenStore.RevokeFamily(claims.FamilyID)
+		return nil, errors.New("account is inactive")
+	}
+
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	revoked, err := s.denylist.IsRevoked("", user.ID, issuedAt)
 	if err != nil {
 		return nil, err
 	}
+	if revoked {
+		_ = s.tokenStore.RevokeFamily(claims.FamilyID)
+		return nil, errors.New("invalid refresh token")
+	}
 
-	newRefreshToken, err := s.jwtService.GenerateRefreshToken(user.ID)
+	newRefreshToken, newClaims, err := s.jwtService.GenerateRefreshToken(user.ID, claims.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.tokenStore.Rotate(user.ID, claims.FamilyID, claims.ID, newClaims.ID, s.jwtService.RefreshTokenTTL()); err != nil {
+		if errors.Is(err, domain.ErrRefreshTokenReused) {
+			return nil, err
+		}
+		return nil, errors.New("invalid refresh token")
+	}
+
+	accessToken, err := s.jwtService.GenerateAccessToken(user.ID, user.Role)
 	if err != nil {
 		return nil, err
 	}
@@ -122,6 +375,65 @@ func (s *AuthService) RefreshToken(refreshToken string) (*AuthResponse, error) {
 	return &AuthResponse{
 		AccessToken:  accessToken,
 		RefreshToken: newRefreshToken,
-		User:         user,
+		User:         NewUserResponse(user),
+	}, nil
+}
+
+// Logout revokes the access token identified by tokenID and, when the caller
+// hands in its refresh token, the refresh token family of the same login.
+func (s *AuthService) Logout(userID uuid.UUID, tokenID string, expiresAt time.Time, req LogoutRequest) error {
+	if err := s.denylist.RevokeToken(tokenID, expiresAt); err != nil {
+		return err
+	}
+
+	if req.RefreshToken == "" {
+		return nil
+	}
+
+	claims, err := s.jwtService.ValidateRefreshToken(req.RefreshToken)
+	if err != nil {
+		return errors.New("invalid refresh token")
+	}
+
+	tokenUserID, err := claims.UserID()
+	if err != nil || tokenUserID != userID {
+		return errors.New("invalid refresh token")
+	}
+
+	return s.tokenStore.RevokeFamily(claims.FamilyID)
+}
+
+// LogoutAll revokes every access and refresh token issued to the user so far.
+// The cut-off is kept as long as a refresh token lives, so that it also
+// rejects refresh families the user index no longer lists.
+func (s *AuthService) LogoutAll(userID uuid.UUID) error {
+	if err := s.denylist.RevokeUserTokens(userID, time.Now(), s.jwtService.RefreshTokenTTL()); err != nil {
+		return err
+	}
+	return s.tokenStore.RevokeUser(userID)
+}
+
+// IssueTokens issues an access token and the first refresh token of a new
+// rotation family for an already authenticated user.
+func (s *AuthService) IssueTokens(user *domain.User) (*AuthResponse, error) {
+	accessToken, err := s.jwtService.GenerateAccessToken(user.ID, user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	familyID := uuid.New()
+	refreshToken, claims, err := s.jwtService.GenerateRefreshToken(user.ID, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.tokenStore.Create(user.ID, familyID, claims.ID, s.jwtService.RefreshTokenTTL()); err != nil {
+		return nil, err
+	}
+
+	return &AuthResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		User:         NewUserResponse(user),
 	}, nil
 }
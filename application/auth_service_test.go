@@ -5,10 +5,12 @@ import (
 	"testing"
 	"threat-intel-backend/domain"
 	"threat-intel-backend/infrastructure/jwt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type MockUserRepository struct {
@@ -36,10 +38,68 @@ func (m *MockUserRepository) FindByEmail(email string) (*domain.User, error) {
 	return args.Get(0).(*domain.User), args.Error(1)
 }
 
+func (m *MockUserRepository) List(filter domain.UserFilter) ([]*domain.User, int64, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*domain.User), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockUserRepository) Delete(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+type MockRefreshTokenStore struct {
+	mock.Mock
+}
+
+func (m *MockRefreshTokenStore) Create(userID, familyID uuid.UUID, tokenID string, ttl time.Duration) error {
+	args := m.Called(userID, familyID, tokenID, ttl)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenStore) Rotate(userID, familyID uuid.UUID, currentTokenID, nextTokenID string, ttl time.Duration) error {
+	args := m.Called(userID, familyID, currentTokenID, nextTokenID, ttl)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenStore) RevokeFamily(familyID uuid.UUID) error {
+	args := m.Called(familyID)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenStore) RevokeUser(userID uuid.UUID) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+type MockAccessTokenDenylist struct {
+	mock.Mock
+}
+
+func (m *MockAccessTokenDenylist) RevokeToken(tokenID string, expiresAt time.Time) error {
+	args := m.Called(tokenID, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockAccessTokenDenylist) RevokeUserTokens(userID uuid.UUID, issuedBefore time.Time, ttl time.Duration) error {
+	args := m.Called(userID, issuedBefore, ttl)
+	return args.Error(0)
+}
+
+func (m *MockAccessTokenDenylist) IsRevoked(tokenID string, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	args := m.Called(tokenID, userID, issuedAt)
+	return args.Bool(0), args.Error(1)
+}
+
 func TestAuthService_Login(t *testing.T) {
 	mockRepo := new(MockUserRepository)
+	mockStore := new(MockRefreshTokenStore)
+	mockStore.On("Create", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	jwtService := jwt.NewService("test-secret")
-	authService := NewAuthService(mockRepo, jwtService)
+	authService := NewAuthService(mockRepo, jwtService, mockStore, new(MockAccessTokenDenylist), permissiveLoginGuard(), noMFA())
 
 	user, _ := domain.NewUser("test@example.com", "password123", domain.RoleViewer)
 	user.ID = uuid.New()
@@ -58,7 +118,24 @@ func TestAuthService_Login(t *testing.T) {
 		assert.NotNil(t, resp)
 		assert.NotEmpty(t, resp.AccessToken)
 		assert.NotEmpty(t, resp.RefreshToken)
-		assert.Equal(t, user, resp.User)
+		assert.Equal(t, NewUserResponse(user), resp.User)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rehashes a weak password hash", func(t *testing.T) {
+		weakHash, _ := domain.PasswordHashing{Algorithm: domain.PasswordHashBcrypt, BcryptCost: bcrypt.MinCost}.Hash("password123")
+		legacyUser := *user
+		legacyUser.PasswordHash = weakHash
+		mockRepo.On("FindByEmail", "test@example.com").Return(&legacyUser, nil).Once()
+		mockRepo.On("Save", &legacyUser).Return(nil).Once()
+
+		resp, err := authService.Login(LoginRequest{Email: "test@example.com", Password: "password123"})
+
+		assert.NoError(t, err)
+		assert.NotNil(t, resp.AuthResponse)
+		assert.NotEqual(t, weakHash, legacyUser.PasswordHash)
+		assert.False(t, legacyUser.PasswordNeedsRehash())
+		assert.True(t, legacyUser.ValidatePassword("password123"))
 		mockRepo.AssertExpectations(t)
 	})
 
@@ -80,7 +157,7 @@ func TestAuthService_Login(t *testing.T) {
 
 	t.Run("inactive user", func(t *testing.T) {
 		inactiveUser := *user
-		inactiveUser.IsActive = false
+		inactiveUser.Deactivate()
 		mockRepo.On("FindByEmail", "test@example.com").Return(&inactiveUser, nil).Once()
 
 		req := LoginRequest{
@@ -96,6 +173,16 @@ func TestAuthService_Login(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("unverified user", func(t *testing.T) {
+		pendingUser, _ := domain.NewPendingUser("test@example.com", "password123", domain.RoleViewer)
+		mockRepo.On("FindByEmail", "test@example.com").Return(pendingUser, nil).Once()
+
+		resp, err := authService.Login(LoginRequest{Email: "test@example.com", Password: "password123"})
+
+		assert.Equal(t, ErrEmailNotVerified, err)
+		assert.Nil(t, resp)
+	})
+
 	t.Run("invalid password", func(t *testing.T) {
 		mockRepo.On("FindByEmail", "test@example.com").Return(user, nil).Once()
 
@@ -113,80 +200,70 @@ func TestAuthService_Login(t *testing.T) {
 	})
 }
 
-func TestAuthService_Register(t *testing.T) {
+func TestAuthService_LoginLockout(t *testing.T) {
 	mockRepo := new(MockUserRepository)
+	mockStore := new(MockRefreshTokenStore)
+	mockStore.On("Create", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	attempts := new(MockLoginAttemptStore)
 	jwtService := jwt.NewService("test-secret")
-	authService := NewAuthService(mockRepo, jwtService)
+	authService := NewAuthService(mockRepo, jwtService, mockStore, new(MockAccessTokenDenylist),
+		NewLoginGuard(attempts, new(MockSecurityEventRepository), DefaultLoginPolicy), noMFA())
 
-	t.Run("successful registration", func(t *testing.T) {
-		mockRepo.On("FindByEmail", "new@example.com").Return(nil, errors.New("not found")).Once()
-		mockRepo.On("Save", mock.AnythingOfType("*domain.User")).Return(nil).Once()
+	user, _ := domain.NewUser("test@example.com", "password123", domain.RoleViewer)
+	req := LoginRequest{Email: "test@example.com", Password: "password123", ClientIP: "192.0.2.1"}
 
-		req := RegisterRequest{
-			Email:    "new@example.com",
-			Password: "password123",
-			Role:     domain.RoleViewer,
-		}
+	t.Run("locked email is rejected before the password is checked", func(t *testing.T) {
+		attempts.On("LockedUntil", "account:test@example.com").Return(time.Now().Add(time.Minute), nil).Once()
+		attempts.On("LockedUntil", "ip:192.0.2.1").Return(time.Time{}, nil).Once()
 
-		resp, err := authService.Register(req)
+		resp, err := authService.Login(req)
 
-		assert.NoError(t, err)
-		assert.NotNil(t, resp)
-		assert.NotEmpty(t, resp.AccessToken)
-		assert.NotEmpty(t, resp.RefreshToken)
-		assert.Equal(t, "new@example.com", resp.User.Email)
-		assert.Equal(t, domain.RoleViewer, resp.User.Role)
-		mockRepo.AssertExpectations(t)
+		assert.Nil(t, resp)
+		assert.True(t, errors.Is(err, ErrAccountLocked))
+		mockRepo.AssertNotCalled(t, "FindByEmail", mock.Anything)
 	})
 
-	t.Run("email already exists", func(t *testing.T) {
-		existingUser, _ := domain.NewUser("existing@example.com", "password123", domain.RoleViewer)
-		mockRepo.On("FindByEmail", "existing@example.com").Return(existingUser, nil).Once()
+	t.Run("unknown email counts as a failure", func(t *testing.T) {
+		attempts.On("LockedUntil", mock.Anything).Return(time.Time{}, nil).Twice()
+		mockRepo.On("FindByEmail", "ghost@example.com").Return(nil, errors.New("not found")).Once()
+		attempts.On("RecordFailure", "account:ghost@example.com", DefaultLoginPolicy.Window).Return(1, nil).Once()
+		attempts.On("RecordFailure", "ip:192.0.2.1", DefaultLoginPolicy.Window).Return(1, nil).Once()
 
-		req := RegisterRequest{
-			Email:    "existing@example.com",
-			Password: "password123",
-			Role:     domain.RoleViewer,
-		}
-
-		resp, err := authService.Register(req)
+		_, err := authService.Login(LoginRequest{Email: "ghost@example.com", Password: "password123", ClientIP: "192.0.2.1"})
 
-		assert.Error(t, err)
-		assert.Nil(t, resp)
-		assert.Equal(t, "email already exists", err.Error())
-		mockRepo.AssertExpectations(t)
+		assert.Equal(t, ErrInvalidCredentials, err)
+		attempts.AssertExpectations(t)
 	})
 
-	t.Run("save user fails", func(t *testing.T) {
-		mockRepo.On("FindByEmail", "new@example.com").Return(nil, errors.New("not found")).Once()
-		mockRepo.On("Save", mock.AnythingOfType("*domain.User")).Return(errors.New("save failed")).Once()
-
-		req := RegisterRequest{
-			Email:    "new@example.com",
-			Password: "password123",
-			Role:     domain.RoleViewer,
-		}
+	t.Run("successful login resets the email", func(t *testing.T) {
+		attempts.On("LockedUntil", mock.Anything).Return(time.Time{}, nil).Twice()
+		mockRepo.On("FindByEmail", "test@example.com").Return(user, nil).Once()
+		attempts.On("Reset", "account:test@example.com").Return(nil).Once()
 
-		resp, err := authService.Register(req)
+		resp, err := authService.Login(req)
 
-		assert.Error(t, err)
-		assert.Nil(t, resp)
-		assert.Equal(t, "save failed", err.Error())
-		mockRepo.AssertExpectations(t)
+		assert.NoError(t, err)
+		assert.NotNil(t, resp)
+		attempts.AssertExpectations(t)
 	})
 }
 
 func TestAuthService_RefreshToken(t *testing.T) {
 	mockRepo := new(MockUserRepository)
+	mockStore := new(MockRefreshTokenStore)
+	mockDenylist := new(MockAccessTokenDenylist)
 	jwtService := jwt.NewService("test-secret")
-	authService := NewAuthService(mockRepo, jwtService)
+	authService := NewAuthService(mockRepo, jwtService, mockStore, mockDenylist, permissiveLoginGuard(), noMFA())
 
 	user, _ := domain.NewUser("test@example.com", "password123", domain.RoleViewer)
 	user.ID = uuid.New()
+	familyID := uuid.New()
+	mockDenylist.On("IsRevoked", "", user.ID, mock.AnythingOfType("time.Time")).Return(false, nil)
 
 	t.Run("successful token refresh", func(t *testing.T) {
-		refreshToken, _ := jwtService.GenerateRefreshToken(user.ID)
+		refreshToken, claims, _ := jwtService.GenerateRefreshToken(user.ID, familyID)
 		mockRepo.On("FindByID", user.ID).Return(user, nil).Once()
+		mockStore.On("Rotate", user.ID, familyID, claims.ID, mock.AnythingOfType("string"), jwtService.RefreshTokenTTL()).Return(nil).Once()
 
 		resp, err := authService.RefreshToken(refreshToken)
 
@@ -194,8 +271,14 @@ func TestAuthService_RefreshToken(t *testing.T) {
 		assert.NotNil(t, resp)
 		assert.NotEmpty(t, resp.AccessToken)
 		assert.NotEmpty(t, resp.RefreshToken)
-		assert.Equal(t, user, resp.User)
+		assert.Equal(t, NewUserResponse(user), resp.User)
+
+		newClaims, err := jwtService.ValidateRefreshToken(resp.RefreshToken)
+		assert.NoError(t, err)
+		assert.Equal(t, familyID, newClaims.FamilyID)
+		assert.NotEqual(t, claims.ID, newClaims.ID)
 		mockRepo.AssertExpectations(t)
+		mockStore.AssertExpectations(t)
 	})
 
 	t.Run("invalid refresh token", func(t *testing.T) {
@@ -207,7 +290,7 @@ func TestAuthService_RefreshToken(t *testing.T) {
 	})
 
 	t.Run("user not found", func(t *testing.T) {
-		refreshToken, _ := jwtService.GenerateRefreshToken(user.ID)
+		refreshToken, _, _ := jwtService.GenerateRefreshToken(user.ID, familyID)
 		mockRepo.On("FindByID", user.ID).Return(nil, errors.New("not found")).Once()
 
 		resp, err := authService.RefreshToken(refreshToken)
@@ -217,15 +300,242 @@ func TestAuthService_RefreshToken(t *testing.T) {
 		assert.Equal(t, "user not found", err.Error())
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("inactive user revokes family", func(t *testing.T) {
+		inactiveUser := *user
+		inactiveUser.IsActive = false
+		refreshToken, _, _ := jwtService.GenerateRefreshToken(user.ID, familyID)
+		mockRepo.On("FindByID", user.ID).Return(&inactiveUser, nil).Once()
+		mockStore.On("RevokeFamily", familyID).Return(nil).Once()
+
+		resp, err := authService.RefreshToken(refreshToken)
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+		assert.Equal(t, "account is inactive", err.Error())
+		mockStore.AssertExpectations(t)
+	})
+
+	t.Run("reused token is rejected", func(t *testing.T) {
+		refreshToken, claims, _ := jwtService.GenerateRefreshToken(user.ID, familyID)
+		mockRepo.On("FindByID", user.ID).Return(user, nil).Once()
+		mockStore.On("Rotate", user.ID, familyID, claims.ID, mock.AnythingOfType("string"), jwtService.RefreshTokenTTL()).Return(domain.ErrRefreshTokenReused).Once()
+
+		resp, err := authService.RefreshToken(refreshToken)
+
+		assert.ErrorIs(t, err, domain.ErrRefreshTokenReused)
+		assert.Nil(t, resp)
+		mockStore.AssertExpectations(t)
+	})
+
+	t.Run("family issued before revoking all sessions is rejected", func(t *testing.T) {
+		loggedOut, _ := domain.NewUser("out@example.com", "password123", domain.RoleViewer)
+		staleFamily := uuid.New()
+		refreshToken, claims, _ := jwtService.GenerateRefreshToken(loggedOut.ID, staleFamily)
+		mockRepo.On("FindByID", loggedOut.ID).Return(loggedOut, nil).Once()
+		mockDenylist.On("IsRevoked", "", loggedOut.ID, claims.IssuedAt.Time).Return(true, nil).Once()
+		mockStore.On("RevokeFamily", staleFamily).Return(nil).Once()
+
+		resp, err := authService.RefreshToken(refreshToken)
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+		assert.Equal(t, "invalid refresh token", err.Error())
+		mockStore.AssertExpectations(t)
+		mockStore.AssertNotCalled(t, "Rotate", loggedOut.ID, staleFamily, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("revoked family is rejected", func(t *testing.T) {
+		refreshToken, claims, _ := jwtService.GenerateRefreshToken(user.ID, familyID)
+		mockRepo.On("FindByID", user.ID).Return(user, nil).Once()
+		mockStore.On("Rotate", user.ID, familyID, claims.ID, mock.AnythingOfType("string"), jwtService.RefreshTokenTTL()).Return(domain.ErrRefreshTokenRevoked).Once()
+
+		resp, err := authService.RefreshToken(refreshToken)
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+		assert.Equal(t, "invalid refresh token", err.Error())
+		mockStore.AssertExpectations(t)
+	})
+}
+
+func TestAuthService_Logout(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	mockStore := new(MockRefreshTokenStore)
+	mockDenylist := new(MockAccessTokenDenylist)
+	jwtService := jwt.NewService("test-secret")
+	authService := NewAuthService(mockRepo, jwtService, mockStore, mockDenylist, permissiveLoginGuard(), noMFA())
+
+	userID := uuid.New()
+	expiresAt := time.Now().Add(time.Minute)
+
+	t.Run("revokes access token only", func(t *testing.T) {
+		mockDenylist.On("RevokeToken", "jti-1", expiresAt).Return(nil).Once()
+
+		err := authService.Logout(userID, "jti-1", expiresAt, LogoutRequest{})
+
+		assert.NoError(t, err)
+		mockDenylist.AssertExpectations(t)
+	})
+
+	t.Run("revokes refresh token family", func(t *testing.T) {
+		familyID := uuid.New()
+		refreshToken, _, _ := jwtService.GenerateRefreshToken(userID, familyID)
+		mockDenylist.On("RevokeToken", "jti-2", expiresAt).Return(nil).Once()
+		mockStore.On("RevokeFamily", familyID).Return(nil).Once()
+
+		err := authService.Logout(userID, "jti-2", expiresAt, LogoutRequest{RefreshToken: refreshToken})
+
+		assert.NoError(t, err)
+		mockDenylist.AssertExpectations(t)
+		mockStore.AssertExpectations(t)
+	})
+
+	t.Run("rejects refresh token of another user", func(t *testing.T) {
+		refreshToken, _, _ := jwtService.GenerateRefreshToken(uuid.New(), uuid.New())
+		mockDenylist.On("RevokeToken", "jti-3", expiresAt).Return(nil).Once()
+
+		err := authService.Logout(userID, "jti-3", expiresAt, LogoutRequest{RefreshToken: refreshToken})
+
+		assert.Error(t, err)
+		assert.Equal(t, "invalid refresh token", err.Error())
+	})
+
+	t.Run("denylist failure", func(t *testing.T) {
+		mockDenylist.On("RevokeToken", "jti-4", expiresAt).Return(errors.New("redis down")).Once()
+
+		err := authService.Logout(userID, "jti-4", expiresAt, LogoutRequest{})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestAuthService_LogoutAll(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	mockStore := new(MockRefreshTokenStore)
+	mockDenylist := new(MockAccessTokenDenylist)
+	jwtService := jwt.NewService("test-secret")
+	authService := NewAuthService(mockRepo, jwtService, mockStore, mockDenylist, permissiveLoginGuard(), noMFA())
+
+	userID := uuid.New()
+
+	t.Run("revokes all tokens", func(t *testing.T) {
+		mockDenylist.On("RevokeUserTokens", userID, mock.AnythingOfType("time.Time"), jwtService.RefreshTokenTTL()).Return(nil).Once()
+		mockStore.On("RevokeUser", userID).Return(nil).Once()
+
+		err := authService.LogoutAll(userID)
+
+		assert.NoError(t, err)
+		mockDenylist.AssertExpectations(t)
+		mockStore.AssertExpectations(t)
+	})
+
+	t.Run("denylist failure", func(t *testing.T) {
+		mockDenylist.On("RevokeUserTokens", userID, mock.AnythingOfType("time.Time"), jwtService.RefreshTokenTTL()).Return(errors.New("redis down")).Once()
+
+		err := authService.LogoutAll(userID)
+
+		assert.Error(t, err)
+	})
 }
 
 func TestNewAuthService(t *testing.T) {
 	mockRepo := new(MockUserRepository)
+	mockStore := new(MockRefreshTokenStore)
+	mockDenylist := new(MockAccessTokenDenylist)
 	jwtService := jwt.NewService("test-secret")
 
-	authService := NewAuthService(mockRepo, jwtService)
+	loginGuard := permissiveLoginGuard()
+	mfaService := noMFA()
+
+	authService := NewAuthService(mockRepo, jwtService, mockStore, mockDenylist, loginGuard, mfaService)
 
 	assert.NotNil(t, authService)
 	assert.Equal(t, mockRepo, authService.userRepo)
 	assert.Equal(t, jwtService, authService.jwtService)
-}
\ No newline at end of file
+	assert.Equal(t, mockStore, authService.tokenStore)
+	assert.Equal(t, mockDenylist, authService.denylist)
+	assert.Equal(t, loginGuard, authService.loginGuard)
+	assert.Equal(t, mfaService, authService.mfa)
+}
+
+func TestAuthService_LoginMFA(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	mockStore := new(MockRefreshTokenStore)
+	mockStore.On("Create", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockFactors := new(MockMFAFactorRepository)
+	mockPolicies := new(MockMFAPolicyRepository)
+	mockPolicies.On("FindByRole", domain.RoleViewer).Return(nil, errors.New("record not found"))
+	mockPolicies.On("FindByRole", domain.RoleAnalyst).Return(&domain.MFAPolicy{Role: domain.RoleAnalyst, Required: true}, nil)
+	jwtService := jwt.NewService("test-secret")
+	authService := NewAuthService(mockRepo, jwtService, mockStore, new(MockAccessTokenDenylist), permissiveLoginGuard(),
+		NewMFAService(mockFactors, mockPolicies, mockRepo, "Test"))
+
+	newUser := func(role domain.UserRole) *domain.User {
+		user, _ := domain.NewUser(string(role)+"@example.com", "password123", role)
+		mockRepo.On("FindByEmail", user.Email).Return(user, nil).Once()
+		mockRepo.On("FindByID", user.ID).Return(user, nil)
+		return user
+	}
+
+	t.Run("enrolled user gets a challenge instead of tokens", func(t *testing.T) {
+		user := newUser(domain.RoleViewer)
+		factor := confirmedFactor(user.ID)
+		mockFactors.On("FindByUserID", user.ID).Return(factor, nil)
+		mockFactors.On("Save", factor).Return(nil)
+
+		resp, err := authService.Login(LoginRequest{Email: user.Email, Password: "password123"})
+
+		assert.NoError(t, err)
+		assert.Nil(t, resp.AuthResponse)
+		assert.True(t, resp.MFARequired)
+		assert.False(t, resp.EnrollmentRequired)
+		assert.Equal(t, 300, resp.ExpiresIn)
+
+		_, err = authService.VerifyMFA(VerifyMFARequest{MFAToken: resp.MFAToken, Code: "000000"})
+		assert.Equal(t, ErrInvalidMFACode, err)
+
+		tokens, err := authService.VerifyMFA(VerifyMFARequest{MFAToken: resp.MFAToken, Code: currentCode(t, factor.Secret)})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, tokens.AccessToken)
+		assert.Equal(t, NewUserResponse(user), tokens.User)
+	})
+
+	t.Run("role requiring MFA forces enrollment", func(t *testing.T) {
+		user := newUser(domain.RoleAnalyst)
+		var pending *domain.MFAFactor
+		mockFactors.On("FindByUserID", user.ID).Return(nil, errors.New("record not found")).Twice()
+		mockFactors.On("Save", mock.MatchedBy(func(factor *domain.MFAFactor) bool {
+			if factor.UserID != user.ID {
+				return false
+			}
+			pending = factor
+			return true
+		})).Return(nil)
+
+		resp, err := authService.Login(LoginRequest{Email: user.Email, Password: "password123"})
+
+		assert.NoError(t, err)
+		assert.True(t, resp.EnrollmentRequired)
+
+		_, err = authService.VerifyMFA(VerifyMFARequest{MFAToken: resp.MFAToken, Code: "000000"})
+		assert.Equal(t, ErrInvalidMFAToken, err)
+
+		enrollment, err := authService.BeginMFAEnrollment(MFATokenRequest{MFAToken: resp.MFAToken})
+		assert.NoError(t, err)
+		assert.Equal(t, pending.Secret, enrollment.Secret)
+
+		mockFactors.On("FindByUserID", user.ID).Return(pending, nil).Once()
+		enrolled, err := authService.CompleteMFAEnrollment(VerifyMFARequest{MFAToken: resp.MFAToken, Code: currentCode(t, pending.Secret)})
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, enrolled.AccessToken)
+		assert.Len(t, enrolled.RecoveryCodes, recoveryCodeCount)
+	})
+
+	t.Run("rejects invalid MFA token", func(t *testing.T) {
+		_, err := authService.VerifyMFA(VerifyMFARequest{MFAToken: "invalid", Code: "123456"})
+
+		assert.Equal(t, ErrInvalidMFAToken, err)
+	})
+}
@@ -0,0 +1,177 @@
+package application
+
+import (
+	"errors"
+	"time"
+	"threat-intel-backend/domain"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrEntitlementRequired = errors.New("an active entitlement covering this intel is required")
+	ErrEntitlementNotFound = errors.New("entitlement not found")
+	ErrSeatNotFound        = errors.New("seat not found")
+)
+
+type EntitlementService struct {
+	entitlementRepo domain.EntitlementRepository
+	userRepo        domain.UserRepository
+	transactor      domain.Transactor
+}
+
+// EntitlementResponse lists the assigned seats of the entitlements the user
+// bought; seats of other buyers' entitlements are not shown.
+type EntitlementResponse struct {
+	*domain.Entitlement
+	Active bool                      `json:"active"`
+	Seats  []*domain.EntitlementSeat `json:"seats,omitempty"`
+}
+
+type EntitlementsResponse struct {
+	Tier         domain.Tier           `json:"tier"`
+	Entitlements []EntitlementResponse `json:"entitlements"`
+}
+
+type AssignSeatRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+func NewEntitlementService(entitlementRepo domain.EntitlementRepository, userRepo domain.UserRepository, transactor domain.Transactor) *EntitlementService {
+	return &EntitlementService{
+		entitlementRepo: entitlementRepo,
+		userRepo:        userRepo,
+		transactor:      transactor,
+	}
+}
+
+// ListEntitlements returns every entitlement the user bought or holds a seat
+// of, newest first, with the tier currently granted by the active ones.
+func (s *EntitlementService) ListEntitlements(userID uuid.UUID) (*EntitlementsResponse, error) {
+	if _, err := s.userRepo.FindByID(userID); err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	entitlements, err := s.entitlementRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	response := &EntitlementsResponse{
+		Tier:         domain.ActiveTier(entitlements, now),
+		Entitlements: make([]EntitlementResponse, 0, len(entitlements)),
+	}
+	for _, entitlement := range entitlements {
+		item := EntitlementResponse{
+			Entitlement: entitlement,
+			Active:      entitlement.IsActive(now),
+		}
+		if entitlement.UserID == userID && entitlement.Quantity > 1 {
+			if item.Seats, err = s.entitlementRepo.FindSeats(entitlement.ID); err != nil {
+				return nil, err
+			}
+		}
+		response.Entitlements = append(response.Entitlements, item)
+	}
+	return response, nil
+}
+
+// AssignSeat gives the user with the requested email one of the unassigned
+// seats of an entitlement the owner bought. The entitlement is locked while
+// its seats are counted, so concurrent assignments cannot exceed its
+// quantity.
+func (s *EntitlementService) AssignSeat(ownerID, entitlementID uuid.UUID, req AssignSeatRequest) (*domain.EntitlementSeat, error) {
+	user, err := s.userRepo.FindByEmail(req.Email)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	var seat *domain.EntitlementSeat
+	err = s.transactor.Transaction(func(repos domain.Repositories) error {
+		entitlement, err := repos.Entitlements.FindByIDForUpdate(entitlementID)
+		if err != nil || entitlement.UserID != ownerID {
+			return ErrEntitlementNotFound
+		}
+
+		seats, err := repos.Entitlements.FindSeats(entitlement.ID)
+		if err != nil {
+			return err
+		}
+
+		seat, err = entitlement.AssignSeat(user.ID, seats)
+		if err != nil {
+			return err
+		}
+		return repos.Entitlements.SaveSeat(seat)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return seat, nil
+}
+
+// RemoveSeat takes a seat of an entitlement the owner bought away from the
+// user holding it, freeing it for someone else.
+func (s *EntitlementService) RemoveSeat(ownerID, entitlementID, userID uuid.UUID) error {
+	return s.transactor.Transaction(func(repos domain.Repositories) error {
+		entitlement, err := repos.Entitlements.FindByIDForUpdate(entitlementID)
+		if err != nil || entitlement.UserID != ownerID {
+			return ErrEntitlementNotFound
+		}
+
+		seats, err := repos.Entitlements.FindSeats(entitlement.ID)
+		if err != nil {
+			return err
+		}
+		for _, seat := range seats {
+			if seat.UserID == userID {
+				return repos.Entitlements.DeleteSeat(entitlement.ID, userID)
+			}
+		}
+		return ErrSeatNotFound
+	})
+}
+
+// accessTier returns the tier of intel the user can read: the highest tier of
+// the active entitlements they bought or hold a seat of, or every tier for
+// analysts and admins.
+func accessTier(userRepo domain.UserRepository, entitlementRepo domain.EntitlementRepository, userID uuid.UUID) (domain.Tier, error) {
+	user, err := userRepo.FindByID(userID)
+	if err != nil || !user.IsActive {
+		return domain.TierNone, ErrInsufficientPermissions
+	}
+	if user.HasPermission(domain.RoleAnalyst) {
+		return domain.TierEnterprise, nil
+	}
+
+	entitlements, err := entitlementRepo.FindByUserID(userID)
+	if err != nil {
+		return domain.TierNone, err
+	}
+	return domain.ActiveTier(entitlements, time.Now()), nil
+}
+
+// requireEntitlement is accessTier for endpoints that serve nothing without
+// an active entitlement.
+func requireEntitlement(userRepo domain.UserRepository, entitlementRepo domain.EntitlementRepository, userID uuid.UUID) (domain.Tier, error) {
+	tier, err := accessTier(userRepo, entitlementRepo, userID)
+	if err != nil {
+		return domain.TierNone, err
+	}
+	if tier == domain.TierNone {
+		return domain.TierNone, ErrEntitlementRequired
+	}
+	return tier, nil
+}
+
+// readableTypes narrows a requested indicator type to the ones the tier can
+// read. A nil result means every type.
+func readableTypes(tier domain.Tier, requested domain.IndicatorType) ([]domain.IndicatorType, error) {
+	if requested == "" {
+		return tier.IndicatorTypes(), nil
+	}
+	if !tier.AllowsIndicatorType(requested) {
+		return nil, ErrEntitlementRequired
+	}
+	return nil, nil
+}
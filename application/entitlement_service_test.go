@@ -0,0 +1,241 @@
+package application
+
+import (
+	"errors"
+	"testing"
+	"threat-intel-backend/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockEntitlementRepository struct {
+	mock.Mock
+}
+
+func (m *MockEntitlementRepository) Save(entitlement *domain.Entitlement) error {
+	args := m.Called(entitlement)
+	return args.Error(0)
+}
+
+func (m *MockEntitlementRepository) FindByOrderID(orderID uuid.UUID) (*domain.Entitlement, error) {
+	args := m.Called(orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Entitlement), args.Error(1)
+}
+
+func (m *MockEntitlementRepository) FindByUserID(userID uuid.UUID) ([]*domain.Entitlement, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Entitlement), args.Error(1)
+}
+
+func (m *MockEntitlementRepository) FindByIDForUpdate(id uuid.UUID) (*domain.Entitlement, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Entitlement), args.Error(1)
+}
+
+func (m *MockEntitlementRepository) SaveSeat(seat *domain.EntitlementSeat) error {
+	args := m.Called(seat)
+	return args.Error(0)
+}
+
+func (m *MockEntitlementRepository) FindSeats(entitlementID uuid.UUID) ([]*domain.EntitlementSeat, error) {
+	args := m.Called(entitlementID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.EntitlementSeat), args.Error(1)
+}
+
+func (m *MockEntitlementRepository) DeleteSeat(entitlementID, userID uuid.UUID) error {
+	args := m.Called(entitlementID, userID)
+	return args.Error(0)
+}
+
+func (m *MockEntitlementRepository) DeleteSeatsByUserID(userID uuid.UUID) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+// activeEntitlement returns an entitlement to tier that is active now.
+func activeEntitlement(userID uuid.UUID, tier domain.Tier) *domain.Entitlement {
+	return &domain.Entitlement{
+		ID:       uuid.New(),
+		UserID:   userID,
+		Tier:     tier,
+		StartsAt: time.Now().Add(-time.Hour),
+		EndsAt:   time.Now().Add(time.Hour),
+	}
+}
+
+func TestEntitlementService_ListEntitlements(t *testing.T) {
+	mockEntitlements := new(MockEntitlementRepository)
+	mockUsers := new(MockUserRepository)
+	service := NewEntitlementService(mockEntitlements, mockUsers, &fakeTransactor{})
+	viewer, _ := domain.NewUser("viewer@example.com", "password123", domain.RoleViewer)
+	mockUsers.On("FindByID", viewer.ID).Return(viewer, nil)
+
+	held := activeEntitlement(uuid.New(), domain.TierPremium)
+	held.Quantity = 5
+	active := activeEntitlement(viewer.ID, domain.TierBasic)
+	active.Quantity = 3
+	expired := &domain.Entitlement{
+		ID:       uuid.New(),
+		UserID:   viewer.ID,
+		Tier:     domain.TierEnterprise,
+		StartsAt: time.Now().Add(-2 * domain.EntitlementTerm),
+		EndsAt:   time.Now().Add(-domain.EntitlementTerm),
+	}
+	seat := &domain.EntitlementSeat{EntitlementID: active.ID, UserID: uuid.New()}
+	mockEntitlements.On("FindByUserID", viewer.ID).Return([]*domain.Entitlement{held, active, expired}, nil)
+	mockEntitlements.On("FindSeats", active.ID).Return([]*domain.EntitlementSeat{seat}, nil)
+
+	response, err := service.ListEntitlements(viewer.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.TierPremium, response.Tier)
+	assert.Len(t, response.Entitlements, 3)
+	assert.True(t, response.Entitlements[0].Active)
+	assert.Empty(t, response.Entitlements[0].Seats)
+	assert.Equal(t, []*domain.EntitlementSeat{seat}, response.Entitlements[1].Seats)
+	assert.False(t, response.Entitlements[2].Active)
+	mockEntitlements.AssertNotCalled(t, "FindSeats", held.ID)
+}
+
+func TestEntitlementService_AssignSeat(t *testing.T) {
+	mockEntitlements := new(MockEntitlementRepository)
+	mockUsers := new(MockUserRepository)
+	transactor := &fakeTransactor{repos: domain.Repositories{Entitlements: mockEntitlements}}
+	service := NewEntitlementService(mockEntitlements, mockUsers, transactor)
+	owner, _ := domain.NewUser("owner@example.com", "password123", domain.RoleViewer)
+	colleague, _ := domain.NewUser("colleague@example.com", "password123", domain.RoleViewer)
+	mockUsers.On("FindByEmail", colleague.Email).Return(colleague, nil)
+	mockUsers.On("FindByEmail", owner.Email).Return(owner, nil)
+	mockUsers.On("FindByEmail", "nobody@example.com").Return(nil, errors.New("record not found"))
+	request := AssignSeatRequest{Email: colleague.Email}
+
+	t.Run("assigns a free seat", func(t *testing.T) {
+		entitlement := activeEntitlement(owner.ID, domain.TierBasic)
+		entitlement.Quantity = 2
+		mockEntitlements.On("FindByIDForUpdate", entitlement.ID).Return(entitlement, nil).Once()
+		mockEntitlements.On("FindSeats", entitlement.ID).Return([]*domain.EntitlementSeat{}, nil).Once()
+		mockEntitlements.On("SaveSeat", mock.MatchedBy(func(seat *domain.EntitlementSeat) bool {
+			return seat.EntitlementID == entitlement.ID && seat.UserID == colleague.ID
+		})).Return(nil).Once()
+
+		seat, err := service.AssignSeat(owner.ID, entitlement.ID, request)
+
+		assert.NoError(t, err)
+		assert.Equal(t, colleague.ID, seat.UserID)
+		mockEntitlements.AssertExpectations(t)
+	})
+
+	t.Run("rejects assignments beyond the quantity", func(t *testing.T) {
+		entitlement := activeEntitlement(owner.ID, domain.TierBasic)
+		entitlement.Quantity = 2
+		taken := &domain.EntitlementSeat{EntitlementID: entitlement.ID, UserID: uuid.New()}
+		mockEntitlements.On("FindByIDForUpdate", entitlement.ID).Return(entitlement, nil).Once()
+		mockEntitlements.On("FindSeats", entitlement.ID).Return([]*domain.EntitlementSeat{taken}, nil).Once()
+
+		_, err := service.AssignSeat(owner.ID, entitlement.ID, request)
+
+		assert.ErrorIs(t, err, domain.ErrNoSeatsLeft)
+	})
+
+	t.Run("rejects the buyer", func(t *testing.T) {
+		entitlement := activeEntitlement(owner.ID, domain.TierBasic)
+		entitlement.Quantity = 2
+		mockEntitlements.On("FindByIDForUpdate", entitlement.ID).Return(entitlement, nil).Once()
+		mockEntitlements.On("FindSeats", entitlement.ID).Return([]*domain.EntitlementSeat{}, nil).Once()
+
+		_, err := service.AssignSeat(owner.ID, entitlement.ID, AssignSeatRequest{Email: owner.Email})
+
+		assert.ErrorIs(t, err, domain.ErrSeatAlreadyAssigned)
+	})
+
+	t.Run("hides entitlements of other users", func(t *testing.T) {
+		entitlement := activeEntitlement(uuid.New(), domain.TierBasic)
+		entitlement.Quantity = 2
+		mockEntitlements.On("FindByIDForUpdate", entitlement.ID).Return(entitlement, nil).Once()
+
+		_, err := service.AssignSeat(owner.ID, entitlement.ID, request)
+
+		assert.ErrorIs(t, err, ErrEntitlementNotFound)
+	})
+
+	t.Run("unknown email", func(t *testing.T) {
+		_, err := service.AssignSeat(owner.ID, uuid.New(), AssignSeatRequest{Email: "nobody@example.com"})
+
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+}
+
+func TestEntitlementService_RemoveSeat(t *testing.T) {
+	mockEntitlements := new(MockEntitlementRepository)
+	transactor := &fakeTransactor{repos: domain.Repositories{Entitlements: mockEntitlements}}
+	service := NewEntitlementService(mockEntitlements, new(MockUserRepository), transactor)
+	ownerID := uuid.New()
+	entitlement := activeEntitlement(ownerID, domain.TierBasic)
+	entitlement.Quantity = 2
+	seat := &domain.EntitlementSeat{EntitlementID: entitlement.ID, UserID: uuid.New()}
+	mockEntitlements.On("FindByIDForUpdate", entitlement.ID).Return(entitlement, nil)
+	mockEntitlements.On("FindSeats", entitlement.ID).Return([]*domain.EntitlementSeat{seat}, nil)
+
+	t.Run("frees the seat", func(t *testing.T) {
+		mockEntitlements.On("DeleteSeat", entitlement.ID, seat.UserID).Return(nil).Once()
+
+		err := service.RemoveSeat(ownerID, entitlement.ID, seat.UserID)
+
+		assert.NoError(t, err)
+		mockEntitlements.AssertExpectations(t)
+	})
+
+	t.Run("unknown seat", func(t *testing.T) {
+		err := service.RemoveSeat(ownerID, entitlement.ID, uuid.New())
+
+		assert.ErrorIs(t, err, ErrSeatNotFound)
+	})
+
+	t.Run("hides entitlements of other users", func(t *testing.T) {
+		err := service.RemoveSeat(uuid.New(), entitlement.ID, seat.UserID)
+
+		assert.ErrorIs(t, err, ErrEntitlementNotFound)
+	})
+}
+
+func TestAccessTier(t *testing.T) {
+	mockEntitlements := new(MockEntitlementRepository)
+	mockUsers := new(MockUserRepository)
+	analyst, _ := domain.NewUser("analyst@example.com", "password123", domain.RoleAnalyst)
+	viewer, _ := domain.NewUser("viewer@example.com", "password123", domain.RoleViewer)
+	inactive, _ := domain.NewUser("inactive@example.com", "password123", domain.RoleViewer)
+	inactive.IsActive = false
+	mockUsers.On("FindByID", analyst.ID).Return(analyst, nil)
+	mockUsers.On("FindByID", viewer.ID).Return(viewer, nil)
+	mockUsers.On("FindByID", inactive.ID).Return(inactive, nil)
+	mockEntitlements.On("FindByUserID", viewer.ID).Return([]*domain.Entitlement{}, nil)
+
+	tier, err := accessTier(mockUsers, mockEntitlements, analyst.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.TierEnterprise, tier)
+
+	tier, err = accessTier(mockUsers, mockEntitlements, viewer.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.TierNone, tier)
+
+	_, err = requireEntitlement(mockUsers, mockEntitlements, viewer.ID)
+	assert.Equal(t, ErrEntitlementRequired, err)
+
+	_, err = accessTier(mockUsers, mockEntitlements, inactive.ID)
+	assert.Equal(t, ErrInsufficientPermissions, err)
+}
@@ -0,0 +1,234 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"threat-intel-backend/domain"
+	"github.com/google/uuid"
+)
+
+// feedRunHistory is how many recent runs are shown with a feed.
+const feedRunHistory = 20
+
+var (
+	ErrFeedNotFound      = errors.New("feed not found")
+	ErrFeedExists        = errors.New("feed already exists")
+	ErrFeedPaused        = errors.New("feed is paused")
+	ErrUnsupportedFormat = errors.New("no source for feed format")
+)
+
+// JobLock makes sure a single replica runs a periodic job at a time. The
+// lock is a lease: Refresh reports false once it was lost.
+type JobLock interface {
+	Acquire(ctx context.Context) (bool, error)
+	Refresh(ctx context.Context) (bool, error)
+	Release(ctx context.Context) error
+}
+
+type FeedService struct {
+	feedRepo      domain.FeedRepository
+	runRepo       domain.FeedRunRepository
+	indicatorRepo domain.IndicatorRepository
+	sightingRepo  domain.SightingRepository
+	userRepo      domain.UserRepository
+	sources       map[domain.FeedFormat]domain.FeedSource
+	lock          JobLock
+	lookupCache   domain.LookupCache
+}
+
+type CreateFeedRequest struct {
+	Name     string             `json:"name" binding:"required"`
+	Format   domain.FeedFormat  `json:"format" binding:"required"`
+	URL      string             `json:"url" binding:"required"`
+	Schedule string             `json:"schedule" binding:"required"`
+	Options  domain.FeedOptions `json:"options"`
+}
+
+type FeedDetailResponse struct {
+	Feed *domain.Feed      `json:"feed"`
+	Runs []*domain.FeedRun `json:"runs"`
+}
+
+func NewFeedService(feedRepo domain.FeedRepository, runRepo domain.FeedRunRepository, indicatorRepo domain.IndicatorRepository, sightingRepo domain.SightingRepository, userRepo domain.UserRepository, sources map[domain.FeedFormat]domain.FeedSource, lock JobLock, lookupCache domain.LookupCache) *FeedService {
+	return &FeedService{
+		feedRepo:      feedRepo,
+		runRepo:       runRepo,
+		indicatorRepo: indicatorRepo,
+		sightingRepo:  sightingRepo,
+		userRepo:      userRepo,
+		sources:       sources,
+		lock:          lock,
+		lookupCache:   lookupCache,
+	}
+}
+
+func (s *FeedService) ListFeeds(actorID uuid.UUID) ([]*domain.Feed, error) {
+	if err := requireRole(s.userRepo, actorID, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	return s.feedRepo.List()
+}
+
+// CreateFeed adds a feed, which is pulled on the next scheduler tick.
+func (s *FeedService) CreateFeed(actorID uuid.UUID, req CreateFeedRequest) (*domain.Feed, error) {
+	if err := requireRole(s.userRepo, actorID, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	feed, err := domain.NewFeed(req.Name, req.Format, req.URL, req.Schedule, req.Options)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := s.sources[feed.Format]; !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedFormat, feed.Format)
+	}
+	if _, err := s.feedRepo.FindByName(feed.Name); err == nil {
+		return nil, ErrFeedExists
+	}
+
+	feed.CreatedBy = actorID
+	if err := s.feedRepo.Save(feed); err != nil {
+		return nil, err
+	}
+	return feed, nil
+}
+
+// GetFeed returns a feed with its most recent runs.
+func (s *FeedService) GetFeed(actorID, id uuid.UUID) (*FeedDetailResponse, error) {
+	if err := requireRole(s.userRepo, actorID, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	feed, err := s.findFeed(id)
+	if err != nil {
+		return nil, err
+	}
+	runs, err := s.runRepo.ListByFeedID(feed.ID, feedRunHistory)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FeedDetailResponse{Feed: feed, Runs: runs}, nil
+}
+
+func (s *FeedService) PauseFeed(actorID, id uuid.UUID) (*domain.Feed, error) {
+	return s.updateFeed(actorID, id, func(feed *domain.Feed) error {
+		feed.Pause()
+		return nil
+	})
+}
+
+func (s *FeedService) ResumeFeed(actorID, id uuid.UUID) (*domain.Feed, error) {
+	return s.updateFeed(actorID, id, func(feed *domain.Feed) error {
+		feed.Resume()
+		return nil
+	})
+}
+
+// TriggerFeed makes a feed due, so that it is pulled on the next scheduler
+// tick rather than at its scheduled time.
+func (s *FeedService) TriggerFeed(actorID, id uuid.UUID) (*domain.Feed, error) {
+	return s.updateFeed(actorID, id, func(feed *domain.Feed) error {
+		if feed.Paused {
+			return ErrFeedPaused
+		}
+		feed.Trigger()
+		return nil
+	})
+}
+
+// RunDue pulls the feeds that are due, unless another replica holds the lock.
+// The lock is refreshed after every feed and the remaining feeds are left for
+// the next tick once it is lost. A failing feed is recorded in its run
+// history and does not stop the others.
+func (s *FeedService) RunDue(ctx context.Context) error {
+	acquired, err := s.lock.Acquire(ctx)
+	if err != nil || !acquired {
+		return err
+	}
+	defer s.lock.Release(context.Background())
+
+	feeds, err := s.feedRepo.ListDue(time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, feed := range feeds {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := s.runFeed(ctx, feed); err != nil {
+			return err
+		}
+		if held, err := s.lock.Refresh(ctx); err != nil || !held {
+			return err
+		}
+	}
+	return nil
+}
+
+// runFeed pulls a feed and records the run. Only failing to record it is an
+// error.
+func (s *FeedService) runFeed(ctx context.Context, feed *domain.Feed) error {
+	run := domain.NewFeedRun(feed.ID)
+
+	var result *domain.FeedPullResult
+	source, ok := s.sources[feed.Format]
+	if !ok {
+		err := fmt.Errorf("%w %q", ErrUnsupportedFormat, feed.Format)
+		run.Finish(nil, err)
+	} else {
+		var written []domain.IndicatorKey
+		var err error
+		result, err = source.Pull(ctx, feed, func(indicator *domain.Indicator, rawPayload string) error {
+			report := IndicatorReport{Indicator: indicator, Source: feed.Name, RawPayload: rawPayload}
+			stored, created, err := upsertIndicator(s.indicatorRepo, s.sightingRepo, report, feed.CreatedBy)
+			if err != nil {
+				return err
+			}
+			written = append(written, stored.Key())
+			if created {
+				run.Created++
+			} else {
+				run.Updated++
+			}
+			return nil
+		})
+		forgetLookups(ctx, s.lookupCache, written)
+		run.Finish(result, err)
+	}
+
+	feed.RecordRun(run, result)
+	if err := s.runRepo.Save(run); err != nil {
+		return err
+	}
+	return s.feedRepo.Save(feed)
+}
+
+func (s *FeedService) updateFeed(actorID, id uuid.UUID, update func(*domain.Feed) error) (*domain.Feed, error) {
+	if err := requireRole(s.userRepo, actorID, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	feed, err := s.findFeed(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := update(feed); err != nil {
+		return nil, err
+	}
+	if err := s.feedRepo.Save(feed); err != nil {
+		return nil, err
+	}
+	return feed, nil
+}
+
+func (s *FeedService) findFeed(id uuid.UUID) (*domain.Feed, error) {
+	feed, err := s.feedRepo.FindByID(id)
+	if err != nil {
+		return nil, ErrFeedNotFound
+	}
+	return feed, nil
+}
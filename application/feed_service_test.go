@@ -0,0 +1,324 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"threat-intel-backend/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockFeedRepository struct {
+	mock.Mock
+}
+
+func (m *MockFeedRepository) Save(feed *domain.Feed) error {
+	args := m.Called(feed)
+	return args.Error(0)
+}
+
+func (m *MockFeedRepository) FindByID(id uuid.UUID) (*domain.Feed, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Feed), args.Error(1)
+}
+
+func (m *MockFeedRepository) FindByName(name string) (*domain.Feed, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Feed), args.Error(1)
+}
+
+func (m *MockFeedRepository) List() ([]*domain.Feed, error) {
+	args := m.Called()
+	return args.Get(0).([]*domain.Feed), args.Error(1)
+}
+
+func (m *MockFeedRepository) ListDue(now time.Time) ([]*domain.Feed, error) {
+	args := m.Called(now)
+	return args.Get(0).([]*domain.Feed), args.Error(1)
+}
+
+type MockFeedRunRepository struct {
+	mock.Mock
+}
+
+func (m *MockFeedRunRepository) Save(run *domain.FeedRun) error {
+	args := m.Called(run)
+	return args.Error(0)
+}
+
+func (m *MockFeedRunRepository) ListByFeedID(feedID uuid.UUID, limit int) ([]*domain.FeedRun, error) {
+	args := m.Called(feedID, limit)
+	return args.Get(0).([]*domain.FeedRun), args.Error(1)
+}
+
+// MockFeedSource emits its indicators and returns its result.
+type MockFeedSource struct {
+	mock.Mock
+	indicators []*domain.Indicator
+}
+
+func (m *MockFeedSource) Pull(ctx context.Context, feed *domain.Feed, emit domain.FeedEmitFunc) (*domain.FeedPullResult, error) {
+	args := m.Called(feed)
+	for _, indicator := range m.indicators {
+		if err := emit(indicator, indicator.Value); err != nil {
+			return &domain.FeedPullResult{}, err
+		}
+	}
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.FeedPullResult), args.Error(1)
+}
+
+type MockJobLock struct {
+	mock.Mock
+}
+
+func (m *MockJobLock) Acquire(ctx context.Context) (bool, error) {
+	args := m.Called()
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockJobLock) Refresh(ctx context.Context) (bool, error) {
+	args := m.Called()
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockJobLock) Release(ctx context.Context) error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+type feedServiceMocks struct {
+	feeds      *MockFeedRepository
+	runs       *MockFeedRunRepository
+	indicators *MockIndicatorRepository
+	sightings  *MockSightingRepository
+	source     *MockFeedSource
+	lock       *MockJobLock
+	cache      *memoryLookupCache
+}
+
+func setupFeedService() (*FeedService, *feedServiceMocks, *domain.User, *domain.User) {
+	mocks := &feedServiceMocks{
+		feeds:      new(MockFeedRepository),
+		runs:       new(MockFeedRunRepository),
+		indicators: new(MockIndicatorRepository),
+		sightings:  new(MockSightingRepository),
+		source:     new(MockFeedSource),
+		lock:       new(MockJobLock),
+		cache:      newMemoryLookupCache(),
+	}
+	mockUsers := new(MockUserRepository)
+	admin, _ := domain.NewUser("admin@example.com", "password123", domain.RoleAdmin)
+	analyst, _ := domain.NewUser("analyst@example.com", "password123", domain.RoleAnalyst)
+	mockUsers.On("FindByID", admin.ID).Return(admin, nil)
+	mockUsers.On("FindByID", analyst.ID).Return(analyst, nil)
+
+	sources := map[domain.FeedFormat]domain.FeedSource{domain.FeedPlainText: mocks.source}
+	service := NewFeedService(mocks.feeds, mocks.runs, mocks.indicators, mocks.sightings, mockUsers, sources, mocks.lock, mocks.cache)
+	return service, mocks, admin, analyst
+}
+
+func newTestFeed() *domain.Feed {
+	feed, _ := domain.NewFeed("blocklist", domain.FeedPlainText, "https://feeds.example.com/ips.txt", "@hourly", domain.FeedOptions{})
+	return feed
+}
+
+func TestFeedService_CreateFeed(t *testing.T) {
+	service, mocks, admin, analyst := setupFeedService()
+	req := CreateFeedRequest{Name: "blocklist", Format: domain.FeedPlainText, URL: "https://feeds.example.com/ips.txt", Schedule: "@hourly"}
+
+	t.Run("creates a due feed", func(t *testing.T) {
+		mocks.feeds.On("FindByName", "blocklist").Return(nil, errors.New("record not found")).Once()
+		mocks.feeds.On("Save", mock.AnythingOfType("*domain.Feed")).Return(nil).Once()
+
+		feed, err := service.CreateFeed(admin.ID, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, admin.ID, feed.CreatedBy)
+		assert.True(t, feed.IsDue(time.Now()))
+		mocks.feeds.AssertExpectations(t)
+	})
+
+	t.Run("rejects duplicate names", func(t *testing.T) {
+		mocks.feeds.On("FindByName", "blocklist").Return(newTestFeed(), nil).Once()
+
+		_, err := service.CreateFeed(admin.ID, req)
+
+		assert.Equal(t, ErrFeedExists, err)
+	})
+
+	t.Run("rejects formats without a source", func(t *testing.T) {
+		stix := req
+		stix.Format = domain.FeedSTIX
+
+		_, err := service.CreateFeed(admin.ID, stix)
+
+		assert.True(t, errors.Is(err, ErrUnsupportedFormat))
+	})
+
+	t.Run("rejects invalid schedules", func(t *testing.T) {
+		invalid := req
+		invalid.Schedule = "10s"
+
+		_, err := service.CreateFeed(admin.ID, invalid)
+
+		assert.True(t, errors.Is(err, domain.ErrInvalidFeedSchedule))
+	})
+
+	t.Run("requires admin", func(t *testing.T) {
+		_, err := service.CreateFeed(analyst.ID, req)
+
+		assert.Equal(t, ErrInsufficientPermissions, err)
+	})
+}
+
+func TestFeedService_GetFeed(t *testing.T) {
+	service, mocks, admin, _ := setupFeedService()
+	feed := newTestFeed()
+	runs := []*domain.FeedRun{domain.NewFeedRun(feed.ID)}
+	mocks.feeds.On("FindByID", feed.ID).Return(feed, nil)
+	mocks.runs.On("ListByFeedID", feed.ID, feedRunHistory).Return(runs, nil)
+
+	resp, err := service.GetFeed(admin.ID, feed.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, feed, resp.Feed)
+	assert.Equal(t, runs, resp.Runs)
+
+	missing := uuid.New()
+	mocks.feeds.On("FindByID", missing).Return(nil, errors.New("record not found"))
+	_, err = service.GetFeed(admin.ID, missing)
+	assert.Equal(t, ErrFeedNotFound, err)
+}
+
+func TestFeedService_PauseResumeTrigger(t *testing.T) {
+	service, mocks, admin, _ := setupFeedService()
+	feed := newTestFeed()
+	feed.NextRunAt = time.Now().Add(time.Hour)
+	mocks.feeds.On("FindByID", feed.ID).Return(feed, nil)
+	mocks.feeds.On("Save", feed).Return(nil)
+
+	paused, err := service.PauseFeed(admin.ID, feed.ID)
+	assert.NoError(t, err)
+	assert.True(t, paused.Paused)
+
+	_, err = service.TriggerFeed(admin.ID, feed.ID)
+	assert.Equal(t, ErrFeedPaused, err)
+
+	resumed, err := service.ResumeFeed(admin.ID, feed.ID)
+	assert.NoError(t, err)
+	assert.False(t, resumed.Paused)
+
+	feed.NextRunAt = time.Now().Add(time.Hour)
+	triggered, err := service.TriggerFeed(admin.ID, feed.ID)
+	assert.NoError(t, err)
+	assert.True(t, triggered.IsDue(time.Now()))
+}
+
+func TestFeedService_RunDue(t *testing.T) {
+	t.Run("pulls due feeds and records the run", func(t *testing.T) {
+		service, mocks, _, _ := setupFeedService()
+		feed := newTestFeed()
+		fresh, _ := domain.NewIndicator(domain.IndicatorIPv4, "192.0.2.1", 50, "")
+		known, _ := domain.NewIndicator(domain.IndicatorIPv4, "192.0.2.2", 50, "")
+		known.Tags = domain.Tags{"scanner"}
+		stored, _ := domain.NewIndicator(domain.IndicatorIPv4, "192.0.2.2", 50, "")
+		stored.Tags = domain.Tags{"botnet"}
+		mocks.source.indicators = []*domain.Indicator{fresh, known}
+		mocks.cache.entries[fresh.Key()] = nil
+		mocks.cache.entries[stored.Key()] = &domain.LookupMatch{IndicatorID: stored.ID, Tags: domain.Tags{"botnet"}}
+
+		mocks.lock.On("Acquire").Return(true, nil)
+		mocks.lock.On("Refresh").Return(true, nil)
+		mocks.lock.On("Release").Return(nil)
+		mocks.feeds.On("ListDue", mock.AnythingOfType("time.Time")).Return([]*domain.Feed{feed}, nil)
+		mocks.source.On("Pull", feed).Return(&domain.FeedPullResult{ETag: `"v2"`, Skipped: 1, Errors: []string{"line 3: invalid"}}, nil)
+		mocks.indicators.On("FindByValue", domain.IndicatorIPv4, "192.0.2.1").Return(nil, errors.New("record not found"))
+		mocks.indicators.On("FindByStixID", fresh.StixID).Return(nil, errors.New("record not found"))
+		mocks.indicators.On("FindByValue", domain.IndicatorIPv4, "192.0.2.2").Return(stored, nil)
+		mocks.indicators.On("Save", fresh).Return(nil)
+		mocks.indicators.On("Save", stored).Return(nil)
+		mocks.sightings.On("FindByIndicatorAndSource", mock.Anything, "blocklist").Return(nil, errors.New("record not found"))
+		mocks.sightings.On("Save", mock.AnythingOfType("*domain.Sighting")).Return(nil).Twice()
+		var run *domain.FeedRun
+		mocks.runs.On("Save", mock.AnythingOfType("*domain.FeedRun")).Run(func(args mock.Arguments) {
+			run = args.Get(0).(*domain.FeedRun)
+		}).Return(nil)
+		mocks.feeds.On("Save", feed).Return(nil)
+
+		err := service.RunDue(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.FeedRunSucceeded, run.Status)
+		assert.Equal(t, 1, run.Created)
+		assert.Equal(t, 1, run.Updated)
+		assert.Equal(t, 1, run.Skipped)
+		assert.Equal(t, "blocklist", fresh.Source)
+		assert.Equal(t, domain.Tags{"botnet", "scanner"}, stored.Tags)
+		assert.Empty(t, mocks.cache.entries, "lookups see the pulled indicators")
+		assert.Equal(t, `"v2"`, feed.ETag)
+		assert.False(t, feed.IsDue(time.Now()))
+		mocks.lock.AssertExpectations(t)
+		mocks.indicators.AssertExpectations(t)
+		mocks.sightings.AssertExpectations(t)
+	})
+
+	t.Run("failures are recorded on the feed", func(t *testing.T) {
+		service, mocks, _, _ := setupFeedService()
+		feed := newTestFeed()
+
+		mocks.lock.On("Acquire").Return(true, nil)
+		mocks.lock.On("Refresh").Return(true, nil)
+		mocks.lock.On("Release").Return(nil)
+		mocks.feeds.On("ListDue", mock.AnythingOfType("time.Time")).Return([]*domain.Feed{feed}, nil)
+		mocks.source.On("Pull", feed).Return(nil, errors.New("connection refused"))
+		mocks.runs.On("Save", mock.AnythingOfType("*domain.FeedRun")).Return(nil)
+		mocks.feeds.On("Save", feed).Return(nil)
+
+		err := service.RunDue(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, "connection refused", feed.LastError)
+		assert.Equal(t, 1, feed.ConsecutiveFailures)
+	})
+
+	t.Run("stops once the lock is lost", func(t *testing.T) {
+		service, mocks, _, _ := setupFeedService()
+		first, second := newTestFeed(), newTestFeed()
+
+		mocks.lock.On("Acquire").Return(true, nil)
+		mocks.lock.On("Refresh").Return(false, nil)
+		mocks.lock.On("Release").Return(nil)
+		mocks.feeds.On("ListDue", mock.AnythingOfType("time.Time")).Return([]*domain.Feed{first, second}, nil)
+		mocks.source.On("Pull", first).Return(&domain.FeedPullResult{NotModified: true}, nil)
+		mocks.runs.On("Save", mock.AnythingOfType("*domain.FeedRun")).Return(nil)
+		mocks.feeds.On("Save", first).Return(nil)
+
+		err := service.RunDue(context.Background())
+
+		assert.NoError(t, err)
+		mocks.source.AssertNotCalled(t, "Pull", second)
+	})
+
+	t.Run("does nothing while another replica holds the lock", func(t *testing.T) {
+		service, mocks, _, _ := setupFeedService()
+		mocks.lock.On("Acquire").Return(false, nil)
+
+		err := service.RunDue(context.Background())
+
+		assert.NoError(t, err)
+		mocks.feeds.AssertNotCalled(t, "ListDue", mock.Anything)
+	})
+}
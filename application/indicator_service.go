@@ -0,0 +1,261 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"threat-intel-backend/domain"
+	"github.com/google/uuid"
+)
+
+// manualSource is the source of indicators analysts enter without naming
+// one.
+const manualSource = "manual"
+
+var (
+	ErrIndicatorNotFound = errors.New("indicator not found")
+	ErrIndicatorExists   = errors.New("indicator already exists")
+)
+
+type IndicatorService struct {
+	indicatorRepo   domain.IndicatorRepository
+	sightingRepo    domain.SightingRepository
+	userRepo        domain.UserRepository
+	entitlementRepo domain.EntitlementRepository
+	lookupCache     domain.LookupCache
+}
+
+type CreateIndicatorRequest struct {
+	Type       domain.IndicatorType `json:"type" binding:"required"`
+	Value      string               `json:"value" binding:"required"`
+	Confidence int                  `json:"confidence" binding:"min=0,max=100"`
+	Severity   domain.Severity      `json:"severity"`
+	Tags       []string             `json:"tags"`
+	Source     string               `json:"source"`
+	FirstSeen  *time.Time           `json:"first_seen"`
+	LastSeen   *time.Time           `json:"last_seen"`
+	ExpiresAt  *time.Time           `json:"expires_at"`
+}
+
+// UpdateIndicatorRequest only touches the fields that are present. Type and
+// value identify the indicator and cannot be changed.
+type UpdateIndicatorRequest struct {
+	Confidence *int             `json:"confidence" binding:"omitempty,min=0,max=100"`
+	Severity   *domain.Severity `json:"severity"`
+	Tags       *[]string        `json:"tags"`
+	Source     *string          `json:"source"`
+	LastSeen   *time.Time       `json:"last_seen"`
+	ExpiresAt  *time.Time       `json:"expires_at"`
+}
+
+type ListIndicatorsRequest struct {
+	Type           domain.IndicatorType `form:"type"`
+	Severity       domain.Severity      `form:"severity"`
+	Tag            string               `form:"tag"`
+	Source         string               `form:"source"`
+	IncludeExpired bool                 `form:"include_expired"`
+	Page           int                  `form:"page" binding:"omitempty,min=1"`
+	PageSize       int                  `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+type IndicatorListResponse struct {
+	Indicators []*domain.Indicator `json:"indicators"`
+	Total      int64               `json:"total"`
+	Page       int                 `json:"page"`
+	PageSize   int                 `json:"page_size"`
+}
+
+func NewIndicatorService(indicatorRepo domain.IndicatorRepository, sightingRepo domain.SightingRepository, userRepo domain.UserRepository, entitlementRepo domain.EntitlementRepository, lookupCache domain.LookupCache) *IndicatorService {
+	return &IndicatorService{
+		indicatorRepo:   indicatorRepo,
+		sightingRepo:    sightingRepo,
+		userRepo:        userRepo,
+		entitlementRepo: entitlementRepo,
+		lookupCache:     lookupCache,
+	}
+}
+
+func (s *IndicatorService) CreateIndicator(actorID uuid.UUID, req CreateIndicatorRequest) (*domain.Indicator, error) {
+	if err := requireRole(s.userRepo, actorID, domain.RoleAnalyst); err != nil {
+		return nil, err
+	}
+
+	indicator, err := domain.NewIndicator(req.Type, req.Value, req.Confidence, req.Severity)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.indicatorRepo.FindByValue(indicator.Type, indicator.Value); err == nil {
+		return nil, ErrIndicatorExists
+	}
+
+	indicator.SetTags(req.Tags)
+	indicator.Source = strings.TrimSpace(req.Source)
+	if indicator.Source == "" {
+		indicator.Source = manualSource
+	}
+	indicator.CreatedBy = actorID
+	if req.FirstSeen != nil {
+		indicator.FirstSeen = *req.FirstSeen
+		indicator.LastSeen = *req.FirstSeen
+	}
+	if req.LastSeen != nil {
+		indicator.Seen(*req.LastSeen)
+	}
+	indicator.ExpiresAt = req.ExpiresAt
+
+	if err := s.indicatorRepo.Save(indicator); err != nil {
+		return nil, err
+	}
+
+	// The analyst's entry counts as a sighting like any other source's.
+	sighting := domain.NewSighting(indicator.ID, indicator.Source)
+	sighting.Record(indicator, "")
+	if err := s.sightingRepo.Save(sighting); err != nil {
+		return nil, err
+	}
+
+	forgetLookups(context.Background(), s.lookupCache, []domain.IndicatorKey{indicator.Key()})
+	return indicator, nil
+}
+
+// GetIndicator returns an indicator whose type is covered by the user's
+// entitlement.
+func (s *IndicatorService) GetIndicator(userID, id uuid.UUID) (*domain.Indicator, error) {
+	tier, err := requireEntitlement(s.userRepo, s.entitlementRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	indicator, err := s.findIndicator(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !tier.AllowsIndicatorType(indicator.Type) {
+		return nil, ErrEntitlementRequired
+	}
+
+	return indicator, nil
+}
+
+// ListSightings returns the sources that reported an indicator whose type is
+// covered by the user's entitlement, with what each of them reported last.
+func (s *IndicatorService) ListSightings(userID, id uuid.UUID) ([]*domain.Sighting, error) {
+	if _, err := s.GetIndicator(userID, id); err != nil {
+		return nil, err
+	}
+	return s.sightingRepo.ListByIndicatorID(id)
+}
+
+// ListIndicators lists the matching indicators among the types covered by
+// the user's entitlement.
+func (s *IndicatorService) ListIndicators(userID uuid.UUID, req ListIndicatorsRequest) (*IndicatorListResponse, error) {
+	if req.Type != "" && !req.Type.IsValid() {
+		return nil, domain.ErrInvalidIndicatorType
+	}
+	if req.Severity != "" && !req.Severity.IsValid() {
+		return nil, domain.ErrInvalidSeverity
+	}
+
+	tier, err := requireEntitlement(s.userRepo, s.entitlementRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	types, err := readableTypes(tier, req.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	page, pageSize := normalizePage(req.Page, req.PageSize)
+
+	indicators, total, err := s.indicatorRepo.List(domain.IndicatorFilter{
+		Type:           req.Type,
+		Types:          types,
+		Severity:       req.Severity,
+		Tag:            strings.ToLower(strings.TrimSpace(req.Tag)),
+		Source:         req.Source,
+		IncludeExpired: req.IncludeExpired,
+		Offset:         (page - 1) * pageSize,
+		Limit:          pageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &IndicatorListResponse{
+		Indicators: indicators,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+	}, nil
+}
+
+func (s *IndicatorService) UpdateIndicator(actorID, id uuid.UUID, req UpdateIndicatorRequest) (*domain.Indicator, error) {
+	if err := requireRole(s.userRepo, actorID, domain.RoleAnalyst); err != nil {
+		return nil, err
+	}
+
+	indicator, err := s.findIndicator(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Confidence != nil {
+		if err := indicator.SetConfidence(*req.Confidence); err != nil {
+			return nil, err
+		}
+	}
+	if req.Severity != nil {
+		if err := indicator.SetSeverity(*req.Severity); err != nil {
+			return nil, err
+		}
+	}
+	if req.Tags != nil {
+		indicator.SetTags(*req.Tags)
+	}
+	if req.Source != nil {
+		indicator.Source = *req.Source
+	}
+	if req.LastSeen != nil {
+		indicator.Seen(*req.LastSeen)
+	}
+	if req.ExpiresAt != nil {
+		indicator.ExpiresAt = req.ExpiresAt
+	}
+	indicator.UpdatedAt = time.Now()
+
+	if err := s.indicatorRepo.Save(indicator); err != nil {
+		return nil, err
+	}
+
+	forgetLookups(context.Background(), s.lookupCache, []domain.IndicatorKey{indicator.Key()})
+	return indicator, nil
+}
+
+func (s *IndicatorService) DeleteIndicator(actorID, id uuid.UUID) error {
+	if err := requireRole(s.userRepo, actorID, domain.RoleAnalyst); err != nil {
+		return err
+	}
+
+	indicator, err := s.findIndicator(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.indicatorRepo.Delete(id); err != nil {
+		return err
+	}
+
+	forgetLookups(context.Background(), s.lookupCache, []domain.IndicatorKey{indicator.Key()})
+	return nil
+}
+
+func (s *IndicatorService) findIndicator(id uuid.UUID) (*domain.Indicator, error) {
+	indicator, err := s.indicatorRepo.FindByID(id)
+	if err != nil {
+		return nil, ErrIndicatorNotFound
+	}
+	return indicator, nil
+}
@@ -0,0 +1,334 @@
+package application
+
+import (
+	"errors"
+	"testing"
+	"threat-intel-backend/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockIndicatorRepository struct {
+	mock.Mock
+}
+
+func (m *MockIndicatorRepository) Save(indicator *domain.Indicator) error {
+	args := m.Called(indicator)
+	return args.Error(0)
+}
+
+func (m *MockIndicatorRepository) FindByID(id uuid.UUID) (*domain.Indicator, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Indicator), args.Error(1)
+}
+
+func (m *MockIndicatorRepository) FindByValue(indicatorType domain.IndicatorType, value string) (*domain.Indicator, error) {
+	args := m.Called(indicatorType, value)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Indicator), args.Error(1)
+}
+
+func (m *MockIndicatorRepository) FindByStixID(stixID string) (*domain.Indicator, error) {
+	args := m.Called(stixID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Indicator), args.Error(1)
+}
+
+func (m *MockIndicatorRepository) List(filter domain.IndicatorFilter) ([]*domain.Indicator, int64, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]*domain.Indicator), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockIndicatorRepository) ListChanges(filter domain.IndicatorFilter, after domain.IndicatorCursor) ([]*domain.Indicator, error) {
+	args := m.Called(filter, after)
+	return args.Get(0).([]*domain.Indicator), args.Error(1)
+}
+
+func (m *MockIndicatorRepository) FindByKeys(keys []domain.IndicatorKey) ([]*domain.Indicator, error) {
+	args := m.Called(keys)
+	return args.Get(0).([]*domain.Indicator), args.Error(1)
+}
+
+func (m *MockIndicatorRepository) ListUnexpired(now time.Time, after uuid.UUID, limit int) ([]*domain.Indicator, error) {
+	args := m.Called(now, after, limit)
+	return args.Get(0).([]*domain.Indicator), args.Error(1)
+}
+
+func (m *MockIndicatorRepository) SaveScore(indicator *domain.Indicator) error {
+	args := m.Called(indicator)
+	return args.Error(0)
+}
+
+func (m *MockIndicatorRepository) Delete(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func setupIndicatorService() (*IndicatorService, *MockIndicatorRepository, *MockEntitlementRepository, *domain.User, *domain.User) {
+	mockIndicators := new(MockIndicatorRepository)
+	mockUsers := new(MockUserRepository)
+	mockEntitlements := new(MockEntitlementRepository)
+	analyst, _ := domain.NewUser("analyst@example.com", "password123", domain.RoleAnalyst)
+	viewer, _ := domain.NewUser("viewer@example.com", "password123", domain.RoleViewer)
+	mockUsers.On("FindByID", analyst.ID).Return(analyst, nil)
+	mockUsers.On("FindByID", viewer.ID).Return(viewer, nil)
+
+	return NewIndicatorService(mockIndicators, new(MockSightingRepository), mockUsers, mockEntitlements, newMemoryLookupCache()), mockIndicators, mockEntitlements, analyst, viewer
+}
+
+func TestIndicatorService_CreateIndicator(t *testing.T) {
+	service, mockIndicators, _, analyst, viewer := setupIndicatorService()
+
+	mockSightings := service.sightingRepo.(*MockSightingRepository)
+	cache := service.lookupCache.(*memoryLookupCache)
+
+	t.Run("creates normalized indicator", func(t *testing.T) {
+		lastSeen := time.Now().Add(time.Hour)
+		key := domain.IndicatorKey{Type: domain.IndicatorDomain, Value: "evil.example.com"}
+		cache.entries[key] = nil
+		mockIndicators.On("FindByValue", domain.IndicatorDomain, "evil.example.com").Return(nil, errors.New("record not found")).Once()
+		mockIndicators.On("Save", mock.AnythingOfType("*domain.Indicator")).Return(nil).Once()
+		mockSightings.On("Save", mock.MatchedBy(func(s *domain.Sighting) bool {
+			return s.Source == "osint-team" && s.Confidence == 70 && s.Count == 1
+		})).Return(nil).Once()
+
+		indicator, err := service.CreateIndicator(analyst.ID, CreateIndicatorRequest{
+			Type:       domain.IndicatorDomain,
+			Value:      "Evil.Example.com",
+			Confidence: 70,
+			Severity:   domain.SeverityHigh,
+			Tags:       []string{"Phishing"},
+			Source:     "osint-team",
+			LastSeen:   &lastSeen,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "evil.example.com", indicator.Value)
+		assert.Equal(t, domain.Tags{"phishing"}, indicator.Tags)
+		assert.Equal(t, analyst.ID, indicator.CreatedBy)
+		assert.Equal(t, lastSeen, indicator.LastSeen)
+		assert.NotContains(t, cache.entries, key, "the cached miss is dropped")
+		mockIndicators.AssertExpectations(t)
+		mockSightings.AssertExpectations(t)
+	})
+
+	t.Run("records a manual sighting without a source", func(t *testing.T) {
+		mockIndicators.On("FindByValue", domain.IndicatorIPv4, "192.0.2.44").Return(nil, errors.New("record not found")).Once()
+		mockIndicators.On("Save", mock.AnythingOfType("*domain.Indicator")).Return(nil).Once()
+		mockSightings.On("Save", mock.MatchedBy(func(s *domain.Sighting) bool {
+			return s.Source == manualSource
+		})).Return(nil).Once()
+
+		indicator, err := service.CreateIndicator(analyst.ID, CreateIndicatorRequest{Type: domain.IndicatorIPv4, Value: "192.0.2.44", Confidence: 50})
+
+		assert.NoError(t, err)
+		assert.Equal(t, manualSource, indicator.Source)
+		mockSightings.AssertExpectations(t)
+	})
+
+	t.Run("rejects duplicates", func(t *testing.T) {
+		existing, _ := domain.NewIndicator(domain.IndicatorIPv4, "10.0.0.1", 50, domain.SeverityLow)
+		mockIndicators.On("FindByValue", domain.IndicatorIPv4, "10.0.0.1").Return(existing, nil).Once()
+
+		_, err := service.CreateIndicator(analyst.ID, CreateIndicatorRequest{Type: domain.IndicatorIPv4, Value: "10.0.0.1"})
+
+		assert.Equal(t, ErrIndicatorExists, err)
+	})
+
+	t.Run("rejects invalid value", func(t *testing.T) {
+		_, err := service.CreateIndicator(analyst.ID, CreateIndicatorRequest{Type: domain.IndicatorIPv4, Value: "not-an-ip"})
+
+		assert.True(t, errors.Is(err, domain.ErrInvalidIndicatorValue))
+	})
+
+	t.Run("viewers cannot write", func(t *testing.T) {
+		_, err := service.CreateIndicator(viewer.ID, CreateIndicatorRequest{Type: domain.IndicatorIPv4, Value: "10.0.0.1"})
+
+		assert.Equal(t, ErrInsufficientPermissions, err)
+	})
+}
+
+func TestIndicatorService_GetIndicator(t *testing.T) {
+	service, mockIndicators, mockEntitlements, analyst, viewer := setupIndicatorService()
+	hash, _ := domain.NewIndicator(domain.IndicatorMD5, "d41d8cd98f00b204e9800998ecf8427e", 50, domain.SeverityLow)
+	mockIndicators.On("FindByID", hash.ID).Return(hash, nil)
+
+	t.Run("analysts read every type", func(t *testing.T) {
+		indicator, err := service.GetIndicator(analyst.ID, hash.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, hash, indicator)
+	})
+
+	t.Run("type outside the viewer's tier", func(t *testing.T) {
+		mockEntitlements.On("FindByUserID", viewer.ID).Return([]*domain.Entitlement{activeEntitlement(viewer.ID, domain.TierBasic)}, nil).Once()
+
+		_, err := service.GetIndicator(viewer.ID, hash.ID)
+
+		assert.Equal(t, ErrEntitlementRequired, err)
+	})
+
+	t.Run("viewer without entitlement", func(t *testing.T) {
+		mockEntitlements.On("FindByUserID", viewer.ID).Return([]*domain.Entitlement{}, nil).Once()
+
+		_, err := service.GetIndicator(viewer.ID, hash.ID)
+
+		assert.Equal(t, ErrEntitlementRequired, err)
+	})
+}
+
+func TestIndicatorService_ListSightings(t *testing.T) {
+	mockIndicators := new(MockIndicatorRepository)
+	mockSightings := new(MockSightingRepository)
+	mockUsers := new(MockUserRepository)
+	mockEntitlements := new(MockEntitlementRepository)
+	service := NewIndicatorService(mockIndicators, mockSightings, mockUsers, mockEntitlements, newMemoryLookupCache())
+	analyst, _ := domain.NewUser("analyst@example.com", "password123", domain.RoleAnalyst)
+	viewer, _ := domain.NewUser("viewer@example.com", "password123", domain.RoleViewer)
+	mockUsers.On("FindByID", analyst.ID).Return(analyst, nil)
+	mockUsers.On("FindByID", viewer.ID).Return(viewer, nil)
+	hash, _ := domain.NewIndicator(domain.IndicatorMD5, "d41d8cd98f00b204e9800998ecf8427e", 50, domain.SeverityLow)
+	mockIndicators.On("FindByID", hash.ID).Return(hash, nil)
+
+	t.Run("lists the sources of the indicator", func(t *testing.T) {
+		sightings := []*domain.Sighting{domain.NewSighting(hash.ID, "malware-bazaar")}
+		mockSightings.On("ListByIndicatorID", hash.ID).Return(sightings, nil).Once()
+
+		result, err := service.ListSightings(analyst.ID, hash.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, sightings, result)
+	})
+
+	t.Run("type outside the viewer's tier", func(t *testing.T) {
+		mockEntitlements.On("FindByUserID", viewer.ID).Return([]*domain.Entitlement{activeEntitlement(viewer.ID, domain.TierBasic)}, nil).Once()
+
+		_, err := service.ListSightings(viewer.ID, hash.ID)
+
+		assert.Equal(t, ErrEntitlementRequired, err)
+		mockSightings.AssertNumberOfCalls(t, "ListByIndicatorID", 1)
+	})
+}
+
+func TestIndicatorService_ListIndicators(t *testing.T) {
+	service, mockIndicators, mockEntitlements, analyst, viewer := setupIndicatorService()
+
+	t.Run("applies filter and pagination", func(t *testing.T) {
+		indicators := []*domain.Indicator{{ID: uuid.New()}}
+		mockIndicators.On("List", domain.IndicatorFilter{
+			Type:   domain.IndicatorURL,
+			Tag:    "phishing",
+			Offset: 20,
+			Limit:  20,
+		}).Return(indicators, int64(21), nil).Once()
+
+		resp, err := service.ListIndicators(analyst.ID, ListIndicatorsRequest{Type: domain.IndicatorURL, Tag: " Phishing ", Page: 2})
+
+		assert.NoError(t, err)
+		assert.Equal(t, indicators, resp.Indicators)
+		assert.Equal(t, int64(21), resp.Total)
+		assert.Equal(t, 2, resp.Page)
+		assert.Equal(t, defaultPageSize, resp.PageSize)
+	})
+
+	t.Run("restricts viewers to entitled types", func(t *testing.T) {
+		mockEntitlements.On("FindByUserID", viewer.ID).Return([]*domain.Entitlement{activeEntitlement(viewer.ID, domain.TierBasic)}, nil).Once()
+		mockIndicators.On("List", domain.IndicatorFilter{
+			Types: domain.TierBasic.IndicatorTypes(),
+			Limit: defaultPageSize,
+		}).Return([]*domain.Indicator{}, int64(0), nil).Once()
+
+		_, err := service.ListIndicators(viewer.ID, ListIndicatorsRequest{})
+
+		assert.NoError(t, err)
+		mockIndicators.AssertExpectations(t)
+	})
+
+	t.Run("rejects type outside the viewer's tier", func(t *testing.T) {
+		mockEntitlements.On("FindByUserID", viewer.ID).Return([]*domain.Entitlement{activeEntitlement(viewer.ID, domain.TierBasic)}, nil).Once()
+
+		_, err := service.ListIndicators(viewer.ID, ListIndicatorsRequest{Type: domain.IndicatorSHA1})
+
+		assert.Equal(t, ErrEntitlementRequired, err)
+	})
+
+	t.Run("rejects unknown type", func(t *testing.T) {
+		_, err := service.ListIndicators(analyst.ID, ListIndicatorsRequest{Type: "mutex"})
+
+		assert.Equal(t, domain.ErrInvalidIndicatorType, err)
+	})
+}
+
+func TestIndicatorService_UpdateIndicator(t *testing.T) {
+	service, mockIndicators, _, analyst, _ := setupIndicatorService()
+	cache := service.lookupCache.(*memoryLookupCache)
+
+	t.Run("updates provided fields", func(t *testing.T) {
+		indicator, _ := domain.NewIndicator(domain.IndicatorSHA256, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", 50, domain.SeverityLow)
+		cache.entries[indicator.Key()] = &domain.LookupMatch{IndicatorID: indicator.ID, Confidence: 50}
+		mockIndicators.On("FindByID", indicator.ID).Return(indicator, nil).Once()
+		mockIndicators.On("Save", indicator).Return(nil).Once()
+
+		confidence := 90
+		severity := domain.SeverityCritical
+		updated, err := service.UpdateIndicator(analyst.ID, indicator.ID, UpdateIndicatorRequest{
+			Confidence: &confidence,
+			Severity:   &severity,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 90, updated.Confidence)
+		assert.Equal(t, domain.SeverityCritical, updated.Severity)
+		assert.NotContains(t, cache.entries, indicator.Key())
+		mockIndicators.AssertExpectations(t)
+	})
+
+	t.Run("rejects invalid severity", func(t *testing.T) {
+		indicator, _ := domain.NewIndicator(domain.IndicatorIPv4, "10.0.0.2", 50, domain.SeverityLow)
+		mockIndicators.On("FindByID", indicator.ID).Return(indicator, nil).Once()
+
+		severity := domain.Severity("severe")
+		_, err := service.UpdateIndicator(analyst.ID, indicator.ID, UpdateIndicatorRequest{Severity: &severity})
+
+		assert.Equal(t, domain.ErrInvalidSeverity, err)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		id := uuid.New()
+		mockIndicators.On("FindByID", id).Return(nil, errors.New("record not found")).Once()
+
+		_, err := service.UpdateIndicator(analyst.ID, id, UpdateIndicatorRequest{})
+
+		assert.Equal(t, ErrIndicatorNotFound, err)
+	})
+}
+
+func TestIndicatorService_DeleteIndicator(t *testing.T) {
+	service, mockIndicators, _, analyst, viewer := setupIndicatorService()
+	cache := service.lookupCache.(*memoryLookupCache)
+
+	t.Run("deletes indicator", func(t *testing.T) {
+		indicator, _ := domain.NewIndicator(domain.IndicatorIPv4, "10.0.0.3", 50, domain.SeverityLow)
+		cache.entries[indicator.Key()] = &domain.LookupMatch{IndicatorID: indicator.ID}
+		mockIndicators.On("FindByID", indicator.ID).Return(indicator, nil).Once()
+		mockIndicators.On("Delete", indicator.ID).Return(nil).Once()
+
+		assert.NoError(t, service.DeleteIndicator(analyst.ID, indicator.ID))
+		assert.NotContains(t, cache.entries, indicator.Key())
+		mockIndicators.AssertExpectations(t)
+	})
+
+	t.Run("viewers cannot delete", func(t *testing.T) {
+		assert.Equal(t, ErrInsufficientPermissions, service.DeleteIndicator(viewer.ID, uuid.New()))
+	})
+}
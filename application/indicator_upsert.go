@@ -0,0 +1,51 @@
+package application
+
+import (
+	"threat-intel-backend/domain"
+	"github.com/google/uuid"
+)
+
+// IndicatorReport is an indicator as one source reported it, with the raw
+// entry of the source it was read from.
+type IndicatorReport struct {
+	Indicator  *domain.Indicator
+	Source     string
+	RawPayload string
+}
+
+// upsertIndicator stores a reported indicator, or merges it into the stored
+// one with the same type and value, so that an observable reported by many
+// sources is a single indicator. The report is recorded as the sighting of
+// its source. It returns the stored indicator and whether it was created.
+func upsertIndicator(indicatorRepo domain.IndicatorRepository, sightingRepo domain.SightingRepository, report IndicatorReport, createdBy uuid.UUID) (*domain.Indicator, bool, error) {
+	reported := report.Indicator
+
+	indicator, err := indicatorRepo.FindByValue(reported.Type, reported.Value)
+	created := err != nil
+	if created {
+		// An indicator whose value changed upstream keeps its STIX ID on the
+		// stored one; the new value gets an ID of its own.
+		if _, err := indicatorRepo.FindByStixID(reported.StixID); err == nil {
+			reported.StixID = domain.NewStixID("indicator", reported.ID)
+		}
+		indicator = reported
+		indicator.Source = report.Source
+		indicator.CreatedBy = createdBy
+	} else {
+		indicator.Merge(reported)
+	}
+	if err := indicatorRepo.Save(indicator); err != nil {
+		return nil, false, err
+	}
+
+	sighting, err := sightingRepo.FindByIndicatorAndSource(indicator.ID, report.Source)
+	if err != nil {
+		sighting = domain.NewSighting(indicator.ID, report.Source)
+	}
+	sighting.Record(reported, report.RawPayload)
+	if err := sightingRepo.Save(sighting); err != nil {
+		return nil, false, err
+	}
+
+	return indicator, created, nil
+}
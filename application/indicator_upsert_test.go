@@ -0,0 +1,114 @@
+package application
+
+import (
+	"errors"
+	"testing"
+	"threat-intel-backend/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSightingRepository struct {
+	mock.Mock
+}
+
+func (m *MockSightingRepository) Save(sighting *domain.Sighting) error {
+	args := m.Called(sighting)
+	return args.Error(0)
+}
+
+func (m *MockSightingRepository) FindByIndicatorAndSource(indicatorID uuid.UUID, source string) (*domain.Sighting, error) {
+	args := m.Called(indicatorID, source)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Sighting), args.Error(1)
+}
+
+func (m *MockSightingRepository) ListByIndicatorID(indicatorID uuid.UUID) ([]*domain.Sighting, error) {
+	args := m.Called(indicatorID)
+	return args.Get(0).([]*domain.Sighting), args.Error(1)
+}
+
+func (m *MockSightingRepository) ListByIndicatorIDs(indicatorIDs []uuid.UUID) ([]*domain.Sighting, error) {
+	args := m.Called(indicatorIDs)
+	return args.Get(0).([]*domain.Sighting), args.Error(1)
+}
+
+func TestUpsertIndicator(t *testing.T) {
+	feedOwner := uuid.New()
+	notFound := errors.New("record not found")
+
+	t.Run("creates the indicator and its first sighting", func(t *testing.T) {
+		mockIndicators := new(MockIndicatorRepository)
+		mockSightings := new(MockSightingRepository)
+		reported, _ := domain.NewIndicator(domain.IndicatorDomain, "evil[.]example[.]com", 60, "")
+		mockIndicators.On("FindByValue", domain.IndicatorDomain, "evil.example.com").Return(nil, notFound)
+		mockIndicators.On("FindByStixID", reported.StixID).Return(nil, notFound)
+		mockIndicators.On("Save", reported).Return(nil)
+		mockSightings.On("FindByIndicatorAndSource", reported.ID, "openphish").Return(nil, notFound)
+		var sighting *domain.Sighting
+		mockSightings.On("Save", mock.AnythingOfType("*domain.Sighting")).Run(func(args mock.Arguments) {
+			sighting = args.Get(0).(*domain.Sighting)
+		}).Return(nil)
+
+		indicator, created, err := upsertIndicator(mockIndicators, mockSightings, IndicatorReport{Indicator: reported, Source: "openphish", RawPayload: "hxxp://evil[.]example[.]com"}, feedOwner)
+
+		assert.NoError(t, err)
+		assert.True(t, created)
+		assert.Equal(t, "openphish", indicator.Source)
+		assert.Equal(t, feedOwner, indicator.CreatedBy)
+		assert.Equal(t, reported.ID, sighting.IndicatorID)
+		assert.Equal(t, 1, sighting.Count)
+		assert.Equal(t, "hxxp://evil[.]example[.]com", sighting.RawPayload)
+	})
+
+	t.Run("merges into the stored indicator", func(t *testing.T) {
+		mockIndicators := new(MockIndicatorRepository)
+		mockSightings := new(MockSightingRepository)
+		stored, _ := domain.NewIndicator(domain.IndicatorIPv4, "192.0.2.1", 80, domain.SeverityHigh)
+		stored.Source = "blocklist-a"
+		stored.Tags = domain.Tags{"scanner"}
+		reported, _ := domain.NewIndicator(domain.IndicatorIPv4, "192.0.2.1", 40, domain.SeverityLow)
+		reported.Tags = domain.Tags{"botnet"}
+		reported.LastSeen = stored.LastSeen.Add(time.Hour)
+		existing := domain.NewSighting(stored.ID, "blocklist-b")
+		existing.Record(reported, "192.0.2.1")
+		mockIndicators.On("FindByValue", domain.IndicatorIPv4, "192.0.2.1").Return(stored, nil)
+		mockIndicators.On("Save", stored).Return(nil)
+		mockSightings.On("FindByIndicatorAndSource", stored.ID, "blocklist-b").Return(existing, nil)
+		mockSightings.On("Save", existing).Return(nil)
+
+		indicator, created, err := upsertIndicator(mockIndicators, mockSightings, IndicatorReport{Indicator: reported, Source: "blocklist-b", RawPayload: "192.0.2.1 # c2"}, feedOwner)
+
+		assert.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, stored, indicator)
+		assert.Equal(t, "blocklist-a", indicator.Source)
+		assert.Equal(t, domain.Tags{"botnet", "scanner"}, indicator.Tags)
+		assert.Equal(t, 80, indicator.Confidence)
+		assert.Equal(t, reported.LastSeen, indicator.LastSeen)
+		assert.Equal(t, 2, existing.Count)
+		assert.Equal(t, "192.0.2.1 # c2", existing.RawPayload)
+	})
+
+	t.Run("a new value keeps away from a used STIX ID", func(t *testing.T) {
+		mockIndicators := new(MockIndicatorRepository)
+		mockSightings := new(MockSightingRepository)
+		reported, _ := domain.NewIndicator(domain.IndicatorIPv4, "192.0.2.9", 50, "")
+		reported.StixID = "indicator--5f6b1c2a-1e2d-4c3b-9a8f-7e6d5c4b3a21"
+		mockIndicators.On("FindByValue", domain.IndicatorIPv4, "192.0.2.9").Return(nil, notFound)
+		mockIndicators.On("FindByStixID", "indicator--5f6b1c2a-1e2d-4c3b-9a8f-7e6d5c4b3a21").Return(&domain.Indicator{}, nil)
+		mockIndicators.On("Save", reported).Return(nil)
+		mockSightings.On("FindByIndicatorAndSource", reported.ID, "circl").Return(nil, notFound)
+		mockSightings.On("Save", mock.AnythingOfType("*domain.Sighting")).Return(nil)
+
+		indicator, _, err := upsertIndicator(mockIndicators, mockSightings, IndicatorReport{Indicator: reported, Source: "circl"}, feedOwner)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.NewStixID("indicator", reported.ID), indicator.StixID)
+	})
+}
@@ -0,0 +1,124 @@
+package application
+
+import (
+	"errors"
+	"time"
+	"threat-intel-backend/domain"
+	"threat-intel-backend/infrastructure/jwt"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidInvitation = errors.New("invalid or expired invitation")
+	ErrEmailExists       = errors.New("email already exists")
+)
+
+// SessionStarter signs in a user who has been authenticated by other means
+// under the same MFA policy as a password login. AuthService implements it.
+type SessionStarter interface {
+	StartSession(user *domain.User) (*LoginResponse, error)
+}
+
+type InvitationService struct {
+	userRepo   domain.UserRepository
+	jwtService *jwt.Service
+	tokens     domain.OneTimeTokenStore
+	sessions   SessionStarter
+	passwords  PasswordPolicy
+}
+
+type CreateInvitationRequest struct {
+	Email string          `json:"email" binding:"required,email"`
+	Role  domain.UserRole `json:"role" binding:"required"`
+}
+
+type InvitationResponse struct {
+	Token     string          `json:"token"`
+	Email     string          `json:"email"`
+	Role      domain.UserRole `json:"role"`
+	ExpiresAt time.Time       `json:"expires_at"`
+}
+
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+func NewInvitationService(userRepo domain.UserRepository, jwtService *jwt.Service, tokens domain.OneTimeTokenStore, sessions SessionStarter, passwords PasswordPolicy) *InvitationService {
+	return &InvitationService{
+		userRepo:   userRepo,
+		jwtService: jwtService,
+		tokens:     tokens,
+		sessions:   sessions,
+		passwords:  passwords,
+	}
+}
+
+// CreateInvitation issues a signed, expiring invite token for the given email
+// and role. Only admins may invite.
+func (s *InvitationService) CreateInvitation(actorID uuid.UUID, req CreateInvitationRequest) (*InvitationResponse, error) {
+	if err := requireRole(s.userRepo, actorID, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	if !req.Role.IsValid() {
+		return nil, ErrInvalidRole
+	}
+
+	if existing, _ := s.userRepo.FindByEmail(req.Email); existing != nil {
+		return nil, ErrEmailExists
+	}
+
+	token, claims, err := s.jwtService.GenerateInviteToken(req.Email, req.Role, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &InvitationResponse{
+		Token:     token,
+		Email:     claims.Email,
+		Role:      claims.Role,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}, nil
+}
+
+// AcceptInvitation redeems an invite token exactly once, creating the user
+// with the invited role and signing them in. When the role requires MFA the
+// response is an enrollment challenge rather than tokens.
+func (s *InvitationService) AcceptInvitation(req AcceptInvitationRequest) (*LoginResponse, error) {
+	claims, err := s.jwtService.ValidateInviteToken(req.Token)
+	if err != nil {
+		return nil, ErrInvalidInvitation
+	}
+
+	if !claims.Role.IsValid() {
+		return nil, ErrInvalidInvitation
+	}
+
+	if err := s.passwords.Check(claims.Email, req.Password); err != nil {
+		return nil, err
+	}
+
+	if existing, _ := s.userRepo.FindByEmail(claims.Email); existing != nil {
+		return nil, ErrEmailExists
+	}
+
+	first, err := s.tokens.Consume(claims.ID, claims.ExpiresAt.Time)
+	if err != nil {
+		return nil, err
+	}
+	if !first {
+		return nil, ErrInvalidInvitation
+	}
+
+	user, err := domain.NewUser(claims.Email, req.Password, claims.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.Save(user); err != nil {
+		return nil, err
+	}
+
+	return s.sessions.StartSession(user)
+}
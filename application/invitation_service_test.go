@@ -0,0 +1,182 @@
+package application
+
+import (
+	"errors"
+	"testing"
+	"threat-intel-backend/domain"
+	"threat-intel-backend/infrastructure/jwt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOneTimeTokenStore struct {
+	mock.Mock
+}
+
+func (m *MockOneTimeTokenStore) Consume(tokenID string, expiresAt time.Time) (bool, error) {
+	args := m.Called(tokenID, expiresAt)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockSessionStarter struct {
+	mock.Mock
+}
+
+func (m *MockSessionStarter) StartSession(user *domain.User) (*LoginResponse, error) {
+	args := m.Called(user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*LoginResponse), args.Error(1)
+}
+
+func setupInvitationService() (*InvitationService, *MockUserRepository, *MockOneTimeTokenStore, *MockSessionStarter, *jwt.Service, *domain.User) {
+	mockRepo := new(MockUserRepository)
+	mockTokens := new(MockOneTimeTokenStore)
+	mockSessions := new(MockSessionStarter)
+	jwtService := jwt.NewService("test-secret")
+	admin, _ := domain.NewUser("admin@example.com", "password123", domain.RoleAdmin)
+	mockRepo.On("FindByID", admin.ID).Return(admin, nil).Maybe()
+
+	service := NewInvitationService(mockRepo, jwtService, mockTokens, mockSessions, DefaultPasswordPolicy)
+	return service, mockRepo, mockTokens, mockSessions, jwtService, admin
+}
+
+func TestInvitationService_CreateInvitation(t *testing.T) {
+	service, mockRepo, _, _, jwtService, admin := setupInvitationService()
+
+	t.Run("issues invite token carrying the role", func(t *testing.T) {
+		mockRepo.On("FindByEmail", "analyst@example.com").Return(nil, errors.New("not found")).Once()
+
+		resp, err := service.CreateInvitation(admin.ID, CreateInvitationRequest{Email: "analyst@example.com", Role: domain.RoleAnalyst})
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.RoleAnalyst, resp.Role)
+		assert.True(t, resp.ExpiresAt.After(time.Now()))
+
+		claims, err := jwtService.ValidateInviteToken(resp.Token)
+		assert.NoError(t, err)
+		assert.Equal(t, "analyst@example.com", claims.Email)
+		assert.Equal(t, domain.RoleAnalyst, claims.Role)
+		assert.Equal(t, admin.ID, claims.InvitedBy)
+	})
+
+	t.Run("rejects non-admin", func(t *testing.T) {
+		analyst, _ := domain.NewUser("analyst@example.com", "password123", domain.RoleAnalyst)
+		mockRepo.On("FindByID", analyst.ID).Return(analyst, nil).Once()
+
+		_, err := service.CreateInvitation(analyst.ID, CreateInvitationRequest{Email: "x@example.com", Role: domain.RoleAdmin})
+
+		assert.ErrorIs(t, err, ErrInsufficientPermissions)
+	})
+
+	t.Run("rejects unknown role", func(t *testing.T) {
+		_, err := service.CreateInvitation(admin.ID, CreateInvitationRequest{Email: "x@example.com", Role: "root"})
+
+		assert.ErrorIs(t, err, ErrInvalidRole)
+	})
+
+	t.Run("rejects existing email", func(t *testing.T) {
+		existing, _ := domain.NewUser("taken@example.com", "password123", domain.RoleViewer)
+		mockRepo.On("FindByEmail", "taken@example.com").Return(existing, nil).Once()
+
+		_, err := service.CreateInvitation(admin.ID, CreateInvitationRequest{Email: "taken@example.com", Role: domain.RoleAnalyst})
+
+		assert.ErrorIs(t, err, ErrEmailExists)
+	})
+}
+
+func TestInvitationService_AcceptInvitation(t *testing.T) {
+	service, mockRepo, mockTokens, mockSessions, jwtService, admin := setupInvitationService()
+
+	t.Run("creates user with invited role", func(t *testing.T) {
+		token, claims, _ := jwtService.GenerateInviteToken("analyst@example.com", domain.RoleAnalyst, admin.ID)
+		mockRepo.On("FindByEmail", "analyst@example.com").Return(nil, errors.New("not found")).Once()
+		mockTokens.On("Consume", claims.ID, claims.ExpiresAt.Time).Return(true, nil).Once()
+		mockRepo.On("Save", mock.MatchedBy(func(u *domain.User) bool {
+			return u.Email == "analyst@example.com" && u.Role == domain.RoleAnalyst
+		})).Return(nil).Once()
+		mockSessions.On("StartSession", mock.AnythingOfType("*domain.User")).Return(&LoginResponse{AuthResponse: &AuthResponse{AccessToken: "access"}}, nil).Once()
+
+		resp, err := service.AcceptInvitation(AcceptInvitationRequest{Token: token, Password: "password123"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "access", resp.AccessToken)
+		mockRepo.AssertExpectations(t)
+		mockTokens.AssertExpectations(t)
+	})
+
+	t.Run("rejects already used invite", func(t *testing.T) {
+		token, claims, _ := jwtService.GenerateInviteToken("again@example.com", domain.RoleAnalyst, admin.ID)
+		mockRepo.On("FindByEmail", "again@example.com").Return(nil, errors.New("not found")).Once()
+		mockTokens.On("Consume", claims.ID, claims.ExpiresAt.Time).Return(false, nil).Once()
+
+		resp, err := service.AcceptInvitation(AcceptInvitationRequest{Token: token, Password: "password123"})
+
+		assert.ErrorIs(t, err, ErrInvalidInvitation)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("rejects weak password without using the invite", func(t *testing.T) {
+		token, claims, _ := jwtService.GenerateInviteToken("weak@example.com", domain.RoleAnalyst, admin.ID)
+
+		resp, err := service.AcceptInvitation(AcceptInvitationRequest{Token: token, Password: "weak"})
+
+		assert.ErrorIs(t, err, ErrWeakPassword)
+		assert.Nil(t, resp)
+		mockTokens.AssertNotCalled(t, "Consume", claims.ID, mock.Anything)
+	})
+
+	t.Run("rejects forged invite", func(t *testing.T) {
+		token, _, _ := jwt.NewService("other-secret").GenerateInviteToken("evil@example.com", domain.RoleAdmin, uuid.New())
+
+		resp, err := service.AcceptInvitation(AcceptInvitationRequest{Token: token, Password: "password123"})
+
+		assert.ErrorIs(t, err, ErrInvalidInvitation)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("rejects access token", func(t *testing.T) {
+		token, _ := jwtService.GenerateAccessToken(admin.ID, domain.RoleAdmin)
+
+		_, err := service.AcceptInvitation(AcceptInvitationRequest{Token: token, Password: "password123"})
+
+		assert.ErrorIs(t, err, ErrInvalidInvitation)
+	})
+}
+
+func TestInvitationService_AcceptInvitationMFA(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	mockTokens := new(MockOneTimeTokenStore)
+	mockStore := new(MockRefreshTokenStore)
+	mockFactors := new(MockMFAFactorRepository)
+	mockPolicies := new(MockMFAPolicyRepository)
+	jwtService := jwt.NewService("test-secret")
+	authService := NewAuthService(mockRepo, jwtService, mockStore, new(MockAccessTokenDenylist), permissiveLoginGuard(),
+		NewMFAService(mockFactors, mockPolicies, mockRepo, "Test"))
+	service := NewInvitationService(mockRepo, jwtService, mockTokens, authService, DefaultPasswordPolicy)
+
+	t.Run("invited admin under a required policy must enroll before getting tokens", func(t *testing.T) {
+		token, claims, _ := jwtService.GenerateInviteToken("new-admin@example.com", domain.RoleAdmin, uuid.New())
+		mockRepo.On("FindByEmail", "new-admin@example.com").Return(nil, errors.New("not found")).Once()
+		mockTokens.On("Consume", claims.ID, claims.ExpiresAt.Time).Return(true, nil).Once()
+		mockRepo.On("Save", mock.AnythingOfType("*domain.User")).Return(nil).Once()
+		mockFactors.On("FindByUserID", mock.Anything).Return(nil, errors.New("record not found")).Once()
+		mockPolicies.On("FindByRole", domain.RoleAdmin).Return(&domain.MFAPolicy{Role: domain.RoleAdmin, Required: true}, nil).Once()
+
+		resp, err := service.AcceptInvitation(AcceptInvitationRequest{Token: token, Password: "password123"})
+
+		assert.NoError(t, err)
+		assert.Nil(t, resp.AuthResponse)
+		assert.True(t, resp.MFARequired)
+		assert.True(t, resp.EnrollmentRequired)
+
+		_, err = jwtService.ValidateMFAToken(resp.MFAToken, jwt.MFAPurposeEnroll)
+		assert.NoError(t, err)
+		mockStore.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,186 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"threat-intel-backend/domain"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrAccountLocked      = errors.New("too many failed login attempts")
+)
+
+// LoginLockedError is returned while an email or client IP is locked. The
+// message is the same for both and for unknown emails, so that a lockout
+// does not reveal whether an account exists.
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return "too many failed login attempts, try again later"
+}
+
+func (e *LoginLockedError) Is(target error) bool {
+	return target == ErrAccountLocked
+}
+
+// LoginPolicy configures brute-force protection. After FreeAttempts failures
+// within Window every further failure locks the email for BaseDelay, doubling
+// each time, until MaxAccountFailures locks it for LockoutDuration. Client IPs
+// are locked for LockoutDuration after MaxIPFailures failures on any email.
+type LoginPolicy struct {
+	Window             time.Duration
+	FreeAttempts       int
+	BaseDelay          time.Duration
+	MaxAccountFailures int
+	MaxIPFailures      int
+	LockoutDuration    time.Duration
+}
+
+var DefaultLoginPolicy = LoginPolicy{
+	Window:             15 * time.Minute,
+	FreeAttempts:       3,
+	BaseDelay:          time.Second,
+	MaxAccountFailures: 10,
+	MaxIPFailures:      50,
+	LockoutDuration:    15 * time.Minute,
+}
+
+// LoginGuard tracks failed logins per email and per client IP. Emails are
+// tracked whether or not an account exists for them.
+type LoginGuard struct {
+	attempts domain.LoginAttemptStore
+	events   domain.SecurityEventRepository
+	policy   LoginPolicy
+}
+
+func NewLoginGuard(attempts domain.LoginAttemptStore, events domain.SecurityEventRepository, policy LoginPolicy) *LoginGuard {
+	return &LoginGuard{
+		attempts: attempts,
+		events:   events,
+		policy:   policy,
+	}
+}
+
+// Check returns a *LoginLockedError if the email or the client IP is locked.
+func (g *LoginGuard) Check(email, clientIP string) error {
+	now := time.Now()
+	var lockedUntil time.Time
+	for _, key := range g.keys(email, clientIP) {
+		until, err := g.attempts.LockedUntil(key)
+		if err != nil {
+			return err
+		}
+		if until.After(lockedUntil) {
+			lockedUntil = until
+		}
+	}
+
+	if lockedUntil.After(now) {
+		return &LoginLockedError{RetryAfter: lockedUntil.Sub(now)}
+	}
+	return nil
+}
+
+// Fail records a failed login. user is nil when the email is unknown.
+func (g *LoginGuard) Fail(user *domain.User, email, clientIP string) error {
+	email = normalizeEmail(email)
+
+	failures, err := g.attempts.RecordFailure(accountKey(email), g.policy.Window)
+	if err != nil {
+		return err
+	}
+	switch {
+	case failures >= g.policy.MaxAccountFailures:
+		if err := g.lock(accountKey(email), g.policy.LockoutDuration); err != nil {
+			return err
+		}
+		event := domain.NewSecurityEvent(domain.SecurityEventAccountLocked, email, clientIP,
+			fmt.Sprintf("%d failed logins, locked for %s", failures, g.policy.LockoutDuration))
+		if user != nil {
+			event.UserID = &user.ID
+		}
+		if err := g.events.Save(event); err != nil {
+			return err
+		}
+	case failures >= g.policy.FreeAttempts:
+		if err := g.lock(accountKey(email), g.backoff(failures)); err != nil {
+			return err
+		}
+	}
+
+	if clientIP == "" {
+		return nil
+	}
+	failures, err = g.attempts.RecordFailure(ipKey(clientIP), g.policy.Window)
+	if err != nil {
+		return err
+	}
+	if failures < g.policy.MaxIPFailures {
+		return nil
+	}
+	if err := g.lock(ipKey(clientIP), g.policy.LockoutDuration); err != nil {
+		return err
+	}
+	return g.events.Save(domain.NewSecurityEvent(domain.SecurityEventIPLocked, email, clientIP,
+		fmt.Sprintf("%d failed logins, locked for %s", failures, g.policy.LockoutDuration)))
+}
+
+// Succeed clears the failures of the email. Failures of the client IP are
+// kept so that a valid account cannot be used to reset them.
+func (g *LoginGuard) Succeed(email string) error {
+	return g.attempts.Reset(accountKey(normalizeEmail(email)))
+}
+
+// Unlock lifts the lockout of a user's email on behalf of an admin.
+func (g *LoginGuard) Unlock(actorID uuid.UUID, user *domain.User) error {
+	email := normalizeEmail(user.Email)
+	if err := g.attempts.Reset(accountKey(email)); err != nil {
+		return err
+	}
+
+	event := domain.NewSecurityEvent(domain.SecurityEventAccountUnlocked, email, "", "")
+	event.UserID = &user.ID
+	event.ActorID = &actorID
+	return g.events.Save(event)
+}
+
+func (g *LoginGuard) backoff(failures int) time.Duration {
+	delay := g.policy.BaseDelay
+	for i := g.policy.FreeAttempts; i < failures && delay < g.policy.LockoutDuration; i++ {
+		delay *= 2
+	}
+	if delay > g.policy.LockoutDuration {
+		return g.policy.LockoutDuration
+	}
+	return delay
+}
+
+func (g *LoginGuard) lock(key string, duration time.Duration) error {
+	return g.attempts.Lock(key, time.Now().Add(duration))
+}
+
+func (g *LoginGuard) keys(email, clientIP string) []string {
+	keys := []string{accountKey(normalizeEmail(email))}
+	if clientIP != "" {
+		keys = append(keys, ipKey(clientIP))
+	}
+	return keys
+}
+
+func accountKey(email string) string {
+	return "account:" + email
+}
+
+func ipKey(clientIP string) string {
+	return "ip:" + clientIP
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,171 @@
+package application
+
+import (
+	"errors"
+	"testing"
+	"threat-intel-backend/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLoginAttemptStore struct {
+	mock.Mock
+}
+
+func (m *MockLoginAttemptStore) RecordFailure(key string, window time.Duration) (int, error) {
+	args := m.Called(key, window)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockLoginAttemptStore) Lock(key string, until time.Time) error {
+	args := m.Called(key, until)
+	return args.Error(0)
+}
+
+func (m *MockLoginAttemptStore) LockedUntil(key string) (time.Time, error) {
+	args := m.Called(key)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *MockLoginAttemptStore) Reset(key string) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+type MockSecurityEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockSecurityEventRepository) Save(event *domain.SecurityEvent) error {
+	args := m.Called(event)
+	return args.Error(0)
+}
+
+func (m *MockSecurityEventRepository) List(filter domain.SecurityEventFilter) ([]*domain.SecurityEvent, int64, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]*domain.SecurityEvent), args.Get(1).(int64), args.Error(2)
+}
+
+// permissiveLoginGuard never locks anyone, for tests that are not about
+// brute-force protection.
+func permissiveLoginGuard() *LoginGuard {
+	attempts := new(MockLoginAttemptStore)
+	attempts.On("LockedUntil", mock.Anything).Return(time.Time{}, nil).Maybe()
+	attempts.On("RecordFailure", mock.Anything, mock.Anything).Return(1, nil).Maybe()
+	attempts.On("Reset", mock.Anything).Return(nil).Maybe()
+	return NewLoginGuard(attempts, new(MockSecurityEventRepository), DefaultLoginPolicy)
+}
+
+func lockedFor(duration time.Duration) interface{} {
+	return mock.MatchedBy(func(until time.Time) bool {
+		return until.Sub(time.Now()).Round(time.Second) == duration
+	})
+}
+
+func TestLoginGuard_Check(t *testing.T) {
+	attempts := new(MockLoginAttemptStore)
+	guard := NewLoginGuard(attempts, new(MockSecurityEventRepository), DefaultLoginPolicy)
+
+	t.Run("not locked", func(t *testing.T) {
+		attempts.On("LockedUntil", "account:a@example.com").Return(time.Time{}, nil).Once()
+		attempts.On("LockedUntil", "ip:192.0.2.1").Return(time.Now().Add(-time.Second), nil).Once()
+
+		assert.NoError(t, guard.Check("A@example.com ", "192.0.2.1"))
+	})
+
+	t.Run("reports the longest lock", func(t *testing.T) {
+		attempts.On("LockedUntil", "account:a@example.com").Return(time.Now().Add(time.Minute), nil).Once()
+		attempts.On("LockedUntil", "ip:192.0.2.1").Return(time.Now().Add(time.Hour), nil).Once()
+
+		err := guard.Check("a@example.com", "192.0.2.1")
+
+		assert.True(t, errors.Is(err, ErrAccountLocked))
+		var locked *LoginLockedError
+		assert.True(t, errors.As(err, &locked))
+		assert.InDelta(t, time.Hour.Seconds(), locked.RetryAfter.Seconds(), 1)
+	})
+}
+
+func TestLoginGuard_Fail(t *testing.T) {
+	const email = "a@example.com"
+	const ip = "192.0.2.1"
+	user, _ := domain.NewUser(email, "password123", domain.RoleViewer)
+
+	setup := func(accountFailures, ipFailures int) (*LoginGuard, *MockLoginAttemptStore, *MockSecurityEventRepository) {
+		attempts := new(MockLoginAttemptStore)
+		events := new(MockSecurityEventRepository)
+		attempts.On("RecordFailure", "account:"+email, DefaultLoginPolicy.Window).Return(accountFailures, nil).Once()
+		attempts.On("RecordFailure", "ip:"+ip, DefaultLoginPolicy.Window).Return(ipFailures, nil).Once()
+		return NewLoginGuard(attempts, events, DefaultLoginPolicy), attempts, events
+	}
+
+	t.Run("first failures are free", func(t *testing.T) {
+		guard, attempts, _ := setup(2, 2)
+
+		assert.NoError(t, guard.Fail(user, email, ip))
+		attempts.AssertNotCalled(t, "Lock", mock.Anything, mock.Anything)
+	})
+
+	t.Run("backs off exponentially", func(t *testing.T) {
+		guard, attempts, _ := setup(5, 5)
+		attempts.On("Lock", "account:"+email, lockedFor(4*time.Second)).Return(nil).Once()
+
+		assert.NoError(t, guard.Fail(user, email, ip))
+		attempts.AssertExpectations(t)
+	})
+
+	t.Run("locks the account and records an event", func(t *testing.T) {
+		guard, attempts, events := setup(10, 10)
+		attempts.On("Lock", "account:"+email, lockedFor(15*time.Minute)).Return(nil).Once()
+		events.On("Save", mock.MatchedBy(func(event *domain.SecurityEvent) bool {
+			return event.Type == domain.SecurityEventAccountLocked && *event.UserID == user.ID && event.IPAddress == ip
+		})).Return(nil).Once()
+
+		assert.NoError(t, guard.Fail(user, email, ip))
+		attempts.AssertExpectations(t)
+		events.AssertExpectations(t)
+	})
+
+	t.Run("locks unknown emails the same way", func(t *testing.T) {
+		guard, attempts, events := setup(10, 10)
+		attempts.On("Lock", "account:"+email, mock.Anything).Return(nil).Once()
+		events.On("Save", mock.MatchedBy(func(event *domain.SecurityEvent) bool {
+			return event.Type == domain.SecurityEventAccountLocked && event.UserID == nil && event.Email == email
+		})).Return(nil).Once()
+
+		assert.NoError(t, guard.Fail(nil, email, ip))
+		events.AssertExpectations(t)
+	})
+
+	t.Run("locks the client IP", func(t *testing.T) {
+		guard, attempts, events := setup(1, 50)
+		attempts.On("Lock", "ip:"+ip, lockedFor(15*time.Minute)).Return(nil).Once()
+		events.On("Save", mock.MatchedBy(func(event *domain.SecurityEvent) bool {
+			return event.Type == domain.SecurityEventIPLocked && event.IPAddress == ip
+		})).Return(nil).Once()
+
+		assert.NoError(t, guard.Fail(user, email, ip))
+		attempts.AssertExpectations(t)
+		events.AssertExpectations(t)
+	})
+}
+
+func TestLoginGuard_Unlock(t *testing.T) {
+	attempts := new(MockLoginAttemptStore)
+	events := new(MockSecurityEventRepository)
+	guard := NewLoginGuard(attempts, events, DefaultLoginPolicy)
+	user, _ := domain.NewUser("Locked@example.com", "password123", domain.RoleViewer)
+	actorID := uuid.New()
+
+	attempts.On("Reset", "account:locked@example.com").Return(nil).Once()
+	events.On("Save", mock.MatchedBy(func(event *domain.SecurityEvent) bool {
+		return event.Type == domain.SecurityEventAccountUnlocked && *event.UserID == user.ID && *event.ActorID == actorID
+	})).Return(nil).Once()
+
+	assert.NoError(t, guard.Unlock(actorID, user))
+	attempts.AssertExpectations(t)
+	events.AssertExpectations(t)
+}
@@ -0,0 +1,212 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"threat-intel-backend/domain"
+	"github.com/google/uuid"
+)
+
+// lookupQueryBatch bounds the observables looked up per query, keeping the
+// query well below the bind parameter limit of the database.
+const lookupQueryBatch = 1000
+
+var ErrTooManyObservables = errors.New("too many observables")
+
+type LookupService struct {
+	indicatorRepo   domain.IndicatorRepository
+	sightingRepo    domain.SightingRepository
+	userRepo        domain.UserRepository
+	entitlementRepo domain.EntitlementRepository
+	cache           domain.LookupCache
+	maxObservables  int
+}
+
+type LookupRequest struct {
+	Observables []string `json:"observables" binding:"required,min=1"`
+}
+
+// LookupResult is a match for one of the submitted observables.
+type LookupResult struct {
+	Observable string `json:"observable"`
+	domain.LookupMatch
+}
+
+// LookupResponse lists the matches in the order the observables were
+// submitted. Unrecognized observables have no indicator type; restricted
+// ones have a type outside the caller's entitlement and were not looked up.
+type LookupResponse struct {
+	Total        int            `json:"total"`
+	Matches      []LookupResult `json:"matches"`
+	Unrecognized []string       `json:"unrecognized"`
+	Restricted   []string       `json:"restricted"`
+}
+
+func NewLookupService(indicatorRepo domain.IndicatorRepository, sightingRepo domain.SightingRepository, userRepo domain.UserRepository, entitlementRepo domain.EntitlementRepository, cache domain.LookupCache, maxObservables int) *LookupService {
+	return &LookupService{
+		indicatorRepo:   indicatorRepo,
+		sightingRepo:    sightingRepo,
+		userRepo:        userRepo,
+		entitlementRepo: entitlementRepo,
+		cache:           cache,
+		maxObservables:  maxObservables,
+	}
+}
+
+// Lookup detects the type of every observable, refanging defanged ones, and
+// returns the indicators that are known and not expired. Results are served
+// from the cache when it holds them.
+func (s *LookupService) Lookup(ctx context.Context, userID uuid.UUID, observables []string) (*LookupResponse, error) {
+	if len(observables) > s.maxObservables {
+		return nil, fmt.Errorf("%w: at most %d per lookup", ErrTooManyObservables, s.maxObservables)
+	}
+
+	tier, err := requireEntitlement(s.userRepo, s.entitlementRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &LookupResponse{
+		Total:        len(observables),
+		Matches:      []LookupResult{},
+		Unrecognized: []string{},
+		Restricted:   []string{},
+	}
+	keys := make([]domain.IndicatorKey, len(observables))
+	var unique []domain.IndicatorKey
+	seen := make(map[domain.IndicatorKey]bool, len(observables))
+	for i, observable := range observables {
+		value := strings.TrimSpace(observable)
+		indicatorType := domain.DetectIndicatorType(value)
+		normalized, err := domain.NormalizeIndicatorValue(indicatorType, value)
+		if err != nil {
+			response.Unrecognized = append(response.Unrecognized, observable)
+			continue
+		}
+		if !tier.AllowsIndicatorType(indicatorType) {
+			response.Restricted = append(response.Restricted, observable)
+			continue
+		}
+
+		keys[i] = domain.IndicatorKey{Type: indicatorType, Value: normalized}
+		if !seen[keys[i]] {
+			seen[keys[i]] = true
+			unique = append(unique, keys[i])
+		}
+	}
+
+	matches, err := s.findMatches(ctx, unique)
+	if err != nil {
+		return nil, err
+	}
+	for i, observable := range observables {
+		if match := matches[keys[i]]; match != nil {
+			response.Matches = append(response.Matches, LookupResult{Observable: observable, LookupMatch: *match})
+		}
+	}
+
+	return response, nil
+}
+
+// findMatches returns the match of every key, nil for unknown ones. The cache
+// only saves work: when it fails, everything is read from the database.
+func (s *LookupService) findMatches(ctx context.Context, keys []domain.IndicatorKey) (map[domain.IndicatorKey]*domain.LookupMatch, error) {
+	matches, err := s.cache.Get(ctx, keys)
+	if err != nil {
+		matches = map[domain.IndicatorKey]*domain.LookupMatch{}
+	}
+
+	var misses []domain.IndicatorKey
+	for _, key := range keys {
+		if _, ok := matches[key]; !ok {
+			misses = append(misses, key)
+		}
+	}
+	if len(misses) == 0 {
+		return matches, nil
+	}
+
+	found := make(map[domain.IndicatorKey]*domain.LookupMatch, len(misses))
+	for _, key := range misses {
+		found[key] = nil
+	}
+	now := time.Now()
+	for start := 0; start < len(misses); start += lookupQueryBatch {
+		end := start + lookupQueryBatch
+		if end > len(misses) {
+			end = len(misses)
+		}
+		if err := s.loadMatches(misses[start:end], found, now); err != nil {
+			return nil, err
+		}
+	}
+
+	_ = s.cache.Set(ctx, found)
+	for key, match := range found {
+		matches[key] = match
+	}
+	return matches, nil
+}
+
+// loadMatches reads the indicators of keys with their sources into found.
+func (s *LookupService) loadMatches(keys []domain.IndicatorKey, found map[domain.IndicatorKey]*domain.LookupMatch, now time.Time) error {
+	indicators, err := s.indicatorRepo.FindByKeys(keys)
+	if err != nil {
+		return err
+	}
+
+	var ids []uuid.UUID
+	for _, indicator := range indicators {
+		if !indicator.IsExpired(now) {
+			ids = append(ids, indicator.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	sightings, err := s.sightingRepo.ListByIndicatorIDs(ids)
+	if err != nil {
+		return err
+	}
+	sources := make(map[uuid.UUID][]string, len(ids))
+	for _, sighting := range sightings {
+		sources[sighting.IndicatorID] = append(sources[sighting.IndicatorID], sighting.Source)
+	}
+
+	for _, indicator := range indicators {
+		if indicator.IsExpired(now) {
+			continue
+		}
+		names := sources[indicator.ID]
+		if len(names) == 0 && indicator.Source != "" {
+			names = []string{indicator.Source}
+		}
+		sort.Strings(names)
+
+		found[indicator.Key()] = &domain.LookupMatch{
+			IndicatorID: indicator.ID,
+			Type:        indicator.Type,
+			Value:       indicator.Value,
+			Score:       indicator.Score,
+			Confidence:  indicator.Confidence,
+			Severity:    indicator.Severity,
+			Tags:        indicator.Tags,
+			Sources:     append([]string{}, names...),
+			FirstSeen:   indicator.FirstSeen,
+			LastSeen:    indicator.LastSeen,
+			ExpiresAt:   indicator.ExpiresAt,
+		}
+	}
+	return nil
+}
+
+// forgetLookups drops the cached lookup results of written indicators, so
+// that lookups see the write at once. When that fails, the old results are
+// served until the cache TTL runs out.
+func forgetLookups(ctx context.Context, cache domain.LookupCache, keys []domain.IndicatorKey) {
+	_ = cache.Delete(ctx, keys)
+}
@@ -0,0 +1,289 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"threat-intel-backend/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryLookupCache is a LookupCache in a map; err fails every call.
+type memoryLookupCache struct {
+	mu      sync.Mutex
+	entries map[domain.IndicatorKey]*domain.LookupMatch
+	err     error
+}
+
+func newMemoryLookupCache() *memoryLookupCache {
+	return &memoryLookupCache{entries: map[domain.IndicatorKey]*domain.LookupMatch{}}
+}
+
+func (c *memoryLookupCache) Get(ctx context.Context, keys []domain.IndicatorKey) (map[domain.IndicatorKey]*domain.LookupMatch, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return nil, c.err
+	}
+	cached := map[domain.IndicatorKey]*domain.LookupMatch{}
+	for _, key := range keys {
+		if match, ok := c.entries[key]; ok {
+			cached[key] = match
+		}
+	}
+	return cached, nil
+}
+
+func (c *memoryLookupCache) Set(ctx context.Context, results map[domain.IndicatorKey]*domain.LookupMatch) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	for key, match := range results {
+		c.entries[key] = match
+	}
+	return nil
+}
+
+func (c *memoryLookupCache) Delete(ctx context.Context, keys []domain.IndicatorKey) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+	return nil
+}
+
+type lookupServiceMocks struct {
+	indicators   *MockIndicatorRepository
+	sightings    *MockSightingRepository
+	entitlements *MockEntitlementRepository
+	cache        *memoryLookupCache
+}
+
+func setupLookupService(maxObservables int) (*LookupService, *lookupServiceMocks, *domain.User, *domain.User) {
+	mocks := &lookupServiceMocks{
+		indicators:   new(MockIndicatorRepository),
+		sightings:    new(MockSightingRepository),
+		entitlements: new(MockEntitlementRepository),
+		cache:        newMemoryLookupCache(),
+	}
+	mockUsers := new(MockUserRepository)
+	analyst, _ := domain.NewUser("analyst@example.com", "password123", domain.RoleAnalyst)
+	viewer, _ := domain.NewUser("viewer@example.com", "password123", domain.RoleViewer)
+	mockUsers.On("FindByID", analyst.ID).Return(analyst, nil)
+	mockUsers.On("FindByID", viewer.ID).Return(viewer, nil)
+
+	service := NewLookupService(mocks.indicators, mocks.sightings, mockUsers, mocks.entitlements, mocks.cache, maxObservables)
+	return service, mocks, analyst, viewer
+}
+
+func TestLookupService_Lookup(t *testing.T) {
+	domainIndicator, _ := domain.NewIndicator(domain.IndicatorDomain, "evil.example.com", 70, domain.SeverityHigh)
+	domainIndicator.Score = 62
+	domainIndicator.Tags = domain.Tags{"phishing"}
+	ipIndicator, _ := domain.NewIndicator(domain.IndicatorIPv4, "192.0.2.1", 50, "")
+	ipIndicator.Source = "manual"
+	expiredAt := time.Now().Add(-time.Hour)
+	expired, _ := domain.NewIndicator(domain.IndicatorURL, "http://old.example.com/", 50, "")
+	expired.ExpiresAt = &expiredAt
+	hash, _ := domain.NewIndicator(domain.IndicatorMD5, "d41d8cd98f00b204e9800998ecf8427e", 50, "")
+
+	observables := []string{
+		"evil[.]example[.]com",
+		"192.0.2.1",
+		"not an observable",
+		"EVIL.example.com",
+		"hxxp://old[.]example[.]com/",
+		"198.51.100.7",
+		"d41d8cd98f00b204e9800998ecf8427e",
+	}
+	keys := []domain.IndicatorKey{
+		{Type: domain.IndicatorDomain, Value: "evil.example.com"},
+		{Type: domain.IndicatorIPv4, Value: "192.0.2.1"},
+		{Type: domain.IndicatorURL, Value: "http://old.example.com/"},
+		{Type: domain.IndicatorIPv4, Value: "198.51.100.7"},
+		{Type: domain.IndicatorMD5, Value: "D41D8CD98F00B204E9800998ECF8427E"},
+	}
+
+	t.Run("matches in submission order and caches the results", func(t *testing.T) {
+		service, mocks, analyst, _ := setupLookupService(100)
+		mocks.indicators.On("FindByKeys", keys).Return([]*domain.Indicator{domainIndicator, ipIndicator, expired}, nil).Once()
+		mocks.sightings.On("ListByIndicatorIDs", []uuid.UUID{domainIndicator.ID, ipIndicator.ID}).Return([]*domain.Sighting{
+			domain.NewSighting(domainIndicator.ID, "openphish"),
+			domain.NewSighting(domainIndicator.ID, "abuse-ch-urlhaus"),
+		}, nil).Once()
+
+		resp, err := service.Lookup(context.Background(), analyst.ID, observables)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 7, resp.Total)
+		assert.Equal(t, []string{"not an observable"}, resp.Unrecognized)
+		assert.Empty(t, resp.Restricted)
+		if assert.Len(t, resp.Matches, 3) {
+			assert.Equal(t, "evil[.]example[.]com", resp.Matches[0].Observable)
+			assert.Equal(t, 62, resp.Matches[0].Score)
+			assert.Equal(t, domain.Tags{"phishing"}, resp.Matches[0].Tags)
+			assert.Equal(t, []string{"abuse-ch-urlhaus", "openphish"}, resp.Matches[0].Sources)
+			assert.Equal(t, "192.0.2.1", resp.Matches[1].Observable)
+			assert.Equal(t, []string{"manual"}, resp.Matches[1].Sources)
+			assert.Equal(t, "EVIL.example.com", resp.Matches[2].Observable)
+			assert.Equal(t, domainIndicator.ID, resp.Matches[2].IndicatorID)
+		}
+		assert.Len(t, mocks.cache.entries, 5)
+		assert.Nil(t, mocks.cache.entries[keys[3]], "misses are cached")
+
+		cached, err := service.Lookup(context.Background(), analyst.ID, observables)
+
+		assert.NoError(t, err)
+		assert.Equal(t, resp, cached)
+		mocks.indicators.AssertExpectations(t)
+	})
+
+	t.Run("falls back to the database when the cache fails", func(t *testing.T) {
+		service, mocks, analyst, _ := setupLookupService(100)
+		mocks.cache.err = errors.New("connection refused")
+		mocks.indicators.On("FindByKeys", []domain.IndicatorKey{keys[1]}).Return([]*domain.Indicator{ipIndicator}, nil)
+		mocks.sightings.On("ListByIndicatorIDs", []uuid.UUID{ipIndicator.ID}).Return([]*domain.Sighting{}, nil)
+
+		resp, err := service.Lookup(context.Background(), analyst.ID, []string{"192[.]0[.]2[.]1"})
+
+		assert.NoError(t, err)
+		assert.Len(t, resp.Matches, 1)
+	})
+
+	t.Run("leaves out types outside the entitlement", func(t *testing.T) {
+		service, mocks, _, viewer := setupLookupService(100)
+		mocks.entitlements.On("FindByUserID", viewer.ID).Return([]*domain.Entitlement{activeEntitlement(viewer.ID, domain.TierBasic)}, nil)
+		mocks.indicators.On("FindByKeys", []domain.IndicatorKey{keys[1]}).Return([]*domain.Indicator{}, nil)
+
+		resp, err := service.Lookup(context.Background(), viewer.ID, []string{"192.0.2.1", hash.Value})
+
+		assert.NoError(t, err)
+		assert.Empty(t, resp.Matches)
+		assert.Equal(t, []string{hash.Value}, resp.Restricted)
+	})
+
+	t.Run("requires an entitlement", func(t *testing.T) {
+		service, mocks, _, viewer := setupLookupService(100)
+		mocks.entitlements.On("FindByUserID", viewer.ID).Return([]*domain.Entitlement{}, nil)
+
+		_, err := service.Lookup(context.Background(), viewer.ID, []string{"192.0.2.1"})
+
+		assert.Equal(t, ErrEntitlementRequired, err)
+	})
+
+	t.Run("bounds the batch", func(t *testing.T) {
+		service, _, analyst, _ := setupLookupService(2)
+
+		_, err := service.Lookup(context.Background(), analyst.ID, []string{"a.example.com", "b.example.com", "c.example.com"})
+
+		assert.True(t, errors.Is(err, ErrTooManyObservables))
+	})
+}
+
+// memoryIndicatorRepository serves FindByKeys from a map.
+type memoryIndicatorRepository struct {
+	*MockIndicatorRepository
+	indicators map[domain.IndicatorKey]*domain.Indicator
+}
+
+func (r *memoryIndicatorRepository) FindByKeys(keys []domain.IndicatorKey) ([]*domain.Indicator, error) {
+	var found []*domain.Indicator
+	for _, key := range keys {
+		if indicator, ok := r.indicators[key]; ok {
+			found = append(found, indicator)
+		}
+	}
+	return found, nil
+}
+
+// memorySightingRepository serves ListByIndicatorIDs from a map.
+type memorySightingRepository struct {
+	*MockSightingRepository
+	sightings map[uuid.UUID][]*domain.Sighting
+}
+
+func (r *memorySightingRepository) ListByIndicatorIDs(indicatorIDs []uuid.UUID) ([]*domain.Sighting, error) {
+	var found []*domain.Sighting
+	for _, id := range indicatorIDs {
+		found = append(found, r.sightings[id]...)
+	}
+	return found, nil
+}
+
+// lookupBatch returns 10k mixed observables of which every fifth is known,
+// along with the repositories that know them.
+func lookupBatch() ([]string, *memoryIndicatorRepository, *memorySightingRepository) {
+	indicators := &memoryIndicatorRepository{indicators: map[domain.IndicatorKey]*domain.Indicator{}}
+	sightings := &memorySightingRepository{sightings: map[uuid.UUID][]*domain.Sighting{}}
+
+	observables := make([]string, 10000)
+	for i := range observables {
+		switch i % 4 {
+		case 0:
+			observables[i] = fmt.Sprintf("10.%d.%d.%d", i>>16&255, i>>8&255, i&255)
+		case 1:
+			observables[i] = fmt.Sprintf("host-%d[.]example[.]com", i)
+		case 2:
+			observables[i] = fmt.Sprintf("hxxps://cdn-%d.example.net/payload.bin", i)
+		default:
+			observables[i] = strings.Repeat("0", 64-len(fmt.Sprint(i))) + fmt.Sprint(i)
+		}
+		if i%5 != 0 {
+			continue
+		}
+
+		indicatorType := domain.DetectIndicatorType(observables[i])
+		indicator, _ := domain.NewIndicator(indicatorType, observables[i], 60, domain.SeverityHigh)
+		indicator.Tags = domain.Tags{"benchmark"}
+		indicators.indicators[domain.IndicatorKey{Type: indicator.Type, Value: indicator.Value}] = indicator
+		sightings.sightings[indicator.ID] = []*domain.Sighting{
+			domain.NewSighting(indicator.ID, "abuse-ch-urlhaus"),
+			domain.NewSighting(indicator.ID, "openphish"),
+		}
+	}
+	return observables, indicators, sightings
+}
+
+func BenchmarkLookupService_Lookup10k(b *testing.B) {
+	observables, indicators, sightings := lookupBatch()
+	mockUsers := new(MockUserRepository)
+	analyst, _ := domain.NewUser("analyst@example.com", "password123", domain.RoleAnalyst)
+	mockUsers.On("FindByID", analyst.ID).Return(analyst, nil)
+
+	run := func(b *testing.B, cache *memoryLookupCache, warm bool) {
+		service := NewLookupService(indicators, sightings, mockUsers, new(MockEntitlementRepository), cache, len(observables))
+		if warm {
+			if _, err := service.Lookup(context.Background(), analyst.ID, observables); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if !warm {
+				b.StopTimer()
+				cache.entries = map[domain.IndicatorKey]*domain.LookupMatch{}
+				b.StartTimer()
+			}
+			resp, err := service.Lookup(context.Background(), analyst.ID, observables)
+			if err != nil || len(resp.Matches) != len(observables)/5 {
+				b.Fatalf("lookup failed: %v", err)
+			}
+		}
+	}
+
+	b.Run("cold", func(b *testing.B) { run(b, newMemoryLookupCache(), false) })
+	b.Run("cached", func(b *testing.B) { run(b, newMemoryLookupCache(), true) })
+}
@@ -0,0 +1,316 @@
+package application
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+	"threat-intel-backend/domain"
+	"threat-intel-backend/infrastructure/totp"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrMFAAlreadyEnabled = errors.New("MFA is already enabled")
+	ErrMFANotEnrolled    = errors.New("MFA is not enrolled")
+	ErrInvalidMFACode    = errors.New("invalid MFA code")
+	ErrMFARequired       = errors.New("MFA is required for this role")
+	ErrInvalidMFAToken   = errors.New("invalid or expired MFA token")
+)
+
+const (
+	// mfaSkew accepts codes of the neighbouring time steps to allow for clock
+	// drift between the server and the authenticator.
+	mfaSkew = 1
+
+	recoveryCodeCount = 10
+)
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type MFAService struct {
+	factorRepo domain.MFAFactorRepository
+	policyRepo domain.MFAPolicyRepository
+	userRepo   domain.UserRepository
+	issuer     string
+}
+
+type MFAStatusResponse struct {
+	Enabled                bool       `json:"enabled"`
+	Required               bool       `json:"required"`
+	ConfirmedAt            *time.Time `json:"confirmed_at,omitempty"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+}
+
+// MFAEnrollmentResponse carries the secret of a pending factor, both raw for
+// manual entry and as an otpauth:// URI to render as a QR code.
+type MFAEnrollmentResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// MFACodeRequest carries a TOTP code or, where accepted, a recovery code.
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// RecoveryCodesResponse returns recovery codes in clear text. They are only
+// shown once; the server keeps their hashes.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type SetMFAPolicyRequest struct {
+	Required *bool `json:"required" binding:"required"`
+}
+
+func NewMFAService(factorRepo domain.MFAFactorRepository, policyRepo domain.MFAPolicyRepository, userRepo domain.UserRepository, issuer string) *MFAService {
+	return &MFAService{
+		factorRepo: factorRepo,
+		policyRepo: policyRepo,
+		userRepo:   userRepo,
+		issuer:     issuer,
+	}
+}
+
+func (s *MFAService) Status(userID uuid.UUID) (*MFAStatusResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	status := &MFAStatusResponse{Required: s.roleRequiresMFA(user.Role)}
+	if factor := s.confirmedFactor(userID); factor != nil {
+		status.Enabled = true
+		status.ConfirmedAt = factor.ConfirmedAt
+		status.RecoveryCodesRemaining = len(factor.RecoveryCodes)
+	}
+	return status, nil
+}
+
+// BeginEnrollment creates a pending factor with a new secret, replacing any
+// earlier pending one. It does not protect logins until it is confirmed.
+func (s *MFAService) BeginEnrollment(userID uuid.UUID) (*MFAEnrollmentResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	if s.confirmedFactor(userID) != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.factorRepo.Save(domain.NewMFAFactor(userID, secret)); err != nil {
+		return nil, err
+	}
+
+	return &MFAEnrollmentResponse{
+		Secret: secret,
+		URI:    totp.URI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmEnrollment enables the pending factor once the user proves that the
+// authenticator produces valid codes, and returns the first recovery codes.
+func (s *MFAService) ConfirmEnrollment(userID uuid.UUID, req MFACodeRequest) (*RecoveryCodesResponse, error) {
+	factor, err := s.factorRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, ErrMFANotEnrolled
+	}
+	if factor.IsConfirmed() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	if !s.useTOTP(factor, req.Code) {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, err := s.newRecoveryCodes(factor)
+	if err != nil {
+		return nil, err
+	}
+	factor.Confirm()
+
+	if err := s.factorRepo.Save(factor); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable removes the factor after checking a TOTP or recovery code. Users
+// whose role requires MFA cannot disable it.
+func (s *MFAService) Disable(userID uuid.UUID, req MFACodeRequest) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+
+	if s.roleRequiresMFA(user.Role) {
+		return ErrMFARequired
+	}
+
+	if err := s.Verify(userID, req.Code); err != nil {
+		return err
+	}
+	return s.factorRepo.Delete(userID)
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes after checking a TOTP
+// code.
+func (s *MFAService) RegenerateRecoveryCodes(userID uuid.UUID, req MFACodeRequest) (*RecoveryCodesResponse, error) {
+	factor := s.confirmedFactor(userID)
+	if factor == nil {
+		return nil, ErrMFANotEnrolled
+	}
+
+	if !s.useTOTP(factor, req.Code) {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, err := s.newRecoveryCodes(factor)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.factorRepo.Save(factor); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Verify checks a TOTP code, or consumes a recovery code, against the
+// confirmed factor of the user.
+func (s *MFAService) Verify(userID uuid.UUID, code string) error {
+	factor := s.confirmedFactor(userID)
+	if factor == nil {
+		return ErrMFANotEnrolled
+	}
+
+	if !s.useTOTP(factor, code) && !factor.UseRecoveryCode(hashRecoveryCode(code)) {
+		return ErrInvalidMFACode
+	}
+	return s.factorRepo.Save(factor)
+}
+
+// Requirement reports whether the user has a confirmed factor and whether
+// their role requires one.
+func (s *MFAService) Requirement(user *domain.User) (enrolled bool, required bool) {
+	return s.confirmedFactor(user.ID) != nil, s.roleRequiresMFA(user.Role)
+}
+
+// ResetMFA removes the factor of a user who lost both their authenticator
+// and their recovery codes. Only admins may reset.
+func (s *MFAService) ResetMFA(actorID, userID uuid.UUID) error {
+	if err := requireRole(s.userRepo, actorID, domain.RoleAdmin); err != nil {
+		return err
+	}
+
+	if _, err := s.userRepo.FindByID(userID); err != nil {
+		return ErrUserNotFound
+	}
+	return s.factorRepo.Delete(userID)
+}
+
+// ListPolicies returns the policy of every role, including the roles that
+// never had one stored.
+func (s *MFAService) ListPolicies(actorID uuid.UUID) ([]*domain.MFAPolicy, error) {
+	if err := requireRole(s.userRepo, actorID, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	stored, err := s.policyRepo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	byRole := make(map[domain.UserRole]*domain.MFAPolicy, len(stored))
+	for _, policy := range stored {
+		byRole[policy.Role] = policy
+	}
+
+	policies := make([]*domain.MFAPolicy, 0, 3)
+	for _, role := range []domain.UserRole{domain.RoleAdmin, domain.RoleAnalyst, domain.RoleViewer} {
+		if policy, ok := byRole[role]; ok {
+			policies = append(policies, policy)
+		} else {
+			policies = append(policies, &domain.MFAPolicy{Role: role})
+		}
+	}
+	return policies, nil
+}
+
+// SetPolicy makes MFA required or optional for every user of a role. Users
+// of a role that requires MFA who are not enrolled must enroll at their next
+// login; sessions they already hold are not affected.
+func (s *MFAService) SetPolicy(actorID uuid.UUID, role domain.UserRole, req SetMFAPolicyRequest) (*domain.MFAPolicy, error) {
+	if err := requireRole(s.userRepo, actorID, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	if !role.IsValid() {
+		return nil, ErrInvalidRole
+	}
+
+	policy := &domain.MFAPolicy{
+		Role:      role,
+		Required:  *req.Required,
+		UpdatedBy: &actorID,
+		UpdatedAt: time.Now(),
+	}
+	if err := s.policyRepo.Save(policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (s *MFAService) roleRequiresMFA(role domain.UserRole) bool {
+	policy, err := s.policyRepo.FindByRole(role)
+	return err == nil && policy.Required
+}
+
+func (s *MFAService) confirmedFactor(userID uuid.UUID) *domain.MFAFactor {
+	factor, err := s.factorRepo.FindByUserID(userID)
+	if err != nil || !factor.IsConfirmed() {
+		return nil
+	}
+	return factor
+}
+
+// useTOTP accepts a code of the factor that is newer than the last one used.
+func (s *MFAService) useTOTP(factor *domain.MFAFactor, code string) bool {
+	step, ok := totp.Validate(factor.Secret, code, time.Now(), mfaSkew)
+	return ok && factor.UseStep(step)
+}
+
+func (s *MFAService) newRecoveryCodes(factor *domain.MFAFactor) (*RecoveryCodesResponse, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(recoveryEncoding.EncodeToString(raw))
+		codes[i] = code[:4] + "-" + code[4:]
+		hashes[i] = hashRecoveryCode(code)
+	}
+
+	factor.SetRecoveryCodes(hashes)
+	return &RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// hashRecoveryCode ignores case, spaces and dashes, so that codes can be
+// typed the way they were displayed or without the separator.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
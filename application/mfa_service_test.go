@@ -0,0 +1,233 @@
+package application
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"threat-intel-backend/domain"
+	"threat-intel-backend/infrastructure/totp"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMFAFactorRepository struct {
+	mock.Mock
+}
+
+func (m *MockMFAFactorRepository) Save(factor *domain.MFAFactor) error {
+	args := m.Called(factor)
+	return args.Error(0)
+}
+
+func (m *MockMFAFactorRepository) FindByUserID(userID uuid.UUID) (*domain.MFAFactor, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.MFAFactor), args.Error(1)
+}
+
+func (m *MockMFAFactorRepository) Delete(userID uuid.UUID) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+type MockMFAPolicyRepository struct {
+	mock.Mock
+}
+
+func (m *MockMFAPolicyRepository) Save(policy *domain.MFAPolicy) error {
+	args := m.Called(policy)
+	return args.Error(0)
+}
+
+func (m *MockMFAPolicyRepository) FindByRole(role domain.UserRole) (*domain.MFAPolicy, error) {
+	args := m.Called(role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.MFAPolicy), args.Error(1)
+}
+
+func (m *MockMFAPolicyRepository) List() ([]*domain.MFAPolicy, error) {
+	args := m.Called()
+	return args.Get(0).([]*domain.MFAPolicy), args.Error(1)
+}
+
+// noMFA is an MFAService under which nobody is enrolled and no role requires
+// MFA, for tests that are not about MFA.
+func noMFA() *MFAService {
+	factors := new(MockMFAFactorRepository)
+	factors.On("FindByUserID", mock.Anything).Return(nil, errors.New("record not found")).Maybe()
+	policies := new(MockMFAPolicyRepository)
+	policies.On("FindByRole", mock.Anything).Return(nil, errors.New("record not found")).Maybe()
+	return NewMFAService(factors, policies, new(MockUserRepository), "Test")
+}
+
+func currentCode(t *testing.T, secret string) string {
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	assert.NoError(t, err)
+	return code
+}
+
+func confirmedFactor(userID uuid.UUID) *domain.MFAFactor {
+	secret, _ := totp.GenerateSecret()
+	factor := domain.NewMFAFactor(userID, secret)
+	factor.Confirm()
+	return factor
+}
+
+func setupMFAService() (*MFAService, *MockMFAFactorRepository, *MockMFAPolicyRepository, *domain.User, *domain.User) {
+	mockFactors := new(MockMFAFactorRepository)
+	mockPolicies := new(MockMFAPolicyRepository)
+	mockUsers := new(MockUserRepository)
+	admin, _ := domain.NewUser("admin@example.com", "password123", domain.RoleAdmin)
+	analyst, _ := domain.NewUser("analyst@example.com", "password123", domain.RoleAnalyst)
+	mockUsers.On("FindByID", admin.ID).Return(admin, nil)
+	mockUsers.On("FindByID", analyst.ID).Return(analyst, nil)
+
+	return NewMFAService(mockFactors, mockPolicies, mockUsers, "Zentara"), mockFactors, mockPolicies, admin, analyst
+}
+
+func TestMFAService_Enrollment(t *testing.T) {
+	service, mockFactors, _, _, analyst := setupMFAService()
+
+	t.Run("begins and confirms enrollment", func(t *testing.T) {
+		var pending *domain.MFAFactor
+		mockFactors.On("FindByUserID", analyst.ID).Return(nil, errors.New("record not found")).Once()
+		mockFactors.On("Save", mock.MatchedBy(func(factor *domain.MFAFactor) bool {
+			pending = factor
+			return !factor.IsConfirmed()
+		})).Return(nil).Once()
+
+		enrollment, err := service.BeginEnrollment(analyst.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, pending.Secret, enrollment.Secret)
+		assert.True(t, strings.HasPrefix(enrollment.URI, "otpauth://totp/Zentara:analyst@example.com?"))
+
+		mockFactors.On("FindByUserID", analyst.ID).Return(pending, nil).Once()
+		mockFactors.On("Save", pending).Return(nil).Once()
+
+		codes, err := service.ConfirmEnrollment(analyst.ID, MFACodeRequest{Code: currentCode(t, pending.Secret)})
+
+		assert.NoError(t, err)
+		assert.Len(t, codes.RecoveryCodes, recoveryCodeCount)
+		assert.True(t, pending.IsConfirmed())
+		assert.Len(t, pending.RecoveryCodes, recoveryCodeCount)
+		assert.NotContains(t, pending.RecoveryCodes, codes.RecoveryCodes[0])
+		mockFactors.AssertExpectations(t)
+	})
+
+	t.Run("rejects wrong confirmation code", func(t *testing.T) {
+		secret, _ := totp.GenerateSecret()
+		mockFactors.On("FindByUserID", analyst.ID).Return(domain.NewMFAFactor(analyst.ID, secret), nil).Once()
+
+		_, err := service.ConfirmEnrollment(analyst.ID, MFACodeRequest{Code: "000000"})
+
+		assert.Equal(t, ErrInvalidMFACode, err)
+	})
+
+	t.Run("cannot enroll twice", func(t *testing.T) {
+		mockFactors.On("FindByUserID", analyst.ID).Return(confirmedFactor(analyst.ID), nil).Once()
+
+		_, err := service.BeginEnrollment(analyst.ID)
+
+		assert.Equal(t, ErrMFAAlreadyEnabled, err)
+	})
+}
+
+func TestMFAService_Verify(t *testing.T) {
+	service, mockFactors, _, _, analyst := setupMFAService()
+	factor := confirmedFactor(analyst.ID)
+	codes, _ := service.newRecoveryCodes(factor)
+	mockFactors.On("FindByUserID", analyst.ID).Return(factor, nil)
+	mockFactors.On("Save", factor).Return(nil)
+
+	t.Run("accepts a TOTP code once", func(t *testing.T) {
+		code := currentCode(t, factor.Secret)
+
+		assert.NoError(t, service.Verify(analyst.ID, code))
+		assert.Equal(t, ErrInvalidMFACode, service.Verify(analyst.ID, code))
+	})
+
+	t.Run("accepts a recovery code once", func(t *testing.T) {
+		code := strings.ToUpper(codes.RecoveryCodes[0])
+
+		assert.NoError(t, service.Verify(analyst.ID, code))
+		assert.Equal(t, ErrInvalidMFACode, service.Verify(analyst.ID, code))
+		assert.Len(t, factor.RecoveryCodes, recoveryCodeCount-1)
+	})
+
+	t.Run("not enrolled", func(t *testing.T) {
+		userID := uuid.New()
+		mockFactors.On("FindByUserID", userID).Return(nil, errors.New("record not found")).Once()
+
+		assert.Equal(t, ErrMFANotEnrolled, service.Verify(userID, "123456"))
+	})
+}
+
+func TestMFAService_Disable(t *testing.T) {
+	service, mockFactors, mockPolicies, admin, analyst := setupMFAService()
+
+	t.Run("disables with a valid code", func(t *testing.T) {
+		factor := confirmedFactor(analyst.ID)
+		mockPolicies.On("FindByRole", domain.RoleAnalyst).Return(nil, errors.New("record not found")).Once()
+		mockFactors.On("FindByUserID", analyst.ID).Return(factor, nil).Once()
+		mockFactors.On("Save", factor).Return(nil).Once()
+		mockFactors.On("Delete", analyst.ID).Return(nil).Once()
+
+		assert.NoError(t, service.Disable(analyst.ID, MFACodeRequest{Code: currentCode(t, factor.Secret)}))
+		mockFactors.AssertExpectations(t)
+	})
+
+	t.Run("cannot disable when the role requires MFA", func(t *testing.T) {
+		mockPolicies.On("FindByRole", domain.RoleAdmin).Return(&domain.MFAPolicy{Role: domain.RoleAdmin, Required: true}, nil).Once()
+
+		assert.Equal(t, ErrMFARequired, service.Disable(admin.ID, MFACodeRequest{Code: "123456"}))
+	})
+}
+
+func TestMFAService_Policies(t *testing.T) {
+	service, _, mockPolicies, admin, analyst := setupMFAService()
+
+	t.Run("lists a policy for every role", func(t *testing.T) {
+		mockPolicies.On("List").Return([]*domain.MFAPolicy{{Role: domain.RoleAnalyst, Required: true}}, nil).Once()
+
+		policies, err := service.ListPolicies(admin.ID)
+
+		assert.NoError(t, err)
+		assert.Len(t, policies, 3)
+		assert.Equal(t, domain.RoleAdmin, policies[0].Role)
+		assert.False(t, policies[0].Required)
+		assert.True(t, policies[1].Required)
+	})
+
+	t.Run("sets a policy", func(t *testing.T) {
+		required := true
+		mockPolicies.On("Save", mock.AnythingOfType("*domain.MFAPolicy")).Return(nil).Once()
+
+		policy, err := service.SetPolicy(admin.ID, domain.RoleAdmin, SetMFAPolicyRequest{Required: &required})
+
+		assert.NoError(t, err)
+		assert.True(t, policy.Required)
+		assert.Equal(t, admin.ID, *policy.UpdatedBy)
+	})
+
+	t.Run("rejects unknown role", func(t *testing.T) {
+		required := true
+
+		_, err := service.SetPolicy(admin.ID, "root", SetMFAPolicyRequest{Required: &required})
+
+		assert.Equal(t, ErrInvalidRole, err)
+	})
+
+	t.Run("requires admin", func(t *testing.T) {
+		_, err := service.ListPolicies(analyst.ID)
+
+		assert.Equal(t, ErrInsufficientPermissions, err)
+	})
+}
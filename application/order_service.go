@@ -2,13 +2,24 @@ package application
 
 import (
 	"errors"
+	"time"
 	"threat-intel-backend/domain"
 	"github.com/google/uuid"
 )
 
+var (
+	ErrOrderNotFound     = errors.New("order not found")
+	ErrOrderAccessDenied = errors.New("access denied")
+	ErrInvalidItem       = errors.New("invalid item_id")
+	ErrQuantityExceeded  = errors.New("quantity exceeds the product maximum")
+)
+
 type OrderService struct {
-	orderRepo domain.OrderRepository
-	userRepo  domain.UserRepository
+	orderRepo       domain.OrderRepository
+	userRepo        domain.UserRepository
+	entitlementRepo domain.EntitlementRepository
+	productRepo     domain.ProductRepository
+	transactor      domain.Transactor
 }
 
 type CreateOrderRequest struct {
@@ -17,26 +28,33 @@ type CreateOrderRequest struct {
 }
 
 type OrderResponse struct {
-	OrderID string             `json:"order_id"`
-	Status  domain.OrderStatus `json:"status"`
+	OrderID   string             `json:"order_id"`
+	Status    domain.OrderStatus `json:"status"`
+	UnitPrice int64              `json:"unit_price"`
+	Total     int64              `json:"total"`
+	Currency  string             `json:"currency"`
 }
 
-var validItems = map[string]bool{
-	"intel-basic":    true,
-	"intel-premium":  true,
-	"intel-enterprise": true,
-}
-
-func NewOrderService(orderRepo domain.OrderRepository, userRepo domain.UserRepository) *OrderService {
+func NewOrderService(orderRepo domain.OrderRepository, userRepo domain.UserRepository, entitlementRepo domain.EntitlementRepository, productRepo domain.ProductRepository, transactor domain.Transactor) *OrderService {
 	return &OrderService{
-		orderRepo: orderRepo,
-		userRepo:  userRepo,
+		orderRepo:       orderRepo,
+		userRepo:        userRepo,
+		entitlementRepo: entitlementRepo,
+		productRepo:     productRepo,
+		transactor:      transactor,
 	}
 }
 
+// CreateOrder confirms an order and grants the entitlement it buys. The order
+// and the entitlement are saved in one transaction, so a confirmed order
+// always comes with its entitlement.
 func (s *OrderService) CreateOrder(userID uuid.UUID, req CreateOrderRequest) (*OrderResponse, error) {
-	if !validItems[req.ItemID] {
-		return nil, errors.New("invalid item_id")
+	product, err := s.productRepo.FindByID(req.ItemID)
+	if err != nil || !product.Active {
+		return nil, ErrInvalidItem
+	}
+	if req.Quantity > product.MaxQuantity {
+		return nil, ErrQuantityExceeded
 	}
 
 	user, err := s.userRepo.FindByID(userID)
@@ -49,22 +67,28 @@ func (s *OrderService) CreateOrder(userID uuid.UUID, req CreateOrderRequest) (*O
 	}
 
 	orderAggregate := domain.NewOrder(userID, req.ItemID, req.Quantity)
-	orderAggregate.Confirm()
+	orderAggregate.PriceFrom(product)
+	if err := orderAggregate.Confirm(); err != nil {
+		return nil, err
+	}
 
-	if err := s.orderRepo.Save(orderAggregate.Order); err != nil {
+	if err := s.saveAndGrant(orderAggregate.Order); err != nil {
 		return nil, err
 	}
 
 	return &OrderResponse{
-		OrderID: orderAggregate.Order.ID.String(),
-		Status:  orderAggregate.Order.Status,
+		OrderID:   orderAggregate.Order.ID.String(),
+		Status:    orderAggregate.Order.Status,
+		UnitPrice: orderAggregate.Order.UnitPrice,
+		Total:     orderAggregate.Order.Total(),
+		Currency:  orderAggregate.Order.Currency,
 	}, nil
 }
 
 func (s *OrderService) GetOrder(orderID uuid.UUID, userID uuid.UUID) (*domain.Order, error) {
 	order, err := s.orderRepo.FindByID(orderID)
 	if err != nil {
-		return nil, err
+		return nil, ErrOrderNotFound
 	}
 
 	user, err := s.userRepo.FindByID(userID)
@@ -73,7 +97,75 @@ func (s *OrderService) GetOrder(orderID uuid.UUID, userID uuid.UUID) (*domain.Or
 	}
 
 	if order.UserID != userID && !user.HasPermission(domain.RoleAnalyst) {
-		return nil, errors.New("access denied")
+		return nil, ErrOrderAccessDenied
+	}
+
+	return order, nil
+}
+
+// CancelOrder cancels one of the user's own orders and revokes the
+// entitlement it granted, in one transaction. The order is locked while its
+// status is checked, so a concurrent completion either waits or wins.
+func (s *OrderService) CancelOrder(orderID, userID uuid.UUID) (*domain.Order, error) {
+	var order *domain.Order
+	err := s.transactor.Transaction(func(repos domain.Repositories) error {
+		var err error
+		order, err = repos.Orders.FindByIDForUpdate(orderID)
+		if err != nil {
+			return ErrOrderNotFound
+		}
+		if order.UserID != userID {
+			return ErrOrderAccessDenied
+		}
+
+		orderAggregate := &domain.OrderAggregate{Order: order}
+		if err := orderAggregate.Cancel(); err != nil {
+			return err
+		}
+		if err := repos.Orders.Save(order); err != nil {
+			return err
+		}
+
+		if entitlement, err := repos.Entitlements.FindByOrderID(order.ID); err == nil {
+			entitlement.Revoke(time.Now())
+			return repos.Entitlements.Save(entitlement)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// CompleteOrder marks a confirmed order as fulfilled (admin only), granting
+// its entitlement in the same transaction if it has none yet. Like
+// CancelOrder, it checks the status under the order's lock.
+func (s *OrderService) CompleteOrder(actorID, orderID uuid.UUID) (*domain.Order, error) {
+	if err := requireRole(s.userRepo, actorID, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	var order *domain.Order
+	err := s.transactor.Transaction(func(repos domain.Repositories) error {
+		var err error
+		order, err = repos.Orders.FindByIDForUpdate(orderID)
+		if err != nil {
+			return ErrOrderNotFound
+		}
+
+		orderAggregate := &domain.OrderAggregate{Order: order}
+		if err := orderAggregate.Complete(); err != nil {
+			return err
+		}
+		if err := repos.Orders.Save(order); err != nil {
+			return err
+		}
+		return grantEntitlement(repos.Entitlements, order)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return order, nil
@@ -81,4 +173,44 @@ func (s *OrderService) GetOrder(orderID uuid.UUID, userID uuid.UUID) (*domain.Or
 
 func (s *OrderService) GetUserOrders(userID uuid.UUID) ([]*domain.Order, error) {
 	return s.orderRepo.FindByUserID(userID)
-}
\ No newline at end of file
+}
+
+// saveAndGrant saves the order and grants its entitlement in one transaction.
+func (s *OrderService) saveAndGrant(order *domain.Order) error {
+	return s.transactor.Transaction(func(repos domain.Repositories) error {
+		if err := repos.Orders.Save(order); err != nil {
+			return err
+		}
+		return grantEntitlement(repos.Entitlements, order)
+	})
+}
+
+// grantEntitlement gives the buyer of a confirmed or completed order access
+// to the tier snapshotted on the order. A purchase of a tier the user already
+// holds starts when the current term ends. Granting is idempotent per order.
+func grantEntitlement(entitlementRepo domain.EntitlementRepository, order *domain.Order) error {
+	tier := order.Tier
+	if !tier.IsValid() {
+		return nil
+	}
+	if _, err := entitlementRepo.FindByOrderID(order.ID); err == nil {
+		return nil
+	}
+
+	entitlements, err := entitlementRepo.FindByUserID(order.UserID)
+	if err != nil {
+		return err
+	}
+
+	// Only the buyer's own purchases are extended; a seat of someone else's
+	// entitlement can be taken away at any time.
+	bought := make([]*domain.Entitlement, 0, len(entitlements))
+	for _, entitlement := range entitlements {
+		if entitlement.UserID == order.UserID {
+			bought = append(bought, entitlement)
+		}
+	}
+
+	startsAt := domain.RenewalStart(bought, tier, time.Now())
+	return entitlementRepo.Save(domain.NewEntitlement(order, tier, startsAt))
+}
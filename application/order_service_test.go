@@ -4,6 +4,7 @@ import (
 	"errors"
 	"testing"
 	"threat-intel-backend/domain"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -27,6 +28,14 @@ func (m *MockOrderRepository) FindByID(id uuid.UUID) (*domain.Order, error) {
 	return args.Get(0).(*domain.Order), args.Error(1)
 }
 
+func (m *MockOrderRepository) FindByIDForUpdate(id uuid.UUID) (*domain.Order, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Order), args.Error(1)
+}
+
 func (m *MockOrderRepository) FindByUserID(userID uuid.UUID) ([]*domain.Order, error) {
 	args := m.Called(userID)
 	if args.Get(0) == nil {
@@ -35,18 +44,54 @@ func (m *MockOrderRepository) FindByUserID(userID uuid.UUID) ([]*domain.Order, e
 	return args.Get(0).([]*domain.Order), args.Error(1)
 }
 
+// fakeTransactor hands the same repositories to every transaction and
+// counts how transactions ended.
+type fakeTransactor struct {
+	repos     domain.Repositories
+	commits   int
+	rollbacks int
+}
+
+func (t *fakeTransactor) Transaction(fn func(repos domain.Repositories) error) error {
+	if err := fn(t.repos); err != nil {
+		t.rollbacks++
+		return err
+	}
+	t.commits++
+	return nil
+}
+
+// orderTransactor runs the transactions of an OrderService against the given
+// mocks.
+func orderTransactor(orders *MockOrderRepository, entitlements *MockEntitlementRepository) *fakeTransactor {
+	return &fakeTransactor{repos: domain.Repositories{Orders: orders, Entitlements: entitlements}}
+}
+
 func TestOrderService_CreateOrder(t *testing.T) {
 	mockOrderRepo := new(MockOrderRepository)
 	mockUserRepo := new(MockUserRepository)
-	orderService := NewOrderService(mockOrderRepo, mockUserRepo)
+	mockEntitlementRepo := new(MockEntitlementRepository)
+	mockProductRepo := new(MockProductRepository)
+	transactor := orderTransactor(mockOrderRepo, mockEntitlementRepo)
+	orderService := NewOrderService(mockOrderRepo, mockUserRepo, mockEntitlementRepo, mockProductRepo, transactor)
 
 	userID := uuid.New()
 	user, _ := domain.NewUser("test@example.com", "password123", domain.RoleViewer)
 	user.ID = userID
 
+	products := domain.DefaultProducts()
+	basic := products[0]
+	mockProductRepo.On("FindByID", basic.ID).Return(basic, nil)
+	mockProductRepo.On("FindByID", "invalid-item").Return(nil, errors.New("record not found"))
+
 	t.Run("successful order creation", func(t *testing.T) {
 		mockUserRepo.On("FindByID", userID).Return(user, nil).Once()
 		mockOrderRepo.On("Save", mock.AnythingOfType("*domain.Order")).Return(nil).Once()
+		mockEntitlementRepo.On("FindByOrderID", mock.Anything).Return(nil, errors.New("record not found")).Once()
+		mockEntitlementRepo.On("FindByUserID", userID).Return([]*domain.Entitlement{}, nil).Once()
+		mockEntitlementRepo.On("Save", mock.MatchedBy(func(e *domain.Entitlement) bool {
+			return e.UserID == userID && e.Tier == domain.TierBasic && e.Quantity == 1
+		})).Return(nil).Once()
 
 		req := CreateOrderRequest{
 			ItemID:   "intel-basic",
@@ -59,8 +104,60 @@ func TestOrderService_CreateOrder(t *testing.T) {
 		assert.NotNil(t, resp)
 		assert.NotEmpty(t, resp.OrderID)
 		assert.Equal(t, domain.OrderStatusConfirmed, resp.Status)
+		assert.Equal(t, basic.Price, resp.UnitPrice)
+		assert.Equal(t, basic.Price, resp.Total)
+		assert.Equal(t, "USD", resp.Currency)
 		mockUserRepo.AssertExpectations(t)
 		mockOrderRepo.AssertExpectations(t)
+		mockEntitlementRepo.AssertExpectations(t)
+	})
+
+	t.Run("renewal extends current entitlement", func(t *testing.T) {
+		current := activeEntitlement(userID, domain.TierBasic)
+		mockUserRepo.On("FindByID", userID).Return(user, nil).Once()
+		mockOrderRepo.On("Save", mock.AnythingOfType("*domain.Order")).Return(nil).Once()
+		mockEntitlementRepo.On("FindByOrderID", mock.Anything).Return(nil, errors.New("record not found")).Once()
+		mockEntitlementRepo.On("FindByUserID", userID).Return([]*domain.Entitlement{current}, nil).Once()
+		mockEntitlementRepo.On("Save", mock.MatchedBy(func(e *domain.Entitlement) bool {
+			return e.StartsAt.Equal(current.EndsAt)
+		})).Return(nil).Once()
+
+		_, err := orderService.CreateOrder(userID, CreateOrderRequest{ItemID: "intel-basic", Quantity: 1})
+
+		assert.NoError(t, err)
+		mockEntitlementRepo.AssertExpectations(t)
+	})
+
+	t.Run("seat of another buyer's entitlement is not extended", func(t *testing.T) {
+		held := activeEntitlement(uuid.New(), domain.TierBasic)
+		mockUserRepo.On("FindByID", userID).Return(user, nil).Once()
+		mockOrderRepo.On("Save", mock.AnythingOfType("*domain.Order")).Return(nil).Once()
+		mockEntitlementRepo.On("FindByOrderID", mock.Anything).Return(nil, errors.New("record not found")).Once()
+		mockEntitlementRepo.On("FindByUserID", userID).Return([]*domain.Entitlement{held}, nil).Once()
+		mockEntitlementRepo.On("Save", mock.MatchedBy(func(e *domain.Entitlement) bool {
+			return e.StartsAt.Before(held.EndsAt)
+		})).Return(nil).Once()
+
+		_, err := orderService.CreateOrder(userID, CreateOrderRequest{ItemID: "intel-basic", Quantity: 1})
+
+		assert.NoError(t, err)
+		mockEntitlementRepo.AssertExpectations(t)
+	})
+
+	t.Run("rolls the order back when the grant fails", func(t *testing.T) {
+		rollbacks := transactor.rollbacks
+		mockUserRepo.On("FindByID", userID).Return(user, nil).Once()
+		mockOrderRepo.On("Save", mock.AnythingOfType("*domain.Order")).Return(nil).Once()
+		mockEntitlementRepo.On("FindByOrderID", mock.Anything).Return(nil, errors.New("record not found")).Once()
+		mockEntitlementRepo.On("FindByUserID", userID).Return([]*domain.Entitlement{}, nil).Once()
+		mockEntitlementRepo.On("Save", mock.AnythingOfType("*domain.Entitlement")).Return(errors.New("connection reset")).Once()
+
+		resp, err := orderService.CreateOrder(userID, CreateOrderRequest{ItemID: "intel-basic", Quantity: 1})
+
+		assert.EqualError(t, err, "connection reset")
+		assert.Nil(t, resp)
+		assert.Equal(t, rollbacks+1, transactor.rollbacks)
+		mockOrderRepo.AssertExpectations(t)
 	})
 
 	t.Run("invalid item_id", func(t *testing.T) {
@@ -76,6 +173,22 @@ func TestOrderService_CreateOrder(t *testing.T) {
 		assert.Equal(t, "invalid item_id", err.Error())
 	})
 
+	t.Run("inactive product", func(t *testing.T) {
+		retired, _ := domain.NewProduct("intel-legacy", "Intel Legacy", 100, "USD", domain.TierBasic, 10)
+		retired.Active = false
+		mockProductRepo.On("FindByID", retired.ID).Return(retired, nil).Once()
+
+		_, err := orderService.CreateOrder(userID, CreateOrderRequest{ItemID: retired.ID, Quantity: 1})
+
+		assert.Equal(t, ErrInvalidItem, err)
+	})
+
+	t.Run("quantity above product maximum", func(t *testing.T) {
+		_, err := orderService.CreateOrder(userID, CreateOrderRequest{ItemID: basic.ID, Quantity: basic.MaxQuantity + 1})
+
+		assert.Equal(t, ErrQuantityExceeded, err)
+	})
+
 	t.Run("user not found", func(t *testing.T) {
 		mockUserRepo.On("FindByID", userID).Return(nil, errors.New("not found")).Once()
 
@@ -114,7 +227,7 @@ func TestOrderService_CreateOrder(t *testing.T) {
 func TestOrderService_GetOrder(t *testing.T) {
 	mockOrderRepo := new(MockOrderRepository)
 	mockUserRepo := new(MockUserRepository)
-	orderService := NewOrderService(mockOrderRepo, mockUserRepo)
+	orderService := NewOrderService(mockOrderRepo, mockUserRepo, new(MockEntitlementRepository), new(MockProductRepository), orderTransactor(mockOrderRepo, new(MockEntitlementRepository)))
 
 	userID := uuid.New()
 	orderID := uuid.New()
@@ -196,10 +309,116 @@ func TestOrderService_GetOrder(t *testing.T) {
 	})
 }
 
+func TestOrderService_CancelOrder(t *testing.T) {
+	mockOrderRepo := new(MockOrderRepository)
+	mockUserRepo := new(MockUserRepository)
+	mockEntitlementRepo := new(MockEntitlementRepository)
+	orderService := NewOrderService(mockOrderRepo, mockUserRepo, mockEntitlementRepo, new(MockProductRepository), orderTransactor(mockOrderRepo, mockEntitlementRepo))
+	userID := uuid.New()
+
+	t.Run("cancels and revokes entitlement", func(t *testing.T) {
+		order := &domain.Order{ID: uuid.New(), UserID: userID, ItemID: "intel-basic", Status: domain.OrderStatusConfirmed}
+		entitlement := activeEntitlement(userID, domain.TierBasic)
+		mockOrderRepo.On("FindByIDForUpdate", order.ID).Return(order, nil).Once()
+		mockOrderRepo.On("Save", order).Return(nil).Once()
+		mockEntitlementRepo.On("FindByOrderID", order.ID).Return(entitlement, nil).Once()
+		mockEntitlementRepo.On("Save", entitlement).Return(nil).Once()
+
+		result, err := orderService.CancelOrder(order.ID, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.OrderStatusCancelled, result.Status)
+		assert.False(t, entitlement.IsActive(time.Now()))
+		mockEntitlementRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects completed order", func(t *testing.T) {
+		order := &domain.Order{ID: uuid.New(), UserID: userID, ItemID: "intel-basic", Status: domain.OrderStatusCompleted}
+		mockOrderRepo.On("FindByIDForUpdate", order.ID).Return(order, nil).Once()
+
+		_, err := orderService.CancelOrder(order.ID, userID)
+
+		assert.True(t, errors.Is(err, domain.ErrInvalidOrderTransition))
+		assert.Equal(t, domain.OrderStatusCompleted, order.Status)
+	})
+
+	t.Run("rejects other user's order", func(t *testing.T) {
+		order := &domain.Order{ID: uuid.New(), UserID: uuid.New(), Status: domain.OrderStatusConfirmed}
+		mockOrderRepo.On("FindByIDForUpdate", order.ID).Return(order, nil).Once()
+
+		_, err := orderService.CancelOrder(order.ID, userID)
+
+		assert.Equal(t, ErrOrderAccessDenied, err)
+	})
+}
+
+func TestOrderService_CompleteOrder(t *testing.T) {
+	mockOrderRepo := new(MockOrderRepository)
+	mockUserRepo := new(MockUserRepository)
+	mockEntitlementRepo := new(MockEntitlementRepository)
+	transactor := orderTransactor(mockOrderRepo, mockEntitlementRepo)
+	orderService := NewOrderService(mockOrderRepo, mockUserRepo, mockEntitlementRepo, new(MockProductRepository), transactor)
+	admin, _ := domain.NewUser("admin@example.com", "password123", domain.RoleAdmin)
+	viewer, _ := domain.NewUser("viewer@example.com", "password123", domain.RoleViewer)
+	mockUserRepo.On("FindByID", admin.ID).Return(admin, nil)
+	mockUserRepo.On("FindByID", viewer.ID).Return(viewer, nil)
+
+	t.Run("completes confirmed order", func(t *testing.T) {
+		order := &domain.Order{ID: uuid.New(), UserID: viewer.ID, ItemID: "intel-basic", Tier: domain.TierBasic, Status: domain.OrderStatusConfirmed}
+		mockOrderRepo.On("FindByIDForUpdate", order.ID).Return(order, nil).Once()
+		mockOrderRepo.On("Save", order).Return(nil).Once()
+		mockEntitlementRepo.On("FindByOrderID", order.ID).Return(activeEntitlement(viewer.ID, domain.TierBasic), nil).Once()
+
+		result, err := orderService.CompleteOrder(admin.ID, order.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.OrderStatusCompleted, result.Status)
+		mockEntitlementRepo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+
+	t.Run("fails without completing when the grant fails", func(t *testing.T) {
+		order := &domain.Order{ID: uuid.New(), UserID: viewer.ID, ItemID: "intel-basic", Tier: domain.TierBasic, Status: domain.OrderStatusConfirmed}
+		mockOrderRepo.On("FindByIDForUpdate", order.ID).Return(order, nil).Once()
+		mockOrderRepo.On("Save", order).Return(nil).Once()
+		mockEntitlementRepo.On("FindByOrderID", order.ID).Return(nil, errors.New("record not found")).Once()
+		mockEntitlementRepo.On("FindByUserID", viewer.ID).Return(nil, errors.New("connection reset")).Once()
+
+		result, err := orderService.CompleteOrder(admin.ID, order.ID)
+
+		assert.EqualError(t, err, "connection reset")
+		assert.Nil(t, result)
+		assert.Equal(t, 1, transactor.rollbacks)
+	})
+
+	t.Run("rejects cancelled order", func(t *testing.T) {
+		order := &domain.Order{ID: uuid.New(), UserID: viewer.ID, ItemID: "intel-basic", Status: domain.OrderStatusCancelled}
+		mockOrderRepo.On("FindByIDForUpdate", order.ID).Return(order, nil).Once()
+
+		_, err := orderService.CompleteOrder(admin.ID, order.ID)
+
+		assert.True(t, errors.Is(err, domain.ErrInvalidOrderTransition))
+	})
+
+	t.Run("requires admin", func(t *testing.T) {
+		_, err := orderService.CompleteOrder(viewer.ID, uuid.New())
+
+		assert.Equal(t, ErrInsufficientPermissions, err)
+	})
+
+	t.Run("order not found", func(t *testing.T) {
+		id := uuid.New()
+		mockOrderRepo.On("FindByIDForUpdate", id).Return(nil, errors.New("record not found")).Once()
+
+		_, err := orderService.CompleteOrder(admin.ID, id)
+
+		assert.Equal(t, ErrOrderNotFound, err)
+	})
+}
+
 func TestOrderService_GetUserOrders(t *testing.T) {
 	mockOrderRepo := new(MockOrderRepository)
 	mockUserRepo := new(MockUserRepository)
-	orderService := NewOrderService(mockOrderRepo, mockUserRepo)
+	orderService := NewOrderService(mockOrderRepo, mockUserRepo, new(MockEntitlementRepository), new(MockProductRepository), orderTransactor(mockOrderRepo, new(MockEntitlementRepository)))
 
 	userID := uuid.New()
 	orders := []*domain.Order{
@@ -234,7 +453,7 @@ func TestNewOrderService(t *testing.T) {
 	mockOrderRepo := new(MockOrderRepository)
 	mockUserRepo := new(MockUserRepository)
 
-	orderService := NewOrderService(mockOrderRepo, mockUserRepo)
+	orderService := NewOrderService(mockOrderRepo, mockUserRepo, new(MockEntitlementRepository), new(MockProductRepository), orderTransactor(mockOrderRepo, new(MockEntitlementRepository)))
 
 	assert.NotNil(t, orderService)
 	assert.Equal(t, mockOrderRepo, orderService.orderRepo)
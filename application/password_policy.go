@@ -0,0 +1,77 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var ErrWeakPassword = errors.New("password does not meet the password policy")
+
+// BreachedPasswordList tells whether a password is known from breaches.
+// password.BreachedList implements it.
+type BreachedPasswordList interface {
+	Contains(password string) bool
+}
+
+// PasswordPolicy is what new passwords must satisfy. MinLength counts
+// characters; MaxLength counts bytes, as bcrypt ignores all but the first 72.
+// MinCharClasses is how many of lower case letters, upper case letters,
+// digits and other characters a password must mix. A password may not be
+// the email address of its user or its local part, and is checked against
+// Breached when set.
+type PasswordPolicy struct {
+	MinLength      int
+	MaxLength      int
+	MinCharClasses int
+	Breached       BreachedPasswordList
+}
+
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength:      8,
+	MaxLength:      72,
+	MinCharClasses: 2,
+}
+
+// Check returns an ErrWeakPassword that says what is wrong with the password
+// of the user with the given email.
+func (p PasswordPolicy) Check(email, password string) error {
+	if utf8.RuneCountInString(password) < p.MinLength {
+		return fmt.Errorf("%w: it must be at least %d characters long", ErrWeakPassword, p.MinLength)
+	}
+	if p.MaxLength > 0 && len(password) > p.MaxLength {
+		return fmt.Errorf("%w: it must be at most %d bytes long", ErrWeakPassword, p.MaxLength)
+	}
+	if charClasses(password) < p.MinCharClasses {
+		return fmt.Errorf("%w: it must mix at least %d of lower case letters, upper case letters, digits and symbols", ErrWeakPassword, p.MinCharClasses)
+	}
+
+	localPart, _, _ := strings.Cut(email, "@")
+	if strings.Contains(strings.ToLower(password), strings.ToLower(email)) || strings.EqualFold(password, localPart) {
+		return fmt.Errorf("%w: it must not be your email address", ErrWeakPassword)
+	}
+
+	if p.Breached != nil && p.Breached.Contains(password) {
+		return fmt.Errorf("%w: it appears in a list of breached passwords", ErrWeakPassword)
+	}
+	return nil
+}
+
+func charClasses(password string) int {
+	var lower, upper, digit, other int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			other = 1
+		}
+	}
+	return lower + upper + digit + other
+}
@@ -0,0 +1,53 @@
+package application
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type breachedSet map[string]bool
+
+func (s breachedSet) Contains(password string) bool {
+	return s[password]
+}
+
+func TestPasswordPolicy_Check(t *testing.T) {
+	policy := DefaultPasswordPolicy
+	policy.Breached = breachedSet{"Password123": true}
+
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		reason   string
+	}{
+		{"accepted", "analyst@example.com", "tr0ub4dor&3", ""},
+		{"counts characters, not bytes", "analyst@example.com", "crème-brû", ""},
+		{"too short", "analyst@example.com", "ab1", "at least 8 characters"},
+		{"too long", "analyst@example.com", "a1" + strings.Repeat("x", 71), "at most 72 bytes"},
+		{"single character class", "analyst@example.com", "abcdefghij", "at least 2 of"},
+		{"email address", "analyst@example.com", "Analyst@Example.com", "email address"},
+		{"contains email address", "analyst@example.com", "analyst@example.com1", "email address"},
+		{"local part of email", "j.doe2026@example.com", "J.Doe2026", "email address"},
+		{"breached", "analyst@example.com", "Password123", "breached"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Check(tt.email, tt.password)
+
+			if tt.reason == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrWeakPassword)
+			assert.Contains(t, err.Error(), tt.reason)
+		})
+	}
+
+	t.Run("without a breached list", func(t *testing.T) {
+		assert.NoError(t, DefaultPasswordPolicy.Check("analyst@example.com", "Password123"))
+	})
+}
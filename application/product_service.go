@@ -0,0 +1,167 @@
+package application
+
+import (
+	"errors"
+	"threat-intel-backend/domain"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrProductNotFound = errors.New("product not found")
+	ErrProductExists   = errors.New("product already exists")
+)
+
+type ProductService struct {
+	productRepo domain.ProductRepository
+	userRepo    domain.UserRepository
+}
+
+type CreateProductRequest struct {
+	ID          string      `json:"id" binding:"required"`
+	Name        string      `json:"name" binding:"required"`
+	Description string      `json:"description"`
+	Price       int64       `json:"price" binding:"min=0"`
+	Currency    string      `json:"currency" binding:"required"`
+	Tier        domain.Tier `json:"tier" binding:"required"`
+	MaxQuantity int         `json:"max_quantity" binding:"required,min=1"`
+	Active      *bool       `json:"active"`
+}
+
+// UpdateProductRequest only touches the fields that are present. Price and
+// currency are validated together, so a new currency keeps the current price
+// and vice versa.
+type UpdateProductRequest struct {
+	Name        *string      `json:"name"`
+	Description *string      `json:"description"`
+	Price       *int64       `json:"price" binding:"omitempty,min=0"`
+	Currency    *string      `json:"currency"`
+	Tier        *domain.Tier `json:"tier"`
+	MaxQuantity *int         `json:"max_quantity" binding:"omitempty,min=1"`
+	Active      *bool        `json:"active"`
+}
+
+func NewProductService(productRepo domain.ProductRepository, userRepo domain.UserRepository) *ProductService {
+	return &ProductService{
+		productRepo: productRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// ListCatalog returns the products that can currently be ordered.
+func (s *ProductService) ListCatalog() ([]*domain.Product, error) {
+	return s.productRepo.List(true)
+}
+
+// ListProducts returns every product, including inactive ones (admin only).
+func (s *ProductService) ListProducts(actorID uuid.UUID) ([]*domain.Product, error) {
+	if err := requireRole(s.userRepo, actorID, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	return s.productRepo.List(false)
+}
+
+func (s *ProductService) GetProduct(actorID uuid.UUID, id string) (*domain.Product, error) {
+	if err := requireRole(s.userRepo, actorID, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	return s.findProduct(id)
+}
+
+func (s *ProductService) CreateProduct(actorID uuid.UUID, req CreateProductRequest) (*domain.Product, error) {
+	if err := requireRole(s.userRepo, actorID, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	product, err := domain.NewProduct(req.ID, req.Name, req.Price, req.Currency, req.Tier, req.MaxQuantity)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.productRepo.FindByID(product.ID); err == nil {
+		return nil, ErrProductExists
+	}
+
+	product.Description = req.Description
+	if req.Active != nil {
+		product.Active = *req.Active
+	}
+
+	if err := s.productRepo.Save(product); err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
+func (s *ProductService) UpdateProduct(actorID uuid.UUID, id string, req UpdateProductRequest) (*domain.Product, error) {
+	if err := requireRole(s.userRepo, actorID, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	product, err := s.findProduct(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		if err := product.SetName(*req.Name); err != nil {
+			return nil, err
+		}
+	}
+	if req.Description != nil {
+		product.Description = *req.Description
+	}
+	if req.Price != nil || req.Currency != nil {
+		price, currency := product.Price, product.Currency
+		if req.Price != nil {
+			price = *req.Price
+		}
+		if req.Currency != nil {
+			currency = *req.Currency
+		}
+		if err := product.SetPrice(price, currency); err != nil {
+			return nil, err
+		}
+	}
+	if req.Tier != nil {
+		if err := product.SetTier(*req.Tier); err != nil {
+			return nil, err
+		}
+	}
+	if req.MaxQuantity != nil {
+		if err := product.SetMaxQuantity(*req.MaxQuantity); err != nil {
+			return nil, err
+		}
+	}
+	if req.Active != nil {
+		product.Active = *req.Active
+	}
+
+	if err := s.productRepo.Save(product); err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
+// DeleteProduct removes a product from the catalog. Past orders keep their
+// price snapshot; deactivating is preferred when the product may return.
+func (s *ProductService) DeleteProduct(actorID uuid.UUID, id string) error {
+	if err := requireRole(s.userRepo, actorID, domain.RoleAdmin); err != nil {
+		return err
+	}
+
+	if _, err := s.findProduct(id); err != nil {
+		return err
+	}
+
+	return s.productRepo.Delete(id)
+}
+
+func (s *ProductService) findProduct(id string) (*domain.Product, error) {
+	product, err := s.productRepo.FindByID(id)
+	if err != nil {
+		return nil, ErrProductNotFound
+	}
+	return product, nil
+}
@@ -0,0 +1,156 @@
+package application
+
+import (
+	"errors"
+	"testing"
+	"threat-intel-backend/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockProductRepository struct {
+	mock.Mock
+}
+
+func (m *MockProductRepository) Save(product *domain.Product) error {
+	args := m.Called(product)
+	return args.Error(0)
+}
+
+func (m *MockProductRepository) FindByID(id string) (*domain.Product, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) List(activeOnly bool) ([]*domain.Product, error) {
+	args := m.Called(activeOnly)
+	return args.Get(0).([]*domain.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) Delete(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func setupProductService() (*ProductService, *MockProductRepository, *domain.User, *domain.User) {
+	mockProducts := new(MockProductRepository)
+	mockUsers := new(MockUserRepository)
+	admin, _ := domain.NewUser("admin@example.com", "password123", domain.RoleAdmin)
+	analyst, _ := domain.NewUser("analyst@example.com", "password123", domain.RoleAnalyst)
+	mockUsers.On("FindByID", admin.ID).Return(admin, nil)
+	mockUsers.On("FindByID", analyst.ID).Return(analyst, nil)
+
+	return NewProductService(mockProducts, mockUsers), mockProducts, admin, analyst
+}
+
+func TestProductService_ListCatalog(t *testing.T) {
+	service, mockProducts, _, _ := setupProductService()
+	products := domain.DefaultProducts()
+	mockProducts.On("List", true).Return(products, nil).Once()
+
+	catalog, err := service.ListCatalog()
+
+	assert.NoError(t, err)
+	assert.Equal(t, products, catalog)
+}
+
+func TestProductService_CreateProduct(t *testing.T) {
+	service, mockProducts, admin, analyst := setupProductService()
+
+	t.Run("creates product", func(t *testing.T) {
+		inactive := false
+		mockProducts.On("FindByID", "intel-gov").Return(nil, errors.New("record not found")).Once()
+		mockProducts.On("Save", mock.AnythingOfType("*domain.Product")).Return(nil).Once()
+
+		product, err := service.CreateProduct(admin.ID, CreateProductRequest{
+			ID:          "intel-gov",
+			Name:        "Intel Government",
+			Price:       250000,
+			Currency:    "USD",
+			Tier:        domain.TierEnterprise,
+			MaxQuantity: 50,
+			Active:      &inactive,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(250000), product.Price)
+		assert.False(t, product.Active)
+		mockProducts.AssertExpectations(t)
+	})
+
+	t.Run("rejects duplicates", func(t *testing.T) {
+		mockProducts.On("FindByID", "intel-basic").Return(domain.DefaultProducts()[0], nil).Once()
+
+		_, err := service.CreateProduct(admin.ID, CreateProductRequest{
+			ID: "intel-basic", Name: "Intel Basic", Currency: "USD", Tier: domain.TierBasic, MaxQuantity: 1,
+		})
+
+		assert.Equal(t, ErrProductExists, err)
+	})
+
+	t.Run("rejects invalid currency", func(t *testing.T) {
+		_, err := service.CreateProduct(admin.ID, CreateProductRequest{
+			ID: "intel-x", Name: "Intel X", Currency: "dollars", Tier: domain.TierBasic, MaxQuantity: 1,
+		})
+
+		assert.Equal(t, domain.ErrInvalidCurrency, err)
+	})
+
+	t.Run("requires admin", func(t *testing.T) {
+		_, err := service.CreateProduct(analyst.ID, CreateProductRequest{})
+
+		assert.Equal(t, ErrInsufficientPermissions, err)
+	})
+}
+
+func TestProductService_UpdateProduct(t *testing.T) {
+	service, mockProducts, admin, _ := setupProductService()
+
+	t.Run("updates price and deactivates", func(t *testing.T) {
+		product := domain.DefaultProducts()[1]
+		mockProducts.On("FindByID", product.ID).Return(product, nil).Once()
+		mockProducts.On("Save", product).Return(nil).Once()
+
+		price := int64(24900)
+		active := false
+		updated, err := service.UpdateProduct(admin.ID, product.ID, UpdateProductRequest{Price: &price, Active: &active})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(24900), updated.Price)
+		assert.Equal(t, "USD", updated.Currency)
+		assert.False(t, updated.Active)
+	})
+
+	t.Run("rejects invalid tier", func(t *testing.T) {
+		product := domain.DefaultProducts()[0]
+		mockProducts.On("FindByID", product.ID).Return(product, nil).Once()
+
+		tier := domain.Tier("gold")
+		_, err := service.UpdateProduct(admin.ID, product.ID, UpdateProductRequest{Tier: &tier})
+
+		assert.Equal(t, domain.ErrInvalidTier, err)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockProducts.On("FindByID", "missing").Return(nil, errors.New("record not found")).Once()
+
+		_, err := service.UpdateProduct(admin.ID, "missing", UpdateProductRequest{})
+
+		assert.Equal(t, ErrProductNotFound, err)
+	})
+}
+
+func TestProductService_DeleteProduct(t *testing.T) {
+	service, mockProducts, admin, analyst := setupProductService()
+	product := domain.DefaultProducts()[0]
+	mockProducts.On("FindByID", product.ID).Return(product, nil).Once()
+	mockProducts.On("Delete", product.ID).Return(nil).Once()
+
+	assert.NoError(t, service.DeleteProduct(admin.ID, product.ID))
+	assert.Equal(t, ErrInsufficientPermissions, service.DeleteProduct(analyst.ID, product.ID))
+	mockProducts.AssertExpectations(t)
+}
@@ -0,0 +1,194 @@
+package application
+
+import (
+	"context"
+	"time"
+	"threat-intel-backend/domain"
+	"github.com/google/uuid"
+)
+
+// scoringBatchSize is how many indicators a scoring run loads at a time.
+const scoringBatchSize = 500
+
+// ScoringPolicy is how indicators are scored and the score below which they
+// expire; an ExpireBelow of 0 never expires them.
+type ScoringPolicy struct {
+	Scorer      *domain.Scorer
+	ExpireBelow int
+}
+
+// ScoringRun counts the indicators a run scored and expired.
+type ScoringRun struct {
+	Scored  int
+	Expired int
+}
+
+type ScoringService struct {
+	indicatorRepo domain.IndicatorRepository
+	sightingRepo  domain.SightingRepository
+	voteRepo      domain.IndicatorVoteRepository
+	userRepo      domain.UserRepository
+	policy        ScoringPolicy
+	lock          JobLock
+	lookupCache   domain.LookupCache
+}
+
+type VoteRequest struct {
+	Value int `json:"value" binding:"required,oneof=-1 1"`
+}
+
+func NewScoringService(indicatorRepo domain.IndicatorRepository, sightingRepo domain.SightingRepository, voteRepo domain.IndicatorVoteRepository, userRepo domain.UserRepository, policy ScoringPolicy, lock JobLock, lookupCache domain.LookupCache) *ScoringService {
+	return &ScoringService{
+		indicatorRepo: indicatorRepo,
+		sightingRepo:  sightingRepo,
+		voteRepo:      voteRepo,
+		userRepo:      userRepo,
+		policy:        policy,
+		lock:          lock,
+		lookupCache:   lookupCache,
+	}
+}
+
+// VoteIndicator records the vote of an analyst, replacing an earlier one, and
+// rescores the indicator right away.
+func (s *ScoringService) VoteIndicator(actorID, id uuid.UUID, value int) (*domain.Indicator, error) {
+	if err := requireRole(s.userRepo, actorID, domain.RoleAnalyst); err != nil {
+		return nil, err
+	}
+
+	indicator, err := s.findIndicator(id)
+	if err != nil {
+		return nil, err
+	}
+
+	vote, err := s.voteRepo.FindByIndicatorAndUser(id, actorID)
+	if err != nil {
+		vote, err = domain.NewIndicatorVote(id, actorID, value)
+	} else {
+		err = vote.Change(value)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.voteRepo.Save(vote); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.scoreIndicators(context.Background(), []*domain.Indicator{indicator}, time.Now()); err != nil {
+		return nil, err
+	}
+	return indicator, nil
+}
+
+// ClearVote withdraws the vote of an analyst and rescores the indicator.
+func (s *ScoringService) ClearVote(actorID, id uuid.UUID) (*domain.Indicator, error) {
+	if err := requireRole(s.userRepo, actorID, domain.RoleAnalyst); err != nil {
+		return nil, err
+	}
+
+	indicator, err := s.findIndicator(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.voteRepo.Delete(id, actorID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.scoreIndicators(context.Background(), []*domain.Indicator{indicator}, time.Now()); err != nil {
+		return nil, err
+	}
+	return indicator, nil
+}
+
+// RecomputeScores scores every indicator that is not expired, unless another
+// replica holds the lock, and expires those scoring below the threshold. The
+// lock is refreshed after every batch and the run stops once it is lost.
+func (s *ScoringService) RecomputeScores(ctx context.Context) (*ScoringRun, error) {
+	acquired, err := s.lock.Acquire(ctx)
+	if err != nil || !acquired {
+		return nil, err
+	}
+	defer s.lock.Release(context.Background())
+
+	run := &ScoringRun{}
+	now := time.Now()
+	after := uuid.Nil
+	for {
+		if ctx.Err() != nil {
+			return run, ctx.Err()
+		}
+
+		indicators, err := s.indicatorRepo.ListUnexpired(now, after, scoringBatchSize)
+		if err != nil {
+			return run, err
+		}
+		if len(indicators) == 0 {
+			return run, nil
+		}
+
+		expired, err := s.scoreIndicators(ctx, indicators, now)
+		if err != nil {
+			return run, err
+		}
+		run.Scored += len(indicators)
+		run.Expired += expired
+
+		if len(indicators) < scoringBatchSize {
+			return run, nil
+		}
+		if held, err := s.lock.Refresh(ctx); err != nil || !held {
+			return run, err
+		}
+		after = indicators[len(indicators)-1].ID
+	}
+}
+
+// scoreIndicators scores and stores the indicators and returns how many of
+// them expired. Cached lookups of the indicators whose score changed are
+// dropped.
+func (s *ScoringService) scoreIndicators(ctx context.Context, indicators []*domain.Indicator, now time.Time) (int, error) {
+	ids := make([]uuid.UUID, len(indicators))
+	for i, indicator := range indicators {
+		ids[i] = indicator.ID
+	}
+
+	sightings, err := s.sightingRepo.ListByIndicatorIDs(ids)
+	if err != nil {
+		return 0, err
+	}
+	sightingsByIndicator := make(map[uuid.UUID][]*domain.Sighting, len(indicators))
+	for _, sighting := range sightings {
+		sightingsByIndicator[sighting.IndicatorID] = append(sightingsByIndicator[sighting.IndicatorID], sighting)
+	}
+	tallies, err := s.voteRepo.TallyByIndicatorIDs(ids)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	var changed []domain.IndicatorKey
+	defer func() { forgetLookups(ctx, s.lookupCache, changed) }()
+	for _, indicator := range indicators {
+		score := s.policy.Scorer.Score(indicator, sightingsByIndicator[indicator.ID], tallies[indicator.ID], now)
+		previous := indicator.Score
+		expiredNow := indicator.ApplyScore(score, s.policy.ExpireBelow, now)
+		if expiredNow {
+			expired++
+		}
+		if expiredNow || score != previous {
+			changed = append(changed, indicator.Key())
+		}
+		if err := s.indicatorRepo.SaveScore(indicator); err != nil {
+			return expired, err
+		}
+	}
+	return expired, nil
+}
+
+func (s *ScoringService) findIndicator(id uuid.UUID) (*domain.Indicator, error) {
+	indicator, err := s.indicatorRepo.FindByID(id)
+	if err != nil {
+		return nil, ErrIndicatorNotFound
+	}
+	return indicator, nil
+}
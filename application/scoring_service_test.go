@@ -0,0 +1,215 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"threat-intel-backend/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockIndicatorVoteRepository struct {
+	mock.Mock
+}
+
+func (m *MockIndicatorVoteRepository) Save(vote *domain.IndicatorVote) error {
+	args := m.Called(vote)
+	return args.Error(0)
+}
+
+func (m *MockIndicatorVoteRepository) FindByIndicatorAndUser(indicatorID, userID uuid.UUID) (*domain.IndicatorVote, error) {
+	args := m.Called(indicatorID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.IndicatorVote), args.Error(1)
+}
+
+func (m *MockIndicatorVoteRepository) Delete(indicatorID, userID uuid.UUID) error {
+	args := m.Called(indicatorID, userID)
+	return args.Error(0)
+}
+
+func (m *MockIndicatorVoteRepository) TallyByIndicatorIDs(indicatorIDs []uuid.UUID) (map[uuid.UUID]domain.VoteTally, error) {
+	args := m.Called(indicatorIDs)
+	return args.Get(0).(map[uuid.UUID]domain.VoteTally), args.Error(1)
+}
+
+type scoringServiceMocks struct {
+	indicators *MockIndicatorRepository
+	sightings  *MockSightingRepository
+	votes      *MockIndicatorVoteRepository
+	lock       *MockJobLock
+	cache      *memoryLookupCache
+}
+
+func setupScoringService() (*ScoringService, *scoringServiceMocks, *domain.User, *domain.User) {
+	mocks := &scoringServiceMocks{
+		indicators: new(MockIndicatorRepository),
+		sightings:  new(MockSightingRepository),
+		votes:      new(MockIndicatorVoteRepository),
+		lock:       new(MockJobLock),
+		cache:      newMemoryLookupCache(),
+	}
+	mockUsers := new(MockUserRepository)
+	analyst, _ := domain.NewUser("analyst@example.com", "password123", domain.RoleAnalyst)
+	viewer, _ := domain.NewUser("viewer@example.com", "password123", domain.RoleViewer)
+	mockUsers.On("FindByID", analyst.ID).Return(analyst, nil)
+	mockUsers.On("FindByID", viewer.ID).Return(viewer, nil)
+
+	scorer, _ := domain.NewScorer(nil, map[string]int{"abuse-ch": 90}, domain.DefaultSourceReputation)
+	policy := ScoringPolicy{Scorer: scorer, ExpireBelow: 10}
+	service := NewScoringService(mocks.indicators, mocks.sightings, mocks.votes, mockUsers, policy, mocks.lock, mocks.cache)
+	return service, mocks, analyst, viewer
+}
+
+func TestScoringService_RecomputeScores(t *testing.T) {
+	t.Run("scores and expires indicators", func(t *testing.T) {
+		service, mocks, _, _ := setupScoringService()
+		trusted, _ := domain.NewIndicator(domain.IndicatorIPv4, "192.0.2.1", 50, "")
+		sighting := domain.NewSighting(trusted.ID, "abuse-ch")
+		sighting.Confidence = 100
+		stale, _ := domain.NewIndicator(domain.IndicatorIPv4, "192.0.2.2", 50, "")
+		stale.LastSeen = time.Now().Add(-30 * 24 * time.Hour)
+		ids := []uuid.UUID{trusted.ID, stale.ID}
+
+		mocks.lock.On("Acquire").Return(true, nil)
+		mocks.lock.On("Release").Return(nil)
+		mocks.indicators.On("ListUnexpired", mock.AnythingOfType("time.Time"), uuid.Nil, scoringBatchSize).Return([]*domain.Indicator{trusted, stale}, nil)
+		mocks.sightings.On("ListByIndicatorIDs", ids).Return([]*domain.Sighting{sighting}, nil)
+		mocks.votes.On("TallyByIndicatorIDs", ids).Return(map[uuid.UUID]domain.VoteTally{}, nil)
+		mocks.indicators.On("SaveScore", trusted).Return(nil)
+		mocks.indicators.On("SaveScore", stale).Return(nil)
+		trusted.Score = 54
+		mocks.cache.entries[trusted.Key()] = &domain.LookupMatch{IndicatorID: trusted.ID, Score: 54}
+		mocks.cache.entries[stale.Key()] = &domain.LookupMatch{IndicatorID: stale.ID, Score: 50}
+
+		run, err := service.RecomputeScores(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, &ScoringRun{Scored: 2, Expired: 1}, run)
+		assert.Equal(t, 54, trusted.Score)
+		assert.Nil(t, trusted.ExpiresAt)
+		assert.Equal(t, 1, stale.Score)
+		assert.NotNil(t, stale.ExpiresAt)
+		assert.Contains(t, mocks.cache.entries, trusted.Key(), "unchanged scores stay cached")
+		assert.NotContains(t, mocks.cache.entries, stale.Key())
+		mocks.indicators.AssertExpectations(t)
+		mocks.lock.AssertExpectations(t)
+	})
+
+	t.Run("pages through the indicators", func(t *testing.T) {
+		service, mocks, _, _ := setupScoringService()
+		batch := make([]*domain.Indicator, scoringBatchSize)
+		for i := range batch {
+			batch[i] = &domain.Indicator{ID: uuid.New(), Type: domain.IndicatorDomain, Confidence: 80, LastSeen: time.Now()}
+		}
+		last := batch[len(batch)-1].ID
+
+		mocks.lock.On("Acquire").Return(true, nil)
+		mocks.lock.On("Refresh").Return(true, nil).Once()
+		mocks.lock.On("Release").Return(nil)
+		mocks.indicators.On("ListUnexpired", mock.AnythingOfType("time.Time"), uuid.Nil, scoringBatchSize).Return(batch, nil)
+		mocks.indicators.On("ListUnexpired", mock.AnythingOfType("time.Time"), last, scoringBatchSize).Return([]*domain.Indicator{}, nil)
+		mocks.sightings.On("ListByIndicatorIDs", mock.Anything).Return([]*domain.Sighting{}, nil)
+		mocks.votes.On("TallyByIndicatorIDs", mock.Anything).Return(map[uuid.UUID]domain.VoteTally{}, nil)
+		mocks.indicators.On("SaveScore", mock.AnythingOfType("*domain.Indicator")).Return(nil)
+
+		run, err := service.RecomputeScores(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, scoringBatchSize, run.Scored)
+		mocks.indicators.AssertExpectations(t)
+		mocks.lock.AssertExpectations(t)
+	})
+
+	t.Run("skips when another replica holds the lock", func(t *testing.T) {
+		service, mocks, _, _ := setupScoringService()
+		mocks.lock.On("Acquire").Return(false, nil)
+
+		run, err := service.RecomputeScores(context.Background())
+
+		assert.NoError(t, err)
+		assert.Nil(t, run)
+		mocks.indicators.AssertNotCalled(t, "ListUnexpired", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestScoringService_VoteIndicator(t *testing.T) {
+	service, mocks, analyst, viewer := setupScoringService()
+	indicator, _ := domain.NewIndicator(domain.IndicatorDomain, "evil.example.com", 80, domain.SeverityHigh)
+	ids := []uuid.UUID{indicator.ID}
+	mocks.indicators.On("FindByID", indicator.ID).Return(indicator, nil)
+	mocks.sightings.On("ListByIndicatorIDs", ids).Return([]*domain.Sighting{}, nil)
+	mocks.indicators.On("SaveScore", indicator).Return(nil)
+
+	t.Run("records a vote and rescores", func(t *testing.T) {
+		mocks.votes.On("FindByIndicatorAndUser", indicator.ID, analyst.ID).Return(nil, errors.New("record not found")).Once()
+		mocks.votes.On("Save", mock.MatchedBy(func(vote *domain.IndicatorVote) bool { return vote.Value == 1 })).Return(nil).Once()
+		mocks.votes.On("TallyByIndicatorIDs", ids).Return(map[uuid.UUID]domain.VoteTally{indicator.ID: {Up: 3}}, nil).Once()
+
+		scored, err := service.VoteIndicator(analyst.ID, indicator.ID, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 39, scored.Score)
+		assert.NotNil(t, scored.ScoredAt)
+	})
+
+	t.Run("changes an earlier vote", func(t *testing.T) {
+		earlier, _ := domain.NewIndicatorVote(indicator.ID, analyst.ID, 1)
+		mocks.votes.On("FindByIndicatorAndUser", indicator.ID, analyst.ID).Return(earlier, nil).Once()
+		mocks.votes.On("Save", earlier).Return(nil).Once()
+		mocks.votes.On("TallyByIndicatorIDs", ids).Return(map[uuid.UUID]domain.VoteTally{indicator.ID: {Down: 3}}, nil).Once()
+
+		scored, err := service.VoteIndicator(analyst.ID, indicator.ID, -1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, -1, earlier.Value)
+		assert.Equal(t, 9, scored.Score)
+		assert.True(t, scored.IsExpired(time.Now()))
+	})
+
+	t.Run("rejects invalid votes", func(t *testing.T) {
+		mocks.votes.On("FindByIndicatorAndUser", indicator.ID, analyst.ID).Return(nil, errors.New("record not found")).Once()
+
+		_, err := service.VoteIndicator(analyst.ID, indicator.ID, 5)
+
+		assert.Equal(t, domain.ErrInvalidVote, err)
+	})
+
+	t.Run("indicator not found", func(t *testing.T) {
+		missing := uuid.New()
+		mocks.indicators.On("FindByID", missing).Return(nil, errors.New("record not found"))
+
+		_, err := service.VoteIndicator(analyst.ID, missing, 1)
+
+		assert.Equal(t, ErrIndicatorNotFound, err)
+	})
+
+	t.Run("requires analyst", func(t *testing.T) {
+		_, err := service.VoteIndicator(viewer.ID, indicator.ID, 1)
+
+		assert.Equal(t, ErrInsufficientPermissions, err)
+	})
+}
+
+func TestScoringService_ClearVote(t *testing.T) {
+	service, mocks, analyst, _ := setupScoringService()
+	indicator, _ := domain.NewIndicator(domain.IndicatorDomain, "evil.example.com", 80, domain.SeverityHigh)
+	ids := []uuid.UUID{indicator.ID}
+	mocks.indicators.On("FindByID", indicator.ID).Return(indicator, nil)
+	mocks.votes.On("Delete", indicator.ID, analyst.ID).Return(nil)
+	mocks.sightings.On("ListByIndicatorIDs", ids).Return([]*domain.Sighting{}, nil)
+	mocks.votes.On("TallyByIndicatorIDs", ids).Return(map[uuid.UUID]domain.VoteTally{}, nil)
+	mocks.indicators.On("SaveScore", indicator).Return(nil)
+
+	scored, err := service.ClearVote(analyst.ID, indicator.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 24, scored.Score)
+	mocks.votes.AssertExpectations(t)
+}
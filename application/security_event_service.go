@@ -0,0 +1,77 @@
+package application
+
+import (
+	"errors"
+	"strings"
+	"threat-intel-backend/domain"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidSecurityEventType = errors.New("invalid security event type")
+
+type SecurityEventService struct {
+	eventRepo domain.SecurityEventRepository
+	userRepo  domain.UserRepository
+}
+
+type ListSecurityEventsRequest struct {
+	Type     domain.SecurityEventType `form:"type"`
+	UserID   string                   `form:"user_id" binding:"omitempty,uuid"`
+	Email    string                   `form:"email"`
+	Page     int                      `form:"page" binding:"omitempty,min=1"`
+	PageSize int                      `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+type SecurityEventListResponse struct {
+	Events   []*domain.SecurityEvent `json:"events"`
+	Total    int64                   `json:"total"`
+	Page     int                     `json:"page"`
+	PageSize int                     `json:"page_size"`
+}
+
+func NewSecurityEventService(eventRepo domain.SecurityEventRepository, userRepo domain.UserRepository) *SecurityEventService {
+	return &SecurityEventService{
+		eventRepo: eventRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// ListSecurityEvents returns the matching events, newest first (admin only).
+func (s *SecurityEventService) ListSecurityEvents(actorID uuid.UUID, req ListSecurityEventsRequest) (*SecurityEventListResponse, error) {
+	if err := requireRole(s.userRepo, actorID, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	if req.Type != "" && !req.Type.IsValid() {
+		return nil, ErrInvalidSecurityEventType
+	}
+
+	var userID *uuid.UUID
+	if req.UserID != "" {
+		id, err := uuid.Parse(req.UserID)
+		if err != nil {
+			return nil, ErrUserNotFound
+		}
+		userID = &id
+	}
+
+	page, pageSize := normalizePage(req.Page, req.PageSize)
+
+	events, total, err := s.eventRepo.List(domain.SecurityEventFilter{
+		Type:   req.Type,
+		UserID: userID,
+		Email:  strings.ToLower(strings.TrimSpace(req.Email)),
+		Offset: (page - 1) * pageSize,
+		Limit:  pageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SecurityEventListResponse{
+		Events:   events,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
@@ -0,0 +1,52 @@
+package application
+
+import (
+	"testing"
+	"threat-intel-backend/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityEventService_ListSecurityEvents(t *testing.T) {
+	mockEvents := new(MockSecurityEventRepository)
+	mockUsers := new(MockUserRepository)
+	service := NewSecurityEventService(mockEvents, mockUsers)
+	admin, _ := domain.NewUser("admin@example.com", "password123", domain.RoleAdmin)
+	viewer, _ := domain.NewUser("viewer@example.com", "password123", domain.RoleViewer)
+	mockUsers.On("FindByID", admin.ID).Return(admin, nil)
+	mockUsers.On("FindByID", viewer.ID).Return(viewer, nil)
+
+	t.Run("applies filter and pagination", func(t *testing.T) {
+		events := []*domain.SecurityEvent{domain.NewSecurityEvent(domain.SecurityEventAccountLocked, "a@example.com", "192.0.2.1", "")}
+		mockEvents.On("List", domain.SecurityEventFilter{
+			Type:   domain.SecurityEventAccountLocked,
+			Email:  "a@example.com",
+			Offset: 10,
+			Limit:  10,
+		}).Return(events, int64(11), nil).Once()
+
+		resp, err := service.ListSecurityEvents(admin.ID, ListSecurityEventsRequest{
+			Type:     domain.SecurityEventAccountLocked,
+			Email:    " A@example.com",
+			Page:     2,
+			PageSize: 10,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, events, resp.Events)
+		assert.Equal(t, int64(11), resp.Total)
+		mockEvents.AssertExpectations(t)
+	})
+
+	t.Run("rejects unknown type", func(t *testing.T) {
+		_, err := service.ListSecurityEvents(admin.ID, ListSecurityEventsRequest{Type: "login_failed"})
+
+		assert.Equal(t, ErrInvalidSecurityEventType, err)
+	})
+
+	t.Run("requires admin", func(t *testing.T) {
+		_, err := service.ListSecurityEvents(viewer.ID, ListSecurityEventsRequest{})
+
+		assert.Equal(t, ErrInsufficientPermissions, err)
+	})
+}
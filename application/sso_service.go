@@ -0,0 +1,196 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"threat-intel-backend/domain"
+	"threat-intel-backend/infrastructure/oidc"
+)
+
+var (
+	ErrSSODisabled         = errors.New("single sign-on is not configured")
+	ErrInvalidSSOState     = errors.New("invalid or expired SSO state")
+	ErrSSOFailed           = errors.New("single sign-on failed")
+	ErrSSOEmailNotVerified = errors.New("identity provider did not verify the email address")
+	ErrSSONoRole           = errors.New("no role is mapped to the groups of this user")
+	ErrAccountInactive     = errors.New("account is inactive")
+)
+
+// ssoStateTTL is how long a user has to log in at the identity provider.
+const ssoStateTTL = 10 * time.Minute
+
+// OIDCProvider is the identity provider side of the login. oidc.Provider
+// implements it.
+type OIDCProvider interface {
+	Issuer() string
+	AuthCodeURL(state, nonce, codeChallenge string) string
+	Exchange(ctx context.Context, code, codeVerifier string) (string, error)
+	VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*oidc.Identity, error)
+}
+
+// SSORoleMapping derives the role of a user from their groups at the
+// identity provider. A user in several mapped groups gets the highest of
+// their roles; a user in none gets DefaultRole, or is refused when it is
+// empty.
+type SSORoleMapping struct {
+	GroupRoles  map[string]domain.UserRole
+	DefaultRole domain.UserRole
+}
+
+type SSOService struct {
+	provider     OIDCProvider
+	states       domain.SSOStateStore
+	identityRepo domain.ExternalIdentityRepository
+	userRepo     domain.UserRepository
+	sessions     SessionStarter
+	roles        SSORoleMapping
+}
+
+type SSOCallbackRequest struct {
+	Code  string `form:"code" binding:"required"`
+	State string `form:"state" binding:"required"`
+}
+
+func NewSSOService(provider OIDCProvider, states domain.SSOStateStore, identityRepo domain.ExternalIdentityRepository, userRepo domain.UserRepository, sessions SessionStarter, roles SSORoleMapping) *SSOService {
+	return &SSOService{
+		provider:     provider,
+		states:       states,
+		identityRepo: identityRepo,
+		userRepo:     userRepo,
+		sessions:     sessions,
+		roles:        roles,
+	}
+}
+
+// BeginLogin starts an authorization code flow and returns the URL of the
+// identity provider to send the user to. The nonce and PKCE verifier stay on
+// the server under the state, which the provider hands back on the callback.
+func (s *SSOService) BeginLogin() (string, error) {
+	state, err := oidc.RandomString()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		return "", err
+	}
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.states.Save(state, &domain.SSOLoginState{Nonce: nonce, CodeVerifier: verifier}, ssoStateTTL); err != nil {
+		return "", err
+	}
+	return s.provider.AuthCodeURL(state, nonce, challenge), nil
+}
+
+// CompleteLogin redeems the authorization code of a callback and issues our
+// own token pair for the user the ID token names.
+//
+// Users are found by their subject at the provider. On their first login, a
+// local user with the same verified email is linked, and otherwise one is
+// provisioned without a password. Their role follows the provider's groups
+// at every login. Local MFA applies as on a password login, so users who are
+// enrolled or whose role requires it get a challenge instead of tokens.
+func (s *SSOService) CompleteLogin(ctx context.Context, req SSOCallbackRequest) (*LoginResponse, error) {
+	login, err := s.states.Consume(req.State)
+	if err != nil {
+		return nil, err
+	}
+	if login == nil {
+		return nil, ErrInvalidSSOState
+	}
+
+	rawIDToken, err := s.provider.Exchange(ctx, req.Code, login.CodeVerifier)
+	if err != nil {
+		return nil, ErrSSOFailed
+	}
+	identity, err := s.provider.VerifyIDToken(ctx, rawIDToken, login.Nonce)
+	if err != nil {
+		return nil, ErrSSOFailed
+	}
+
+	role := s.roleFor(identity.Groups)
+	if role == "" {
+		return nil, ErrSSONoRole
+	}
+
+	user, link, err := s.resolveUser(identity, role)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, ErrAccountInactive
+	}
+
+	if user.Role != role {
+		user.ChangeRole(role)
+		if err := s.userRepo.Save(user); err != nil {
+			return nil, err
+		}
+	}
+
+	link.Email = user.Email
+	link.LastLoginAt = time.Now()
+	if err := s.identityRepo.Save(link); err != nil {
+		return nil, err
+	}
+
+	return s.sessions.StartSession(user)
+}
+
+// resolveUser returns the local user of an identity and its link, creating
+// either when this is the first login of the subject. A link whose user no
+// longer exists is handled like a first login and moved to the user found
+// or provisioned.
+func (s *SSOService) resolveUser(identity *oidc.Identity, role domain.UserRole) (*domain.User, *domain.ExternalIdentity, error) {
+	link, err := s.identityRepo.FindBySubject(s.provider.Issuer(), identity.Subject)
+	if err == nil {
+		if user, err := s.userRepo.FindByID(link.UserID); err == nil {
+			return user, link, nil
+		}
+	} else {
+		link = &domain.ExternalIdentity{
+			Provider:  s.provider.Issuer(),
+			Subject:   identity.Subject,
+			CreatedAt: time.Now(),
+		}
+	}
+
+	email := strings.ToLower(strings.TrimSpace(identity.Email))
+	if email == "" || !identity.EmailVerified {
+		return nil, nil, ErrSSOEmailNotVerified
+	}
+
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		user = domain.NewSSOUser(email, role)
+		if err := s.userRepo.Save(user); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	link.UserID = user.ID
+	return user, link, nil
+}
+
+func (s *SSOService) roleFor(groups []string) domain.UserRole {
+	var best *domain.User
+	for _, group := range groups {
+		role, ok := s.roles.GroupRoles[group]
+		if !ok {
+			continue
+		}
+		if best == nil || !best.HasPermission(role) {
+			best = &domain.User{Role: role}
+		}
+	}
+
+	if best == nil {
+		return s.roles.DefaultRole
+	}
+	return best.Role
+}
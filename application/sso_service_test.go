@@ -0,0 +1,285 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"threat-intel-backend/domain"
+	"threat-intel-backend/infrastructure/oidc"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOIDCProvider struct {
+	mock.Mock
+}
+
+func (m *MockOIDCProvider) Issuer() string {
+	return "https://idp.example.com"
+}
+
+func (m *MockOIDCProvider) AuthCodeURL(state, nonce, codeChallenge string) string {
+	return "https://idp.example.com/authorize?" + url.Values{
+		"state":          {state},
+		"nonce":          {nonce},
+		"code_challenge": {codeChallenge},
+	}.Encode()
+}
+
+func (m *MockOIDCProvider) Exchange(ctx context.Context, code, codeVerifier string) (string, error) {
+	args := m.Called(code, codeVerifier)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockOIDCProvider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*oidc.Identity, error) {
+	args := m.Called(rawIDToken, nonce)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*oidc.Identity), args.Error(1)
+}
+
+type MockSSOStateStore struct {
+	mock.Mock
+}
+
+func (m *MockSSOStateStore) Save(state string, login *domain.SSOLoginState, ttl time.Duration) error {
+	args := m.Called(state, login, ttl)
+	return args.Error(0)
+}
+
+func (m *MockSSOStateStore) Consume(state string) (*domain.SSOLoginState, error) {
+	args := m.Called(state)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SSOLoginState), args.Error(1)
+}
+
+type MockExternalIdentityRepository struct {
+	mock.Mock
+}
+
+func (m *MockExternalIdentityRepository) Save(identity *domain.ExternalIdentity) error {
+	args := m.Called(identity)
+	return args.Error(0)
+}
+
+func (m *MockExternalIdentityRepository) FindBySubject(provider, subject string) (*domain.ExternalIdentity, error) {
+	args := m.Called(provider, subject)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ExternalIdentity), args.Error(1)
+}
+
+func (m *MockExternalIdentityRepository) DeleteByUserID(userID uuid.UUID) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func setupSSOService() (*SSOService, *MockOIDCProvider, *MockSSOStateStore, *MockExternalIdentityRepository, *MockUserRepository, *MockSessionStarter) {
+	mockProvider := new(MockOIDCProvider)
+	mockStates := new(MockSSOStateStore)
+	mockIdentities := new(MockExternalIdentityRepository)
+	mockUsers := new(MockUserRepository)
+	mockSessions := new(MockSessionStarter)
+
+	service := NewSSOService(mockProvider, mockStates, mockIdentities, mockUsers, mockSessions, SSORoleMapping{
+		GroupRoles: map[string]domain.UserRole{
+			"intel-admins":   domain.RoleAdmin,
+			"intel-analysts": domain.RoleAnalyst,
+		},
+		DefaultRole: domain.RoleViewer,
+	})
+	return service, mockProvider, mockStates, mockIdentities, mockUsers, mockSessions
+}
+
+func TestSSOService_BeginLogin(t *testing.T) {
+	service, _, mockStates, _, _, _ := setupSSOService()
+	var saved *domain.SSOLoginState
+	mockStates.On("Save", mock.Anything, mock.MatchedBy(func(login *domain.SSOLoginState) bool {
+		saved = login
+		return true
+	}), ssoStateTTL).Return(nil).Once()
+
+	authURL, err := service.BeginLogin()
+
+	assert.NoError(t, err)
+	parsed, _ := url.Parse(authURL)
+	query := parsed.Query()
+	assert.NotEmpty(t, query.Get("state"))
+	assert.Equal(t, saved.Nonce, query.Get("nonce"))
+	assert.NotEqual(t, saved.CodeVerifier, query.Get("code_challenge"))
+	assert.NotContains(t, authURL, saved.CodeVerifier)
+	mockStates.AssertCalled(t, "Save", query.Get("state"), saved, ssoStateTTL)
+}
+
+func TestSSOService_CompleteLogin(t *testing.T) {
+	ctx := context.Background()
+	req := SSOCallbackRequest{Code: "code-1", State: "state-1"}
+	login := &domain.SSOLoginState{Nonce: "nonce-1", CodeVerifier: "verifier-1"}
+
+	callback := func(identity *oidc.Identity) (*SSOService, *MockExternalIdentityRepository, *MockUserRepository, *MockSessionStarter) {
+		service, mockProvider, mockStates, mockIdentities, mockUsers, mockSessions := setupSSOService()
+		mockStates.On("Consume", "state-1").Return(login, nil).Once()
+		mockProvider.On("Exchange", "code-1", "verifier-1").Return("id-token", nil).Once()
+		mockProvider.On("VerifyIDToken", "id-token", "nonce-1").Return(identity, nil).Once()
+		return service, mockIdentities, mockUsers, mockSessions
+	}
+
+	t.Run("provisions a new user with the mapped role", func(t *testing.T) {
+		service, mockIdentities, mockUsers, mockSessions := callback(&oidc.Identity{
+			Subject: "sub-1", Email: "Jane@Example.com", EmailVerified: true,
+			Groups: []string{"intel-analysts", "intel-admins", "staff"},
+		})
+		mockIdentities.On("FindBySubject", "https://idp.example.com", "sub-1").Return(nil, errors.New("record not found")).Once()
+		mockUsers.On("FindByEmail", "jane@example.com").Return(nil, errors.New("record not found")).Once()
+		mockUsers.On("Save", mock.MatchedBy(func(user *domain.User) bool {
+			return user.Email == "jane@example.com" && user.Role == domain.RoleAdmin && user.PasswordHash == ""
+		})).Return(nil).Once()
+		mockIdentities.On("Save", mock.MatchedBy(func(identity *domain.ExternalIdentity) bool {
+			return identity.Subject == "sub-1" && identity.Email == "jane@example.com" && !identity.LastLoginAt.IsZero()
+		})).Return(nil).Once()
+		mockSessions.On("StartSession", mock.AnythingOfType("*domain.User")).Return(&LoginResponse{AuthResponse: &AuthResponse{AccessToken: "access"}}, nil).Once()
+
+		resp, err := service.CompleteLogin(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "access", resp.AccessToken)
+		mockUsers.AssertExpectations(t)
+		mockIdentities.AssertExpectations(t)
+	})
+
+	t.Run("links an existing user by verified email", func(t *testing.T) {
+		existing, _ := domain.NewUser("jane@example.com", "password123", domain.RoleViewer)
+		service, mockIdentities, mockUsers, mockSessions := callback(&oidc.Identity{
+			Subject: "sub-1", Email: "jane@example.com", EmailVerified: true,
+		})
+		mockIdentities.On("FindBySubject", "https://idp.example.com", "sub-1").Return(nil, errors.New("record not found")).Once()
+		mockUsers.On("FindByEmail", "jane@example.com").Return(existing, nil).Once()
+		mockIdentities.On("Save", mock.MatchedBy(func(identity *domain.ExternalIdentity) bool {
+			return identity.UserID == existing.ID
+		})).Return(nil).Once()
+		mockSessions.On("StartSession", existing).Return(&LoginResponse{AuthResponse: &AuthResponse{AccessToken: "access"}}, nil).Once()
+
+		_, err := service.CompleteLogin(ctx, req)
+
+		assert.NoError(t, err)
+		mockUsers.AssertNotCalled(t, "Save", mock.Anything)
+	})
+
+	t.Run("refuses to link an unverified email", func(t *testing.T) {
+		service, mockIdentities, _, _ := callback(&oidc.Identity{Subject: "sub-1", Email: "jane@example.com"})
+		mockIdentities.On("FindBySubject", "https://idp.example.com", "sub-1").Return(nil, errors.New("record not found")).Once()
+
+		_, err := service.CompleteLogin(ctx, req)
+
+		assert.Equal(t, ErrSSOEmailNotVerified, err)
+	})
+
+	t.Run("syncs the role of a returning user", func(t *testing.T) {
+		user := domain.NewSSOUser("jane@example.com", domain.RoleAdmin)
+		link := &domain.ExternalIdentity{Provider: "https://idp.example.com", Subject: "sub-1", UserID: user.ID}
+		service, mockIdentities, mockUsers, mockSessions := callback(&oidc.Identity{Subject: "sub-1", Groups: []string{"staff"}})
+		mockIdentities.On("FindBySubject", "https://idp.example.com", "sub-1").Return(link, nil).Once()
+		mockUsers.On("FindByID", user.ID).Return(user, nil).Once()
+		mockUsers.On("Save", user).Return(nil).Once()
+		mockIdentities.On("Save", link).Return(nil).Once()
+		mockSessions.On("StartSession", user).Return(&LoginResponse{AuthResponse: &AuthResponse{AccessToken: "access"}}, nil).Once()
+
+		_, err := service.CompleteLogin(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.RoleViewer, user.Role)
+	})
+
+	t.Run("provisions again when the linked user was deleted", func(t *testing.T) {
+		link := &domain.ExternalIdentity{Provider: "https://idp.example.com", Subject: "sub-1", UserID: uuid.New()}
+		service, mockIdentities, mockUsers, mockSessions := callback(&oidc.Identity{
+			Subject: "sub-1", Email: "jane@example.com", EmailVerified: true,
+		})
+		mockIdentities.On("FindBySubject", "https://idp.example.com", "sub-1").Return(link, nil).Once()
+		mockUsers.On("FindByID", link.UserID).Return(nil, errors.New("record not found")).Once()
+		mockUsers.On("FindByEmail", "jane@example.com").Return(nil, errors.New("record not found")).Once()
+		var provisioned *domain.User
+		mockUsers.On("Save", mock.MatchedBy(func(user *domain.User) bool {
+			provisioned = user
+			return user.Email == "jane@example.com"
+		})).Return(nil).Once()
+		mockIdentities.On("Save", link).Return(nil).Once()
+		mockSessions.On("StartSession", mock.AnythingOfType("*domain.User")).Return(&LoginResponse{AuthResponse: &AuthResponse{AccessToken: "access"}}, nil).Once()
+
+		_, err := service.CompleteLogin(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, provisioned.ID, link.UserID)
+		mockIdentities.AssertExpectations(t)
+	})
+
+	t.Run("answers with the MFA challenge of the user", func(t *testing.T) {
+		user := domain.NewSSOUser("jane@example.com", domain.RoleViewer)
+		link := &domain.ExternalIdentity{Provider: "https://idp.example.com", Subject: "sub-1", UserID: user.ID}
+		service, mockIdentities, mockUsers, mockSessions := callback(&oidc.Identity{Subject: "sub-1"})
+		mockIdentities.On("FindBySubject", "https://idp.example.com", "sub-1").Return(link, nil).Once()
+		mockUsers.On("FindByID", user.ID).Return(user, nil).Once()
+		mockIdentities.On("Save", link).Return(nil).Once()
+		challenge := &MFAChallenge{MFARequired: true, EnrollmentRequired: true, MFAToken: "mfa-token"}
+		mockSessions.On("StartSession", user).Return(&LoginResponse{MFAChallenge: challenge}, nil).Once()
+
+		resp, err := service.CompleteLogin(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Nil(t, resp.AuthResponse)
+		assert.Equal(t, challenge, resp.MFAChallenge)
+	})
+
+	t.Run("rejects deactivated user", func(t *testing.T) {
+		user := domain.NewSSOUser("jane@example.com", domain.RoleViewer)
+		user.Deactivate()
+		link := &domain.ExternalIdentity{Provider: "https://idp.example.com", Subject: "sub-1", UserID: user.ID}
+		service, mockIdentities, mockUsers, mockSessions := callback(&oidc.Identity{Subject: "sub-1"})
+		mockIdentities.On("FindBySubject", "https://idp.example.com", "sub-1").Return(link, nil).Once()
+		mockUsers.On("FindByID", user.ID).Return(user, nil).Once()
+
+		_, err := service.CompleteLogin(ctx, req)
+
+		assert.Equal(t, ErrAccountInactive, err)
+		mockSessions.AssertNotCalled(t, "StartSession", mock.Anything)
+	})
+
+	t.Run("rejects unknown state", func(t *testing.T) {
+		service, _, mockStates, _, _, _ := setupSSOService()
+		mockStates.On("Consume", "state-1").Return(nil, nil).Once()
+
+		_, err := service.CompleteLogin(ctx, req)
+
+		assert.Equal(t, ErrInvalidSSOState, err)
+	})
+
+	t.Run("rejects invalid ID token", func(t *testing.T) {
+		service, mockProvider, mockStates, _, _, _ := setupSSOService()
+		mockStates.On("Consume", "state-1").Return(login, nil).Once()
+		mockProvider.On("Exchange", "code-1", "verifier-1").Return("id-token", nil).Once()
+		mockProvider.On("VerifyIDToken", "id-token", "nonce-1").Return(nil, oidc.ErrInvalidIDToken).Once()
+
+		_, err := service.CompleteLogin(ctx, req)
+
+		assert.Equal(t, ErrSSOFailed, err)
+	})
+}
+
+func TestSSOService_RoleMapping(t *testing.T) {
+	service, _, _, _, _, _ := setupSSOService()
+
+	assert.Equal(t, domain.RoleAdmin, service.roleFor([]string{"intel-admins", "intel-analysts"}))
+	assert.Equal(t, domain.RoleAnalyst, service.roleFor([]string{"staff", "intel-analysts"}))
+	assert.Equal(t, domain.RoleViewer, service.roleFor(nil))
+
+	service.roles.DefaultRole = ""
+	assert.Equal(t, domain.UserRole(""), service.roleFor([]string{"staff"}))
+}
@@ -0,0 +1,325 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"threat-intel-backend/domain"
+	"threat-intel-backend/infrastructure/stix"
+	"github.com/google/uuid"
+)
+
+// maxExportIndicators caps the size of a single exported bundle.
+const maxExportIndicators = 10000
+
+// stixImportSource is the source of imported indicators that name neither a
+// source nor the identity that produced them.
+const stixImportSource = "stix-import"
+
+type StixService struct {
+	indicatorRepo    domain.IndicatorRepository
+	entityRepo       domain.ThreatEntityRepository
+	relationshipRepo domain.RelationshipRepository
+	userRepo         domain.UserRepository
+	entitlementRepo  domain.EntitlementRepository
+	transactor       domain.Transactor
+	lookupCache      domain.LookupCache
+}
+
+// StixImportResult counts what happened to the objects of an imported bundle.
+// Objects that cannot be represented are skipped and explained in Errors.
+type StixImportResult struct {
+	Created int      `json:"created"`
+	Updated int      `json:"updated"`
+	Skipped int      `json:"skipped"`
+	Errors  []string `json:"errors,omitempty"`
+
+	indicators []domain.IndicatorKey
+}
+
+type ExportStixRequest struct {
+	Type           domain.IndicatorType `form:"type"`
+	Severity       domain.Severity      `form:"severity"`
+	Tag            string               `form:"tag"`
+	Source         string               `form:"source"`
+	IncludeExpired bool                 `form:"include_expired"`
+}
+
+func NewStixService(indicatorRepo domain.IndicatorRepository, entityRepo domain.ThreatEntityRepository, relationshipRepo domain.RelationshipRepository, userRepo domain.UserRepository, entitlementRepo domain.EntitlementRepository, transactor domain.Transactor, lookupCache domain.LookupCache) *StixService {
+	return &StixService{
+		indicatorRepo:    indicatorRepo,
+		entityRepo:       entityRepo,
+		relationshipRepo: relationshipRepo,
+		userRepo:         userRepo,
+		entitlementRepo:  entitlementRepo,
+		transactor:       transactor,
+		lookupCache:      lookupCache,
+	}
+}
+
+// ImportBundle upserts the indicators, malware, threat actors and
+// relationships of a bundle in one transaction, so that a failure leaves
+// nothing half imported. Indicators are merged like those of feeds: one per
+// observable, with a sighting of the source of the bundle. Relationships of
+// the bundle are rewritten to point at the indicators they were merged into.
+func (s *StixService) ImportBundle(actorID uuid.UUID, bundle *stix.Bundle) (*StixImportResult, error) {
+	if err := requireRole(s.userRepo, actorID, domain.RoleAnalyst); err != nil {
+		return nil, err
+	}
+
+	var result *StixImportResult
+	err := s.transactor.Transaction(func(repos domain.Repositories) error {
+		var err error
+		result, err = importObjects(repos, actorID, bundle)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	forgetLookups(context.Background(), s.lookupCache, result.indicators)
+	return result, nil
+}
+
+func importObjects(repos domain.Repositories, actorID uuid.UUID, bundle *stix.Bundle) (*StixImportResult, error) {
+	result := &StixImportResult{}
+	refs := make(map[string]string)
+	identities := make(map[string]string)
+	for _, object := range bundle.Objects {
+		if object.Type == stix.TypeIdentity && object.Name != "" {
+			identities[object.ID] = object.Name
+		}
+	}
+
+	// Relationships go last so that refs to merged indicators can be rewritten.
+	var relationships []stix.Object
+	for _, object := range bundle.Objects {
+		var created bool
+		var err error
+
+		switch object.Type {
+		case stix.TypeIndicator:
+			var indicator *domain.Indicator
+			indicator, created, err = importIndicator(repos, actorID, object, stixSource(object, identities), refs)
+			if err == nil {
+				result.indicators = append(result.indicators, indicator.Key())
+			}
+		case stix.TypeMalware, stix.TypeThreatActor:
+			created, err = importThreatEntity(repos.ThreatEntities, object)
+		case stix.TypeRelationship:
+			relationships = append(relationships, object)
+			continue
+		default:
+			if stix.IsContextType(object.Type) {
+				continue
+			}
+			result.skip(object, fmt.Errorf("unsupported object type %q", object.Type))
+			continue
+		}
+
+		if err := result.record(object, created, err); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, object := range relationships {
+		created, err := importRelationship(repos.Relationships, object, refs)
+		if err := result.record(object, created, err); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// stixSource names the source of an imported object: its x_source, else
+// the identity that created it.
+func stixSource(object stix.Object, identities map[string]string) string {
+	switch {
+	case object.Source != "":
+		return object.Source
+	case identities[object.CreatedByRef] != "":
+		return identities[object.CreatedByRef]
+	case object.CreatedByRef != "":
+		return object.CreatedByRef
+	default:
+		return stixImportSource
+	}
+}
+
+// ExportBundle serializes the matching indicators of the types covered by the
+// user's entitlement together with their outgoing relationships and the
+// malware and threat actors they point to.
+func (s *StixService) ExportBundle(userID uuid.UUID, req ExportStixRequest) (*stix.Bundle, error) {
+	if req.Type != "" && !req.Type.IsValid() {
+		return nil, domain.ErrInvalidIndicatorType
+	}
+	if req.Severity != "" && !req.Severity.IsValid() {
+		return nil, domain.ErrInvalidSeverity
+	}
+
+	tier, err := requireEntitlement(s.userRepo, s.entitlementRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	types, err := readableTypes(tier, req.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	indicators, _, err := s.indicatorRepo.List(domain.IndicatorFilter{
+		Type:           req.Type,
+		Types:          types,
+		Severity:       req.Severity,
+		Tag:            strings.ToLower(strings.TrimSpace(req.Tag)),
+		Source:         req.Source,
+		IncludeExpired: req.IncludeExpired,
+		Limit:          maxExportIndicators,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]stix.Object, 0, len(indicators))
+	sourceRefs := make([]string, 0, len(indicators))
+	for _, indicator := range indicators {
+		object := stix.FromIndicator(indicator)
+		objects = append(objects, object)
+		sourceRefs = append(sourceRefs, object.ID)
+	}
+
+	relationships, err := s.relationshipRepo.FindBySourceRefs(sourceRefs)
+	if err != nil {
+		return nil, err
+	}
+
+	var targetRefs []string
+	seen := make(map[string]bool)
+	for _, relationship := range relationships {
+		objects = append(objects, stix.FromRelationship(relationship))
+		if !seen[relationship.TargetRef] {
+			seen[relationship.TargetRef] = true
+			targetRefs = append(targetRefs, relationship.TargetRef)
+		}
+	}
+
+	entities, err := s.entityRepo.FindByStixIDs(targetRefs)
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range entities {
+		objects = append(objects, stix.FromThreatEntity(entity))
+	}
+
+	return stix.NewBundle(objects), nil
+}
+
+func importIndicator(repos domain.Repositories, actorID uuid.UUID, object stix.Object, source string, refs map[string]string) (*domain.Indicator, bool, error) {
+	incoming, err := stix.ToIndicator(object)
+	if err != nil {
+		return nil, false, invalidObject(err)
+	}
+	raw, err := json.Marshal(object)
+	if err != nil {
+		return nil, false, invalidObject(err)
+	}
+
+	indicator, created, err := upsertIndicator(repos.Indicators, repos.Sightings, IndicatorReport{
+		Indicator:  incoming,
+		Source:     source,
+		RawPayload: string(raw),
+	}, actorID)
+	if err != nil {
+		return nil, false, err
+	}
+	if indicator.StixID != object.ID {
+		refs[object.ID] = indicator.StixID
+	}
+	return indicator, created, nil
+}
+
+func importThreatEntity(entityRepo domain.ThreatEntityRepository, object stix.Object) (bool, error) {
+	incoming, err := stix.ToThreatEntity(object)
+	if err != nil {
+		return false, invalidObject(err)
+	}
+
+	existing, err := entityRepo.FindByStixID(object.ID)
+	if err != nil {
+		return true, entityRepo.Save(incoming)
+	}
+
+	existing.Name = incoming.Name
+	existing.Description = incoming.Description
+	existing.Aliases = incoming.Aliases
+	existing.Labels = incoming.Labels
+	existing.IsFamily = incoming.IsFamily
+	existing.UpdatedAt = time.Now()
+
+	return false, entityRepo.Save(existing)
+}
+
+func importRelationship(relationshipRepo domain.RelationshipRepository, object stix.Object, refs map[string]string) (bool, error) {
+	if ref, ok := refs[object.SourceRef]; ok {
+		object.SourceRef = ref
+	}
+	if ref, ok := refs[object.TargetRef]; ok {
+		object.TargetRef = ref
+	}
+
+	incoming, err := stix.ToRelationship(object)
+	if err != nil {
+		return false, invalidObject(err)
+	}
+
+	existing, err := relationshipRepo.FindByStixID(object.ID)
+	if err != nil {
+		return true, relationshipRepo.Save(incoming)
+	}
+
+	existing.RelationshipType = incoming.RelationshipType
+	existing.SourceRef = incoming.SourceRef
+	existing.TargetRef = incoming.TargetRef
+	existing.Description = incoming.Description
+	existing.UpdatedAt = time.Now()
+
+	return false, relationshipRepo.Save(existing)
+}
+
+// objectError marks conversion failures, which skip the object, as opposed
+// to storage failures, which abort the import.
+type objectError struct {
+	err error
+}
+
+func (e objectError) Error() string { return e.err.Error() }
+
+func (e objectError) Unwrap() error { return e.err }
+
+func invalidObject(err error) error {
+	return objectError{err: err}
+}
+
+func (r *StixImportResult) record(object stix.Object, created bool, err error) error {
+	if err != nil {
+		if _, ok := err.(objectError); ok {
+			r.skip(object, err)
+			return nil
+		}
+		return err
+	}
+
+	if created {
+		r.Created++
+	} else {
+		r.Updated++
+	}
+	return nil
+}
+
+func (r *StixImportResult) skip(object stix.Object, err error) {
+	r.Skipped++
+	r.Errors = append(r.Errors, fmt.Sprintf("%s: %v", object.ID, err))
+}
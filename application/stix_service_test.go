@@ -0,0 +1,291 @@
+package application
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"threat-intel-backend/domain"
+	"threat-intel-backend/infrastructure/stix"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockThreatEntityRepository struct {
+	mock.Mock
+}
+
+func (m *MockThreatEntityRepository) Save(entity *domain.ThreatEntity) error {
+	args := m.Called(entity)
+	return args.Error(0)
+}
+
+func (m *MockThreatEntityRepository) FindByStixID(stixID string) (*domain.ThreatEntity, error) {
+	args := m.Called(stixID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ThreatEntity), args.Error(1)
+}
+
+func (m *MockThreatEntityRepository) FindByStixIDs(stixIDs []string) ([]*domain.ThreatEntity, error) {
+	args := m.Called(stixIDs)
+	return args.Get(0).([]*domain.ThreatEntity), args.Error(1)
+}
+
+type MockRelationshipRepository struct {
+	mock.Mock
+}
+
+func (m *MockRelationshipRepository) Save(relationship *domain.Relationship) error {
+	args := m.Called(relationship)
+	return args.Error(0)
+}
+
+func (m *MockRelationshipRepository) FindByStixID(stixID string) (*domain.Relationship, error) {
+	args := m.Called(stixID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Relationship), args.Error(1)
+}
+
+func (m *MockRelationshipRepository) FindBySourceRefs(sourceRefs []string) ([]*domain.Relationship, error) {
+	args := m.Called(sourceRefs)
+	return args.Get(0).([]*domain.Relationship), args.Error(1)
+}
+
+func setupStixService() (*StixService, *MockIndicatorRepository, *MockThreatEntityRepository, *MockRelationshipRepository, *domain.User) {
+	mockIndicators := new(MockIndicatorRepository)
+	mockEntities := new(MockThreatEntityRepository)
+	mockRelationships := new(MockRelationshipRepository)
+	mockSightings := new(MockSightingRepository)
+	mockSightings.On("FindByIndicatorAndSource", mock.Anything, mock.Anything).Return(nil, errors.New("record not found"))
+	mockSightings.On("Save", mock.AnythingOfType("*domain.Sighting")).Return(nil)
+	mockUsers := new(MockUserRepository)
+	analyst, _ := domain.NewUser("analyst@example.com", "password123", domain.RoleAnalyst)
+	mockUsers.On("FindByID", analyst.ID).Return(analyst, nil)
+
+	transactor := &fakeTransactor{repos: domain.Repositories{
+		Indicators:     mockIndicators,
+		Sightings:      mockSightings,
+		ThreatEntities: mockEntities,
+		Relationships:  mockRelationships,
+	}}
+	service := NewStixService(mockIndicators, mockEntities, mockRelationships, mockUsers, new(MockEntitlementRepository), transactor, newMemoryLookupCache())
+	return service, mockIndicators, mockEntities, mockRelationships, analyst
+}
+
+func loadBundle(t *testing.T, name string) *stix.Bundle {
+	file, err := os.Open("../infrastructure/stix/testdata/" + name)
+	if err != nil {
+		t.Fatalf("failed to open fixture: %v", err)
+	}
+	defer file.Close()
+
+	bundle, err := stix.Decode(file)
+	if err != nil {
+		t.Fatalf("failed to decode fixture: %v", err)
+	}
+	return bundle
+}
+
+func TestStixService_ImportBundle(t *testing.T) {
+	notFound := errors.New("record not found")
+
+	t.Run("upserts objects and rewrites refs of merged indicators", func(t *testing.T) {
+		service, mockIndicators, mockEntities, mockRelationships, analyst := setupStixService()
+		existing, _ := domain.NewIndicator(domain.IndicatorIPv4, "198.51.100.23", 40, domain.SeverityLow)
+		existing.SetTags([]string{"botnet"})
+
+		mockIndicators.On("FindByStixID", mock.Anything).Return(nil, notFound)
+		mockIndicators.On("FindByValue", domain.IndicatorIPv4, "198.51.100.23").Return(existing, nil)
+		mockIndicators.On("FindByValue", mock.Anything, mock.Anything).Return(nil, notFound)
+		mockIndicators.On("Save", mock.AnythingOfType("*domain.Indicator")).Return(nil)
+		mockEntities.On("FindByStixID", mock.Anything).Return(nil, notFound)
+		mockEntities.On("Save", mock.AnythingOfType("*domain.ThreatEntity")).Return(nil)
+		mockRelationships.On("FindByStixID", mock.Anything).Return(nil, notFound)
+		mockRelationships.On("Save", mock.AnythingOfType("*domain.Relationship")).Return(nil)
+		cache := service.lookupCache.(*memoryLookupCache)
+		cache.entries[existing.Key()] = &domain.LookupMatch{IndicatorID: existing.ID, Confidence: 40}
+
+		result, err := service.ImportBundle(analyst.ID, loadBundle(t, "campaign.json"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 8, result.Created)
+		assert.Equal(t, 1, result.Updated)
+		assert.Equal(t, 0, result.Skipped)
+		assert.Empty(t, result.Errors)
+
+		assert.Equal(t, 85, existing.Confidence)
+		assert.Equal(t, domain.Tags{"apt-example", "botnet", "c2"}, existing.Tags)
+		assert.NotContains(t, cache.entries, existing.Key())
+		mockRelationships.AssertCalled(t, "Save", mock.MatchedBy(func(r *domain.Relationship) bool {
+			return r.StixID == "relationship--44298a74-ba52-4f0c-87a3-1824e67d7fad" && r.SourceRef == existing.StixID
+		}))
+		mockIndicators.AssertCalled(t, "Save", mock.MatchedBy(func(i *domain.Indicator) bool {
+			return i.StixID == "indicator--a932fcc6-e032-476c-826f-cb970a5a1ade" && i.Value == "login-example.com" && i.CreatedBy == analyst.ID
+		}))
+		mockSightings := service.transactor.(*fakeTransactor).repos.Sightings.(*MockSightingRepository)
+		mockSightings.AssertCalled(t, "Save", mock.MatchedBy(func(s *domain.Sighting) bool {
+			return s.IndicatorID == existing.ID && s.Source == stixImportSource && strings.Contains(s.RawPayload, "198.51.100.23")
+		}))
+		assert.Equal(t, 1, service.transactor.(*fakeTransactor).commits)
+	})
+
+	t.Run("names the identity that created an indicator as its source", func(t *testing.T) {
+		service, mockIndicators, _, _, analyst := setupStixService()
+		mockIndicators.On("FindByValue", domain.IndicatorDomain, "evil.example.com").Return(nil, notFound)
+		mockIndicators.On("FindByStixID", mock.Anything).Return(nil, notFound)
+		mockIndicators.On("Save", mock.AnythingOfType("*domain.Indicator")).Return(nil)
+
+		bundle := stix.NewBundle([]stix.Object{
+			{Type: stix.TypeIdentity, ID: "identity--f431f809-377b-45e0-aa1c-6a4751cae5ff", Name: "Partner CERT"},
+			{
+				Type:         stix.TypeIndicator,
+				ID:           "indicator--0f1d4b3e-5a6b-4c4b-9a43-2f6f6d1f6a10",
+				Pattern:      "[domain-name:value = 'evil.example.com']",
+				PatternType:  "stix",
+				CreatedByRef: "identity--f431f809-377b-45e0-aa1c-6a4751cae5ff",
+			},
+		})
+		_, err := service.ImportBundle(analyst.ID, bundle)
+
+		assert.NoError(t, err)
+		mockSightings := service.transactor.(*fakeTransactor).repos.Sightings.(*MockSightingRepository)
+		mockSightings.AssertCalled(t, "Save", mock.MatchedBy(func(s *domain.Sighting) bool {
+			return s.Source == "Partner CERT"
+		}))
+	})
+
+	t.Run("keeps a stored indicator whose STIX ID now has another value", func(t *testing.T) {
+		service, mockIndicators, _, mockRelationships, analyst := setupStixService()
+		stored, _ := domain.NewIndicator(domain.IndicatorDomain, "old.example.com", 50, domain.SeverityLow)
+		stored.StixID = "indicator--0f1d4b3e-5a6b-4c4b-9a43-2f6f6d1f6a10"
+		mockIndicators.On("FindByValue", domain.IndicatorDomain, "new.example.com").Return(nil, notFound)
+		mockIndicators.On("FindByStixID", stored.StixID).Return(stored, nil)
+		mockIndicators.On("Save", mock.AnythingOfType("*domain.Indicator")).Return(nil)
+		mockRelationships.On("FindByStixID", mock.Anything).Return(nil, notFound)
+		mockRelationships.On("Save", mock.AnythingOfType("*domain.Relationship")).Return(nil)
+
+		bundle := stix.NewBundle([]stix.Object{
+			{Type: stix.TypeIndicator, ID: stored.StixID, Pattern: "[domain-name:value = 'new.example.com']", PatternType: "stix"},
+			{
+				Type:             stix.TypeRelationship,
+				ID:               "relationship--6f0f6b8e-0c4d-4a4e-9d55-0d7d2a9d7a11",
+				RelationshipType: "indicates",
+				SourceRef:        stored.StixID,
+				TargetRef:        "malware--31b940d4-6f7f-459a-80ea-9c1f17b5891b",
+			},
+		})
+		result, err := service.ImportBundle(analyst.ID, bundle)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.Created)
+		assert.Equal(t, "old.example.com", stored.Value)
+		mockIndicators.AssertCalled(t, "Save", mock.MatchedBy(func(i *domain.Indicator) bool {
+			return i.Value == "new.example.com" && i.StixID != stored.StixID
+		}))
+		mockRelationships.AssertCalled(t, "Save", mock.MatchedBy(func(r *domain.Relationship) bool {
+			return r.SourceRef != stored.StixID
+		}))
+	})
+
+	t.Run("updates objects with known STIX IDs", func(t *testing.T) {
+		service, _, mockEntities, _, analyst := setupStixService()
+		malware, _ := domain.NewThreatEntity(domain.ThreatEntityMalware, "malware--31b940d4-6f7f-459a-80ea-9c1f17b5891b", "OldName")
+		mockEntities.On("FindByStixID", malware.StixID).Return(malware, nil)
+		mockEntities.On("Save", malware).Return(nil).Once()
+
+		bundle := loadBundle(t, "campaign.json")
+		bundle.Objects = bundle.Objects[5:6]
+		result, err := service.ImportBundle(analyst.ID, bundle)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Updated)
+		assert.Equal(t, "ExampleRAT", malware.Name)
+		assert.True(t, malware.IsFamily)
+	})
+
+	t.Run("skips unsupported patterns", func(t *testing.T) {
+		service, mockIndicators, _, _, analyst := setupStixService()
+		mockIndicators.On("FindByStixID", mock.Anything).Return(nil, notFound)
+		mockIndicators.On("FindByValue", mock.Anything, mock.Anything).Return(nil, notFound)
+		mockIndicators.On("Save", mock.Anything).Return(nil)
+
+		result, err := service.ImportBundle(analyst.ID, loadBundle(t, "hashes.json"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 4, result.Created)
+		assert.Equal(t, 2, result.Skipped)
+	})
+
+	t.Run("reports unsupported object types only", func(t *testing.T) {
+		service, _, _, _, analyst := setupStixService()
+		bundle := stix.NewBundle([]stix.Object{
+			{Type: "marking-definition", ID: "marking-definition--613f2e26-407d-48c7-9eca-b8e91df99dc9"},
+			{Type: "report", ID: "report--84e4d88f-44ea-4bcd-bbf3-b2c1c320bcb3"},
+			{Type: "x-acme-widget", ID: "x-acme-widget--4527e5de-8572-446a-a57a-706f15467461"},
+		})
+
+		result, err := service.ImportBundle(analyst.ID, bundle)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Skipped)
+		assert.Equal(t, []string{`x-acme-widget--4527e5de-8572-446a-a57a-706f15467461: unsupported object type "x-acme-widget"`}, result.Errors)
+	})
+
+	t.Run("storage failure aborts", func(t *testing.T) {
+		service, mockIndicators, _, _, analyst := setupStixService()
+		mockIndicators.On("FindByStixID", mock.Anything).Return(nil, notFound)
+		mockIndicators.On("FindByValue", mock.Anything, mock.Anything).Return(nil, notFound)
+		mockIndicators.On("Save", mock.Anything).Return(errors.New("connection refused"))
+
+		_, err := service.ImportBundle(analyst.ID, loadBundle(t, "hashes.json"))
+
+		assert.EqualError(t, err, "connection refused")
+		assert.Equal(t, 1, service.transactor.(*fakeTransactor).rollbacks)
+	})
+
+	t.Run("viewers cannot import", func(t *testing.T) {
+		service, _, _, _, _ := setupStixService()
+		mockUsers := service.userRepo.(*MockUserRepository)
+		viewer, _ := domain.NewUser("viewer@example.com", "password123", domain.RoleViewer)
+		mockUsers.On("FindByID", viewer.ID).Return(viewer, nil)
+
+		_, err := service.ImportBundle(viewer.ID, stix.NewBundle(nil))
+
+		assert.Equal(t, ErrInsufficientPermissions, err)
+	})
+}
+
+func TestStixService_ExportBundle(t *testing.T) {
+	service, mockIndicators, mockEntities, mockRelationships, analyst := setupStixService()
+
+	first, _ := domain.NewIndicator(domain.IndicatorDomain, "evil.example.com", 80, domain.SeverityHigh)
+	second, _ := domain.NewIndicator(domain.IndicatorIPv4, "198.51.100.7", 60, domain.SeverityMedium)
+	malware, _ := domain.NewThreatEntity(domain.ThreatEntityMalware, domain.NewStixID("malware", uuid.New()), "ExampleRAT")
+	relationship, _ := domain.NewRelationship(domain.NewStixID("relationship", uuid.New()), "indicates", first.StixID, malware.StixID)
+
+	mockIndicators.On("List", domain.IndicatorFilter{Tag: "phishing", Limit: maxExportIndicators}).
+		Return([]*domain.Indicator{first, second}, int64(2), nil)
+	mockRelationships.On("FindBySourceRefs", []string{first.StixID, second.StixID}).
+		Return([]*domain.Relationship{relationship}, nil)
+	mockEntities.On("FindByStixIDs", []string{malware.StixID}).
+		Return([]*domain.ThreatEntity{malware}, nil)
+
+	bundle, err := service.ExportBundle(analyst.ID, ExportStixRequest{Tag: "Phishing"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, stix.TypeBundle, bundle.Type)
+	assert.Len(t, bundle.Objects, 4)
+	assert.Equal(t, first.StixID, bundle.Objects[0].ID)
+	assert.Equal(t, "[domain-name:value = 'evil.example.com']", bundle.Objects[0].Pattern)
+	assert.Equal(t, relationship.StixID, bundle.Objects[2].ID)
+	assert.Equal(t, malware.StixID, bundle.Objects[3].ID)
+
+	_, err = service.ExportBundle(analyst.ID, ExportStixRequest{Type: "mutex"})
+	assert.Equal(t, domain.ErrInvalidIndicatorType, err)
+}
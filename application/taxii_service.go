@@ -0,0 +1,246 @@
+package application
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"threat-intel-backend/domain"
+	"threat-intel-backend/infrastructure/stix"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrCollectionNotFound = errors.New("collection not found")
+	ErrInvalidTaxiiQuery  = errors.New("invalid TAXII query")
+)
+
+const (
+	defaultTaxiiPageSize = 100
+	maxTaxiiPageSize     = 1000
+)
+
+// TaxiiCollection describes a read-only collection of indicators.
+type TaxiiCollection struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	CanRead     bool     `json:"can_read"`
+	CanWrite    bool     `json:"can_write"`
+	MediaTypes  []string `json:"media_types"`
+
+	types []domain.IndicatorType
+	tier  domain.Tier
+}
+
+// taxiiCollections partitions the indicator store by type. Each collection
+// requires a minimum entitled tier; analysts and admins can read all of them.
+var taxiiCollections = []TaxiiCollection{
+	{
+		ID:          "5a0f8a3c-1f7e-4a8e-9c57-6f1e3a3b7d01",
+		Title:       "Network Indicators",
+		Description: "IP addresses, domains and URLs",
+		types:       []domain.IndicatorType{domain.IndicatorIPv4, domain.IndicatorIPv6, domain.IndicatorDomain, domain.IndicatorURL},
+		tier:        domain.TierBasic,
+	},
+	{
+		ID:          "9d3b6c2e-47a1-4c0f-8b1d-2e5f7a9c4b02",
+		Title:       "File Hashes",
+		Description: "MD5, SHA-1 and SHA-256 file hashes",
+		types:       []domain.IndicatorType{domain.IndicatorMD5, domain.IndicatorSHA1, domain.IndicatorSHA256},
+		tier:        domain.TierPremium,
+	},
+	{
+		ID:          "c7e4d1a9-3b8f-4e26-a05c-8f2d6b1e9a03",
+		Title:       "All Indicators",
+		Description: "Every indicator in the store",
+		tier:        domain.TierEnterprise,
+	},
+}
+
+// TaxiiObjectsRequest holds the supported filters of the objects endpoint.
+// AddedAfter is an RFC 3339 timestamp and Next an opaque cursor returned by a
+// previous page.
+type TaxiiObjectsRequest struct {
+	AddedAfter string `form:"added_after"`
+	Limit      int    `form:"limit"`
+	Next       string `form:"next"`
+}
+
+// TaxiiEnvelope is a page of objects. The date added of an object is the time
+// its indicator was last updated, so that clients polling with added_after
+// pick up modified indicators as well as new ones.
+type TaxiiEnvelope struct {
+	More    bool          `json:"more"`
+	Next    string        `json:"next,omitempty"`
+	Objects []stix.Object `json:"objects,omitempty"`
+
+	DateAddedFirst time.Time `json:"-"`
+	DateAddedLast  time.Time `json:"-"`
+}
+
+type TaxiiService struct {
+	indicatorRepo   domain.IndicatorRepository
+	userRepo        domain.UserRepository
+	entitlementRepo domain.EntitlementRepository
+}
+
+func NewTaxiiService(indicatorRepo domain.IndicatorRepository, userRepo domain.UserRepository, entitlementRepo domain.EntitlementRepository) *TaxiiService {
+	return &TaxiiService{
+		indicatorRepo:   indicatorRepo,
+		userRepo:        userRepo,
+		entitlementRepo: entitlementRepo,
+	}
+}
+
+// ListCollections returns the collections visible to the user.
+func (s *TaxiiService) ListCollections(userID uuid.UUID) ([]TaxiiCollection, error) {
+	tier, err := accessTier(s.userRepo, s.entitlementRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	collections := make([]TaxiiCollection, 0, len(taxiiCollections))
+	for _, collection := range taxiiCollections {
+		if tier.Includes(collection.tier) {
+			collections = append(collections, collection.describe())
+		}
+	}
+	return collections, nil
+}
+
+// GetCollection returns a collection visible to the user. Collections above
+// the user's tier are reported as not found.
+func (s *TaxiiService) GetCollection(userID uuid.UUID, collectionID string) (*TaxiiCollection, error) {
+	collection, err := s.findCollection(userID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	described := collection.describe()
+	return &described, nil
+}
+
+// GetObjects returns a page of the collection's indicators in the order they
+// were added, including expired ones so that clients can retire them.
+func (s *TaxiiService) GetObjects(userID uuid.UUID, collectionID string, req TaxiiObjectsRequest) (*TaxiiEnvelope, error) {
+	collection, err := s.findCollection(userID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := taxiiCursor(req)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit < 1 {
+		limit = defaultTaxiiPageSize
+	}
+	if limit > maxTaxiiPageSize {
+		limit = maxTaxiiPageSize
+	}
+
+	// One extra row tells whether another page follows.
+	indicators, err := s.indicatorRepo.ListChanges(domain.IndicatorFilter{
+		Types:          collection.types,
+		IncludeExpired: true,
+		Limit:          limit + 1,
+	}, after)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope := &TaxiiEnvelope{}
+	if len(indicators) > limit {
+		indicators = indicators[:limit]
+		envelope.More = true
+	}
+	if len(indicators) == 0 {
+		return envelope, nil
+	}
+
+	envelope.Objects = make([]stix.Object, 0, len(indicators))
+	for _, indicator := range indicators {
+		envelope.Objects = append(envelope.Objects, stix.FromIndicator(indicator))
+	}
+
+	first, last := indicators[0], indicators[len(indicators)-1]
+	envelope.DateAddedFirst = first.UpdatedAt
+	envelope.DateAddedLast = last.UpdatedAt
+	if envelope.More {
+		envelope.Next = encodeTaxiiCursor(domain.IndicatorCursor{UpdatedAt: last.UpdatedAt, ID: last.ID})
+	}
+
+	return envelope, nil
+}
+
+func (s *TaxiiService) findCollection(userID uuid.UUID, collectionID string) (*TaxiiCollection, error) {
+	tier, err := accessTier(s.userRepo, s.entitlementRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range taxiiCollections {
+		collection := &taxiiCollections[i]
+		if collection.ID == collectionID && tier.Includes(collection.tier) {
+			return collection, nil
+		}
+	}
+	return nil, ErrCollectionNotFound
+}
+
+func (c TaxiiCollection) describe() TaxiiCollection {
+	c.CanRead = true
+	c.MediaTypes = []string{stix.MediaType}
+	return c
+}
+
+// taxiiCursor resolves the position to resume from. A next cursor already
+// lies after added_after, so it takes precedence.
+func taxiiCursor(req TaxiiObjectsRequest) (domain.IndicatorCursor, error) {
+	if req.Next != "" {
+		return decodeTaxiiCursor(req.Next)
+	}
+	if req.AddedAfter == "" {
+		return domain.IndicatorCursor{}, nil
+	}
+
+	addedAfter, err := time.Parse(time.RFC3339Nano, req.AddedAfter)
+	if err != nil {
+		return domain.IndicatorCursor{}, fmt.Errorf("%w: added_after must be an RFC 3339 timestamp", ErrInvalidTaxiiQuery)
+	}
+	return domain.IndicatorCursor{UpdatedAt: addedAfter}, nil
+}
+
+func encodeTaxiiCursor(cursor domain.IndicatorCursor) string {
+	raw := strconv.FormatInt(cursor.UpdatedAt.UnixNano(), 10) + ":" + cursor.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeTaxiiCursor(next string) (domain.IndicatorCursor, error) {
+	invalid := fmt.Errorf("%w: malformed next cursor", ErrInvalidTaxiiQuery)
+
+	raw, err := base64.RawURLEncoding.DecodeString(next)
+	if err != nil {
+		return domain.IndicatorCursor{}, invalid
+	}
+
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return domain.IndicatorCursor{}, invalid
+	}
+	unixNano, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return domain.IndicatorCursor{}, invalid
+	}
+	cursorID, err := uuid.Parse(id)
+	if err != nil {
+		return domain.IndicatorCursor{}, invalid
+	}
+
+	return domain.IndicatorCursor{UpdatedAt: time.Unix(0, unixNano).UTC(), ID: cursorID}, nil
+}
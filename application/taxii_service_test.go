@@ -0,0 +1,167 @@
+package application
+
+import (
+	"errors"
+	"testing"
+	"threat-intel-backend/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	networkCollectionID = "5a0f8a3c-1f7e-4a8e-9c57-6f1e3a3b7d01"
+	hashCollectionID    = "9d3b6c2e-47a1-4c0f-8b1d-2e5f7a9c4b02"
+)
+
+func setupTaxiiService() (*TaxiiService, *MockIndicatorRepository, *MockEntitlementRepository, *MockUserRepository) {
+	mockIndicators := new(MockIndicatorRepository)
+	mockEntitlements := new(MockEntitlementRepository)
+	mockUsers := new(MockUserRepository)
+	return NewTaxiiService(mockIndicators, mockUsers, mockEntitlements), mockIndicators, mockEntitlements, mockUsers
+}
+
+func TestTaxiiService_ListCollections(t *testing.T) {
+	t.Run("viewer sees collections of entitled tier", func(t *testing.T) {
+		service, _, mockEntitlements, mockUsers := setupTaxiiService()
+		viewer, _ := domain.NewUser("viewer@example.com", "password123", domain.RoleViewer)
+		mockUsers.On("FindByID", viewer.ID).Return(viewer, nil)
+		mockEntitlements.On("FindByUserID", viewer.ID).Return([]*domain.Entitlement{activeEntitlement(viewer.ID, domain.TierBasic)}, nil)
+
+		collections, err := service.ListCollections(viewer.ID)
+
+		assert.NoError(t, err)
+		assert.Len(t, collections, 1)
+		assert.Equal(t, networkCollectionID, collections[0].ID)
+		assert.True(t, collections[0].CanRead)
+		assert.False(t, collections[0].CanWrite)
+	})
+
+	t.Run("viewer without entitlement sees nothing", func(t *testing.T) {
+		service, _, mockEntitlements, mockUsers := setupTaxiiService()
+		viewer, _ := domain.NewUser("viewer@example.com", "password123", domain.RoleViewer)
+		mockUsers.On("FindByID", viewer.ID).Return(viewer, nil)
+		mockEntitlements.On("FindByUserID", viewer.ID).Return([]*domain.Entitlement{}, nil)
+
+		collections, err := service.ListCollections(viewer.ID)
+
+		assert.NoError(t, err)
+		assert.Empty(t, collections)
+	})
+
+	t.Run("analyst sees every collection", func(t *testing.T) {
+		service, _, mockEntitlements, mockUsers := setupTaxiiService()
+		analyst, _ := domain.NewUser("analyst@example.com", "password123", domain.RoleAnalyst)
+		mockUsers.On("FindByID", analyst.ID).Return(analyst, nil)
+
+		collections, err := service.ListCollections(analyst.ID)
+
+		assert.NoError(t, err)
+		assert.Len(t, collections, 3)
+		mockEntitlements.AssertNotCalled(t, "FindByUserID", mock.Anything)
+	})
+}
+
+func TestTaxiiService_GetCollection(t *testing.T) {
+	service, _, mockEntitlements, mockUsers := setupTaxiiService()
+	viewer, _ := domain.NewUser("viewer@example.com", "password123", domain.RoleViewer)
+	mockUsers.On("FindByID", viewer.ID).Return(viewer, nil)
+	mockEntitlements.On("FindByUserID", viewer.ID).Return([]*domain.Entitlement{activeEntitlement(viewer.ID, domain.TierBasic)}, nil)
+
+	t.Run("returns visible collection", func(t *testing.T) {
+		collection, err := service.GetCollection(viewer.ID, networkCollectionID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Network Indicators", collection.Title)
+	})
+
+	t.Run("hides collection above tier", func(t *testing.T) {
+		_, err := service.GetCollection(viewer.ID, hashCollectionID)
+
+		assert.Equal(t, ErrCollectionNotFound, err)
+	})
+
+	t.Run("unknown collection", func(t *testing.T) {
+		_, err := service.GetCollection(viewer.ID, uuid.NewString())
+
+		assert.Equal(t, ErrCollectionNotFound, err)
+	})
+}
+
+func TestTaxiiService_GetObjects(t *testing.T) {
+	service, mockIndicators, _, mockUsers := setupTaxiiService()
+	analyst, _ := domain.NewUser("analyst@example.com", "password123", domain.RoleAnalyst)
+	mockUsers.On("FindByID", analyst.ID).Return(analyst, nil)
+
+	networkTypes := []domain.IndicatorType{domain.IndicatorIPv4, domain.IndicatorIPv6, domain.IndicatorDomain, domain.IndicatorURL}
+	newIndicator := func(value string, updatedAt time.Time) *domain.Indicator {
+		indicator, _ := domain.NewIndicator(domain.IndicatorIPv4, value, 50, domain.SeverityLow)
+		indicator.UpdatedAt = updatedAt
+		return indicator
+	}
+
+	t.Run("pages with next cursor", func(t *testing.T) {
+		base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		first := newIndicator("10.0.0.1", base)
+		second := newIndicator("10.0.0.2", base.Add(time.Minute))
+		third := newIndicator("10.0.0.3", base.Add(2*time.Minute))
+		mockIndicators.On("ListChanges", domain.IndicatorFilter{
+			Types:          networkTypes,
+			IncludeExpired: true,
+			Limit:          3,
+		}, domain.IndicatorCursor{UpdatedAt: base.Add(-time.Hour)}).Return([]*domain.Indicator{first, second, third}, nil).Once()
+
+		envelope, err := service.GetObjects(analyst.ID, networkCollectionID, TaxiiObjectsRequest{
+			AddedAfter: "2023-12-31T23:00:00Z",
+			Limit:      2,
+		})
+
+		assert.NoError(t, err)
+		assert.True(t, envelope.More)
+		assert.Len(t, envelope.Objects, 2)
+		assert.Equal(t, first.StixID, envelope.Objects[0].ID)
+		assert.Equal(t, first.UpdatedAt, envelope.DateAddedFirst)
+		assert.Equal(t, second.UpdatedAt, envelope.DateAddedLast)
+
+		cursor, err := decodeTaxiiCursor(envelope.Next)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.IndicatorCursor{UpdatedAt: second.UpdatedAt, ID: second.ID}, cursor)
+
+		mockIndicators.On("ListChanges", mock.Anything, cursor).Return([]*domain.Indicator{third}, nil).Once()
+
+		envelope, err = service.GetObjects(analyst.ID, networkCollectionID, TaxiiObjectsRequest{Next: envelope.Next, Limit: 2})
+
+		assert.NoError(t, err)
+		assert.False(t, envelope.More)
+		assert.Empty(t, envelope.Next)
+		assert.Len(t, envelope.Objects, 1)
+	})
+
+	t.Run("caps limit", func(t *testing.T) {
+		mockIndicators.On("ListChanges", domain.IndicatorFilter{
+			Types:          networkTypes,
+			IncludeExpired: true,
+			Limit:          maxTaxiiPageSize + 1,
+		}, domain.IndicatorCursor{}).Return([]*domain.Indicator{}, nil).Once()
+
+		envelope, err := service.GetObjects(analyst.ID, networkCollectionID, TaxiiObjectsRequest{Limit: 5000})
+
+		assert.NoError(t, err)
+		assert.False(t, envelope.More)
+		assert.Empty(t, envelope.Objects)
+	})
+
+	t.Run("rejects malformed added_after", func(t *testing.T) {
+		_, err := service.GetObjects(analyst.ID, networkCollectionID, TaxiiObjectsRequest{AddedAfter: "yesterday"})
+
+		assert.True(t, errors.Is(err, ErrInvalidTaxiiQuery))
+	})
+
+	t.Run("rejects malformed cursor", func(t *testing.T) {
+		_, err := service.GetObjects(analyst.ID, networkCollectionID, TaxiiObjectsRequest{Next: "not-a-cursor"})
+
+		assert.True(t, errors.Is(err, ErrInvalidTaxiiQuery))
+	})
+}
@@ -0,0 +1,285 @@
+package application
+
+import (
+	"errors"
+	"threat-intel-backend/domain"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUserNotFound            = errors.New("user not found")
+	ErrInsufficientPermissions = errors.New("insufficient permissions")
+	ErrInvalidRole             = errors.New("invalid role")
+	ErrSelfModification        = errors.New("admins cannot modify their own account")
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// SessionRevoker ends every session of a user. AuthService implements it.
+type SessionRevoker interface {
+	LogoutAll(userID uuid.UUID) error
+}
+
+// AccountUnlocker lifts login lockouts. LoginGuard implements it.
+type AccountUnlocker interface {
+	Unlock(actorID uuid.UUID, user *domain.User) error
+}
+
+type UserService struct {
+	userRepo   domain.UserRepository
+	sessions   SessionRevoker
+	unlocker   AccountUnlocker
+	transactor domain.Transactor
+}
+
+type ListUsersRequest struct {
+	Role        domain.UserRole `form:"role"`
+	IsActive    *bool           `form:"is_active"`
+	EmailPrefix string          `form:"email_prefix"`
+	Page        int             `form:"page" binding:"omitempty,min=1"`
+	PageSize    int             `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+type UserListResponse struct {
+	Users    []*UserResponse `json:"users"`
+	Total    int64           `json:"total"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"page_size"`
+}
+
+type UpdateRoleRequest struct {
+	Role domain.UserRole `json:"role" binding:"required"`
+}
+
+func NewUserService(userRepo domain.UserRepository, sessions SessionRevoker, unlocker AccountUnlocker, transactor domain.Transactor) *UserService {
+	return &UserService{
+		userRepo:   userRepo,
+		sessions:   sessions,
+		unlocker:   unlocker,
+		transactor: transactor,
+	}
+}
+
+func (s *UserService) ListUsers(actorID uuid.UUID, req ListUsersRequest) (*UserListResponse, error) {
+	if err := s.requireAdmin(actorID); err != nil {
+		return nil, err
+	}
+
+	if req.Role != "" && !req.Role.IsValid() {
+		return nil, ErrInvalidRole
+	}
+
+	page, pageSize := normalizePage(req.Page, req.PageSize)
+
+	users, total, err := s.userRepo.List(domain.UserFilter{
+		Role:        req.Role,
+		IsActive:    req.IsActive,
+		EmailPrefix: req.EmailPrefix,
+		Offset:      (page - 1) * pageSize,
+		Limit:       pageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*UserResponse, len(users))
+	for i, user := range users {
+		responses[i] = NewUserResponse(user)
+	}
+
+	return &UserListResponse{
+		Users:    responses,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
+
+func (s *UserService) GetUser(actorID, userID uuid.UUID) (*UserResponse, error) {
+	if err := s.requireAdmin(actorID); err != nil {
+		return nil, err
+	}
+
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewUserResponse(user), nil
+}
+
+func (s *UserService) UpdateRole(actorID, userID uuid.UUID, req UpdateRoleRequest) (*UserResponse, error) {
+	if err := s.requireAdmin(actorID); err != nil {
+		return nil, err
+	}
+
+	if !req.Role.IsValid() {
+		return nil, ErrInvalidRole
+	}
+
+	if actorID == userID {
+		return nil, ErrSelfModification
+	}
+
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Role == req.Role {
+		return NewUserResponse(user), nil
+	}
+
+	user.ChangeRole(req.Role)
+	if err := s.userRepo.Save(user); err != nil {
+		return nil, err
+	}
+
+	// Access tokens carry the role, so force the user to pick up the new one.
+	if err := s.sessions.LogoutAll(user.ID); err != nil {
+		return nil, err
+	}
+
+	return NewUserResponse(user), nil
+}
+
+func (s *UserService) SetActive(actorID, userID uuid.UUID, active bool) (*UserResponse, error) {
+	if err := s.requireAdmin(actorID); err != nil {
+		return nil, err
+	}
+
+	if actorID == userID {
+		return nil, ErrSelfModification
+	}
+
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsActive == active {
+		return NewUserResponse(user), nil
+	}
+
+	if active {
+		user.Activate()
+	} else {
+		user.Deactivate()
+	}
+
+	if err := s.userRepo.Save(user); err != nil {
+		return nil, err
+	}
+
+	if !active {
+		if err := s.sessions.LogoutAll(user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return NewUserResponse(user), nil
+}
+
+// UnlockUser clears the failed logins and lockout of a user's email.
+func (s *UserService) UnlockUser(actorID, userID uuid.UUID) (*UserResponse, error) {
+	if err := s.requireAdmin(actorID); err != nil {
+		return nil, err
+	}
+
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.unlocker.Unlock(actorID, user); err != nil {
+		return nil, err
+	}
+
+	return NewUserResponse(user), nil
+}
+
+// DeleteUser removes a user and ends their sessions. Users who have orders
+// cannot be deleted and fail with domain.ErrUserHasOrders; deactivate them
+// instead.
+func (s *UserService) DeleteUser(actorID, userID uuid.UUID) error {
+	if err := s.requireAdmin(actorID); err != nil {
+		return err
+	}
+
+	if actorID == userID {
+		return ErrSelfModification
+	}
+
+	if _, err := s.findUser(userID); err != nil {
+		return err
+	}
+
+	// The user's credentials, SSO links and entitlement seats go with the
+	// account, so that none of them can outlive it or point at a missing user.
+	err := s.transactor.Transaction(func(repos domain.Repositories) error {
+		if err := repos.MFAFactors.Delete(userID); err != nil {
+			return err
+		}
+		if err := repos.Entitlements.DeleteSeatsByUserID(userID); err != nil {
+			return err
+		}
+		if err := repos.APIKeys.DeleteByUserID(userID); err != nil {
+			return err
+		}
+		if err := repos.ExternalIdentities.DeleteByUserID(userID); err != nil {
+			return err
+		}
+		return repos.Users.Delete(userID)
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.sessions.LogoutAll(userID)
+}
+
+func (s *UserService) requireAdmin(actorID uuid.UUID) error {
+	return requireRole(s.userRepo, actorID, domain.RoleAdmin)
+}
+
+func (s *UserService) findUser(userID uuid.UUID) (*domain.User, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// normalizePage applies the default and maximum page sizes shared by the
+// listing endpoints.
+func normalizePage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	return page, pageSize
+}
+
+// requireRole applies the same hierarchy as the HTTP RequireRole middleware,
+// but against the stored user so that deactivations and role changes take
+// effect before the caller's access token expires.
+func requireRole(userRepo domain.UserRepository, actorID uuid.UUID, role domain.UserRole) error {
+	actor, err := userRepo.FindByID(actorID)
+	if err != nil {
+		return ErrInsufficientPermissions
+	}
+
+	if !actor.IsActive || !actor.HasPermission(role) {
+		return ErrInsufficientPermissions
+	}
+
+	return nil
+}
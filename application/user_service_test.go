@@ -0,0 +1,314 @@
+package application
+
+import (
+	"errors"
+	"testing"
+	"threat-intel-backend/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSessionRevoker struct {
+	mock.Mock
+}
+
+func (m *MockSessionRevoker) LogoutAll(userID uuid.UUID) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+type MockAccountUnlocker struct {
+	mock.Mock
+}
+
+func (m *MockAccountUnlocker) Unlock(actorID uuid.UUID, user *domain.User) error {
+	args := m.Called(actorID, user)
+	return args.Error(0)
+}
+
+func setupUserService() (*UserService, *MockUserRepository, *MockSessionRevoker, *domain.User) {
+	mockRepo := new(MockUserRepository)
+	mockSessions := new(MockSessionRevoker)
+	admin, _ := domain.NewUser("admin@example.com", "password123", domain.RoleAdmin)
+	mockRepo.On("FindByID", admin.ID).Return(admin, nil)
+
+	transactor := &fakeTransactor{repos: domain.Repositories{Users: mockRepo}}
+	return NewUserService(mockRepo, mockSessions, new(MockAccountUnlocker), transactor), mockRepo, mockSessions, admin
+}
+
+func TestUserService_ListUsers(t *testing.T) {
+	service, mockRepo, _, admin := setupUserService()
+
+	t.Run("applies filter and pagination", func(t *testing.T) {
+		active := true
+		users := []*domain.User{{ID: uuid.New(), Email: "a@example.com"}}
+		mockRepo.On("List", domain.UserFilter{
+			Role:        domain.RoleAnalyst,
+			IsActive:    &active,
+			EmailPrefix: "a",
+			Offset:      10,
+			Limit:       10,
+		}).Return(users, int64(11), nil).Once()
+
+		resp, err := service.ListUsers(admin.ID, ListUsersRequest{
+			Role:        domain.RoleAnalyst,
+			IsActive:    &active,
+			EmailPrefix: "a",
+			Page:        2,
+			PageSize:    10,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*UserResponse{NewUserResponse(users[0])}, resp.Users)
+		assert.Equal(t, int64(11), resp.Total)
+		assert.Equal(t, 2, resp.Page)
+		assert.Equal(t, 10, resp.PageSize)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("uses default page size", func(t *testing.T) {
+		mockRepo.On("List", domain.UserFilter{Limit: defaultPageSize}).Return([]*domain.User{}, int64(0), nil).Once()
+
+		resp, err := service.ListUsers(admin.ID, ListUsersRequest{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, resp.Page)
+		assert.Equal(t, defaultPageSize, resp.PageSize)
+	})
+
+	t.Run("rejects unknown role filter", func(t *testing.T) {
+		resp, err := service.ListUsers(admin.ID, ListUsersRequest{Role: "root"})
+
+		assert.ErrorIs(t, err, ErrInvalidRole)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("rejects non-admin actor", func(t *testing.T) {
+		analyst, _ := domain.NewUser("analyst@example.com", "password123", domain.RoleAnalyst)
+		mockRepo.On("FindByID", analyst.ID).Return(analyst, nil).Once()
+
+		resp, err := service.ListUsers(analyst.ID, ListUsersRequest{})
+
+		assert.ErrorIs(t, err, ErrInsufficientPermissions)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("rejects inactive admin", func(t *testing.T) {
+		inactive, _ := domain.NewUser("old-admin@example.com", "password123", domain.RoleAdmin)
+		inactive.IsActive = false
+		mockRepo.On("FindByID", inactive.ID).Return(inactive, nil).Once()
+
+		_, err := service.ListUsers(inactive.ID, ListUsersRequest{})
+
+		assert.ErrorIs(t, err, ErrInsufficientPermissions)
+	})
+}
+
+func TestUserService_GetUser(t *testing.T) {
+	service, mockRepo, _, admin := setupUserService()
+
+	t.Run("returns user", func(t *testing.T) {
+		user, _ := domain.NewUser("user@example.com", "password123", domain.RoleViewer)
+		mockRepo.On("FindByID", user.ID).Return(user, nil).Once()
+
+		result, err := service.GetUser(admin.ID, user.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, NewUserResponse(user), result)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		userID := uuid.New()
+		mockRepo.On("FindByID", userID).Return(nil, errors.New("record not found")).Once()
+
+		result, err := service.GetUser(admin.ID, userID)
+
+		assert.ErrorIs(t, err, ErrUserNotFound)
+		assert.Nil(t, result)
+	})
+}
+
+func TestUserService_UpdateRole(t *testing.T) {
+	service, mockRepo, mockSessions, admin := setupUserService()
+
+	t.Run("changes role and revokes sessions", func(t *testing.T) {
+		user, _ := domain.NewUser("user@example.com", "password123", domain.RoleViewer)
+		mockRepo.On("FindByID", user.ID).Return(user, nil).Once()
+		mockRepo.On("Save", user).Return(nil).Once()
+		mockSessions.On("LogoutAll", user.ID).Return(nil).Once()
+
+		result, err := service.UpdateRole(admin.ID, user.ID, UpdateRoleRequest{Role: domain.RoleAnalyst})
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.RoleAnalyst, result.Role)
+		mockRepo.AssertExpectations(t)
+		mockSessions.AssertExpectations(t)
+	})
+
+	t.Run("unchanged role is a no-op", func(t *testing.T) {
+		user, _ := domain.NewUser("same@example.com", "password123", domain.RoleViewer)
+		mockRepo.On("FindByID", user.ID).Return(user, nil).Once()
+
+		result, err := service.UpdateRole(admin.ID, user.ID, UpdateRoleRequest{Role: domain.RoleViewer})
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.RoleViewer, result.Role)
+	})
+
+	t.Run("rejects invalid role", func(t *testing.T) {
+		_, err := service.UpdateRole(admin.ID, uuid.New(), UpdateRoleRequest{Role: "root"})
+
+		assert.ErrorIs(t, err, ErrInvalidRole)
+	})
+
+	t.Run("rejects self modification", func(t *testing.T) {
+		_, err := service.UpdateRole(admin.ID, admin.ID, UpdateRoleRequest{Role: domain.RoleViewer})
+
+		assert.ErrorIs(t, err, ErrSelfModification)
+	})
+}
+
+func TestUserService_SetActive(t *testing.T) {
+	service, mockRepo, mockSessions, admin := setupUserService()
+
+	t.Run("deactivates user and revokes sessions", func(t *testing.T) {
+		user, _ := domain.NewUser("user@example.com", "password123", domain.RoleViewer)
+		mockRepo.On("FindByID", user.ID).Return(user, nil).Once()
+		mockRepo.On("Save", user).Return(nil).Once()
+		mockSessions.On("LogoutAll", user.ID).Return(nil).Once()
+
+		result, err := service.SetActive(admin.ID, user.ID, false)
+
+		assert.NoError(t, err)
+		assert.False(t, result.IsActive)
+		mockSessions.AssertExpectations(t)
+	})
+
+	t.Run("reactivates user", func(t *testing.T) {
+		user, _ := domain.NewUser("inactive@example.com", "password123", domain.RoleViewer)
+		user.IsActive = false
+		mockRepo.On("FindByID", user.ID).Return(user, nil).Once()
+		mockRepo.On("Save", user).Return(nil).Once()
+
+		result, err := service.SetActive(admin.ID, user.ID, true)
+
+		assert.NoError(t, err)
+		assert.True(t, result.IsActive)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects self deactivation", func(t *testing.T) {
+		_, err := service.SetActive(admin.ID, admin.ID, false)
+
+		assert.ErrorIs(t, err, ErrSelfModification)
+	})
+}
+
+func TestUserService_UnlockUser(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	mockUnlocker := new(MockAccountUnlocker)
+	service := NewUserService(mockRepo, new(MockSessionRevoker), mockUnlocker, &fakeTransactor{})
+	admin, _ := domain.NewUser("admin@example.com", "password123", domain.RoleAdmin)
+	viewer, _ := domain.NewUser("viewer@example.com", "password123", domain.RoleViewer)
+	mockRepo.On("FindByID", admin.ID).Return(admin, nil)
+	mockRepo.On("FindByID", viewer.ID).Return(viewer, nil)
+
+	t.Run("unlocks user", func(t *testing.T) {
+		mockUnlocker.On("Unlock", admin.ID, viewer).Return(nil).Once()
+
+		result, err := service.UnlockUser(admin.ID, viewer.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, NewUserResponse(viewer), result)
+		mockUnlocker.AssertExpectations(t)
+	})
+
+	t.Run("requires admin", func(t *testing.T) {
+		_, err := service.UnlockUser(viewer.ID, admin.ID)
+
+		assert.Equal(t, ErrInsufficientPermissions, err)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		id := uuid.New()
+		mockRepo.On("FindByID", id).Return(nil, errors.New("record not found")).Once()
+
+		_, err := service.UnlockUser(admin.ID, id)
+
+		assert.Equal(t, ErrUserNotFound, err)
+	})
+}
+
+func TestUserService_DeleteUser(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	mockSessions := new(MockSessionRevoker)
+	mockFactors := new(MockMFAFactorRepository)
+	mockKeys := new(MockAPIKeyRepository)
+	mockIdentities := new(MockExternalIdentityRepository)
+	mockEntitlements := new(MockEntitlementRepository)
+	transactor := &fakeTransactor{repos: domain.Repositories{
+		Users:              mockRepo,
+		MFAFactors:         mockFactors,
+		APIKeys:            mockKeys,
+		ExternalIdentities: mockIdentities,
+		Entitlements:       mockEntitlements,
+	}}
+	service := NewUserService(mockRepo, mockSessions, new(MockAccountUnlocker), transactor)
+	admin, _ := domain.NewUser("admin@example.com", "password123", domain.RoleAdmin)
+	mockRepo.On("FindByID", admin.ID).Return(admin, nil)
+
+	t.Run("deletes user with credentials, SSO links and seats", func(t *testing.T) {
+		user, _ := domain.NewUser("user@example.com", "password123", domain.RoleViewer)
+		mockRepo.On("FindByID", user.ID).Return(user, nil).Once()
+		mockFactors.On("Delete", user.ID).Return(nil).Once()
+		mockEntitlements.On("DeleteSeatsByUserID", user.ID).Return(nil).Once()
+		mockKeys.On("DeleteByUserID", user.ID).Return(nil).Once()
+		mockIdentities.On("DeleteByUserID", user.ID).Return(nil).Once()
+		mockRepo.On("Delete", user.ID).Return(nil).Once()
+		mockSessions.On("LogoutAll", user.ID).Return(nil).Once()
+
+		err := service.DeleteUser(admin.ID, user.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, transactor.commits)
+		mockRepo.AssertExpectations(t)
+		mockFactors.AssertExpectations(t)
+		mockKeys.AssertExpectations(t)
+		mockIdentities.AssertExpectations(t)
+		mockEntitlements.AssertExpectations(t)
+		mockSessions.AssertExpectations(t)
+	})
+
+	t.Run("user with orders is kept and stays signed in", func(t *testing.T) {
+		user, _ := domain.NewUser("buyer@example.com", "password123", domain.RoleViewer)
+		mockRepo.On("FindByID", user.ID).Return(user, nil).Once()
+		mockFactors.On("Delete", user.ID).Return(nil).Once()
+		mockEntitlements.On("DeleteSeatsByUserID", user.ID).Return(nil).Once()
+		mockKeys.On("DeleteByUserID", user.ID).Return(nil).Once()
+		mockIdentities.On("DeleteByUserID", user.ID).Return(nil).Once()
+		mockRepo.On("Delete", user.ID).Return(domain.ErrUserHasOrders).Once()
+
+		err := service.DeleteUser(admin.ID, user.ID)
+
+		assert.ErrorIs(t, err, domain.ErrUserHasOrders)
+		assert.Equal(t, 1, transactor.rollbacks)
+		mockSessions.AssertNotCalled(t, "LogoutAll", user.ID)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		userID := uuid.New()
+		mockRepo.On("FindByID", userID).Return(nil, errors.New("record not found")).Once()
+
+		err := service.DeleteUser(admin.ID, userID)
+
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+
+	t.Run("rejects self deletion", func(t *testing.T) {
+		err := service.DeleteUser(admin.ID, admin.ID)
+
+		assert.ErrorIs(t, err, ErrSelfModification)
+	})
+}
@@ -4,20 +4,29 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"sync"
 	"syscall"
 	"time"
 
 	"threat-intel-backend/application"
 	"threat-intel-backend/configs"
+	"threat-intel-backend/domain"
+	"threat-intel-backend/infrastructure/feeds"
 	"threat-intel-backend/infrastructure/jwt"
+	"threat-intel-backend/infrastructure/mail"
 	"threat-intel-backend/infrastructure/newrelic"
+	"threat-intel-backend/infrastructure/oidc"
+	"threat-intel-backend/infrastructure/password"
 	"threat-intel-backend/infrastructure/postgres"
 	"threat-intel-backend/infrastructure/redis"
 	httpInterface "threat-intel-backend/interfaces/http"
 	newrelicAgent "github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/sirupsen/logrus"
 )
 
 // @title Zentara Threat Intelligence API
@@ -87,16 +96,114 @@ func main() {
 	// Initialize repositories
 	userRepo := postgres.NewUserRepository(db)
 	orderRepo := postgres.NewOrderRepository(db)
+	indicatorRepo := postgres.NewIndicatorRepository(db)
+	threatEntityRepo := postgres.NewThreatEntityRepository(db)
+	relationshipRepo := postgres.NewRelationshipRepository(db)
+	entitlementRepo := postgres.NewEntitlementRepository(db)
+	productRepo := postgres.NewProductRepository(db)
+	securityEventRepo := postgres.NewSecurityEventRepository(db)
+	mfaFactorRepo := postgres.NewMFAFactorRepository(db)
+	mfaPolicyRepo := postgres.NewMFAPolicyRepository(db)
+	apiKeyRepo := postgres.NewAPIKeyRepository(db)
+	externalIdentityRepo := postgres.NewExternalIdentityRepository(db)
+	feedRepo := postgres.NewFeedRepository(db)
+	feedRunRepo := postgres.NewFeedRunRepository(db)
+	sightingRepo := postgres.NewSightingRepository(db)
+	indicatorVoteRepo := postgres.NewIndicatorVoteRepository(db)
+	transactor := postgres.NewTransactor(db)
+
+	refreshTokenStore := redis.NewRefreshTokenStore(redisClient)
+	accessTokenDenylist := redis.NewAccessTokenDenylist(redisClient)
+	oneTimeTokenStore := redis.NewOneTimeTokenStore(redisClient)
+	rateLimiter := redis.NewRateLimiter(redisClient)
+	loginAttemptStore := redis.NewLoginAttemptStore(redisClient)
+	ssoStateStore := redis.NewSSOStateStore(redisClient)
+	accountTokenStore := redis.NewAccountTokenStore(redisClient)
+	feedLock := redis.NewLock(redisClient, "feeds", config.Feeds.LockTTL)
+	scoringLock := redis.NewLock(redisClient, "scoring", config.Scoring.LockTTL)
+	lookupCache := redis.NewLookupCache(redisClient, config.Lookup.CacheTTL)
 
 	// Initialize services
-	jwtService := jwt.NewService(config.JWT.SecretKey)
-	authService := application.NewAuthService(userRepo, jwtService)
-	orderService := application.NewOrderService(orderRepo, userRepo)
+	if config.JWT.SecretKey == configs.DefaultJWTSecret {
+		logger.Warn("JWT_SECRET is the development default; set it before deploying")
+	}
+	jwtService := jwt.NewService(config.JWT.SecretKey).
+		WithValidation(config.JWT.Issuer, config.JWT.Audience, config.JWT.Leeway)
+	if config.JWT.SigningKeysDir != "" {
+		keys, err := jwt.LoadKeySet(config.JWT.SigningKeysDir, config.JWT.SigningKeyID)
+		if err != nil {
+			log.Fatal("Failed to load JWT signing keys:", err)
+		}
+		jwtService.WithKeySet(keys).WithHS256Window(config.JWT.HS256Window)
+		logger.WithField("kid", keys.Signing().ID).Info("Signing tokens with " + keys.Signing().Method.Alg())
+		if config.JWT.HS256Window > 0 {
+			logger.WithField("window", config.JWT.HS256Window).Info("Still accepting HS256 tokens")
+		}
+	}
+	passwordPolicy := newPasswordPolicy(config.Password, logger)
+	loginGuard := application.NewLoginGuard(loginAttemptStore, securityEventRepo, application.DefaultLoginPolicy)
+	mfaService := application.NewMFAService(mfaFactorRepo, mfaPolicyRepo, userRepo, config.MFA.Issuer)
+	authService := application.NewAuthService(userRepo, jwtService, refreshTokenStore, accessTokenDenylist, loginGuard, mfaService)
+	orderService := application.NewOrderService(orderRepo, userRepo, entitlementRepo, productRepo, transactor)
+	userService := application.NewUserService(userRepo, authService, loginGuard, transactor)
+	invitationService := application.NewInvitationService(userRepo, jwtService, oneTimeTokenStore, authService, passwordPolicy)
+	indicatorService := application.NewIndicatorService(indicatorRepo, sightingRepo, userRepo, entitlementRepo, lookupCache)
+	stixService := application.NewStixService(indicatorRepo, threatEntityRepo, relationshipRepo, userRepo, entitlementRepo, transactor, lookupCache)
+	taxiiService := application.NewTaxiiService(indicatorRepo, userRepo, entitlementRepo)
+	entitlementService := application.NewEntitlementService(entitlementRepo, userRepo, transactor)
+	productService := application.NewProductService(productRepo, userRepo)
+	securityEventService := application.NewSecurityEventService(securityEventRepo, userRepo)
+	apiKeyService := application.NewAPIKeyService(apiKeyRepo, userRepo)
+	accountService := application.NewAccountService(userRepo, jwtService, accountTokenStore, newMailer(config.Mail, logger), authService, loginGuard, passwordPolicy, config.Mail.AppURL)
+	feedService := application.NewFeedService(feedRepo, feedRunRepo, indicatorRepo, sightingRepo, userRepo, newFeedSources(config.Feeds), feedLock, lookupCache)
+	scoringService := application.NewScoringService(indicatorRepo, sightingRepo, indicatorVoteRepo, userRepo, newScoringPolicy(config.Scoring), scoringLock, lookupCache)
+	lookupService := application.NewLookupService(indicatorRepo, sightingRepo, userRepo, entitlementRepo, lookupCache, config.Lookup.MaxObservables)
+	var ssoService *application.SSOService
+	if config.OIDC.IssuerURL != "" {
+		ssoService = newSSOService(config.OIDC, ssoStateStore, externalIdentityRepo, userRepo, authService)
+		logger.WithField("issuer", config.OIDC.IssuerURL).Info("Single sign-on enabled")
+	}
 
 	// Initialize HTTP layer
-	middleware := httpInterface.NewMiddleware(jwtService, logger)
-	handler := httpInterface.NewHandler(authService, orderService, logger)
-	router := httpInterface.NewRouter(handler, middleware)
+	limit := func(n int) domain.RateLimit {
+		return domain.RateLimit{Limit: n, Period: config.RateLimit.Period}
+	}
+	roleLimits := func(limits configs.RoleRateLimits) map[domain.UserRole]domain.RateLimit {
+		return map[domain.UserRole]domain.RateLimit{
+			domain.RoleViewer:  limit(limits.Viewer),
+			domain.RoleAnalyst: limit(limits.Analyst),
+			domain.RoleAdmin:   limit(limits.Admin),
+		}
+	}
+	middleware := httpInterface.NewMiddleware(jwtService, accessTokenDenylist, logger).
+		WithRateLimiter(rateLimiter, map[string]httpInterface.RateLimitPolicy{
+			httpInterface.RateLimitAuth:   {Anonymous: limit(config.RateLimit.Auth)},
+			httpInterface.RateLimitPublic: {Anonymous: limit(config.RateLimit.Public)},
+			httpInterface.RateLimitAPI:    {Roles: roleLimits(config.RateLimit.API)},
+			httpInterface.RateLimitTAXII:  {Roles: roleLimits(config.RateLimit.TAXII)},
+		}).
+		WithAPIKeys(apiKeyService)
+	handler := httpInterface.NewHandler(authService, orderService, logger).
+		WithUserService(userService).
+		WithInvitationService(invitationService).
+		WithIndicatorService(indicatorService).
+		WithStixService(stixService).
+		WithTaxiiService(taxiiService).
+		WithEntitlementService(entitlementService).
+		WithProductService(productService).
+		WithSecurityEventService(securityEventService).
+		WithMFAService(mfaService).
+		WithAPIKeyService(apiKeyService).
+		WithAccountService(accountService).
+		WithFeedService(feedService).
+		WithScoringService(scoringService).
+		WithLookupService(lookupService).
+		WithJWKS(jwtService)
+	if ssoService != nil {
+		handler.WithSSOService(ssoService)
+	}
+	router := httpInterface.NewRouter(handler, middleware).
+		WithTrustedProxies(trustedProxies(config.Server.TrustedProxies))
 
 	// Setup router with New Relic
 	var app *newrelicAgent.Application
@@ -119,11 +226,33 @@ func main() {
 		}
 	}()
 
+	// Start feed and scoring schedulers
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	var schedulers sync.WaitGroup
+	if config.Feeds.Enabled {
+		schedulers.Add(1)
+		go func() {
+			defer schedulers.Done()
+			runScheduler(schedulerCtx, "Feed", config.Feeds.PollInterval, feedService.RunDue, logger)
+		}()
+	}
+	if config.Scoring.Enabled {
+		schedulers.Add(1)
+		go func() {
+			defer schedulers.Done()
+			runScheduler(schedulerCtx, "Scoring", config.Scoring.Interval, func(ctx context.Context) error {
+				return recomputeScores(ctx, scoringService, logger)
+			}, logger)
+		}()
+	}
+
 	// Wait for interrupt signal to gracefully shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	logger.Info("Shutting down server...")
+	stopScheduler()
+	schedulers.Wait()
 
 	// Shutdown with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -142,4 +271,172 @@ func main() {
 	}
 
 	logger.Info("Server exited")
-}
\ No newline at end of file
+}
+
+// newPasswordPolicy sets how passwords are hashed and returns the policy for
+// new passwords. Invalid hashing parameters and an unreadable breached
+// password list are fatal.
+func newPasswordPolicy(config configs.PasswordConfig, logger *logrus.Logger) application.PasswordPolicy {
+	err := domain.UsePasswordHashing(domain.PasswordHashing{
+		Algorithm:     config.Hash,
+		BcryptCost:    config.BcryptCost,
+		Argon2Time:    uint32(config.Argon2Time),
+		Argon2Memory:  uint32(config.Argon2Memory),
+		Argon2Threads: uint8(config.Argon2Threads),
+	})
+	if err != nil {
+		log.Fatal("Failed to configure password hashing:", err)
+	}
+
+	breached := password.CommonPasswords()
+	if config.BreachedListFile != "" {
+		if err := breached.LoadFile(config.BreachedListFile); err != nil {
+			log.Fatal("Failed to load breached password list:", err)
+		}
+		logger.WithField("entries", breached.Len()).Info("Breached password list loaded")
+	}
+
+	return application.PasswordPolicy{
+		MinLength:      config.MinLength,
+		MaxLength:      config.MaxLength,
+		MinCharClasses: config.MinCharClasses,
+		Breached:       breached,
+	}
+}
+
+// newMailer returns the mailer of the configured driver. Unknown drivers are
+// fatal rather than silently dropping account emails.
+func newMailer(config configs.MailConfig, logger *logrus.Logger) domain.Mailer {
+	switch config.Driver {
+	case "smtp":
+		return mail.NewSMTPMailer(config.SMTPHost, config.SMTPPort, config.SMTPUsername, config.SMTPPassword, config.From)
+	case "file":
+		return mail.NewFileMailer(config.Dir, config.From)
+	case "log":
+		logger.Warn("MAIL_DRIVER is log; account emails are only written to the log")
+		return mail.NewLogMailer(logger)
+	default:
+		log.Fatalf("Unknown MAIL_DRIVER %q", config.Driver)
+		return nil
+	}
+}
+
+// newFeedSources returns the source of every feed format.
+func newFeedSources(config configs.FeedsConfig) map[domain.FeedFormat]domain.FeedSource {
+	client := &http.Client{Timeout: config.FetchTimeout}
+	return map[domain.FeedFormat]domain.FeedSource{
+		domain.FeedPlainText: feeds.NewPlainTextSource(client),
+		domain.FeedCSV:       feeds.NewCSVSource(client),
+		domain.FeedJSON:      feeds.NewJSONSource(client),
+		domain.FeedSTIX:      feeds.NewSTIXSource(client),
+		domain.FeedMISP:      feeds.NewMISPSource(client),
+	}
+}
+
+// trustedProxies checks that every trusted proxy is an IP address or a CIDR
+// range. An invalid one is fatal rather than silently trusting none.
+func trustedProxies(proxies []string) []string {
+	for _, proxy := range proxies {
+		if net.ParseIP(proxy) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(proxy); err != nil {
+			log.Fatalf("Invalid trusted proxy %q", proxy)
+		}
+	}
+	return proxies
+}
+
+// newScoringPolicy builds the scorer from the configured reputations and
+// weights. Invalid ones are fatal.
+func newScoringPolicy(config configs.ScoringConfig) application.ScoringPolicy {
+	reputation := make(map[string]int, len(config.SourceReputation))
+	for source, value := range config.SourceReputation {
+		r, err := strconv.Atoi(value)
+		if err != nil {
+			log.Fatalf("Invalid reputation %q for source %q", value, source)
+		}
+		reputation[source] = r
+	}
+
+	weights := make(map[domain.IndicatorType]domain.ScoreWeights, len(config.Weights))
+	for indicatorType, value := range config.Weights {
+		w, err := domain.ParseScoreWeights(value)
+		if err != nil {
+			log.Fatalf("Invalid score weights for %s: %v", indicatorType, err)
+		}
+		weights[domain.IndicatorType(indicatorType)] = w
+	}
+
+	scorer, err := domain.NewScorer(weights, reputation, config.DefaultReputation)
+	if err != nil {
+		log.Fatal("Failed to configure scoring:", err)
+	}
+	return application.ScoringPolicy{Scorer: scorer, ExpireBelow: config.ExpireBelow}
+}
+
+// recomputeScores runs the scoring job and logs what it did, unless another
+// replica ran it.
+func recomputeScores(ctx context.Context, scoringService *application.ScoringService, logger *logrus.Logger) error {
+	run, err := scoringService.RecomputeScores(ctx)
+	if run != nil {
+		logger.WithFields(logrus.Fields{
+			"scored":  run.Scored,
+			"expired": run.Expired,
+		}).Info("Indicator scores recomputed")
+	}
+	return err
+}
+
+// runScheduler calls run every interval until ctx is done. A run in progress
+// is cancelled with it.
+func runScheduler(ctx context.Context, name string, interval time.Duration, run func(context.Context) error, logger *logrus.Logger) {
+	logger.WithField("interval", interval).Info(name + " scheduler started")
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := run(ctx); err != nil && ctx.Err() == nil {
+			logger.WithError(err).Error(name + " scheduler run failed")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// newSSOService discovers the OpenID Connect provider and checks the role
+// mapping. Both are fatal, as a half-configured SSO is a misconfiguration.
+func newSSOService(config configs.OIDCConfig, states domain.SSOStateStore, identityRepo domain.ExternalIdentityRepository, userRepo domain.UserRepository, sessions application.SessionStarter) *application.SSOService {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	provider, err := oidc.Discover(ctx, oidc.Config{
+		IssuerURL:    config.IssuerURL,
+		ClientID:     config.ClientID,
+		ClientSecret: config.ClientSecret,
+		RedirectURL:  config.RedirectURL,
+		GroupsClaim:  config.GroupsClaim,
+	}, &http.Client{Timeout: 10 * time.Second})
+	if err != nil {
+		log.Fatal("Failed to discover OIDC provider:", err)
+	}
+
+	roles := application.SSORoleMapping{
+		GroupRoles:  make(map[string]domain.UserRole, len(config.GroupRoles)),
+		DefaultRole: domain.UserRole(config.DefaultRole),
+	}
+	for group, role := range config.GroupRoles {
+		if !domain.UserRole(role).IsValid() {
+			log.Fatalf("Invalid role %q for OIDC group %q", role, group)
+		}
+		roles.GroupRoles[group] = domain.UserRole(role)
+	}
+	if roles.DefaultRole != "" && !roles.DefaultRole.IsValid() {
+		log.Fatalf("Invalid OIDC default role %q", roles.DefaultRole)
+	}
+
+	return application.NewSSOService(provider, states, identityRepo, userRepo, sessions, roles)
+}
@@ -3,19 +3,36 @@ package configs
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
+// DefaultJWTSecret is only meant for local development.
+const DefaultJWTSecret = "your-secret-key-change-in-production"
+
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	JWT      JWTConfig
-	NewRelic NewRelicConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	Redis     RedisConfig
+	JWT       JWTConfig
+	NewRelic  NewRelicConfig
+	RateLimit RateLimitConfig
+	MFA       MFAConfig
+	OIDC      OIDCConfig
+	Mail      MailConfig
+	Password  PasswordConfig
+	Feeds     FeedsConfig
+	Scoring   ScoringConfig
+	Lookup    LookupConfig
 }
 
+// ServerConfig is where the API listens. TrustedProxies lists the addresses
+// or CIDR ranges of the reverse proxies allowed to set the client IP through
+// X-Forwarded-For; by default none are, and the client IP is the peer address.
 type ServerConfig struct {
-	Port string
-	Host string
+	Port           string
+	Host           string
+	TrustedProxies []string
 }
 
 type DatabaseConfig struct {
@@ -33,8 +50,127 @@ type RedisConfig struct {
 	DB       int
 }
 
+// JWTConfig selects how access and refresh tokens are signed. With an empty
+// SigningKeysDir they are signed with SecretKey using HS256; otherwise every
+// *.pem file of the directory is a key named by its file name, and
+// SigningKeyID names the one that signs. SecretKey always signs invite and
+// MFA tokens.
+// Issuer and Audience are the iss and aud claims tokens are issued with and
+// must carry; Leeway is the clock skew tolerated when checking their times.
 type JWTConfig struct {
-	SecretKey string
+	SecretKey      string
+	SigningKeysDir string
+	SigningKeyID   string
+	// HS256Window is how long after startup tokens signed with SecretKey are
+	// still accepted once a key set signs, so that sessions survive the switch.
+	HS256Window time.Duration
+	Issuer      string
+	Audience    string
+	Leeway      time.Duration
+}
+
+// RateLimitConfig holds the request budgets per Period. Auth and Public are
+// counted per client IP; API and TAXII are counted per user by role. A limit
+// of 0 disables limiting.
+type RateLimitConfig struct {
+	Period time.Duration
+	Auth   int
+	Public int
+	API    RoleRateLimits
+	TAXII  RoleRateLimits
+}
+
+type RoleRateLimits struct {
+	Viewer  int
+	Analyst int
+	Admin   int
+}
+
+// MFAConfig holds the issuer that authenticator apps show next to the
+// account of an enrolled user.
+type MFAConfig struct {
+	Issuer string
+}
+
+// OIDCConfig enables single sign-on with an OpenID Connect provider. It is
+// disabled while IssuerURL is empty. GroupRoles maps the groups of the
+// GroupsClaim to roles; users in none of them get DefaultRole, or are refused
+// when it is empty.
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	GroupsClaim  string
+	GroupRoles   map[string]string
+	DefaultRole  string
+}
+
+// MailConfig selects how account emails are delivered: "smtp" through
+// SMTPHost, "file" as .eml files in Dir, or "log" to the application log.
+// AppURL is the web application their links point to.
+type MailConfig struct {
+	Driver       string
+	From         string
+	Dir          string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	AppURL       string
+}
+
+// PasswordConfig holds the policy new passwords must satisfy and how they
+// are hashed. BreachedListFile extends the built-in list of common passwords
+// with one password or SHA-1 hex digest per line. Hash is bcrypt or
+// argon2id; Argon2Memory is in KiB. Stored hashes weaker than these settings
+// are replaced at the next login.
+type PasswordConfig struct {
+	MinLength        int
+	MaxLength        int
+	MinCharClasses   int
+	BreachedListFile string
+	Hash             string
+	BcryptCost       int
+	Argon2Time       int
+	Argon2Memory     int
+	Argon2Threads    int
+}
+
+// FeedsConfig controls the scheduler that pulls the external feeds. Every
+// PollInterval it pulls the feeds that are due, on one replica at a time:
+// the replica holds a lock that lapses after LockTTL unless renewed between
+// feeds, so LockTTL must exceed the longest single pull. FetchTimeout bounds
+// each request to a feed.
+type FeedsConfig struct {
+	Enabled      bool
+	PollInterval time.Duration
+	LockTTL      time.Duration
+	FetchTimeout time.Duration
+}
+
+// ScoringConfig controls the job that recomputes indicator scores every
+// Interval, on one replica at a time like the feed scheduler, and expires the
+// indicators scoring below ExpireBelow (0 never expires them).
+// SourceReputation maps source names to their reputation from 0 to 100;
+// other sources have DefaultReputation. Weights overrides the weights of
+// indicator types as reputation/sources/votes/half-life, for example
+// ipv4=60/25/15/168h.
+type ScoringConfig struct {
+	Enabled           bool
+	Interval          time.Duration
+	LockTTL           time.Duration
+	ExpireBelow       int
+	DefaultReputation int
+	SourceReputation  map[string]string
+	Weights           map[string]string
+}
+
+// LookupConfig bounds the observables of a bulk lookup and sets how long
+// results are cached in Redis; a CacheTTL of 0 disables the cache.
+type LookupConfig struct {
+	MaxObservables int
+	CacheTTL       time.Duration
 }
 
 type NewRelicConfig struct {
@@ -45,8 +181,9 @@ type NewRelicConfig struct {
 func Load() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "8080"),
-			Host: getEnv("SERVER_HOST", "0.0.0.0"),
+			Port:           getEnv("SERVER_PORT", "8080"),
+			Host:           getEnv("SERVER_HOST", "0.0.0.0"),
+			TrustedProxies: getEnvAsList("TRUSTED_PROXIES"),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -62,12 +199,85 @@ func Load() *Config {
 			DB:       getEnvAsInt("REDIS_DB", 0),
 		},
 		JWT: JWTConfig{
-			SecretKey: getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
+			SecretKey:      getEnv("JWT_SECRET", DefaultJWTSecret),
+			SigningKeysDir: getEnv("JWT_SIGNING_KEYS_DIR", ""),
+			SigningKeyID:   getEnv("JWT_SIGNING_KEY_ID", ""),
+			HS256Window:    getEnvAsDuration("JWT_HS256_WINDOW", 7*24*time.Hour),
+			Issuer:         getEnv("JWT_ISSUER", "threat-intel-backend"),
+			Audience:       getEnv("JWT_AUDIENCE", "threat-intel-api"),
+			Leeway:         getEnvAsDuration("JWT_LEEWAY", 30*time.Second),
 		},
 		NewRelic: NewRelicConfig{
 			LicenseKey: getEnv("NEW_RELIC_LICENSE_KEY", ""),
 			AppName:    getEnv("NEW_RELIC_APP_NAME", "zentara-threat-intel-api"),
 		},
+		RateLimit: RateLimitConfig{
+			Period: getEnvAsDuration("RATE_LIMIT_PERIOD", time.Minute),
+			Auth:   getEnvAsInt("RATE_LIMIT_AUTH", 10),
+			Public: getEnvAsInt("RATE_LIMIT_PUBLIC", 60),
+			API: RoleRateLimits{
+				Viewer:  getEnvAsInt("RATE_LIMIT_API_VIEWER", 60),
+				Analyst: getEnvAsInt("RATE_LIMIT_API_ANALYST", 300),
+				Admin:   getEnvAsInt("RATE_LIMIT_API_ADMIN", 300),
+			},
+			TAXII: RoleRateLimits{
+				Viewer:  getEnvAsInt("RATE_LIMIT_TAXII_VIEWER", 120),
+				Analyst: getEnvAsInt("RATE_LIMIT_TAXII_ANALYST", 600),
+				Admin:   getEnvAsInt("RATE_LIMIT_TAXII_ADMIN", 600),
+			},
+		},
+		MFA: MFAConfig{
+			Issuer: getEnv("MFA_ISSUER", "Zentara Threat Intel"),
+		},
+		OIDC: OIDCConfig{
+			IssuerURL:    getEnv("OIDC_ISSUER_URL", ""),
+			ClientID:     getEnv("OIDC_CLIENT_ID", ""),
+			ClientSecret: getEnv("OIDC_CLIENT_SECRET", ""),
+			RedirectURL:  getEnv("OIDC_REDIRECT_URL", ""),
+			GroupsClaim:  getEnv("OIDC_GROUPS_CLAIM", "groups"),
+			GroupRoles:   getEnvAsMap("OIDC_GROUP_ROLES"),
+			DefaultRole:  getEnv("OIDC_DEFAULT_ROLE", ""),
+		},
+		Mail: MailConfig{
+			Driver:       getEnv("MAIL_DRIVER", "log"),
+			From:         getEnv("MAIL_FROM", "Zentara Threat Intel <no-reply@localhost>"),
+			Dir:          getEnv("MAIL_DIR", "mail"),
+			SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+			SMTPPort:     getEnvAsInt("SMTP_PORT", 587),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+			AppURL:       getEnv("APP_URL", "http://localhost:3000"),
+		},
+		Password: PasswordConfig{
+			MinLength:        getEnvAsInt("PASSWORD_MIN_LENGTH", 8),
+			MaxLength:        getEnvAsInt("PASSWORD_MAX_LENGTH", 72),
+			MinCharClasses:   getEnvAsInt("PASSWORD_MIN_CHAR_CLASSES", 2),
+			BreachedListFile: getEnv("PASSWORD_BREACHED_LIST", ""),
+			Hash:             getEnv("PASSWORD_HASH", "bcrypt"),
+			BcryptCost:       getEnvAsInt("BCRYPT_COST", 12),
+			Argon2Time:       getEnvAsInt("ARGON2_TIME", 3),
+			Argon2Memory:     getEnvAsInt("ARGON2_MEMORY", 64*1024),
+			Argon2Threads:    getEnvAsInt("ARGON2_THREADS", 4),
+		},
+		Feeds: FeedsConfig{
+			Enabled:      getEnvAsBool("FEEDS_ENABLED", true),
+			PollInterval: getEnvAsDuration("FEEDS_POLL_INTERVAL", 30*time.Second),
+			LockTTL:      getEnvAsDuration("FEEDS_LOCK_TTL", 10*time.Minute),
+			FetchTimeout: getEnvAsDuration("FEEDS_FETCH_TIMEOUT", 2*time.Minute),
+		},
+		Scoring: ScoringConfig{
+			Enabled:           getEnvAsBool("SCORING_ENABLED", true),
+			Interval:          getEnvAsDuration("SCORING_INTERVAL", time.Hour),
+			LockTTL:           getEnvAsDuration("SCORING_LOCK_TTL", 10*time.Minute),
+			ExpireBelow:       getEnvAsInt("SCORING_EXPIRE_BELOW", 10),
+			DefaultReputation: getEnvAsInt("SCORING_DEFAULT_REPUTATION", 50),
+			SourceReputation:  getEnvAsMap("SCORING_SOURCE_REPUTATION"),
+			Weights:           getEnvAsMap("SCORING_WEIGHTS"),
+		},
+		Lookup: LookupConfig{
+			MaxObservables: getEnvAsInt("LOOKUP_MAX_OBSERVABLES", 10000),
+			CacheTTL:       getEnvAsDuration("LOOKUP_CACHE_TTL", 5*time.Minute),
+		},
 	}
 }
 
@@ -85,4 +295,45 @@ func getEnvAsInt(key string, defaultValue int) int {
 		}
 	}
 	return defaultValue
-}
\ No newline at end of file
+}
+
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return defaultValue
+}
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsList reads comma-separated values, skipping empty ones.
+func getEnvAsList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getEnvAsMap reads comma-separated key=value pairs, skipping malformed ones.
+func getEnvAsMap(key string) map[string]string {
+	values := map[string]string{}
+	for _, pair := range strings.Split(os.Getenv(key), ",") {
+		name, value, ok := strings.Cut(pair, "=")
+		if name, value = strings.TrimSpace(name), strings.TrimSpace(value); ok && name != "" && value != "" {
+			values[name] = value
+		}
+	}
+	return values
+}
@@ -2,6 +2,7 @@ package configs
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -24,20 +25,90 @@ func TestLoad(t *testing.T) {
 		assert.Equal(t, 0, config.Redis.DB)
 		assert.Equal(t, "", config.NewRelic.LicenseKey)
 		assert.Equal(t, "zentara-threat-intel-api", config.NewRelic.AppName)
+		assert.Equal(t, time.Minute, config.RateLimit.Period)
+		assert.Equal(t, 10, config.RateLimit.Auth)
+		assert.Equal(t, 60, config.RateLimit.API.Viewer)
+		assert.Equal(t, "Zentara Threat Intel", config.MFA.Issuer)
+		assert.Equal(t, "", config.JWT.SigningKeysDir)
+		assert.Equal(t, "threat-intel-backend", config.JWT.Issuer)
+		assert.Equal(t, "threat-intel-api", config.JWT.Audience)
+		assert.Equal(t, 30*time.Second, config.JWT.Leeway)
+		assert.Equal(t, 7*24*time.Hour, config.JWT.HS256Window)
+		assert.Equal(t, "", config.OIDC.IssuerURL)
+		assert.Equal(t, "groups", config.OIDC.GroupsClaim)
+		assert.Empty(t, config.OIDC.GroupRoles)
+		assert.Equal(t, "log", config.Mail.Driver)
+		assert.Equal(t, 587, config.Mail.SMTPPort)
+		assert.Equal(t, "http://localhost:3000", config.Mail.AppURL)
+		assert.Equal(t, 8, config.Password.MinLength)
+		assert.Equal(t, "bcrypt", config.Password.Hash)
+		assert.Equal(t, 12, config.Password.BcryptCost)
+		assert.Equal(t, 64*1024, config.Password.Argon2Memory)
+		assert.True(t, config.Feeds.Enabled)
+		assert.Equal(t, 30*time.Second, config.Feeds.PollInterval)
+		assert.Equal(t, 10*time.Minute, config.Feeds.LockTTL)
+		assert.True(t, config.Scoring.Enabled)
+		assert.Equal(t, time.Hour, config.Scoring.Interval)
+		assert.Equal(t, 10, config.Scoring.ExpireBelow)
+		assert.Equal(t, 50, config.Scoring.DefaultReputation)
+		assert.Empty(t, config.Scoring.Weights)
+		assert.Empty(t, config.Server.TrustedProxies)
+		assert.Equal(t, 10000, config.Lookup.MaxObservables)
+		assert.Equal(t, 5*time.Minute, config.Lookup.CacheTTL)
 	})
 
 	t.Run("load with environment variables", func(t *testing.T) {
 		t.Setenv("SERVER_PORT", "9000")
+		t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.0.2.10")
 		t.Setenv("DB_HOST", "testdb")
 		t.Setenv("REDIS_DB", "5")
 		t.Setenv("JWT_SECRET", "test-secret")
+		t.Setenv("JWT_SIGNING_KEYS_DIR", "/etc/threat-intel/jwt")
+		t.Setenv("JWT_SIGNING_KEY_ID", "2026-10")
+		t.Setenv("JWT_HS256_WINDOW", "24h")
+		t.Setenv("JWT_AUDIENCE", "intel-api")
+		t.Setenv("RATE_LIMIT_PERIOD", "10s")
+		t.Setenv("RATE_LIMIT_TAXII_VIEWER", "5")
+		t.Setenv("OIDC_ISSUER_URL", "https://idp.example.com")
+		t.Setenv("OIDC_GROUP_ROLES", "intel-admins=admin, intel-analysts = analyst,broken")
+		t.Setenv("MAIL_DRIVER", "smtp")
+		t.Setenv("SMTP_PORT", "2525")
+		t.Setenv("PASSWORD_HASH", "argon2id")
+		t.Setenv("PASSWORD_BREACHED_LIST", "/etc/threat-intel/breached.txt")
+		t.Setenv("FEEDS_ENABLED", "false")
+		t.Setenv("FEEDS_FETCH_TIMEOUT", "30s")
+		t.Setenv("SCORING_EXPIRE_BELOW", "20")
+		t.Setenv("SCORING_SOURCE_REPUTATION", "abuse-ch-urlhaus=90")
+		t.Setenv("SCORING_WEIGHTS", "ipv4=70/20/10/72h")
+		t.Setenv("LOOKUP_MAX_OBSERVABLES", "500")
+		t.Setenv("LOOKUP_CACHE_TTL", "0s")
 
 		config := Load()
 
 		assert.Equal(t, "9000", config.Server.Port)
+		assert.Equal(t, []string{"10.0.0.0/8", "192.0.2.10"}, config.Server.TrustedProxies)
 		assert.Equal(t, "testdb", config.Database.Host)
 		assert.Equal(t, 5, config.Redis.DB)
 		assert.Equal(t, "test-secret", config.JWT.SecretKey)
+		assert.Equal(t, "/etc/threat-intel/jwt", config.JWT.SigningKeysDir)
+		assert.Equal(t, "2026-10", config.JWT.SigningKeyID)
+		assert.Equal(t, 24*time.Hour, config.JWT.HS256Window)
+		assert.Equal(t, "intel-api", config.JWT.Audience)
+		assert.Equal(t, 10*time.Second, config.RateLimit.Period)
+		assert.Equal(t, 5, config.RateLimit.TAXII.Viewer)
+		assert.Equal(t, "https://idp.example.com", config.OIDC.IssuerURL)
+		assert.Equal(t, map[string]string{"intel-admins": "admin", "intel-analysts": "analyst"}, config.OIDC.GroupRoles)
+		assert.Equal(t, "smtp", config.Mail.Driver)
+		assert.Equal(t, 2525, config.Mail.SMTPPort)
+		assert.Equal(t, "argon2id", config.Password.Hash)
+		assert.Equal(t, "/etc/threat-intel/breached.txt", config.Password.BreachedListFile)
+		assert.False(t, config.Feeds.Enabled)
+		assert.Equal(t, 30*time.Second, config.Feeds.FetchTimeout)
+		assert.Equal(t, 20, config.Scoring.ExpireBelow)
+		assert.Equal(t, map[string]string{"abuse-ch-urlhaus": "90"}, config.Scoring.SourceReputation)
+		assert.Equal(t, map[string]string{"ipv4": "70/20/10/72h"}, config.Scoring.Weights)
+		assert.Equal(t, 500, config.Lookup.MaxObservables)
+		assert.Equal(t, time.Duration(0), config.Lookup.CacheTTL)
 	})
 }
 
@@ -96,3 +167,39 @@ func TestGetEnvAsInt(t *testing.T) {
 		assert.Equal(t, 10, result)
 	})
 }
+
+func TestGetEnvAsDuration(t *testing.T) {
+	t.Run("returns parsed duration when valid", func(t *testing.T) {
+		t.Setenv("DURATION_KEY", "90s")
+
+		result := getEnvAsDuration("DURATION_KEY", time.Minute)
+
+		assert.Equal(t, 90*time.Second, result)
+	})
+
+	t.Run("returns default when invalid duration", func(t *testing.T) {
+		t.Setenv("INVALID_DURATION", "60")
+
+		result := getEnvAsDuration("INVALID_DURATION", time.Minute)
+
+		assert.Equal(t, time.Minute, result)
+	})
+}
+
+func TestGetEnvAsBool(t *testing.T) {
+	t.Run("returns parsed bool when valid", func(t *testing.T) {
+		t.Setenv("BOOL_KEY", "false")
+
+		result := getEnvAsBool("BOOL_KEY", true)
+
+		assert.False(t, result)
+	})
+
+	t.Run("returns default when invalid bool", func(t *testing.T) {
+		t.Setenv("INVALID_BOOL", "nope")
+
+		result := getEnvAsBool("INVALID_BOOL", true)
+
+		assert.True(t, result)
+	})
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"time"
+	"github.com/google/uuid"
+)
+
+type APIKeyScope string
+
+const (
+	// ScopeIntelRead covers reading indicators, exporting STIX and polling
+	// TAXII collections.
+	ScopeIntelRead APIKeyScope = "intel:read"
+	// ScopeIntelWrite covers creating, updating and deleting indicators and
+	// importing STIX bundles. It requires the analyst role.
+	ScopeIntelWrite APIKeyScope = "intel:write"
+)
+
+func (s APIKeyScope) IsValid() bool {
+	switch s {
+	case ScopeIntelRead, ScopeIntelWrite:
+		return true
+	}
+	return false
+}
+
+// APIKey lets a machine client act as its owner, limited to its scopes. The
+// public Prefix identifies the key; only a hash of the secret is stored.
+type APIKey struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID     uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	Name       string     `json:"name" gorm:"not null"`
+	Prefix     string     `json:"prefix" gorm:"uniqueIndex;not null"`
+	SecretHash string     `json:"-" gorm:"not null"`
+	Scopes     Tags       `json:"scopes" gorm:"type:jsonb;not null;default:'[]'"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func NewAPIKey(userID uuid.UUID, name, prefix, secretHash string, scopes []APIKeyScope, expiresAt *time.Time) *APIKey {
+	key := &APIKey{
+		ID:         uuid.New(),
+		UserID:     userID,
+		Name:       name,
+		Prefix:     prefix,
+		SecretHash: secretHash,
+		Scopes:     Tags{},
+		ExpiresAt:  expiresAt,
+		CreatedAt:  time.Now(),
+	}
+	for _, scope := range scopes {
+		if !key.HasScope(scope) {
+			key.Scopes = append(key.Scopes, string(scope))
+		}
+	}
+	return key
+}
+
+// IsActive reports whether the key is neither revoked nor expired at now.
+func (k *APIKey) IsActive(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+func (k *APIKey) HasScope(scope APIKeyScope) bool {
+	for _, granted := range k.Scopes {
+		if granted == string(scope) {
+			return true
+		}
+	}
+	return false
+}
+
+func (k *APIKey) Revoke() {
+	now := time.Now()
+	k.RevokedAt = &now
+}
+
+type APIKeyRepository interface {
+	Save(key *APIKey) error
+	FindByID(id uuid.UUID) (*APIKey, error)
+	FindByPrefix(prefix string) (*APIKey, error)
+	FindByUserID(userID uuid.UUID) ([]*APIKey, error)
+	TouchLastUsed(id uuid.UUID, usedAt time.Time) error
+	DeleteByUserID(userID uuid.UUID) error
+}
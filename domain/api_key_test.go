@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyScope_IsValid(t *testing.T) {
+	assert.True(t, ScopeIntelRead.IsValid())
+	assert.True(t, ScopeIntelWrite.IsValid())
+	assert.False(t, APIKeyScope("admin").IsValid())
+}
+
+func TestNewAPIKey(t *testing.T) {
+	key := NewAPIKey(uuid.New(), "siem", "abc123", "hash", []APIKeyScope{ScopeIntelRead, ScopeIntelRead}, nil)
+
+	assert.Equal(t, Tags{"intel:read"}, key.Scopes)
+	assert.True(t, key.HasScope(ScopeIntelRead))
+	assert.False(t, key.HasScope(ScopeIntelWrite))
+}
+
+func TestAPIKey_IsActive(t *testing.T) {
+	now := time.Now()
+
+	t.Run("without expiry", func(t *testing.T) {
+		key := NewAPIKey(uuid.New(), "siem", "abc123", "hash", nil, nil)
+
+		assert.True(t, key.IsActive(now))
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		expiresAt := now.Add(-time.Minute)
+		key := NewAPIKey(uuid.New(), "siem", "abc123", "hash", nil, &expiresAt)
+
+		assert.False(t, key.IsActive(now))
+	})
+
+	t.Run("revoked", func(t *testing.T) {
+		key := NewAPIKey(uuid.New(), "siem", "abc123", "hash", nil, nil)
+		key.Revoke()
+
+		assert.False(t, key.IsActive(now))
+	})
+}
@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"errors"
+	"time"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNoSeatsLeft         = errors.New("every seat of the entitlement is assigned")
+	ErrSeatAlreadyAssigned = errors.New("the user already holds a seat of the entitlement")
+)
+
+// EntitlementTerm is how long a purchase grants access to its tier.
+const EntitlementTerm = 365 * 24 * time.Hour
+
+// Entitlement is the time-bound access to a tier granted by an order.
+// Quantity is the number of seats purchased: the buyer holds the first one and
+// can assign the others to other users.
+type Entitlement struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	OrderID   uuid.UUID `json:"order_id" gorm:"type:uuid;not null;uniqueIndex"`
+	Tier      Tier      `json:"tier" gorm:"not null"`
+	Quantity  int       `json:"quantity" gorm:"not null;default:1"`
+	StartsAt  time.Time `json:"starts_at" gorm:"not null"`
+	EndsAt    time.Time `json:"ends_at" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewEntitlement grants the tier bought by an order for one term starting at
+// startsAt.
+func NewEntitlement(order *Order, tier Tier, startsAt time.Time) *Entitlement {
+	return &Entitlement{
+		ID:        uuid.New(),
+		UserID:    order.UserID,
+		OrderID:   order.ID,
+		Tier:      tier,
+		Quantity:  order.Quantity,
+		StartsAt:  startsAt,
+		EndsAt:    startsAt.Add(EntitlementTerm),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// EntitlementSeat gives a user other than the buyer access through an
+// entitlement.
+type EntitlementSeat struct {
+	EntitlementID uuid.UUID `json:"entitlement_id" gorm:"type:uuid;primary_key"`
+	UserID        uuid.UUID `json:"user_id" gorm:"type:uuid;primary_key;index"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (e *Entitlement) IsActive(now time.Time) bool {
+	return !now.Before(e.StartsAt) && now.Before(e.EndsAt)
+}
+
+// Revoke ends the entitlement at the given time. An entitlement that has not
+// started yet never becomes active.
+func (e *Entitlement) Revoke(at time.Time) {
+	if e.StartsAt.After(at) {
+		e.StartsAt = at
+	}
+	if e.EndsAt.After(at) {
+		e.EndsAt = at
+	}
+	e.UpdatedAt = time.Now()
+}
+
+// AssignSeat gives the user a seat of the entitlement, given the seats
+// assigned so far. The buyer's own seat is never assigned.
+func (e *Entitlement) AssignSeat(userID uuid.UUID, seats []*EntitlementSeat) (*EntitlementSeat, error) {
+	if userID == e.UserID {
+		return nil, ErrSeatAlreadyAssigned
+	}
+	for _, seat := range seats {
+		if seat.UserID == userID {
+			return nil, ErrSeatAlreadyAssigned
+		}
+	}
+	if len(seats)+1 >= e.Quantity {
+		return nil, ErrNoSeatsLeft
+	}
+	return &EntitlementSeat{
+		EntitlementID: e.ID,
+		UserID:        userID,
+		CreatedAt:     time.Now(),
+	}, nil
+}
+
+// ActiveTier returns the highest tier among the entitlements active at now.
+func ActiveTier(entitlements []*Entitlement, now time.Time) Tier {
+	highest := TierNone
+	for _, entitlement := range entitlements {
+		if entitlement.IsActive(now) && !highest.Includes(entitlement.Tier) {
+			highest = entitlement.Tier
+		}
+	}
+	return highest
+}
+
+// RenewalStart returns when a new entitlement to tier should start so that
+// back-to-back purchases extend access instead of overlapping.
+func RenewalStart(entitlements []*Entitlement, tier Tier, now time.Time) time.Time {
+	start := now
+	for _, entitlement := range entitlements {
+		if entitlement.Tier == tier && entitlement.EndsAt.After(start) {
+			start = entitlement.EndsAt
+		}
+	}
+	return start
+}
+
+type EntitlementRepository interface {
+	Save(entitlement *Entitlement) error
+	FindByOrderID(orderID uuid.UUID) (*Entitlement, error)
+	// FindByIDForUpdate loads an entitlement and locks it until the end of
+	// the surrounding transaction.
+	FindByIDForUpdate(id uuid.UUID) (*Entitlement, error)
+	// FindByUserID returns the entitlements the user bought or holds a seat
+	// of, latest ending first.
+	FindByUserID(userID uuid.UUID) ([]*Entitlement, error)
+	SaveSeat(seat *EntitlementSeat) error
+	FindSeats(entitlementID uuid.UUID) ([]*EntitlementSeat, error)
+	DeleteSeat(entitlementID, userID uuid.UUID) error
+	DeleteSeatsByUserID(userID uuid.UUID) error
+}
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEntitlement(t *testing.T) {
+	order := NewOrder(uuid.New(), "intel-premium", 5).Order
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	entitlement := NewEntitlement(order, TierPremium, start)
+
+	assert.Equal(t, order.UserID, entitlement.UserID)
+	assert.Equal(t, order.ID, entitlement.OrderID)
+	assert.Equal(t, 5, entitlement.Quantity)
+	assert.Equal(t, start.Add(EntitlementTerm), entitlement.EndsAt)
+	assert.True(t, entitlement.IsActive(start))
+	assert.False(t, entitlement.IsActive(start.Add(-time.Second)))
+	assert.False(t, entitlement.IsActive(entitlement.EndsAt))
+}
+
+func TestEntitlement_Revoke(t *testing.T) {
+	now := time.Now()
+	current := &Entitlement{StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)}
+	renewal := &Entitlement{StartsAt: now.Add(time.Hour), EndsAt: now.Add(2 * time.Hour)}
+
+	current.Revoke(now)
+	renewal.Revoke(now)
+
+	assert.Equal(t, now, current.EndsAt)
+	assert.False(t, current.IsActive(now))
+	assert.False(t, renewal.IsActive(now))
+	assert.False(t, renewal.IsActive(now.Add(90*time.Minute)))
+}
+
+func TestEntitlement_AssignSeat(t *testing.T) {
+	entitlement := NewEntitlement(NewOrder(uuid.New(), "intel-basic", 3).Order, TierBasic, time.Now())
+	colleague := uuid.New()
+
+	seat, err := entitlement.AssignSeat(colleague, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, entitlement.ID, seat.EntitlementID)
+	assert.Equal(t, colleague, seat.UserID)
+
+	seats := []*EntitlementSeat{seat}
+	_, err = entitlement.AssignSeat(colleague, seats)
+	assert.Equal(t, ErrSeatAlreadyAssigned, err)
+	_, err = entitlement.AssignSeat(entitlement.UserID, seats)
+	assert.Equal(t, ErrSeatAlreadyAssigned, err)
+
+	seat, err = entitlement.AssignSeat(uuid.New(), seats)
+	assert.NoError(t, err)
+	seats = append(seats, seat)
+
+	_, err = entitlement.AssignSeat(uuid.New(), seats)
+	assert.Equal(t, ErrNoSeatsLeft, err)
+}
+
+func TestActiveTier(t *testing.T) {
+	now := time.Now()
+	entitlements := []*Entitlement{
+		{Tier: TierBasic, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)},
+		{Tier: TierEnterprise, StartsAt: now.Add(-2 * time.Hour), EndsAt: now.Add(-time.Hour)},
+		{Tier: TierPremium, StartsAt: now.Add(time.Hour), EndsAt: now.Add(2 * time.Hour)},
+	}
+
+	assert.Equal(t, TierBasic, ActiveTier(entitlements, now))
+	assert.Equal(t, TierNone, ActiveTier(nil, now))
+}
+
+func TestRenewalStart(t *testing.T) {
+	now := time.Now()
+	entitlements := []*Entitlement{
+		{Tier: TierBasic, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(24 * time.Hour)},
+		{Tier: TierPremium, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(48 * time.Hour)},
+	}
+
+	assert.Equal(t, now.Add(24*time.Hour), RenewalStart(entitlements, TierBasic, now))
+	assert.Equal(t, now, RenewalStart(entitlements, TierEnterprise, now))
+}
@@ -0,0 +1,353 @@
+package domain
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidFeed         = errors.New("invalid feed")
+	ErrInvalidFeedSchedule = errors.New("invalid feed schedule")
+)
+
+const (
+	// MinFeedInterval keeps feeds from being pulled more often than sources
+	// tolerate.
+	MinFeedInterval = time.Minute
+
+	// MaxFeedRunErrors bounds the skipped entries a run explains.
+	MaxFeedRunErrors = 20
+)
+
+type FeedFormat string
+
+const (
+	// FeedPlainText has one observable per line; # and ; start comments.
+	FeedPlainText FeedFormat = "plaintext"
+	FeedCSV       FeedFormat = "csv"
+	FeedJSON      FeedFormat = "json"
+	FeedSTIX      FeedFormat = "stix"
+	FeedMISP      FeedFormat = "misp"
+)
+
+func (f FeedFormat) IsValid() bool {
+	switch f {
+	case FeedPlainText, FeedCSV, FeedJSON, FeedSTIX, FeedMISP:
+		return true
+	}
+	return false
+}
+
+// FeedOptions tell how to read the entries of a feed and what to record for
+// them. Type, Confidence, Severity and Tags apply to the formats that do not
+// carry them (plaintext, csv and json); an empty Type is detected from each
+// value, and a zero Confidence is the default of 50.
+type FeedOptions struct {
+	Type       IndicatorType `json:"type,omitempty"`
+	Confidence int           `json:"confidence,omitempty"`
+	Severity   Severity      `json:"severity,omitempty"`
+	Tags       []string      `json:"tags,omitempty"`
+
+	// Column is the zero-based column of the value in CSV feeds.
+	Column     int  `json:"column,omitempty"`
+	SkipHeader bool `json:"skip_header,omitempty"`
+
+	// ItemsPath is the dot-separated path of the array of entries in JSON
+	// feeds, empty when the document is the array. ValueField names the
+	// value of entries that are objects.
+	ItemsPath  string `json:"items_path,omitempty"`
+	ValueField string `json:"value_field,omitempty"`
+
+	// IDSOnly skips MISP attributes that are not flagged for detection.
+	IDSOnly bool `json:"ids_only,omitempty"`
+}
+
+func (o FeedOptions) Value() (driver.Value, error) {
+	data, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (o *FeedOptions) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*o = FeedOptions{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, o)
+	case string:
+		return json.Unmarshal([]byte(v), o)
+	default:
+		return fmt.Errorf("unsupported feed options value %T", value)
+	}
+}
+
+func (o FeedOptions) validate() error {
+	if o.Type != "" && !o.Type.IsValid() {
+		return ErrInvalidIndicatorType
+	}
+	if err := validateConfidence(o.Confidence); err != nil {
+		return err
+	}
+	if o.Severity != "" && !o.Severity.IsValid() {
+		return ErrInvalidSeverity
+	}
+	if o.Column < 0 {
+		return fmt.Errorf("%w: column must not be negative", ErrInvalidFeed)
+	}
+	return nil
+}
+
+// Feed is an external source of indicators that is pulled on a schedule.
+// ETag, LastModified and Cursor are what the source needs to only fetch what
+// changed since the last pull.
+type Feed struct {
+	ID                  uuid.UUID   `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Name                string      `json:"name" gorm:"uniqueIndex;not null"`
+	Format              FeedFormat  `json:"format" gorm:"not null"`
+	URL                 string      `json:"url" gorm:"not null"`
+	Schedule            string      `json:"schedule" gorm:"not null"`
+	Options             FeedOptions `json:"options" gorm:"type:jsonb;not null;default:'{}'"`
+	Paused              bool        `json:"paused" gorm:"not null;default:false"`
+	ETag                string      `json:"etag,omitempty"`
+	LastModified        string      `json:"last_modified,omitempty"`
+	Cursor              string      `json:"cursor,omitempty"`
+	NextRunAt           time.Time   `json:"next_run_at" gorm:"not null;index"`
+	LastRunAt           *time.Time  `json:"last_run_at,omitempty"`
+	LastSuccessAt       *time.Time  `json:"last_success_at,omitempty"`
+	LastError           string      `json:"last_error,omitempty"`
+	ConsecutiveFailures int         `json:"consecutive_failures" gorm:"not null;default:0"`
+	TotalRuns           int         `json:"total_runs" gorm:"not null;default:0"`
+	TotalFailures       int         `json:"total_failures" gorm:"not null;default:0"`
+	CreatedBy           uuid.UUID   `json:"created_by" gorm:"type:uuid"`
+	CreatedAt           time.Time   `json:"created_at"`
+	UpdatedAt           time.Time   `json:"updated_at"`
+}
+
+// NewFeed returns a feed that is due right away.
+func NewFeed(name string, format FeedFormat, feedURL, schedule string, options FeedOptions) (*Feed, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidFeed)
+	}
+	if !format.IsValid() {
+		return nil, fmt.Errorf("%w: unknown format %q", ErrInvalidFeed, format)
+	}
+	if u, err := url.Parse(feedURL); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("%w: url must be an http or https URL", ErrInvalidFeed)
+	}
+	if _, err := ParseFeedSchedule(schedule); err != nil {
+		return nil, err
+	}
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &Feed{
+		ID:        uuid.New(),
+		Name:      name,
+		Format:    format,
+		URL:       feedURL,
+		Schedule:  schedule,
+		Options:   options,
+		NextRunAt: now,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// ParseFeedSchedule returns the interval of a schedule, which is either a
+// duration such as 30m or one of the cron descriptors @hourly, @daily,
+// @weekly and @every <duration>.
+func ParseFeedSchedule(schedule string) (time.Duration, error) {
+	var interval time.Duration
+	switch schedule = strings.TrimSpace(schedule); schedule {
+	case "@hourly":
+		interval = time.Hour
+	case "@daily":
+		interval = 24 * time.Hour
+	case "@weekly":
+		interval = 7 * 24 * time.Hour
+	default:
+		duration, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(schedule, "@every")))
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q is neither a duration nor @hourly, @daily, @weekly or @every <duration>", ErrInvalidFeedSchedule, schedule)
+		}
+		interval = duration
+	}
+
+	if interval < MinFeedInterval {
+		return 0, fmt.Errorf("%w: feeds are pulled at most every %s", ErrInvalidFeedSchedule, MinFeedInterval)
+	}
+	return interval, nil
+}
+
+// Interval is the time between two scheduled pulls.
+func (f *Feed) Interval() time.Duration {
+	interval, err := ParseFeedSchedule(f.Schedule)
+	if err != nil {
+		return MinFeedInterval
+	}
+	return interval
+}
+
+func (f *Feed) IsDue(now time.Time) bool {
+	return !f.Paused && !now.Before(f.NextRunAt)
+}
+
+func (f *Feed) Pause() {
+	f.Paused = true
+	f.UpdatedAt = time.Now()
+}
+
+// Resume schedules the next pull one interval after the last one, or right
+// away when that is past.
+func (f *Feed) Resume() {
+	f.Paused = false
+	f.NextRunAt = time.Now()
+	if f.LastRunAt != nil && f.LastRunAt.Add(f.Interval()).After(f.NextRunAt) {
+		f.NextRunAt = f.LastRunAt.Add(f.Interval())
+	}
+	f.UpdatedAt = time.Now()
+}
+
+// Trigger makes the feed due right away.
+func (f *Feed) Trigger() {
+	f.NextRunAt = time.Now()
+	f.UpdatedAt = f.NextRunAt
+}
+
+// RecordRun updates the counters and caching state of the feed with a
+// finished run and schedules the next one.
+func (f *Feed) RecordRun(run *FeedRun, result *FeedPullResult) {
+	finished := run.StartedAt
+	if run.FinishedAt != nil {
+		finished = *run.FinishedAt
+	}
+
+	f.LastRunAt = &finished
+	f.TotalRuns++
+	f.NextRunAt = finished.Add(f.Interval())
+	f.UpdatedAt = time.Now()
+
+	if run.Status == FeedRunFailed {
+		f.LastError = run.Error
+		f.ConsecutiveFailures++
+		f.TotalFailures++
+		// Incremental sources report the progress made before failing.
+		if result != nil {
+			f.Cursor = result.Cursor
+		}
+		return
+	}
+
+	f.LastError = ""
+	f.ConsecutiveFailures = 0
+	f.LastSuccessAt = &finished
+	if result != nil && !result.NotModified {
+		f.ETag = result.ETag
+		f.LastModified = result.LastModified
+		f.Cursor = result.Cursor
+	}
+}
+
+type FeedRunStatus string
+
+const (
+	FeedRunSucceeded   FeedRunStatus = "succeeded"
+	FeedRunNotModified FeedRunStatus = "not_modified"
+	FeedRunFailed      FeedRunStatus = "failed"
+)
+
+// FeedRun is the history record of one pull of a feed. Entries that could not
+// be turned into indicators are counted in Skipped and do not fail the run.
+type FeedRun struct {
+	ID         uuid.UUID     `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	FeedID     uuid.UUID     `json:"feed_id" gorm:"type:uuid;not null;index"`
+	Status     FeedRunStatus `json:"status" gorm:"not null"`
+	Created    int           `json:"created"`
+	Updated    int           `json:"updated"`
+	Skipped    int           `json:"skipped"`
+	Error      string        `json:"error,omitempty"`
+	Errors     StringList    `json:"errors,omitempty" gorm:"type:jsonb;not null;default:'[]'"`
+	StartedAt  time.Time     `json:"started_at" gorm:"index"`
+	FinishedAt *time.Time    `json:"finished_at,omitempty"`
+}
+
+func NewFeedRun(feedID uuid.UUID) *FeedRun {
+	return &FeedRun{
+		ID:        uuid.New(),
+		FeedID:    feedID,
+		Errors:    StringList{},
+		StartedAt: time.Now(),
+	}
+}
+
+// Finish records the outcome of the run; err fails it.
+func (r *FeedRun) Finish(result *FeedPullResult, err error) {
+	now := time.Now()
+	r.FinishedAt = &now
+
+	switch {
+	case err != nil:
+		r.Status = FeedRunFailed
+		r.Error = err.Error()
+	case result.NotModified:
+		r.Status = FeedRunNotModified
+	default:
+		r.Status = FeedRunSucceeded
+	}
+	if result != nil {
+		r.Skipped += result.Skipped
+		for _, message := range result.Errors {
+			if len(r.Errors) == MaxFeedRunErrors {
+				break
+			}
+			r.Errors = append(r.Errors, message)
+		}
+	}
+}
+
+// FeedPullResult is what a source reports about a pull besides the
+// indicators: whether anything changed and the state for the next pull.
+type FeedPullResult struct {
+	NotModified  bool
+	ETag         string
+	LastModified string
+	Cursor       string
+	Skipped      int
+	Errors       []string
+}
+
+// FeedSource pulls the feeds of one format. Pull passes every indicator of
+// the feed, or of what changed since its caching state, to emit together
+// with the raw entry it was read from, and stops at the first error emit
+// returns.
+type FeedSource interface {
+	Pull(ctx context.Context, feed *Feed, emit FeedEmitFunc) (*FeedPullResult, error)
+}
+
+type FeedEmitFunc func(indicator *Indicator, rawPayload string) error
+
+type FeedRepository interface {
+	Save(feed *Feed) error
+	FindByID(id uuid.UUID) (*Feed, error)
+	FindByName(name string) (*Feed, error)
+	List() ([]*Feed, error)
+	ListDue(now time.Time) ([]*Feed, error)
+}
+
+type FeedRunRepository interface {
+	Save(run *FeedRun) error
+	ListByFeedID(feedID uuid.UUID, limit int) ([]*FeedRun, error)
+}
@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFeedSchedule(t *testing.T) {
+	valid := map[string]time.Duration{
+		"30m":            30 * time.Minute,
+		"@every 90m":     90 * time.Minute,
+		"@hourly":        time.Hour,
+		"@daily":         24 * time.Hour,
+		" @weekly ":      7 * 24 * time.Hour,
+		"@every 1h30m0s": 90 * time.Minute,
+	}
+	for schedule, expected := range valid {
+		interval, err := ParseFeedSchedule(schedule)
+		assert.NoError(t, err, schedule)
+		assert.Equal(t, expected, interval, schedule)
+	}
+
+	for _, schedule := range []string{"", "*/5 * * * *", "@monthly", "10s", "@every -1h"} {
+		_, err := ParseFeedSchedule(schedule)
+		assert.True(t, errors.Is(err, ErrInvalidFeedSchedule), schedule)
+	}
+}
+
+func TestNewFeed(t *testing.T) {
+	t.Run("is due right away", func(t *testing.T) {
+		feed, err := NewFeed(" abuse.ch ", FeedPlainText, "https://feeds.example.com/ips.txt", "@hourly", FeedOptions{Type: IndicatorIPv4})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "abuse.ch", feed.Name)
+		assert.True(t, feed.IsDue(time.Now()))
+	})
+
+	invalid := map[string]func() (*Feed, error){
+		"name":     func() (*Feed, error) { return NewFeed(" ", FeedCSV, "https://example.com", "1h", FeedOptions{}) },
+		"format":   func() (*Feed, error) { return NewFeed("a", "xml", "https://example.com", "1h", FeedOptions{}) },
+		"url":      func() (*Feed, error) { return NewFeed("a", FeedCSV, "file:///etc/passwd", "1h", FeedOptions{}) },
+		"schedule": func() (*Feed, error) { return NewFeed("a", FeedCSV, "https://example.com", "5s", FeedOptions{}) },
+		"type": func() (*Feed, error) {
+			return NewFeed("a", FeedCSV, "https://example.com", "1h", FeedOptions{Type: "asn"})
+		},
+		"column": func() (*Feed, error) {
+			return NewFeed("a", FeedCSV, "https://example.com", "1h", FeedOptions{Column: -1})
+		},
+	}
+	for name, create := range invalid {
+		t.Run("rejects invalid "+name, func(t *testing.T) {
+			_, err := create()
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestFeed_PauseResume(t *testing.T) {
+	feed, _ := NewFeed("a", FeedCSV, "https://example.com", "1h", FeedOptions{})
+	lastRun := time.Now().Add(-10 * time.Minute)
+	feed.LastRunAt = &lastRun
+
+	feed.Pause()
+	assert.False(t, feed.IsDue(time.Now().Add(2*time.Hour)))
+
+	feed.Resume()
+	assert.False(t, feed.IsDue(time.Now()))
+	assert.WithinDuration(t, lastRun.Add(time.Hour), feed.NextRunAt, time.Second)
+
+	feed.Trigger()
+	assert.True(t, feed.IsDue(time.Now()))
+}
+
+func TestFeed_RecordRun(t *testing.T) {
+	feed, _ := NewFeed("a", FeedCSV, "https://example.com", "1h", FeedOptions{})
+
+	t.Run("failure counts and keeps the caching state", func(t *testing.T) {
+		feed.ETag = `"v1"`
+		for i := 0; i < 2; i++ {
+			run := NewFeedRun(feed.ID)
+			run.Finish(nil, errors.New("connection refused"))
+			feed.RecordRun(run, nil)
+		}
+
+		assert.Equal(t, 2, feed.ConsecutiveFailures)
+		assert.Equal(t, 2, feed.TotalFailures)
+		assert.Equal(t, "connection refused", feed.LastError)
+		assert.Equal(t, `"v1"`, feed.ETag)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), feed.NextRunAt, time.Second)
+	})
+
+	t.Run("success resets the failures and stores the caching state", func(t *testing.T) {
+		result := &FeedPullResult{ETag: `"v2"`, Skipped: 1, Errors: []string{"line 3: invalid"}}
+		run := NewFeedRun(feed.ID)
+		run.Finish(result, nil)
+		feed.RecordRun(run, result)
+
+		assert.Equal(t, FeedRunSucceeded, run.Status)
+		assert.Equal(t, 1, run.Skipped)
+		assert.Equal(t, 0, feed.ConsecutiveFailures)
+		assert.Equal(t, 3, feed.TotalRuns)
+		assert.Equal(t, `"v2"`, feed.ETag)
+		assert.Empty(t, feed.LastError)
+		assert.NotNil(t, feed.LastSuccessAt)
+	})
+
+	t.Run("not modified keeps the caching state", func(t *testing.T) {
+		result := &FeedPullResult{NotModified: true}
+		run := NewFeedRun(feed.ID)
+		run.Finish(result, nil)
+		feed.RecordRun(run, result)
+
+		assert.Equal(t, FeedRunNotModified, run.Status)
+		assert.Equal(t, `"v2"`, feed.ETag)
+	})
+}
+
+func TestFeedRun_FinishCapsErrors(t *testing.T) {
+	errs := make([]string, 50)
+	run := NewFeedRun(uuid.New())
+	run.Finish(&FeedPullResult{Skipped: 50, Errors: errs}, nil)
+
+	assert.Equal(t, 50, run.Skipped)
+	assert.Len(t, run.Errors, MaxFeedRunErrors)
+}
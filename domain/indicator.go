@@ -0,0 +1,430 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidIndicatorType  = errors.New("invalid indicator type")
+	ErrInvalidIndicatorValue = errors.New("invalid indicator value")
+	ErrInvalidConfidence     = errors.New("confidence must be between 0 and 100")
+	ErrInvalidSeverity       = errors.New("invalid severity")
+)
+
+type IndicatorType string
+
+const (
+	IndicatorIPv4   IndicatorType = "ipv4"
+	IndicatorIPv6   IndicatorType = "ipv6"
+	IndicatorDomain IndicatorType = "domain"
+	IndicatorURL    IndicatorType = "url"
+	IndicatorMD5    IndicatorType = "md5"
+	IndicatorSHA1   IndicatorType = "sha1"
+	IndicatorSHA256 IndicatorType = "sha256"
+	IndicatorEmail  IndicatorType = "email"
+)
+
+func (t IndicatorType) IsValid() bool {
+	switch t {
+	case IndicatorIPv4, IndicatorIPv6, IndicatorDomain, IndicatorURL,
+		IndicatorMD5, IndicatorSHA1, IndicatorSHA256, IndicatorEmail:
+		return true
+	}
+	return false
+}
+
+type Severity string
+
+const (
+	SeverityLow      Severity = "low"
+	SeverityMedium   Severity = "medium"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
+)
+
+func (s Severity) IsValid() bool {
+	switch s {
+	case SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical:
+		return true
+	}
+	return false
+}
+
+// Tags is stored as a JSON array so it can be filtered with jsonb containment.
+type Tags []string
+
+func (t Tags) Value() (driver.Value, error) {
+	if t == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal([]string(t))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (t *Tags) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*t = Tags{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported tags value %T", value)
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+type Indicator struct {
+	ID         uuid.UUID     `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	StixID     string        `json:"stix_id" gorm:"uniqueIndex"`
+	Type       IndicatorType `json:"type" gorm:"not null;uniqueIndex:idx_indicator_type_value"`
+	Value      string        `json:"value" gorm:"not null;uniqueIndex:idx_indicator_type_value"`
+	Confidence int           `json:"confidence" gorm:"not null;default:50"`
+	Score      int           `json:"score" gorm:"not null;default:0;index"`
+	ScoredAt   *time.Time    `json:"scored_at,omitempty"`
+	Severity   Severity      `json:"severity" gorm:"not null;default:'medium'"`
+	Tags       Tags          `json:"tags" gorm:"type:jsonb;not null;default:'[]'"`
+	Source     string        `json:"source"`
+	FirstSeen  time.Time     `json:"first_seen" gorm:"not null"`
+	LastSeen   time.Time     `json:"last_seen" gorm:"not null;index"`
+	ExpiresAt  *time.Time    `json:"expires_at,omitempty" gorm:"index"`
+	CreatedBy  uuid.UUID     `json:"created_by" gorm:"type:uuid"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+// NewIndicator validates and normalizes the value for its type so that the
+// same observable always ends up with the same stored representation. The
+// score is the confidence until the indicator is first scored.
+func NewIndicator(indicatorType IndicatorType, value string, confidence int, severity Severity) (*Indicator, error) {
+	normalized, err := NormalizeIndicatorValue(indicatorType, value)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateConfidence(confidence); err != nil {
+		return nil, err
+	}
+	if severity == "" {
+		severity = SeverityMedium
+	}
+	if !severity.IsValid() {
+		return nil, ErrInvalidSeverity
+	}
+
+	id := uuid.New()
+	now := time.Now()
+	return &Indicator{
+		ID:         id,
+		StixID:     NewStixID("indicator", id),
+		Type:       indicatorType,
+		Value:      normalized,
+		Confidence: confidence,
+		Score:      confidence,
+		Severity:   severity,
+		Tags:       Tags{},
+		FirstSeen:  now,
+		LastSeen:   now,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}, nil
+}
+
+func (i *Indicator) Key() IndicatorKey {
+	return IndicatorKey{Type: i.Type, Value: i.Value}
+}
+
+func (i *Indicator) SetConfidence(confidence int) error {
+	if err := validateConfidence(confidence); err != nil {
+		return err
+	}
+	i.Confidence = confidence
+	i.UpdatedAt = time.Now()
+	return nil
+}
+
+func (i *Indicator) SetSeverity(severity Severity) error {
+	if !severity.IsValid() {
+		return ErrInvalidSeverity
+	}
+	i.Severity = severity
+	i.UpdatedAt = time.Now()
+	return nil
+}
+
+// SetTags lowercases, trims and de-duplicates the tags.
+func (i *Indicator) SetTags(tags []string) {
+	i.Tags = NormalizeTags(tags)
+	i.UpdatedAt = time.Now()
+}
+
+// Merge folds what a source reported about the same observable into the
+// indicator: the tags are joined, the highest confidence is kept and the
+// first/last seen window widened. A report seen after the indicator expired
+// revives it with the expiry of the report.
+func (i *Indicator) Merge(reported *Indicator) {
+	i.Tags = NormalizeTags(append(append([]string{}, i.Tags...), reported.Tags...))
+	if reported.Confidence > i.Confidence {
+		i.Confidence = reported.Confidence
+	}
+	if i.ExpiresAt != nil && reported.LastSeen.After(*i.ExpiresAt) {
+		i.ExpiresAt = reported.ExpiresAt
+	}
+	i.Seen(reported.FirstSeen)
+	i.Seen(reported.LastSeen)
+}
+
+// Seen widens the first/last seen window to include at.
+func (i *Indicator) Seen(at time.Time) {
+	if at.Before(i.FirstSeen) {
+		i.FirstSeen = at
+	}
+	if at.After(i.LastSeen) {
+		i.LastSeen = at
+	}
+	i.UpdatedAt = time.Now()
+}
+
+func (i *Indicator) IsExpired(now time.Time) bool {
+	return i.ExpiresAt != nil && !now.Before(*i.ExpiresAt)
+}
+
+// ApplyScore records a computed score and expires the indicator when the
+// score is below expireBelow. It reports whether the indicator expired.
+func (i *Indicator) ApplyScore(score, expireBelow int, now time.Time) bool {
+	i.Score = score
+	i.ScoredAt = &now
+	if score >= expireBelow || i.IsExpired(now) {
+		return false
+	}
+	i.ExpiresAt = &now
+	i.UpdatedAt = now
+	return true
+}
+
+func NormalizeTags(tags []string) Tags {
+	seen := make(map[string]bool, len(tags))
+	normalized := Tags{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+var hashLengths = map[IndicatorType]int{
+	IndicatorMD5:    32,
+	IndicatorSHA1:   40,
+	IndicatorSHA256: 64,
+}
+
+var (
+	hexPattern   = regexp.MustCompile(`^[0-9A-F]+$`)
+	labelPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+)
+
+// refanger undoes the usual ways of defanging observables in reports, such
+// as evil[.]example[.]com or phish[at]example.com.
+var refanger = strings.NewReplacer(
+	"[.]", ".", "(.)", ".", "{.}", ".", "[dot]", ".", "(dot)", ".", "{dot}", ".",
+	"[:]", ":", "[://]", "://",
+	"[@]", "@", "[at]", "@", "(at)", "@", "{at}", "@",
+)
+
+// defangedSchemes are the defanged URL schemes and what they stand for.
+var defangedSchemes = []struct{ defanged, scheme string }{
+	{"hxxps://", "https://"},
+	{"hxxp://", "http://"},
+	{"fxp://", "ftp://"},
+}
+
+// defaultPorts are left out of canonical URLs.
+var defaultPorts = map[string]string{"http": "80", "https": "443", "ftp": "21"}
+
+// Refang returns a defanged observable as it was before defanging, and any
+// other value unchanged.
+func Refang(value string) string {
+	value = refanger.Replace(strings.TrimSpace(value))
+	lower := strings.ToLower(value)
+	for _, s := range defangedSchemes {
+		if strings.HasPrefix(lower, s.defanged) {
+			return s.scheme + value[len(s.defanged):]
+		}
+	}
+	return value
+}
+
+// NormalizeIndicatorValue returns the canonical form of value for the given
+// type, or an error wrapping ErrInvalidIndicatorValue. Defanged values are
+// accepted; domains and the hosts of URLs and emails are lower case, IPv6
+// addresses in their RFC 5952 form and hashes upper case.
+func NormalizeIndicatorValue(indicatorType IndicatorType, value string) (string, error) {
+	value = Refang(value)
+
+	switch indicatorType {
+	case IndicatorIPv4:
+		ip := net.ParseIP(value)
+		if ip == nil || ip.To4() == nil || strings.Contains(value, ":") {
+			return "", invalidValue(indicatorType, value)
+		}
+		return ip.To4().String(), nil
+	case IndicatorIPv6:
+		addr, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(value, "["), "]"))
+		if err != nil || !addr.Is6() {
+			return "", invalidValue(indicatorType, value)
+		}
+		return addr.WithZone("").String(), nil
+	case IndicatorDomain:
+		domain, ok := normalizeDomain(value)
+		if !ok {
+			return "", invalidValue(indicatorType, value)
+		}
+		return domain, nil
+	case IndicatorURL:
+		u, err := url.Parse(value)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "ftp") {
+			return "", invalidValue(indicatorType, value)
+		}
+		u.Scheme = strings.ToLower(u.Scheme)
+		u.Host = strings.ToLower(u.Host)
+		if u.Port() == defaultPorts[u.Scheme] {
+			u.Host = strings.TrimSuffix(u.Host, ":"+u.Port())
+		}
+		u.Fragment = ""
+		return u.String(), nil
+	case IndicatorMD5, IndicatorSHA1, IndicatorSHA256:
+		hash := strings.ToUpper(value)
+		if len(hash) != hashLengths[indicatorType] || !hexPattern.MatchString(hash) {
+			return "", invalidValue(indicatorType, value)
+		}
+		return hash, nil
+	case IndicatorEmail:
+		addr, err := mail.ParseAddress(value)
+		if err != nil || addr.Name != "" || addr.Address != value {
+			return "", invalidValue(indicatorType, value)
+		}
+		at := strings.LastIndex(addr.Address, "@")
+		domain, ok := normalizeDomain(addr.Address[at+1:])
+		if !ok {
+			return "", invalidValue(indicatorType, value)
+		}
+		return addr.Address[:at+1] + domain, nil
+	default:
+		return "", ErrInvalidIndicatorType
+	}
+}
+
+// DetectIndicatorType guesses the type of an observable from its shape. The
+// value still has to be normalized, which rejects values of no type.
+func DetectIndicatorType(value string) IndicatorType {
+	value = Refang(value)
+
+	if ip := net.ParseIP(value); ip != nil {
+		if strings.Contains(value, ":") {
+			return IndicatorIPv6
+		}
+		return IndicatorIPv4
+	}
+	if strings.Contains(value, "://") {
+		return IndicatorURL
+	}
+	if hexPattern.MatchString(strings.ToUpper(value)) {
+		for indicatorType, length := range hashLengths {
+			if len(value) == length {
+				return indicatorType
+			}
+		}
+	}
+	if strings.Contains(value, "@") {
+		return IndicatorEmail
+	}
+	return IndicatorDomain
+}
+
+func normalizeDomain(value string) (string, bool) {
+	domain := strings.TrimSuffix(strings.ToLower(value), ".")
+	if len(domain) == 0 || len(domain) > 253 {
+		return "", false
+	}
+
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return "", false
+	}
+	for _, label := range labels {
+		if !labelPattern.MatchString(label) {
+			return "", false
+		}
+	}
+	return domain, true
+}
+
+func validateConfidence(confidence int) error {
+	if confidence < 0 || confidence > 100 {
+		return ErrInvalidConfidence
+	}
+	return nil
+}
+
+func invalidValue(indicatorType IndicatorType, value string) error {
+	return fmt.Errorf("%w: %q is not a valid %s", ErrInvalidIndicatorValue, value, indicatorType)
+}
+
+// IndicatorFilter narrows down indicator listings. Zero values match
+// everything; expired indicators are excluded unless IncludeExpired is set.
+type IndicatorFilter struct {
+	Type           IndicatorType
+	Types          []IndicatorType
+	Severity       Severity
+	Tag            string
+	Source         string
+	IncludeExpired bool
+	Offset         int
+	Limit          int
+}
+
+// IndicatorCursor is a position in the stream of indicators ordered by update
+// time. A zero ID means "strictly after UpdatedAt".
+type IndicatorCursor struct {
+	UpdatedAt time.Time
+	ID        uuid.UUID
+}
+
+type IndicatorRepository interface {
+	Save(indicator *Indicator) error
+	FindByID(id uuid.UUID) (*Indicator, error)
+	FindByValue(indicatorType IndicatorType, value string) (*Indicator, error)
+	FindByStixID(stixID string) (*Indicator, error)
+	FindByKeys(keys []IndicatorKey) ([]*Indicator, error)
+	List(filter IndicatorFilter) ([]*Indicator, int64, error)
+	ListChanges(filter IndicatorFilter, after IndicatorCursor) ([]*Indicator, error)
+	// ListUnexpired pages through the indicators that are not expired at
+	// now in ID order, starting after the given ID.
+	ListUnexpired(now time.Time, after uuid.UUID, limit int) ([]*Indicator, error)
+	// SaveScore stores the score, scoring time and expiry of an indicator
+	// without touching its other fields.
+	SaveScore(indicator *Indicator) error
+	Delete(id uuid.UUID) error
+}
@@ -0,0 +1,218 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeIndicatorValue(t *testing.T) {
+	valid := []struct {
+		indicatorType IndicatorType
+		input         string
+		expected      string
+	}{
+		{IndicatorIPv4, " 192.168.1.10 ", "192.168.1.10"},
+		{IndicatorIPv4, "192.0.2[.]1", "192.0.2.1"},
+		{IndicatorIPv6, "2001:DB8:0:0:0:0:0:1", "2001:db8::1"},
+		{IndicatorIPv6, "[2001:db8:0::1]", "2001:db8::1"},
+		{IndicatorIPv6, "::FFFF:10.0.0.1", "::ffff:10.0.0.1"},
+		{IndicatorIPv6, "fe80::1%eth0", "fe80::1"},
+		{IndicatorDomain, "Evil.Example.COM.", "evil.example.com"},
+		{IndicatorDomain, "evil[.]example(.)com", "evil.example.com"},
+		{IndicatorDomain, "xn--bcher-kva.example", "xn--bcher-kva.example"},
+		{IndicatorURL, "HTTP://Evil.Example.com/Path?q=1#frag", "http://evil.example.com/Path?q=1"},
+		{IndicatorURL, "hXXps[:]//evil[.]example[.]com/a", "https://evil.example.com/a"},
+		{IndicatorURL, "https://evil.example.com:443/a", "https://evil.example.com/a"},
+		{IndicatorURL, "http://evil.example.com:8443/a", "http://evil.example.com:8443/a"},
+		{IndicatorMD5, "d41d8cd98f00b204e9800998ecf8427e", "D41D8CD98F00B204E9800998ECF8427E"},
+		{IndicatorSHA1, "da39a3ee5e6b4b0d3255bfef95601890afd80709", "DA39A3EE5E6B4B0D3255BFEF95601890AFD80709"},
+		{IndicatorSHA256, "E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855", "E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855"},
+		{IndicatorEmail, "Phish@Example.COM", "Phish@example.com"},
+		{IndicatorEmail, "phish[at]example[.]com", "phish@example.com"},
+	}
+	for _, tc := range valid {
+		t.Run(string(tc.indicatorType)+" "+tc.input, func(t *testing.T) {
+			value, err := NormalizeIndicatorValue(tc.indicatorType, tc.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, value)
+		})
+	}
+
+	invalid := []struct {
+		indicatorType IndicatorType
+		input         string
+	}{
+		{IndicatorIPv4, "256.1.1.1"},
+		{IndicatorIPv4, "::ffff:10.0.0.1"},
+		{IndicatorIPv6, "10.0.0.1"},
+		{IndicatorDomain, "localhost"},
+		{IndicatorDomain, "-bad.example.com"},
+		{IndicatorDomain, "bad_label.example.com"},
+		{IndicatorURL, "evil.example.com/path"},
+		{IndicatorURL, "javascript:alert(1)"},
+		{IndicatorMD5, "d41d8cd98f00b204"},
+		{IndicatorSHA1, "zz39a3ee5e6b4b0d3255bfef95601890afd80709"},
+		{IndicatorSHA256, "d41d8cd98f00b204e9800998ecf8427e"},
+		{IndicatorEmail, "Someone <someone@example.com>"},
+		{IndicatorEmail, "not-an-email"},
+	}
+	for _, tc := range invalid {
+		t.Run("rejects "+string(tc.indicatorType)+" "+tc.input, func(t *testing.T) {
+			_, err := NormalizeIndicatorValue(tc.indicatorType, tc.input)
+			assert.True(t, errors.Is(err, ErrInvalidIndicatorValue))
+		})
+	}
+
+	t.Run("rejects unknown type", func(t *testing.T) {
+		_, err := NormalizeIndicatorValue("registry-key", "HKLM")
+		assert.Equal(t, ErrInvalidIndicatorType, err)
+	})
+}
+
+func TestDetectIndicatorType(t *testing.T) {
+	tests := map[string]IndicatorType{
+		"192.0.2.1":                                IndicatorIPv4,
+		"2001:db8::1":                              IndicatorIPv6,
+		"https://evil.example.com/a":               IndicatorURL,
+		"D41D8CD98F00B204E9800998ECF8427E":         IndicatorMD5,
+		"da39a3ee5e6b4b0d3255bfef95601890afd80709": IndicatorSHA1,
+		"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855": IndicatorSHA256,
+		"phish@example.com": IndicatorEmail,
+		"evil.example.com":  IndicatorDomain,
+		"hxxp://evil[.]example[.]com/a": IndicatorURL,
+		"198.51.100[.]7":                 IndicatorIPv4,
+		"deadbeef":          IndicatorDomain,
+	}
+	for value, expected := range tests {
+		assert.Equal(t, expected, DetectIndicatorType(value), value)
+	}
+}
+
+func TestNewIndicator(t *testing.T) {
+	t.Run("creates normalized indicator", func(t *testing.T) {
+		indicator, err := NewIndicator(IndicatorDomain, "Evil.Example.com", 80, SeverityHigh)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, indicator.ID)
+		assert.Equal(t, "evil.example.com", indicator.Value)
+		assert.Equal(t, 80, indicator.Confidence)
+		assert.Equal(t, SeverityHigh, indicator.Severity)
+		assert.Equal(t, indicator.FirstSeen, indicator.LastSeen)
+		assert.NotNil(t, indicator.Tags)
+	})
+
+	t.Run("defaults severity", func(t *testing.T) {
+		indicator, err := NewIndicator(IndicatorIPv4, "10.0.0.1", 50, "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, SeverityMedium, indicator.Severity)
+	})
+
+	t.Run("rejects out of range confidence", func(t *testing.T) {
+		_, err := NewIndicator(IndicatorIPv4, "10.0.0.1", 101, SeverityLow)
+		assert.Equal(t, ErrInvalidConfidence, err)
+	})
+
+	t.Run("rejects unknown severity", func(t *testing.T) {
+		_, err := NewIndicator(IndicatorIPv4, "10.0.0.1", 10, "severe")
+		assert.Equal(t, ErrInvalidSeverity, err)
+	})
+}
+
+func TestIndicator_Seen(t *testing.T) {
+	indicator, _ := NewIndicator(IndicatorIPv4, "10.0.0.1", 50, SeverityLow)
+	first, last := indicator.FirstSeen, indicator.LastSeen
+
+	indicator.Seen(first.Add(-time.Hour))
+	assert.Equal(t, first.Add(-time.Hour), indicator.FirstSeen)
+	assert.Equal(t, last, indicator.LastSeen)
+
+	indicator.Seen(last.Add(time.Hour))
+	assert.Equal(t, last.Add(time.Hour), indicator.LastSeen)
+}
+
+func TestIndicator_Merge(t *testing.T) {
+	indicator, _ := NewIndicator(IndicatorIPv4, "10.0.0.1", 60, SeverityLow)
+	indicator.Tags = Tags{"scanner"}
+	first, last := indicator.FirstSeen, indicator.LastSeen
+
+	reported, _ := NewIndicator(IndicatorIPv4, "10.0.0.1", 40, SeverityHigh)
+	reported.Tags = Tags{"botnet", "scanner"}
+	reported.FirstSeen = first.Add(-time.Hour)
+	reported.LastSeen = last.Add(time.Hour)
+	indicator.Merge(reported)
+
+	assert.Equal(t, Tags{"botnet", "scanner"}, indicator.Tags)
+	assert.Equal(t, 60, indicator.Confidence)
+	assert.Equal(t, SeverityLow, indicator.Severity)
+	assert.Equal(t, first.Add(-time.Hour), indicator.FirstSeen)
+	assert.Equal(t, last.Add(time.Hour), indicator.LastSeen)
+
+	reported.Confidence = 90
+	indicator.Merge(reported)
+	assert.Equal(t, 90, indicator.Confidence)
+
+	t.Run("a later report revives an expired indicator", func(t *testing.T) {
+		expiresAt := reported.LastSeen.Add(-time.Minute)
+		indicator.ExpiresAt = &expiresAt
+		reported.LastSeen = reported.LastSeen.Add(time.Hour)
+
+		indicator.Merge(reported)
+
+		assert.Nil(t, indicator.ExpiresAt)
+	})
+}
+
+func TestIndicator_ApplyScore(t *testing.T) {
+	indicator, _ := NewIndicator(IndicatorIPv4, "10.0.0.1", 60, SeverityLow)
+	assert.Equal(t, 60, indicator.Score)
+	now := time.Now()
+
+	assert.False(t, indicator.ApplyScore(35, 10, now))
+	assert.Equal(t, 35, indicator.Score)
+	assert.Equal(t, &now, indicator.ScoredAt)
+	assert.Nil(t, indicator.ExpiresAt)
+
+	assert.True(t, indicator.ApplyScore(9, 10, now))
+	assert.True(t, indicator.IsExpired(now))
+	assert.Equal(t, now, indicator.UpdatedAt)
+
+	later := now.Add(time.Hour)
+	assert.False(t, indicator.ApplyScore(5, 10, later), "already expired")
+	assert.Equal(t, now, *indicator.ExpiresAt)
+
+	fresh, _ := NewIndicator(IndicatorIPv4, "10.0.0.2", 0, SeverityLow)
+	assert.False(t, fresh.ApplyScore(0, 0, now), "a threshold of 0 never expires")
+}
+
+func TestIndicator_IsExpired(t *testing.T) {
+	indicator, _ := NewIndicator(IndicatorIPv4, "10.0.0.1", 50, SeverityLow)
+	now := time.Now()
+
+	assert.False(t, indicator.IsExpired(now))
+
+	expiresAt := now.Add(-time.Minute)
+	indicator.ExpiresAt = &expiresAt
+	assert.True(t, indicator.IsExpired(now))
+}
+
+func TestNormalizeTags(t *testing.T) {
+	assert.Equal(t, Tags{"apt28", "phishing"}, NormalizeTags([]string{" Phishing", "APT28", "phishing", ""}))
+	assert.Equal(t, Tags{}, NormalizeTags(nil))
+}
+
+func TestTags_ValueScan(t *testing.T) {
+	value, err := Tags{"a", "b"}.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, `["a","b"]`, value)
+
+	var tags Tags
+	assert.NoError(t, tags.Scan([]byte(`["c"]`)))
+	assert.Equal(t, Tags{"c"}, tags)
+
+	assert.NoError(t, tags.Scan(nil))
+	assert.Equal(t, Tags{}, tags)
+}
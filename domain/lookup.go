@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"context"
+	"time"
+	"github.com/google/uuid"
+)
+
+// IndicatorKey identifies an observable by its type and normalized value.
+type IndicatorKey struct {
+	Type  IndicatorType
+	Value string
+}
+
+// LookupMatch is what a lookup tells about a known indicator. Sources are the
+// names of the sources that reported it.
+type LookupMatch struct {
+	IndicatorID uuid.UUID     `json:"indicator_id"`
+	Type        IndicatorType `json:"type"`
+	Value       string        `json:"value"`
+	Score       int           `json:"score"`
+	Confidence  int           `json:"confidence"`
+	Severity    Severity      `json:"severity"`
+	Tags        Tags          `json:"tags"`
+	Sources     []string      `json:"sources"`
+	FirstSeen   time.Time     `json:"first_seen"`
+	LastSeen    time.Time     `json:"last_seen"`
+	ExpiresAt   *time.Time    `json:"expires_at,omitempty"`
+}
+
+// LookupCache keeps recent lookup results by observable. Unknown observables
+// are cached too, as a nil match; Get leaves out the keys it does not hold.
+// Delete drops the results of observables whose indicator was written.
+type LookupCache interface {
+	Get(ctx context.Context, keys []IndicatorKey) (map[IndicatorKey]*LookupMatch, error)
+	Set(ctx context.Context, results map[IndicatorKey]*LookupMatch) error
+	Delete(ctx context.Context, keys []IndicatorKey) error
+}
@@ -0,0 +1,13 @@
+package domain
+
+// Email is a plain-text message to a single recipient.
+type Email struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers emails, such as password reset and verification links.
+type Mailer interface {
+	Send(email *Email) error
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"time"
+	"github.com/google/uuid"
+)
+
+// MFAFactor is the TOTP factor of a user. It only protects logins once it has
+// been confirmed with a first code. Recovery codes are stored as hashes and
+// each can be used once.
+type MFAFactor struct {
+	UserID        uuid.UUID  `json:"user_id" gorm:"type:uuid;primary_key"`
+	Secret        string     `json:"-" gorm:"not null"`
+	RecoveryCodes StringList `json:"-" gorm:"type:jsonb;not null;default:'[]'"`
+	ConfirmedAt   *time.Time `json:"confirmed_at,omitempty"`
+	LastUsedStep  int64      `json:"-" gorm:"not null;default:0"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func NewMFAFactor(userID uuid.UUID, secret string) *MFAFactor {
+	return &MFAFactor{
+		UserID:        userID,
+		Secret:        secret,
+		RecoveryCodes: StringList{},
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+}
+
+func (f *MFAFactor) IsConfirmed() bool {
+	return f.ConfirmedAt != nil
+}
+
+func (f *MFAFactor) Confirm() {
+	now := time.Now()
+	f.ConfirmedAt = &now
+	f.UpdatedAt = now
+}
+
+// UseStep records the time step of an accepted code. It reports false when
+// the step is not newer than the last one used, so that an observed code
+// cannot be replayed within its validity window.
+func (f *MFAFactor) UseStep(step int64) bool {
+	if step <= f.LastUsedStep {
+		return false
+	}
+	f.LastUsedStep = step
+	f.UpdatedAt = time.Now()
+	return true
+}
+
+func (f *MFAFactor) SetRecoveryCodes(hashes []string) {
+	f.RecoveryCodes = StringList(hashes)
+	f.UpdatedAt = time.Now()
+}
+
+// UseRecoveryCode removes the recovery code with the given hash and reports
+// whether it was there.
+func (f *MFAFactor) UseRecoveryCode(hash string) bool {
+	for i, stored := range f.RecoveryCodes {
+		if stored == hash {
+			f.RecoveryCodes = append(f.RecoveryCodes[:i:i], f.RecoveryCodes[i+1:]...)
+			f.UpdatedAt = time.Now()
+			return true
+		}
+	}
+	return false
+}
+
+// MFAPolicy records whether admins require MFA for every user of a role.
+// Roles without a stored policy do not require it.
+type MFAPolicy struct {
+	Role      UserRole   `json:"role" gorm:"primary_key"`
+	Required  bool       `json:"required" gorm:"not null;default:false"`
+	UpdatedBy *uuid.UUID `json:"updated_by,omitempty" gorm:"type:uuid"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+type MFAFactorRepository interface {
+	Save(factor *MFAFactor) error
+	FindByUserID(userID uuid.UUID) (*MFAFactor, error)
+	Delete(userID uuid.UUID) error
+}
+
+type MFAPolicyRepository interface {
+	Save(policy *MFAPolicy) error
+	FindByRole(role UserRole) (*MFAPolicy, error)
+	List() ([]*MFAPolicy, error)
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMFAFactor(t *testing.T) {
+	userID := uuid.New()
+	factor := NewMFAFactor(userID, "JBSWY3DPEHPK3PXP")
+
+	assert.Equal(t, userID, factor.UserID)
+	assert.False(t, factor.IsConfirmed())
+	assert.Empty(t, factor.RecoveryCodes)
+
+	factor.Confirm()
+	assert.True(t, factor.IsConfirmed())
+}
+
+func TestMFAFactor_UseStep(t *testing.T) {
+	factor := NewMFAFactor(uuid.New(), "JBSWY3DPEHPK3PXP")
+
+	assert.True(t, factor.UseStep(100))
+	assert.False(t, factor.UseStep(100))
+	assert.False(t, factor.UseStep(99))
+	assert.True(t, factor.UseStep(101))
+	assert.Equal(t, int64(101), factor.LastUsedStep)
+}
+
+func TestMFAFactor_UseRecoveryCode(t *testing.T) {
+	factor := NewMFAFactor(uuid.New(), "JBSWY3DPEHPK3PXP")
+	factor.SetRecoveryCodes([]string{"a", "b", "c"})
+
+	assert.True(t, factor.UseRecoveryCode("b"))
+	assert.False(t, factor.UseRecoveryCode("b"))
+	assert.False(t, factor.UseRecoveryCode("d"))
+	assert.Equal(t, StringList{"a", "c"}, factor.RecoveryCodes)
+}
@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
 	"time"
 	"github.com/google/uuid"
 )
@@ -14,17 +16,60 @@ const (
 	OrderStatusCancelled OrderStatus = "cancelled"
 )
 
+var ErrInvalidOrderTransition = errors.New("invalid order status transition")
+
+// OrderTransitionError reports a status change the order lifecycle does not
+// allow. It matches ErrInvalidOrderTransition with errors.Is.
+type OrderTransitionError struct {
+	From OrderStatus
+	To   OrderStatus
+}
+
+func (e *OrderTransitionError) Error() string {
+	return fmt.Sprintf("cannot move order from %s to %s", e.From, e.To)
+}
+
+func (e *OrderTransitionError) Is(target error) bool {
+	return target == ErrInvalidOrderTransition
+}
+
+// orderTransitions lists the statuses each status can move to. Completed and
+// cancelled orders are final.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPending:   {OrderStatusConfirmed, OrderStatusCancelled},
+	OrderStatusConfirmed: {OrderStatusCompleted, OrderStatusCancelled},
+}
+
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Order keeps a snapshot of the product's price and tier at purchase time so
+// that later catalog changes do not alter past orders.
 type Order struct {
 	ID        uuid.UUID   `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
 	UserID    uuid.UUID   `json:"user_id" gorm:"type:uuid;not null"`
 	ItemID    string      `json:"item_id" gorm:"not null"`
 	Quantity  int         `json:"quantity" gorm:"not null;default:1"`
+	UnitPrice int64       `json:"unit_price" gorm:"not null;default:0"`
+	Currency  string      `json:"currency" gorm:"size:3"`
+	Tier      Tier        `json:"tier"`
 	Status    OrderStatus `json:"status" gorm:"not null;default:'pending'"`
 	CreatedAt time.Time   `json:"created_at"`
 	UpdatedAt time.Time   `json:"updated_at"`
 	User      User        `json:"user" gorm:"foreignKey:UserID"`
 }
 
+// Total is the order amount in the minor unit of its currency.
+func (o *Order) Total() int64 {
+	return o.UnitPrice * int64(o.Quantity)
+}
+
 type OrderAggregate struct {
 	Order *Order
 }
@@ -42,24 +87,40 @@ func NewOrder(userID uuid.UUID, itemID string, quantity int) *OrderAggregate {
 	return &OrderAggregate{Order: order}
 }
 
-func (oa *OrderAggregate) Confirm() {
-	oa.Order.Status = OrderStatusConfirmed
-	oa.Order.UpdatedAt = time.Now()
+// PriceFrom snapshots the product's price and tier onto the order.
+func (oa *OrderAggregate) PriceFrom(product *Product) {
+	oa.Order.UnitPrice = product.Price
+	oa.Order.Currency = product.Currency
+	oa.Order.Tier = product.Tier
 }
 
-func (oa *OrderAggregate) Complete() {
-	oa.Order.Status = OrderStatusCompleted
-	oa.Order.UpdatedAt = time.Now()
+func (oa *OrderAggregate) Confirm() error {
+	return oa.transition(OrderStatusConfirmed)
 }
 
-func (oa *OrderAggregate) Cancel() {
-	oa.Order.Status = OrderStatusCancelled
+func (oa *OrderAggregate) Complete() error {
+	return oa.transition(OrderStatusCompleted)
+}
+
+func (oa *OrderAggregate) Cancel() error {
+	return oa.transition(OrderStatusCancelled)
+}
+
+func (oa *OrderAggregate) transition(next OrderStatus) error {
+	if !oa.Order.Status.CanTransitionTo(next) {
+		return &OrderTransitionError{From: oa.Order.Status, To: next}
+	}
+	oa.Order.Status = next
 	oa.Order.UpdatedAt = time.Now()
+	return nil
 }
 
 type OrderRepository interface {
 	Save(order *Order) error
 	FindByID(id uuid.UUID) (*Order, error)
+	// FindByIDForUpdate loads an order and locks it until the end of the
+	// transaction, so that concurrent status changes are applied in turn.
+	FindByIDForUpdate(id uuid.UUID) (*Order, error)
 	FindByUserID(userID uuid.UUID) ([]*Order, error)
 }
 
@@ -67,4 +128,6 @@ type UserRepository interface {
 	Save(user *User) error
 	FindByID(id uuid.UUID) (*User, error)
 	FindByEmail(email string) (*User, error)
+	List(filter UserFilter) ([]*User, int64, error)
+	Delete(id uuid.UUID) error
 }
\ No newline at end of file
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -32,7 +33,7 @@ func TestOrderAggregate_Confirm(t *testing.T) {
 	originalUpdatedAt := orderAggregate.Order.UpdatedAt
 
 	time.Sleep(1 * time.Millisecond)
-	orderAggregate.Confirm()
+	assert.NoError(t, orderAggregate.Confirm())
 
 	assert.Equal(t, OrderStatusConfirmed, orderAggregate.Order.Status)
 	assert.True(t, orderAggregate.Order.UpdatedAt.After(originalUpdatedAt))
@@ -41,10 +42,11 @@ func TestOrderAggregate_Confirm(t *testing.T) {
 func TestOrderAggregate_Complete(t *testing.T) {
 	userID := uuid.New()
 	orderAggregate := NewOrder(userID, "intel-basic", 1)
+	assert.NoError(t, orderAggregate.Confirm())
 	originalUpdatedAt := orderAggregate.Order.UpdatedAt
 
 	time.Sleep(1 * time.Millisecond)
-	orderAggregate.Complete()
+	assert.NoError(t, orderAggregate.Complete())
 
 	assert.Equal(t, OrderStatusCompleted, orderAggregate.Order.Status)
 	assert.True(t, orderAggregate.Order.UpdatedAt.After(originalUpdatedAt))
@@ -56,8 +58,44 @@ func TestOrderAggregate_Cancel(t *testing.T) {
 	originalUpdatedAt := orderAggregate.Order.UpdatedAt
 
 	time.Sleep(1 * time.Millisecond)
-	orderAggregate.Cancel()
+	assert.NoError(t, orderAggregate.Cancel())
 
 	assert.Equal(t, OrderStatusCancelled, orderAggregate.Order.Status)
 	assert.True(t, orderAggregate.Order.UpdatedAt.After(originalUpdatedAt))
-}
\ No newline at end of file
+}
+
+func TestOrderAggregate_IllegalTransitions(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(*OrderAggregate) error
+		move  func(*OrderAggregate) error
+		from  OrderStatus
+		to    OrderStatus
+	}{
+		{"complete pending", func(*OrderAggregate) error { return nil }, (*OrderAggregate).Complete, OrderStatusPending, OrderStatusCompleted},
+		{"confirm twice", (*OrderAggregate).Confirm, (*OrderAggregate).Confirm, OrderStatusConfirmed, OrderStatusConfirmed},
+		{"complete cancelled", (*OrderAggregate).Cancel, (*OrderAggregate).Complete, OrderStatusCancelled, OrderStatusCompleted},
+		{"cancel completed", func(oa *OrderAggregate) error {
+			if err := oa.Confirm(); err != nil {
+				return err
+			}
+			return oa.Complete()
+		}, (*OrderAggregate).Cancel, OrderStatusCompleted, OrderStatusCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orderAggregate := NewOrder(uuid.New(), "intel-basic", 1)
+			assert.NoError(t, tt.setup(orderAggregate))
+
+			err := tt.move(orderAggregate)
+
+			assert.True(t, errors.Is(err, ErrInvalidOrderTransition))
+			var transitionErr *OrderTransitionError
+			assert.True(t, errors.As(err, &transitionErr))
+			assert.Equal(t, tt.from, transitionErr.From)
+			assert.Equal(t, tt.to, transitionErr.To)
+			assert.Equal(t, tt.from, orderAggregate.Order.Status)
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	PasswordHashBcrypt   = "bcrypt"
+	PasswordHashArgon2id = "argon2id"
+)
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+var ErrInvalidPasswordHashing = errors.New("invalid password hashing parameters")
+
+// PasswordHashing are the algorithm and parameters new password hashes are
+// made with. Hashes of either algorithm verify whatever the parameters, so
+// they can be changed at any time; NeedsRehash tells which stored hashes are
+// weaker than the current parameters. Argon2Memory is in KiB.
+type PasswordHashing struct {
+	Algorithm     string
+	BcryptCost    int
+	Argon2Time    uint32
+	Argon2Memory  uint32
+	Argon2Threads uint8
+}
+
+// DefaultPasswordHashing is bcrypt with its default cost, which hashes made
+// before the parameters were configurable use.
+var DefaultPasswordHashing = PasswordHashing{
+	Algorithm:     PasswordHashBcrypt,
+	BcryptCost:    bcrypt.DefaultCost,
+	Argon2Time:    3,
+	Argon2Memory:  64 * 1024,
+	Argon2Threads: 4,
+}
+
+var passwordHashing = DefaultPasswordHashing
+
+// UsePasswordHashing sets the parameters users hash their passwords with.
+// It is meant to be called once at startup.
+func UsePasswordHashing(hashing PasswordHashing) error {
+	if err := hashing.validate(); err != nil {
+		return err
+	}
+	passwordHashing = hashing
+	return nil
+}
+
+func (h PasswordHashing) validate() error {
+	switch h.Algorithm {
+	case PasswordHashBcrypt:
+		if h.BcryptCost < bcrypt.MinCost || h.BcryptCost > bcrypt.MaxCost {
+			return fmt.Errorf("%w: bcrypt cost must be between %d and %d", ErrInvalidPasswordHashing, bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	case PasswordHashArgon2id:
+		if h.Argon2Time == 0 || h.Argon2Memory < 8*uint32(h.Argon2Threads) || h.Argon2Threads == 0 {
+			return fmt.Errorf("%w: argon2id needs a time, threads and at least 8 KiB of memory per thread", ErrInvalidPasswordHashing)
+		}
+	default:
+		return fmt.Errorf("%w: unknown algorithm %q", ErrInvalidPasswordHashing, h.Algorithm)
+	}
+	return nil
+}
+
+// Hash hashes a password with a random salt. Argon2id hashes are encoded in
+// the PHC string format, e.g. $argon2id$v=19$m=65536,t=3,p=4$<salt>$<key>.
+func (h PasswordHashing) Hash(password string) (string, error) {
+	if h.Algorithm != PasswordHashArgon2id {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.BcryptCost)
+		return string(hash), err
+	}
+
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.Argon2Time, h.Argon2Memory, h.Argon2Threads, argon2KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version,
+		h.Argon2Memory, h.Argon2Time, h.Argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// NeedsRehash reports whether a hash was made with another algorithm or with
+// weaker parameters than h.
+func (h PasswordHashing) NeedsRehash(hash string) bool {
+	if h.Algorithm == PasswordHashArgon2id {
+		params, _, _, err := parseArgon2id(hash)
+		return err != nil ||
+			params.Argon2Time < h.Argon2Time ||
+			params.Argon2Memory < h.Argon2Memory ||
+			params.Argon2Threads < h.Argon2Threads
+	}
+
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost < h.BcryptCost
+}
+
+// VerifyPassword checks a password against a bcrypt or argon2id hash.
+func VerifyPassword(hash, password string) bool {
+	if !strings.HasPrefix(hash, "$argon2id$") {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	}
+
+	params, salt, key, err := parseArgon2id(hash)
+	if err != nil {
+		return false
+	}
+	actual := argon2.IDKey([]byte(password), salt, params.Argon2Time, params.Argon2Memory, params.Argon2Threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(actual, key) == 1
+}
+
+func parseArgon2id(hash string) (PasswordHashing, []byte, []byte, error) {
+	params := PasswordHashing{Algorithm: PasswordHashArgon2id}
+	invalid := errors.New("malformed argon2id hash")
+
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, invalid
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, invalid
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Argon2Memory, &params.Argon2Time, &params.Argon2Threads); err != nil {
+		return params, nil, nil, invalid
+	}
+	if params.validate() != nil {
+		return params, nil, nil, invalid
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, invalid
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, invalid
+	}
+	return params, salt, key, nil
+}
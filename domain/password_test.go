@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testArgon2id keeps argon2id fast enough for tests.
+var testArgon2id = PasswordHashing{Algorithm: PasswordHashArgon2id, Argon2Time: 1, Argon2Memory: 64, Argon2Threads: 1}
+
+func usePasswordHashing(t *testing.T, hashing PasswordHashing) {
+	previous := passwordHashing
+	assert.NoError(t, UsePasswordHashing(hashing))
+	t.Cleanup(func() { passwordHashing = previous })
+}
+
+func TestPasswordHashing_Hash(t *testing.T) {
+	t.Run("bcrypt", func(t *testing.T) {
+		hash, err := PasswordHashing{Algorithm: PasswordHashBcrypt, BcryptCost: bcrypt.MinCost}.Hash("password123")
+
+		assert.NoError(t, err)
+		cost, _ := bcrypt.Cost([]byte(hash))
+		assert.Equal(t, bcrypt.MinCost, cost)
+		assert.True(t, VerifyPassword(hash, "password123"))
+		assert.False(t, VerifyPassword(hash, "password124"))
+	})
+
+	t.Run("argon2id", func(t *testing.T) {
+		hash, err := testArgon2id.Hash("password123")
+
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$"))
+		assert.True(t, VerifyPassword(hash, "password123"))
+		assert.False(t, VerifyPassword(hash, "password124"))
+
+		other, _ := testArgon2id.Hash("password123")
+		assert.NotEqual(t, hash, other)
+	})
+
+	t.Run("rejects malformed hashes", func(t *testing.T) {
+		for _, hash := range []string{
+			"",
+			"$argon2id$v=19$m=64,t=1,p=1$c2FsdA",
+			"$argon2id$v=16$m=64,t=1,p=1$c2FsdA$a2V5",
+			"$argon2id$v=19$m=64,t=0,p=1$c2FsdA$a2V5",
+			"$argon2id$v=19$m=64,t=1,p=1$c2FsdA$",
+		} {
+			assert.False(t, VerifyPassword(hash, "password123"), hash)
+		}
+	})
+}
+
+func TestPasswordHashing_NeedsRehash(t *testing.T) {
+	bcrypt4, _ := PasswordHashing{Algorithm: PasswordHashBcrypt, BcryptCost: bcrypt.MinCost}.Hash("password123")
+	weakArgon2id, _ := testArgon2id.Hash("password123")
+
+	strongBcrypt := PasswordHashing{Algorithm: PasswordHashBcrypt, BcryptCost: bcrypt.MinCost + 1}
+	assert.True(t, strongBcrypt.NeedsRehash(bcrypt4))
+	assert.False(t, PasswordHashing{Algorithm: PasswordHashBcrypt, BcryptCost: bcrypt.MinCost}.NeedsRehash(bcrypt4))
+	assert.True(t, strongBcrypt.NeedsRehash(weakArgon2id))
+
+	assert.False(t, testArgon2id.NeedsRehash(weakArgon2id))
+	assert.True(t, testArgon2id.NeedsRehash(bcrypt4))
+	stronger := testArgon2id
+	stronger.Argon2Memory = 128
+	assert.True(t, stronger.NeedsRehash(weakArgon2id))
+}
+
+func TestUsePasswordHashing(t *testing.T) {
+	t.Run("rejects invalid parameters", func(t *testing.T) {
+		for _, hashing := range []PasswordHashing{
+			{Algorithm: "md5"},
+			{Algorithm: PasswordHashBcrypt, BcryptCost: 32},
+			{Algorithm: PasswordHashArgon2id, Argon2Time: 1, Argon2Memory: 4, Argon2Threads: 1},
+		} {
+			assert.ErrorIs(t, UsePasswordHashing(hashing), ErrInvalidPasswordHashing)
+		}
+		assert.Equal(t, DefaultPasswordHashing, passwordHashing)
+	})
+
+	t.Run("hashes new passwords with the parameters", func(t *testing.T) {
+		legacy, _ := NewUser("user@example.com", "password123", RoleViewer)
+		assert.False(t, legacy.PasswordNeedsRehash())
+
+		usePasswordHashing(t, testArgon2id)
+
+		user, err := NewUser("user@example.com", "password123", RoleViewer)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(user.PasswordHash, "$argon2id$"))
+		assert.False(t, user.PasswordNeedsRehash())
+
+		assert.True(t, legacy.PasswordNeedsRehash())
+		assert.True(t, legacy.ValidatePassword("password123"))
+		assert.NoError(t, legacy.SetPassword("password123"))
+		assert.False(t, legacy.PasswordNeedsRehash())
+		assert.False(t, NewSSOUser("sso@example.com", RoleViewer).PasswordNeedsRehash())
+	})
+}
@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"time"
+)
+
+var (
+	ErrInvalidProductID   = errors.New("product ID must be 2-64 lowercase letters, digits or hyphens")
+	ErrInvalidProductName = errors.New("product name is required")
+	ErrInvalidPrice       = errors.New("price must not be negative")
+	ErrInvalidCurrency    = errors.New("currency must be a 3-letter ISO 4217 code")
+	ErrInvalidTier        = errors.New("invalid tier")
+	ErrInvalidMaxQuantity = errors.New("max quantity must be at least 1")
+)
+
+var (
+	productIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,63}$`)
+	currencyPattern  = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// Product is an item of the catalog that orders refer to by ID. Prices are in
+// the minor unit of the currency, e.g. cents.
+type Product struct {
+	ID          string    `json:"id" gorm:"primary_key"`
+	Name        string    `json:"name" gorm:"not null"`
+	Description string    `json:"description"`
+	Price       int64     `json:"price" gorm:"not null"`
+	Currency    string    `json:"currency" gorm:"not null;size:3"`
+	Tier        Tier      `json:"tier" gorm:"not null"`
+	MaxQuantity int       `json:"max_quantity" gorm:"not null;default:1"`
+	Active      bool      `json:"active" gorm:"not null;default:true"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func NewProduct(id, name string, price int64, currency string, tier Tier, maxQuantity int) (*Product, error) {
+	product := &Product{
+		ID:        id,
+		Active:    true,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if !productIDPattern.MatchString(id) {
+		return nil, ErrInvalidProductID
+	}
+	if err := product.SetName(name); err != nil {
+		return nil, err
+	}
+	if err := product.SetPrice(price, currency); err != nil {
+		return nil, err
+	}
+	if err := product.SetTier(tier); err != nil {
+		return nil, err
+	}
+	if err := product.SetMaxQuantity(maxQuantity); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+func (p *Product) SetName(name string) error {
+	if name == "" {
+		return ErrInvalidProductName
+	}
+	p.Name = name
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+func (p *Product) SetPrice(price int64, currency string) error {
+	if price < 0 {
+		return ErrInvalidPrice
+	}
+	if !currencyPattern.MatchString(currency) {
+		return ErrInvalidCurrency
+	}
+	p.Price = price
+	p.Currency = currency
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+func (p *Product) SetTier(tier Tier) error {
+	if !tier.IsValid() {
+		return ErrInvalidTier
+	}
+	p.Tier = tier
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+func (p *Product) SetMaxQuantity(maxQuantity int) error {
+	if maxQuantity < 1 {
+		return ErrInvalidMaxQuantity
+	}
+	p.MaxQuantity = maxQuantity
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// DefaultProducts is the catalog seeded into an empty database.
+func DefaultProducts() []*Product {
+	basic, _ := NewProduct("intel-basic", "Intel Basic", 4900, "USD", TierBasic, 100)
+	basic.Description = "Network indicators: IP addresses, domains and URLs"
+	premium, _ := NewProduct("intel-premium", "Intel Premium", 19900, "USD", TierPremium, 100)
+	premium.Description = "Network indicators and file hashes"
+	enterprise, _ := NewProduct("intel-enterprise", "Intel Enterprise", 99900, "USD", TierEnterprise, 1000)
+	enterprise.Description = "Every indicator type"
+	return []*Product{basic, premium, enterprise}
+}
+
+type ProductRepository interface {
+	Save(product *Product) error
+	FindByID(id string) (*Product, error)
+	List(activeOnly bool) ([]*Product, error)
+	Delete(id string) error
+}
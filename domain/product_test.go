@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewProduct(t *testing.T) {
+	product, err := NewProduct("intel-basic", "Intel Basic", 4900, "USD", TierBasic, 10)
+
+	assert.NoError(t, err)
+	assert.True(t, product.Active)
+	assert.Equal(t, int64(4900), product.Price)
+
+	tests := []struct {
+		name        string
+		id          string
+		productName string
+		price       int64
+		currency    string
+		tier        Tier
+		maxQuantity int
+		err         error
+	}{
+		{"bad id", "Intel Basic", "Intel Basic", 0, "USD", TierBasic, 1, ErrInvalidProductID},
+		{"missing name", "intel-basic", "", 0, "USD", TierBasic, 1, ErrInvalidProductName},
+		{"negative price", "intel-basic", "Intel Basic", -1, "USD", TierBasic, 1, ErrInvalidPrice},
+		{"bad currency", "intel-basic", "Intel Basic", 0, "usd", TierBasic, 1, ErrInvalidCurrency},
+		{"bad tier", "intel-basic", "Intel Basic", 0, "USD", Tier("gold"), 1, ErrInvalidTier},
+		{"bad max quantity", "intel-basic", "Intel Basic", 0, "USD", TierBasic, 0, ErrInvalidMaxQuantity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewProduct(tt.id, tt.productName, tt.price, tt.currency, tt.tier, tt.maxQuantity)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestOrderAggregate_PriceFrom(t *testing.T) {
+	product, _ := NewProduct("intel-premium", "Intel Premium", 19900, "EUR", TierPremium, 10)
+	orderAggregate := NewOrder(uuid.New(), product.ID, 3)
+
+	orderAggregate.PriceFrom(product)
+	product.Price = 1
+
+	assert.Equal(t, int64(19900), orderAggregate.Order.UnitPrice)
+	assert.Equal(t, "EUR", orderAggregate.Order.Currency)
+	assert.Equal(t, TierPremium, orderAggregate.Order.Tier)
+	assert.Equal(t, int64(59700), orderAggregate.Order.Total())
+}
+
+func TestDefaultProducts(t *testing.T) {
+	products := DefaultProducts()
+
+	assert.Len(t, products, 3)
+	for _, product := range products {
+		assert.NotNil(t, product)
+		assert.True(t, product.Tier.IsValid())
+	}
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"time"
+)
+
+// RateLimit allows Limit requests per Period. A zero Limit disables limiting.
+type RateLimit struct {
+	Limit  int
+	Period time.Duration
+}
+
+// RateLimitResult describes the state of a key after a request was counted.
+// ResetAfter is the time until the full budget is available again and
+// RetryAfter, set only when the request was denied, the time until the next
+// request would be allowed.
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration
+	RetryAfter time.Duration
+}
+
+// RateLimiter counts requests per key. Implementations are shared by every
+// replica of the API so that a limit holds across pods.
+type RateLimiter interface {
+	Allow(key string, limit RateLimit) (*RateLimitResult, error)
+}
@@ -0,0 +1,170 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidScoreWeights = errors.New("invalid score weights")
+
+const (
+	// DefaultSourceReputation is the reputation of sources without one of
+	// their own.
+	DefaultSourceReputation = 50
+
+	// fullVoteMargin is the lead of up over down votes, or the other way
+	// round, that counts the full votes weight.
+	fullVoteMargin = 3
+)
+
+// ScoreWeights are the points out of 100 that each signal contributes to
+// the score of an indicator type: the reputation of the most trusted source
+// scaled by the confidence it reported, the number of independent sources and
+// the net analyst votes. The sum is then halved every HalfLife since the
+// indicator was last seen.
+type ScoreWeights struct {
+	Reputation int           `json:"reputation"`
+	Sources    int           `json:"sources"`
+	Votes      int           `json:"votes"`
+	HalfLife   time.Duration `json:"half_life"`
+}
+
+// DefaultScoreWeights decay network indicators, which are reassigned and
+// cleaned up, much faster than file hashes, which never change meaning.
+var DefaultScoreWeights = map[IndicatorType]ScoreWeights{
+	IndicatorIPv4:   {Reputation: 60, Sources: 25, Votes: 15, HalfLife: 7 * 24 * time.Hour},
+	IndicatorIPv6:   {Reputation: 60, Sources: 25, Votes: 15, HalfLife: 7 * 24 * time.Hour},
+	IndicatorURL:    {Reputation: 60, Sources: 25, Votes: 15, HalfLife: 14 * 24 * time.Hour},
+	IndicatorDomain: {Reputation: 60, Sources: 25, Votes: 15, HalfLife: 30 * 24 * time.Hour},
+	IndicatorEmail:  {Reputation: 60, Sources: 25, Votes: 15, HalfLife: 30 * 24 * time.Hour},
+	IndicatorMD5:    {Reputation: 60, Sources: 25, Votes: 15, HalfLife: 365 * 24 * time.Hour},
+	IndicatorSHA1:   {Reputation: 60, Sources: 25, Votes: 15, HalfLife: 365 * 24 * time.Hour},
+	IndicatorSHA256: {Reputation: 60, Sources: 25, Votes: 15, HalfLife: 365 * 24 * time.Hour},
+}
+
+func (w ScoreWeights) validate() error {
+	if w.Reputation < 0 || w.Sources < 0 || w.Votes < 0 {
+		return fmt.Errorf("%w: weights must not be negative", ErrInvalidScoreWeights)
+	}
+	if sum := w.Reputation + w.Sources + w.Votes; sum != 100 {
+		return fmt.Errorf("%w: weights add up to %d instead of 100", ErrInvalidScoreWeights, sum)
+	}
+	if w.HalfLife <= 0 {
+		return fmt.Errorf("%w: half-life must be positive", ErrInvalidScoreWeights)
+	}
+	return nil
+}
+
+// ParseScoreWeights reads weights written as reputation/sources/votes/half-life,
+// for example 60/25/15/168h.
+func ParseScoreWeights(value string) (ScoreWeights, error) {
+	parts := strings.Split(strings.TrimSpace(value), "/")
+	if len(parts) != 4 {
+		return ScoreWeights{}, fmt.Errorf("%w: %q is not reputation/sources/votes/half-life", ErrInvalidScoreWeights, value)
+	}
+
+	var points [3]int
+	for i, part := range parts[:3] {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return ScoreWeights{}, fmt.Errorf("%w: %q is not a number", ErrInvalidScoreWeights, part)
+		}
+		points[i] = n
+	}
+	halfLife, err := time.ParseDuration(strings.TrimSpace(parts[3]))
+	if err != nil {
+		return ScoreWeights{}, fmt.Errorf("%w: %q is not a duration", ErrInvalidScoreWeights, parts[3])
+	}
+
+	weights := ScoreWeights{Reputation: points[0], Sources: points[1], Votes: points[2], HalfLife: halfLife}
+	if err := weights.validate(); err != nil {
+		return ScoreWeights{}, err
+	}
+	return weights, nil
+}
+
+// VoteTally counts the analyst votes on an indicator.
+type VoteTally struct {
+	Up   int `json:"up"`
+	Down int `json:"down"`
+}
+
+// Scorer computes how much an indicator is to be trusted from what its
+// sources reported, the analyst votes and how long ago it was last seen.
+type Scorer struct {
+	weights           map[IndicatorType]ScoreWeights
+	reputation        map[string]int
+	defaultReputation int
+}
+
+// NewScorer returns a scorer with the default weights of the types missing
+// from weights. Reputations range from 0 to 100 and are keyed by source name.
+func NewScorer(weights map[IndicatorType]ScoreWeights, reputation map[string]int, defaultReputation int) (*Scorer, error) {
+	merged := make(map[IndicatorType]ScoreWeights, len(DefaultScoreWeights))
+	for indicatorType, w := range DefaultScoreWeights {
+		merged[indicatorType] = w
+	}
+	for indicatorType, w := range weights {
+		if !indicatorType.IsValid() {
+			return nil, fmt.Errorf("%w: unknown indicator type %q", ErrInvalidScoreWeights, indicatorType)
+		}
+		if err := w.validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", indicatorType, err)
+		}
+		merged[indicatorType] = w
+	}
+
+	for source, r := range reputation {
+		if r < 0 || r > 100 {
+			return nil, fmt.Errorf("%w: reputation of %q must be between 0 and 100", ErrInvalidScoreWeights, source)
+		}
+	}
+	if defaultReputation < 0 || defaultReputation > 100 {
+		return nil, fmt.Errorf("%w: default reputation must be between 0 and 100", ErrInvalidScoreWeights)
+	}
+
+	return &Scorer{weights: merged, reputation: reputation, defaultReputation: defaultReputation}, nil
+}
+
+func (s *Scorer) reputationOf(source string) int {
+	if r, ok := s.reputation[source]; ok {
+		return r
+	}
+	return s.defaultReputation
+}
+
+// Score returns the score of an indicator from 0 to 100. An indicator without
+// sightings, such as one entered by an analyst, counts as sighted once by its
+// own source with its own confidence.
+func (s *Scorer) Score(indicator *Indicator, sightings []*Sighting, votes VoteTally, now time.Time) int {
+	weights, ok := s.weights[indicator.Type]
+	if !ok {
+		return 0
+	}
+	if len(sightings) == 0 {
+		sightings = []*Sighting{{Source: indicator.Source, Confidence: indicator.Confidence}}
+	}
+
+	var reputation float64
+	for _, sighting := range sightings {
+		trust := float64(s.reputationOf(sighting.Source)) * float64(sighting.Confidence) / 10000
+		reputation = math.Max(reputation, trust)
+	}
+	// One source adds nothing, each further one half of what is left.
+	corroboration := 1 - math.Pow(0.5, float64(len(sightings)-1))
+	margin := math.Max(-1, math.Min(1, float64(votes.Up-votes.Down)/fullVoteMargin))
+
+	points := reputation*float64(weights.Reputation) +
+		corroboration*float64(weights.Sources) +
+		margin*float64(weights.Votes)
+	points = math.Max(0, math.Min(100, points))
+
+	if age := now.Sub(indicator.LastSeen); age > 0 {
+		points *= math.Pow(0.5, float64(age)/float64(weights.HalfLife))
+	}
+	return int(math.Round(points))
+}
@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseScoreWeights(t *testing.T) {
+	weights, err := ParseScoreWeights(" 60/25/15/168h ")
+	assert.NoError(t, err)
+	assert.Equal(t, ScoreWeights{Reputation: 60, Sources: 25, Votes: 15, HalfLife: 168 * time.Hour}, weights)
+
+	for _, value := range []string{"60/25/15", "60/25/10/168h", "sixty/25/15/168h", "60/25/15/week", "60/25/15/0s", "120/-5/-15/1h"} {
+		t.Run(value, func(t *testing.T) {
+			_, err := ParseScoreWeights(value)
+			assert.True(t, errors.Is(err, ErrInvalidScoreWeights))
+		})
+	}
+}
+
+func TestNewScorer(t *testing.T) {
+	_, err := NewScorer(map[IndicatorType]ScoreWeights{"mutex": DefaultScoreWeights[IndicatorIPv4]}, nil, 50)
+	assert.True(t, errors.Is(err, ErrInvalidScoreWeights))
+
+	_, err = NewScorer(map[IndicatorType]ScoreWeights{IndicatorURL: {Reputation: 50, HalfLife: time.Hour}}, nil, 50)
+	assert.True(t, errors.Is(err, ErrInvalidScoreWeights))
+
+	_, err = NewScorer(nil, map[string]int{"abuse-ch": 120}, 50)
+	assert.True(t, errors.Is(err, ErrInvalidScoreWeights))
+
+	_, err = NewScorer(nil, nil, -1)
+	assert.True(t, errors.Is(err, ErrInvalidScoreWeights))
+}
+
+func TestScorer_Score(t *testing.T) {
+	scorer, err := NewScorer(nil, map[string]int{"abuse-ch": 90}, DefaultSourceReputation)
+	assert.NoError(t, err)
+	now := time.Now()
+
+	newSighted := func(indicatorType IndicatorType, value string, lastSeen time.Time, sources ...string) (*Indicator, []*Sighting) {
+		indicator, _ := NewIndicator(indicatorType, value, 50, SeverityMedium)
+		indicator.LastSeen = lastSeen
+		var sightings []*Sighting
+		for i, source := range sources {
+			sighting := NewSighting(indicator.ID, source)
+			sighting.Confidence = 100 - 20*i
+			sightings = append(sightings, sighting)
+		}
+		return indicator, sightings
+	}
+
+	t.Run("reputation of the most trusted source", func(t *testing.T) {
+		indicator, sightings := newSighted(IndicatorIPv4, "192.0.2.1", now, "abuse-ch")
+
+		assert.Equal(t, 54, scorer.Score(indicator, sightings, VoteTally{}, now))
+	})
+
+	t.Run("independent sources add up", func(t *testing.T) {
+		indicator, sightings := newSighted(IndicatorIPv4, "192.0.2.1", now, "abuse-ch", "blocklist", "openphish")
+
+		assert.Equal(t, 73, scorer.Score(indicator, sightings, VoteTally{}, now))
+	})
+
+	t.Run("analyst votes", func(t *testing.T) {
+		indicator, sightings := newSighted(IndicatorIPv4, "192.0.2.1", now, "abuse-ch")
+
+		assert.Equal(t, 69, scorer.Score(indicator, sightings, VoteTally{Up: 4}, now))
+		assert.Equal(t, 44, scorer.Score(indicator, sightings, VoteTally{Up: 1, Down: 3}, now))
+	})
+
+	t.Run("decays per type since last seen", func(t *testing.T) {
+		ip, ipSightings := newSighted(IndicatorIPv4, "192.0.2.1", now.Add(-7*24*time.Hour), "abuse-ch")
+		hash, hashSightings := newSighted(IndicatorSHA256, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", now.Add(-7*24*time.Hour), "abuse-ch")
+
+		assert.Equal(t, 27, scorer.Score(ip, ipSightings, VoteTally{}, now))
+		assert.Equal(t, 53, scorer.Score(hash, hashSightings, VoteTally{}, now))
+	})
+
+	t.Run("without sightings the indicator is its own source", func(t *testing.T) {
+		indicator, _ := NewIndicator(IndicatorDomain, "evil.example.com", 80, SeverityHigh)
+		indicator.Source = "manual"
+
+		assert.Equal(t, 24, scorer.Score(indicator, nil, VoteTally{}, indicator.LastSeen))
+	})
+
+	t.Run("stays within 0 and 100", func(t *testing.T) {
+		indicator, _ := NewIndicator(IndicatorIPv4, "192.0.2.1", 0, SeverityLow)
+
+		assert.Equal(t, 0, scorer.Score(indicator, nil, VoteTally{Down: 5}, indicator.LastSeen))
+	})
+
+	t.Run("configured weights", func(t *testing.T) {
+		custom, _ := NewScorer(map[IndicatorType]ScoreWeights{IndicatorIPv4: {Reputation: 100, HalfLife: time.Hour}}, map[string]int{"abuse-ch": 90}, 50)
+		indicator, sightings := newSighted(IndicatorIPv4, "192.0.2.1", now, "abuse-ch", "blocklist")
+
+		assert.Equal(t, 90, custom.Score(indicator, sightings, VoteTally{Up: 3}, now))
+		assert.Equal(t, 45, custom.Score(indicator, sightings, VoteTally{}, now.Add(time.Hour)))
+	})
+}
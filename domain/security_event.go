@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"time"
+	"github.com/google/uuid"
+)
+
+type SecurityEventType string
+
+const (
+	// SecurityEventAccountLocked is recorded when an email is locked after
+	// too many failed logins, whether or not an account exists for it.
+	SecurityEventAccountLocked   SecurityEventType = "account_locked"
+	SecurityEventIPLocked        SecurityEventType = "ip_locked"
+	SecurityEventAccountUnlocked SecurityEventType = "account_unlocked"
+)
+
+func (t SecurityEventType) IsValid() bool {
+	switch t {
+	case SecurityEventAccountLocked, SecurityEventIPLocked, SecurityEventAccountUnlocked:
+		return true
+	}
+	return false
+}
+
+// SecurityEvent is an append-only audit record. UserID is set when the event
+// concerns a known account and ActorID when an admin caused it.
+type SecurityEvent struct {
+	ID        uuid.UUID         `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Type      SecurityEventType `json:"type" gorm:"not null;index"`
+	UserID    *uuid.UUID        `json:"user_id,omitempty" gorm:"type:uuid;index"`
+	ActorID   *uuid.UUID        `json:"actor_id,omitempty" gorm:"type:uuid"`
+	Email     string            `json:"email,omitempty"`
+	IPAddress string            `json:"ip_address,omitempty"`
+	Details   string            `json:"details,omitempty"`
+	CreatedAt time.Time         `json:"created_at" gorm:"index"`
+}
+
+func NewSecurityEvent(eventType SecurityEventType, email, ipAddress, details string) *SecurityEvent {
+	return &SecurityEvent{
+		ID:        uuid.New(),
+		Type:      eventType,
+		Email:     email,
+		IPAddress: ipAddress,
+		Details:   details,
+		CreatedAt: time.Now(),
+	}
+}
+
+type SecurityEventFilter struct {
+	Type   SecurityEventType
+	UserID *uuid.UUID
+	Email  string
+	Offset int
+	Limit  int
+}
+
+type SecurityEventRepository interface {
+	Save(event *SecurityEvent) error
+	List(filter SecurityEventFilter) ([]*SecurityEvent, int64, error)
+}
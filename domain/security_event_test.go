@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityEventType_IsValid(t *testing.T) {
+	assert.True(t, SecurityEventAccountLocked.IsValid())
+	assert.True(t, SecurityEventIPLocked.IsValid())
+	assert.True(t, SecurityEventAccountUnlocked.IsValid())
+	assert.False(t, SecurityEventType("login_failed").IsValid())
+}
+
+func TestNewSecurityEvent(t *testing.T) {
+	event := NewSecurityEvent(SecurityEventIPLocked, "a@example.com", "192.0.2.1", "50 failed logins")
+
+	assert.NotEmpty(t, event.ID)
+	assert.Equal(t, SecurityEventIPLocked, event.Type)
+	assert.Equal(t, "192.0.2.1", event.IPAddress)
+	assert.Nil(t, event.UserID)
+	assert.False(t, event.CreatedAt.IsZero())
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"errors"
+	"time"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRefreshTokenRevoked = errors.New("refresh token revoked")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+)
+
+// RefreshTokenStore tracks refresh token families. Every login starts a new
+// family; each refresh rotates the family to a new token ID so that a token
+// can only be redeemed once. Presenting an already rotated token revokes the
+// whole family.
+type RefreshTokenStore interface {
+	Create(userID, familyID uuid.UUID, tokenID string, ttl time.Duration) error
+	Rotate(userID, familyID uuid.UUID, currentTokenID, nextTokenID string, ttl time.Duration) error
+	RevokeFamily(familyID uuid.UUID) error
+	RevokeUser(userID uuid.UUID) error
+}
+
+// AccessTokenDenylist revokes access tokens before they expire, either one
+// token at a time by ID or every token issued to a user before a point in time.
+type AccessTokenDenylist interface {
+	RevokeToken(tokenID string, expiresAt time.Time) error
+	RevokeUserTokens(userID uuid.UUID, issuedBefore time.Time, ttl time.Duration) error
+	IsRevoked(tokenID string, userID uuid.UUID, issuedAt time.Time) (bool, error)
+}
+
+// OneTimeTokenStore makes signed tokens single-use. Consume reports whether the
+// token ID was seen for the first time; the record is kept until expiresAt.
+type OneTimeTokenStore interface {
+	Consume(tokenID string, expiresAt time.Time) (bool, error)
+}
+
+// AccountTokenStore keeps the one outstanding token of a purpose, such as a
+// password reset, for each user. Saving a token replaces the previous one;
+// Consume accepts only the outstanding token, and only once.
+type AccountTokenStore interface {
+	Save(purpose string, userID uuid.UUID, tokenID string, ttl time.Duration) error
+	Consume(purpose string, userID uuid.UUID, tokenID string) (bool, error)
+}
+
+// LoginAttemptStore counts failed logins and holds temporary locks for a key,
+// such as an email address or a client IP. Failures are forgotten once no
+// new failure happened within the window.
+type LoginAttemptStore interface {
+	RecordFailure(key string, window time.Duration) (int, error)
+	Lock(key string, until time.Time) error
+	LockedUntil(key string) (time.Time, error)
+	Reset(key string) error
+}
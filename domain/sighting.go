@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"time"
+	"github.com/google/uuid"
+)
+
+// MaxSightingPayload bounds the raw payload kept per sighting.
+const MaxSightingPayload = 64 << 10
+
+// Sighting is the provenance of an indicator: one source that reported it,
+// when that source saw it and what it reported last. Sources reporting the
+// indicator again update their sighting rather than adding one.
+type Sighting struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	IndicatorID uuid.UUID `json:"indicator_id" gorm:"type:uuid;not null;uniqueIndex:idx_sighting_indicator_source"`
+	Source      string    `json:"source" gorm:"not null;uniqueIndex:idx_sighting_indicator_source"`
+	Confidence  int       `json:"confidence" gorm:"not null;default:0"`
+	Count       int       `json:"count" gorm:"not null;default:1"`
+	FirstSeen   time.Time `json:"first_seen" gorm:"not null"`
+	LastSeen    time.Time `json:"last_seen" gorm:"not null"`
+	RawPayload  string    `json:"raw_payload" gorm:"type:text"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func NewSighting(indicatorID uuid.UUID, source string) *Sighting {
+	now := time.Now()
+	return &Sighting{
+		ID:          uuid.New(),
+		IndicatorID: indicatorID,
+		Source:      source,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// Record adds a report of the source: the indicator as the source described
+// it and the raw entry it came from, which is truncated to
+// MaxSightingPayload bytes.
+func (s *Sighting) Record(reported *Indicator, rawPayload string) {
+	if s.Count == 0 || reported.FirstSeen.Before(s.FirstSeen) {
+		s.FirstSeen = reported.FirstSeen
+	}
+	if reported.LastSeen.After(s.LastSeen) {
+		s.LastSeen = reported.LastSeen
+	}
+	if len(rawPayload) > MaxSightingPayload {
+		rawPayload = rawPayload[:MaxSightingPayload]
+	}
+
+	s.Count++
+	s.Confidence = reported.Confidence
+	s.RawPayload = rawPayload
+	s.UpdatedAt = time.Now()
+}
+
+type SightingRepository interface {
+	Save(sighting *Sighting) error
+	FindByIndicatorAndSource(indicatorID uuid.UUID, source string) (*Sighting, error)
+	ListByIndicatorID(indicatorID uuid.UUID) ([]*Sighting, error)
+	ListByIndicatorIDs(indicatorIDs []uuid.UUID) ([]*Sighting, error)
+}
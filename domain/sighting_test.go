@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSighting_Record(t *testing.T) {
+	indicator, _ := NewIndicator(IndicatorIPv4, "192.0.2.1", 70, SeverityLow)
+	seenAt := indicator.LastSeen
+	sighting := NewSighting(indicator.ID, "blocklist")
+
+	sighting.Record(indicator, "192.0.2.1 # ssh")
+
+	assert.Equal(t, 1, sighting.Count)
+	assert.Equal(t, 70, sighting.Confidence)
+	assert.Equal(t, seenAt, sighting.FirstSeen)
+	assert.Equal(t, seenAt, sighting.LastSeen)
+	assert.Equal(t, "192.0.2.1 # ssh", sighting.RawPayload)
+
+	t.Run("a later report widens the window", func(t *testing.T) {
+		reported, _ := NewIndicator(IndicatorIPv4, "192.0.2.1", 40, SeverityLow)
+		reported.FirstSeen = seenAt.Add(time.Hour)
+		reported.LastSeen = seenAt.Add(time.Hour)
+
+		sighting.Record(reported, "192.0.2.1")
+
+		assert.Equal(t, 2, sighting.Count)
+		assert.Equal(t, 40, sighting.Confidence)
+		assert.Equal(t, seenAt, sighting.FirstSeen)
+		assert.Equal(t, seenAt.Add(time.Hour), sighting.LastSeen)
+		assert.Equal(t, "192.0.2.1", sighting.RawPayload)
+	})
+
+	t.Run("truncates large payloads", func(t *testing.T) {
+		sighting.Record(indicator, strings.Repeat("x", MaxSightingPayload+1))
+
+		assert.Len(t, sighting.RawPayload, MaxSightingPayload)
+	})
+}
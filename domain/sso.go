@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"time"
+	"github.com/google/uuid"
+)
+
+// SSOLoginState is what the service keeps between sending a user to the
+// identity provider and the provider sending them back. The code verifier
+// never leaves the server, which is what makes PKCE effective.
+type SSOLoginState struct {
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+// SSOStateStore holds pending SSO logins by their state parameter. Consume
+// returns nil for unknown or expired states and deletes the state, so that a
+// callback can only be redeemed once.
+type SSOStateStore interface {
+	Save(state string, login *SSOLoginState, ttl time.Duration) error
+	Consume(state string) (*SSOLoginState, error)
+}
+
+// ExternalIdentity links the subject of an identity provider, identified by
+// its issuer, to a local user.
+type ExternalIdentity struct {
+	Provider    string    `json:"provider" gorm:"primaryKey"`
+	Subject     string    `json:"subject" gorm:"primaryKey"`
+	UserID      uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	Email       string    `json:"email"`
+	LastLoginAt time.Time `json:"last_login_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type ExternalIdentityRepository interface {
+	Save(identity *ExternalIdentity) error
+	FindBySubject(provider, subject string) (*ExternalIdentity, error)
+	DeleteByUserID(userID uuid.UUID) error
+}
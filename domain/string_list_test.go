@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStringList_ValueScan(t *testing.T) {
+	value, err := StringList{"a", "b"}.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, `["a","b"]`, value)
+
+	value, err = StringList(nil).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, `[]`, value)
+
+	var list StringList
+	assert.NoError(t, list.Scan(`["c"]`))
+	assert.Equal(t, StringList{"c"}, list)
+
+	assert.NoError(t, list.Scan(nil))
+	assert.Equal(t, StringList{}, list)
+
+	assert.Error(t, list.Scan(42))
+}
@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidStixID       = errors.New("invalid STIX identifier")
+	ErrInvalidThreatEntity = errors.New("invalid threat entity")
+	ErrInvalidRelationship = errors.New("invalid relationship")
+)
+
+// NewStixID builds a STIX 2.1 identifier ("<type>--<uuid>") for an object.
+func NewStixID(objectType string, id uuid.UUID) string {
+	return objectType + "--" + id.String()
+}
+
+// ParseStixID splits a STIX identifier into its object type and UUID.
+func ParseStixID(stixID string) (string, uuid.UUID, error) {
+	objectType, rawID, found := strings.Cut(stixID, "--")
+	if !found || objectType == "" {
+		return "", uuid.Nil, ErrInvalidStixID
+	}
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return "", uuid.Nil, ErrInvalidStixID
+	}
+	return objectType, id, nil
+}
+
+type ThreatEntityType string
+
+const (
+	ThreatEntityMalware     ThreatEntityType = "malware"
+	ThreatEntityThreatActor ThreatEntityType = "threat-actor"
+)
+
+func (t ThreatEntityType) IsValid() bool {
+	switch t {
+	case ThreatEntityMalware, ThreatEntityThreatActor:
+		return true
+	}
+	return false
+}
+
+// ThreatEntity is a named adversary artefact, such as a malware family or a
+// threat actor, that indicators can be related to.
+type ThreatEntity struct {
+	ID          uuid.UUID        `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	StixID      string           `json:"stix_id" gorm:"uniqueIndex;not null"`
+	Type        ThreatEntityType `json:"type" gorm:"not null;index"`
+	Name        string           `json:"name" gorm:"not null"`
+	Description string           `json:"description"`
+	Aliases     Tags             `json:"aliases" gorm:"type:jsonb;not null;default:'[]'"`
+	Labels      Tags             `json:"labels" gorm:"type:jsonb;not null;default:'[]'"`
+	IsFamily    bool             `json:"is_family"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+func NewThreatEntity(entityType ThreatEntityType, stixID, name string) (*ThreatEntity, error) {
+	if !entityType.IsValid() || strings.TrimSpace(name) == "" {
+		return nil, ErrInvalidThreatEntity
+	}
+	if objectType, _, err := ParseStixID(stixID); err != nil || objectType != string(entityType) {
+		return nil, ErrInvalidStixID
+	}
+
+	now := time.Now()
+	return &ThreatEntity{
+		ID:        uuid.New(),
+		StixID:    stixID,
+		Type:      entityType,
+		Name:      strings.TrimSpace(name),
+		Aliases:   Tags{},
+		Labels:    Tags{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// Relationship links two STIX objects, e.g. an indicator that "indicates" a
+// malware family. The refs are STIX identifiers so that relationships can be
+// stored before or without their endpoints.
+type Relationship struct {
+	ID               uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	StixID           string    `json:"stix_id" gorm:"uniqueIndex;not null"`
+	RelationshipType string    `json:"relationship_type" gorm:"not null"`
+	SourceRef        string    `json:"source_ref" gorm:"not null;index"`
+	TargetRef        string    `json:"target_ref" gorm:"not null;index"`
+	Description      string    `json:"description"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+func NewRelationship(stixID, relationshipType, sourceRef, targetRef string) (*Relationship, error) {
+	if objectType, _, err := ParseStixID(stixID); err != nil || objectType != "relationship" {
+		return nil, ErrInvalidStixID
+	}
+	if strings.TrimSpace(relationshipType) == "" {
+		return nil, ErrInvalidRelationship
+	}
+	for _, ref := range []string{sourceRef, targetRef} {
+		if _, _, err := ParseStixID(ref); err != nil {
+			return nil, ErrInvalidRelationship
+		}
+	}
+
+	now := time.Now()
+	return &Relationship{
+		ID:               uuid.New(),
+		StixID:           stixID,
+		RelationshipType: relationshipType,
+		SourceRef:        sourceRef,
+		TargetRef:        targetRef,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}, nil
+}
+
+type ThreatEntityRepository interface {
+	Save(entity *ThreatEntity) error
+	FindByStixID(stixID string) (*ThreatEntity, error)
+	FindByStixIDs(stixIDs []string) ([]*ThreatEntity, error)
+}
+
+type RelationshipRepository interface {
+	Save(relationship *Relationship) error
+	FindByStixID(stixID string) (*Relationship, error)
+	FindBySourceRefs(sourceRefs []string) ([]*Relationship, error)
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStixID(t *testing.T) {
+	id := uuid.New()
+
+	objectType, parsed, err := ParseStixID(NewStixID("malware", id))
+	assert.NoError(t, err)
+	assert.Equal(t, "malware", objectType)
+	assert.Equal(t, id, parsed)
+
+	for _, invalid := range []string{"", "malware", "--" + id.String(), "malware--not-a-uuid"} {
+		_, _, err := ParseStixID(invalid)
+		assert.Equal(t, ErrInvalidStixID, err, invalid)
+	}
+}
+
+func TestNewThreatEntity(t *testing.T) {
+	stixID := NewStixID("threat-actor", uuid.New())
+
+	entity, err := NewThreatEntity(ThreatEntityThreatActor, stixID, " Example Panda ")
+	assert.NoError(t, err)
+	assert.Equal(t, "Example Panda", entity.Name)
+	assert.Equal(t, stixID, entity.StixID)
+
+	_, err = NewThreatEntity(ThreatEntityMalware, stixID, "ExampleRAT")
+	assert.Equal(t, ErrInvalidStixID, err)
+
+	_, err = NewThreatEntity(ThreatEntityThreatActor, stixID, " ")
+	assert.Equal(t, ErrInvalidThreatEntity, err)
+}
+
+func TestNewRelationship(t *testing.T) {
+	source := NewStixID("indicator", uuid.New())
+	target := NewStixID("malware", uuid.New())
+
+	relationship, err := NewRelationship(NewStixID("relationship", uuid.New()), "indicates", source, target)
+	assert.NoError(t, err)
+	assert.Equal(t, source, relationship.SourceRef)
+
+	_, err = NewRelationship(NewStixID("relationship", uuid.New()), "indicates", source, "malware")
+	assert.Equal(t, ErrInvalidRelationship, err)
+
+	_, err = NewRelationship(source, "indicates", source, target)
+	assert.Equal(t, ErrInvalidStixID, err)
+}
@@ -0,0 +1,67 @@
+package domain
+
+// Tier is the level of intel access a customer has purchased.
+type Tier string
+
+const (
+	TierNone       Tier = ""
+	TierBasic      Tier = "basic"
+	TierPremium    Tier = "premium"
+	TierEnterprise Tier = "enterprise"
+)
+
+var tierRanks = map[Tier]int{
+	TierNone:       0,
+	TierBasic:      1,
+	TierPremium:    2,
+	TierEnterprise: 3,
+}
+
+var (
+	networkIndicatorTypes = []IndicatorType{IndicatorIPv4, IndicatorIPv6, IndicatorDomain, IndicatorURL}
+	hashIndicatorTypes    = []IndicatorType{IndicatorMD5, IndicatorSHA1, IndicatorSHA256}
+)
+
+// tierIndicatorTypes lists the indicator types readable with each tier.
+// Enterprise is absent because it can read every type.
+var tierIndicatorTypes = map[Tier][]IndicatorType{
+	TierNone:    {},
+	TierBasic:   networkIndicatorTypes,
+	TierPremium: append(append([]IndicatorType{}, networkIndicatorTypes...), hashIndicatorTypes...),
+}
+
+func (t Tier) IsValid() bool {
+	switch t {
+	case TierBasic, TierPremium, TierEnterprise:
+		return true
+	}
+	return false
+}
+
+// Includes reports whether t grants at least the access of required.
+func (t Tier) Includes(required Tier) bool {
+	return tierRanks[t] >= tierRanks[required]
+}
+
+// IndicatorTypes returns the indicator types readable with the tier, or nil
+// when every type is.
+func (t Tier) IndicatorTypes() []IndicatorType {
+	types, ok := tierIndicatorTypes[t]
+	if !ok {
+		return nil
+	}
+	return types
+}
+
+func (t Tier) AllowsIndicatorType(indicatorType IndicatorType) bool {
+	types := t.IndicatorTypes()
+	if types == nil {
+		return true
+	}
+	for _, allowed := range types {
+		if allowed == indicatorType {
+			return true
+		}
+	}
+	return false
+}
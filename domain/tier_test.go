@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTier_Includes(t *testing.T) {
+	assert.True(t, TierEnterprise.Includes(TierPremium))
+	assert.True(t, TierBasic.Includes(TierBasic))
+	assert.True(t, TierBasic.Includes(TierNone))
+	assert.False(t, TierBasic.Includes(TierPremium))
+	assert.False(t, TierNone.Includes(TierBasic))
+}
+
+func TestTier_AllowsIndicatorType(t *testing.T) {
+	assert.True(t, TierBasic.AllowsIndicatorType(IndicatorDomain))
+	assert.False(t, TierBasic.AllowsIndicatorType(IndicatorSHA256))
+	assert.True(t, TierPremium.AllowsIndicatorType(IndicatorSHA256))
+	assert.False(t, TierPremium.AllowsIndicatorType(IndicatorEmail))
+	assert.True(t, TierEnterprise.AllowsIndicatorType(IndicatorEmail))
+	assert.False(t, TierNone.AllowsIndicatorType(IndicatorIPv4))
+	assert.Nil(t, TierEnterprise.IndicatorTypes())
+}
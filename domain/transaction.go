@@ -0,0 +1,23 @@
+package domain
+
+// Repositories are the repositories bound to one transaction. Only the
+// repositories of work that has to be atomic are listed.
+type Repositories struct {
+	Indicators         IndicatorRepository
+	Sightings          SightingRepository
+	ThreatEntities     ThreatEntityRepository
+	Relationships      RelationshipRepository
+	Orders             OrderRepository
+	Entitlements       EntitlementRepository
+	Users              UserRepository
+	MFAFactors         MFAFactorRepository
+	APIKeys            APIKeyRepository
+	ExternalIdentities ExternalIdentityRepository
+}
+
+// Transactor runs fn in a database transaction, handing it repositories that
+// read and write within it. The transaction commits when fn returns nil and
+// rolls back otherwise.
+type Transactor interface {
+	Transaction(fn func(repos Repositories) error) error
+}
@@ -1,11 +1,15 @@
 package domain
 
 import (
+	"errors"
 	"time"
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 )
 
+// ErrUserHasOrders is returned when deleting a user who still owns orders;
+// orders are kept for accounting, so such users can only be deactivated.
+var ErrUserHasOrders = errors.New("user has orders and cannot be deleted; deactivate the account instead")
+
 type UserRole string
 
 const (
@@ -14,18 +18,33 @@ const (
 	RoleViewer  UserRole = "viewer"
 )
 
+func (r UserRole) IsValid() bool {
+	switch r {
+	case RoleAdmin, RoleAnalyst, RoleViewer:
+		return true
+	}
+	return false
+}
+
+// User is a person who can sign in. A user who registered themselves is
+// pending verification and stays inactive until they verify their email
+// address; IsActive has no column default so that such users are stored as
+// inactive.
 type User struct {
-	ID           uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	Email        string    `json:"email" gorm:"uniqueIndex;not null"`
-	PasswordHash string    `json:"-" gorm:"not null"`
-	Role         UserRole  `json:"role" gorm:"not null;default:'viewer'"`
-	IsActive     bool      `json:"is_active" gorm:"default:true"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID                  uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Email               string     `json:"email" gorm:"uniqueIndex;not null"`
+	Name                string     `json:"name" gorm:"not null;default:''"`
+	PasswordHash        string     `json:"-" gorm:"not null"`
+	Role                UserRole   `json:"role" gorm:"not null;default:'viewer'"`
+	IsActive            bool       `json:"is_active" gorm:"not null"`
+	EmailVerifiedAt     *time.Time `json:"email_verified_at,omitempty"`
+	PendingVerification bool       `json:"-" gorm:"not null;default:false"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
 }
 
 func NewUser(email, password string, role UserRole) (*User, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashedPassword, err := passwordHashing.Hash(password)
 	if err != nil {
 		return nil, err
 	}
@@ -33,7 +52,7 @@ func NewUser(email, password string, role UserRole) (*User, error) {
 	return &User{
 		ID:           uuid.New(),
 		Email:        email,
-		PasswordHash: string(hashedPassword),
+		PasswordHash: hashedPassword,
 		Role:         role,
 		IsActive:     true,
 		CreatedAt:    time.Now(),
@@ -41,9 +60,69 @@ func NewUser(email, password string, role UserRole) (*User, error) {
 	}, nil
 }
 
+// NewPendingUser creates a self-registered user, who stays inactive until
+// they verify their email address.
+func NewPendingUser(email, password string, role UserRole) (*User, error) {
+	user, err := NewUser(email, password, role)
+	if err != nil {
+		return nil, err
+	}
+	user.IsActive = false
+	user.PendingVerification = true
+	return user, nil
+}
+
+// NewSSOUser creates a user provisioned by single sign-on. It has no
+// password, so it can only log in through the identity provider.
+func NewSSOUser(email string, role UserRole) *User {
+	return &User{
+		ID:        uuid.New(),
+		Email:     email,
+		Role:      role,
+		IsActive:  true,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
 func (u *User) ValidatePassword(password string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password))
-	return err == nil
+	return VerifyPassword(u.PasswordHash, password)
+}
+
+// SetPassword replaces the password hash.
+func (u *User) SetPassword(password string) error {
+	hashedPassword, err := passwordHashing.Hash(password)
+	if err != nil {
+		return err
+	}
+	u.PasswordHash = hashedPassword
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// PasswordNeedsRehash reports whether the password hash is weaker than the
+// configured hashing, so that it should be replaced while the password is
+// known.
+func (u *User) PasswordNeedsRehash() bool {
+	return u.PasswordHash != "" && passwordHashing.NeedsRehash(u.PasswordHash)
+}
+
+// AwaitingVerification reports whether the user registered and has not yet
+// verified their email. Invited, SSO and deactivated users never are.
+func (u *User) AwaitingVerification() bool {
+	return u.PendingVerification
+}
+
+// VerifyEmail records that the user proved they own their email address,
+// and activates a user who was awaiting it.
+func (u *User) VerifyEmail() {
+	if u.PendingVerification {
+		u.IsActive = true
+		u.PendingVerification = false
+	}
+	now := time.Now()
+	u.EmailVerifiedAt = &now
+	u.UpdatedAt = now
 }
 
 func (u *User) HasPermission(requiredRole UserRole) bool {
@@ -53,4 +132,38 @@ func (u *User) HasPermission(requiredRole UserRole) bool {
 		RoleAdmin:   3,
 	}
 	return roleHierarchy[u.Role] >= roleHierarchy[requiredRole]
+}
+
+// Rename sets the display name the user chose.
+func (u *User) Rename(name string) {
+	u.Name = name
+	u.UpdatedAt = time.Now()
+}
+
+func (u *User) ChangeRole(role UserRole) {
+	u.Role = role
+	u.UpdatedAt = time.Now()
+}
+
+// Activate and Deactivate settle a pending verification: an admin decision
+// is not undone by the user verifying their email afterwards.
+func (u *User) Activate() {
+	u.IsActive = true
+	u.PendingVerification = false
+	u.UpdatedAt = time.Now()
+}
+
+func (u *User) Deactivate() {
+	u.IsActive = false
+	u.PendingVerification = false
+	u.UpdatedAt = time.Now()
+}
+
+// UserFilter narrows down user listings. Zero values match everything.
+type UserFilter struct {
+	Role        UserRole
+	IsActive    *bool
+	EmailPrefix string
+	Offset      int
+	Limit       int
 }
\ No newline at end of file
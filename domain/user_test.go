@@ -2,6 +2,7 @@ package domain
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -28,6 +29,72 @@ func TestNewUser(t *testing.T) {
 	})
 }
 
+func TestNewSSOUser(t *testing.T) {
+	user := NewSSOUser("sso@example.com", RoleAnalyst)
+
+	assert.Equal(t, RoleAnalyst, user.Role)
+	assert.True(t, user.IsActive)
+	assert.Empty(t, user.PasswordHash)
+	assert.False(t, user.ValidatePassword(""))
+}
+
+func TestNewPendingUser(t *testing.T) {
+	user, err := NewPendingUser("new@example.com", "password123", RoleViewer)
+
+	assert.NoError(t, err)
+	assert.False(t, user.IsActive)
+	assert.True(t, user.AwaitingVerification())
+	assert.True(t, user.ValidatePassword("password123"))
+}
+
+func TestUser_VerifyEmail(t *testing.T) {
+	t.Run("activates a pending user", func(t *testing.T) {
+		user, _ := NewPendingUser("new@example.com", "password123", RoleViewer)
+
+		user.VerifyEmail()
+
+		assert.True(t, user.IsActive)
+		assert.NotNil(t, user.EmailVerifiedAt)
+		assert.False(t, user.AwaitingVerification())
+	})
+
+	t.Run("does not reactivate a deactivated user", func(t *testing.T) {
+		user, _ := NewUser("user@example.com", "password123", RoleViewer)
+		user.VerifyEmail()
+		user.Deactivate()
+
+		assert.False(t, user.AwaitingVerification())
+		user.VerifyEmail()
+		assert.False(t, user.IsActive)
+	})
+
+	t.Run("does not activate an invited user an admin deactivated", func(t *testing.T) {
+		user, _ := NewUser("invited@example.com", "password123", RoleAnalyst)
+		user.Deactivate()
+
+		assert.False(t, user.AwaitingVerification())
+		user.VerifyEmail()
+		assert.False(t, user.IsActive)
+	})
+
+	t.Run("does not activate a pending user an admin deactivated", func(t *testing.T) {
+		user, _ := NewPendingUser("new@example.com", "password123", RoleViewer)
+		user.Deactivate()
+
+		assert.False(t, user.AwaitingVerification())
+		user.VerifyEmail()
+		assert.False(t, user.IsActive)
+	})
+}
+
+func TestUser_SetPassword(t *testing.T) {
+	user, _ := NewUser("test@example.com", "password123", RoleViewer)
+
+	assert.NoError(t, user.SetPassword("newpassword456"))
+	assert.True(t, user.ValidatePassword("newpassword456"))
+	assert.False(t, user.ValidatePassword("password123"))
+}
+
 func TestUser_ValidatePassword(t *testing.T) {
 	user, _ := NewUser("test@example.com", "password123", RoleViewer)
 
@@ -66,4 +133,44 @@ func TestUser_HasPermission(t *testing.T) {
 			assert.Equal(t, tt.expected, result)
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestUserRole_IsValid(t *testing.T) {
+	assert.True(t, RoleAdmin.IsValid())
+	assert.True(t, RoleAnalyst.IsValid())
+	assert.True(t, RoleViewer.IsValid())
+	assert.False(t, UserRole("superuser").IsValid())
+	assert.False(t, UserRole("").IsValid())
+}
+
+func TestUser_Rename(t *testing.T) {
+	user, _ := NewUser("test@example.com", "password123", RoleViewer)
+	originalUpdatedAt := user.UpdatedAt
+
+	time.Sleep(1 * time.Millisecond)
+	user.Rename("Ada Lovelace")
+
+	assert.Equal(t, "Ada Lovelace", user.Name)
+	assert.True(t, user.UpdatedAt.After(originalUpdatedAt))
+}
+
+func TestUser_ChangeRole(t *testing.T) {
+	user, _ := NewUser("test@example.com", "password123", RoleViewer)
+	originalUpdatedAt := user.UpdatedAt
+
+	time.Sleep(1 * time.Millisecond)
+	user.ChangeRole(RoleAnalyst)
+
+	assert.Equal(t, RoleAnalyst, user.Role)
+	assert.True(t, user.UpdatedAt.After(originalUpdatedAt))
+}
+
+func TestUser_ActivateDeactivate(t *testing.T) {
+	user, _ := NewUser("test@example.com", "password123", RoleViewer)
+
+	user.Deactivate()
+	assert.False(t, user.IsActive)
+
+	user.Activate()
+	assert.True(t, user.IsActive)
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"errors"
+	"time"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidVote = errors.New("vote must be 1 or -1")
+
+// IndicatorVote is the verdict of an analyst on an indicator: 1 when it is
+// malicious, -1 when it is a false positive. Analysts have one vote per
+// indicator and voting again replaces it.
+type IndicatorVote struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	IndicatorID uuid.UUID `json:"indicator_id" gorm:"type:uuid;not null;uniqueIndex:idx_vote_indicator_user"`
+	UserID      uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_vote_indicator_user"`
+	Value       int       `json:"value" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func NewIndicatorVote(indicatorID, userID uuid.UUID, value int) (*IndicatorVote, error) {
+	now := time.Now()
+	vote := &IndicatorVote{
+		ID:          uuid.New(),
+		IndicatorID: indicatorID,
+		UserID:      userID,
+		CreatedAt:   now,
+	}
+	if err := vote.Change(value); err != nil {
+		return nil, err
+	}
+	return vote, nil
+}
+
+func (v *IndicatorVote) Change(value int) error {
+	if value != 1 && value != -1 {
+		return ErrInvalidVote
+	}
+	v.Value = value
+	v.UpdatedAt = time.Now()
+	return nil
+}
+
+type IndicatorVoteRepository interface {
+	Save(vote *IndicatorVote) error
+	FindByIndicatorAndUser(indicatorID, userID uuid.UUID) (*IndicatorVote, error)
+	Delete(indicatorID, userID uuid.UUID) error
+	TallyByIndicatorIDs(indicatorIDs []uuid.UUID) (map[uuid.UUID]VoteTally, error)
+}
@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewIndicatorVote(t *testing.T) {
+	vote, err := NewIndicatorVote(uuid.New(), uuid.New(), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, -1, vote.Value)
+
+	assert.NoError(t, vote.Change(1))
+	assert.Equal(t, 1, vote.Value)
+
+	assert.Equal(t, ErrInvalidVote, vote.Change(2))
+	_, err = NewIndicatorVote(uuid.New(), uuid.New(), 0)
+	assert.Equal(t, ErrInvalidVote, err)
+}
@@ -0,0 +1,211 @@
+package feeds
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"threat-intel-backend/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// serve answers every request with body, honouring If-None-Match for etag.
+func serve(t *testing.T, body, etag string) (*httptest.Server, *[]*http.Request) {
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		if etag != "" {
+			w.Header().Set("ETag", etag)
+			if r.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		w.Header().Set("Last-Modified", "Mon, 01 Jan 2024 00:00:00 GMT")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+// pulled is what a pull emitted.
+type pulled struct {
+	indicators []*domain.Indicator
+	payloads   []string
+}
+
+func pull(t *testing.T, source domain.FeedSource, feed *domain.Feed) ([]*domain.Indicator, *domain.FeedPullResult, error) {
+	emitted, result, err := pullPayloads(t, source, feed)
+	return emitted.indicators, result, err
+}
+
+func pullPayloads(t *testing.T, source domain.FeedSource, feed *domain.Feed) (*pulled, *domain.FeedPullResult, error) {
+	emitted := &pulled{}
+	result, err := source.Pull(context.Background(), feed, func(indicator *domain.Indicator, rawPayload string) error {
+		emitted.indicators = append(emitted.indicators, indicator)
+		emitted.payloads = append(emitted.payloads, rawPayload)
+		return nil
+	})
+	return emitted, result, err
+}
+
+func values(indicators []*domain.Indicator) []string {
+	values := make([]string, 0, len(indicators))
+	for _, indicator := range indicators {
+		values = append(values, indicator.Value)
+	}
+	return values
+}
+
+func TestHTTPSource_Caching(t *testing.T) {
+	server, requests := serve(t, "192.0.2.1\n", `"v1"`)
+	feed := &domain.Feed{URL: server.URL}
+	source := NewPlainTextSource(server.Client())
+
+	indicators, result, err := pull(t, source, feed)
+	assert.NoError(t, err)
+	assert.Len(t, indicators, 1)
+	assert.Equal(t, `"v1"`, result.ETag)
+	assert.Equal(t, "Mon, 01 Jan 2024 00:00:00 GMT", result.LastModified)
+
+	feed.ETag, feed.LastModified = result.ETag, result.LastModified
+	indicators, result, err = pull(t, source, feed)
+	assert.NoError(t, err)
+	assert.True(t, result.NotModified)
+	assert.Empty(t, indicators)
+	assert.Equal(t, "Mon, 01 Jan 2024 00:00:00 GMT", (*requests)[1].Header.Get("If-Modified-Since"))
+}
+
+func TestHTTPSource_Errors(t *testing.T) {
+	t.Run("status", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+
+		_, _, err := pull(t, NewCSVSource(server.Client()), &domain.Feed{URL: server.URL})
+
+		assert.True(t, errors.Is(err, ErrFetch))
+	})
+
+	t.Run("emit failure stops the pull", func(t *testing.T) {
+		server, _ := serve(t, "192.0.2.1\n192.0.2.2\n", "")
+		failure := errors.New("database unavailable")
+		calls := 0
+
+		_, err := NewPlainTextSource(server.Client()).Pull(context.Background(), &domain.Feed{URL: server.URL}, func(*domain.Indicator, string) error {
+			calls++
+			return failure
+		})
+
+		assert.Equal(t, failure, err)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestPlainTextSource(t *testing.T) {
+	server, _ := serve(t, "# Scanners\n192.0.2.1 # ssh\n\n; legacy\n2001:DB8::1\nEVIL.example.com\nnot a value\n", "")
+	feed := &domain.Feed{URL: server.URL, Options: domain.FeedOptions{Confidence: 80, Tags: []string{"Scanner"}}}
+
+	emitted, result, err := pullPayloads(t, NewPlainTextSource(server.Client()), feed)
+	indicators := emitted.indicators
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"192.0.2.1", "2001:db8::1", "evil.example.com"}, values(indicators))
+	assert.Equal(t, "192.0.2.1 # ssh", emitted.payloads[0])
+	assert.Equal(t, domain.IndicatorIPv6, indicators[1].Type)
+	assert.Equal(t, 80, indicators[0].Confidence)
+	assert.Equal(t, domain.Tags{"scanner"}, indicators[0].Tags)
+	assert.Equal(t, 1, result.Skipped)
+	assert.Contains(t, result.Errors[0], "line 7")
+}
+
+func TestCSVSource(t *testing.T) {
+	server, _ := serve(t, "id,url,status\n1,http://evil.example.com/a,online\n2,\"http://evil.example.com/b,c\",offline\n# note\n3\n", "")
+	feed := &domain.Feed{URL: server.URL, Options: domain.FeedOptions{Type: domain.IndicatorURL, Column: 1, SkipHeader: true}}
+
+	emitted, result, err := pullPayloads(t, NewCSVSource(server.Client()), feed)
+	indicators := emitted.indicators
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://evil.example.com/a", "http://evil.example.com/b,c"}, values(indicators))
+	assert.Equal(t, `2,"http://evil.example.com/b,c",offline`, emitted.payloads[1])
+	assert.Equal(t, 50, indicators[0].Confidence)
+	assert.Equal(t, 1, result.Skipped)
+}
+
+func TestJSONSource(t *testing.T) {
+	t.Run("objects at a path", func(t *testing.T) {
+		server, _ := serve(t, `{"data":{"items":[{"sha256":"E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855"},{"sha256":null},{"md5":"x"}]}}`, "")
+		feed := &domain.Feed{URL: server.URL, Options: domain.FeedOptions{ItemsPath: "data.items", ValueField: "sha256", Severity: domain.SeverityHigh}}
+
+		emitted, result, err := pullPayloads(t, NewJSONSource(server.Client()), feed)
+		indicators := emitted.indicators
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855"}, values(indicators))
+		assert.Equal(t, []string{`{"sha256":"E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855"}`}, emitted.payloads)
+		assert.Equal(t, domain.SeverityHigh, indicators[0].Severity)
+		assert.Equal(t, 2, result.Skipped)
+	})
+
+	t.Run("array of values", func(t *testing.T) {
+		server, _ := serve(t, `["192.0.2.1","phish@example.com"]`, "")
+
+		indicators, _, err := pull(t, NewJSONSource(server.Client()), &domain.Feed{URL: server.URL})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"192.0.2.1", "phish@example.com"}, values(indicators))
+	})
+
+	t.Run("no array", func(t *testing.T) {
+		server, _ := serve(t, `{"data":[]}`, "")
+		feed := &domain.Feed{URL: server.URL, Options: domain.FeedOptions{ItemsPath: "items"}}
+
+		_, _, err := pull(t, NewJSONSource(server.Client()), feed)
+
+		assert.True(t, errors.Is(err, ErrInvalidDocument))
+	})
+}
+
+func TestSTIXSource(t *testing.T) {
+	bundle, err := os.ReadFile("../stix/testdata/hashes.json")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	server, _ := serve(t, string(bundle), "")
+
+	emitted, result, err := pullPayloads(t, NewSTIXSource(server.Client()), &domain.Feed{URL: server.URL})
+	indicators := emitted.indicators
+
+	assert.NoError(t, err)
+	assert.Len(t, indicators, 4)
+	assert.Equal(t, "indicator--2b7c8d9e-0f1a-4b2c-8d3e-4f5a6b7c8d9e", indicators[0].StixID)
+	assert.Contains(t, emitted.payloads[0], `"id":"indicator--2b7c8d9e-0f1a-4b2c-8d3e-4f5a6b7c8d9e"`)
+	assert.Equal(t, 2, result.Skipped)
+}
+
+func TestMISPSource(t *testing.T) {
+	server := httptest.NewServer(http.FileServer(http.Dir("../misp/testdata/feed")))
+	defer server.Close()
+	feed := &domain.Feed{Name: "circl-osint", URL: server.URL}
+	source := NewMISPSource(server.Client())
+
+	emitted, result, err := pullPayloads(t, source, feed)
+	assert.NoError(t, err)
+	assert.Len(t, emitted.indicators, 6)
+	assert.Equal(t, "circl-osint", emitted.indicators[0].Source)
+	for _, payload := range emitted.payloads {
+		assert.Contains(t, payload, `"to_ids":`)
+	}
+	assert.Equal(t, "1706745600", result.Cursor)
+	assert.Equal(t, 1, result.Skipped)
+
+	feed.Cursor = result.Cursor
+	indicators, result, err := pull(t, source, feed)
+	assert.NoError(t, err)
+	assert.True(t, result.NotModified)
+	assert.Empty(t, indicators)
+	assert.Equal(t, "1706745600", result.Cursor)
+}
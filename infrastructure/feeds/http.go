@@ -0,0 +1,110 @@
+// Package feeds implements the sources the feed scheduler pulls indicators
+// from: plain-text, CSV and JSON lists and STIX bundles fetched over HTTP,
+// and MISP feeds.
+package feeds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"threat-intel-backend/domain"
+)
+
+// maxDocumentSize bounds the documents read from a feed.
+const maxDocumentSize = 128 << 20
+
+var ErrFetch = errors.New("feed fetch failed")
+
+// parser reads the entries of a document and passes their indicators to
+// emit. Entries that cannot be converted are recorded in the result.
+type parser func(body io.Reader, options domain.FeedOptions, emit domain.FeedEmitFunc, result *domain.FeedPullResult) error
+
+// HTTPSource pulls feeds that are a single document at their URL. It sends
+// the ETag and Last-Modified of the previous pull, so that unchanged
+// documents are not downloaded again.
+type HTTPSource struct {
+	client *http.Client
+	parse  parser
+}
+
+func NewPlainTextSource(client *http.Client) *HTTPSource {
+	return &HTTPSource{client: client, parse: parsePlainText}
+}
+
+func NewCSVSource(client *http.Client) *HTTPSource {
+	return &HTTPSource{client: client, parse: parseCSV}
+}
+
+func NewJSONSource(client *http.Client) *HTTPSource {
+	return &HTTPSource{client: client, parse: parseJSON}
+}
+
+func NewSTIXSource(client *http.Client) *HTTPSource {
+	return &HTTPSource{client: client, parse: parseSTIX}
+}
+
+func (s *HTTPSource) Pull(ctx context.Context, feed *domain.Feed, emit domain.FeedEmitFunc) (*domain.FeedPullResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feed.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if feed.ETag != "" {
+		req.Header.Set("If-None-Match", feed.ETag)
+	}
+	if feed.LastModified != "" {
+		req.Header.Set("If-Modified-Since", feed.LastModified)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFetch, err)
+	}
+	defer resp.Body.Close()
+
+	result := &domain.FeedPullResult{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		result.NotModified = true
+		return result, nil
+	default:
+		return nil, fmt.Errorf("%w: GET %s: status %d", ErrFetch, feed.URL, resp.StatusCode)
+	}
+
+	if err := s.parse(io.LimitReader(resp.Body, maxDocumentSize), feed.Options, emit, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// newIndicator builds the indicator of a value of a list feed with the
+// defaults of the feed.
+func newIndicator(value string, options domain.FeedOptions) (*domain.Indicator, error) {
+	indicatorType := options.Type
+	if indicatorType == "" {
+		indicatorType = domain.DetectIndicatorType(value)
+	}
+	confidence := options.Confidence
+	if confidence == 0 {
+		confidence = 50
+	}
+
+	indicator, err := domain.NewIndicator(indicatorType, value, confidence, options.Severity)
+	if err != nil {
+		return nil, err
+	}
+	indicator.Tags = domain.NormalizeTags(options.Tags)
+	return indicator, nil
+}
+
+func skip(result *domain.FeedPullResult, entry string, err error) {
+	result.Skipped++
+	if len(result.Errors) < domain.MaxFeedRunErrors {
+		result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", entry, err))
+	}
+}
@@ -0,0 +1,81 @@
+package feeds
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+	"threat-intel-backend/domain"
+	"threat-intel-backend/infrastructure/misp"
+	"github.com/google/uuid"
+)
+
+// MISPSource pulls MISP feeds incrementally: the cursor of a feed is the
+// newest event timestamp pulled, and only events of the manifest that
+// changed after it are read.
+type MISPSource struct {
+	client *http.Client
+}
+
+func NewMISPSource(client *http.Client) *MISPSource {
+	return &MISPSource{client: client}
+}
+
+// Pull stores the cursor of the events done even when a later event fails,
+// so that the next pull resumes there.
+func (s *MISPSource) Pull(ctx context.Context, feed *domain.Feed, emit domain.FeedEmitFunc) (*domain.FeedPullResult, error) {
+	var since time.Time
+	if seconds, err := strconv.ParseInt(feed.Cursor, 10, 64); err == nil {
+		since = time.Unix(seconds, 0)
+	}
+
+	result := &domain.FeedPullResult{Cursor: feed.Cursor}
+	options := misp.Options{Source: feed.Name, IDSOnly: feed.Options.IDSOnly}
+	watermark, err := misp.NewFeed(feed.URL, s.client).Pull(ctx, since, func(event *misp.Event) error {
+		indicators, errs := misp.ToIndicators(event, options)
+		for _, err := range errs {
+			skip(result, "event "+event.UUID, err)
+		}
+		attributes := attributesByStixID(event)
+		for _, indicator := range indicators {
+			var raw []byte
+			if attribute, ok := attributes[indicator.StixID]; ok {
+				raw, _ = json.Marshal(attribute)
+			}
+			if err := emit(indicator, string(raw)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if !watermark.IsZero() {
+		result.Cursor = strconv.FormatInt(watermark.Unix(), 10)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	result.NotModified = watermark.Equal(since)
+	return result, nil
+}
+
+// attributesByStixID indexes the attributes of an event, those of its objects
+// included, by the STIX ID misp.ToIndicators gives their indicators.
+func attributesByStixID(event *misp.Event) map[string]misp.Attribute {
+	attributes := make(map[string]misp.Attribute, len(event.Attributes))
+	index := func(attribute misp.Attribute) {
+		if id, err := uuid.Parse(attribute.UUID); err == nil {
+			attributes[domain.NewStixID("indicator", id)] = attribute
+		}
+	}
+	for _, attribute := range event.Attributes {
+		index(attribute)
+	}
+	for _, object := range event.Objects {
+		for _, attribute := range object.Attributes {
+			index(attribute)
+		}
+	}
+	return attributes
+}
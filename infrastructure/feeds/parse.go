@@ -0,0 +1,154 @@
+package feeds
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"threat-intel-backend/domain"
+	"threat-intel-backend/infrastructure/stix"
+)
+
+var ErrInvalidDocument = errors.New("invalid feed document")
+
+// parsePlainText reads one observable per line, the first word of the line,
+// so that lists with trailing comments such as "192.0.2.1 # scanner" work.
+// Blank lines and lines starting with # or ; are skipped.
+func parsePlainText(body io.Reader, options domain.FeedOptions, emit domain.FeedEmitFunc, result *domain.FeedPullResult) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, ";") {
+			continue
+		}
+		if err := emitValue(strings.Fields(text)[0], text, options, emit, result, fmt.Sprintf("line %d", line)); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+	return nil
+}
+
+// parseCSV reads the value in options.Column of each record. Lines starting
+// with # are comments.
+func parseCSV(body io.Reader, options domain.FeedOptions, emit domain.FeedEmitFunc, result *domain.FeedPullResult) error {
+	reader := csv.NewReader(body)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	for record := 1; ; record++ {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+		}
+		if record == 1 && options.SkipHeader {
+			continue
+		}
+
+		entry := fmt.Sprintf("record %d", record)
+		if options.Column >= len(fields) {
+			skip(result, entry, fmt.Errorf("no column %d", options.Column))
+			continue
+		}
+		if err := emitValue(fields[options.Column], csvRecord(fields), options, emit, result, entry); err != nil {
+			return err
+		}
+	}
+}
+
+// parseJSON reads the array at options.ItemsPath, whose items are either the
+// values or objects holding them in options.ValueField.
+func parseJSON(body io.Reader, options domain.FeedOptions, emit domain.FeedEmitFunc, result *domain.FeedPullResult) error {
+	var document interface{}
+	decoder := json.NewDecoder(body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&document); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+
+	if options.ItemsPath != "" {
+		for _, key := range strings.Split(options.ItemsPath, ".") {
+			object, ok := document.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%w: %s is not an object", ErrInvalidDocument, key)
+			}
+			document = object[key]
+		}
+	}
+	items, ok := document.([]interface{})
+	if !ok {
+		return fmt.Errorf("%w: no array at %q", ErrInvalidDocument, options.ItemsPath)
+	}
+
+	for i, item := range items {
+		entry := fmt.Sprintf("item %d", i)
+		raw, _ := json.Marshal(item)
+		if object, ok := item.(map[string]interface{}); ok {
+			item = object[options.ValueField]
+		}
+		value, ok := item.(string)
+		if !ok {
+			skip(result, entry, errors.New("no string value"))
+			continue
+		}
+		if err := emitValue(value, string(raw), options, emit, result, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseSTIX reads the indicators of a bundle, which carry their own
+// confidence, severity and labels. Other objects are ignored.
+func parseSTIX(body io.Reader, _ domain.FeedOptions, emit domain.FeedEmitFunc, result *domain.FeedPullResult) error {
+	bundle, err := stix.Decode(body)
+	if err != nil {
+		return err
+	}
+
+	for _, object := range bundle.Objects {
+		if object.Type != stix.TypeIndicator {
+			continue
+		}
+		indicator, err := stix.ToIndicator(object)
+		if err != nil {
+			skip(result, object.ID, err)
+			continue
+		}
+		raw, _ := json.Marshal(object)
+		if err := emit(indicator, string(raw)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func emitValue(value, rawPayload string, options domain.FeedOptions, emit domain.FeedEmitFunc, result *domain.FeedPullResult, entry string) error {
+	indicator, err := newIndicator(value, options)
+	if err != nil {
+		skip(result, entry, err)
+		return nil
+	}
+	return emit(indicator, rawPayload)
+}
+
+// csvRecord encodes a record back into its CSV line.
+func csvRecord(fields []string) string {
+	var line strings.Builder
+	writer := csv.NewWriter(&line)
+	_ = writer.Write(fields)
+	writer.Flush()
+	return strings.TrimSuffix(line.String(), "\n")
+}
@@ -0,0 +1,287 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRSABits is the smallest RSA modulus accepted for signing keys.
+const minRSABits = 2048
+
+var (
+	ErrUnsupportedKey = errors.New("unsupported key: use RSA (2048+ bits) or ECDSA P-256")
+	ErrNoSigningKey   = errors.New("signing key not found or has no private key")
+	ErrUnknownKeyID   = errors.New("unknown key id")
+)
+
+// Key is an asymmetric key identified by its kid. Keys without a private key
+// only verify tokens, which is how retired signing keys are kept until the
+// tokens they signed have expired.
+type Key struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Private crypto.Signer
+	Public  crypto.PublicKey
+}
+
+// NewKey wraps an RSA or ECDSA P-256 private or public key. RSA keys sign
+// with RS256 and ECDSA keys with ES256.
+func NewKey(id string, key interface{}) (*Key, error) {
+	k := &Key{ID: id}
+
+	switch typed := key.(type) {
+	case *rsa.PrivateKey:
+		k.Private, k.Public = typed, &typed.PublicKey
+	case *ecdsa.PrivateKey:
+		k.Private, k.Public = typed, &typed.PublicKey
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+		k.Public = typed
+	default:
+		return nil, ErrUnsupportedKey
+	}
+
+	switch public := k.Public.(type) {
+	case *rsa.PublicKey:
+		if public.N.BitLen() < minRSABits {
+			return nil, ErrUnsupportedKey
+		}
+		k.Method = jwt.SigningMethodRS256
+	case *ecdsa.PublicKey:
+		if public.Curve != elliptic.P256() {
+			return nil, ErrUnsupportedKey
+		}
+		k.Method = jwt.SigningMethodES256
+	}
+	return k, nil
+}
+
+// ParseKey reads a PEM encoded PKCS#8, PKCS#1 or SEC 1 private key, or a
+// PKIX public key.
+func ParseKey(id string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key %s: no PEM block found", id)
+	}
+
+	var (
+		key interface{}
+		err error
+	)
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("key %s: unsupported PEM block %q", id, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", id, err)
+	}
+
+	k, err := NewKey(id, key)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", id, err)
+	}
+	return k, nil
+}
+
+// KeySet holds the key that signs new tokens and every key whose tokens are
+// still accepted.
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+}
+
+func NewKeySet(signingID string, keys ...*Key) (*KeySet, error) {
+	set := &KeySet{keys: make(map[string]*Key, len(keys))}
+	for _, key := range keys {
+		if _, exists := set.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+		set.keys[key.ID] = key
+	}
+
+	signing, ok := set.keys[signingID]
+	if !ok || signing.Private == nil {
+		return nil, ErrNoSigningKey
+	}
+	set.signing = signing
+	return set, nil
+}
+
+// LoadKeySet reads every *.pem file of dir as a key whose kid is the file
+// name without the extension, and signs with the key signingID.
+func LoadKeySet(dir, signingID string) (*KeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*Key, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := ParseKey(strings.TrimSuffix(filepath.Base(path), ".pem"), data)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return NewKeySet(signingID, keys...)
+}
+
+func (s *KeySet) Signing() *Key {
+	return s.signing
+}
+
+// Methods returns the algorithms of the keys of the set.
+func (s *KeySet) Methods() []string {
+	seen := make(map[string]bool, 2)
+	methods := make([]string, 0, 2)
+	for _, key := range s.keys {
+		if alg := key.Method.Alg(); !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+func (s *KeySet) Lookup(id string) (*Key, error) {
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+	return key, nil
+}
+
+// JWK is the public part of a key as published in a JWK Set (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of the set, the signing key first and the
+// others ordered by kid.
+func (s *KeySet) JWKS() *JWKSet {
+	ids := make([]string, 0, len(s.keys))
+	for id := range s.keys {
+		if id != s.signing.ID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	set := &JWKSet{Keys: []JWK{s.signing.JWK()}}
+	for _, id := range ids {
+		set.Keys = append(set.Keys, s.keys[id].JWK())
+	}
+	return set
+}
+
+func (k *Key) JWK() JWK {
+	jwk := JWK{Use: "sig", Alg: k.Method.Alg(), Kid: k.ID}
+
+	switch public := k.Public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = encodeJWKInt(public.N, 0)
+		jwk.E = encodeJWKInt(big.NewInt(int64(public.E)), 0)
+	case *ecdsa.PublicKey:
+		size := (public.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = public.Curve.Params().Name
+		jwk.X = encodeJWKInt(public.X, size)
+		jwk.Y = encodeJWKInt(public.Y, size)
+	}
+	return jwk
+}
+
+// PublicKey decodes an RSA or EC P-256 key of a JWK Set, such as one
+// published by an identity provider.
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeJWKInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeJWKInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, ErrUnsupportedKey
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, ErrUnsupportedKey
+		}
+		x, err := decodeJWKInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeJWKInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return nil, errors.New("EC point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	}
+	return nil, ErrUnsupportedKey
+}
+
+func decodeJWKInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(raw) == 0 {
+		return nil, errors.New("invalid JWK parameter")
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
+
+// encodeJWKInt encodes n big-endian in base64url, left-padded to size bytes
+// as RFC 7518 requires for EC coordinates.
+func encodeJWKInt(n *big.Int, size int) string {
+	raw := n.Bytes()
+	if len(raw) < size {
+		raw = append(make([]byte, size-len(raw)), raw...)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
@@ -0,0 +1,265 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"threat-intel-backend/domain"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0600))
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	return key
+}
+
+func newECKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	return key
+}
+
+func TestNewKey(t *testing.T) {
+	t.Run("RSA signs with RS256", func(t *testing.T) {
+		key, err := NewKey("rsa", newRSAKey(t))
+
+		assert.NoError(t, err)
+		assert.Equal(t, jwt.SigningMethodRS256, key.Method)
+		assert.NotNil(t, key.Private)
+	})
+
+	t.Run("ECDSA signs with ES256", func(t *testing.T) {
+		key, err := NewKey("ec", &newECKey(t).PublicKey)
+
+		assert.NoError(t, err)
+		assert.Equal(t, jwt.SigningMethodES256, key.Method)
+		assert.Nil(t, key.Private)
+	})
+
+	t.Run("rejects other curves", func(t *testing.T) {
+		key, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+
+		_, err := NewKey("p384", key)
+
+		assert.Equal(t, ErrUnsupportedKey, err)
+	})
+
+	t.Run("rejects short RSA keys", func(t *testing.T) {
+		key, _ := rsa.GenerateKey(rand.Reader, 1024)
+
+		_, err := NewKey("short", key)
+
+		assert.Equal(t, ErrUnsupportedKey, err)
+	})
+}
+
+func TestLoadKeySet(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey := newRSAKey(t)
+	ecKey := newECKey(t)
+	pkcs8, _ := x509.MarshalPKCS8PrivateKey(ecKey)
+	public, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	writePEM(t, dir, "2026-01.pem", "PUBLIC KEY", public)
+	writePEM(t, dir, "2026-07.pem", "PRIVATE KEY", pkcs8)
+
+	t.Run("loads keys named by file", func(t *testing.T) {
+		set, err := LoadKeySet(dir, "2026-07")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "2026-07", set.Signing().ID)
+		key, err := set.Lookup("2026-01")
+		assert.NoError(t, err)
+		assert.Equal(t, jwt.SigningMethodRS256, key.Method)
+	})
+
+	t.Run("signing key needs a private key", func(t *testing.T) {
+		_, err := LoadKeySet(dir, "2026-01")
+
+		assert.Equal(t, ErrNoSigningKey, err)
+	})
+
+	t.Run("rejects malformed files", func(t *testing.T) {
+		bad := t.TempDir()
+		assert.NoError(t, os.WriteFile(filepath.Join(bad, "broken.pem"), []byte("not a key"), 0600))
+
+		_, err := LoadKeySet(bad, "broken")
+
+		assert.Error(t, err)
+	})
+}
+
+func TestKeySet_JWKS(t *testing.T) {
+	rsaKey, _ := NewKey("old", &newRSAKey(t).PublicKey)
+	ecKey, _ := NewKey("new", newECKey(t))
+	set, err := NewKeySet("new", rsaKey, ecKey)
+	assert.NoError(t, err)
+
+	jwks := set.JWKS()
+
+	assert.Len(t, jwks.Keys, 2)
+	assert.Equal(t, JWK{Kty: "EC", Use: "sig", Alg: "ES256", Kid: "new", Crv: "P-256", X: jwks.Keys[0].X, Y: jwks.Keys[0].Y}, jwks.Keys[0])
+	assert.Len(t, jwks.Keys[0].X, 43)
+	assert.Equal(t, "RSA", jwks.Keys[1].Kty)
+	assert.Equal(t, "RS256", jwks.Keys[1].Alg)
+	assert.Equal(t, "AQAB", jwks.Keys[1].E)
+}
+
+func TestJWK_PublicKey(t *testing.T) {
+	rsaKey := newRSAKey(t)
+	ecKey := newECKey(t)
+
+	t.Run("round trips RSA", func(t *testing.T) {
+		key, _ := NewKey("rsa", rsaKey)
+
+		public, err := key.JWK().PublicKey()
+
+		assert.NoError(t, err)
+		assert.True(t, rsaKey.PublicKey.Equal(public))
+	})
+
+	t.Run("round trips EC", func(t *testing.T) {
+		key, _ := NewKey("ec", ecKey)
+
+		public, err := key.JWK().PublicKey()
+
+		assert.NoError(t, err)
+		assert.True(t, ecKey.PublicKey.Equal(public))
+	})
+
+	t.Run("rejects unsupported keys", func(t *testing.T) {
+		_, err := JWK{Kty: "oct"}.PublicKey()
+
+		assert.Equal(t, ErrUnsupportedKey, err)
+	})
+
+	t.Run("rejects points off the curve", func(t *testing.T) {
+		_, err := JWK{Kty: "EC", Crv: "P-256", X: "AQ", Y: "AQ"}.PublicKey()
+
+		assert.Error(t, err)
+	})
+}
+
+func TestService_KeyRotation(t *testing.T) {
+	oldKey := newRSAKey(t)
+	newKey := newECKey(t)
+	userID := uuid.New()
+
+	oldSigning, _ := NewKey("old", oldKey)
+	before, _ := NewKeySet("old", oldSigning)
+	service := NewService("test-secret").WithKeySet(before)
+	oldToken, err := service.GenerateAccessToken(userID, domain.RoleAnalyst)
+	assert.NoError(t, err)
+
+	retired, _ := NewKey("old", &oldKey.PublicKey)
+	newSigning, _ := NewKey("new", newKey)
+	after, _ := NewKeySet("new", retired, newSigning)
+	service.WithKeySet(after)
+
+	t.Run("signs with the new key", func(t *testing.T) {
+		token, err := service.GenerateAccessToken(userID, domain.RoleAnalyst)
+		assert.NoError(t, err)
+
+		parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+		assert.NoError(t, err)
+		assert.Equal(t, "new", parsed.Header["kid"])
+		assert.Equal(t, "ES256", parsed.Method.Alg())
+
+		claims, err := service.ValidateAccessToken(token)
+		assert.NoError(t, err)
+		assert.Equal(t, userID, claims.UserID)
+	})
+
+	t.Run("still accepts tokens of the retired key", func(t *testing.T) {
+		claims, err := service.ValidateAccessToken(oldToken)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.RoleAnalyst, claims.Role)
+	})
+
+	t.Run("refresh tokens use the key set", func(t *testing.T) {
+		token, _, err := service.GenerateRefreshToken(userID, uuid.New())
+		assert.NoError(t, err)
+
+		_, err = service.ValidateRefreshToken(token)
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejects HS256 tokens", func(t *testing.T) {
+		token, _ := NewService("test-secret").GenerateAccessToken(userID, domain.RoleAdmin)
+
+		_, err := service.ValidateAccessToken(token)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("accepts HS256 tokens during the HS256 window", func(t *testing.T) {
+		legacy := NewService("test-secret")
+		accessToken, _ := legacy.GenerateAccessToken(userID, domain.RoleAdmin)
+		refreshToken, _, _ := legacy.GenerateRefreshToken(userID, uuid.New())
+		migrating := NewService("test-secret").WithKeySet(after).WithHS256Window(time.Hour)
+
+		claims, err := migrating.ValidateAccessToken(accessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.RoleAdmin, claims.Role)
+
+		_, err = migrating.ValidateRefreshToken(refreshToken)
+		assert.NoError(t, err)
+
+		token, _ := migrating.GenerateAccessToken(userID, domain.RoleAdmin)
+		parsed, _, _ := jwt.NewParser().ParseUnverified(token, &Claims{})
+		assert.Equal(t, "ES256", parsed.Method.Alg())
+
+		_, err = NewService("other-secret").WithKeySet(after).WithHS256Window(time.Hour).ValidateAccessToken(accessToken)
+		assert.Equal(t, ErrTokenSignatureInvalid, err)
+	})
+
+	t.Run("rejects HS256 tokens once the HS256 window has passed", func(t *testing.T) {
+		token, _ := NewService("test-secret").GenerateAccessToken(userID, domain.RoleAdmin)
+		expired := NewService("test-secret").WithKeySet(after).WithHS256Window(-time.Second)
+
+		_, err := expired.ValidateAccessToken(token)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects a kid signed with another alg", func(t *testing.T) {
+		forged := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{UserID: userID, Role: domain.RoleAdmin})
+		forged.Header["kid"] = "old"
+		token, _ := forged.SignedString(x509.MarshalPKCS1PublicKey(&oldKey.PublicKey))
+
+		_, err := service.ValidateAccessToken(token)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects unknown kid", func(t *testing.T) {
+		other, _ := NewKey("other", newRSAKey(t))
+		otherSet, _ := NewKeySet("other", other)
+		token, _ := NewService("test-secret").WithKeySet(otherSet).GenerateAccessToken(userID, domain.RoleAdmin)
+
+		_, err := service.ValidateAccessToken(token)
+
+		assert.Equal(t, ErrTokenSignatureInvalid, err)
+	})
+
+	t.Run("publishes both keys", func(t *testing.T) {
+		assert.Len(t, service.JWKS().Keys, 2)
+		assert.Empty(t, NewService("test-secret").JWKS().Keys)
+	})
+}
@@ -1,6 +1,8 @@
 package jwt
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"errors"
 	"time"
 	"threat-intel-backend/domain"
@@ -8,84 +10,471 @@ import (
 	"github.com/google/uuid"
 )
 
+// Token types, carried in the typ claim so that a token issued for one
+// purpose is never accepted for another.
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+	TokenTypeInvite  = "invite"
+	TokenTypeMFA     = "mfa"
+
+	TokenTypePasswordReset     = "password_reset"
+	TokenTypeEmailVerification = "email_verification"
+)
+
+// Defaults for the iss and aud claims and for the clock skew tolerated when
+// checking exp, nbf and iat.
+const (
+	DefaultIssuer   = "threat-intel-backend"
+	DefaultAudience = "threat-intel-api"
+	DefaultLeeway   = 30 * time.Second
+)
+
+// Validation errors. Every token that fails to validate is reported as one
+// of them, so callers can tell an expired token from a forged one.
+var (
+	ErrTokenMalformed        = errors.New("token is malformed")
+	ErrTokenSignatureInvalid = errors.New("token signature is invalid")
+	ErrTokenExpired          = errors.New("token has expired")
+	ErrTokenNotValidYet      = errors.New("token is not valid yet")
+	ErrTokenInvalidIssuer    = errors.New("token has an invalid issuer")
+	ErrTokenInvalidAudience  = errors.New("token has an invalid audience")
+	ErrTokenWrongType        = errors.New("token has the wrong type")
+	ErrTokenInvalidClaims    = errors.New("token has invalid claims")
+)
+
+// Service signs access and refresh tokens with the signing key of its key
+// set, or with the HS256 secret when no key set is configured. Invite, MFA,
+// password reset and email verification tokens are only ever read by this
+// service and are always HMAC signed with keys derived from the secret.
 type Service struct {
-	secretKey        []byte
-	accessTokenTTL   time.Duration
-	refreshTokenTTL  time.Duration
+	secretKey       []byte
+	keys            *KeySet
+	hs256Until      time.Time
+	inviteKey       []byte
+	mfaKey          []byte
+	resetKey        []byte
+	verificationKey []byte
+	issuer          string
+	audience        string
+	leeway          time.Duration
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+	inviteTokenTTL  time.Duration
+	mfaTokenTTL     time.Duration
+	resetTokenTTL   time.Duration
+	verifyTokenTTL  time.Duration
 }
 
 type Claims struct {
 	UserID uuid.UUID       `json:"user_id"`
 	Role   domain.UserRole `json:"role"`
+	Type   string          `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+func (c *Claims) tokenType() string {
+	return c.Type
+}
+
+// RefreshClaims identifies a single refresh token (ID) and the rotation
+// family (FamilyID) it belongs to.
+type RefreshClaims struct {
+	FamilyID uuid.UUID `json:"fid"`
+	Type     string    `json:"typ"`
 	jwt.RegisteredClaims
 }
 
+func (c *RefreshClaims) UserID() (uuid.UUID, error) {
+	return uuid.Parse(c.Subject)
+}
+
+func (c *RefreshClaims) tokenType() string {
+	return c.Type
+}
+
+// InviteClaims carry the email and role an administrator invited someone
+// with. Invite tokens are signed with a key derived from the secret so they
+// can never be passed off as access or refresh tokens.
+type InviteClaims struct {
+	Email     string          `json:"email"`
+	Role      domain.UserRole `json:"invite_role"`
+	InvitedBy uuid.UUID       `json:"invited_by"`
+	Type      string          `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+func (c *InviteClaims) tokenType() string {
+	return c.Type
+}
+
+// MFA token purposes. A verify token lets a user whose password was accepted
+// submit a TOTP or recovery code; an enroll token lets a user whose role
+// requires MFA set it up before the first login completes.
+const (
+	MFAPurposeVerify = "verify"
+	MFAPurposeEnroll = "enroll"
+)
+
+// MFAClaims identify the user (Subject) halfway through a login that still
+// needs a second factor. They are signed with their own derived key.
+type MFAClaims struct {
+	Purpose string `json:"mfa_purpose"`
+	Type    string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+func (c *MFAClaims) UserID() (uuid.UUID, error) {
+	return uuid.Parse(c.Subject)
+}
+
+func (c *MFAClaims) tokenType() string {
+	return c.Type
+}
+
+// AccountClaims identify the user (Subject) and the email address a password
+// reset or email verification link was sent to. Each purpose is signed with
+// its own derived key.
+type AccountClaims struct {
+	Email string `json:"email"`
+	Type  string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+func (c *AccountClaims) UserID() (uuid.UUID, error) {
+	return uuid.Parse(c.Subject)
+}
+
+func (c *AccountClaims) tokenType() string {
+	return c.Type
+}
+
+type typedClaims interface {
+	jwt.Claims
+	tokenType() string
+}
+
 func NewService(secretKey string) *Service {
 	return &Service{
-		secretKey:        []byte(secretKey),
-		accessTokenTTL:   15 * time.Minute,
-		refreshTokenTTL:  7 * 24 * time.Hour,
+		secretKey:       []byte(secretKey),
+		inviteKey:       deriveKey(secretKey, "invite"),
+		mfaKey:          deriveKey(secretKey, "mfa"),
+		resetKey:        deriveKey(secretKey, TokenTypePasswordReset),
+		verificationKey: deriveKey(secretKey, TokenTypeEmailVerification),
+		issuer:          DefaultIssuer,
+		audience:        DefaultAudience,
+		leeway:          DefaultLeeway,
+		accessTokenTTL:  15 * time.Minute,
+		refreshTokenTTL: 7 * 24 * time.Hour,
+		inviteTokenTTL:  72 * time.Hour,
+		mfaTokenTTL:     5 * time.Minute,
+		resetTokenTTL:   time.Hour,
+		verifyTokenTTL:  24 * time.Hour,
+	}
+}
+
+// WithValidation sets the iss and aud claims that tokens are issued with and
+// must carry, and the clock skew tolerated when validating them.
+func (s *Service) WithValidation(issuer, audience string, leeway time.Duration) *Service {
+	s.issuer = issuer
+	s.audience = audience
+	s.leeway = leeway
+	return s
+}
+
+// WithKeySet switches access and refresh tokens from HS256 to the keys of
+// keys. Tokens signed with the HS256 secret are no longer accepted, unless
+// WithHS256Window keeps them valid for a while.
+func (s *Service) WithKeySet(keys *KeySet) *Service {
+	s.keys = keys
+	return s
+}
+
+// WithHS256Window keeps accepting access and refresh tokens signed with the
+// HS256 secret for window from now, so that sessions started before the
+// switch to a key set survive it. New tokens are only signed with the key
+// set.
+func (s *Service) WithHS256Window(window time.Duration) *Service {
+	s.hs256Until = time.Now().Add(window)
+	return s
+}
+
+// JWKS returns the public keys that verify access and refresh tokens. It is
+// empty in HS256 mode, as those tokens cannot be verified without the secret.
+func (s *Service) JWKS() *JWKSet {
+	if s.keys == nil {
+		return &JWKSet{Keys: []JWK{}}
+	}
+	return s.keys.JWKS()
+}
+
+func (s *Service) signSessionToken(claims jwt.Claims) (string, error) {
+	if s.keys == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secretKey)
+	}
+
+	key := s.keys.Signing()
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
+}
+
+// sessionKey picks the key that verifies an access or refresh token: the key
+// named by its kid header, which must also match its alg. An HS256 token
+// without a kid predates the key set; sessionMethods only lets it through
+// during the HS256 window.
+func (s *Service) sessionKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if s.keys == nil || (kid == "" && token.Method.Alg() == jwt.SigningMethodHS256.Alg()) {
+		return s.secretKey, nil
+	}
+
+	key, err := s.keys.Lookup(kid)
+	if err != nil {
+		return nil, err
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, errors.New("signing method does not match key")
+	}
+	return key.Public, nil
+}
+
+func (s *Service) sessionMethods() []string {
+	if s.keys == nil {
+		return []string{jwt.SigningMethodHS256.Alg()}
+	}
+	if time.Now().Before(s.hs256Until) {
+		return append(s.keys.Methods(), jwt.SigningMethodHS256.Alg())
+	}
+	return s.keys.Methods()
+}
+
+func (s *Service) registeredClaims(subject string, ttl time.Duration) jwt.RegisteredClaims {
+	now := time.Now()
+	return jwt.RegisteredClaims{
+		ID:        uuid.NewString(),
+		Issuer:    s.issuer,
+		Audience:  jwt.ClaimStrings{s.audience},
+		Subject:   subject,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+	}
+}
+
+// parse verifies the signature of a token with one of methods, checks its
+// iss, aud, exp, nbf and iat claims and that it is of tokenType.
+func (s *Service) parse(tokenString string, claims typedClaims, tokenType string, methods []string, keyFunc jwt.Keyfunc) error {
+	parser := jwt.NewParser(
+		jwt.WithValidMethods(methods),
+		jwt.WithIssuer(s.issuer),
+		jwt.WithAudience(s.audience),
+		jwt.WithLeeway(s.leeway),
+		jwt.WithIssuedAt(),
+	)
+
+	if _, err := parser.ParseWithClaims(tokenString, claims, keyFunc); err != nil {
+		return validationError(err)
+	}
+
+	if claims.tokenType() != tokenType {
+		return ErrTokenWrongType
+	}
+	if exp, err := claims.GetExpirationTime(); err != nil || exp == nil {
+		return ErrTokenInvalidClaims
+	}
+	return nil
+}
+
+func validationError(err error) error {
+	switch {
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return ErrTokenMalformed
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid), errors.Is(err, jwt.ErrTokenUnverifiable):
+		return ErrTokenSignatureInvalid
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return ErrTokenExpired
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return ErrTokenNotValidYet
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		return ErrTokenInvalidIssuer
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		return ErrTokenInvalidAudience
 	}
+	return ErrTokenInvalidClaims
+}
+
+func hmacKey(key []byte) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		return key, nil
+	}
+}
+
+func deriveKey(secretKey, purpose string) []byte {
+	mac := hmac.New(sha256.New, []byte(secretKey))
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
 }
 
 func (s *Service) GenerateAccessToken(userID uuid.UUID, role domain.UserRole) (string, error) {
 	claims := Claims{
-		UserID: userID,
-		Role:   role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.accessTokenTTL)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Subject:   userID.String(),
-		},
+		UserID:           userID,
+		Role:             role,
+		Type:             TokenTypeAccess,
+		RegisteredClaims: s.registeredClaims(userID.String(), s.accessTokenTTL),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(s.secretKey)
+	return s.signSessionToken(claims)
 }
 
-func (s *Service) GenerateRefreshToken(userID uuid.UUID) (string, error) {
-	claims := jwt.RegisteredClaims{
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.refreshTokenTTL)),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
-		Subject:   userID.String(),
+func (s *Service) GenerateRefreshToken(userID, familyID uuid.UUID) (string, *RefreshClaims, error) {
+	claims := &RefreshClaims{
+		FamilyID:         familyID,
+		Type:             TokenTypeRefresh,
+		RegisteredClaims: s.registeredClaims(userID.String(), s.refreshTokenTTL),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(s.secretKey)
+	signed, err := s.signSessionToken(claims)
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, claims, nil
+}
+
+func (s *Service) AccessTokenTTL() time.Duration {
+	return s.accessTokenTTL
+}
+
+func (s *Service) RefreshTokenTTL() time.Duration {
+	return s.refreshTokenTTL
 }
 
 func (s *Service) ValidateAccessToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return s.secretKey, nil
-	})
+	claims := &Claims{}
+	if err := s.parse(tokenString, claims, TokenTypeAccess, s.sessionMethods(), s.sessionKey); err != nil {
+		return nil, err
+	}
+
+	if claims.UserID == uuid.Nil || !claims.Role.IsValid() {
+		return nil, ErrTokenInvalidClaims
+	}
+	return claims, nil
+}
+
+func (s *Service) ValidateRefreshToken(tokenString string) (*RefreshClaims, error) {
+	claims := &RefreshClaims{}
+	if err := s.parse(tokenString, claims, TokenTypeRefresh, s.sessionMethods(), s.sessionKey); err != nil {
+		return nil, err
+	}
+
+	if _, err := claims.UserID(); err != nil {
+		return nil, ErrTokenInvalidClaims
+	}
+	if claims.ID == "" || claims.FamilyID == uuid.Nil {
+		return nil, ErrTokenInvalidClaims
+	}
+	return claims, nil
+}
 
+func (s *Service) GenerateInviteToken(email string, role domain.UserRole, invitedBy uuid.UUID) (string, *InviteClaims, error) {
+	claims := &InviteClaims{
+		Email:            email,
+		Role:             role,
+		InvitedBy:        invitedBy,
+		Type:             TokenTypeInvite,
+		RegisteredClaims: s.registeredClaims("", s.inviteTokenTTL),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString(s.inviteKey)
 	if err != nil {
+		return "", nil, err
+	}
+	return signed, claims, nil
+}
+
+func (s *Service) ValidateInviteToken(tokenString string) (*InviteClaims, error) {
+	claims := &InviteClaims{}
+	if err := s.parse(tokenString, claims, TokenTypeInvite, []string{jwt.SigningMethodHS256.Alg()}, hmacKey(s.inviteKey)); err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return claims, nil
+	if claims.ID == "" || claims.Email == "" {
+		return nil, ErrTokenInvalidClaims
 	}
+	return claims, nil
+}
 
-	return nil, errors.New("invalid token")
+func (s *Service) MFATokenTTL() time.Duration {
+	return s.mfaTokenTTL
 }
 
-func (s *Service) ValidateRefreshToken(tokenString string) (uuid.UUID, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return s.secretKey, nil
-	})
+func (s *Service) GenerateMFAToken(userID uuid.UUID, purpose string) (string, error) {
+	claims := &MFAClaims{
+		Purpose:          purpose,
+		Type:             TokenTypeMFA,
+		RegisteredClaims: s.registeredClaims(userID.String(), s.mfaTokenTTL),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(s.mfaKey)
+}
+
+func (s *Service) ValidateMFAToken(tokenString, purpose string) (*MFAClaims, error) {
+	claims := &MFAClaims{}
+	if err := s.parse(tokenString, claims, TokenTypeMFA, []string{jwt.SigningMethodHS256.Alg()}, hmacKey(s.mfaKey)); err != nil {
+		return nil, err
+	}
+
+	if _, err := claims.UserID(); err != nil {
+		return nil, ErrTokenInvalidClaims
+	}
+	if claims.Purpose != purpose {
+		return nil, ErrTokenWrongType
+	}
+	return claims, nil
+}
 
+func (s *Service) GeneratePasswordResetToken(userID uuid.UUID, email string) (string, *AccountClaims, error) {
+	return s.generateAccountToken(TokenTypePasswordReset, s.resetKey, s.resetTokenTTL, userID, email)
+}
+
+func (s *Service) ValidatePasswordResetToken(tokenString string) (*AccountClaims, error) {
+	return s.validateAccountToken(tokenString, TokenTypePasswordReset, s.resetKey)
+}
+
+func (s *Service) GenerateEmailVerificationToken(userID uuid.UUID, email string) (string, *AccountClaims, error) {
+	return s.generateAccountToken(TokenTypeEmailVerification, s.verificationKey, s.verifyTokenTTL, userID, email)
+}
+
+func (s *Service) ValidateEmailVerificationToken(tokenString string) (*AccountClaims, error) {
+	return s.validateAccountToken(tokenString, TokenTypeEmailVerification, s.verificationKey)
+}
+
+func (s *Service) generateAccountToken(tokenType string, key []byte, ttl time.Duration, userID uuid.UUID, email string) (string, *AccountClaims, error) {
+	claims := &AccountClaims{
+		Email:            email,
+		Type:             tokenType,
+		RegisteredClaims: s.registeredClaims(userID.String(), ttl),
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
 	if err != nil {
-		return uuid.Nil, err
+		return "", nil, err
 	}
+	return signed, claims, nil
+}
 
-	if claims, ok := token.Claims.(*jwt.RegisteredClaims); ok && token.Valid {
-		userID, err := uuid.Parse(claims.Subject)
-		if err != nil {
-			return uuid.Nil, err
-		}
-		return userID, nil
+func (s *Service) validateAccountToken(tokenString, tokenType string, key []byte) (*AccountClaims, error) {
+	claims := &AccountClaims{}
+	if err := s.parse(tokenString, claims, tokenType, []string{jwt.SigningMethodHS256.Alg()}, hmacKey(key)); err != nil {
+		return nil, err
 	}
 
-	return uuid.Nil, errors.New("invalid refresh token")
-}
\ No newline at end of file
+	if _, err := claims.UserID(); err != nil {
+		return nil, ErrTokenInvalidClaims
+	}
+	if claims.ID == "" || claims.Email == "" {
+		return nil, ErrTokenInvalidClaims
+	}
+	return claims, nil
+}
@@ -5,6 +5,7 @@ import (
 	"threat-intel-backend/domain"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
@@ -41,20 +42,32 @@ func TestService_GenerateRefreshToken(t *testing.T) {
 	service := NewService("test-secret")
 	userID := uuid.New()
 
+	familyID := uuid.New()
+
 	t.Run("generates valid refresh token", func(t *testing.T) {
-		token, err := service.GenerateRefreshToken(userID)
+		token, claims, err := service.GenerateRefreshToken(userID, familyID)
 
 		assert.NoError(t, err)
 		assert.NotEmpty(t, token)
+		assert.NotEmpty(t, claims.ID)
+		assert.Equal(t, familyID, claims.FamilyID)
+		assert.Equal(t, userID.String(), claims.Subject)
 	})
 
 	t.Run("generates tokens for different users", func(t *testing.T) {
 		userID2 := uuid.New()
-		token1, _ := service.GenerateRefreshToken(userID)
-		token2, _ := service.GenerateRefreshToken(userID2)
+		token1, _, _ := service.GenerateRefreshToken(userID, familyID)
+		token2, _, _ := service.GenerateRefreshToken(userID2, familyID)
 
 		assert.NotEqual(t, token1, token2)
 	})
+
+	t.Run("generates unique token IDs within a family", func(t *testing.T) {
+		_, claims1, _ := service.GenerateRefreshToken(userID, familyID)
+		_, claims2, _ := service.GenerateRefreshToken(userID, familyID)
+
+		assert.NotEqual(t, claims1.ID, claims2.ID)
+	})
 }
 
 func TestService_ValidateAccessToken(t *testing.T) {
@@ -70,6 +83,8 @@ func TestService_ValidateAccessToken(t *testing.T) {
 		assert.NotNil(t, claims)
 		assert.Equal(t, userID, claims.UserID)
 		assert.Equal(t, domain.RoleAdmin, claims.Role)
+		assert.NotEmpty(t, claims.ID)
+		assert.NotNil(t, claims.IssuedAt)
 	})
 
 	t.Run("rejects invalid token", func(t *testing.T) {
@@ -90,33 +105,283 @@ func TestService_ValidateAccessToken(t *testing.T) {
 	})
 }
 
+func TestService_TokenValidation(t *testing.T) {
+	service := NewService("test-secret")
+	userID := uuid.New()
+
+	sign := func(method jwt.SigningMethod, key interface{}, mutate func(*Claims)) string {
+		now := time.Now()
+		claims := Claims{
+			UserID: userID,
+			Role:   domain.RoleViewer,
+			Type:   TokenTypeAccess,
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    DefaultIssuer,
+				Audience:  jwt.ClaimStrings{DefaultAudience},
+				Subject:   userID.String(),
+				IssuedAt:  jwt.NewNumericDate(now),
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+			},
+		}
+		if mutate != nil {
+			mutate(&claims)
+		}
+		token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		assert.NoError(t, err)
+		return token
+	}
+	secret := []byte("test-secret")
+
+	tests := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{"accepts a well formed token", sign(jwt.SigningMethodHS256, secret, nil), nil},
+		{"malformed", "not.a.token", ErrTokenMalformed},
+		{"expired", sign(jwt.SigningMethodHS256, secret, func(c *Claims) {
+			c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+		}), ErrTokenExpired},
+		{"expired within leeway", sign(jwt.SigningMethodHS256, secret, func(c *Claims) {
+			c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-10 * time.Second))
+		}), nil},
+		{"issued in the future", sign(jwt.SigningMethodHS256, secret, func(c *Claims) {
+			c.IssuedAt = jwt.NewNumericDate(time.Now().Add(time.Hour))
+		}), ErrTokenNotValidYet},
+		{"without expiry", sign(jwt.SigningMethodHS256, secret, func(c *Claims) {
+			c.ExpiresAt = nil
+		}), ErrTokenInvalidClaims},
+		{"other issuer", sign(jwt.SigningMethodHS256, secret, func(c *Claims) {
+			c.Issuer = "someone-else"
+		}), ErrTokenInvalidIssuer},
+		{"other audience", sign(jwt.SigningMethodHS256, secret, func(c *Claims) {
+			c.Audience = jwt.ClaimStrings{"other-api"}
+		}), ErrTokenInvalidAudience},
+		{"without type", sign(jwt.SigningMethodHS256, secret, func(c *Claims) {
+			c.Type = ""
+		}), ErrTokenWrongType},
+		{"without role", sign(jwt.SigningMethodHS256, secret, func(c *Claims) {
+			c.Role = ""
+		}), ErrTokenInvalidClaims},
+		{"other HMAC algorithm", sign(jwt.SigningMethodHS512, secret, nil), ErrTokenSignatureInvalid},
+		{"unsigned", sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, nil), ErrTokenSignatureInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.ValidateAccessToken(tt.token)
+
+			assert.Equal(t, tt.err, err)
+		})
+	}
+
+	t.Run("refresh token is not an access token", func(t *testing.T) {
+		token, _, _ := service.GenerateRefreshToken(userID, uuid.New())
+
+		_, err := service.ValidateAccessToken(token)
+
+		assert.Equal(t, ErrTokenWrongType, err)
+	})
+
+	t.Run("access token is not a refresh token", func(t *testing.T) {
+		token, _ := service.GenerateAccessToken(userID, domain.RoleViewer)
+
+		_, err := service.ValidateRefreshToken(token)
+
+		assert.Equal(t, ErrTokenWrongType, err)
+	})
+
+	t.Run("issues the configured issuer and audience", func(t *testing.T) {
+		configured := NewService("test-secret").WithValidation("https://intel.example.com", "soar", time.Second)
+		token, _ := configured.GenerateAccessToken(userID, domain.RoleViewer)
+
+		claims, err := configured.ValidateAccessToken(token)
+		assert.NoError(t, err)
+		assert.Equal(t, "https://intel.example.com", claims.Issuer)
+		assert.Equal(t, jwt.ClaimStrings{"soar"}, claims.Audience)
+
+		_, err = service.ValidateAccessToken(token)
+		assert.Equal(t, ErrTokenInvalidIssuer, err)
+	})
+}
+
 func TestService_ValidateRefreshToken(t *testing.T) {
 	service := NewService("test-secret")
 	userID := uuid.New()
+	familyID := uuid.New()
 
 	t.Run("validates valid refresh token", func(t *testing.T) {
-		token, _ := service.GenerateRefreshToken(userID)
+		token, issued, _ := service.GenerateRefreshToken(userID, familyID)
 
-		extractedUserID, err := service.ValidateRefreshToken(token)
+		claims, err := service.ValidateRefreshToken(token)
 
+		assert.NoError(t, err)
+		assert.Equal(t, issued.ID, claims.ID)
+		assert.Equal(t, familyID, claims.FamilyID)
+		extractedUserID, err := claims.UserID()
 		assert.NoError(t, err)
 		assert.Equal(t, userID, extractedUserID)
 	})
 
 	t.Run("rejects invalid token", func(t *testing.T) {
-		extractedUserID, err := service.ValidateRefreshToken("invalid-token")
+		claims, err := service.ValidateRefreshToken("invalid-token")
 
 		assert.Error(t, err)
-		assert.Equal(t, uuid.Nil, extractedUserID)
+		assert.Nil(t, claims)
 	})
 
 	t.Run("rejects token with wrong secret", func(t *testing.T) {
 		wrongService := NewService("wrong-secret")
-		token, _ := service.GenerateRefreshToken(userID)
+		token, _, _ := service.GenerateRefreshToken(userID, familyID)
+
+		claims, err := wrongService.ValidateRefreshToken(token)
+
+		assert.Error(t, err)
+		assert.Nil(t, claims)
+	})
+
+	t.Run("rejects token without family", func(t *testing.T) {
+		token, _, _ := service.GenerateRefreshToken(userID, uuid.Nil)
+
+		claims, err := service.ValidateRefreshToken(token)
+
+		assert.Error(t, err)
+		assert.Nil(t, claims)
+	})
+}
+
+func TestService_InviteToken(t *testing.T) {
+	service := NewService("test-secret")
+	adminID := uuid.New()
+
+	t.Run("round trips invite claims", func(t *testing.T) {
+		token, issued, err := service.GenerateInviteToken("analyst@example.com", domain.RoleAnalyst, adminID)
+		assert.NoError(t, err)
+
+		claims, err := service.ValidateInviteToken(token)
+
+		assert.NoError(t, err)
+		assert.Equal(t, issued.ID, claims.ID)
+		assert.Equal(t, "analyst@example.com", claims.Email)
+		assert.Equal(t, domain.RoleAnalyst, claims.Role)
+		assert.Equal(t, adminID, claims.InvitedBy)
+	})
+
+	t.Run("invite token is not an access token", func(t *testing.T) {
+		token, _, _ := service.GenerateInviteToken("admin@example.com", domain.RoleAdmin, adminID)
+
+		claims, err := service.ValidateAccessToken(token)
+
+		assert.Error(t, err)
+		assert.Nil(t, claims)
+	})
+
+	t.Run("access token is not an invite token", func(t *testing.T) {
+		token, _ := service.GenerateAccessToken(adminID, domain.RoleAdmin)
+
+		claims, err := service.ValidateInviteToken(token)
+
+		assert.Error(t, err)
+		assert.Nil(t, claims)
+	})
+
+	t.Run("rejects token with wrong secret", func(t *testing.T) {
+		token, _, _ := NewService("wrong-secret").GenerateInviteToken("a@example.com", domain.RoleAnalyst, adminID)
 
-		extractedUserID, err := wrongService.ValidateRefreshToken(token)
+		claims, err := service.ValidateInviteToken(token)
 
 		assert.Error(t, err)
-		assert.Equal(t, uuid.Nil, extractedUserID)
+		assert.Nil(t, claims)
+	})
+}
+
+func TestService_MFAToken(t *testing.T) {
+	service := NewService("test-secret")
+	userID := uuid.New()
+
+	t.Run("round trips MFA claims", func(t *testing.T) {
+		token, err := service.GenerateMFAToken(userID, MFAPurposeVerify)
+		assert.NoError(t, err)
+
+		claims, err := service.ValidateMFAToken(token, MFAPurposeVerify)
+
+		assert.NoError(t, err)
+		id, _ := claims.UserID()
+		assert.Equal(t, userID, id)
+	})
+
+	t.Run("rejects other purpose", func(t *testing.T) {
+		token, _ := service.GenerateMFAToken(userID, MFAPurposeEnroll)
+
+		claims, err := service.ValidateMFAToken(token, MFAPurposeVerify)
+
+		assert.Error(t, err)
+		assert.Nil(t, claims)
+	})
+
+	t.Run("MFA token is not an access token", func(t *testing.T) {
+		token, _ := service.GenerateMFAToken(userID, MFAPurposeVerify)
+
+		claims, err := service.ValidateAccessToken(token)
+
+		assert.Error(t, err)
+		assert.Nil(t, claims)
+	})
+
+	t.Run("access token is not an MFA token", func(t *testing.T) {
+		token, _ := service.GenerateAccessToken(userID, domain.RoleAdmin)
+
+		claims, err := service.ValidateMFAToken(token, MFAPurposeVerify)
+
+		assert.Error(t, err)
+		assert.Nil(t, claims)
 	})
-}
\ No newline at end of file
+}
+
+func TestService_AccountTokens(t *testing.T) {
+	service := NewService("test-secret")
+	userID := uuid.New()
+
+	t.Run("round trips password reset claims", func(t *testing.T) {
+		token, issued, err := service.GeneratePasswordResetToken(userID, "user@example.com")
+		assert.NoError(t, err)
+
+		claims, err := service.ValidatePasswordResetToken(token)
+
+		assert.NoError(t, err)
+		assert.Equal(t, issued.ID, claims.ID)
+		assert.Equal(t, "user@example.com", claims.Email)
+		subject, _ := claims.UserID()
+		assert.Equal(t, userID, subject)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), claims.ExpiresAt.Time, time.Minute)
+	})
+
+	t.Run("verification lasts a day", func(t *testing.T) {
+		token, _, _ := service.GenerateEmailVerificationToken(userID, "user@example.com")
+
+		claims, err := service.ValidateEmailVerificationToken(token)
+
+		assert.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(24*time.Hour), claims.ExpiresAt.Time, time.Minute)
+	})
+
+	t.Run("purposes are not interchangeable", func(t *testing.T) {
+		verification, _, _ := service.GenerateEmailVerificationToken(userID, "user@example.com")
+		reset, _, _ := service.GeneratePasswordResetToken(userID, "user@example.com")
+
+		_, err := service.ValidatePasswordResetToken(verification)
+		assert.Error(t, err)
+		_, err = service.ValidateEmailVerificationToken(reset)
+		assert.Error(t, err)
+		_, err = service.ValidateAccessToken(reset)
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects token with wrong secret", func(t *testing.T) {
+		token, _, _ := NewService("wrong-secret").GeneratePasswordResetToken(userID, "user@example.com")
+
+		_, err := service.ValidatePasswordResetToken(token)
+
+		assert.ErrorIs(t, err, ErrTokenSignatureInvalid)
+	})
+}
@@ -0,0 +1,98 @@
+// Package mail delivers emails over SMTP, or, where no mail server is
+// available, writes them to the log or to files.
+package mail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"threat-intel-backend/domain"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrInvalidHeader = errors.New("invalid email header")
+
+// LogMailer logs emails instead of sending them, for local development. The
+// log contains the links the emails carry, so it must not be used in
+// production.
+type LogMailer struct {
+	logger *logrus.Logger
+}
+
+func NewLogMailer(logger *logrus.Logger) *LogMailer {
+	return &LogMailer{logger: logger}
+}
+
+func (m *LogMailer) Send(email *domain.Email) error {
+	m.logger.WithFields(logrus.Fields{
+		"to":      email.To,
+		"subject": email.Subject,
+	}).Info(email.Body)
+	return nil
+}
+
+// FileMailer writes every email as an .eml file into a directory, where it
+// can be opened with a mail client.
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+func NewFileMailer(dir, from string) *FileMailer {
+	return &FileMailer{dir: dir, from: from}
+}
+
+func (m *FileMailer) Send(email *domain.Email) error {
+	now := time.Now()
+	message, err := format(m.from, email, now)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(m.dir, 0o700); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%d-%s.eml", now.UnixNano(), strings.NewReplacer("/", "_", "\\", "_").Replace(email.To))
+	return os.WriteFile(filepath.Join(m.dir, name), message, 0o600)
+}
+
+// format renders a plain-text RFC 5322 message. Headers are checked for line
+// breaks, which would let a recipient address inject headers of its own.
+func format(from string, email *domain.Email, date time.Time) ([]byte, error) {
+	for _, value := range []string{from, email.To, email.Subject} {
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, ErrInvalidHeader
+		}
+	}
+	if _, err := mail.ParseAddress(from); err != nil {
+		return nil, fmt.Errorf("%w: from: %v", ErrInvalidHeader, err)
+	}
+	if _, err := mail.ParseAddress(email.To); err != nil {
+		return nil, fmt.Errorf("%w: to: %v", ErrInvalidHeader, err)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", email.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", email.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	body := quotedprintable.NewWriter(&buf)
+	if _, err := body.Write([]byte(strings.ReplaceAll(email.Body, "\n", "\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
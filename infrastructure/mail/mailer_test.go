@@ -0,0 +1,81 @@
+package mail
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"threat-intel-backend/domain"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func testEmail() *domain.Email {
+	return &domain.Email{
+		To:      "user@example.com",
+		Subject: "Reset your password",
+		Body:    "Open https://app.example.com/reset-password?token=abc\nto reset it.",
+	}
+}
+
+func TestFormat(t *testing.T) {
+	t.Run("renders a plain-text message", func(t *testing.T) {
+		message, err := format("Zentara <no-reply@example.com>", testEmail(), time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC))
+
+		assert.NoError(t, err)
+		text := string(message)
+		assert.Contains(t, text, "From: Zentara <no-reply@example.com>\r\n")
+		assert.Contains(t, text, "To: user@example.com\r\n")
+		assert.Contains(t, text, "Subject: Reset your password\r\n")
+		assert.Contains(t, text, "Date: Fri, 16 Oct 2026 09:00:00 +0000\r\n")
+		assert.Contains(t, text, "token=3Dabc\r\nto reset it.")
+	})
+
+	t.Run("rejects header injection", func(t *testing.T) {
+		email := testEmail()
+		email.To = "user@example.com\r\nBcc: victim@example.com"
+
+		_, err := format("no-reply@example.com", email, time.Now())
+
+		assert.ErrorIs(t, err, ErrInvalidHeader)
+	})
+
+	t.Run("rejects invalid recipient", func(t *testing.T) {
+		email := testEmail()
+		email.To = "not an address"
+
+		_, err := format("no-reply@example.com", email, time.Now())
+
+		assert.ErrorIs(t, err, ErrInvalidHeader)
+	})
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	mailer := NewFileMailer(dir, "no-reply@example.com")
+
+	assert.NoError(t, mailer.Send(testEmail()))
+
+	files, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	if assert.Len(t, files, 1) {
+		assert.True(t, strings.HasSuffix(files[0].Name(), "-user@example.com.eml"))
+		content, _ := os.ReadFile(filepath.Join(dir, files[0].Name()))
+		assert.Contains(t, string(content), "Subject: Reset your password")
+	}
+}
+
+func TestLogMailer(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	mailer := NewLogMailer(logger)
+
+	assert.NoError(t, mailer.Send(testEmail()))
+
+	entry := hook.LastEntry()
+	assert.Equal(t, logrus.InfoLevel, entry.Level)
+	assert.Equal(t, "user@example.com", entry.Data["to"])
+	assert.Contains(t, entry.Message, "token=abc")
+}
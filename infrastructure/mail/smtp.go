@@ -0,0 +1,44 @@
+package mail
+
+import (
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+	"threat-intel-backend/domain"
+)
+
+// SMTPMailer sends emails through a mail server. The connection is upgraded
+// with STARTTLS when the server offers it; credentials are only sent over
+// TLS or to localhost.
+type SMTPMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPMailer returns a mailer for the server at host:port. It does not
+// authenticate when username is empty.
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	mailer := &SMTPMailer{
+		addr: net.JoinHostPort(host, strconv.Itoa(port)),
+		from: from,
+	}
+	if username != "" {
+		mailer.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return mailer
+}
+
+func (m *SMTPMailer) Send(email *domain.Email) error {
+	message, err := format(m.from, email, time.Now())
+	if err != nil {
+		return err
+	}
+
+	// format has already checked that both parse.
+	sender, _ := mail.ParseAddress(m.from)
+	recipient, _ := mail.ParseAddress(email.To)
+	return smtp.SendMail(m.addr, m.auth, sender.Address, []string{recipient.Address}, message)
+}
@@ -0,0 +1,86 @@
+package mail
+
+import (
+	"bufio"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// smtpSession is what the stub server received.
+type smtpSession struct {
+	from string
+	to   []string
+	data string
+}
+
+// stubSMTPServer accepts one session without TLS or authentication and
+// reports what it received.
+func stubSMTPServer(t *testing.T) (string, int, <-chan smtpSession) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	received := make(chan smtpSession, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		text := textproto.NewConn(conn)
+		var session smtpSession
+		_ = text.PrintfLine("220 stub ESMTP")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch {
+			case command == "EHLO" || command == "HELO":
+				_ = text.PrintfLine("250 stub")
+			case strings.HasPrefix(strings.ToUpper(line), "MAIL FROM:"):
+				session.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+				_ = text.PrintfLine("250 OK")
+			case strings.HasPrefix(strings.ToUpper(line), "RCPT TO:"):
+				session.to = append(session.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+				_ = text.PrintfLine("250 OK")
+			case command == "DATA":
+				_ = text.PrintfLine("354 go ahead")
+				data, _ := text.ReadDotBytes()
+				session.data = string(data)
+				_ = text.PrintfLine("250 OK")
+			case command == "QUIT":
+				_ = text.PrintfLine("221 bye")
+				received <- session
+				return
+			default:
+				_ = text.PrintfLine("250 OK")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	return host, portNumber, received
+}
+
+func TestSMTPMailer_Send(t *testing.T) {
+	host, port, received := stubSMTPServer(t)
+	mailer := NewSMTPMailer(host, port, "", "", "Zentara <no-reply@example.com>")
+
+	assert.NoError(t, mailer.Send(testEmail()))
+
+	session := <-received
+	assert.Equal(t, "no-reply@example.com", session.from)
+	assert.Equal(t, []string{"user@example.com"}, session.to)
+	message, err := textproto.NewReader(bufio.NewReader(strings.NewReader(session.data))).ReadMIMEHeader()
+	assert.NoError(t, err)
+	assert.Equal(t, "Reset your password", message.Get("Subject"))
+}
@@ -0,0 +1,174 @@
+package misp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+	"threat-intel-backend/domain"
+	"github.com/google/uuid"
+)
+
+// Confidence of indicators from attributes that are and are not flagged for
+// detection (to_ids). Attributes without the flag are context, e.g. a
+// legitimate domain abused by the campaign, and false positives as IOCs.
+const (
+	idsConfidence    = 75
+	nonIDSConfidence = 25
+)
+
+var ErrUnsupportedType = errors.New("unsupported MISP attribute type")
+
+// Options tune the conversion of the events of one feed.
+type Options struct {
+	// Source is set as the source of the indicators. Defaults to the name
+	// of the organisation that created the event.
+	Source string
+	// IDSOnly skips attributes that are not flagged for detection.
+	IDSOnly bool
+}
+
+// ToIndicators converts the attributes of an event, those of its objects
+// included, into indicators. Indicators keep the attribute UUID in their STIX
+// ID, so that pulling an event again updates them. Attributes that cannot be
+// converted are skipped and explained in the returned errors; deleted ones,
+// and with IDSOnly those not flagged for detection, are skipped silently.
+func ToIndicators(event *Event, options Options) ([]*domain.Indicator, []error) {
+	attributes := append([]Attribute{}, event.Attributes...)
+	for _, object := range event.Objects {
+		attributes = append(attributes, object.Attributes...)
+	}
+
+	eventTags := append(tagNames(event.Tags), clusterTags(event.Galaxies)...)
+	severity := severityOf(event.ThreatLevelID)
+	source := options.Source
+	if source == "" {
+		source = event.Orgc.Name
+	}
+
+	var indicators []*domain.Indicator
+	var errs []error
+	for _, attribute := range attributes {
+		if attribute.Deleted || (options.IDSOnly && !attribute.ToIDS) {
+			continue
+		}
+
+		indicator, err := toIndicator(attribute, severity)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("attribute %s: %w", attribute.UUID, err))
+			continue
+		}
+
+		tags := append(tagNames(attribute.Tags), clusterTags(attribute.Galaxies)...)
+		indicator.Tags = domain.NormalizeTags(append(tags, eventTags...))
+		indicator.Source = source
+		indicators = append(indicators, indicator)
+	}
+	return indicators, errs
+}
+
+func toIndicator(attribute Attribute, severity domain.Severity) (*domain.Indicator, error) {
+	indicatorType, value, err := MapAttribute(attribute.Type, attribute.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	confidence := nonIDSConfidence
+	if attribute.ToIDS {
+		confidence = idsConfidence
+	}
+
+	indicator, err := domain.NewIndicator(indicatorType, value, confidence, severity)
+	if err != nil {
+		return nil, err
+	}
+
+	if id, err := uuid.Parse(attribute.UUID); err == nil {
+		indicator.StixID = domain.NewStixID("indicator", id)
+	}
+
+	seen := attribute.Timestamp.Time
+	if seen.IsZero() {
+		seen = time.Now()
+	}
+	indicator.FirstSeen, indicator.LastSeen = seen, seen
+	if attribute.FirstSeen != nil {
+		indicator.FirstSeen = *attribute.FirstSeen
+		indicator.LastSeen = *attribute.FirstSeen
+	}
+	if attribute.LastSeen != nil {
+		indicator.Seen(*attribute.LastSeen)
+	}
+
+	return indicator, nil
+}
+
+// MapAttribute returns the indicator type of a MISP attribute type and the
+// part of a composite value, such as filename|md5, that is the observable.
+func MapAttribute(attributeType, value string) (domain.IndicatorType, string, error) {
+	switch attributeType {
+	case "ip-src", "ip-dst":
+		return ipType(value), value, nil
+	case "ip-src|port", "ip-dst|port":
+		ip, _, _ := strings.Cut(value, "|")
+		return ipType(ip), ip, nil
+	case "domain", "hostname":
+		return domain.IndicatorDomain, value, nil
+	case "domain|ip", "hostname|port":
+		host, _, _ := strings.Cut(value, "|")
+		return domain.IndicatorDomain, host, nil
+	case "url":
+		return domain.IndicatorURL, value, nil
+	case "md5", "sha1", "sha256":
+		return domain.IndicatorType(attributeType), value, nil
+	case "filename|md5", "filename|sha1", "filename|sha256":
+		_, hash, _ := strings.Cut(value, "|")
+		return domain.IndicatorType(strings.TrimPrefix(attributeType, "filename|")), hash, nil
+	case "email", "email-src", "email-dst":
+		return domain.IndicatorEmail, value, nil
+	}
+	return "", "", fmt.Errorf("%w: %q", ErrUnsupportedType, attributeType)
+}
+
+func ipType(value string) domain.IndicatorType {
+	if strings.Contains(value, ":") && net.ParseIP(value) != nil {
+		return domain.IndicatorIPv6
+	}
+	return domain.IndicatorIPv4
+}
+
+// severityOf maps MISP threat levels (1 high, 2 medium, 3 low, 4 undefined).
+func severityOf(threatLevel Number) domain.Severity {
+	switch threatLevel {
+	case 1:
+		return domain.SeverityHigh
+	case 3:
+		return domain.SeverityLow
+	}
+	return domain.SeverityMedium
+}
+
+func tagNames(tags []Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+// clusterTags returns the tags of the galaxy clusters, e.g.
+// misp-galaxy:threat-actor="Sofacy", which is how MISP tags with them.
+func clusterTags(galaxies []Galaxy) []string {
+	var names []string
+	for _, galaxy := range galaxies {
+		for _, cluster := range galaxy.Clusters {
+			name := cluster.TagName
+			if name == "" {
+				name = fmt.Sprintf("misp-galaxy:%s=%q", galaxy.Type, cluster.Value)
+			}
+			names = append(names, name)
+		}
+	}
+	return names
+}
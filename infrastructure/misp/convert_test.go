@@ -0,0 +1,133 @@
+package misp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"threat-intel-backend/domain"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func loadEvent(t *testing.T, eventUUID string) *Event {
+	event, err := NewFeed("testdata/feed", nil).Event(context.Background(), eventUUID)
+	if err != nil {
+		t.Fatalf("failed to load fixture: %v", err)
+	}
+	return event
+}
+
+func indicatorsByValue(indicators []*domain.Indicator) map[string]*domain.Indicator {
+	byValue := make(map[string]*domain.Indicator, len(indicators))
+	for _, indicator := range indicators {
+		byValue[indicator.Value] = indicator
+	}
+	return byValue
+}
+
+func TestToIndicators(t *testing.T) {
+	indicators, errs := ToIndicators(loadEvent(t, campaignEvent), Options{})
+	byValue := indicatorsByValue(indicators)
+
+	assert.Len(t, indicators, 4)
+	assert.Len(t, errs, 1)
+	assert.True(t, errors.Is(errs[0], ErrUnsupportedType))
+	assert.Contains(t, errs[0].Error(), "5e6f7a8b-9c0d-4e1f-8a3b-4c5d6e7f8a9b")
+	assert.NotContains(t, byValue, "198.51.100.7", "deleted attributes are skipped")
+
+	t.Run("maps composite values, types and to_ids", func(t *testing.T) {
+		ip := byValue["203.0.113.10"]
+		if assert.NotNil(t, ip) {
+			assert.Equal(t, domain.IndicatorIPv4, ip.Type)
+			assert.Equal(t, 75, ip.Confidence)
+			assert.Equal(t, domain.SeverityHigh, ip.Severity)
+			assert.Equal(t, "CIRCL", ip.Source)
+			assert.Equal(t, "indicator--1a2b3c4d-5e6f-4a7b-8c9d-0e1f2a3b4c5d", ip.StixID)
+		}
+
+		domainIndicator := byValue["login-portal.example.com"]
+		if assert.NotNil(t, domainIndicator) {
+			assert.Equal(t, 25, domainIndicator.Confidence)
+		}
+
+		assert.Contains(t, byValue, "hr@example.org")
+		hash := byValue["E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855"]
+		if assert.NotNil(t, hash, "object attributes are converted") {
+			assert.Equal(t, domain.IndicatorSHA256, hash.Type)
+		}
+	})
+
+	t.Run("tags with event and attribute tags and galaxies", func(t *testing.T) {
+		assert.Equal(t, domain.Tags{
+			"kill-chain:command-and-control",
+			`misp-galaxy:threat-actor="sofacy"`,
+			"tlp:white",
+		}, byValue["203.0.113.10"].Tags)
+		assert.Contains(t, byValue["login-portal.example.com"].Tags, `misp-galaxy:mitre-attack-pattern="phishing - t1566"`)
+	})
+
+	t.Run("keeps first and last seen", func(t *testing.T) {
+		ip := byValue["203.0.113.10"]
+		assert.True(t, time.Date(2023, 12, 30, 8, 0, 0, 0, time.UTC).Equal(ip.FirstSeen))
+		assert.True(t, time.Date(2023, 12, 31, 20, 0, 0, 0, time.UTC).Equal(ip.LastSeen))
+
+		email := byValue["hr@example.org"]
+		assert.True(t, campaignTimestamp.Equal(email.FirstSeen))
+		assert.True(t, campaignTimestamp.Equal(email.LastSeen))
+	})
+}
+
+func TestToIndicators_Options(t *testing.T) {
+	indicators, _ := ToIndicators(loadEvent(t, campaignEvent), Options{Source: "circl-osint", IDSOnly: true})
+
+	assert.Len(t, indicators, 3)
+	for _, indicator := range indicators {
+		assert.Equal(t, 75, indicator.Confidence)
+		assert.Equal(t, "circl-osint", indicator.Source)
+	}
+}
+
+func TestMapAttribute(t *testing.T) {
+	tests := []struct {
+		attributeType string
+		value         string
+		indicatorType domain.IndicatorType
+		observable    string
+	}{
+		{"ip-src", "192.0.2.1", domain.IndicatorIPv4, "192.0.2.1"},
+		{"ip-dst", "2001:db8::1", domain.IndicatorIPv6, "2001:db8::1"},
+		{"ip-src|port", "2001:db8::1|8080", domain.IndicatorIPv6, "2001:db8::1"},
+		{"hostname", "mail.example.com", domain.IndicatorDomain, "mail.example.com"},
+		{"domain|ip", "example.com|192.0.2.1", domain.IndicatorDomain, "example.com"},
+		{"url", "http://example.com/a", domain.IndicatorURL, "http://example.com/a"},
+		{"sha1", "da39a3ee5e6b4b0d3255bfef95601890afd80709", domain.IndicatorSHA1, "da39a3ee5e6b4b0d3255bfef95601890afd80709"},
+		{"filename|md5", "a.exe|d41d8cd98f00b204e9800998ecf8427e", domain.IndicatorMD5, "d41d8cd98f00b204e9800998ecf8427e"},
+		{"email-dst", "a@example.com", domain.IndicatorEmail, "a@example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.attributeType, func(t *testing.T) {
+			indicatorType, observable, err := MapAttribute(tt.attributeType, tt.value)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.indicatorType, indicatorType)
+			assert.Equal(t, tt.observable, observable)
+		})
+	}
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, _, err := MapAttribute("regkey", `HKLM\Software\Run`)
+
+		assert.True(t, errors.Is(err, ErrUnsupportedType))
+	})
+}
+
+func TestToIndicators_Severity(t *testing.T) {
+	indicators, errs := ToIndicators(loadEvent(t, hashesEvent), Options{})
+
+	assert.Empty(t, errs)
+	for _, indicator := range indicators {
+		assert.Equal(t, domain.SeverityLow, indicator.Severity)
+	}
+	assert.Contains(t, indicatorsByValue(indicators), "2001:db8::1")
+}
@@ -0,0 +1,255 @@
+// Package misp reads MISP feeds: a manifest.json listing the events of the
+// feed, and one <uuid>.json file per event, served from a directory or over
+// HTTP. Events are converted into domain indicators.
+package misp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"github.com/google/uuid"
+)
+
+// maxDocumentSize bounds the manifest and event files read from a feed.
+const maxDocumentSize = 64 << 20
+
+var (
+	ErrInvalidFeed  = errors.New("invalid MISP feed")
+	ErrInvalidEvent = errors.New("invalid MISP event")
+)
+
+// Manifest maps the UUIDs of the events of a feed to their summary.
+type Manifest map[string]ManifestEntry
+
+type ManifestEntry struct {
+	Info          string    `json:"info"`
+	Orgc          Org       `json:"Orgc"`
+	Tags          []Tag     `json:"Tag"`
+	ThreatLevelID Number    `json:"threat_level_id"`
+	Timestamp     Timestamp `json:"timestamp"`
+}
+
+type Org struct {
+	Name string `json:"name"`
+	UUID string `json:"uuid"`
+}
+
+type Tag struct {
+	Name string `json:"name"`
+}
+
+type Event struct {
+	UUID          string      `json:"uuid"`
+	Info          string      `json:"info"`
+	Orgc          Org         `json:"Orgc"`
+	ThreatLevelID Number      `json:"threat_level_id"`
+	Timestamp     Timestamp   `json:"timestamp"`
+	Tags          []Tag       `json:"Tag"`
+	Galaxies      []Galaxy    `json:"Galaxy"`
+	Attributes    []Attribute `json:"Attribute"`
+	Objects       []Object    `json:"Object"`
+}
+
+// Object groups attributes describing one thing, e.g. a file and its hashes.
+type Object struct {
+	Name       string      `json:"name"`
+	Attributes []Attribute `json:"Attribute"`
+}
+
+type Attribute struct {
+	UUID      string     `json:"uuid"`
+	Type      string     `json:"type"`
+	Category  string     `json:"category"`
+	Value     string     `json:"value"`
+	ToIDS     bool       `json:"to_ids"`
+	Deleted   bool       `json:"deleted"`
+	Timestamp Timestamp  `json:"timestamp"`
+	FirstSeen *time.Time `json:"first_seen"`
+	LastSeen  *time.Time `json:"last_seen"`
+	Tags      []Tag      `json:"Tag"`
+	Galaxies  []Galaxy   `json:"Galaxy"`
+}
+
+// Galaxy is a kind of knowledge, e.g. threat actors, whose clusters an event
+// or attribute is attached to.
+type Galaxy struct {
+	Name     string          `json:"name"`
+	Type     string          `json:"type"`
+	Clusters []GalaxyCluster `json:"GalaxyCluster"`
+}
+
+type GalaxyCluster struct {
+	Value   string `json:"value"`
+	TagName string `json:"tag_name"`
+}
+
+// Timestamp is a Unix time, which MISP encodes as a string or a number.
+type Timestamp struct {
+	time.Time
+}
+
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	var seconds Number
+	if err := seconds.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	t.Time = time.Time{}
+	if seconds != 0 {
+		t.Time = time.Unix(int64(seconds), 0).UTC()
+	}
+	return nil
+}
+
+// Number is an integer that MISP encodes as a string or a number.
+type Number int64
+
+func (n *Number) UnmarshalJSON(data []byte) error {
+	raw := strings.Trim(string(data), `"`)
+	if raw == "" || raw == "null" {
+		*n = 0
+		return nil
+	}
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %s", data)
+	}
+	*n = Number(value)
+	return nil
+}
+
+// Feed is a MISP feed at a URL or in a local directory.
+type Feed struct {
+	location string
+	client   *http.Client
+}
+
+// NewFeed returns the feed at location, an http(s) URL or a directory.
+func NewFeed(location string, client *http.Client) *Feed {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Feed{location: strings.TrimSuffix(location, "/"), client: client}
+}
+
+func (f *Feed) Manifest(ctx context.Context) (Manifest, error) {
+	var manifest Manifest
+	if err := f.read(ctx, "manifest.json", &manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func (f *Feed) Event(ctx context.Context, eventUUID string) (*Event, error) {
+	// The UUID becomes a file name, so it must not be able to name another
+	// file.
+	if _, err := uuid.Parse(eventUUID); err != nil {
+		return nil, fmt.Errorf("%w: malformed event UUID %q", ErrInvalidFeed, eventUUID)
+	}
+
+	var document struct {
+		Event *Event `json:"Event"`
+	}
+	if err := f.read(ctx, eventUUID+".json", &document); err != nil {
+		return nil, err
+	}
+	if document.Event == nil {
+		return nil, fmt.Errorf("%w: %s has no Event", ErrInvalidEvent, eventUUID)
+	}
+	if document.Event.UUID == "" {
+		document.Event.UUID = eventUUID
+	}
+	return document.Event, nil
+}
+
+// Pull reads the events the manifest lists as changed after since, oldest
+// first, and passes each to fn. It returns the timestamp to pass as since to
+// the next pull: the newest one of the events done, or since if none is.
+// When fn or reading an event fails, events done so far are not read again,
+// but the rest of the events with their timestamp are.
+func (f *Feed) Pull(ctx context.Context, since time.Time, fn func(*Event) error) (time.Time, error) {
+	manifest, err := f.Manifest(ctx)
+	if err != nil {
+		return since, err
+	}
+
+	changed := manifest.ChangedSince(since)
+	watermark := since
+	for i, eventUUID := range changed {
+		event, err := f.Event(ctx, eventUUID)
+		if err != nil {
+			return watermark, err
+		}
+		if err := fn(event); err != nil {
+			return watermark, err
+		}
+
+		timestamp := manifest[eventUUID].Timestamp.Time
+		if i == len(changed)-1 || !manifest[changed[i+1]].Timestamp.Equal(timestamp) {
+			watermark = timestamp
+		}
+	}
+	return watermark, nil
+}
+
+// ChangedSince returns the UUIDs of the events with a timestamp after since,
+// ordered by timestamp.
+func (m Manifest) ChangedSince(since time.Time) []string {
+	var changed []string
+	for eventUUID, entry := range m {
+		if entry.Timestamp.After(since) {
+			changed = append(changed, eventUUID)
+		}
+	}
+	sort.Slice(changed, func(i, j int) bool {
+		a, b := m[changed[i]].Timestamp.Time, m[changed[j]].Timestamp.Time
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return changed[i] < changed[j]
+	})
+	return changed
+}
+
+func (f *Feed) read(ctx context.Context, name string, v interface{}) error {
+	body, err := f.open(ctx, name)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidFeed, err)
+	}
+	defer body.Close()
+
+	if err := json.NewDecoder(io.LimitReader(body, maxDocumentSize)).Decode(v); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidFeed, name, err)
+	}
+	return nil
+}
+
+func (f *Feed) open(ctx context.Context, name string) (io.ReadCloser, error) {
+	if !strings.HasPrefix(f.location, "http://") && !strings.HasPrefix(f.location, "https://") {
+		return os.Open(filepath.Join(f.location, name))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.location+"/"+name, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: status %d", req.URL, resp.StatusCode)
+	}
+	return resp.Body, nil
+}
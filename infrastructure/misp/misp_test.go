@@ -0,0 +1,164 @@
+package misp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	campaignEvent = "5f6b1c2a-8d3e-4f7a-9b1c-2d3e4f5a6b7c"
+	hashesEvent   = "6a7b8c9d-0e1f-4a2b-8c3d-4e5f6a7b8c9d"
+)
+
+var (
+	campaignTimestamp = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	hashesTimestamp   = time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// serveFeed serves the fixture feed and records the paths requested.
+func serveFeed(t *testing.T) (*Feed, *[]string) {
+	var requested []string
+	files := http.FileServer(http.Dir("testdata/feed"))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		files.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return NewFeed(server.URL+"/", server.Client()), &requested
+}
+
+func TestFeed_Manifest(t *testing.T) {
+	feed, _ := serveFeed(t)
+
+	manifest, err := feed.Manifest(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, manifest, 2)
+	assert.Equal(t, "CIRCL", manifest[campaignEvent].Orgc.Name)
+	assert.Equal(t, Number(1), manifest[campaignEvent].ThreatLevelID)
+	assert.True(t, campaignTimestamp.Equal(manifest[campaignEvent].Timestamp.Time))
+}
+
+func TestFeed_Event(t *testing.T) {
+	feed, _ := serveFeed(t)
+
+	t.Run("reads an event", func(t *testing.T) {
+		event, err := feed.Event(context.Background(), hashesEvent)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Commodity stealer hashes", event.Info)
+		assert.Len(t, event.Attributes, 2)
+		assert.True(t, hashesTimestamp.Equal(event.Attributes[0].Timestamp.Time))
+	})
+
+	t.Run("missing event", func(t *testing.T) {
+		_, err := feed.Event(context.Background(), "00000000-0000-4000-8000-000000000000")
+
+		assert.True(t, errors.Is(err, ErrInvalidFeed))
+		assert.Contains(t, err.Error(), "status 404")
+	})
+
+	t.Run("rejects a UUID that is a path", func(t *testing.T) {
+		_, err := feed.Event(context.Background(), "../manifest")
+
+		assert.True(t, errors.Is(err, ErrInvalidFeed))
+	})
+}
+
+func TestFeed_Pull(t *testing.T) {
+	t.Run("reads every event oldest first", func(t *testing.T) {
+		feed, _ := serveFeed(t)
+		var pulled []string
+
+		watermark, err := feed.Pull(context.Background(), time.Time{}, func(event *Event) error {
+			pulled = append(pulled, event.UUID)
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{campaignEvent, hashesEvent}, pulled)
+		assert.True(t, hashesTimestamp.Equal(watermark))
+	})
+
+	t.Run("reads only events changed since the last pull", func(t *testing.T) {
+		feed, requested := serveFeed(t)
+		var pulled []string
+
+		watermark, err := feed.Pull(context.Background(), campaignTimestamp, func(event *Event) error {
+			pulled = append(pulled, event.UUID)
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{hashesEvent}, pulled)
+		assert.True(t, hashesTimestamp.Equal(watermark))
+		assert.Equal(t, []string{"/manifest.json", "/" + hashesEvent + ".json"}, *requested)
+	})
+
+	t.Run("nothing changed", func(t *testing.T) {
+		feed, _ := serveFeed(t)
+
+		watermark, err := feed.Pull(context.Background(), hashesTimestamp, func(event *Event) error {
+			t.Errorf("unexpected event %s", event.UUID)
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.True(t, hashesTimestamp.Equal(watermark))
+	})
+
+	t.Run("stops at the first failure", func(t *testing.T) {
+		feed, _ := serveFeed(t)
+		failure := errors.New("database unavailable")
+
+		watermark, err := feed.Pull(context.Background(), time.Time{}, func(event *Event) error {
+			if event.UUID == hashesEvent {
+				return failure
+			}
+			return nil
+		})
+
+		assert.Equal(t, failure, err)
+		assert.True(t, campaignTimestamp.Equal(watermark))
+	})
+}
+
+func TestFeed_Directory(t *testing.T) {
+	feed := NewFeed("testdata/feed", nil)
+	var pulled []string
+
+	_, err := feed.Pull(context.Background(), time.Time{}, func(event *Event) error {
+		pulled = append(pulled, event.UUID)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{campaignEvent, hashesEvent}, pulled)
+}
+
+func TestManifest_ChangedSince(t *testing.T) {
+	at := func(seconds int64) ManifestEntry {
+		return ManifestEntry{Timestamp: Timestamp{time.Unix(seconds, 0)}}
+	}
+	manifest := Manifest{"c": at(20), "a": at(30), "b": at(20), "d": at(10)}
+
+	assert.Equal(t, []string{"b", "c", "a"}, manifest.ChangedSince(time.Unix(10, 0)))
+	assert.Empty(t, manifest.ChangedSince(time.Unix(30, 0)))
+}
+
+func TestTimestamp_UnmarshalJSON(t *testing.T) {
+	for _, data := range []string{`"1704067200"`, `1704067200`} {
+		var timestamp Timestamp
+		assert.NoError(t, timestamp.UnmarshalJSON([]byte(data)))
+		assert.True(t, campaignTimestamp.Equal(timestamp.Time))
+	}
+
+	var timestamp Timestamp
+	assert.Error(t, timestamp.UnmarshalJSON([]byte(`"yesterday"`)))
+}
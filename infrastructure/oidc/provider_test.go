@@ -0,0 +1,250 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"threat-intel-backend/infrastructure/jwt"
+	"time"
+
+	jwtlib "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubIdP is a local OpenID provider: it serves discovery and a JWK Set, and
+// issues ID tokens for codes registered with their PKCE challenge.
+type stubIdP struct {
+	server     *httptest.Server
+	keys       []*jwt.Key
+	codes      map[string]string
+	claims     jwtlib.MapClaims
+	jwksRequests int
+}
+
+func newStubIdP(t *testing.T) *stubIdP {
+	idp := &stubIdP{codes: map[string]string{}}
+	idp.rotate(t, "idp-1")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(Metadata{
+			Issuer:                idp.server.URL,
+			AuthorizationEndpoint: idp.server.URL + "/authorize",
+			TokenEndpoint:         idp.server.URL + "/token",
+			JWKSURI:               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		idp.jwksRequests++
+		set := jwt.JWKSet{Keys: []jwt.JWK{}}
+		for _, key := range idp.keys {
+			set.Keys = append(set.Keys, key.JWK())
+		}
+		_ = json.NewEncoder(w).Encode(set)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		challenge, ok := idp.codes[r.PostForm.Get("code")]
+		if !ok || challenge != base64.RawURLEncoding.EncodeToString(sum[:]) {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"id_token": idp.sign(t, idp.claims)})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *stubIdP) rotate(t *testing.T, kid string) {
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	key, err := jwt.NewKey(kid, private)
+	assert.NoError(t, err)
+	idp.keys = append([]*jwt.Key{key}, idp.keys...)
+}
+
+func (idp *stubIdP) sign(t *testing.T, claims jwtlib.MapClaims) string {
+	key := idp.keys[0]
+	token := jwtlib.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	signed, err := token.SignedString(key.Private)
+	assert.NoError(t, err)
+	return signed
+}
+
+func (idp *stubIdP) idClaims(nonce string) jwtlib.MapClaims {
+	now := time.Now()
+	return jwtlib.MapClaims{
+		"iss":            idp.server.URL,
+		"sub":            "idp-user-1",
+		"aud":            "threat-intel",
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          nonce,
+		"email":          "jane@example.com",
+		"email_verified": true,
+		"groups":         []string{"intel-analysts"},
+	}
+}
+
+func discover(t *testing.T, idp *stubIdP) *Provider {
+	provider, err := Discover(context.Background(), Config{
+		IssuerURL:   idp.server.URL,
+		ClientID:    "threat-intel",
+		RedirectURL: "https://app.example.com/auth/oidc/callback",
+	}, idp.server.Client())
+	assert.NoError(t, err)
+	return provider
+}
+
+func TestDiscover(t *testing.T) {
+	idp := newStubIdP(t)
+
+	t.Run("reads provider metadata", func(t *testing.T) {
+		provider := discover(t, idp)
+
+		assert.Equal(t, idp.server.URL, provider.Issuer())
+	})
+
+	t.Run("rejects issuer mismatch", func(t *testing.T) {
+		_, err := Discover(context.Background(), Config{IssuerURL: idp.server.URL + "/other"}, idp.server.Client())
+
+		assert.ErrorIs(t, err, ErrDiscovery)
+	})
+}
+
+func TestProvider_AuthCodeURL(t *testing.T) {
+	provider := discover(t, newStubIdP(t))
+
+	authURL, err := url.Parse(provider.AuthCodeURL("state-1", "nonce-1", "challenge-1"))
+
+	assert.NoError(t, err)
+	query := authURL.Query()
+	assert.Equal(t, "/authorize", authURL.Path)
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, "threat-intel", query.Get("client_id"))
+	assert.Equal(t, "openid email profile", query.Get("scope"))
+	assert.Equal(t, "state-1", query.Get("state"))
+	assert.Equal(t, "nonce-1", query.Get("nonce"))
+	assert.Equal(t, "challenge-1", query.Get("code_challenge"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+}
+
+func TestProvider_Login(t *testing.T) {
+	idp := newStubIdP(t)
+	provider := discover(t, idp)
+	ctx := context.Background()
+
+	login := func(t *testing.T, claims jwtlib.MapClaims) (*Identity, error) {
+		verifier, challenge, err := NewPKCE()
+		assert.NoError(t, err)
+		idp.codes["code-1"] = challenge
+		idp.claims = claims
+
+		rawIDToken, err := provider.Exchange(ctx, "code-1", verifier)
+		assert.NoError(t, err)
+		return provider.VerifyIDToken(ctx, rawIDToken, "nonce-1")
+	}
+
+	t.Run("verifies the ID token", func(t *testing.T) {
+		identity, err := login(t, idp.idClaims("nonce-1"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "idp-user-1", identity.Subject)
+		assert.Equal(t, "jane@example.com", identity.Email)
+		assert.True(t, identity.EmailVerified)
+		assert.Equal(t, []string{"intel-analysts"}, identity.Groups)
+	})
+
+	t.Run("rejects wrong code verifier", func(t *testing.T) {
+		_, challenge, _ := NewPKCE()
+		idp.codes["code-2"] = challenge
+
+		_, err := provider.Exchange(ctx, "code-2", "not-the-verifier")
+
+		assert.ErrorIs(t, err, ErrTokenExchange)
+	})
+
+	t.Run("rejects wrong nonce", func(t *testing.T) {
+		_, err := login(t, idp.idClaims("nonce-2"))
+
+		assert.ErrorIs(t, err, ErrInvalidIDToken)
+	})
+
+	t.Run("rejects wrong audience", func(t *testing.T) {
+		claims := idp.idClaims("nonce-1")
+		claims["aud"] = "another-client"
+
+		_, err := login(t, claims)
+
+		assert.ErrorIs(t, err, ErrInvalidIDToken)
+	})
+
+	t.Run("rejects unmatched azp", func(t *testing.T) {
+		claims := idp.idClaims("nonce-1")
+		claims["aud"] = []string{"threat-intel", "another-client"}
+		claims["azp"] = "another-client"
+
+		_, err := login(t, claims)
+
+		assert.ErrorIs(t, err, ErrInvalidIDToken)
+	})
+
+	t.Run("rejects expired token", func(t *testing.T) {
+		claims := idp.idClaims("nonce-1")
+		claims["exp"] = time.Now().Add(-time.Hour).Unix()
+
+		_, err := login(t, claims)
+
+		assert.ErrorIs(t, err, ErrInvalidIDToken)
+	})
+
+	t.Run("rejects HMAC-signed token", func(t *testing.T) {
+		token := jwtlib.NewWithClaims(jwtlib.SigningMethodHS256, idp.idClaims("nonce-1"))
+		signed, _ := token.SignedString([]byte("threat-intel"))
+
+		_, err := provider.VerifyIDToken(ctx, signed, "nonce-1")
+
+		assert.ErrorIs(t, err, ErrInvalidIDToken)
+	})
+}
+
+func TestProvider_KeyRotation(t *testing.T) {
+	idp := newStubIdP(t)
+	provider := discover(t, idp)
+	ctx := context.Background()
+
+	_, err := provider.VerifyIDToken(ctx, idp.sign(t, idp.idClaims("nonce-1")), "nonce-1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, idp.jwksRequests)
+
+	idp.rotate(t, "idp-2")
+	rotated := idp.sign(t, idp.idClaims("nonce-1"))
+
+	t.Run("refetch is rate limited", func(t *testing.T) {
+		_, err := provider.VerifyIDToken(ctx, rotated, "nonce-1")
+
+		assert.ErrorIs(t, err, ErrInvalidIDToken)
+		assert.Equal(t, 1, idp.jwksRequests)
+	})
+
+	t.Run("refetches keys for an unknown kid", func(t *testing.T) {
+		provider.fetchedAt = time.Now().Add(-keysRefreshInterval)
+
+		_, err := provider.VerifyIDToken(ctx, rotated, "nonce-1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, idp.jwksRequests)
+	})
+}
@@ -0,0 +1,114 @@
+// Package password checks passwords against lists of breached passwords.
+package password
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+//go:embed common.txt
+var commonPasswords string
+
+type digest [sha1.Size]byte
+
+// BreachedList is a set of breached passwords, kept as sorted SHA-1 digests
+// so that large lists take 20 bytes per entry.
+type BreachedList struct {
+	digests []digest
+}
+
+// CommonPasswords returns a list of the most common passwords, which is
+// always checked.
+func CommonPasswords() *BreachedList {
+	list := &BreachedList{}
+	if err := list.Load(strings.NewReader(commonPasswords)); err != nil {
+		panic(err)
+	}
+	return list
+}
+
+// LoadFile adds the entries of a file to the list. See Load.
+func (l *BreachedList) LoadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := l.Load(file); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// Load adds one entry per line: a password, or the hex SHA-1 digest of one
+// as in the Have I Been Pwned downloads, whose ":count" suffix is ignored.
+// Empty lines are skipped.
+func (l *BreachedList) Load(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		l.digests = append(l.digests, parseEntry(line))
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	sort.Slice(l.digests, func(i, j int) bool {
+		return bytes.Compare(l.digests[i][:], l.digests[j][:]) < 0
+	})
+	l.digests = dedupe(l.digests)
+	return nil
+}
+
+// Contains reports whether the password, or its lower case form, is on the
+// list.
+func (l *BreachedList) Contains(password string) bool {
+	return l.contains(sha1.Sum([]byte(password))) || l.contains(sha1.Sum([]byte(strings.ToLower(password))))
+}
+
+func (l *BreachedList) Len() int {
+	return len(l.digests)
+}
+
+func (l *BreachedList) contains(sum digest) bool {
+	i := sort.Search(len(l.digests), func(i int) bool {
+		return bytes.Compare(l.digests[i][:], sum[:]) >= 0
+	})
+	return i < len(l.digests) && l.digests[i] == sum
+}
+
+func parseEntry(line string) digest {
+	hash, _, _ := strings.Cut(line, ":")
+	if len(hash) == hex.EncodedLen(sha1.Size) {
+		var sum digest
+		if _, err := hex.Decode(sum[:], []byte(hash)); err == nil {
+			return sum
+		}
+	}
+	return sha1.Sum([]byte(line))
+}
+
+func dedupe(digests []digest) []digest {
+	if len(digests) == 0 {
+		return digests
+	}
+	unique := digests[:1]
+	for _, sum := range digests[1:] {
+		if sum != unique[len(unique)-1] {
+			unique = append(unique, sum)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,53 @@
+package password
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommonPasswords(t *testing.T) {
+	list := CommonPasswords()
+
+	assert.Greater(t, list.Len(), 100)
+	assert.True(t, list.Contains("password123"))
+	assert.True(t, list.Contains("PASSWORD123"))
+	assert.False(t, list.Contains("correct horse battery staple"))
+}
+
+func TestBreachedList_Load(t *testing.T) {
+	sum := sha1.Sum([]byte("hunter42"))
+	entries := strings.Join([]string{
+		strings.ToUpper(hex.EncodeToString(sum[:])) + ":1523",
+		"tr0ub4dor&3\r",
+		"",
+		"tr0ub4dor&3",
+	}, "\n")
+
+	list := &BreachedList{}
+	assert.NoError(t, list.Load(strings.NewReader(entries)))
+
+	assert.Equal(t, 2, list.Len())
+	assert.True(t, list.Contains("hunter42"))
+	assert.True(t, list.Contains("tr0ub4dor&3"))
+	assert.False(t, list.Contains("hunter43"))
+}
+
+func TestBreachedList_LoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("zentara2026\n"), 0o600))
+
+	list := CommonPasswords()
+	common := list.Len()
+	assert.NoError(t, list.LoadFile(path))
+
+	assert.Equal(t, common+1, list.Len())
+	assert.True(t, list.Contains("zentara2026"))
+	assert.True(t, list.Contains("password123"))
+	assert.Error(t, list.LoadFile(filepath.Join(t.TempDir(), "missing.txt")))
+}
@@ -32,8 +32,54 @@ func NewConnection(config Config) (*gorm.DB, error) {
 }
 
 func Migrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	// Users from before email verification registered without it; they are
+	// treated as verified rather than locked out.
+	backfillVerified := db.Migrator().HasTable(&domain.User{}) &&
+		!db.Migrator().HasColumn(&domain.User{}, "EmailVerifiedAt")
+
+	err := db.AutoMigrate(
 		&domain.User{},
 		&domain.Order{},
+		&domain.Indicator{},
+		&domain.ThreatEntity{},
+		&domain.Relationship{},
+		&domain.Entitlement{},
+		&domain.EntitlementSeat{},
+		&domain.Product{},
+		&domain.SecurityEvent{},
+		&domain.MFAFactor{},
+		&domain.MFAPolicy{},
+		&domain.APIKey{},
+		&domain.ExternalIdentity{},
+		&domain.Feed{},
+		&domain.FeedRun{},
+		&domain.Sighting{},
+		&domain.IndicatorVote{},
 	)
+	if err != nil {
+		return err
+	}
+
+	if backfillVerified {
+		err := db.Model(&domain.User{}).Where("email_verified_at IS NULL").
+			Update("email_verified_at", gorm.Expr("created_at")).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return seedProducts(db)
+}
+
+// seedProducts fills an empty catalog with the default products, so that a
+// fresh install can take orders before an admin has set up the catalog.
+func seedProducts(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&domain.Product{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return db.Create(domain.DefaultProducts()).Error
 }
\ No newline at end of file
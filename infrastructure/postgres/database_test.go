@@ -2,6 +2,8 @@ package postgres
 
 import (
 	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
 )
 
 func TestNewConnection(t *testing.T) {
@@ -37,4 +39,18 @@ func TestNewConnection(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestSeedProducts_SkipsExistingCatalog(t *testing.T) {
+	db, mock := newMockDB(t)
+
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "products"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	if err := seedProducts(db); err != nil {
+		t.Fatalf("seedProducts() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unexpected queries: %v", err)
+	}
+}
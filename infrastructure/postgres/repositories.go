@@ -1,11 +1,20 @@
 package postgres
 
 import (
+	"errors"
+	"strings"
+	"time"
 	"threat-intel-backend/domain"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// foreignKeyViolation is the PostgreSQL SQLSTATE for a row that is still
+// referenced by another table.
+const foreignKeyViolation = "23503"
+
 type UserRepository struct {
 	db *gorm.DB
 }
@@ -14,6 +23,62 @@ type OrderRepository struct {
 	db *gorm.DB
 }
 
+type IndicatorRepository struct {
+	db *gorm.DB
+}
+
+type ThreatEntityRepository struct {
+	db *gorm.DB
+}
+
+type RelationshipRepository struct {
+	db *gorm.DB
+}
+
+type EntitlementRepository struct {
+	db *gorm.DB
+}
+
+type ProductRepository struct {
+	db *gorm.DB
+}
+
+type SecurityEventRepository struct {
+	db *gorm.DB
+}
+
+type MFAFactorRepository struct {
+	db *gorm.DB
+}
+
+type MFAPolicyRepository struct {
+	db *gorm.DB
+}
+
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+type ExternalIdentityRepository struct {
+	db *gorm.DB
+}
+
+type FeedRepository struct {
+	db *gorm.DB
+}
+
+type FeedRunRepository struct {
+	db *gorm.DB
+}
+
+type SightingRepository struct {
+	db *gorm.DB
+}
+
+type IndicatorVoteRepository struct {
+	db *gorm.DB
+}
+
 func NewUserRepository(db *gorm.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
@@ -22,6 +87,62 @@ func NewOrderRepository(db *gorm.DB) *OrderRepository {
 	return &OrderRepository{db: db}
 }
 
+func NewIndicatorRepository(db *gorm.DB) *IndicatorRepository {
+	return &IndicatorRepository{db: db}
+}
+
+func NewThreatEntityRepository(db *gorm.DB) *ThreatEntityRepository {
+	return &ThreatEntityRepository{db: db}
+}
+
+func NewRelationshipRepository(db *gorm.DB) *RelationshipRepository {
+	return &RelationshipRepository{db: db}
+}
+
+func NewEntitlementRepository(db *gorm.DB) *EntitlementRepository {
+	return &EntitlementRepository{db: db}
+}
+
+func NewProductRepository(db *gorm.DB) *ProductRepository {
+	return &ProductRepository{db: db}
+}
+
+func NewSecurityEventRepository(db *gorm.DB) *SecurityEventRepository {
+	return &SecurityEventRepository{db: db}
+}
+
+func NewMFAFactorRepository(db *gorm.DB) *MFAFactorRepository {
+	return &MFAFactorRepository{db: db}
+}
+
+func NewMFAPolicyRepository(db *gorm.DB) *MFAPolicyRepository {
+	return &MFAPolicyRepository{db: db}
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func NewExternalIdentityRepository(db *gorm.DB) *ExternalIdentityRepository {
+	return &ExternalIdentityRepository{db: db}
+}
+
+func NewFeedRepository(db *gorm.DB) *FeedRepository {
+	return &FeedRepository{db: db}
+}
+
+func NewFeedRunRepository(db *gorm.DB) *FeedRunRepository {
+	return &FeedRunRepository{db: db}
+}
+
+func NewSightingRepository(db *gorm.DB) *SightingRepository {
+	return &SightingRepository{db: db}
+}
+
+func NewIndicatorVoteRepository(db *gorm.DB) *IndicatorVoteRepository {
+	return &IndicatorVoteRepository{db: db}
+}
+
 func (r *UserRepository) Save(user *domain.User) error {
 	return r.db.Save(user).Error
 }
@@ -57,8 +178,536 @@ func (r *OrderRepository) FindByID(id uuid.UUID) (*domain.Order, error) {
 	return &order, nil
 }
 
+func (r *OrderRepository) FindByIDForUpdate(id uuid.UUID) (*domain.Order, error) {
+	var order domain.Order
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("User").Where("id = ?", id).First(&order).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
 func (r *OrderRepository) FindByUserID(userID uuid.UUID) ([]*domain.Order, error) {
 	var orders []*domain.Order
 	err := r.db.Where("user_id = ?", userID).Find(&orders).Error
 	return orders, err
-}
\ No newline at end of file
+}
+
+func (r *UserRepository) List(filter domain.UserFilter) ([]*domain.User, int64, error) {
+	query := r.db.Model(&domain.User{})
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+	if filter.EmailPrefix != "" {
+		query = query.Where("email LIKE ?", escapeLike(filter.EmailPrefix)+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []*domain.User
+	err := query.Order("created_at DESC").Offset(filter.Offset).Limit(filter.Limit).Find(&users).Error
+	return users, total, err
+}
+
+// Delete removes a user. Orders reference their user, so deleting a user who
+// has orders fails with domain.ErrUserHasOrders.
+func (r *UserRepository) Delete(id uuid.UUID) error {
+	err := r.db.Where("id = ?", id).Delete(&domain.User{}).Error
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+		return domain.ErrUserHasOrders
+	}
+	return err
+}
+
+func (r *IndicatorRepository) Save(indicator *domain.Indicator) error {
+	return r.db.Save(indicator).Error
+}
+
+func (r *IndicatorRepository) FindByID(id uuid.UUID) (*domain.Indicator, error) {
+	var indicator domain.Indicator
+	err := r.db.Where("id = ?", id).First(&indicator).Error
+	if err != nil {
+		return nil, err
+	}
+	return &indicator, nil
+}
+
+func (r *IndicatorRepository) FindByValue(indicatorType domain.IndicatorType, value string) (*domain.Indicator, error) {
+	var indicator domain.Indicator
+	err := r.db.Where("type = ? AND value = ?", indicatorType, value).First(&indicator).Error
+	if err != nil {
+		return nil, err
+	}
+	return &indicator, nil
+}
+
+func (r *IndicatorRepository) FindByStixID(stixID string) (*domain.Indicator, error) {
+	var indicator domain.Indicator
+	err := r.db.Where("stix_id = ?", stixID).First(&indicator).Error
+	if err != nil {
+		return nil, err
+	}
+	return &indicator, nil
+}
+
+// FindByKeys loads the indicators of several type and value pairs in one
+// query; keys without an indicator are left out.
+func (r *IndicatorRepository) FindByKeys(keys []domain.IndicatorKey) ([]*domain.Indicator, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	pairs := make([][]interface{}, len(keys))
+	for i, key := range keys {
+		pairs[i] = []interface{}{key.Type, key.Value}
+	}
+
+	var indicators []*domain.Indicator
+	err := r.db.Where("(type, value) IN ?", pairs).Find(&indicators).Error
+	return indicators, err
+}
+
+func (r *IndicatorRepository) List(filter domain.IndicatorFilter) ([]*domain.Indicator, int64, error) {
+	query := r.filterIndicators(filter)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var indicators []*domain.Indicator
+	err := query.Order("last_seen DESC").Offset(filter.Offset).Limit(filter.Limit).Find(&indicators).Error
+	return indicators, total, err
+}
+
+// ListChanges pages through indicators in update order using keyset
+// pagination, so that concurrent updates never cause rows to be skipped.
+func (r *IndicatorRepository) ListChanges(filter domain.IndicatorFilter, after domain.IndicatorCursor) ([]*domain.Indicator, error) {
+	query := r.filterIndicators(filter)
+	if !after.UpdatedAt.IsZero() {
+		if after.ID != uuid.Nil {
+			query = query.Where("updated_at > ? OR (updated_at = ? AND id > ?)", after.UpdatedAt, after.UpdatedAt, after.ID)
+		} else {
+			query = query.Where("updated_at > ?", after.UpdatedAt)
+		}
+	}
+
+	var indicators []*domain.Indicator
+	err := query.Order("updated_at ASC, id ASC").Limit(filter.Limit).Find(&indicators).Error
+	return indicators, err
+}
+
+func (r *IndicatorRepository) ListUnexpired(now time.Time, after uuid.UUID, limit int) ([]*domain.Indicator, error) {
+	var indicators []*domain.Indicator
+	err := r.db.Where("(expires_at IS NULL OR expires_at > ?) AND id > ?", now, after).
+		Order("id").Limit(limit).Find(&indicators).Error
+	return indicators, err
+}
+
+// SaveScore writes the scoring columns only, so that rescoring does not show
+// up as a change in update order unless it expired the indicator.
+func (r *IndicatorRepository) SaveScore(indicator *domain.Indicator) error {
+	return r.db.Model(&domain.Indicator{}).Where("id = ?", indicator.ID).UpdateColumns(map[string]interface{}{
+		"score":      indicator.Score,
+		"scored_at":  indicator.ScoredAt,
+		"expires_at": indicator.ExpiresAt,
+		"updated_at": indicator.UpdatedAt,
+	}).Error
+}
+
+// Delete removes an indicator together with its sightings and votes.
+func (r *IndicatorRepository) Delete(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("indicator_id = ?", id).Delete(&domain.Sighting{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("indicator_id = ?", id).Delete(&domain.IndicatorVote{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&domain.Indicator{}).Error
+	})
+}
+
+func (r *ThreatEntityRepository) Save(entity *domain.ThreatEntity) error {
+	return r.db.Save(entity).Error
+}
+
+func (r *ThreatEntityRepository) FindByStixID(stixID string) (*domain.ThreatEntity, error) {
+	var entity domain.ThreatEntity
+	err := r.db.Where("stix_id = ?", stixID).First(&entity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (r *ThreatEntityRepository) FindByStixIDs(stixIDs []string) ([]*domain.ThreatEntity, error) {
+	var entities []*domain.ThreatEntity
+	if len(stixIDs) == 0 {
+		return entities, nil
+	}
+	err := r.db.Where("stix_id IN ?", stixIDs).Find(&entities).Error
+	return entities, err
+}
+
+func (r *RelationshipRepository) Save(relationship *domain.Relationship) error {
+	return r.db.Save(relationship).Error
+}
+
+func (r *RelationshipRepository) FindByStixID(stixID string) (*domain.Relationship, error) {
+	var relationship domain.Relationship
+	err := r.db.Where("stix_id = ?", stixID).First(&relationship).Error
+	if err != nil {
+		return nil, err
+	}
+	return &relationship, nil
+}
+
+func (r *RelationshipRepository) FindBySourceRefs(sourceRefs []string) ([]*domain.Relationship, error) {
+	var relationships []*domain.Relationship
+	if len(sourceRefs) == 0 {
+		return relationships, nil
+	}
+	err := r.db.Where("source_ref IN ?", sourceRefs).Find(&relationships).Error
+	return relationships, err
+}
+
+func (r *EntitlementRepository) Save(entitlement *domain.Entitlement) error {
+	return r.db.Save(entitlement).Error
+}
+
+func (r *EntitlementRepository) FindByOrderID(orderID uuid.UUID) (*domain.Entitlement, error) {
+	var entitlement domain.Entitlement
+	err := r.db.Where("order_id = ?", orderID).First(&entitlement).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entitlement, nil
+}
+
+func (r *EntitlementRepository) FindByIDForUpdate(id uuid.UUID) (*domain.Entitlement, error) {
+	var entitlement domain.Entitlement
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&entitlement).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entitlement, nil
+}
+
+func (r *EntitlementRepository) FindByUserID(userID uuid.UUID) ([]*domain.Entitlement, error) {
+	var entitlements []*domain.Entitlement
+	seats := r.db.Model(&domain.EntitlementSeat{}).Select("entitlement_id").Where("user_id = ?", userID)
+	err := r.db.Where("user_id = ? OR id IN (?)", userID, seats).Order("ends_at DESC").Find(&entitlements).Error
+	return entitlements, err
+}
+
+func (r *EntitlementRepository) SaveSeat(seat *domain.EntitlementSeat) error {
+	return r.db.Create(seat).Error
+}
+
+func (r *EntitlementRepository) FindSeats(entitlementID uuid.UUID) ([]*domain.EntitlementSeat, error) {
+	var seats []*domain.EntitlementSeat
+	err := r.db.Where("entitlement_id = ?", entitlementID).Order("created_at ASC").Find(&seats).Error
+	return seats, err
+}
+
+func (r *EntitlementRepository) DeleteSeat(entitlementID, userID uuid.UUID) error {
+	return r.db.Where("entitlement_id = ? AND user_id = ?", entitlementID, userID).Delete(&domain.EntitlementSeat{}).Error
+}
+
+func (r *EntitlementRepository) DeleteSeatsByUserID(userID uuid.UUID) error {
+	return r.db.Where("user_id = ?", userID).Delete(&domain.EntitlementSeat{}).Error
+}
+
+func (r *ProductRepository) Save(product *domain.Product) error {
+	return r.db.Save(product).Error
+}
+
+func (r *ProductRepository) FindByID(id string) (*domain.Product, error) {
+	var product domain.Product
+	err := r.db.Where("id = ?", id).First(&product).Error
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+func (r *ProductRepository) List(activeOnly bool) ([]*domain.Product, error) {
+	query := r.db.Model(&domain.Product{})
+	if activeOnly {
+		query = query.Where("active = ?", true)
+	}
+
+	var products []*domain.Product
+	err := query.Order("price ASC, id ASC").Find(&products).Error
+	return products, err
+}
+
+func (r *ProductRepository) Delete(id string) error {
+	return r.db.Where("id = ?", id).Delete(&domain.Product{}).Error
+}
+
+func (r *SecurityEventRepository) Save(event *domain.SecurityEvent) error {
+	return r.db.Create(event).Error
+}
+
+func (r *SecurityEventRepository) List(filter domain.SecurityEventFilter) ([]*domain.SecurityEvent, int64, error) {
+	query := r.db.Model(&domain.SecurityEvent{})
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Email != "" {
+		query = query.Where("email = ?", filter.Email)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var events []*domain.SecurityEvent
+	err := query.Order("created_at DESC").Offset(filter.Offset).Limit(filter.Limit).Find(&events).Error
+	return events, total, err
+}
+
+func (r *MFAFactorRepository) Save(factor *domain.MFAFactor) error {
+	return r.db.Save(factor).Error
+}
+
+func (r *MFAFactorRepository) FindByUserID(userID uuid.UUID) (*domain.MFAFactor, error) {
+	var factor domain.MFAFactor
+	err := r.db.Where("user_id = ?", userID).First(&factor).Error
+	if err != nil {
+		return nil, err
+	}
+	return &factor, nil
+}
+
+func (r *MFAFactorRepository) Delete(userID uuid.UUID) error {
+	return r.db.Where("user_id = ?", userID).Delete(&domain.MFAFactor{}).Error
+}
+
+func (r *MFAPolicyRepository) Save(policy *domain.MFAPolicy) error {
+	return r.db.Save(policy).Error
+}
+
+func (r *MFAPolicyRepository) FindByRole(role domain.UserRole) (*domain.MFAPolicy, error) {
+	var policy domain.MFAPolicy
+	err := r.db.Where("role = ?", role).First(&policy).Error
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *MFAPolicyRepository) List() ([]*domain.MFAPolicy, error) {
+	var policies []*domain.MFAPolicy
+	err := r.db.Order("role ASC").Find(&policies).Error
+	return policies, err
+}
+
+func (r *APIKeyRepository) Save(key *domain.APIKey) error {
+	return r.db.Save(key).Error
+}
+
+func (r *APIKeyRepository) FindByID(id uuid.UUID) (*domain.APIKey, error) {
+	var key domain.APIKey
+	err := r.db.Where("id = ?", id).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *APIKeyRepository) FindByPrefix(prefix string) (*domain.APIKey, error) {
+	var key domain.APIKey
+	err := r.db.Where("prefix = ?", prefix).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *APIKeyRepository) FindByUserID(userID uuid.UUID) ([]*domain.APIKey, error) {
+	var keys []*domain.APIKey
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&keys).Error
+	return keys, err
+}
+
+// TouchLastUsed only writes the timestamp, so that concurrent requests with
+// the same key cannot overwrite a revocation.
+func (r *APIKeyRepository) TouchLastUsed(id uuid.UUID, usedAt time.Time) error {
+	return r.db.Model(&domain.APIKey{}).Where("id = ?", id).Update("last_used_at", usedAt).Error
+}
+
+func (r *APIKeyRepository) DeleteByUserID(userID uuid.UUID) error {
+	return r.db.Where("user_id = ?", userID).Delete(&domain.APIKey{}).Error
+}
+
+func (r *ExternalIdentityRepository) Save(identity *domain.ExternalIdentity) error {
+	return r.db.Save(identity).Error
+}
+
+func (r *ExternalIdentityRepository) FindBySubject(provider, subject string) (*domain.ExternalIdentity, error) {
+	var identity domain.ExternalIdentity
+	err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *ExternalIdentityRepository) DeleteByUserID(userID uuid.UUID) error {
+	return r.db.Where("user_id = ?", userID).Delete(&domain.ExternalIdentity{}).Error
+}
+
+func (r *IndicatorRepository) filterIndicators(filter domain.IndicatorFilter) *gorm.DB {
+	query := r.db.Model(&domain.Indicator{})
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if len(filter.Types) > 0 {
+		query = query.Where("type IN ?", filter.Types)
+	}
+	if filter.Severity != "" {
+		query = query.Where("severity = ?", filter.Severity)
+	}
+	if filter.Source != "" {
+		query = query.Where("source = ?", filter.Source)
+	}
+	if filter.Tag != "" {
+		query = query.Where("tags @> ?", domain.Tags{filter.Tag})
+	}
+	if !filter.IncludeExpired {
+		query = query.Where("expires_at IS NULL OR expires_at > ?", time.Now())
+	}
+	return query
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+func (r *FeedRepository) Save(feed *domain.Feed) error {
+	return r.db.Save(feed).Error
+}
+
+func (r *FeedRepository) FindByID(id uuid.UUID) (*domain.Feed, error) {
+	var feed domain.Feed
+	err := r.db.Where("id = ?", id).First(&feed).Error
+	if err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+func (r *FeedRepository) FindByName(name string) (*domain.Feed, error) {
+	var feed domain.Feed
+	err := r.db.Where("name = ?", name).First(&feed).Error
+	if err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+func (r *FeedRepository) List() ([]*domain.Feed, error) {
+	var feeds []*domain.Feed
+	err := r.db.Order("name").Find(&feeds).Error
+	return feeds, err
+}
+
+// ListDue returns the feeds that are not paused and due at now, the most
+// overdue first.
+func (r *FeedRepository) ListDue(now time.Time) ([]*domain.Feed, error) {
+	var feeds []*domain.Feed
+	err := r.db.Where("paused = ? AND next_run_at <= ?", false, now).Order("next_run_at").Find(&feeds).Error
+	return feeds, err
+}
+
+func (r *FeedRunRepository) Save(run *domain.FeedRun) error {
+	return r.db.Save(run).Error
+}
+
+// ListByFeedID returns the most recent runs of a feed, newest first.
+func (r *FeedRunRepository) ListByFeedID(feedID uuid.UUID, limit int) ([]*domain.FeedRun, error) {
+	var runs []*domain.FeedRun
+	err := r.db.Where("feed_id = ?", feedID).Order("started_at DESC").Limit(limit).Find(&runs).Error
+	return runs, err
+}
+
+func (r *SightingRepository) Save(sighting *domain.Sighting) error {
+	return r.db.Save(sighting).Error
+}
+
+func (r *SightingRepository) FindByIndicatorAndSource(indicatorID uuid.UUID, source string) (*domain.Sighting, error) {
+	var sighting domain.Sighting
+	err := r.db.Where("indicator_id = ? AND source = ?", indicatorID, source).First(&sighting).Error
+	if err != nil {
+		return nil, err
+	}
+	return &sighting, nil
+}
+
+// ListByIndicatorID returns the sightings of an indicator, the most recently
+// seen first.
+func (r *SightingRepository) ListByIndicatorID(indicatorID uuid.UUID) ([]*domain.Sighting, error) {
+	var sightings []*domain.Sighting
+	err := r.db.Where("indicator_id = ?", indicatorID).Order("last_seen DESC").Find(&sightings).Error
+	return sightings, err
+}
+
+func (r *SightingRepository) ListByIndicatorIDs(indicatorIDs []uuid.UUID) ([]*domain.Sighting, error) {
+	var sightings []*domain.Sighting
+	err := r.db.Where("indicator_id IN ?", indicatorIDs).Find(&sightings).Error
+	return sightings, err
+}
+
+func (r *IndicatorVoteRepository) Save(vote *domain.IndicatorVote) error {
+	return r.db.Save(vote).Error
+}
+
+func (r *IndicatorVoteRepository) FindByIndicatorAndUser(indicatorID, userID uuid.UUID) (*domain.IndicatorVote, error) {
+	var vote domain.IndicatorVote
+	err := r.db.Where("indicator_id = ? AND user_id = ?", indicatorID, userID).First(&vote).Error
+	if err != nil {
+		return nil, err
+	}
+	return &vote, nil
+}
+
+func (r *IndicatorVoteRepository) Delete(indicatorID, userID uuid.UUID) error {
+	return r.db.Where("indicator_id = ? AND user_id = ?", indicatorID, userID).Delete(&domain.IndicatorVote{}).Error
+}
+
+// TallyByIndicatorIDs counts the up and down votes of the indicators;
+// indicators without votes are left out.
+func (r *IndicatorVoteRepository) TallyByIndicatorIDs(indicatorIDs []uuid.UUID) (map[uuid.UUID]domain.VoteTally, error) {
+	var rows []struct {
+		IndicatorID uuid.UUID
+		Up          int
+		Down        int
+	}
+	err := r.db.Model(&domain.IndicatorVote{}).
+		Select("indicator_id, COUNT(*) FILTER (WHERE value > 0) AS up, COUNT(*) FILTER (WHERE value < 0) AS down").
+		Where("indicator_id IN ?", indicatorIDs).
+		Group("indicator_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	tallies := make(map[uuid.UUID]domain.VoteTally, len(rows))
+	for _, row := range rows {
+		tallies[row.IndicatorID] = domain.VoteTally{Up: row.Up, Down: row.Down}
+	}
+	return tallies, nil
+}
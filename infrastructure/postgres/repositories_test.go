@@ -1,9 +1,18 @@
 package postgres
 
 import (
+	"errors"
 	"testing"
+	"threat-intel-backend/domain"
+	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 func TestNewUserRepository(t *testing.T) {
@@ -33,3 +42,550 @@ func TestOrderRepositoryStructure(t *testing.T) {
 	// Test repository has the expected structure
 	assert.IsType(t, &OrderRepository{}, repo)
 }
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open gorm: %v", err)
+	}
+	return db, mock
+}
+
+func TestUserRepository_List(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewUserRepository(db)
+	active := true
+
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "users" WHERE role = \$1 AND is_active = \$2 AND email LIKE \$3`).
+		WithArgs(domain.RoleAnalyst, true, `ana\_%`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`SELECT \* FROM "users" WHERE role = \$1 AND is_active = \$2 AND email LIKE \$3 ORDER BY created_at DESC LIMIT 10 OFFSET 20`).
+		WithArgs(domain.RoleAnalyst, true, `ana\_%`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "role", "is_active"}).
+			AddRow(uuid.New(), "ana_1@example.com", domain.RoleAnalyst, true))
+
+	users, total, err := repo.List(domain.UserFilter{
+		Role:        domain.RoleAnalyst,
+		IsActive:    &active,
+		EmailPrefix: "ana_",
+		Offset:      20,
+		Limit:       10,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Len(t, users, 1)
+	assert.Equal(t, "ana_1@example.com", users[0].Email)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_Delete(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewUserRepository(db)
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM "users" WHERE id = \$1`).
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.Delete(id))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_DeleteWithOrders(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewUserRepository(db)
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM "users" WHERE id = \$1`).
+		WithArgs(id).
+		WillReturnError(&pgconn.PgError{Code: "23503", ConstraintName: "fk_orders_user"})
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, repo.Delete(id), domain.ErrUserHasOrders)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderRepository_FindByIDForUpdate(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewOrderRepository(db)
+	id := uuid.New()
+	userID := uuid.New()
+
+	mock.ExpectQuery(`SELECT \* FROM "orders" WHERE id = \$1 ORDER BY "orders"."id" LIMIT 1 FOR UPDATE`).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "status"}).
+			AddRow(id, userID, domain.OrderStatusConfirmed))
+	mock.ExpectQuery(`SELECT \* FROM "users" WHERE "users"."id" = \$1`).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(userID))
+
+	order, err := repo.FindByIDForUpdate(id)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.OrderStatusConfirmed, order.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEscapeLike(t *testing.T) {
+	assert.Equal(t, `50\%\_off\\`, escapeLike(`50%_off\`))
+}
+
+func TestIndicatorRepository_List(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewIndicatorRepository(db)
+
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "indicators" WHERE type = \$1 AND tags @> \$2 AND \(expires_at IS NULL OR expires_at > \$3\)`).
+		WithArgs(domain.IndicatorDomain, `["phishing"]`, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`SELECT \* FROM "indicators" WHERE type = \$1 AND tags @> \$2 AND \(expires_at IS NULL OR expires_at > \$3\) ORDER BY last_seen DESC LIMIT 20`).
+		WithArgs(domain.IndicatorDomain, `["phishing"]`, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type", "value", "tags"}).
+			AddRow(uuid.New(), domain.IndicatorDomain, "evil.example.com", `["phishing"]`))
+
+	indicators, total, err := repo.List(domain.IndicatorFilter{
+		Type:  domain.IndicatorDomain,
+		Tag:   "phishing",
+		Limit: 20,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Len(t, indicators, 1)
+	assert.Equal(t, domain.Tags{"phishing"}, indicators[0].Tags)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIndicatorRepository_ListChanges(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewIndicatorRepository(db)
+	cursor := domain.IndicatorCursor{UpdatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), ID: uuid.New()}
+
+	mock.ExpectQuery(`SELECT \* FROM "indicators" WHERE type IN \(\$1,\$2\) AND \(updated_at > \$3 OR \(updated_at = \$4 AND id > \$5\)\) ORDER BY updated_at ASC, id ASC LIMIT 50`).
+		WithArgs(domain.IndicatorIPv4, domain.IndicatorDomain, cursor.UpdatedAt, cursor.UpdatedAt, cursor.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type", "value"}).
+			AddRow(uuid.New(), domain.IndicatorIPv4, "10.0.0.1"))
+
+	indicators, err := repo.ListChanges(domain.IndicatorFilter{
+		Types:          []domain.IndicatorType{domain.IndicatorIPv4, domain.IndicatorDomain},
+		IncludeExpired: true,
+		Limit:          50,
+	}, cursor)
+
+	assert.NoError(t, err)
+	assert.Len(t, indicators, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIndicatorRepository_FindByValue(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewIndicatorRepository(db)
+
+	mock.ExpectQuery(`SELECT \* FROM "indicators" WHERE type = \$1 AND value = \$2 ORDER BY "indicators"."id" LIMIT 1`).
+		WithArgs(domain.IndicatorIPv4, "10.0.0.1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type", "value"}).
+			AddRow(uuid.New(), domain.IndicatorIPv4, "10.0.0.1"))
+
+	indicator, err := repo.FindByValue(domain.IndicatorIPv4, "10.0.0.1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", indicator.Value)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIndicatorRepository_FindByKeys(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewIndicatorRepository(db)
+	keys := []domain.IndicatorKey{
+		{Type: domain.IndicatorIPv4, Value: "10.0.0.1"},
+		{Type: domain.IndicatorDomain, Value: "evil.example.com"},
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "indicators" WHERE \(type, value\) IN \(\(\$1,\$2\),\(\$3,\$4\)\)`).
+		WithArgs(domain.IndicatorIPv4, "10.0.0.1", domain.IndicatorDomain, "evil.example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type", "value"}).
+			AddRow(uuid.New(), domain.IndicatorDomain, "evil.example.com"))
+
+	indicators, err := repo.FindByKeys(keys)
+
+	assert.NoError(t, err)
+	assert.Len(t, indicators, 1)
+	assert.Equal(t, "evil.example.com", indicators[0].Value)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRelationshipRepository_FindBySourceRefs(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewRelationshipRepository(db)
+	refs := []string{"indicator--a", "indicator--b"}
+
+	mock.ExpectQuery(`SELECT \* FROM "relationships" WHERE source_ref IN \(\$1,\$2\)`).
+		WithArgs("indicator--a", "indicator--b").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "stix_id", "relationship_type", "source_ref", "target_ref"}).
+			AddRow(uuid.New(), "relationship--1", "indicates", "indicator--a", "malware--1"))
+
+	relationships, err := repo.FindBySourceRefs(refs)
+
+	assert.NoError(t, err)
+	assert.Len(t, relationships, 1)
+	assert.Equal(t, "malware--1", relationships[0].TargetRef)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	relationships, err = repo.FindBySourceRefs(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, relationships)
+}
+
+func TestEntitlementRepository_FindByUserID(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewEntitlementRepository(db)
+	userID := uuid.New()
+
+	mock.ExpectQuery(`SELECT \* FROM "entitlements" WHERE user_id = \$1 OR id IN \(SELECT "entitlement_id" FROM "entitlement_seats" WHERE user_id = \$2\) ORDER BY ends_at DESC`).
+		WithArgs(userID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "tier"}).
+			AddRow(uuid.New(), userID, domain.TierPremium))
+
+	entitlements, err := repo.FindByUserID(userID)
+
+	assert.NoError(t, err)
+	assert.Len(t, entitlements, 1)
+	assert.Equal(t, domain.TierPremium, entitlements[0].Tier)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEntitlementRepository_FindByIDForUpdate(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewEntitlementRepository(db)
+	id := uuid.New()
+
+	mock.ExpectQuery(`SELECT \* FROM "entitlements" WHERE id = \$1 ORDER BY "entitlements"."id" LIMIT 1 FOR UPDATE`).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "quantity"}).AddRow(id, 5))
+
+	entitlement, err := repo.FindByIDForUpdate(id)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, entitlement.Quantity)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProductRepository_List(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewProductRepository(db)
+
+	mock.ExpectQuery(`SELECT \* FROM "products" WHERE active = \$1 ORDER BY price ASC, id ASC`).
+		WithArgs(true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "currency", "tier", "active"}).
+			AddRow("intel-basic", "Intel Basic", 4900, "USD", domain.TierBasic, true))
+
+	products, err := repo.List(true)
+
+	assert.NoError(t, err)
+	assert.Len(t, products, 1)
+	assert.Equal(t, int64(4900), products[0].Price)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSecurityEventRepository_List(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewSecurityEventRepository(db)
+
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "security_events" WHERE type = \$1 AND email = \$2`).
+		WithArgs(domain.SecurityEventAccountLocked, "a@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`SELECT \* FROM "security_events" WHERE type = \$1 AND email = \$2 ORDER BY created_at DESC LIMIT 20`).
+		WithArgs(domain.SecurityEventAccountLocked, "a@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type", "email", "ip_address"}).
+			AddRow(uuid.New(), domain.SecurityEventAccountLocked, "a@example.com", "192.0.2.1"))
+
+	events, total, err := repo.List(domain.SecurityEventFilter{
+		Type:  domain.SecurityEventAccountLocked,
+		Email: "a@example.com",
+		Limit: 20,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Len(t, events, 1)
+	assert.Equal(t, "192.0.2.1", events[0].IPAddress)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMFAFactorRepository_FindByUserID(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewMFAFactorRepository(db)
+	userID := uuid.New()
+
+	mock.ExpectQuery(`SELECT \* FROM "mfa_factors" WHERE user_id = \$1 ORDER BY "mfa_factors"."user_id" LIMIT 1`).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "secret", "recovery_codes", "last_used_step"}).
+			AddRow(userID, "JBSWY3DPEHPK3PXP", `["hash"]`, 42))
+
+	factor, err := repo.FindByUserID(userID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", factor.Secret)
+	assert.Equal(t, domain.StringList{"hash"}, factor.RecoveryCodes)
+	assert.Equal(t, int64(42), factor.LastUsedStep)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMFAPolicyRepository_List(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewMFAPolicyRepository(db)
+
+	mock.ExpectQuery(`SELECT \* FROM "mfa_policies" ORDER BY role ASC`).
+		WillReturnRows(sqlmock.NewRows([]string{"role", "required"}).
+			AddRow(domain.RoleAdmin, true))
+
+	policies, err := repo.List()
+
+	assert.NoError(t, err)
+	assert.Len(t, policies, 1)
+	assert.True(t, policies[0].Required)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAPIKeyRepository_FindByPrefix(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewAPIKeyRepository(db)
+	id := uuid.New()
+
+	mock.ExpectQuery(`SELECT \* FROM "api_keys" WHERE prefix = \$1 ORDER BY "api_keys"."id" LIMIT 1`).
+		WithArgs("a1b2c3d4e5f6").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "prefix", "scopes"}).
+			AddRow(id, "siem", "a1b2c3d4e5f6", `["intel:read"]`))
+
+	key, err := repo.FindByPrefix("a1b2c3d4e5f6")
+
+	assert.NoError(t, err)
+	assert.Equal(t, id, key.ID)
+	assert.True(t, key.HasScope(domain.ScopeIntelRead))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAPIKeyRepository_TouchLastUsed(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewAPIKeyRepository(db)
+	id := uuid.New()
+	usedAt := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "api_keys" SET "last_used_at"=\$1 WHERE id = \$2`).
+		WithArgs(usedAt, id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.TouchLastUsed(id, usedAt))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExternalIdentityRepository_FindBySubject(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewExternalIdentityRepository(db)
+	userID := uuid.New()
+
+	mock.ExpectQuery(`SELECT \* FROM "external_identities" WHERE provider = \$1 AND subject = \$2 ORDER BY "external_identities"."provider" LIMIT 1`).
+		WithArgs("https://idp.example.com", "sub-1").
+		WillReturnRows(sqlmock.NewRows([]string{"provider", "subject", "user_id"}).
+			AddRow("https://idp.example.com", "sub-1", userID))
+
+	identity, err := repo.FindBySubject("https://idp.example.com", "sub-1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, userID, identity.UserID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFeedRepository_ListDue(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewFeedRepository(db)
+	id := uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery(`SELECT \* FROM "feeds" WHERE paused = \$1 AND next_run_at <= \$2 ORDER BY next_run_at`).
+		WithArgs(false, now).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "format", "schedule", "options"}).
+			AddRow(id, "blocklist", "csv", "@hourly", `{"column":1,"skip_header":true}`))
+
+	feeds, err := repo.ListDue(now)
+
+	assert.NoError(t, err)
+	assert.Len(t, feeds, 1)
+	assert.Equal(t, domain.FeedOptions{Column: 1, SkipHeader: true}, feeds[0].Options)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFeedRunRepository_ListByFeedID(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewFeedRunRepository(db)
+	feedID := uuid.New()
+
+	mock.ExpectQuery(`SELECT \* FROM "feed_runs" WHERE feed_id = \$1 ORDER BY started_at DESC LIMIT 20`).
+		WithArgs(feedID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "feed_id", "status", "errors"}).
+			AddRow(uuid.New(), feedID, "succeeded", `["line 3: invalid"]`))
+
+	runs, err := repo.ListByFeedID(feedID, 20)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.StringList{"line 3: invalid"}, runs[0].Errors)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIndicatorRepository_Delete(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewIndicatorRepository(db)
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM "sightings" WHERE indicator_id = \$1`).
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`DELETE FROM "indicator_votes" WHERE indicator_id = \$1`).
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM "indicators" WHERE id = \$1`).
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.Delete(id))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSightingRepository_FindByIndicatorAndSource(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewSightingRepository(db)
+	indicatorID := uuid.New()
+
+	mock.ExpectQuery(`SELECT \* FROM "sightings" WHERE indicator_id = \$1 AND source = \$2 ORDER BY "sightings"."id" LIMIT 1`).
+		WithArgs(indicatorID, "urlhaus").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "indicator_id", "source", "count", "raw_payload"}).
+			AddRow(uuid.New(), indicatorID, "urlhaus", 3, "http://evil.example.com/"))
+
+	sighting, err := repo.FindByIndicatorAndSource(indicatorID, "urlhaus")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, sighting.Count)
+	assert.Equal(t, "http://evil.example.com/", sighting.RawPayload)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSightingRepository_ListByIndicatorID(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewSightingRepository(db)
+	indicatorID := uuid.New()
+
+	mock.ExpectQuery(`SELECT \* FROM "sightings" WHERE indicator_id = \$1 ORDER BY last_seen DESC`).
+		WithArgs(indicatorID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "indicator_id", "source"}).
+			AddRow(uuid.New(), indicatorID, "urlhaus").
+			AddRow(uuid.New(), indicatorID, "openphish"))
+
+	sightings, err := repo.ListByIndicatorID(indicatorID)
+
+	assert.NoError(t, err)
+	assert.Len(t, sightings, 2)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIndicatorRepository_ListUnexpired(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewIndicatorRepository(db)
+	now := time.Now()
+	after := uuid.New()
+
+	mock.ExpectQuery(`SELECT \* FROM "indicators" WHERE \(expires_at IS NULL OR expires_at > \$1\) AND id > \$2 ORDER BY id LIMIT 500`).
+		WithArgs(now, after).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type", "value", "score"}).
+			AddRow(uuid.New(), "ipv4", "192.0.2.1", 54))
+
+	indicators, err := repo.ListUnexpired(now, after, 500)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 54, indicators[0].Score)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIndicatorRepository_SaveScore(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewIndicatorRepository(db)
+	indicator, _ := domain.NewIndicator(domain.IndicatorIPv4, "192.0.2.1", 50, "")
+	indicator.ApplyScore(5, 10, time.Now())
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "indicators" SET "expires_at"=\$1,"score"=\$2,"scored_at"=\$3,"updated_at"=\$4 WHERE id = \$5`).
+		WithArgs(indicator.ExpiresAt, 5, indicator.ScoredAt, indicator.UpdatedAt, indicator.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.SaveScore(indicator))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIndicatorVoteRepository_TallyByIndicatorIDs(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewIndicatorVoteRepository(db)
+	voted, unvoted := uuid.New(), uuid.New()
+
+	mock.ExpectQuery(`SELECT indicator_id, COUNT\(\*\) FILTER \(WHERE value > 0\) AS up, COUNT\(\*\) FILTER \(WHERE value < 0\) AS down FROM "indicator_votes" WHERE indicator_id IN \(\$1,\$2\) GROUP BY "indicator_id"`).
+		WithArgs(voted, unvoted).
+		WillReturnRows(sqlmock.NewRows([]string{"indicator_id", "up", "down"}).
+			AddRow(voted, 3, 1))
+
+	tallies, err := repo.TallyByIndicatorIDs([]uuid.UUID{voted, unvoted})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]domain.VoteTally{voted: {Up: 3, Down: 1}}, tallies)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTransactor_Transaction(t *testing.T) {
+	t.Run("commits the work of the repositories", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		transactor := NewTransactor(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT \* FROM "indicators" WHERE type = \$1 AND value = \$2 ORDER BY "indicators"."id" LIMIT 1`).
+			WithArgs(domain.IndicatorIPv4, "10.0.0.1").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "type", "value"}).
+				AddRow(uuid.New(), domain.IndicatorIPv4, "10.0.0.1"))
+		mock.ExpectCommit()
+
+		err := transactor.Transaction(func(repos domain.Repositories) error {
+			_, err := repos.Indicators.FindByValue(domain.IndicatorIPv4, "10.0.0.1")
+			return err
+		})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rolls back on error", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		transactor := NewTransactor(db)
+
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		err := transactor.Transaction(func(repos domain.Repositories) error {
+			return errors.New("import failed")
+		})
+
+		assert.EqualError(t, err, "import failed")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
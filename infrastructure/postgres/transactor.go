@@ -0,0 +1,31 @@
+package postgres
+
+import (
+	"threat-intel-backend/domain"
+	"gorm.io/gorm"
+)
+
+type Transactor struct {
+	db *gorm.DB
+}
+
+func NewTransactor(db *gorm.DB) *Transactor {
+	return &Transactor{db: db}
+}
+
+func (t *Transactor) Transaction(fn func(repos domain.Repositories) error) error {
+	return t.db.Transaction(func(tx *gorm.DB) error {
+		return fn(domain.Repositories{
+			Indicators:         NewIndicatorRepository(tx),
+			Sightings:          NewSightingRepository(tx),
+			ThreatEntities:     NewThreatEntityRepository(tx),
+			Relationships:      NewRelationshipRepository(tx),
+			Orders:             NewOrderRepository(tx),
+			Entitlements:       NewEntitlementRepository(tx),
+			Users:              NewUserRepository(tx),
+			MFAFactors:         NewMFAFactorRepository(tx),
+			APIKeys:            NewAPIKeyRepository(tx),
+			ExternalIdentities: NewExternalIdentityRepository(tx),
+		})
+	})
+}
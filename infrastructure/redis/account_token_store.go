@@ -0,0 +1,43 @@
+package redis
+
+import (
+	"context"
+	"time"
+	"github.com/google/uuid"
+)
+
+const accountTokenPrefix = "account:"
+
+// consumeScript deletes the outstanding token of a user only if it is the
+// presented one, so that an older token cannot void a newer one.
+const consumeScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	redis.call('DEL', KEYS[1])
+	return 1
+end
+return 0
+`
+
+type AccountTokenStore struct {
+	client *Client
+}
+
+func NewAccountTokenStore(client *Client) *AccountTokenStore {
+	return &AccountTokenStore{client: client}
+}
+
+func (s *AccountTokenStore) Save(purpose string, userID uuid.UUID, tokenID string, ttl time.Duration) error {
+	return s.client.Set(context.Background(), accountTokenKey(purpose, userID), tokenID, ttl)
+}
+
+func (s *AccountTokenStore) Consume(purpose string, userID uuid.UUID, tokenID string) (bool, error) {
+	result, err := s.client.Eval(context.Background(), consumeScript, []string{accountTokenKey(purpose, userID)}, tokenID)
+	if err != nil {
+		return false, err
+	}
+	return result == int64(1), nil
+}
+
+func accountTokenKey(purpose string, userID uuid.UUID) string {
+	return accountTokenPrefix + purpose + ":" + userID.String()
+}
@@ -0,0 +1,53 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountTokenStore(t *testing.T) {
+	client, mr := setupTestClient(t)
+	store := NewAccountTokenStore(client)
+	userID := uuid.New()
+
+	t.Run("consumes the outstanding token once", func(t *testing.T) {
+		assert.NoError(t, store.Save("password_reset", userID, "token-1", time.Hour))
+
+		ok, err := store.Consume("password_reset", userID, "token-1")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = store.Consume("password_reset", userID, "token-1")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("a new token replaces the previous one", func(t *testing.T) {
+		assert.NoError(t, store.Save("password_reset", userID, "token-1", time.Hour))
+		assert.NoError(t, store.Save("password_reset", userID, "token-2", time.Hour))
+
+		ok, _ := store.Consume("password_reset", userID, "token-1")
+		assert.False(t, ok)
+
+		ok, _ = store.Consume("password_reset", userID, "token-2")
+		assert.True(t, ok)
+	})
+
+	t.Run("purposes are kept apart", func(t *testing.T) {
+		assert.NoError(t, store.Save("email_verification", userID, "token-3", time.Hour))
+
+		ok, _ := store.Consume("password_reset", userID, "token-3")
+		assert.False(t, ok)
+	})
+
+	t.Run("tokens expire", func(t *testing.T) {
+		assert.NoError(t, store.Save("password_reset", userID, "token-4", time.Minute))
+		mr.FastForward(2 * time.Minute)
+
+		ok, _ := store.Consume("password_reset", userID, "token-4")
+		assert.False(t, ok)
+	})
+}
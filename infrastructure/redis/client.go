@@ -24,10 +24,30 @@ func (c *Client) Set(ctx context.Context, key string, value interface{}, expirat
 	return c.rdb.Set(ctx, key, value, expiration).Err()
 }
 
+// SetMany sets every key of values with the same expiration in one round
+// trip.
+func (c *Client) SetMany(ctx context.Context, values map[string]interface{}, expiration time.Duration) error {
+	pipe := c.rdb.Pipeline()
+	for key, value := range values {
+		pipe.Set(ctx, key, value, expiration)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (c *Client) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	return c.rdb.SetNX(ctx, key, value, expiration).Result()
+}
+
 func (c *Client) Get(ctx context.Context, key string) (string, error) {
 	return c.rdb.Get(ctx, key).Result()
 }
 
+// MGet returns the values of keys in order, nil for the missing ones.
+func (c *Client) MGet(ctx context.Context, keys ...string) ([]interface{}, error) {
+	return c.rdb.MGet(ctx, keys...).Result()
+}
+
 func (c *Client) Del(ctx context.Context, keys ...string) error {
 	return c.rdb.Del(ctx, keys...).Err()
 }
@@ -42,4 +62,25 @@ func (c *Client) Ping(ctx context.Context) error {
 
 func (c *Client) Close() error {
 	return c.rdb.Close()
+}
+
+func (c *Client) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	return c.rdb.Eval(ctx, script, keys, args...).Result()
+}
+
+func (c *Client) SAdd(ctx context.Context, key string, members ...interface{}) error {
+	return c.rdb.SAdd(ctx, key, members...).Err()
+}
+
+func (c *Client) SMembers(ctx context.Context, key string) ([]string, error) {
+	return c.rdb.SMembers(ctx, key).Result()
+}
+
+func (c *Client) Expire(ctx context.Context, key string, expiration time.Duration) error {
+	return c.rdb.Expire(ctx, key, expiration).Err()
+}
+
+// IsNil reports whether err is the error returned for a missing key.
+func IsNil(err error) bool {
+	return err == redis.Nil
 }
\ No newline at end of file
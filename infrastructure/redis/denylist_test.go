@@ -0,0 +1,82 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessTokenDenylist_RevokeToken(t *testing.T) {
+	client, mr := setupTestClient(t)
+	denylist := NewAccessTokenDenylist(client)
+	userID := uuid.New()
+
+	t.Run("revoked token is reported", func(t *testing.T) {
+		assert.NoError(t, denylist.RevokeToken("jti-1", time.Now().Add(time.Minute)))
+
+		revoked, err := denylist.IsRevoked("jti-1", userID, time.Now())
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+
+		revoked, err = denylist.IsRevoked("jti-2", userID, time.Now())
+		assert.NoError(t, err)
+		assert.False(t, revoked)
+	})
+
+	t.Run("entry expires with the token", func(t *testing.T) {
+		assert.NoError(t, denylist.RevokeToken("jti-3", time.Now().Add(time.Minute)))
+		mr.FastForward(2 * time.Minute)
+
+		revoked, err := denylist.IsRevoked("jti-3", userID, time.Now())
+		assert.NoError(t, err)
+		assert.False(t, revoked)
+	})
+
+	t.Run("already expired token is ignored", func(t *testing.T) {
+		assert.NoError(t, denylist.RevokeToken("jti-4", time.Now().Add(-time.Minute)))
+	})
+}
+
+func TestAccessTokenDenylist_RevokeUserTokens(t *testing.T) {
+	client, _ := setupTestClient(t)
+	denylist := NewAccessTokenDenylist(client)
+	userID := uuid.New()
+	cutoff := time.Now()
+
+	assert.NoError(t, denylist.RevokeUserTokens(userID, cutoff, time.Hour))
+
+	revoked, err := denylist.IsRevoked("old", userID, cutoff.Add(-time.Minute))
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	revoked, err = denylist.IsRevoked("new", userID, cutoff.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+
+	revoked, err = denylist.IsRevoked("other", uuid.New(), cutoff.Add(-time.Minute))
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func TestAccessTokenDenylist_RevokeUserTokensSecondBoundary(t *testing.T) {
+	client, _ := setupTestClient(t)
+	denylist := NewAccessTokenDenylist(client)
+	userID := uuid.New()
+	cutoff := time.Date(2026, 3, 1, 12, 0, 0, 500*int(time.Millisecond), time.UTC)
+
+	assert.NoError(t, denylist.RevokeUserTokens(userID, cutoff, time.Hour))
+
+	t.Run("token issued in the cut-off second is revoked", func(t *testing.T) {
+		revoked, err := denylist.IsRevoked("", userID, cutoff.Truncate(time.Second))
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("token issued in the second after the cut-off is not", func(t *testing.T) {
+		revoked, err := denylist.IsRevoked("", userID, cutoff.Truncate(time.Second).Add(time.Second))
+		assert.NoError(t, err)
+		assert.False(t, revoked)
+	})
+}
@@ -0,0 +1,59 @@
+package redis
+
+import (
+	"context"
+	"time"
+	"github.com/google/uuid"
+)
+
+const lockPrefix = "lock:"
+
+// refreshScript extends the lock only while it is still held by the token.
+const refreshScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`
+
+// releaseScript deletes the lock only while it is still held by the token,
+// so that a holder whose lease lapsed cannot release the next holder's.
+const releaseScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`
+
+// Lock is a lease on a key that at most one holder has at a time, so that a
+// job runs on a single replica. The lease lapses after its TTL unless it is
+// refreshed, so that a crashed holder does not keep it.
+type Lock struct {
+	client *Client
+	key    string
+	token  string
+	ttl    time.Duration
+}
+
+func NewLock(client *Client, name string, ttl time.Duration) *Lock {
+	return &Lock{client: client, key: lockPrefix + name, token: uuid.NewString(), ttl: ttl}
+}
+
+func (l *Lock) Acquire(ctx context.Context) (bool, error) {
+	return l.client.SetNX(ctx, l.key, l.token, l.ttl)
+}
+
+// Refresh extends the lease, and reports false when the lock was lost.
+func (l *Lock) Refresh(ctx context.Context) (bool, error) {
+	result, err := l.client.Eval(ctx, refreshScript, []string{l.key}, l.token, l.ttl.Milliseconds())
+	if err != nil {
+		return false, err
+	}
+	return result == int64(1), nil
+}
+
+// Release gives the lock up if it is still held.
+func (l *Lock) Release(ctx context.Context) error {
+	_, err := l.client.Eval(ctx, releaseScript, []string{l.key}, l.token)
+	return err
+}
@@ -0,0 +1,54 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLock(t *testing.T) {
+	client, mr := setupTestClient(t)
+	ctx := context.Background()
+	first := NewLock(client, "feeds", time.Minute)
+	second := NewLock(client, "feeds", time.Minute)
+
+	ok, err := first.Acquire(ctx)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = second.Acquire(ctx)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	t.Run("refresh extends the holder's lease", func(t *testing.T) {
+		mr.FastForward(30 * time.Second)
+
+		ok, err := first.Refresh(ctx)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, time.Minute, mr.TTL("lock:feeds"))
+
+		ok, _ = second.Refresh(ctx)
+		assert.False(t, ok)
+	})
+
+	t.Run("only the holder releases", func(t *testing.T) {
+		assert.NoError(t, second.Release(ctx))
+		assert.True(t, mr.Exists("lock:feeds"))
+
+		assert.NoError(t, first.Release(ctx))
+		ok, _ := second.Acquire(ctx)
+		assert.True(t, ok)
+	})
+
+	t.Run("an expired lease is lost", func(t *testing.T) {
+		mr.FastForward(2 * time.Minute)
+
+		ok, _ := second.Refresh(ctx)
+		assert.False(t, ok)
+		ok, _ = first.Acquire(ctx)
+		assert.True(t, ok)
+	})
+}
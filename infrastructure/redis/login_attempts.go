@@ -0,0 +1,65 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+const (
+	loginFailuresPrefix = "login:failures:"
+	loginLockPrefix     = "login:lock:"
+)
+
+type LoginAttemptStore struct {
+	client *Client
+}
+
+func NewLoginAttemptStore(client *Client) *LoginAttemptStore {
+	return &LoginAttemptStore{client: client}
+}
+
+// RecordFailure increments the failure counter of the key and restarts its
+// window, so the count only drops back to zero after a quiet period.
+func (s *LoginAttemptStore) RecordFailure(key string, window time.Duration) (int, error) {
+	ctx := context.Background()
+	failuresKey := loginFailuresPrefix + key
+
+	pipe := s.client.rdb.TxPipeline()
+	count := pipe.Incr(ctx, failuresKey)
+	pipe.Expire(ctx, failuresKey, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return int(count.Val()), nil
+}
+
+func (s *LoginAttemptStore) Lock(key string, until time.Time) error {
+	ttl := time.Until(until)
+	if ttl <= 0 {
+		return nil
+	}
+	return s.client.Set(context.Background(), loginLockPrefix+key, until.UnixMilli(), ttl)
+}
+
+// LockedUntil returns the end of the current lock of the key, or the zero
+// time when it is not locked.
+func (s *LoginAttemptStore) LockedUntil(key string) (time.Time, error) {
+	value, err := s.client.Get(context.Background(), loginLockPrefix+key)
+	if IsNil(err) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	millis, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(millis), nil
+}
+
+func (s *LoginAttemptStore) Reset(key string) error {
+	return s.client.Del(context.Background(), loginFailuresPrefix+key, loginLockPrefix+key)
+}
@@ -0,0 +1,62 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginAttemptStore_RecordFailure(t *testing.T) {
+	client, mr := setupTestClient(t)
+	store := NewLoginAttemptStore(client)
+
+	for want := 1; want <= 3; want++ {
+		count, err := store.RecordFailure("account:a@example.com", 15*time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, want, count)
+	}
+
+	count, err := store.RecordFailure("ip:192.0.2.1", 15*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	mr.FastForward(16 * time.Minute)
+
+	count, err = store.RecordFailure("account:a@example.com", 15*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestLoginAttemptStore_Lock(t *testing.T) {
+	client, mr := setupTestClient(t)
+	store := NewLoginAttemptStore(client)
+	until := time.Now().Add(time.Minute).Truncate(time.Millisecond)
+
+	t.Run("lock is reported until it expires", func(t *testing.T) {
+		assert.NoError(t, store.Lock("account:a@example.com", until))
+
+		lockedUntil, err := store.LockedUntil("account:a@example.com")
+		assert.NoError(t, err)
+		assert.True(t, until.Equal(lockedUntil))
+
+		mr.FastForward(2 * time.Minute)
+
+		lockedUntil, err = store.LockedUntil("account:a@example.com")
+		assert.NoError(t, err)
+		assert.True(t, lockedUntil.IsZero())
+	})
+
+	t.Run("reset clears failures and lock", func(t *testing.T) {
+		_, _ = store.RecordFailure("account:b@example.com", time.Hour)
+		assert.NoError(t, store.Lock("account:b@example.com", time.Now().Add(time.Hour)))
+
+		assert.NoError(t, store.Reset("account:b@example.com"))
+
+		lockedUntil, err := store.LockedUntil("account:b@example.com")
+		assert.NoError(t, err)
+		assert.True(t, lockedUntil.IsZero())
+		count, _ := store.RecordFailure("account:b@example.com", time.Hour)
+		assert.Equal(t, 1, count)
+	})
+}
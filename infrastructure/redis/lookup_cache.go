@@ -0,0 +1,83 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+	"threat-intel-backend/domain"
+)
+
+const lookupPrefix = "lookup:"
+
+// LookupCache keeps lookup results as JSON under the type and value of the
+// observable, with null for unknown observables, until the TTL runs out or
+// the indicator is written.
+type LookupCache struct {
+	client *Client
+	ttl    time.Duration
+}
+
+func NewLookupCache(client *Client, ttl time.Duration) *LookupCache {
+	return &LookupCache{client: client, ttl: ttl}
+}
+
+func lookupKey(key domain.IndicatorKey) string {
+	return lookupPrefix + string(key.Type) + ":" + key.Value
+}
+
+func (c *LookupCache) Get(ctx context.Context, keys []domain.IndicatorKey) (map[domain.IndicatorKey]*domain.LookupMatch, error) {
+	cached := make(map[domain.IndicatorKey]*domain.LookupMatch, len(keys))
+	if len(keys) == 0 || c.ttl <= 0 {
+		return cached, nil
+	}
+
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = lookupKey(key)
+	}
+	values, err := c.client.MGet(ctx, names...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, value := range values {
+		raw, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var match *domain.LookupMatch
+		if err := json.Unmarshal([]byte(raw), &match); err != nil {
+			continue
+		}
+		cached[keys[i]] = match
+	}
+	return cached, nil
+}
+
+func (c *LookupCache) Set(ctx context.Context, results map[domain.IndicatorKey]*domain.LookupMatch) error {
+	if len(results) == 0 || c.ttl <= 0 {
+		return nil
+	}
+
+	values := make(map[string]interface{}, len(results))
+	for key, match := range results {
+		data, err := json.Marshal(match)
+		if err != nil {
+			return err
+		}
+		values[lookupKey(key)] = data
+	}
+	return c.client.SetMany(ctx, values, c.ttl)
+}
+
+func (c *LookupCache) Delete(ctx context.Context, keys []domain.IndicatorKey) error {
+	if len(keys) == 0 || c.ttl <= 0 {
+		return nil
+	}
+
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = lookupKey(key)
+	}
+	return c.client.Del(ctx, names...)
+}
@@ -0,0 +1,73 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"threat-intel-backend/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookupCache(t *testing.T) {
+	client, mr := setupTestClient(t)
+	cache := NewLookupCache(client, 5*time.Minute)
+	ctx := context.Background()
+
+	known := domain.IndicatorKey{Type: domain.IndicatorDomain, Value: "evil.example.com"}
+	unknown := domain.IndicatorKey{Type: domain.IndicatorIPv4, Value: "198.51.100.7"}
+	uncached := domain.IndicatorKey{Type: domain.IndicatorIPv4, Value: "192.0.2.1"}
+	match := &domain.LookupMatch{
+		IndicatorID: uuid.New(),
+		Type:        known.Type,
+		Value:       known.Value,
+		Score:       62,
+		Tags:        domain.Tags{"phishing"},
+		Sources:     []string{"openphish"},
+	}
+
+	t.Run("returns matches and misses it holds", func(t *testing.T) {
+		assert.NoError(t, cache.Set(ctx, map[domain.IndicatorKey]*domain.LookupMatch{known: match, unknown: nil}))
+
+		cached, err := cache.Get(ctx, []domain.IndicatorKey{known, unknown, uncached})
+
+		assert.NoError(t, err)
+		assert.Len(t, cached, 2)
+		assert.Equal(t, match.IndicatorID, cached[known].IndicatorID)
+		assert.Equal(t, []string{"openphish"}, cached[known].Sources)
+		miss, ok := cached[unknown]
+		assert.True(t, ok)
+		assert.Nil(t, miss)
+	})
+
+	t.Run("drops deleted entries", func(t *testing.T) {
+		assert.NoError(t, cache.Set(ctx, map[domain.IndicatorKey]*domain.LookupMatch{known: match, unknown: nil}))
+
+		assert.NoError(t, cache.Delete(ctx, []domain.IndicatorKey{unknown}))
+		cached, err := cache.Get(ctx, []domain.IndicatorKey{known, unknown})
+
+		assert.NoError(t, err)
+		assert.Len(t, cached, 1)
+		assert.Contains(t, cached, known)
+	})
+
+	t.Run("entries expire", func(t *testing.T) {
+		mr.FastForward(6 * time.Minute)
+
+		cached, err := cache.Get(ctx, []domain.IndicatorKey{known, unknown})
+
+		assert.NoError(t, err)
+		assert.Empty(t, cached)
+	})
+
+	t.Run("caching is off without a TTL", func(t *testing.T) {
+		disabled := NewLookupCache(client, 0)
+		assert.NoError(t, disabled.Set(ctx, map[domain.IndicatorKey]*domain.LookupMatch{known: match}))
+
+		cached, err := cache.Get(ctx, []domain.IndicatorKey{known})
+
+		assert.NoError(t, err)
+		assert.Empty(t, cached)
+	})
+}
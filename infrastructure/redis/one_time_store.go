@@ -0,0 +1,24 @@
+package redis
+
+import (
+	"context"
+	"time"
+)
+
+const consumedTokenPrefix = "onetime:"
+
+type OneTimeTokenStore struct {
+	client *Client
+}
+
+func NewOneTimeTokenStore(client *Client) *OneTimeTokenStore {
+	return &OneTimeTokenStore{client: client}
+}
+
+func (s *OneTimeTokenStore) Consume(tokenID string, expiresAt time.Time) (bool, error) {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return false, nil
+	}
+	return s.client.SetNX(context.Background(), consumedTokenPrefix+tokenID, "1", ttl)
+}
@@ -0,0 +1,34 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOneTimeTokenStore_Consume(t *testing.T) {
+	client, _ := setupTestClient(t)
+	store := NewOneTimeTokenStore(client)
+
+	t.Run("first use succeeds", func(t *testing.T) {
+		ok, err := store.Consume("token-1", time.Now().Add(time.Hour))
+
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("second use fails", func(t *testing.T) {
+		ok, err := store.Consume("token-1", time.Now().Add(time.Hour))
+
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("expired token cannot be consumed", func(t *testing.T) {
+		ok, err := store.Consume("token-2", time.Now().Add(-time.Minute))
+
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
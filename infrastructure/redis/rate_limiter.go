@@ -0,0 +1,77 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"threat-intel-backend/domain"
+)
+
+const rateLimitPrefix = "ratelimit:"
+
+// gcraScript implements the generic cell rate algorithm. The key stores the
+// theoretical arrival time (TAT) of the next request in microseconds of the
+// Redis clock, so replicas with skewed clocks still agree. A request is
+// allowed while the TAT stays within one period of now; each allowed request
+// pushes the TAT forward by period/limit.
+const gcraScript = `
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local interval = math.floor(period / limit)
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+local tat = tonumber(redis.call('GET', KEYS[1]))
+if tat == nil or tat < now then
+	tat = now
+end
+
+local next_tat = tat + interval
+local allow_at = next_tat - period
+if allow_at > now then
+	return {0, 0, tat - now, allow_at - now}
+end
+
+redis.call('SET', KEYS[1], string.format('%d', next_tat), 'PX', math.ceil((next_tat - now) / 1000))
+return {1, math.floor((now - allow_at) / interval), next_tat - now, 0}
+`
+
+type RateLimiter struct {
+	client *Client
+}
+
+func NewRateLimiter(client *Client) *RateLimiter {
+	return &RateLimiter{client: client}
+}
+
+func (l *RateLimiter) Allow(key string, limit domain.RateLimit) (*domain.RateLimitResult, error) {
+	if limit.Limit <= 0 || limit.Period <= 0 {
+		return &domain.RateLimitResult{Allowed: true}, nil
+	}
+
+	result, err := l.client.Eval(context.Background(), gcraScript,
+		[]string{rateLimitPrefix + key},
+		limit.Limit, limit.Period.Microseconds())
+	if err != nil {
+		return nil, err
+	}
+
+	values, ok := result.([]interface{})
+	if !ok || len(values) != 4 {
+		return nil, fmt.Errorf("unexpected rate limit result %v", result)
+	}
+	fields := make([]int64, len(values))
+	for i, value := range values {
+		if fields[i], ok = value.(int64); !ok {
+			return nil, fmt.Errorf("unexpected rate limit result %v", result)
+		}
+	}
+
+	return &domain.RateLimitResult{
+		Allowed:    fields[0] == 1,
+		Limit:      limit.Limit,
+		Remaining:  int(fields[1]),
+		ResetAfter: time.Duration(fields[2]) * time.Microsecond,
+		RetryAfter: time.Duration(fields[3]) * time.Microsecond,
+	}, nil
+}
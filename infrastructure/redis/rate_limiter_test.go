@@ -0,0 +1,63 @@
+package redis
+
+import (
+	"testing"
+	"threat-intel-backend/domain"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter_Allow(t *testing.T) {
+	client, mr := setupTestClient(t)
+	limiter := NewRateLimiter(client)
+	limit := domain.RateLimit{Limit: 3, Period: time.Minute}
+	now := time.Now()
+	mr.SetTime(now)
+
+	t.Run("allows the budget then denies", func(t *testing.T) {
+		for remaining := 2; remaining >= 0; remaining-- {
+			result, err := limiter.Allow("user:1", limit)
+			assert.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, 3, result.Limit)
+			assert.Equal(t, remaining, result.Remaining)
+		}
+
+		result, err := limiter.Allow("user:1", limit)
+		assert.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+		assert.Equal(t, 20*time.Second, result.RetryAfter)
+		assert.Equal(t, time.Minute, result.ResetAfter)
+	})
+
+	t.Run("keys are independent", func(t *testing.T) {
+		result, err := limiter.Allow("user:2", limit)
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+	})
+
+	t.Run("budget refills over time", func(t *testing.T) {
+		mr.SetTime(now.Add(20 * time.Second))
+
+		result, err := limiter.Allow("user:1", limit)
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+
+		mr.SetTime(now.Add(2 * time.Minute))
+
+		result, err = limiter.Allow("user:1", limit)
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 2, result.Remaining)
+	})
+
+	t.Run("zero limit is unlimited", func(t *testing.T) {
+		result, err := limiter.Allow("user:3", domain.RateLimit{})
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.False(t, mr.Exists(rateLimitPrefix+"user:3"))
+	})
+}
@@ -0,0 +1,44 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+	"threat-intel-backend/domain"
+)
+
+const ssoStatePrefix = "sso:state:"
+
+type SSOStateStore struct {
+	client *Client
+}
+
+func NewSSOStateStore(client *Client) *SSOStateStore {
+	return &SSOStateStore{client: client}
+}
+
+func (s *SSOStateStore) Save(state string, login *domain.SSOLoginState, ttl time.Duration) error {
+	data, err := json.Marshal(login)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(context.Background(), ssoStatePrefix+state, data, ttl)
+}
+
+// Consume reads and deletes the state atomically, so that two callbacks with
+// the same state cannot both succeed.
+func (s *SSOStateStore) Consume(state string) (*domain.SSOLoginState, error) {
+	data, err := s.client.rdb.GetDel(context.Background(), ssoStatePrefix+state).Result()
+	if IsNil(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var login domain.SSOLoginState
+	if err := json.Unmarshal([]byte(data), &login); err != nil {
+		return nil, err
+	}
+	return &login, nil
+}
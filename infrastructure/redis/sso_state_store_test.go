@@ -0,0 +1,37 @@
+package redis
+
+import (
+	"testing"
+	"threat-intel-backend/domain"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSOStateStore(t *testing.T) {
+	client, server := setupTestClient(t)
+	store := NewSSOStateStore(client)
+	login := &domain.SSOLoginState{Nonce: "nonce", CodeVerifier: "verifier"}
+
+	t.Run("consumes a state once", func(t *testing.T) {
+		assert.NoError(t, store.Save("state-1", login, time.Minute))
+
+		consumed, err := store.Consume("state-1")
+		assert.NoError(t, err)
+		assert.Equal(t, login, consumed)
+
+		consumed, err = store.Consume("state-1")
+		assert.NoError(t, err)
+		assert.Nil(t, consumed)
+	})
+
+	t.Run("states expire", func(t *testing.T) {
+		assert.NoError(t, store.Save("state-2", login, time.Minute))
+		server.FastForward(2 * time.Minute)
+
+		consumed, err := store.Consume("state-2")
+
+		assert.NoError(t, err)
+		assert.Nil(t, consumed)
+	})
+}
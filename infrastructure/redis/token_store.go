@@ -0,0 +1,112 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"threat-intel-backend/domain"
+	"github.com/google/uuid"
+)
+
+const (
+	refreshFamilyPrefix = "refresh:family:"
+	refreshUserPrefix   = "refresh:user:"
+)
+
+// rotateScript atomically swaps the current token of a family. A family that
+// is unknown or revoked is rejected; a token that is not the current one has
+// already been rotated, so the family is revoked. The index of the user's
+// families is kept alive as long as the family, so that revoking every
+// session of the user still finds it.
+const rotateScript = `
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 'missing'
+end
+if redis.call('HGET', KEYS[1], 'revoked') == '1' then
+	return 'revoked'
+end
+if redis.call('HGET', KEYS[1], 'current') ~= ARGV[1] then
+	redis.call('HSET', KEYS[1], 'revoked', '1')
+	return 'reused'
+end
+redis.call('HSET', KEYS[1], 'current', ARGV[2])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+redis.call('SADD', KEYS[2], ARGV[4])
+redis.call('PEXPIRE', KEYS[2], ARGV[3])
+return 'ok'
+`
+
+const revokeScript = `
+for i, key in ipairs(KEYS) do
+	if redis.call('EXISTS', key) == 1 then
+		redis.call('HSET', key, 'revoked', '1')
+	end
+end
+return 'ok'
+`
+
+type RefreshTokenStore struct {
+	client *Client
+}
+
+func NewRefreshTokenStore(client *Client) *RefreshTokenStore {
+	return &RefreshTokenStore{client: client}
+}
+
+func (s *RefreshTokenStore) Create(userID, familyID uuid.UUID, tokenID string, ttl time.Duration) error {
+	ctx := context.Background()
+	familyKey := refreshFamilyPrefix + familyID.String()
+	userKey := refreshUserPrefix + userID.String()
+
+	pipe := s.client.rdb.TxPipeline()
+	pipe.HSet(ctx, familyKey, "user_id", userID.String(), "current", tokenID, "revoked", "0")
+	pipe.Expire(ctx, familyKey, ttl)
+	pipe.SAdd(ctx, userKey, familyID.String())
+	pipe.Expire(ctx, userKey, ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (s *RefreshTokenStore) Rotate(userID, familyID uuid.UUID, currentTokenID, nextTokenID string, ttl time.Duration) error {
+	result, err := s.client.Eval(context.Background(), rotateScript,
+		[]string{refreshFamilyPrefix + familyID.String(), refreshUserPrefix + userID.String()},
+		currentTokenID, nextTokenID, ttl.Milliseconds(), familyID.String())
+	if err != nil {
+		return err
+	}
+
+	switch result {
+	case "ok":
+		return nil
+	case "reused":
+		return domain.ErrRefreshTokenReused
+	case "missing", "revoked":
+		return domain.ErrRefreshTokenRevoked
+	default:
+		return fmt.Errorf("unexpected rotate result: %v", result)
+	}
+}
+
+func (s *RefreshTokenStore) RevokeFamily(familyID uuid.UUID) error {
+	_, err := s.client.Eval(context.Background(), revokeScript,
+		[]string{refreshFamilyPrefix + familyID.String()})
+	return err
+}
+
+func (s *RefreshTokenStore) RevokeUser(userID uuid.UUID) error {
+	ctx := context.Background()
+	families, err := s.client.SMembers(ctx, refreshUserPrefix+userID.String())
+	if err != nil {
+		return err
+	}
+	if len(families) == 0 {
+		return nil
+	}
+
+	keys := make([]string, len(families))
+	for i, familyID := range families {
+		keys[i] = refreshFamilyPrefix + familyID
+	}
+	_, err = s.client.Eval(ctx, revokeScript, keys)
+	return err
+}
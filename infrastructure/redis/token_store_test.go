@@ -0,0 +1,121 @@
+package redis
+
+import (
+	"fmt"
+	"testing"
+	"threat-intel-backend/domain"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupTestClient(t *testing.T) (*Client, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	client := NewClient(mr.Addr(), "", 0)
+	t.Cleanup(func() { _ = client.Close() })
+	return client, mr
+}
+
+func TestRefreshTokenStore_Rotate(t *testing.T) {
+	client, _ := setupTestClient(t)
+	store := NewRefreshTokenStore(client)
+	userID := uuid.New()
+	ttl := time.Hour
+
+	t.Run("rotates current token", func(t *testing.T) {
+		familyID := uuid.New()
+		assert.NoError(t, store.Create(userID, familyID, "jti-1", ttl))
+
+		assert.NoError(t, store.Rotate(userID, familyID, "jti-1", "jti-2", ttl))
+		assert.NoError(t, store.Rotate(userID, familyID, "jti-2", "jti-3", ttl))
+	})
+
+	t.Run("reuse revokes family", func(t *testing.T) {
+		familyID := uuid.New()
+		assert.NoError(t, store.Create(userID, familyID, "jti-1", ttl))
+		assert.NoError(t, store.Rotate(userID, familyID, "jti-1", "jti-2", ttl))
+
+		err := store.Rotate(userID, familyID, "jti-1", "jti-3", ttl)
+		assert.ErrorIs(t, err, domain.ErrRefreshTokenReused)
+
+		err = store.Rotate(userID, familyID, "jti-2", "jti-4", ttl)
+		assert.ErrorIs(t, err, domain.ErrRefreshTokenRevoked)
+	})
+
+	t.Run("unknown family is rejected", func(t *testing.T) {
+		err := store.Rotate(userID, uuid.New(), "jti-1", "jti-2", ttl)
+
+		assert.ErrorIs(t, err, domain.ErrRefreshTokenRevoked)
+	})
+}
+
+func TestRefreshTokenStore_Expiry(t *testing.T) {
+	client, mr := setupTestClient(t)
+	store := NewRefreshTokenStore(client)
+	userID, familyID := uuid.New(), uuid.New()
+
+	assert.NoError(t, store.Create(userID, familyID, "jti-1", time.Minute))
+	mr.FastForward(2 * time.Minute)
+
+	err := store.Rotate(userID, familyID, "jti-1", "jti-2", time.Minute)
+	assert.ErrorIs(t, err, domain.ErrRefreshTokenRevoked)
+}
+
+func TestRefreshTokenStore_Revoke(t *testing.T) {
+	client, _ := setupTestClient(t)
+	store := NewRefreshTokenStore(client)
+	userID := uuid.New()
+	ttl := time.Hour
+
+	t.Run("revoke family", func(t *testing.T) {
+		familyID := uuid.New()
+		assert.NoError(t, store.Create(userID, familyID, "jti-1", ttl))
+
+		assert.NoError(t, store.RevokeFamily(familyID))
+
+		err := store.Rotate(userID, familyID, "jti-1", "jti-2", ttl)
+		assert.ErrorIs(t, err, domain.ErrRefreshTokenRevoked)
+	})
+
+	t.Run("revoke user", func(t *testing.T) {
+		family1, family2 := uuid.New(), uuid.New()
+		other, otherUserID := uuid.New(), uuid.New()
+		assert.NoError(t, store.Create(userID, family1, "a", ttl))
+		assert.NoError(t, store.Create(userID, family2, "b", ttl))
+		assert.NoError(t, store.Create(otherUserID, other, "c", ttl))
+
+		assert.NoError(t, store.RevokeUser(userID))
+
+		assert.ErrorIs(t, store.Rotate(userID, family1, "a", "a2", ttl), domain.ErrRefreshTokenRevoked)
+		assert.ErrorIs(t, store.Rotate(userID, family2, "b", "b2", ttl), domain.ErrRefreshTokenRevoked)
+		assert.NoError(t, store.Rotate(otherUserID, other, "c", "c2", ttl))
+	})
+
+	t.Run("revoke user without sessions", func(t *testing.T) {
+		assert.NoError(t, store.RevokeUser(uuid.New()))
+	})
+}
+
+func TestRefreshTokenStore_RevokeUserAfterRotations(t *testing.T) {
+	client, mr := setupTestClient(t)
+	store := NewRefreshTokenStore(client)
+	userID, familyID := uuid.New(), uuid.New()
+	ttl := time.Hour
+
+	// A session that keeps refreshing outlives the TTL it was created with.
+	assert.NoError(t, store.Create(userID, familyID, "jti-0", ttl))
+	current := "jti-0"
+	for i := 1; i <= 3; i++ {
+		mr.FastForward(45 * time.Minute)
+		next := fmt.Sprintf("jti-%d", i)
+		assert.NoError(t, store.Rotate(userID, familyID, current, next, ttl))
+		current = next
+	}
+
+	assert.NoError(t, store.RevokeUser(userID))
+
+	err := store.Rotate(userID, familyID, current, "jti-next", ttl)
+	assert.ErrorIs(t, err, domain.ErrRefreshTokenRevoked)
+}
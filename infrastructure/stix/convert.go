@@ -0,0 +1,155 @@
+package stix
+
+import (
+	"fmt"
+	"threat-intel-backend/domain"
+)
+
+// defaultConfidence is used for indicators that do not state a confidence,
+// which STIX defines as "unknown".
+const defaultConfidence = 50
+
+// ToIndicator converts a STIX indicator into a domain indicator that keeps the
+// STIX identifier.
+func ToIndicator(object Object) (*domain.Indicator, error) {
+	if object.Type != TypeIndicator {
+		return nil, fmt.Errorf("%w: %s is not an indicator", ErrInvalidBundle, object.ID)
+	}
+	if object.PatternType != "stix" {
+		return nil, fmt.Errorf("%w: pattern type %q", ErrUnsupportedPattern, object.PatternType)
+	}
+
+	indicatorType, value, err := ParsePattern(object.Pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	confidence := defaultConfidence
+	if object.Confidence != nil {
+		confidence = *object.Confidence
+	}
+
+	indicator, err := domain.NewIndicator(indicatorType, value, confidence, domain.Severity(object.Severity))
+	if err != nil {
+		return nil, err
+	}
+
+	indicator.StixID = object.ID
+	indicator.Tags = domain.NormalizeTags(object.Labels)
+	indicator.Source = object.Source
+	indicator.ExpiresAt = object.ValidUntil
+	if object.Created != nil {
+		indicator.CreatedAt = *object.Created
+	}
+	if object.ValidFrom != nil {
+		indicator.FirstSeen = *object.ValidFrom
+		indicator.LastSeen = *object.ValidFrom
+	}
+	if object.LastSeen != nil {
+		indicator.Seen(*object.LastSeen)
+	}
+
+	return indicator, nil
+}
+
+func FromIndicator(indicator *domain.Indicator) Object {
+	confidence := indicator.Confidence
+	object := Object{
+		Type:        TypeIndicator,
+		SpecVersion: SpecVersion,
+		ID:          indicator.StixID,
+		Created:     timestamp(indicator.CreatedAt),
+		Modified:    timestamp(indicator.UpdatedAt),
+		Name:        indicator.Value,
+		Labels:      indicator.Tags,
+		Confidence:  &confidence,
+		Pattern:     BuildPattern(indicator.Type, indicator.Value),
+		PatternType: "stix",
+		ValidFrom:   timestamp(indicator.FirstSeen),
+		Severity:    string(indicator.Severity),
+		LastSeen:    timestamp(indicator.LastSeen),
+		Source:      indicator.Source,
+	}
+	if object.ID == "" {
+		object.ID = domain.NewStixID(TypeIndicator, indicator.ID)
+	}
+	if len(indicator.Tags) == 0 {
+		object.Labels = nil
+	}
+	if indicator.ExpiresAt != nil {
+		object.ValidUntil = timestamp(*indicator.ExpiresAt)
+	}
+	return object
+}
+
+func ToThreatEntity(object Object) (*domain.ThreatEntity, error) {
+	entity, err := domain.NewThreatEntity(domain.ThreatEntityType(object.Type), object.ID, object.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	entity.Description = object.Description
+	entity.Labels = domain.NormalizeTags(object.Labels)
+	entity.IsFamily = object.IsFamily != nil && *object.IsFamily
+	if len(object.Aliases) > 0 {
+		entity.Aliases = domain.Tags(object.Aliases)
+	}
+	if object.Created != nil {
+		entity.CreatedAt = *object.Created
+	}
+
+	return entity, nil
+}
+
+func FromThreatEntity(entity *domain.ThreatEntity) Object {
+	object := Object{
+		Type:        string(entity.Type),
+		SpecVersion: SpecVersion,
+		ID:          entity.StixID,
+		Created:     timestamp(entity.CreatedAt),
+		Modified:    timestamp(entity.UpdatedAt),
+		Name:        entity.Name,
+		Description: entity.Description,
+		Labels:      entity.Labels,
+		Aliases:     entity.Aliases,
+	}
+	if entity.Type == domain.ThreatEntityMalware {
+		isFamily := entity.IsFamily
+		object.IsFamily = &isFamily
+	}
+	if len(object.Labels) == 0 {
+		object.Labels = nil
+	}
+	if len(object.Aliases) == 0 {
+		object.Aliases = nil
+	}
+	return object
+}
+
+func ToRelationship(object Object) (*domain.Relationship, error) {
+	relationship, err := domain.NewRelationship(object.ID, object.RelationshipType, object.SourceRef, object.TargetRef)
+	if err != nil {
+		return nil, err
+	}
+
+	relationship.Description = object.Description
+	if object.Created != nil {
+		relationship.CreatedAt = *object.Created
+	}
+
+	return relationship, nil
+}
+
+func FromRelationship(relationship *domain.Relationship) Object {
+	return Object{
+		Type:             TypeRelationship,
+		SpecVersion:      SpecVersion,
+		ID:               relationship.StixID,
+		Created:          timestamp(relationship.CreatedAt),
+		Modified:         timestamp(relationship.UpdatedAt),
+		Description:      relationship.Description,
+		RelationshipType: relationship.RelationshipType,
+		SourceRef:        relationship.SourceRef,
+		TargetRef:        relationship.TargetRef,
+	}
+}
@@ -0,0 +1,154 @@
+package stix
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"threat-intel-backend/domain"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// roundTrip converts every supported object of the fixture to the domain
+// model and back, then re-encodes and decodes the resulting bundle.
+func roundTrip(t *testing.T, name string) (*Bundle, *Bundle, []error) {
+	original := loadFixture(t, name)
+
+	var objects []Object
+	var errs []error
+	for _, object := range original.Objects {
+		switch object.Type {
+		case TypeIndicator:
+			indicator, err := ToIndicator(object)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			objects = append(objects, FromIndicator(indicator))
+		case TypeMalware, TypeThreatActor:
+			entity, err := ToThreatEntity(object)
+			assert.NoError(t, err)
+			objects = append(objects, FromThreatEntity(entity))
+		case TypeRelationship:
+			relationship, err := ToRelationship(object)
+			assert.NoError(t, err)
+			objects = append(objects, FromRelationship(relationship))
+		}
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, json.NewEncoder(&buf).Encode(NewBundle(objects)))
+	exported, err := Decode(&buf)
+	assert.NoError(t, err)
+
+	return original, exported, errs
+}
+
+func objectsByID(bundle *Bundle) map[string]Object {
+	objects := make(map[string]Object, len(bundle.Objects))
+	for _, object := range bundle.Objects {
+		objects[object.ID] = object
+	}
+	return objects
+}
+
+func TestRoundTrip_Campaign(t *testing.T) {
+	original, exported, errs := roundTrip(t, "campaign.json")
+	assert.Empty(t, errs)
+
+	// Everything but the identity survives with its STIX ID.
+	assert.Len(t, exported.Objects, len(original.Objects)-1)
+	objects := objectsByID(exported)
+
+	c2 := objects["indicator--8e2e2d2b-17d4-4cbf-938f-98ee46b3cd3f"]
+	assert.Equal(t, SpecVersion, c2.SpecVersion)
+	assert.Equal(t, "[ipv4-addr:value = '198.51.100.23']", c2.Pattern)
+	assert.Equal(t, "stix", c2.PatternType)
+	assert.Equal(t, 85, *c2.Confidence)
+	assert.Equal(t, []string{"apt-example", "c2"}, c2.Labels)
+	assert.Equal(t, "high", c2.Severity)
+	assert.Equal(t, time.Date(2024, 1, 12, 9, 30, 0, 0, time.UTC), *c2.ValidFrom)
+	assert.Equal(t, time.Date(2025, 1, 12, 9, 30, 0, 0, time.UTC), *c2.ValidUntil)
+
+	phishing := objects["indicator--a932fcc6-e032-476c-826f-cb970a5a1ade"]
+	assert.Equal(t, "[domain-name:value = 'login-example.com']", phishing.Pattern)
+	assert.Equal(t, time.Date(2024, 1, 20, 12, 0, 0, 0, time.UTC), *phishing.LastSeen)
+	assert.Equal(t, "medium", phishing.Severity)
+
+	dropper := objects["indicator--1a5e3c44-6b0e-4a71-9e41-8e3f4a58e3a2"]
+	assert.Equal(t, "[file:hashes.'SHA-256' = 'E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855']", dropper.Pattern)
+	assert.Equal(t, defaultConfidence, *dropper.Confidence)
+
+	payload := objects["indicator--c7a3c0a0-5f8e-4d0b-8a0e-0f3b7b1f5d11"]
+	assert.Equal(t, `[url:value = 'http://login-example.com/it\'s/payload.bin']`, payload.Pattern)
+
+	malware := objects["malware--31b940d4-6f7f-459a-80ea-9c1f17b5891b"]
+	assert.Equal(t, "ExampleRAT", malware.Name)
+	assert.True(t, *malware.IsFamily)
+	assert.Equal(t, []string{"ExRAT"}, malware.Aliases)
+	assert.Equal(t, []string{"remote-access-trojan"}, malware.Labels)
+
+	actor := objects["threat-actor--56f3f0db-b5d5-431c-ae56-c18f02caf500"]
+	assert.Equal(t, "Example Panda", actor.Name)
+	assert.Nil(t, actor.IsFamily)
+
+	uses := objects["relationship--6f0c2b3a-1d2e-4f5a-8b9c-0d1e2f3a4b5c"]
+	assert.Equal(t, "uses", uses.RelationshipType)
+	assert.Equal(t, "threat-actor--56f3f0db-b5d5-431c-ae56-c18f02caf500", uses.SourceRef)
+	assert.Equal(t, "malware--31b940d4-6f7f-459a-80ea-9c1f17b5891b", uses.TargetRef)
+
+	// A second pass is stable.
+	for _, object := range exported.Objects {
+		if object.Type != TypeIndicator {
+			continue
+		}
+		indicator, err := ToIndicator(object)
+		assert.NoError(t, err)
+		again := FromIndicator(indicator)
+		assert.Equal(t, object.Pattern, again.Pattern)
+		assert.Equal(t, object.Labels, again.Labels)
+		assert.Equal(t, *object.Confidence, *again.Confidence)
+		assert.Equal(t, object.ValidFrom, again.ValidFrom)
+		assert.Equal(t, object.LastSeen, again.LastSeen)
+	}
+}
+
+func TestRoundTrip_Hashes(t *testing.T) {
+	_, exported, errs := roundTrip(t, "hashes.json")
+
+	assert.Len(t, errs, 2)
+	for _, err := range errs {
+		assert.True(t, errors.Is(err, ErrUnsupportedPattern))
+	}
+
+	objects := objectsByID(exported)
+	assert.Len(t, objects, 4)
+	assert.Equal(t, "[file:hashes.MD5 = 'D41D8CD98F00B204E9800998ECF8427E']", objects["indicator--2b7c8d9e-0f1a-4b2c-8d3e-4f5a6b7c8d9e"].Pattern)
+	assert.Equal(t, "[file:hashes.'SHA-1' = 'DA39A3EE5E6B4B0D3255BFEF95601890AFD80709']", objects["indicator--3c8d9e0f-1a2b-4c3d-9e4f-5a6b7c8d9e0f"].Pattern)
+	assert.Equal(t, "[email-addr:value = 'billing@phish.example']", objects["indicator--4d9e0f1a-2b3c-4d4e-8f5a-6b7c8d9e0f1a"].Pattern)
+	assert.Equal(t, "[ipv6-addr:value = '2001:db8::1']", objects["indicator--5e0f1a2b-3c4d-4e5f-9a6b-7c8d9e0f1a2b"].Pattern)
+}
+
+func TestToIndicator_InvalidValue(t *testing.T) {
+	_, err := ToIndicator(Object{
+		Type:        TypeIndicator,
+		ID:          "indicator--2b7c8d9e-0f1a-4b2c-8d3e-4f5a6b7c8d9e",
+		Pattern:     "[ipv4-addr:value = '999.1.1.1']",
+		PatternType: "stix",
+	})
+
+	assert.True(t, errors.Is(err, domain.ErrInvalidIndicatorValue))
+}
+
+func TestFromIndicator_AssignsStixID(t *testing.T) {
+	indicator, _ := domain.NewIndicator(domain.IndicatorIPv4, "10.0.0.1", 50, domain.SeverityLow)
+	indicator.StixID = ""
+
+	object := FromIndicator(indicator)
+
+	assert.Equal(t, domain.NewStixID(TypeIndicator, indicator.ID), object.ID)
+	assert.Nil(t, object.Labels)
+	assert.Nil(t, object.ValidUntil)
+}
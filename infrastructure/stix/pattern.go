@@ -0,0 +1,65 @@
+package stix
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"threat-intel-backend/domain"
+)
+
+var ErrUnsupportedPattern = errors.New("unsupported STIX pattern")
+
+// patternPaths maps indicator types to the object path used when exporting.
+var patternPaths = map[domain.IndicatorType]string{
+	domain.IndicatorIPv4:   "ipv4-addr:value",
+	domain.IndicatorIPv6:   "ipv6-addr:value",
+	domain.IndicatorDomain: "domain-name:value",
+	domain.IndicatorURL:    "url:value",
+	domain.IndicatorEmail:  "email-addr:value",
+	domain.IndicatorMD5:    "file:hashes.MD5",
+	domain.IndicatorSHA1:   "file:hashes.'SHA-1'",
+	domain.IndicatorSHA256: "file:hashes.'SHA-256'",
+}
+
+// pathTypes accepts the quoted and unquoted spellings of the hash paths
+// found in the wild in addition to the ones we export.
+var pathTypes = map[string]domain.IndicatorType{
+	"ipv4-addr:value":       domain.IndicatorIPv4,
+	"ipv6-addr:value":       domain.IndicatorIPv6,
+	"domain-name:value":     domain.IndicatorDomain,
+	"url:value":             domain.IndicatorURL,
+	"email-addr:value":      domain.IndicatorEmail,
+	"file:hashes.md5":       domain.IndicatorMD5,
+	"file:hashes.'md5'":     domain.IndicatorMD5,
+	"file:hashes.sha1":      domain.IndicatorSHA1,
+	"file:hashes.'sha1'":    domain.IndicatorSHA1,
+	"file:hashes.'sha-1'":   domain.IndicatorSHA1,
+	"file:hashes.sha256":    domain.IndicatorSHA256,
+	"file:hashes.'sha256'":  domain.IndicatorSHA256,
+	"file:hashes.'sha-256'": domain.IndicatorSHA256,
+}
+
+var comparisonPattern = regexp.MustCompile(`^\[\s*([^\s=\]]+)\s*=\s*'((?:[^'\\]|\\.)*)'\s*\]$`)
+
+// ParsePattern extracts the indicator type and value from a STIX pattern made
+// of a single equality comparison, e.g. [ipv4-addr:value = '198.51.100.1'].
+// Compound patterns cannot be represented as one indicator and are rejected.
+func ParsePattern(pattern string) (domain.IndicatorType, string, error) {
+	match := comparisonPattern.FindStringSubmatch(strings.TrimSpace(pattern))
+	if match == nil {
+		return "", "", ErrUnsupportedPattern
+	}
+
+	indicatorType, ok := pathTypes[strings.ToLower(match[1])]
+	if !ok {
+		return "", "", ErrUnsupportedPattern
+	}
+
+	value := strings.NewReplacer(`\'`, `'`, `\\`, `\`).Replace(match[2])
+	return indicatorType, value, nil
+}
+
+func BuildPattern(indicatorType domain.IndicatorType, value string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+	return "[" + patternPaths[indicatorType] + " = '" + escaped + "']"
+}
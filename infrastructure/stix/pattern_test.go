@@ -0,0 +1,60 @@
+package stix
+
+import (
+	"testing"
+	"threat-intel-backend/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePattern(t *testing.T) {
+	valid := []struct {
+		pattern       string
+		indicatorType domain.IndicatorType
+		value         string
+	}{
+		{"[ipv4-addr:value = '198.51.100.1']", domain.IndicatorIPv4, "198.51.100.1"},
+		{"[ipv6-addr:value='2001:db8::1']", domain.IndicatorIPv6, "2001:db8::1"},
+		{" [ domain-name:value = 'example.com' ] ", domain.IndicatorDomain, "example.com"},
+		{"[url:value = 'http://example.com/it\\'s']", domain.IndicatorURL, "http://example.com/it's"},
+		{"[email-addr:value = 'a@example.com']", domain.IndicatorEmail, "a@example.com"},
+		{"[file:hashes.MD5 = 'abc']", domain.IndicatorMD5, "abc"},
+		{"[file:hashes.'SHA-1' = 'abc']", domain.IndicatorSHA1, "abc"},
+		{"[file:hashes.'SHA-256' = 'abc']", domain.IndicatorSHA256, "abc"},
+		{"[file:hashes.SHA256 = 'abc']", domain.IndicatorSHA256, "abc"},
+	}
+	for _, tc := range valid {
+		t.Run(tc.pattern, func(t *testing.T) {
+			indicatorType, value, err := ParsePattern(tc.pattern)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.indicatorType, indicatorType)
+			assert.Equal(t, tc.value, value)
+		})
+	}
+
+	unsupported := []string{
+		"[ipv4-addr:value = '198.51.100.1'] OR [ipv4-addr:value = '198.51.100.2']",
+		"[ipv4-addr:value = '198.51.100.1' AND ipv4-addr:value = '198.51.100.2']",
+		"[file:name = 'evil.exe']",
+		"[ipv4-addr:value ISSUBSET '198.51.100.0/24']",
+		"not a pattern",
+	}
+	for _, pattern := range unsupported {
+		t.Run("rejects "+pattern, func(t *testing.T) {
+			_, _, err := ParsePattern(pattern)
+			assert.Equal(t, ErrUnsupportedPattern, err)
+		})
+	}
+}
+
+func TestBuildPattern(t *testing.T) {
+	assert.Equal(t, "[file:hashes.'SHA-256' = 'abc']", BuildPattern(domain.IndicatorSHA256, "abc"))
+
+	pattern := BuildPattern(domain.IndicatorURL, `http://example.com/it's\x`)
+	assert.Equal(t, `[url:value = 'http://example.com/it\'s\\x']`, pattern)
+
+	indicatorType, value, err := ParsePattern(pattern)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.IndicatorURL, indicatorType)
+	assert.Equal(t, `http://example.com/it's\x`, value)
+}
@@ -0,0 +1,163 @@
+package stix
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+	"threat-intel-backend/domain"
+	"github.com/google/uuid"
+)
+
+const (
+	SpecVersion = "2.1"
+
+	TypeBundle       = "bundle"
+	TypeIndicator    = "indicator"
+	TypeMalware      = "malware"
+	TypeThreatActor  = "threat-actor"
+	TypeRelationship = "relationship"
+	TypeIdentity     = "identity"
+
+	// MediaType is the content type of STIX 2.1 bundles as used by TAXII 2.1.
+	MediaType = "application/stix+json;version=2.1"
+)
+
+var ErrInvalidBundle = errors.New("invalid STIX bundle")
+
+// contextTypes are the STIX 2.1 object types that describe or annotate the
+// objects we import without carrying intel of their own.
+var contextTypes = map[string]bool{
+	// Domain objects
+	"attack-pattern":   true,
+	"campaign":         true,
+	"course-of-action": true,
+	"grouping":         true,
+	"identity":         true,
+	"incident":         true,
+	"infrastructure":   true,
+	"intrusion-set":    true,
+	"location":         true,
+	"malware-analysis": true,
+	"note":             true,
+	"observed-data":    true,
+	"opinion":          true,
+	"report":           true,
+	"tool":             true,
+	"vulnerability":    true,
+
+	// Relationship and meta objects
+	"sighting":             true,
+	"marking-definition":   true,
+	"language-content":     true,
+	"extension-definition": true,
+
+	// Cyber-observable objects
+	"artifact":             true,
+	"autonomous-system":    true,
+	"directory":            true,
+	"domain-name":          true,
+	"email-addr":           true,
+	"email-message":        true,
+	"file":                 true,
+	"ipv4-addr":            true,
+	"ipv6-addr":            true,
+	"mac-addr":             true,
+	"mutex":                true,
+	"network-traffic":      true,
+	"process":              true,
+	"software":             true,
+	"url":                  true,
+	"user-account":         true,
+	"windows-registry-key": true,
+	"x509-certificate":     true,
+}
+
+// IsContextType reports whether objectType is a STIX 2.1 type that is not
+// imported, such as identities, marking definitions and reports. Custom and
+// misspelled types are not context types.
+func IsContextType(objectType string) bool {
+	return contextTypes[objectType]
+}
+
+type Bundle struct {
+	Type    string   `json:"type"`
+	ID      string   `json:"id"`
+	Objects []Object `json:"objects"`
+}
+
+// Object holds the union of the properties of the STIX object types we
+// understand. Properties prefixed with x_ are custom properties used to carry
+// fields that have no STIX equivalent.
+type Object struct {
+	Type        string     `json:"type"`
+	SpecVersion string     `json:"spec_version,omitempty"`
+	ID          string     `json:"id"`
+	Created     *time.Time `json:"created,omitempty"`
+	Modified    *time.Time `json:"modified,omitempty"`
+	Name        string     `json:"name,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Labels      []string   `json:"labels,omitempty"`
+	Confidence  *int       `json:"confidence,omitempty"`
+
+	// Indicator
+	Pattern     string     `json:"pattern,omitempty"`
+	PatternType string     `json:"pattern_type,omitempty"`
+	ValidFrom   *time.Time `json:"valid_from,omitempty"`
+	ValidUntil  *time.Time `json:"valid_until,omitempty"`
+
+	// Malware and threat actor
+	IsFamily *bool    `json:"is_family,omitempty"`
+	Aliases  []string `json:"aliases,omitempty"`
+
+	// Relationship
+	RelationshipType string `json:"relationship_type,omitempty"`
+	SourceRef        string `json:"source_ref,omitempty"`
+	TargetRef        string `json:"target_ref,omitempty"`
+
+	// Identity that produced the object
+	CreatedByRef string `json:"created_by_ref,omitempty"`
+
+	// Custom properties
+	Severity string     `json:"x_severity,omitempty"`
+	LastSeen *time.Time `json:"x_last_seen,omitempty"`
+	Source   string     `json:"x_source,omitempty"`
+}
+
+// Decode reads a bundle and checks its envelope. Individual objects are
+// validated when they are converted.
+func Decode(r io.Reader) (*Bundle, error) {
+	var bundle Bundle
+	if err := json.NewDecoder(r).Decode(&bundle); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	if bundle.Type != TypeBundle {
+		return nil, fmt.Errorf("%w: type must be %q", ErrInvalidBundle, TypeBundle)
+	}
+	if objectType, _, err := domain.ParseStixID(bundle.ID); err != nil || objectType != TypeBundle {
+		return nil, fmt.Errorf("%w: malformed id %q", ErrInvalidBundle, bundle.ID)
+	}
+	for i, object := range bundle.Objects {
+		if objectType, _, err := domain.ParseStixID(object.ID); err != nil || objectType != object.Type {
+			return nil, fmt.Errorf("%w: object %d has malformed id %q", ErrInvalidBundle, i, object.ID)
+		}
+	}
+	return &bundle, nil
+}
+
+func NewBundle(objects []Object) *Bundle {
+	if objects == nil {
+		objects = []Object{}
+	}
+	return &Bundle{
+		Type:    TypeBundle,
+		ID:      domain.NewStixID(TypeBundle, uuid.New()),
+		Objects: objects,
+	}
+}
+
+func timestamp(t time.Time) *time.Time {
+	utc := t.UTC()
+	return &utc
+}
@@ -0,0 +1,63 @@
+package stix
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func loadFixture(t *testing.T, name string) *Bundle {
+	file, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatalf("failed to open fixture: %v", err)
+	}
+	defer file.Close()
+
+	bundle, err := Decode(file)
+	if err != nil {
+		t.Fatalf("failed to decode fixture: %v", err)
+	}
+	return bundle
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("decodes fixture", func(t *testing.T) {
+		bundle := loadFixture(t, "campaign.json")
+
+		assert.Equal(t, "bundle--5d0092c5-5f74-4287-9642-33f4c354e56d", bundle.ID)
+		assert.Len(t, bundle.Objects, 10)
+	})
+
+	invalid := map[string]string{
+		"malformed json":     `{"type":"bundle"`,
+		"wrong type":         `{"type":"indicator","id":"indicator--5d0092c5-5f74-4287-9642-33f4c354e56d"}`,
+		"malformed id":       `{"type":"bundle","id":"bundle--1"}`,
+		"object id mismatch": `{"type":"bundle","id":"bundle--5d0092c5-5f74-4287-9642-33f4c354e56d","objects":[{"type":"malware","id":"indicator--5d0092c5-5f74-4287-9642-33f4c354e56d"}]}`,
+	}
+	for name, data := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := Decode(strings.NewReader(data))
+			assert.True(t, errors.Is(err, ErrInvalidBundle))
+		})
+	}
+}
+
+func TestNewBundle(t *testing.T) {
+	bundle := NewBundle(nil)
+
+	assert.Equal(t, TypeBundle, bundle.Type)
+	assert.True(t, strings.HasPrefix(bundle.ID, "bundle--"))
+	assert.NotNil(t, bundle.Objects)
+}
+
+func TestIsContextType(t *testing.T) {
+	for _, objectType := range []string{"identity", "marking-definition", "report", "sighting", "ipv4-addr"} {
+		assert.True(t, IsContextType(objectType), objectType)
+	}
+	for _, objectType := range []string{TypeIndicator, TypeMalware, TypeRelationship, "x-acme-widget", "indicater"} {
+		assert.False(t, IsContextType(objectType), objectType)
+	}
+}
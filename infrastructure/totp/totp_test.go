@@ -0,0 +1,82 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rfcSecret is the SHA1 key of the RFC 6238 test vectors.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode_RFC6238Vectors(t *testing.T) {
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, want := range vectors {
+		code, err := Code(rfcSecret, Step(time.Unix(unix, 0)))
+		assert.NoError(t, err)
+		assert.Equal(t, want, code, "t=%d", unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	code, _ := Code(rfcSecret, Step(now))
+
+	t.Run("accepts current code", func(t *testing.T) {
+		step, ok := Validate(rfcSecret, code, now, 1)
+		assert.True(t, ok)
+		assert.Equal(t, Step(now), step)
+	})
+
+	t.Run("accepts previous step within skew", func(t *testing.T) {
+		step, ok := Validate(rfcSecret, code, now.Add(Period), 1)
+		assert.True(t, ok)
+		assert.Equal(t, Step(now), step)
+	})
+
+	t.Run("rejects code outside skew", func(t *testing.T) {
+		_, ok := Validate(rfcSecret, code, now.Add(3*Period), 1)
+		assert.False(t, ok)
+	})
+
+	t.Run("rejects malformed code", func(t *testing.T) {
+		_, ok := Validate(rfcSecret, "12345", now, 1)
+		assert.False(t, ok)
+	})
+
+	t.Run("rejects invalid secret", func(t *testing.T) {
+		_, ok := Validate("not base32!", code, now, 1)
+		assert.False(t, ok)
+	})
+}
+
+func TestGenerateSecret(t *testing.T) {
+	first, err := GenerateSecret()
+	assert.NoError(t, err)
+	second, _ := GenerateSecret()
+
+	assert.Len(t, first, 32)
+	assert.NotEqual(t, first, second)
+	_, err = Code(first, 1)
+	assert.NoError(t, err)
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("Zentara", "analyst@example.com", "JBSWY3DPEHPK3PXP")
+
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Zentara:analyst@example.com?"))
+	assert.Contains(t, uri, "secret=JBSWY3DPEHPK3PXP")
+	assert.Contains(t, uri, "issuer=Zentara")
+	assert.Contains(t, uri, "digits=6")
+}
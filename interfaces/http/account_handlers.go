@@ -0,0 +1,266 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"threat-intel-backend/application"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AccountServiceInterface interface {
+	Register(req application.RegisterRequest) (*application.RegisterResponse, error)
+	ResendVerification(req application.EmailRequest) error
+	VerifyEmail(req application.VerifyEmailRequest) (*application.UserResponse, error)
+	ForgotPassword(req application.EmailRequest) error
+	ResetPassword(req application.ResetPasswordRequest) error
+	Profile(userID uuid.UUID) (*application.UserResponse, error)
+	UpdateProfile(userID uuid.UUID, req application.UpdateProfileRequest) (*application.UserResponse, error)
+	ChangePassword(userID uuid.UUID, req application.ChangePasswordRequest) error
+}
+
+func (h *Handler) WithAccountService(accountService AccountServiceInterface) *Handler {
+	h.accountService = accountService
+	return h
+}
+
+// @Summary User registration
+// @Description Register a new viewer account. It stays inactive until the email address is verified with the link sent to it
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body application.RegisterRequest true "Registration data"
+// @Success 201 {object} application.RegisterResponse
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /auth/register [post]
+func (h *Handler) Register(c *gin.Context) {
+	var req application.RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.accountService.Register(req)
+	if err != nil {
+		h.logger.WithError(err).Error("Registration failed")
+		c.JSON(accountErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithField("user_id", response.User.ID).Info("User registered")
+	c.JSON(http.StatusCreated, response)
+}
+
+// @Summary Verify email
+// @Description Verify an email address with the token of a verification link, activating the account
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body application.VerifyEmailRequest true "Verification token"
+// @Success 200 {object} application.UserResponse
+// @Failure 400 {object} map[string]string
+// @Router /auth/verify [post]
+func (h *Handler) VerifyEmail(c *gin.Context) {
+	var req application.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.accountService.VerifyEmail(req)
+	if err != nil {
+		h.logger.WithError(err).Warn("Email verification failed")
+		c.JSON(accountErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithField("user_id", user.ID).Info("Email verified")
+	c.JSON(http.StatusOK, user)
+}
+
+// @Summary Resend verification email
+// @Description Send a new verification link to an account awaiting verification. The response is the same whether or not such an account exists
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body application.EmailRequest true "Email address"
+// @Success 202 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Router /auth/verify/resend [post]
+func (h *Handler) ResendVerification(c *gin.Context) {
+	var req application.EmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Failures are only logged: answering differently would tell which
+	// emails have accounts.
+	if err := h.accountService.ResendVerification(req); err != nil {
+		h.logger.WithError(err).Error("Verification email failed")
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the account awaits verification, a new link has been sent"})
+}
+
+// @Summary Forgot password
+// @Description Email a single-use password reset link. The response is the same whether or not the account exists
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body application.EmailRequest true "Email address"
+// @Success 202 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Router /auth/password/forgot [post]
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	var req application.EmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.accountService.ForgotPassword(req); err != nil {
+		h.logger.WithError(err).Error("Password reset email failed")
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the account exists, a password reset link has been sent"})
+}
+
+// @Summary Reset password
+// @Description Set a new password with the token of a reset link. Every session of the user is revoked
+// @Tags auth
+// @Accept json
+// @Param request body application.ResetPasswordRequest true "Reset token and new password"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /auth/password/reset [post]
+func (h *Handler) ResetPassword(c *gin.Context) {
+	var req application.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.accountService.ResetPassword(req); err != nil {
+		h.logger.WithError(err).Warn("Password reset failed")
+		c.JSON(accountErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("Password reset")
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Get my profile
+// @Description Return the caller's own account
+// @Tags profile
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} application.UserResponse
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/me [get]
+func (h *Handler) GetProfile(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	response, err := h.accountService.Profile(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(accountErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Update my profile
+// @Description Change the caller's display name
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body application.UpdateProfileRequest true "Fields to change"
+// @Success 200 {object} application.UserResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/me [patch]
+func (h *Handler) UpdateProfile(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var req application.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.accountService.UpdateProfile(userID.(uuid.UUID), req)
+	if err != nil {
+		h.logger.WithError(err).Error("Profile update failed")
+		c.JSON(accountErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Change my password
+// @Description Replace the caller's password, which requires the current one. Every session of the caller, this one included, is revoked
+// @Tags profile
+// @Accept json
+// @Security BearerAuth
+// @Param request body application.ChangePasswordRequest true "Current and new password"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Router /api/v1/me/password [post]
+func (h *Handler) ChangePassword(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var req application.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.ClientIP = c.ClientIP()
+
+	if err := h.accountService.ChangePassword(userID.(uuid.UUID), req); err != nil {
+		h.logger.WithError(err).Warn("Password change failed")
+		if errors.Is(err, application.ErrAccountLocked) {
+			loginError(c, err)
+			return
+		}
+		c.JSON(accountErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithField("user_id", userID).Info("Password changed")
+	c.Status(http.StatusNoContent)
+}
+
+func accountErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, application.ErrInvalidResetToken), errors.Is(err, application.ErrInvalidVerificationToken),
+		errors.Is(err, application.ErrWeakPassword), errors.Is(err, application.ErrWrongPassword):
+		return http.StatusBadRequest
+	case errors.Is(err, application.ErrEmailExists), errors.Is(err, application.ErrNoPassword):
+		return http.StatusConflict
+	case errors.Is(err, application.ErrAccountInactive):
+		return http.StatusForbidden
+	default:
+		return userErrorStatus(err)
+	}
+}
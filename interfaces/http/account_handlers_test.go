@@ -0,0 +1,315 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"threat-intel-backend/application"
+	"threat-intel-backend/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAccountService struct {
+	mock.Mock
+}
+
+func (m *MockAccountService) Register(req application.RegisterRequest) (*application.RegisterResponse, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*application.RegisterResponse), args.Error(1)
+}
+
+func (m *MockAccountService) ResendVerification(req application.EmailRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockAccountService) VerifyEmail(req application.VerifyEmailRequest) (*application.UserResponse, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*application.UserResponse), args.Error(1)
+}
+
+func (m *MockAccountService) ForgotPassword(req application.EmailRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockAccountService) ResetPassword(req application.ResetPasswordRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockAccountService) Profile(userID uuid.UUID) (*application.UserResponse, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*application.UserResponse), args.Error(1)
+}
+
+func (m *MockAccountService) UpdateProfile(userID uuid.UUID, req application.UpdateProfileRequest) (*application.UserResponse, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*application.UserResponse), args.Error(1)
+}
+
+func (m *MockAccountService) ChangePassword(userID uuid.UUID, req application.ChangePasswordRequest) error {
+	args := m.Called(userID, req)
+	return args.Error(0)
+}
+
+func setupAccountHandler() (*Handler, *MockAccountService) {
+	handler, _, _ := setupHandler()
+	mockAccount := &MockAccountService{}
+	handler.WithAccountService(mockAccount)
+	return handler, mockAccount
+}
+
+func TestRegister(t *testing.T) {
+	handler, mockAccount := setupAccountHandler()
+
+	t.Run("successful registration", func(t *testing.T) {
+		req := application.RegisterRequest{Email: "new@example.com", Password: "password123"}
+		user := &application.UserResponse{ID: uuid.New(), Email: "new@example.com"}
+		mockAccount.On("Register", req).Return(&application.RegisterResponse{User: user, Message: "Check your email"}, nil).Once()
+
+		c, w := newMFARequest("/auth/register", req)
+		handler.Register(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.NotContains(t, w.Body.String(), "access_token")
+		mockAccount.AssertExpectations(t)
+	})
+
+	t.Run("role in payload is ignored", func(t *testing.T) {
+		req := application.RegisterRequest{Email: "sneaky@example.com", Password: "password123"}
+		user := &application.UserResponse{ID: uuid.New(), Email: "sneaky@example.com", Role: domain.RoleViewer}
+		mockAccount.On("Register", req).Return(&application.RegisterResponse{User: user}, nil).Once()
+
+		c, w := newMFARequest("/auth/register", map[string]string{"email": "sneaky@example.com", "password": "password123", "role": "admin"})
+		handler.Register(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockAccount.AssertExpectations(t)
+	})
+
+	t.Run("email already exists", func(t *testing.T) {
+		req := application.RegisterRequest{Email: "existing@example.com", Password: "password123"}
+		mockAccount.On("Register", req).Return(nil, application.ErrEmailExists).Once()
+
+		c, w := newMFARequest("/auth/register", req)
+		handler.Register(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		c, w := newMFARequest("/auth/register", map[string]string{"email": "not-an-email", "password": "password123"})
+		handler.Register(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestVerifyEmail(t *testing.T) {
+	handler, mockAccount := setupAccountHandler()
+	req := application.VerifyEmailRequest{Token: "verification-token"}
+
+	t.Run("activates the account", func(t *testing.T) {
+		mockAccount.On("VerifyEmail", req).Return(&application.UserResponse{ID: uuid.New(), IsActive: true}, nil).Once()
+
+		c, w := newMFARequest("/auth/verify", req)
+		handler.VerifyEmail(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"is_active":true`)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		mockAccount.On("VerifyEmail", req).Return(nil, application.ErrInvalidVerificationToken).Once()
+
+		c, w := newMFARequest("/auth/verify", req)
+		handler.VerifyEmail(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestForgotPassword(t *testing.T) {
+	handler, mockAccount := setupAccountHandler()
+	req := application.EmailRequest{Email: "user@example.com"}
+
+	t.Run("accepts the request", func(t *testing.T) {
+		mockAccount.On("ForgotPassword", req).Return(nil).Once()
+
+		c, w := newMFARequest("/auth/password/forgot", req)
+		handler.ForgotPassword(c)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+	})
+
+	t.Run("hides mail failures", func(t *testing.T) {
+		mockAccount.On("ForgotPassword", req).Return(errors.New("smtp unavailable")).Once()
+
+		c, w := newMFARequest("/auth/password/forgot", req)
+		handler.ForgotPassword(c)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.NotContains(t, w.Body.String(), "smtp")
+	})
+}
+
+func TestResendVerification(t *testing.T) {
+	handler, mockAccount := setupAccountHandler()
+	req := application.EmailRequest{Email: "new@example.com"}
+	mockAccount.On("ResendVerification", req).Return(nil).Once()
+
+	c, w := newMFARequest("/auth/verify/resend", req)
+	handler.ResendVerification(c)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	mockAccount.AssertExpectations(t)
+}
+
+func TestResetPassword(t *testing.T) {
+	handler, mockAccount := setupAccountHandler()
+	req := application.ResetPasswordRequest{Token: "reset-token", NewPassword: "newpassword456"}
+
+	t.Run("resets the password", func(t *testing.T) {
+		mockAccount.On("ResetPassword", req).Return(nil).Once()
+
+		c, _ := newMFARequest("/auth/password/reset", req)
+		handler.ResetPassword(c)
+
+		assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		mockAccount.On("ResetPassword", req).Return(application.ErrInvalidResetToken).Once()
+
+		c, w := newMFARequest("/auth/password/reset", req)
+		handler.ResetPassword(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("weak password", func(t *testing.T) {
+		weak := application.ResetPasswordRequest{Token: "reset-token", NewPassword: "short"}
+		mockAccount.On("ResetPassword", weak).Return(fmt.Errorf("%w: it must be at least 8 characters long", application.ErrWeakPassword)).Once()
+
+		c, w := newMFARequest("/auth/password/reset", weak)
+		handler.ResetPassword(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "at least 8 characters")
+	})
+
+	t.Run("requires a new password", func(t *testing.T) {
+		c, w := newMFARequest("/auth/password/reset", map[string]string{"token": "reset-token"})
+		handler.ResetPassword(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestGetProfile(t *testing.T) {
+	handler, mockAccount := setupAccountHandler()
+	userID := uuid.New()
+	mockAccount.On("Profile", userID).Return(&application.UserResponse{ID: userID, Email: "user@example.com", Name: "Ada"}, nil).Once()
+
+	c, w := newAdminContext("GET", "/api/v1/me", nil, userID, "")
+	handler.GetProfile(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"Ada"`)
+	assert.NotContains(t, w.Body.String(), "password")
+}
+
+func TestUpdateProfile(t *testing.T) {
+	handler, mockAccount := setupAccountHandler()
+	userID := uuid.New()
+	name := "Ada Lovelace"
+	req := application.UpdateProfileRequest{Name: &name}
+
+	t.Run("renames", func(t *testing.T) {
+		mockAccount.On("UpdateProfile", userID, req).Return(&application.UserResponse{ID: userID, Name: name}, nil).Once()
+
+		c, w := newAdminContext("PATCH", "/api/v1/me", []byte(`{"name":"Ada Lovelace"}`), userID, "")
+		handler.UpdateProfile(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), name)
+	})
+
+	t.Run("rejects a long name", func(t *testing.T) {
+		body := fmt.Sprintf(`{"name":%q}`, strings.Repeat("a", 101))
+		c, w := newAdminContext("PATCH", "/api/v1/me", []byte(body), userID, "")
+		handler.UpdateProfile(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestChangePassword(t *testing.T) {
+	handler, mockAccount := setupAccountHandler()
+	userID := uuid.New()
+	body := []byte(`{"current_password":"c0rrect-Horse-battery","new_password":"n3w-Horse-battery"}`)
+	req := application.ChangePasswordRequest{CurrentPassword: "c0rrect-Horse-battery", NewPassword: "n3w-Horse-battery", ClientIP: "192.0.2.1"}
+
+	t.Run("changes the password", func(t *testing.T) {
+		mockAccount.On("ChangePassword", userID, req).Return(nil).Once()
+
+		c, _ := newAdminContext("POST", "/api/v1/me/password", body, userID, "")
+		handler.ChangePassword(c)
+
+		assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+	})
+
+	t.Run("wrong current password", func(t *testing.T) {
+		mockAccount.On("ChangePassword", userID, req).Return(application.ErrWrongPassword).Once()
+
+		c, w := newAdminContext("POST", "/api/v1/me/password", body, userID, "")
+		handler.ChangePassword(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("locked out", func(t *testing.T) {
+		mockAccount.On("ChangePassword", userID, req).Return(&application.LoginLockedError{RetryAfter: time.Minute}).Once()
+
+		c, w := newAdminContext("POST", "/api/v1/me/password", body, userID, "")
+		handler.ChangePassword(c)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "60", w.Header().Get("Retry-After"))
+	})
+
+	t.Run("single sign-on account", func(t *testing.T) {
+		mockAccount.On("ChangePassword", userID, req).Return(application.ErrNoPassword).Once()
+
+		c, w := newAdminContext("POST", "/api/v1/me/password", body, userID, "")
+		handler.ChangePassword(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("requires the current password", func(t *testing.T) {
+		c, w := newAdminContext("POST", "/api/v1/me/password", []byte(`{"new_password":"n3w-Horse-battery"}`), userID, "")
+		handler.ChangePassword(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,132 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"threat-intel-backend/application"
+	"threat-intel-backend/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type APIKeyServiceInterface interface {
+	CreateAPIKey(userID uuid.UUID, req application.CreateAPIKeyRequest) (*application.CreateAPIKeyResponse, error)
+	ListAPIKeys(userID uuid.UUID) ([]*domain.APIKey, error)
+	RevokeAPIKey(userID, keyID uuid.UUID) error
+}
+
+func (h *Handler) WithAPIKeyService(apiKeyService APIKeyServiceInterface) *Handler {
+	h.apiKeyService = apiKeyService
+	return h
+}
+
+// @Summary List API keys
+// @Description List the API keys of the authenticated user, including revoked and expired ones
+// @Tags api-keys
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.APIKey
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/me/api-keys [get]
+func (h *Handler) ListAPIKeys(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	keys, err := h.apiKeyService.ListAPIKeys(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// @Summary Create API key
+// @Description Mint a scoped API key acting as the authenticated user. The key is only returned once. intel:write requires the analyst role.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body application.CreateAPIKeyRequest true "Key name, scopes and optional expiry"
+// @Success 201 {object} application.CreateAPIKeyResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/me/api-keys [post]
+func (h *Handler) CreateAPIKey(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var req application.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.apiKeyService.CreateAPIKey(userID.(uuid.UUID), req)
+	if err != nil {
+		c.JSON(apiKeyErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"user_id":    userID,
+		"api_key_id": response.ID,
+		"scopes":     response.Scopes,
+	}).Info("API key created")
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// @Summary Revoke API key
+// @Description Revoke an API key of the authenticated user
+// @Tags api-keys
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "API key ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/me/api-keys/{id} [delete]
+func (h *Handler) RevokeAPIKey(c *gin.Context) {
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	if err := h.apiKeyService.RevokeAPIKey(userID.(uuid.UUID), keyID); err != nil {
+		c.JSON(apiKeyErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"user_id":    userID,
+		"api_key_id": keyID,
+	}).Info("API key revoked")
+
+	c.Status(http.StatusNoContent)
+}
+
+func apiKeyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, application.ErrAPIKeyNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, application.ErrInvalidScope), errors.Is(err, application.ErrInvalidExpiry):
+		return http.StatusBadRequest
+	}
+	return userErrorStatus(err)
+}
@@ -0,0 +1,126 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"threat-intel-backend/application"
+	"threat-intel-backend/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyService) CreateAPIKey(userID uuid.UUID, req application.CreateAPIKeyRequest) (*application.CreateAPIKeyResponse, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*application.CreateAPIKeyResponse), args.Error(1)
+}
+
+func (m *MockAPIKeyService) ListAPIKeys(userID uuid.UUID) ([]*domain.APIKey, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyService) RevokeAPIKey(userID, keyID uuid.UUID) error {
+	args := m.Called(userID, keyID)
+	return args.Error(0)
+}
+
+func setupAPIKeyHandler() (*Handler, *MockAPIKeyService) {
+	handler, _, _ := setupHandler()
+	mockAPIKeys := &MockAPIKeyService{}
+	handler.WithAPIKeyService(mockAPIKeys)
+	return handler, mockAPIKeys
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	handler, mockAPIKeys := setupAPIKeyHandler()
+	userID := uuid.New()
+	req := application.CreateAPIKeyRequest{Name: "SIEM", Scopes: []domain.APIKeyScope{domain.ScopeIntelRead}}
+	body := []byte(`{"name":"SIEM","scopes":["intel:read"]}`)
+
+	t.Run("returns the key once", func(t *testing.T) {
+		key := domain.NewAPIKey(userID, "SIEM", "a1b2c3d4e5f6", "hash", req.Scopes, nil)
+		mockAPIKeys.On("CreateAPIKey", userID, req).Return(&application.CreateAPIKeyResponse{APIKey: key, Key: "tik_a1b2c3d4e5f6_secret"}, nil).Once()
+
+		c, w := newAdminContext("POST", "/api/v1/me/api-keys", body, userID, "")
+		handler.CreateAPIKey(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var resp map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Equal(t, "tik_a1b2c3d4e5f6_secret", resp["key"])
+		assert.Equal(t, "a1b2c3d4e5f6", resp["prefix"])
+		assert.NotContains(t, resp, "secret_hash")
+	})
+
+	t.Run("requires scopes", func(t *testing.T) {
+		c, w := newAdminContext("POST", "/api/v1/me/api-keys", []byte(`{"name":"SIEM"}`), userID, "")
+		handler.CreateAPIKey(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("maps errors", func(t *testing.T) {
+		mockAPIKeys.On("CreateAPIKey", userID, req).Return(nil, application.ErrInsufficientPermissions).Once()
+
+		c, w := newAdminContext("POST", "/api/v1/me/api-keys", body, userID, "")
+		handler.CreateAPIKey(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestListAPIKeys(t *testing.T) {
+	handler, mockAPIKeys := setupAPIKeyHandler()
+	userID := uuid.New()
+	mockAPIKeys.On("ListAPIKeys", userID).Return([]*domain.APIKey{{ID: uuid.New(), Name: "SIEM"}}, nil).Once()
+
+	c, w := newAdminContext("GET", "/api/v1/me/api-keys", nil, userID, "")
+	handler.ListAPIKeys(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "SIEM")
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	handler, mockAPIKeys := setupAPIKeyHandler()
+	userID := uuid.New()
+	keyID := uuid.New()
+
+	t.Run("revokes", func(t *testing.T) {
+		mockAPIKeys.On("RevokeAPIKey", userID, keyID).Return(nil).Once()
+
+		c, _ := newAdminContext("DELETE", "/api/v1/me/api-keys/"+keyID.String(), nil, userID, keyID.String())
+		handler.RevokeAPIKey(c)
+
+		assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockAPIKeys.On("RevokeAPIKey", userID, keyID).Return(application.ErrAPIKeyNotFound).Once()
+
+		c, w := newAdminContext("DELETE", "/api/v1/me/api-keys/"+keyID.String(), nil, userID, keyID.String())
+		handler.RevokeAPIKey(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		c, w := newAdminContext("DELETE", "/api/v1/me/api-keys/abc", nil, userID, "abc")
+		handler.RevokeAPIKey(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
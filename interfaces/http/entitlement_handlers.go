@@ -0,0 +1,149 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"threat-intel-backend/application"
+	"threat-intel-backend/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type EntitlementServiceInterface interface {
+	ListEntitlements(userID uuid.UUID) (*application.EntitlementsResponse, error)
+	AssignSeat(ownerID, entitlementID uuid.UUID, req application.AssignSeatRequest) (*domain.EntitlementSeat, error)
+	RemoveSeat(ownerID, entitlementID, userID uuid.UUID) error
+}
+
+func (h *Handler) WithEntitlementService(entitlementService EntitlementServiceInterface) *Handler {
+	h.entitlementService = entitlementService
+	return h
+}
+
+// @Summary List my entitlements
+// @Description List the entitlements granted by the caller's orders or seats assigned to the caller, and the tier they currently grant
+// @Tags entitlements
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} application.EntitlementsResponse
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/me/entitlements [get]
+func (h *Handler) GetMyEntitlements(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	response, err := h.entitlementService.ListEntitlements(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(userErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Assign entitlement seat
+// @Description Give another user one of the unassigned seats of an entitlement the caller bought. The buyer holds the first seat.
+// @Tags entitlements
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Entitlement ID"
+// @Param request body application.AssignSeatRequest true "Email of the user to give the seat"
+// @Success 201 {object} domain.EntitlementSeat
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/me/entitlements/{id}/seats [post]
+func (h *Handler) AssignEntitlementSeat(c *gin.Context) {
+	entitlementID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid entitlement ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var req application.AssignSeatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	seat, err := h.entitlementService.AssignSeat(userID.(uuid.UUID), entitlementID, req)
+	if err != nil {
+		c.JSON(entitlementErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"user_id":        userID,
+		"entitlement_id": entitlementID,
+		"seat_user_id":   seat.UserID,
+	}).Info("Entitlement seat assigned")
+
+	c.JSON(http.StatusCreated, seat)
+}
+
+// @Summary Remove entitlement seat
+// @Description Take a seat of an entitlement the caller bought away from the user holding it
+// @Tags entitlements
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Entitlement ID"
+// @Param user_id path string true "ID of the user holding the seat"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/me/entitlements/{id}/seats/{user_id} [delete]
+func (h *Handler) RemoveEntitlementSeat(c *gin.Context) {
+	entitlementID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid entitlement ID"})
+		return
+	}
+
+	seatUserID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	if err := h.entitlementService.RemoveSeat(userID.(uuid.UUID), entitlementID, seatUserID); err != nil {
+		c.JSON(entitlementErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"user_id":        userID,
+		"entitlement_id": entitlementID,
+		"seat_user_id":   seatUserID,
+	}).Info("Entitlement seat removed")
+
+	c.Status(http.StatusNoContent)
+}
+
+func entitlementErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, application.ErrEntitlementNotFound), errors.Is(err, application.ErrSeatNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrNoSeatsLeft), errors.Is(err, domain.ErrSeatAlreadyAssigned):
+		return http.StatusConflict
+	}
+	return userErrorStatus(err)
+}
@@ -0,0 +1,160 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"threat-intel-backend/application"
+	"threat-intel-backend/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockEntitlementService struct {
+	mock.Mock
+}
+
+func (m *MockEntitlementService) ListEntitlements(userID uuid.UUID) (*application.EntitlementsResponse, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*application.EntitlementsResponse), args.Error(1)
+}
+
+func (m *MockEntitlementService) AssignSeat(ownerID, entitlementID uuid.UUID, req application.AssignSeatRequest) (*domain.EntitlementSeat, error) {
+	args := m.Called(ownerID, entitlementID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.EntitlementSeat), args.Error(1)
+}
+
+func (m *MockEntitlementService) RemoveSeat(ownerID, entitlementID, userID uuid.UUID) error {
+	args := m.Called(ownerID, entitlementID, userID)
+	return args.Error(0)
+}
+
+func TestGetMyEntitlements(t *testing.T) {
+	handler, _, _ := setupHandler()
+	mockEntitlements := &MockEntitlementService{}
+	handler.WithEntitlementService(mockEntitlements)
+	userID := uuid.New()
+
+	t.Run("returns entitlements", func(t *testing.T) {
+		response := &application.EntitlementsResponse{
+			Tier: domain.TierPremium,
+			Entitlements: []application.EntitlementResponse{
+				{Entitlement: &domain.Entitlement{ID: uuid.New(), Tier: domain.TierPremium, Quantity: 3}, Active: true},
+			},
+		}
+		mockEntitlements.On("ListEntitlements", userID).Return(response, nil).Once()
+
+		c, w := newAdminContext("GET", "/api/v1/me/entitlements", nil, userID, "")
+		handler.GetMyEntitlements(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"tier":"premium"`)
+		assert.Contains(t, w.Body.String(), `"quantity":3`)
+		assert.Contains(t, w.Body.String(), `"active":true`)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		mockEntitlements.On("ListEntitlements", userID).Return(nil, application.ErrUserNotFound).Once()
+
+		c, w := newAdminContext("GET", "/api/v1/me/entitlements", nil, userID, "")
+		handler.GetMyEntitlements(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestAssignEntitlementSeat(t *testing.T) {
+	handler, _, _ := setupHandler()
+	mockEntitlements := &MockEntitlementService{}
+	handler.WithEntitlementService(mockEntitlements)
+	ownerID := uuid.New()
+	entitlementID := uuid.New()
+	req := application.AssignSeatRequest{Email: "colleague@example.com"}
+	body, _ := json.Marshal(req)
+
+	t.Run("assigns", func(t *testing.T) {
+		seat := &domain.EntitlementSeat{EntitlementID: entitlementID, UserID: uuid.New()}
+		mockEntitlements.On("AssignSeat", ownerID, entitlementID, req).Return(seat, nil).Once()
+
+		c, w := newAdminContext("POST", "/api/v1/me/entitlements/"+entitlementID.String()+"/seats", body, ownerID, entitlementID.String())
+		handler.AssignEntitlementSeat(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), seat.UserID.String())
+	})
+
+	t.Run("no seats left", func(t *testing.T) {
+		mockEntitlements.On("AssignSeat", ownerID, entitlementID, req).Return(nil, domain.ErrNoSeatsLeft).Once()
+
+		c, w := newAdminContext("POST", "/api/v1/me/entitlements/"+entitlementID.String()+"/seats", body, ownerID, entitlementID.String())
+		handler.AssignEntitlementSeat(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		mockEntitlements.On("AssignSeat", ownerID, entitlementID, req).Return(nil, application.ErrUserNotFound).Once()
+
+		c, w := newAdminContext("POST", "/api/v1/me/entitlements/"+entitlementID.String()+"/seats", body, ownerID, entitlementID.String())
+		handler.AssignEntitlementSeat(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid email", func(t *testing.T) {
+		c, w := newAdminContext("POST", "/api/v1/me/entitlements/"+entitlementID.String()+"/seats", []byte(`{"email":"nope"}`), ownerID, entitlementID.String())
+		handler.AssignEntitlementSeat(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestRemoveEntitlementSeat(t *testing.T) {
+	handler, _, _ := setupHandler()
+	mockEntitlements := &MockEntitlementService{}
+	handler.WithEntitlementService(mockEntitlements)
+	ownerID := uuid.New()
+	entitlementID := uuid.New()
+	seatUserID := uuid.New()
+
+	newContext := func(userID string) (*gin.Context, *httptest.ResponseRecorder) {
+		c, w := newAdminContext("DELETE", "/api/v1/me/entitlements/"+entitlementID.String()+"/seats/"+userID, nil, ownerID, entitlementID.String())
+		c.Params = append(c.Params, gin.Param{Key: "user_id", Value: userID})
+		return c, w
+	}
+
+	t.Run("removes", func(t *testing.T) {
+		mockEntitlements.On("RemoveSeat", ownerID, entitlementID, seatUserID).Return(nil).Once()
+
+		c, _ := newContext(seatUserID.String())
+		handler.RemoveEntitlementSeat(c)
+
+		assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockEntitlements.On("RemoveSeat", ownerID, entitlementID, seatUserID).Return(application.ErrSeatNotFound).Once()
+
+		c, w := newContext(seatUserID.String())
+		handler.RemoveEntitlementSeat(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid user id", func(t *testing.T) {
+		c, w := newContext("abc")
+		handler.RemoveEntitlementSeat(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,233 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"threat-intel-backend/application"
+	"threat-intel-backend/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type FeedServiceInterface interface {
+	ListFeeds(actorID uuid.UUID) ([]*domain.Feed, error)
+	CreateFeed(actorID uuid.UUID, req application.CreateFeedRequest) (*domain.Feed, error)
+	GetFeed(actorID, id uuid.UUID) (*application.FeedDetailResponse, error)
+	PauseFeed(actorID, id uuid.UUID) (*domain.Feed, error)
+	ResumeFeed(actorID, id uuid.UUID) (*domain.Feed, error)
+	TriggerFeed(actorID, id uuid.UUID) (*domain.Feed, error)
+}
+
+func (h *Handler) WithFeedService(feedService FeedServiceInterface) *Handler {
+	h.feedService = feedService
+	return h
+}
+
+// @Summary List feeds
+// @Description List the external feeds with their schedule, caching state and error counters (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.Feed
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/admin/feeds [get]
+func (h *Handler) ListFeeds(c *gin.Context) {
+	actorID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	feeds, err := h.feedService.ListFeeds(actorID.(uuid.UUID))
+	if err != nil {
+		c.JSON(feedErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, feeds)
+}
+
+// @Summary Create feed
+// @Description Add an external feed; it is pulled right away and then on its schedule (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body application.CreateFeedRequest true "Feed data"
+// @Success 201 {object} domain.Feed
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/admin/feeds [post]
+func (h *Handler) CreateFeed(c *gin.Context) {
+	actorID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var req application.CreateFeedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	feed, err := h.feedService.CreateFeed(actorID.(uuid.UUID), req)
+	if err != nil {
+		h.logger.WithError(err).Error("Feed creation failed")
+		c.JSON(feedErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"actor_id": actorID,
+		"feed_id":  feed.ID,
+		"format":   feed.Format,
+	}).Info("Feed created")
+
+	c.JSON(http.StatusCreated, feed)
+}
+
+// @Summary Get feed
+// @Description Get a feed with its most recent runs (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Feed ID"
+// @Success 200 {object} application.FeedDetailResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/admin/feeds/{id} [get]
+func (h *Handler) GetFeed(c *gin.Context) {
+	actorID, id, ok := h.feedTarget(c)
+	if !ok {
+		return
+	}
+
+	feed, err := h.feedService.GetFeed(actorID, id)
+	if err != nil {
+		c.JSON(feedErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, feed)
+}
+
+// @Summary Pause feed
+// @Description Stop pulling a feed until it is resumed (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Feed ID"
+// @Success 200 {object} domain.Feed
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/admin/feeds/{id}/pause [post]
+func (h *Handler) PauseFeed(c *gin.Context) {
+	h.updateFeed(c, "Feed paused", h.feedService.PauseFeed)
+}
+
+// @Summary Resume feed
+// @Description Pull a paused feed on its schedule again (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Feed ID"
+// @Success 200 {object} domain.Feed
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/admin/feeds/{id}/resume [post]
+func (h *Handler) ResumeFeed(c *gin.Context) {
+	h.updateFeed(c, "Feed resumed", h.feedService.ResumeFeed)
+}
+
+// @Summary Trigger feed
+// @Description Pull a feed on the next scheduler tick instead of at its scheduled time (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Feed ID"
+// @Success 202 {object} domain.Feed
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/admin/feeds/{id}/trigger [post]
+func (h *Handler) TriggerFeed(c *gin.Context) {
+	actorID, id, ok := h.feedTarget(c)
+	if !ok {
+		return
+	}
+
+	feed, err := h.feedService.TriggerFeed(actorID, id)
+	if err != nil {
+		c.JSON(feedErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"actor_id": actorID,
+		"feed_id":  id,
+	}).Info("Feed triggered")
+
+	c.JSON(http.StatusAccepted, feed)
+}
+
+func (h *Handler) updateFeed(c *gin.Context, message string, update func(actorID, id uuid.UUID) (*domain.Feed, error)) {
+	actorID, id, ok := h.feedTarget(c)
+	if !ok {
+		return
+	}
+
+	feed, err := update(actorID, id)
+	if err != nil {
+		c.JSON(feedErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"actor_id": actorID,
+		"feed_id":  id,
+	}).Info(message)
+
+	c.JSON(http.StatusOK, feed)
+}
+
+func (h *Handler) feedTarget(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	actorID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid feed ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return actorID.(uuid.UUID), id, true
+}
+
+func feedErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, application.ErrFeedNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, application.ErrFeedExists),
+		errors.Is(err, application.ErrFeedPaused):
+		return http.StatusConflict
+	case errors.Is(err, application.ErrUnsupportedFormat),
+		errors.Is(err, domain.ErrInvalidFeed),
+		errors.Is(err, domain.ErrInvalidFeedSchedule),
+		errors.Is(err, domain.ErrInvalidIndicatorType),
+		errors.Is(err, domain.ErrInvalidConfidence),
+		errors.Is(err, domain.ErrInvalidSeverity):
+		return http.StatusBadRequest
+	default:
+		return userErrorStatus(err)
+	}
+}
@@ -0,0 +1,192 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"threat-intel-backend/application"
+	"threat-intel-backend/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockFeedService struct {
+	mock.Mock
+}
+
+func (m *MockFeedService) ListFeeds(actorID uuid.UUID) ([]*domain.Feed, error) {
+	args := m.Called(actorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Feed), args.Error(1)
+}
+
+func (m *MockFeedService) CreateFeed(actorID uuid.UUID, req application.CreateFeedRequest) (*domain.Feed, error) {
+	args := m.Called(actorID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Feed), args.Error(1)
+}
+
+func (m *MockFeedService) GetFeed(actorID, id uuid.UUID) (*application.FeedDetailResponse, error) {
+	args := m.Called(actorID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*application.FeedDetailResponse), args.Error(1)
+}
+
+func (m *MockFeedService) PauseFeed(actorID, id uuid.UUID) (*domain.Feed, error) {
+	args := m.Called(actorID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Feed), args.Error(1)
+}
+
+func (m *MockFeedService) ResumeFeed(actorID, id uuid.UUID) (*domain.Feed, error) {
+	args := m.Called(actorID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Feed), args.Error(1)
+}
+
+func (m *MockFeedService) TriggerFeed(actorID, id uuid.UUID) (*domain.Feed, error) {
+	args := m.Called(actorID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Feed), args.Error(1)
+}
+
+func setupFeedHandler() (*Handler, *MockFeedService) {
+	handler, _, _ := setupHandler()
+	mockFeeds := &MockFeedService{}
+	return handler.WithFeedService(mockFeeds), mockFeeds
+}
+
+func TestCreateFeed(t *testing.T) {
+	handler, mockFeeds := setupFeedHandler()
+	actorID := uuid.New()
+	req := application.CreateFeedRequest{
+		Name:     "urlhaus",
+		Format:   domain.FeedCSV,
+		URL:      "https://urlhaus.example.com/csv",
+		Schedule: "@every 30m",
+		Options:  domain.FeedOptions{Type: domain.IndicatorURL, Column: 2, SkipHeader: true},
+	}
+
+	t.Run("creates feed", func(t *testing.T) {
+		feed, _ := domain.NewFeed(req.Name, req.Format, req.URL, req.Schedule, req.Options)
+		mockFeeds.On("CreateFeed", actorID, req).Return(feed, nil).Once()
+
+		body, _ := json.Marshal(req)
+		c, w := newAdminContext("POST", "/api/v1/admin/feeds", body, actorID, "")
+		handler.CreateFeed(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var created domain.Feed
+		_ = json.Unmarshal(w.Body.Bytes(), &created)
+		assert.Equal(t, req.Options, created.Options)
+		mockFeeds.AssertExpectations(t)
+	})
+
+	t.Run("maps invalid schedule", func(t *testing.T) {
+		invalid := req
+		invalid.Schedule = "5s"
+		mockFeeds.On("CreateFeed", actorID, invalid).Return(nil, fmt.Errorf("%w: too often", domain.ErrInvalidFeedSchedule)).Once()
+
+		body, _ := json.Marshal(invalid)
+		c, w := newAdminContext("POST", "/api/v1/admin/feeds", body, actorID, "")
+		handler.CreateFeed(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("rejects missing fields", func(t *testing.T) {
+		c, w := newAdminContext("POST", "/api/v1/admin/feeds", []byte(`{"name":"urlhaus"}`), actorID, "")
+		handler.CreateFeed(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestGetFeed(t *testing.T) {
+	handler, mockFeeds := setupFeedHandler()
+	actorID := uuid.New()
+	feed, _ := domain.NewFeed("blocklist", domain.FeedPlainText, "https://feeds.example.com/ips.txt", "@hourly", domain.FeedOptions{})
+
+	t.Run("returns feed with runs", func(t *testing.T) {
+		detail := &application.FeedDetailResponse{Feed: feed, Runs: []*domain.FeedRun{domain.NewFeedRun(feed.ID)}}
+		mockFeeds.On("GetFeed", actorID, feed.ID).Return(detail, nil).Once()
+
+		c, w := newAdminContext("GET", "/api/v1/admin/feeds/"+feed.ID.String(), nil, actorID, feed.ID.String())
+		handler.GetFeed(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp application.FeedDetailResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Len(t, resp.Runs, 1)
+	})
+
+	t.Run("maps not found", func(t *testing.T) {
+		id := uuid.New()
+		mockFeeds.On("GetFeed", actorID, id).Return(nil, application.ErrFeedNotFound).Once()
+
+		c, w := newAdminContext("GET", "/api/v1/admin/feeds/"+id.String(), nil, actorID, id.String())
+		handler.GetFeed(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("rejects invalid id", func(t *testing.T) {
+		c, w := newAdminContext("GET", "/api/v1/admin/feeds/blocklist", nil, actorID, "blocklist")
+		handler.GetFeed(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestPauseFeed(t *testing.T) {
+	handler, mockFeeds := setupFeedHandler()
+	actorID := uuid.New()
+	feed, _ := domain.NewFeed("blocklist", domain.FeedPlainText, "https://feeds.example.com/ips.txt", "@hourly", domain.FeedOptions{})
+	feed.Pause()
+	mockFeeds.On("PauseFeed", actorID, feed.ID).Return(feed, nil).Once()
+
+	c, w := newAdminContext("POST", "/api/v1/admin/feeds/"+feed.ID.String()+"/pause", nil, actorID, feed.ID.String())
+	handler.PauseFeed(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockFeeds.AssertExpectations(t)
+}
+
+func TestTriggerFeed(t *testing.T) {
+	handler, mockFeeds := setupFeedHandler()
+	actorID := uuid.New()
+	feed, _ := domain.NewFeed("blocklist", domain.FeedPlainText, "https://feeds.example.com/ips.txt", "@hourly", domain.FeedOptions{})
+
+	t.Run("accepts trigger", func(t *testing.T) {
+		mockFeeds.On("TriggerFeed", actorID, feed.ID).Return(feed, nil).Once()
+
+		c, w := newAdminContext("POST", "/api/v1/admin/feeds/"+feed.ID.String()+"/trigger", nil, actorID, feed.ID.String())
+		handler.TriggerFeed(c)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+	})
+
+	t.Run("maps paused feed", func(t *testing.T) {
+		mockFeeds.On("TriggerFeed", actorID, feed.ID).Return(nil, application.ErrFeedPaused).Once()
+
+		c, w := newAdminContext("POST", "/api/v1/admin/feeds/"+feed.ID.String()+"/trigger", nil, actorID, feed.ID.String())
+		handler.TriggerFeed(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
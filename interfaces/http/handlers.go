@@ -1,30 +1,99 @@
 package http
 
 import (
+	"errors"
 	"net/http"
+	"net/netip"
+	"strconv"
+	"time"
 	"threat-intel-backend/application"
 	"threat-intel-backend/domain"
+	"threat-intel-backend/infrastructure/jwt"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
 type AuthServiceInterface interface {
-	Login(req application.LoginRequest) (*application.AuthResponse, error)
-	Register(req application.RegisterRequest) (*application.AuthResponse, error)
+	Login(req application.LoginRequest) (*application.LoginResponse, error)
+	VerifyMFA(req application.VerifyMFARequest) (*application.AuthResponse, error)
+	BeginMFAEnrollment(req application.MFATokenRequest) (*application.MFAEnrollmentResponse, error)
+	CompleteMFAEnrollment(req application.VerifyMFARequest) (*application.MFAEnrolledResponse, error)
 	RefreshToken(token string) (*application.AuthResponse, error)
+	Logout(userID uuid.UUID, tokenID string, expiresAt time.Time, req application.LogoutRequest) error
+	LogoutAll(userID uuid.UUID) error
 }
 
 type OrderServiceInterface interface {
 	CreateOrder(userID uuid.UUID, req application.CreateOrderRequest) (*application.OrderResponse, error)
 	GetOrder(orderID, userID uuid.UUID) (*domain.Order, error)
 	GetUserOrders(userID uuid.UUID) ([]*domain.Order, error)
+	CancelOrder(orderID, userID uuid.UUID) (*domain.Order, error)
+	CompleteOrder(actorID, orderID uuid.UUID) (*domain.Order, error)
 }
 
 type Handler struct {
-	authService  AuthServiceInterface
-	orderService OrderServiceInterface
-	logger       *logrus.Logger
+	authService        AuthServiceInterface
+	orderService       OrderServiceInterface
+	userService        UserServiceInterface
+	invitationService  InvitationServiceInterface
+	indicatorService   IndicatorServiceInterface
+	stixService        StixServiceInterface
+	taxiiService       TaxiiServiceInterface
+	entitlementService EntitlementServiceInterface
+	productService     ProductServiceInterface
+	securityService    SecurityEventServiceInterface
+	mfaService         MFAServiceInterface
+	apiKeyService      APIKeyServiceInterface
+	ssoService         SSOServiceInterface
+	accountService     AccountServiceInterface
+	feedService        FeedServiceInterface
+	scoringService     ScoringServiceInterface
+	lookupService      LookupServiceInterface
+	jwks               JWKSProvider
+	trustedProxies     []netip.Prefix
+	logger             *logrus.Logger
+}
+
+// JWKSProvider publishes the public keys of the token signer.
+type JWKSProvider interface {
+	JWKS() *jwt.JWKSet
+}
+
+func (h *Handler) WithJWKS(jwks JWKSProvider) *Handler {
+	h.jwks = jwks
+	return h
+}
+
+// WithTrustedProxies sets the reverse proxies, by IP address or CIDR range,
+// whose X-Forwarded-Proto header is believed when absolute URLs are built.
+// Invalid entries are skipped.
+func (h *Handler) WithTrustedProxies(proxies []string) *Handler {
+	h.trustedProxies = nil
+	for _, proxy := range proxies {
+		if addr, err := netip.ParseAddr(proxy); err == nil {
+			h.trustedProxies = append(h.trustedProxies, netip.PrefixFrom(addr, addr.BitLen()))
+		} else if prefix, err := netip.ParsePrefix(proxy); err == nil {
+			h.trustedProxies = append(h.trustedProxies, prefix.Masked())
+		}
+	}
+	return h
+}
+
+// fromTrustedProxy reports whether the peer of the request is one of the
+// trusted proxies.
+func (h *Handler) fromTrustedProxy(c *gin.Context) bool {
+	addr, err := netip.ParseAddr(c.RemoteIP())
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range h.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
 }
 
 func NewHandler(authService AuthServiceInterface, orderService OrderServiceInterface, logger *logrus.Logger) *Handler {
@@ -48,15 +117,27 @@ func (h *Handler) Health(c *gin.Context) {
 	})
 }
 
+// @Summary JSON Web Key Set
+// @Description Public keys that verify access and refresh tokens, identified by kid. Empty when tokens are HS256 signed.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} jwt.JWKSet
+// @Router /.well-known/jwks.json [get]
+func (h *Handler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.jwks.JWKS())
+}
+
 // @Summary User login
-// @Description Authenticate user and return JWT tokens
+// @Description Authenticate user and return JWT tokens, or an MFA challenge if a second factor is required
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param request body application.LoginRequest true "Login credentials"
-// @Success 200 {object} application.AuthResponse
+// @Success 200 {object} application.LoginResponse
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 429 {object} map[string]string
 // @Router /auth/login [post]
 func (h *Handler) Login(c *gin.Context) {
 	var req application.LoginRequest
@@ -64,11 +145,17 @@ func (h *Handler) Login(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	req.ClientIP = c.ClientIP()
 
 	response, err := h.authService.Login(req)
 	if err != nil {
 		h.logger.WithError(err).Error("Login failed")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		loginError(c, err)
+		return
+	}
+
+	if response.MFAChallenge != nil {
+		c.JSON(http.StatusOK, response)
 		return
 	}
 
@@ -76,31 +163,15 @@ func (h *Handler) Login(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
-// @Summary User registration
-// @Description Register a new user
-// @Tags auth
-// @Accept json
-// @Produce json
-// @Param request body application.RegisterRequest true "Registration data"
-// @Success 201 {object} application.AuthResponse
-// @Failure 400 {object} map[string]string
-// @Router /auth/register [post]
-func (h *Handler) Register(c *gin.Context) {
-	var req application.RegisterRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	response, err := h.authService.Register(req)
-	if err != nil {
-		h.logger.WithError(err).Error("Registration failed")
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+// loginError answers a failed login step. Lockouts carry Retry-After.
+func loginError(c *gin.Context, err error) {
+	var locked *application.LoginLockedError
+	if errors.As(err, &locked) {
+		c.Header("Retry-After", strconv.Itoa(ceilSeconds(locked.RetryAfter)))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
 		return
 	}
-
-	h.logger.WithField("user_id", response.User.ID).Info("User registered")
-	c.JSON(http.StatusCreated, response)
+	c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 }
 
 // @Summary Refresh token
@@ -131,6 +202,71 @@ func (h *Handler) RefreshToken(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// @Summary Logout
+// @Description Revoke the current access token and, if provided, its refresh token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body application.LogoutRequest false "Refresh token of the session"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /auth/logout [post]
+func (h *Handler) Logout(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var req application.LogoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	tokenID := c.GetString("token_id")
+	expiresAt := c.GetTime("token_expires_at")
+
+	if err := h.authService.Logout(userID.(uuid.UUID), tokenID, expiresAt, req); err != nil {
+		h.logger.WithError(err).Error("Logout failed")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithField("user_id", userID).Info("User logged out")
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+}
+
+// @Summary Logout everywhere
+// @Description Revoke every access and refresh token issued to the authenticated user
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/logout-all [post]
+func (h *Handler) LogoutAll(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	if err := h.authService.LogoutAll(userID.(uuid.UUID)); err != nil {
+		h.logger.WithError(err).Error("Logout all failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithField("user_id", userID).Info("User logged out of all sessions")
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out of all sessions"})
+}
+
 // @Summary Create order
 // @Description Create a new order for threat intelligence data
 // @Tags orders
@@ -225,4 +361,95 @@ func (h *Handler) GetUserOrders(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, orders)
-}
\ No newline at end of file
+}
+
+// @Summary Cancel order
+// @Description Cancel one of your own pending or confirmed orders and revoke its entitlement
+// @Tags orders
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Success 200 {object} domain.Order
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/orders/{id}/cancel [post]
+func (h *Handler) CancelOrder(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	order, err := h.orderService.CancelOrder(orderID, userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(orderErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"user_id":  userID,
+		"order_id": order.ID,
+	}).Info("Order cancelled")
+
+	c.JSON(http.StatusOK, order)
+}
+
+// @Summary Complete order
+// @Description Mark a confirmed order as fulfilled (admin only)
+// @Tags orders
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Success 200 {object} domain.Order
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/orders/{id}/complete [post]
+func (h *Handler) CompleteOrder(c *gin.Context) {
+	actorID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	order, err := h.orderService.CompleteOrder(actorID.(uuid.UUID), orderID)
+	if err != nil {
+		c.JSON(orderErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"actor_id": actorID,
+		"order_id": order.ID,
+	}).Info("Order completed")
+
+	c.JSON(http.StatusOK, order)
+}
+
+func orderErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, application.ErrOrderNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, application.ErrOrderAccessDenied):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrInvalidOrderTransition):
+		return http.StatusConflict
+	default:
+		return userErrorStatus(err)
+	}
+}
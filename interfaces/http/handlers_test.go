@@ -9,6 +9,8 @@ import (
 	"testing"
 	"threat-intel-backend/application"
 	"threat-intel-backend/domain"
+	"threat-intel-backend/infrastructure/jwt"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -21,15 +23,15 @@ type MockAuthService struct {
 	mock.Mock
 }
 
-func (m *MockAuthService) Login(req application.LoginRequest) (*application.AuthResponse, error) {
+func (m *MockAuthService) Login(req application.LoginRequest) (*application.LoginResponse, error) {
 	args := m.Called(req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*application.AuthResponse), args.Error(1)
+	return args.Get(0).(*application.LoginResponse), args.Error(1)
 }
 
-func (m *MockAuthService) Register(req application.RegisterRequest) (*application.AuthResponse, error) {
+func (m *MockAuthService) VerifyMFA(req application.VerifyMFARequest) (*application.AuthResponse, error) {
 	args := m.Called(req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -37,6 +39,22 @@ func (m *MockAuthService) Register(req application.RegisterRequest) (*applicatio
 	return args.Get(0).(*application.AuthResponse), args.Error(1)
 }
 
+func (m *MockAuthService) BeginMFAEnrollment(req application.MFATokenRequest) (*application.MFAEnrollmentResponse, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*application.MFAEnrollmentResponse), args.Error(1)
+}
+
+func (m *MockAuthService) CompleteMFAEnrollment(req application.VerifyMFARequest) (*application.MFAEnrolledResponse, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*application.MFAEnrolledResponse), args.Error(1)
+}
+
 func (m *MockAuthService) RefreshToken(token string) (*application.AuthResponse, error) {
 	args := m.Called(token)
 	if args.Get(0) == nil {
@@ -45,6 +63,16 @@ func (m *MockAuthService) RefreshToken(token string) (*application.AuthResponse,
 	return args.Get(0).(*application.AuthResponse), args.Error(1)
 }
 
+func (m *MockAuthService) Logout(userID uuid.UUID, tokenID string, expiresAt time.Time, req application.LogoutRequest) error {
+	args := m.Called(userID, tokenID, expiresAt, req)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) LogoutAll(userID uuid.UUID) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
 type MockOrderService struct {
 	mock.Mock
 }
@@ -73,6 +101,22 @@ func (m *MockOrderService) GetUserOrders(userID uuid.UUID) ([]*domain.Order, err
 	return args.Get(0).([]*domain.Order), args.Error(1)
 }
 
+func (m *MockOrderService) CancelOrder(orderID, userID uuid.UUID) (*domain.Order, error) {
+	args := m.Called(orderID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Order), args.Error(1)
+}
+
+func (m *MockOrderService) CompleteOrder(actorID, orderID uuid.UUID) (*domain.Order, error) {
+	args := m.Called(actorID, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Order), args.Error(1)
+}
+
 func setupHandler() (*Handler, *MockAuthService, *MockOrderService) {
 	mockAuth := &MockAuthService{}
 	mockOrder := &MockOrderService{}
@@ -99,17 +143,33 @@ func TestHealth(t *testing.T) {
 	assert.Equal(t, "threat-intel-backend", response["service"])
 }
 
+func TestJWKS(t *testing.T) {
+	handler, _, _ := setupHandler()
+	handler.WithJWKS(jwt.NewService("test-secret"))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/.well-known/jwks.json", nil)
+
+	handler.JWKS(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"keys":[]}`, w.Body.String())
+	assert.Equal(t, "public, max-age=300", w.Header().Get("Cache-Control"))
+}
+
 func TestLogin(t *testing.T) {
 	handler, mockAuth, _ := setupHandler()
 
 	t.Run("successful login", func(t *testing.T) {
 		req := application.LoginRequest{Email: "test@example.com", Password: "password123"}
-		user := &domain.User{ID: uuid.New(), Email: "test@example.com"}
-		response := &application.AuthResponse{AccessToken: "token", User: user}
+		user := &application.UserResponse{ID: uuid.New(), Email: "test@example.com"}
+		response := &application.LoginResponse{AuthResponse: &application.AuthResponse{AccessToken: "token", User: user}}
 
+		body, _ := json.Marshal(req)
+		req.ClientIP = "192.0.2.1"
 		mockAuth.On("Login", req).Return(response, nil)
 
-		body, _ := json.Marshal(req)
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Request = httptest.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
@@ -121,67 +181,72 @@ func TestLogin(t *testing.T) {
 		mockAuth.AssertExpectations(t)
 	})
 
-	t.Run("invalid request", func(t *testing.T) {
+	t.Run("MFA challenge", func(t *testing.T) {
+		req := application.LoginRequest{Email: "mfa@example.com", Password: "password123"}
+		response := &application.LoginResponse{MFAChallenge: &application.MFAChallenge{MFARequired: true, MFAToken: "mfa-token", ExpiresIn: 300}}
+
+		body, _ := json.Marshal(req)
+		req.ClientIP = "192.0.2.1"
+		mockAuth.On("Login", req).Return(response, nil).Once()
+
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
-		c.Request = httptest.NewRequest("POST", "/auth/login", bytes.NewBuffer([]byte("invalid")))
+		c.Request = httptest.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
 		c.Request.Header.Set("Content-Type", "application/json")
 
 		handler.Login(c)
 
-		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Equal(t, true, resp["mfa_required"])
+		assert.Equal(t, "mfa-token", resp["mfa_token"])
+		assert.NotContains(t, resp, "access_token")
 	})
 
-	t.Run("login failure", func(t *testing.T) {
-		req := application.LoginRequest{Email: "test@example.com", Password: "wrong"}
-		mockAuth.On("Login", req).Return(nil, errors.New("invalid credentials"))
-
-		body, _ := json.Marshal(req)
+	t.Run("invalid request", func(t *testing.T) {
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
-		c.Request = httptest.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
+		c.Request = httptest.NewRequest("POST", "/auth/login", bytes.NewBuffer([]byte("invalid")))
 		c.Request.Header.Set("Content-Type", "application/json")
 
 		handler.Login(c)
-	})
-}
-
-func TestRegister(t *testing.T) {
-	handler, mockAuth, _ := setupHandler()
-
-	t.Run("successful registration", func(t *testing.T) {
-		req := application.RegisterRequest{Email: "new@example.com", Password: "password123", Role: domain.RoleViewer}
-		user := &domain.User{ID: uuid.New(), Email: "new@example.com"}
-		response := &application.AuthResponse{AccessToken: "token", User: user}
 
-		mockAuth.On("Register", req).Return(response, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
 
+	t.Run("login failure", func(t *testing.T) {
+		req := application.LoginRequest{Email: "test@example.com", Password: "wrongpassword"}
 		body, _ := json.Marshal(req)
+		req.ClientIP = "192.0.2.1"
+		mockAuth.On("Login", req).Return(nil, errors.New("invalid credentials")).Once()
+
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
-		c.Request = httptest.NewRequest("POST", "/auth/register", bytes.NewBuffer(body))
+		c.Request = httptest.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
 		c.Request.Header.Set("Content-Type", "application/json")
 
-		handler.Register(c)
+		handler.Login(c)
 
-		assert.Equal(t, http.StatusCreated, w.Code)
-		mockAuth.AssertExpectations(t)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
 	})
 
-	t.Run("registration failure", func(t *testing.T) {
-		req := application.RegisterRequest{Email: "existing@example.com", Password: "password123", Role: domain.RoleViewer}
-		mockAuth.On("Register", req).Return(nil, errors.New("email already exists"))
-
+	t.Run("locked out", func(t *testing.T) {
+		req := application.LoginRequest{Email: "locked@example.com", Password: "wrongpassword"}
 		body, _ := json.Marshal(req)
+		req.ClientIP = "192.0.2.1"
+		mockAuth.On("Login", req).Return(nil, &application.LoginLockedError{RetryAfter: 90 * time.Second}).Once()
+
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
-		c.Request = httptest.NewRequest("POST", "/auth/register", bytes.NewBuffer(body))
+		c.Request = httptest.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
 		c.Request.Header.Set("Content-Type", "application/json")
 
-		handler.Register(c)
+		handler.Login(c)
 
-		assert.Equal(t, http.StatusBadRequest, w.Code)
-		mockAuth.AssertExpectations(t)
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "90", w.Header().Get("Retry-After"))
+		assert.Contains(t, w.Body.String(), "try again later")
 	})
 }
 
@@ -190,7 +255,7 @@ func TestRefreshToken(t *testing.T) {
 
 	t.Run("successful refresh", func(t *testing.T) {
 		token := "refresh_token"
-		user := &domain.User{ID: uuid.New()}
+		user := &application.UserResponse{ID: uuid.New()}
 		response := &application.AuthResponse{AccessToken: "new_token", User: user}
 
 		mockAuth.On("RefreshToken", token).Return(response, nil)
@@ -224,6 +289,100 @@ func TestRefreshToken(t *testing.T) {
 	})
 }
 
+func TestLogout(t *testing.T) {
+	handler, mockAuth, _ := setupHandler()
+	userID := uuid.New()
+	expiresAt := time.Now().Add(time.Minute)
+
+	newContext := func(body []byte) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/auth/logout", bytes.NewBuffer(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set("user_id", userID)
+		c.Set("token_id", "jti-1")
+		c.Set("token_expires_at", expiresAt)
+		return c, w
+	}
+
+	t.Run("logout without refresh token", func(t *testing.T) {
+		mockAuth.On("Logout", userID, "jti-1", expiresAt, application.LogoutRequest{}).Return(nil).Once()
+
+		c, w := newContext(nil)
+		handler.Logout(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockAuth.AssertExpectations(t)
+	})
+
+	t.Run("logout with refresh token", func(t *testing.T) {
+		req := application.LogoutRequest{RefreshToken: "refresh"}
+		mockAuth.On("Logout", userID, "jti-1", expiresAt, req).Return(nil).Once()
+
+		body, _ := json.Marshal(req)
+		c, w := newContext(body)
+		handler.Logout(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockAuth.AssertExpectations(t)
+	})
+
+	t.Run("invalid refresh token", func(t *testing.T) {
+		req := application.LogoutRequest{RefreshToken: "bad"}
+		mockAuth.On("Logout", userID, "jti-1", expiresAt, req).Return(errors.New("invalid refresh token")).Once()
+
+		body, _ := json.Marshal(req)
+		c, w := newContext(body)
+		handler.Logout(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockAuth.AssertExpectations(t)
+	})
+
+	t.Run("missing user_id", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/auth/logout", nil)
+
+		handler.Logout(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestLogoutAll(t *testing.T) {
+	handler, mockAuth, _ := setupHandler()
+	userID := uuid.New()
+
+	t.Run("successful logout all", func(t *testing.T) {
+		mockAuth.On("LogoutAll", userID).Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/auth/logout-all", nil)
+		c.Set("user_id", userID)
+
+		handler.LogoutAll(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockAuth.AssertExpectations(t)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		mockAuth.On("LogoutAll", userID).Return(errors.New("redis down")).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/auth/logout-all", nil)
+		c.Set("user_id", userID)
+
+		handler.LogoutAll(c)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		mockAuth.AssertExpectations(t)
+	})
+}
+
 func TestCreateOrder(t *testing.T) {
 	handler, _, mockOrder := setupHandler()
 
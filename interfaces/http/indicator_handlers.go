@@ -0,0 +1,264 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"threat-intel-backend/application"
+	"threat-intel-backend/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type IndicatorServiceInterface interface {
+	CreateIndicator(actorID uuid.UUID, req application.CreateIndicatorRequest) (*domain.Indicator, error)
+	GetIndicator(userID, id uuid.UUID) (*domain.Indicator, error)
+	ListSightings(userID, id uuid.UUID) ([]*domain.Sighting, error)
+	ListIndicators(userID uuid.UUID, req application.ListIndicatorsRequest) (*application.IndicatorListResponse, error)
+	UpdateIndicator(actorID, id uuid.UUID, req application.UpdateIndicatorRequest) (*domain.Indicator, error)
+	DeleteIndicator(actorID, id uuid.UUID) error
+}
+
+func (h *Handler) WithIndicatorService(indicatorService IndicatorServiceInterface) *Handler {
+	h.indicatorService = indicatorService
+	return h
+}
+
+// @Summary List indicators
+// @Description List threat indicators with optional filters, limited to the types covered by the caller's entitlement (viewer+)
+// @Tags indicators
+// @Produce json
+// @Security BearerAuth
+// @Param type query string false "Filter by indicator type"
+// @Param severity query string false "Filter by severity"
+// @Param tag query string false "Filter by tag"
+// @Param source query string false "Filter by source"
+// @Param include_expired query bool false "Include expired indicators"
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} application.IndicatorListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/indicators [get]
+func (h *Handler) ListIndicators(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var req application.ListIndicatorsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.indicatorService.ListIndicators(userID.(uuid.UUID), req)
+	if err != nil {
+		c.JSON(indicatorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Get indicator
+// @Description Get a threat indicator by ID if its type is covered by the caller's entitlement (viewer+)
+// @Tags indicators
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Indicator ID"
+// @Success 200 {object} domain.Indicator
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/indicators/{id} [get]
+func (h *Handler) GetIndicator(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid indicator ID"})
+		return
+	}
+
+	indicator, err := h.indicatorService.GetIndicator(userID.(uuid.UUID), id)
+	if err != nil {
+		c.JSON(indicatorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, indicator)
+}
+
+// @Summary List indicator sightings
+// @Description List the sources that reported an indicator, with when and how often each saw it and the raw entry it last reported (viewer+)
+// @Tags indicators
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Indicator ID"
+// @Success 200 {array} domain.Sighting
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/indicators/{id}/sightings [get]
+func (h *Handler) ListIndicatorSightings(c *gin.Context) {
+	userID, id, ok := h.indicatorTarget(c)
+	if !ok {
+		return
+	}
+
+	sightings, err := h.indicatorService.ListSightings(userID, id)
+	if err != nil {
+		c.JSON(indicatorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, sightings)
+}
+
+// @Summary Create indicator
+// @Description Create a threat indicator; the value is normalized for its type (analyst+)
+// @Tags indicators
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body application.CreateIndicatorRequest true "Indicator data"
+// @Success 201 {object} domain.Indicator
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/indicators [post]
+func (h *Handler) CreateIndicator(c *gin.Context) {
+	actorID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var req application.CreateIndicatorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	indicator, err := h.indicatorService.CreateIndicator(actorID.(uuid.UUID), req)
+	if err != nil {
+		h.logger.WithError(err).Error("Indicator creation failed")
+		c.JSON(indicatorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"actor_id":     actorID,
+		"indicator_id": indicator.ID,
+		"type":         indicator.Type,
+	}).Info("Indicator created")
+
+	c.JSON(http.StatusCreated, indicator)
+}
+
+// @Summary Update indicator
+// @Description Update confidence, severity, tags, source, last seen or expiry of an indicator (analyst+)
+// @Tags indicators
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Indicator ID"
+// @Param request body application.UpdateIndicatorRequest true "Fields to update"
+// @Success 200 {object} domain.Indicator
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/indicators/{id} [patch]
+func (h *Handler) UpdateIndicator(c *gin.Context) {
+	actorID, id, ok := h.indicatorTarget(c)
+	if !ok {
+		return
+	}
+
+	var req application.UpdateIndicatorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	indicator, err := h.indicatorService.UpdateIndicator(actorID, id, req)
+	if err != nil {
+		h.logger.WithError(err).Error("Indicator update failed")
+		c.JSON(indicatorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, indicator)
+}
+
+// @Summary Delete indicator
+// @Description Delete a threat indicator (analyst+)
+// @Tags indicators
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Indicator ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/indicators/{id} [delete]
+func (h *Handler) DeleteIndicator(c *gin.Context) {
+	actorID, id, ok := h.indicatorTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := h.indicatorService.DeleteIndicator(actorID, id); err != nil {
+		h.logger.WithError(err).Error("Indicator deletion failed")
+		c.JSON(indicatorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"actor_id":     actorID,
+		"indicator_id": id,
+	}).Info("Indicator deleted")
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) indicatorTarget(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	actorID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid indicator ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return actorID.(uuid.UUID), id, true
+}
+
+func indicatorErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, application.ErrIndicatorNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, application.ErrIndicatorExists):
+		return http.StatusConflict
+	case errors.Is(err, application.ErrEntitlementRequired):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrInvalidIndicatorType),
+		errors.Is(err, domain.ErrInvalidIndicatorValue),
+		errors.Is(err, domain.ErrInvalidConfidence),
+		errors.Is(err, domain.ErrInvalidSeverity),
+		errors.Is(err, domain.ErrInvalidVote):
+		return http.StatusBadRequest
+	default:
+		return userErrorStatus(err)
+	}
+}
@@ -0,0 +1,250 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"threat-intel-backend/application"
+	"threat-intel-backend/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockIndicatorService struct {
+	mock.Mock
+}
+
+func (m *MockIndicatorService) CreateIndicator(actorID uuid.UUID, req application.CreateIndicatorRequest) (*domain.Indicator, error) {
+	args := m.Called(actorID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Indicator), args.Error(1)
+}
+
+func (m *MockIndicatorService) GetIndicator(userID, id uuid.UUID) (*domain.Indicator, error) {
+	args := m.Called(userID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Indicator), args.Error(1)
+}
+
+func (m *MockIndicatorService) ListSightings(userID, id uuid.UUID) ([]*domain.Sighting, error) {
+	args := m.Called(userID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Sighting), args.Error(1)
+}
+
+func (m *MockIndicatorService) ListIndicators(userID uuid.UUID, req application.ListIndicatorsRequest) (*application.IndicatorListResponse, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*application.IndicatorListResponse), args.Error(1)
+}
+
+func (m *MockIndicatorService) UpdateIndicator(actorID, id uuid.UUID, req application.UpdateIndicatorRequest) (*domain.Indicator, error) {
+	args := m.Called(actorID, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Indicator), args.Error(1)
+}
+
+func (m *MockIndicatorService) DeleteIndicator(actorID, id uuid.UUID) error {
+	args := m.Called(actorID, id)
+	return args.Error(0)
+}
+
+func setupIndicatorHandler() (*Handler, *MockIndicatorService) {
+	handler, _, _ := setupHandler()
+	mockIndicators := &MockIndicatorService{}
+	return handler.WithIndicatorService(mockIndicators), mockIndicators
+}
+
+func TestListIndicators(t *testing.T) {
+	handler, mockIndicators := setupIndicatorHandler()
+	actorID := uuid.New()
+
+	t.Run("binds query filters", func(t *testing.T) {
+		req := application.ListIndicatorsRequest{Type: domain.IndicatorIPv4, Tag: "botnet", IncludeExpired: true, Page: 3}
+		mockIndicators.On("ListIndicators", actorID, req).Return(&application.IndicatorListResponse{Page: 3}, nil).Once()
+
+		c, w := newAdminContext("GET", "/api/v1/indicators?type=ipv4&tag=botnet&include_expired=true&page=3", nil, actorID, "")
+		handler.ListIndicators(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockIndicators.AssertExpectations(t)
+	})
+
+	t.Run("maps invalid type", func(t *testing.T) {
+		req := application.ListIndicatorsRequest{Type: "mutex"}
+		mockIndicators.On("ListIndicators", actorID, req).Return(nil, domain.ErrInvalidIndicatorType).Once()
+
+		c, w := newAdminContext("GET", "/api/v1/indicators?type=mutex", nil, actorID, "")
+		handler.ListIndicators(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestGetIndicator(t *testing.T) {
+	handler, mockIndicators := setupIndicatorHandler()
+	actorID := uuid.New()
+
+	t.Run("returns indicator", func(t *testing.T) {
+		indicator := &domain.Indicator{ID: uuid.New(), Type: domain.IndicatorDomain, Value: "evil.example.com"}
+		mockIndicators.On("GetIndicator", actorID, indicator.ID).Return(indicator, nil).Once()
+
+		c, w := newAdminContext("GET", "/", nil, actorID, indicator.ID.String())
+		handler.GetIndicator(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		id := uuid.New()
+		mockIndicators.On("GetIndicator", actorID, id).Return(nil, application.ErrIndicatorNotFound).Once()
+
+		c, w := newAdminContext("GET", "/", nil, actorID, id.String())
+		handler.GetIndicator(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("not entitled", func(t *testing.T) {
+		id := uuid.New()
+		mockIndicators.On("GetIndicator", actorID, id).Return(nil, application.ErrEntitlementRequired).Once()
+
+		c, w := newAdminContext("GET", "/", nil, actorID, id.String())
+		handler.GetIndicator(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		c, w := newAdminContext("GET", "/", nil, actorID, "not-a-uuid")
+		handler.GetIndicator(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestListIndicatorSightings(t *testing.T) {
+	handler, mockIndicators := setupIndicatorHandler()
+	actorID := uuid.New()
+	indicatorID := uuid.New()
+
+	t.Run("returns sightings", func(t *testing.T) {
+		sighting := domain.NewSighting(indicatorID, "urlhaus")
+		sighting.RawPayload = "hxxp://evil[.]example[.]com/"
+		mockIndicators.On("ListSightings", actorID, indicatorID).Return([]*domain.Sighting{sighting}, nil).Once()
+
+		c, w := newAdminContext("GET", "/", nil, actorID, indicatorID.String())
+		handler.ListIndicatorSightings(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var sightings []domain.Sighting
+		_ = json.Unmarshal(w.Body.Bytes(), &sightings)
+		assert.Len(t, sightings, 1)
+		assert.Equal(t, "urlhaus", sightings[0].Source)
+		assert.Equal(t, sighting.RawPayload, sightings[0].RawPayload)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		id := uuid.New()
+		mockIndicators.On("ListSightings", actorID, id).Return(nil, application.ErrIndicatorNotFound).Once()
+
+		c, w := newAdminContext("GET", "/", nil, actorID, id.String())
+		handler.ListIndicatorSightings(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		c, w := newAdminContext("GET", "/", nil, actorID, "not-a-uuid")
+		handler.ListIndicatorSightings(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestCreateIndicator(t *testing.T) {
+	handler, mockIndicators := setupIndicatorHandler()
+	actorID := uuid.New()
+
+	t.Run("creates indicator", func(t *testing.T) {
+		req := application.CreateIndicatorRequest{Type: domain.IndicatorURL, Value: "http://evil.example.com", Confidence: 60}
+		indicator := &domain.Indicator{ID: uuid.New(), Type: req.Type, Value: req.Value}
+		mockIndicators.On("CreateIndicator", actorID, req).Return(indicator, nil).Once()
+
+		body, _ := json.Marshal(req)
+		c, w := newAdminContext("POST", "/api/v1/indicators", body, actorID, "")
+		handler.CreateIndicator(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockIndicators.AssertExpectations(t)
+	})
+
+	t.Run("maps validation and conflict errors", func(t *testing.T) {
+		cases := map[error]int{
+			fmt.Errorf("%w: bad", domain.ErrInvalidIndicatorValue): http.StatusBadRequest,
+			application.ErrIndicatorExists:                         http.StatusConflict,
+			application.ErrInsufficientPermissions:                 http.StatusForbidden,
+		}
+		for err, status := range cases {
+			req := application.CreateIndicatorRequest{Type: domain.IndicatorIPv4, Value: "10.0.0.1"}
+			mockIndicators.On("CreateIndicator", actorID, req).Return(nil, err).Once()
+
+			body, _ := json.Marshal(req)
+			c, w := newAdminContext("POST", "/api/v1/indicators", body, actorID, "")
+			handler.CreateIndicator(c)
+
+			assert.Equal(t, status, w.Code)
+		}
+	})
+
+	t.Run("rejects confidence out of range", func(t *testing.T) {
+		c, w := newAdminContext("POST", "/api/v1/indicators", []byte(`{"type":"ipv4","value":"10.0.0.1","confidence":150}`), actorID, "")
+		handler.CreateIndicator(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestUpdateIndicator(t *testing.T) {
+	handler, mockIndicators := setupIndicatorHandler()
+	actorID := uuid.New()
+	id := uuid.New()
+
+	severity := domain.SeverityHigh
+	req := application.UpdateIndicatorRequest{Severity: &severity}
+	mockIndicators.On("UpdateIndicator", actorID, id, req).Return(&domain.Indicator{ID: id, Severity: severity}, nil).Once()
+
+	body, _ := json.Marshal(req)
+	c, w := newAdminContext("PATCH", "/", body, actorID, id.String())
+	handler.UpdateIndicator(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockIndicators.AssertExpectations(t)
+}
+
+func TestDeleteIndicator(t *testing.T) {
+	handler, mockIndicators := setupIndicatorHandler()
+	actorID := uuid.New()
+	id := uuid.New()
+
+	mockIndicators.On("DeleteIndicator", actorID, id).Return(nil).Once()
+
+	c, _ := newAdminContext("DELETE", "/", nil, actorID, id.String())
+	handler.DeleteIndicator(c)
+
+	assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+	mockIndicators.AssertExpectations(t)
+}
@@ -0,0 +1,106 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"threat-intel-backend/application"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type InvitationServiceInterface interface {
+	CreateInvitation(actorID uuid.UUID, req application.CreateInvitationRequest) (*application.InvitationResponse, error)
+	AcceptInvitation(req application.AcceptInvitationRequest) (*application.LoginResponse, error)
+}
+
+func (h *Handler) WithInvitationService(invitationService InvitationServiceInterface) *Handler {
+	h.invitationService = invitationService
+	return h
+}
+
+// @Summary Create invitation
+// @Description Issue a single-use invitation granting a role to a new user (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body application.CreateInvitationRequest true "Invitation data"
+// @Success 201 {object} application.InvitationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/admin/invitations [post]
+func (h *Handler) CreateInvitation(c *gin.Context) {
+	actorID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var req application.CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.invitationService.CreateInvitation(actorID.(uuid.UUID), req)
+	if err != nil {
+		h.logger.WithError(err).Error("Invitation creation failed")
+		c.JSON(invitationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"actor_id": actorID,
+		"email":    response.Email,
+		"role":     response.Role,
+	}).Info("Invitation created")
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// @Summary Accept invitation
+// @Description Create an account from an invitation token and sign in
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body application.AcceptInvitationRequest true "Invitation token and password"
+// @Success 201 {object} application.LoginResponse
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /auth/invitations/accept [post]
+func (h *Handler) AcceptInvitation(c *gin.Context) {
+	var req application.AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.invitationService.AcceptInvitation(req)
+	if err != nil {
+		h.logger.WithError(err).Error("Invitation acceptance failed")
+		c.JSON(invitationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if response.MFAChallenge != nil {
+		h.logger.Info("Invitation accepted, MFA challenge issued")
+		c.JSON(http.StatusCreated, response)
+		return
+	}
+
+	h.logger.WithField("user_id", response.User.ID).Info("Invitation accepted")
+	c.JSON(http.StatusCreated, response)
+}
+
+func invitationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, application.ErrInvalidInvitation), errors.Is(err, application.ErrWeakPassword):
+		return http.StatusBadRequest
+	case errors.Is(err, application.ErrEmailExists):
+		return http.StatusConflict
+	default:
+		return userErrorStatus(err)
+	}
+}
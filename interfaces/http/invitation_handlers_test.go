@@ -0,0 +1,139 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"threat-intel-backend/application"
+	"threat-intel-backend/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockInvitationService struct {
+	mock.Mock
+}
+
+func (m *MockInvitationService) CreateInvitation(actorID uuid.UUID, req application.CreateInvitationRequest) (*application.InvitationResponse, error) {
+	args := m.Called(actorID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*application.InvitationResponse), args.Error(1)
+}
+
+func (m *MockInvitationService) AcceptInvitation(req application.AcceptInvitationRequest) (*application.LoginResponse, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*application.LoginResponse), args.Error(1)
+}
+
+func setupInvitationHandler() (*Handler, *MockInvitationService) {
+	handler, _, _ := setupHandler()
+	mockInvitations := &MockInvitationService{}
+	return handler.WithInvitationService(mockInvitations), mockInvitations
+}
+
+func TestCreateInvitation(t *testing.T) {
+	handler, mockInvitations := setupInvitationHandler()
+	actorID := uuid.New()
+
+	newContext := func(body []byte) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/v1/admin/invitations", bytes.NewBuffer(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set("user_id", actorID)
+		return c, w
+	}
+
+	t.Run("creates invitation", func(t *testing.T) {
+		req := application.CreateInvitationRequest{Email: "analyst@example.com", Role: domain.RoleAnalyst}
+		response := &application.InvitationResponse{Token: "invite", Email: req.Email, Role: req.Role}
+		mockInvitations.On("CreateInvitation", actorID, req).Return(response, nil).Once()
+
+		body, _ := json.Marshal(req)
+		c, w := newContext(body)
+		handler.CreateInvitation(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockInvitations.AssertExpectations(t)
+	})
+
+	t.Run("email already exists", func(t *testing.T) {
+		req := application.CreateInvitationRequest{Email: "taken@example.com", Role: domain.RoleAnalyst}
+		mockInvitations.On("CreateInvitation", actorID, req).Return(nil, application.ErrEmailExists).Once()
+
+		body, _ := json.Marshal(req)
+		c, w := newContext(body)
+		handler.CreateInvitation(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		c, w := newContext([]byte(`{"email":"not-an-email"}`))
+		handler.CreateInvitation(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	handler, mockInvitations := setupInvitationHandler()
+
+	newContext := func(body []byte) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/auth/invitations/accept", bytes.NewBuffer(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		return c, w
+	}
+
+	t.Run("accepts invitation", func(t *testing.T) {
+		req := application.AcceptInvitationRequest{Token: "invite", Password: "password123"}
+		user := &application.UserResponse{ID: uuid.New(), Role: domain.RoleAnalyst}
+		mockInvitations.On("AcceptInvitation", req).Return(&application.LoginResponse{AuthResponse: &application.AuthResponse{AccessToken: "token", User: user}}, nil).Once()
+
+		body, _ := json.Marshal(req)
+		c, w := newContext(body)
+		handler.AcceptInvitation(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockInvitations.AssertExpectations(t)
+	})
+
+	t.Run("answers with an MFA challenge", func(t *testing.T) {
+		req := application.AcceptInvitationRequest{Token: "admin-invite", Password: "password123"}
+		challenge := &application.MFAChallenge{MFARequired: true, EnrollmentRequired: true, MFAToken: "mfa", ExpiresIn: 300}
+		mockInvitations.On("AcceptInvitation", req).Return(&application.LoginResponse{MFAChallenge: challenge}, nil).Once()
+
+		body, _ := json.Marshal(req)
+		c, w := newContext(body)
+		handler.AcceptInvitation(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, true, response["mfa_enrollment_required"])
+		assert.NotContains(t, response, "access_token")
+	})
+
+	t.Run("invalid invitation", func(t *testing.T) {
+		req := application.AcceptInvitationRequest{Token: "used", Password: "password123"}
+		mockInvitations.On("AcceptInvitation", req).Return(nil, application.ErrInvalidInvitation).Once()
+
+		body, _ := json.Marshal(req)
+		c, w := newContext(body)
+		handler.AcceptInvitation(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
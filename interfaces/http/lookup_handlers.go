@@ -0,0 +1,66 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"threat-intel-backend/application"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type LookupServiceInterface interface {
+	Lookup(ctx context.Context, userID uuid.UUID, observables []string) (*application.LookupResponse, error)
+}
+
+func (h *Handler) WithLookupService(lookupService LookupServiceInterface) *Handler {
+	h.lookupService = lookupService
+	return h
+}
+
+// @Summary Bulk lookup
+// @Description Look up a batch of mixed observables in one request, for example to enrich SIEM events. The type of every observable is detected and defanged values are refanged; matches that are known and not expired are returned in submission order with their score, tags and sources. Observables of types outside the caller's entitlement are listed as restricted (viewer+)
+// @Tags indicators
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body application.LookupRequest true "Observables"
+// @Success 200 {object} application.LookupResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/lookup [post]
+func (h *Handler) Lookup(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var req application.LookupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.lookupService.Lookup(c.Request.Context(), userID.(uuid.UUID), req.Observables)
+	if err != nil {
+		c.JSON(lookupErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"user_id":     userID,
+		"observables": response.Total,
+		"matches":     len(response.Matches),
+	}).Debug("Bulk lookup")
+
+	c.JSON(http.StatusOK, response)
+}
+
+func lookupErrorStatus(err error) int {
+	if errors.Is(err, application.ErrTooManyObservables) {
+		return http.StatusBadRequest
+	}
+	return indicatorErrorStatus(err)
+}
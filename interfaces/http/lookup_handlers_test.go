@@ -0,0 +1,95 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"threat-intel-backend/application"
+	"threat-intel-backend/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLookupService struct {
+	mock.Mock
+}
+
+func (m *MockLookupService) Lookup(ctx context.Context, userID uuid.UUID, observables []string) (*application.LookupResponse, error) {
+	args := m.Called(ctx, userID, observables)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*application.LookupResponse), args.Error(1)
+}
+
+func setupLookupHandler() (*Handler, *MockLookupService) {
+	handler, _, _ := setupHandler()
+	mockLookup := &MockLookupService{}
+	return handler.WithLookupService(mockLookup), mockLookup
+}
+
+func TestLookup(t *testing.T) {
+	handler, mockLookup := setupLookupHandler()
+	userID := uuid.New()
+	observables := []string{"evil[.]example[.]com", "198.51.100.7"}
+
+	t.Run("returns matches", func(t *testing.T) {
+		response := &application.LookupResponse{
+			Total: 2,
+			Matches: []application.LookupResult{{
+				Observable:  "evil[.]example[.]com",
+				LookupMatch: domain.LookupMatch{IndicatorID: uuid.New(), Type: domain.IndicatorDomain, Value: "evil.example.com", Score: 62},
+			}},
+			Unrecognized: []string{},
+			Restricted:   []string{},
+		}
+		mockLookup.On("Lookup", mock.Anything, userID, observables).Return(response, nil).Once()
+
+		c, w := newAdminContext("POST", "/api/v1/lookup", []byte(`{"observables":["evil[.]example[.]com","198.51.100.7"]}`), userID, "")
+		handler.Lookup(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var body map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
+		matches := body["matches"].([]interface{})
+		assert.Len(t, matches, 1)
+		match := matches[0].(map[string]interface{})
+		assert.Equal(t, "evil[.]example[.]com", match["observable"])
+		assert.Equal(t, "evil.example.com", match["value"])
+		assert.Equal(t, float64(62), match["score"])
+	})
+
+	t.Run("requires observables", func(t *testing.T) {
+		for _, body := range []string{`{}`, `{"observables":[]}`} {
+			c, w := newAdminContext("POST", "/api/v1/lookup", []byte(body), userID, "")
+			handler.Lookup(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+	})
+
+	t.Run("too many observables", func(t *testing.T) {
+		mockLookup.On("Lookup", mock.Anything, userID, []string{"a.example.com"}).
+			Return(nil, fmt.Errorf("%w: at most 0 per lookup", application.ErrTooManyObservables)).Once()
+
+		c, w := newAdminContext("POST", "/api/v1/lookup", []byte(`{"observables":["a.example.com"]}`), userID, "")
+		handler.Lookup(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("entitlement required", func(t *testing.T) {
+		mockLookup.On("Lookup", mock.Anything, userID, []string{"b.example.com"}).Return(nil, application.ErrEntitlementRequired).Once()
+
+		c, w := newAdminContext("POST", "/api/v1/lookup", []byte(`{"observables":["b.example.com"]}`), userID, "")
+		handler.Lookup(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	mockLookup.AssertExpectations(t)
+}
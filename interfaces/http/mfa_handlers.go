@@ -0,0 +1,372 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"threat-intel-backend/application"
+	"threat-intel-backend/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type MFAServiceInterface interface {
+	Status(userID uuid.UUID) (*application.MFAStatusResponse, error)
+	BeginEnrollment(userID uuid.UUID) (*application.MFAEnrollmentResponse, error)
+	ConfirmEnrollment(userID uuid.UUID, req application.MFACodeRequest) (*application.RecoveryCodesResponse, error)
+	RegenerateRecoveryCodes(userID uuid.UUID, req application.MFACodeRequest) (*application.RecoveryCodesResponse, error)
+	Disable(userID uuid.UUID, req application.MFACodeRequest) error
+	ResetMFA(actorID, userID uuid.UUID) error
+	ListPolicies(actorID uuid.UUID) ([]*domain.MFAPolicy, error)
+	SetPolicy(actorID uuid.UUID, role domain.UserRole, req application.SetMFAPolicyRequest) (*domain.MFAPolicy, error)
+}
+
+func (h *Handler) WithMFAService(mfaService MFAServiceInterface) *Handler {
+	h.mfaService = mfaService
+	return h
+}
+
+// @Summary Verify MFA
+// @Description Complete a login that was answered with an MFA challenge, using a TOTP or recovery code
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body application.VerifyMFARequest true "MFA token and code"
+// @Success 200 {object} application.AuthResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Router /auth/mfa/verify [post]
+func (h *Handler) VerifyMFA(c *gin.Context) {
+	var req application.VerifyMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.ClientIP = c.ClientIP()
+
+	response, err := h.authService.VerifyMFA(req)
+	if err != nil {
+		h.logger.WithError(err).Error("MFA verification failed")
+		loginError(c, err)
+		return
+	}
+
+	h.logger.WithField("user_id", response.User.ID).Info("User logged in")
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Begin required MFA enrollment
+// @Description Start TOTP enrollment for a user whose login was answered with an enrollment challenge
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body application.MFATokenRequest true "MFA token"
+// @Success 200 {object} application.MFAEnrollmentResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /auth/mfa/enroll [post]
+func (h *Handler) BeginMFAEnrollment(c *gin.Context) {
+	var req application.MFATokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.authService.BeginMFAEnrollment(req)
+	if err != nil {
+		loginError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Complete required MFA enrollment
+// @Description Confirm the TOTP factor with a first code, complete the login and return the recovery codes
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body application.VerifyMFARequest true "MFA token and code"
+// @Success 200 {object} application.MFAEnrolledResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Router /auth/mfa/enroll/confirm [post]
+func (h *Handler) CompleteMFAEnrollment(c *gin.Context) {
+	var req application.VerifyMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.ClientIP = c.ClientIP()
+
+	response, err := h.authService.CompleteMFAEnrollment(req)
+	if err != nil {
+		h.logger.WithError(err).Error("MFA enrollment failed")
+		loginError(c, err)
+		return
+	}
+
+	h.logger.WithField("user_id", response.User.ID).Info("MFA enabled")
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Get my MFA status
+// @Description Whether MFA is enabled for the caller and required for their role
+// @Tags mfa
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} application.MFAStatusResponse
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/me/mfa [get]
+func (h *Handler) GetMFAStatus(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	response, err := h.mfaService.Status(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Begin MFA enrollment
+// @Description Generate a TOTP secret and otpauth URI. MFA is enabled once a first code is confirmed.
+// @Tags mfa
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} application.MFAEnrollmentResponse
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/me/mfa/enroll [post]
+func (h *Handler) EnrollMFA(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	response, err := h.mfaService.BeginEnrollment(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Confirm MFA enrollment
+// @Description Enable MFA with a first TOTP code and return the recovery codes
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body application.MFACodeRequest true "TOTP code"
+// @Success 200 {object} application.RecoveryCodesResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/me/mfa/enroll/confirm [post]
+func (h *Handler) ConfirmMFAEnrollment(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var req application.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.mfaService.ConfirmEnrollment(userID.(uuid.UUID), req)
+	if err != nil {
+		c.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithField("user_id", userID).Info("MFA enabled")
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Regenerate recovery codes
+// @Description Replace all recovery codes after checking a TOTP code
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body application.MFACodeRequest true "TOTP code"
+// @Success 200 {object} application.RecoveryCodesResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/me/mfa/recovery-codes [post]
+func (h *Handler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var req application.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.mfaService.RegenerateRecoveryCodes(userID.(uuid.UUID), req)
+	if err != nil {
+		c.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Disable MFA
+// @Description Remove the TOTP factor after checking a TOTP or recovery code. Not allowed when the caller's role requires MFA.
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body application.MFACodeRequest true "TOTP or recovery code"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/me/mfa/disable [post]
+func (h *Handler) DisableMFA(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var req application.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.mfaService.Disable(userID.(uuid.UUID), req); err != nil {
+		c.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithField("user_id", userID).Info("MFA disabled")
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Reset user MFA
+// @Description Remove the TOTP factor of a user who lost their authenticator and recovery codes (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/admin/users/{id}/mfa [delete]
+func (h *Handler) ResetUserMFA(c *gin.Context) {
+	actorID, userID, ok := h.adminTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := h.mfaService.ResetMFA(actorID, userID); err != nil {
+		h.logger.WithError(err).Error("MFA reset failed")
+		c.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"actor_id": actorID,
+		"user_id":  userID,
+	}).Info("User MFA reset")
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary List MFA policies
+// @Description Whether MFA is required for each role (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.MFAPolicy
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/admin/mfa-policies [get]
+func (h *Handler) ListMFAPolicies(c *gin.Context) {
+	actorID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	policies, err := h.mfaService.ListPolicies(actorID.(uuid.UUID))
+	if err != nil {
+		c.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, policies)
+}
+
+// @Summary Set MFA policy
+// @Description Require or stop requiring MFA for every user of a role (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param role path string true "Role"
+// @Param request body application.SetMFAPolicyRequest true "Policy"
+// @Success 200 {object} domain.MFAPolicy
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/admin/mfa-policies/{role} [put]
+func (h *Handler) SetMFAPolicy(c *gin.Context) {
+	actorID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var req application.SetMFAPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role := domain.UserRole(c.Param("role"))
+	policy, err := h.mfaService.SetPolicy(actorID.(uuid.UUID), role, req)
+	if err != nil {
+		c.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"actor_id": actorID,
+		"role":     role,
+		"required": policy.Required,
+	}).Info("MFA policy updated")
+
+	c.JSON(http.StatusOK, policy)
+}
+
+func mfaErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, application.ErrInvalidMFACode), errors.Is(err, application.ErrMFANotEnrolled):
+		return http.StatusBadRequest
+	case errors.Is(err, application.ErrMFAAlreadyEnabled):
+		return http.StatusConflict
+	case errors.Is(err, application.ErrMFARequired):
+		return http.StatusForbidden
+	default:
+		return userErrorStatus(err)
+	}
+}
@@ -0,0 +1,254 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"threat-intel-backend/application"
+	"threat-intel-backend/domain"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMFAService struct {
+	mock.Mock
+}
+
+func (m *MockMFAService) Status(userID uuid.UUID) (*application.MFAStatusResponse, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*application.MFAStatusResponse), args.Error(1)
+}
+
+func (m *MockMFAService) BeginEnrollment(userID uuid.UUID) (*application.MFAEnrollmentResponse, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*application.MFAEnrollmentResponse), args.Error(1)
+}
+
+func (m *MockMFAService) ConfirmEnrollment(userID uuid.UUID, req application.MFACodeRequest) (*application.RecoveryCodesResponse, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*application.RecoveryCodesResponse), args.Error(1)
+}
+
+func (m *MockMFAService) RegenerateRecoveryCodes(userID uuid.UUID, req application.MFACodeRequest) (*application.RecoveryCodesResponse, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*application.RecoveryCodesResponse), args.Error(1)
+}
+
+func (m *MockMFAService) Disable(userID uuid.UUID, req application.MFACodeRequest) error {
+	args := m.Called(userID, req)
+	return args.Error(0)
+}
+
+func (m *MockMFAService) ResetMFA(actorID, userID uuid.UUID) error {
+	args := m.Called(actorID, userID)
+	return args.Error(0)
+}
+
+func (m *MockMFAService) ListPolicies(actorID uuid.UUID) ([]*domain.MFAPolicy, error) {
+	args := m.Called(actorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.MFAPolicy), args.Error(1)
+}
+
+func (m *MockMFAService) SetPolicy(actorID uuid.UUID, role domain.UserRole, req application.SetMFAPolicyRequest) (*domain.MFAPolicy, error) {
+	args := m.Called(actorID, role, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.MFAPolicy), args.Error(1)
+}
+
+func setupMFAHandler() (*Handler, *MockAuthService, *MockMFAService) {
+	handler, mockAuth, _ := setupHandler()
+	mockMFA := &MockMFAService{}
+	handler.WithMFAService(mockMFA)
+	return handler, mockAuth, mockMFA
+}
+
+func newMFARequest(path string, body interface{}) (*gin.Context, *httptest.ResponseRecorder) {
+	payload, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", path, bytes.NewBuffer(payload))
+	c.Request.Header.Set("Content-Type", "application/json")
+	return c, w
+}
+
+func TestVerifyMFA(t *testing.T) {
+	handler, mockAuth, _ := setupMFAHandler()
+	req := application.VerifyMFARequest{MFAToken: "mfa-token", Code: "123456", ClientIP: "192.0.2.1"}
+
+	t.Run("completes login", func(t *testing.T) {
+		user := &application.UserResponse{ID: uuid.New()}
+		mockAuth.On("VerifyMFA", req).Return(&application.AuthResponse{AccessToken: "token", User: user}, nil).Once()
+
+		c, w := newMFARequest("/auth/mfa/verify", req)
+		handler.VerifyMFA(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "access_token")
+	})
+
+	t.Run("wrong code", func(t *testing.T) {
+		mockAuth.On("VerifyMFA", req).Return(nil, application.ErrInvalidMFACode).Once()
+
+		c, w := newMFARequest("/auth/mfa/verify", req)
+		handler.VerifyMFA(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("locked out", func(t *testing.T) {
+		mockAuth.On("VerifyMFA", req).Return(nil, &application.LoginLockedError{RetryAfter: 30 * time.Second}).Once()
+
+		c, w := newMFARequest("/auth/mfa/verify", req)
+		handler.VerifyMFA(c)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "30", w.Header().Get("Retry-After"))
+	})
+
+	t.Run("requires code", func(t *testing.T) {
+		c, w := newMFARequest("/auth/mfa/verify", map[string]string{"mfa_token": "mfa-token"})
+		handler.VerifyMFA(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestCompleteMFAEnrollment(t *testing.T) {
+	handler, mockAuth, _ := setupMFAHandler()
+	req := application.VerifyMFARequest{MFAToken: "mfa-token", Code: "123456", ClientIP: "192.0.2.1"}
+	response := &application.MFAEnrolledResponse{
+		AuthResponse:  &application.AuthResponse{AccessToken: "token", User: &application.UserResponse{ID: uuid.New()}},
+		RecoveryCodes: []string{"abcd-efgh"},
+	}
+	mockAuth.On("CompleteMFAEnrollment", req).Return(response, nil).Once()
+
+	c, w := newMFARequest("/auth/mfa/enroll/confirm", req)
+	handler.CompleteMFAEnrollment(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, "token", resp["access_token"])
+	assert.Equal(t, []interface{}{"abcd-efgh"}, resp["recovery_codes"])
+}
+
+func TestConfirmMFAEnrollment(t *testing.T) {
+	handler, _, mockMFA := setupMFAHandler()
+	userID := uuid.New()
+	req := application.MFACodeRequest{Code: "123456"}
+
+	t.Run("returns recovery codes", func(t *testing.T) {
+		mockMFA.On("ConfirmEnrollment", userID, req).Return(&application.RecoveryCodesResponse{RecoveryCodes: []string{"abcd-efgh"}}, nil).Once()
+
+		c, w := newAdminContext("POST", "/api/v1/me/mfa/enroll/confirm", []byte(`{"code":"123456"}`), userID, "")
+		handler.ConfirmMFAEnrollment(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "abcd-efgh")
+	})
+
+	t.Run("maps errors", func(t *testing.T) {
+		mockMFA.On("ConfirmEnrollment", userID, req).Return(nil, application.ErrMFAAlreadyEnabled).Once()
+
+		c, w := newAdminContext("POST", "/api/v1/me/mfa/enroll/confirm", []byte(`{"code":"123456"}`), userID, "")
+		handler.ConfirmMFAEnrollment(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestDisableMFA(t *testing.T) {
+	handler, _, mockMFA := setupMFAHandler()
+	userID := uuid.New()
+	req := application.MFACodeRequest{Code: "123456"}
+
+	t.Run("disables", func(t *testing.T) {
+		mockMFA.On("Disable", userID, req).Return(nil).Once()
+
+		c, _ := newAdminContext("POST", "/api/v1/me/mfa/disable", []byte(`{"code":"123456"}`), userID, "")
+		handler.DisableMFA(c)
+
+		assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+	})
+
+	t.Run("required by role", func(t *testing.T) {
+		mockMFA.On("Disable", userID, req).Return(application.ErrMFARequired).Once()
+
+		c, w := newAdminContext("POST", "/api/v1/me/mfa/disable", []byte(`{"code":"123456"}`), userID, "")
+		handler.DisableMFA(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestSetMFAPolicy(t *testing.T) {
+	handler, _, mockMFA := setupMFAHandler()
+	actorID := uuid.New()
+	required := true
+	req := application.SetMFAPolicyRequest{Required: &required}
+
+	t.Run("sets policy", func(t *testing.T) {
+		mockMFA.On("SetPolicy", actorID, domain.RoleAdmin, req).Return(&domain.MFAPolicy{Role: domain.RoleAdmin, Required: true}, nil).Once()
+
+		c, w := newAdminContext("PUT", "/api/v1/admin/mfa-policies/admin", []byte(`{"required":true}`), actorID, "")
+		c.Params = gin.Params{{Key: "role", Value: "admin"}}
+		handler.SetMFAPolicy(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockMFA.AssertExpectations(t)
+	})
+
+	t.Run("requires the required field", func(t *testing.T) {
+		c, w := newAdminContext("PUT", "/api/v1/admin/mfa-policies/admin", []byte(`{}`), actorID, "")
+		c.Params = gin.Params{{Key: "role", Value: "admin"}}
+		handler.SetMFAPolicy(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("maps invalid role", func(t *testing.T) {
+		mockMFA.On("SetPolicy", actorID, domain.UserRole("root"), req).Return(nil, application.ErrInvalidRole).Once()
+
+		c, w := newAdminContext("PUT", "/api/v1/admin/mfa-policies/root", []byte(`{"required":true}`), actorID, "")
+		c.Params = gin.Params{{Key: "role", Value: "root"}}
+		handler.SetMFAPolicy(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestResetUserMFA(t *testing.T) {
+	handler, _, mockMFA := setupMFAHandler()
+	actorID := uuid.New()
+	userID := uuid.New()
+	mockMFA.On("ResetMFA", actorID, userID).Return(nil).Once()
+
+	c, _ := newAdminContext("DELETE", "/api/v1/admin/users/"+userID.String()+"/mfa", nil, actorID, userID.String())
+	handler.ResetUserMFA(c)
+
+	assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+	mockMFA.AssertExpectations(t)
+}
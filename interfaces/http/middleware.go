@@ -1,7 +1,11 @@
 package http
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 	"threat-intel-backend/domain"
@@ -11,34 +15,71 @@ import (
 	"github.com/newrelic/go-agent/v3/integrations/nrgin"
 	"github.com/newrelic/go-agent/v3/newrelic"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/time/rate"
+)
+
+// Route groups with their own rate limit budget.
+const (
+	RateLimitAuth   = "auth"
+	RateLimitPublic = "public"
+	RateLimitAPI    = "api"
+	RateLimitTAXII  = "taxii"
 )
 
 type JWTServiceInterface interface {
 	ValidateAccessToken(token string) (*jwt.Claims, error)
 	GenerateAccessToken(userID uuid.UUID, role domain.UserRole) (string, error)
-	GenerateRefreshToken(userID uuid.UUID) (string, error)
-	ValidateRefreshToken(token string) (uuid.UUID, error)
+	GenerateRefreshToken(userID, familyID uuid.UUID) (string, *jwt.RefreshClaims, error)
+	ValidateRefreshToken(token string) (*jwt.RefreshClaims, error)
+}
+
+// APIKeyAuthenticator resolves the key of an X-API-Key header to its owner.
+type APIKeyAuthenticator interface {
+	Authenticate(rawKey string) (*domain.User, *domain.APIKey, error)
+}
+
+// RateLimitPolicy is the budget of a route group. Authenticated requests are
+// counted per user against the limit of their role, falling back to
+// Anonymous; other requests are counted per client IP against Anonymous.
+type RateLimitPolicy struct {
+	Anonymous domain.RateLimit
+	Roles     map[domain.UserRole]domain.RateLimit
 }
 
 type Middleware struct {
-	jwtService JWTServiceInterface
-	logger     *logrus.Logger
+	jwtService  JWTServiceInterface
+	denylist    domain.AccessTokenDenylist
+	rateLimiter domain.RateLimiter
+	rateLimits  map[string]RateLimitPolicy
+	apiKeys     APIKeyAuthenticator
+	logger      *logrus.Logger
 }
 
-func NewMiddleware(jwtService JWTServiceInterface, logger *logrus.Logger) *Middleware {
+func NewMiddleware(jwtService JWTServiceInterface, denylist domain.AccessTokenDenylist, logger *logrus.Logger) *Middleware {
 	return &Middleware{
 		jwtService: jwtService,
+		denylist:   denylist,
 		logger:     logger,
 	}
 }
 
+func (m *Middleware) WithRateLimiter(limiter domain.RateLimiter, policies map[string]RateLimitPolicy) *Middleware {
+	m.rateLimiter = limiter
+	m.rateLimits = policies
+	return m
+}
+
+// WithAPIKeys lets Auth accept an X-API-Key header instead of a bearer token.
+func (m *Middleware) WithAPIKeys(apiKeys APIKeyAuthenticator) *Middleware {
+	m.apiKeys = apiKeys
+	return m
+}
+
 func (m *Middleware) CORS() gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Header("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-API-Key, accept, origin, Cache-Control, X-Requested-With")
+		c.Header("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, PATCH, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -76,8 +117,16 @@ func (m *Middleware) Logger() gin.HandlerFunc {
 	})
 }
 
+// Auth authenticates the request with a bearer access token or, when API keys
+// are enabled, an X-API-Key header. Either way the user_id and user_role of
+// the caller are put into the context; API keys additionally set api_key.
 func (m *Middleware) Auth() gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
+		if rawKey := c.GetHeader("X-API-Key"); rawKey != "" && m.apiKeys != nil {
+			m.authenticateAPIKey(c, rawKey)
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
@@ -94,13 +143,103 @@ func (m *Middleware) Auth() gin.HandlerFunc {
 
 		claims, err := m.jwtService.ValidateAccessToken(tokenString)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			message := tokenErrorMessage(err)
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token", error_description="`+message+`"`)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": message})
+			c.Abort()
+			return
+		}
+
+		var issuedAt time.Time
+		if claims.IssuedAt != nil {
+			issuedAt = claims.IssuedAt.Time
+		}
+
+		revoked, err := m.denylist.IsRevoked(claims.ID, claims.UserID, issuedAt)
+		if err != nil {
+			m.logger.WithError(err).Error("Failed to check token revocation")
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Unable to verify token"})
+			c.Abort()
+			return
+		}
+		if revoked {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
 			c.Abort()
 			return
 		}
 
 		c.Set("user_id", claims.UserID)
 		c.Set("user_role", claims.Role)
+		c.Set("token_id", claims.ID)
+		if claims.ExpiresAt != nil {
+			c.Set("token_expires_at", claims.ExpiresAt.Time)
+		}
+		c.Next()
+	})
+}
+
+// tokenValidationErrors are the reasons for rejecting a token that clients
+// are told about, e.g. so that they refresh an expired token rather than
+// asking the user to log in again.
+var tokenValidationErrors = []error{
+	jwt.ErrTokenMalformed,
+	jwt.ErrTokenSignatureInvalid,
+	jwt.ErrTokenExpired,
+	jwt.ErrTokenNotValidYet,
+	jwt.ErrTokenInvalidIssuer,
+	jwt.ErrTokenInvalidAudience,
+	jwt.ErrTokenWrongType,
+	jwt.ErrTokenInvalidClaims,
+}
+
+func tokenErrorMessage(err error) string {
+	for _, known := range tokenValidationErrors {
+		if errors.Is(err, known) {
+			return known.Error()
+		}
+	}
+	return "Invalid token"
+}
+
+func (m *Middleware) authenticateAPIKey(c *gin.Context, rawKey string) {
+	user, key, err := m.apiKeys.Authenticate(rawKey)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+		c.Abort()
+		return
+	}
+
+	c.Set("user_id", user.ID)
+	c.Set("user_role", user.Role)
+	c.Set("api_key", key)
+	c.Next()
+}
+
+// RequireScope limits requests authenticated with an API key to keys holding
+// the scope. Requests with an access token are not limited by scopes.
+func (m *Middleware) RequireScope(scope domain.APIKeyScope) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		key, isAPIKey := c.Get("api_key")
+		if isAPIKey && !key.(*domain.APIKey).HasScope(scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API key lacks the " + string(scope) + " scope"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	})
+}
+
+// RequireSession rejects requests authenticated with an API key, for routes
+// that only an interactive user may call.
+func (m *Middleware) RequireSession() gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		if _, isAPIKey := c.Get("api_key"); isAPIKey {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API keys cannot access this endpoint"})
+			c.Abort()
+			return
+		}
+
 		c.Next()
 	})
 }
@@ -131,11 +270,38 @@ func (m *Middleware) RequireRole(requiredRole domain.UserRole) gin.HandlerFunc {
 	})
 }
 
-func (m *Middleware) RateLimit() gin.HandlerFunc {
-	limiter := rate.NewLimiter(rate.Every(time.Minute), 60)
-	
+// RateLimit enforces the policy of the given route group and reports the
+// remaining budget in RateLimit-* headers. It must run after Auth for
+// requests to be counted per user. Groups without a policy are not limited,
+// and requests are let through when the limiter is unavailable.
+func (m *Middleware) RateLimit(group string) gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
-		if !limiter.Allow() {
+		policy, ok := m.rateLimits[group]
+		if m.rateLimiter == nil || !ok {
+			c.Next()
+			return
+		}
+
+		key, limit := policy.limitFor(group, c)
+		if limit.Limit <= 0 {
+			c.Next()
+			return
+		}
+
+		result, err := m.rateLimiter.Allow(key, limit)
+		if err != nil {
+			m.logger.WithError(err).Warn("Rate limiter unavailable")
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Limit, int(limit.Period.Seconds())))
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
 			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded"})
 			c.Abort()
 			return
@@ -144,6 +310,25 @@ func (m *Middleware) RateLimit() gin.HandlerFunc {
 	})
 }
 
+func (p RateLimitPolicy) limitFor(group string, c *gin.Context) (string, domain.RateLimit) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		return group + ":ip:" + c.ClientIP(), p.Anonymous
+	}
+
+	key := group + ":user:" + userID.(uuid.UUID).String()
+	if role, ok := c.Get("user_role"); ok {
+		if limit, ok := p.Roles[role.(domain.UserRole)]; ok {
+			return key, limit
+		}
+	}
+	return key, p.Anonymous
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
 func (m *Middleware) NewRelic(app *newrelic.Application) gin.HandlerFunc {
 	return nrgin.Middleware(app)
 }
\ No newline at end of file
@@ -7,8 +7,10 @@ import (
 	"testing"
 	"threat-intel-backend/domain"
 	"threat-intel-backend/infrastructure/jwt"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	jwtlib "github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -32,23 +34,55 @@ func (m *MockJWTService) GenerateAccessToken(userID uuid.UUID, role domain.UserR
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockJWTService) GenerateRefreshToken(userID uuid.UUID) (string, error) {
-	args := m.Called(userID)
-	return args.String(0), args.Error(1)
+func (m *MockJWTService) GenerateRefreshToken(userID, familyID uuid.UUID) (string, *jwt.RefreshClaims, error) {
+	args := m.Called(userID, familyID)
+	if args.Get(1) == nil {
+		return args.String(0), nil, args.Error(2)
+	}
+	return args.String(0), args.Get(1).(*jwt.RefreshClaims), args.Error(2)
 }
 
-func (m *MockJWTService) ValidateRefreshToken(token string) (uuid.UUID, error) {
+func (m *MockJWTService) ValidateRefreshToken(token string) (*jwt.RefreshClaims, error) {
 	args := m.Called(token)
-	return args.Get(0).(uuid.UUID), args.Error(1)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*jwt.RefreshClaims), args.Error(1)
+}
+
+type MockAccessTokenDenylist struct {
+	mock.Mock
+}
+
+func (m *MockAccessTokenDenylist) RevokeToken(tokenID string, expiresAt time.Time) error {
+	args := m.Called(tokenID, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockAccessTokenDenylist) RevokeUserTokens(userID uuid.UUID, issuedBefore time.Time, ttl time.Duration) error {
+	args := m.Called(userID, issuedBefore, ttl)
+	return args.Error(0)
+}
+
+func (m *MockAccessTokenDenylist) IsRevoked(tokenID string, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	args := m.Called(tokenID, userID, issuedAt)
+	return args.Bool(0), args.Error(1)
 }
 
 func setupMiddleware() (*Middleware, *MockJWTService) {
+	middleware, mockJWT, _ := setupMiddlewareWithDenylist()
+	return middleware, mockJWT
+}
+
+func setupMiddlewareWithDenylist() (*Middleware, *MockJWTService, *MockAccessTokenDenylist) {
 	mockJWT := &MockJWTService{}
+	mockDenylist := &MockAccessTokenDenylist{}
+	mockDenylist.On("IsRevoked", mock.Anything, mock.Anything, mock.Anything).Return(false, nil).Maybe()
 	logger := logrus.New()
 	logger.SetLevel(logrus.FatalLevel)
 
-	middleware := NewMiddleware(mockJWT, logger)
-	return middleware, mockJWT
+	middleware := NewMiddleware(mockJWT, mockDenylist, logger)
+	return middleware, mockJWT, mockDenylist
 }
 
 func TestCORS(t *testing.T) {
@@ -64,6 +98,8 @@ func TestCORS(t *testing.T) {
 		assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
 		assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
 		assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+		assert.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), "PATCH")
+		assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "X-API-Key")
 		assert.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), "POST")
 	})
 
@@ -167,6 +203,214 @@ func TestAuth(t *testing.T) {
 	})
 }
 
+func TestAuthTokenErrors(t *testing.T) {
+	middleware, mockJWT := setupMiddleware()
+
+	tests := []struct {
+		name    string
+		err     error
+		message string
+	}{
+		{"expired", jwt.ErrTokenExpired, "token has expired"},
+		{"wrong type", jwt.ErrTokenWrongType, "token has the wrong type"},
+		{"unexpected error", errors.New("boom"), "Invalid token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockJWT.On("ValidateAccessToken", tt.name).Return(nil, tt.err).Once()
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/test", nil)
+			c.Request.Header.Set("Authorization", "Bearer "+tt.name)
+
+			middleware.Auth()(c)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.JSONEq(t, `{"error":"`+tt.message+`"}`, w.Body.String())
+			assert.Equal(t, `Bearer error="invalid_token", error_description="`+tt.message+`"`, w.Header().Get("WWW-Authenticate"))
+		})
+	}
+}
+
+func TestAuthRevocation(t *testing.T) {
+	userID := uuid.New()
+	issuedAt := time.Now().Truncate(time.Second)
+	claims := &jwt.Claims{
+		UserID: userID,
+		Role:   domain.RoleViewer,
+		RegisteredClaims: jwtlib.RegisteredClaims{
+			ID:        "jti-1",
+			IssuedAt:  jwtlib.NewNumericDate(issuedAt),
+			ExpiresAt: jwtlib.NewNumericDate(issuedAt.Add(time.Minute)),
+		},
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	newRequest := func(middleware *Middleware) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		_, engine := gin.CreateTestContext(w)
+		engine.Use(middleware.Auth())
+		engine.GET("/test", func(c *gin.Context) {
+			assert.Equal(t, "jti-1", c.GetString("token_id"))
+			assert.Equal(t, issuedAt.Add(time.Minute), c.GetTime("token_expires_at"))
+			c.Status(http.StatusOK)
+		})
+
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("Authorization", "Bearer token")
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("not revoked", func(t *testing.T) {
+		mockJWT := &MockJWTService{}
+		mockDenylist := &MockAccessTokenDenylist{}
+		middleware := NewMiddleware(mockJWT, mockDenylist, logger)
+		mockJWT.On("ValidateAccessToken", "token").Return(claims, nil)
+		mockDenylist.On("IsRevoked", "jti-1", userID, issuedAt).Return(false, nil)
+
+		w := newRequest(middleware)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockDenylist.AssertExpectations(t)
+	})
+
+	t.Run("revoked", func(t *testing.T) {
+		mockJWT := &MockJWTService{}
+		mockDenylist := &MockAccessTokenDenylist{}
+		middleware := NewMiddleware(mockJWT, mockDenylist, logger)
+		mockJWT.On("ValidateAccessToken", "token").Return(claims, nil)
+		mockDenylist.On("IsRevoked", "jti-1", userID, issuedAt).Return(true, nil)
+
+		w := newRequest(middleware)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("denylist unavailable", func(t *testing.T) {
+		mockJWT := &MockJWTService{}
+		mockDenylist := &MockAccessTokenDenylist{}
+		middleware := NewMiddleware(mockJWT, mockDenylist, logger)
+		mockJWT.On("ValidateAccessToken", "token").Return(claims, nil)
+		mockDenylist.On("IsRevoked", "jti-1", userID, issuedAt).Return(false, errors.New("redis down"))
+
+		w := newRequest(middleware)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
+
+type MockAPIKeyAuthenticator struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyAuthenticator) Authenticate(rawKey string) (*domain.User, *domain.APIKey, error) {
+	args := m.Called(rawKey)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*domain.User), args.Get(1).(*domain.APIKey), args.Error(2)
+}
+
+func TestAuthAPIKey(t *testing.T) {
+	middleware, _ := setupMiddleware()
+	mockAPIKeys := &MockAPIKeyAuthenticator{}
+	middleware.WithAPIKeys(mockAPIKeys)
+
+	user := &domain.User{ID: uuid.New(), Role: domain.RoleAnalyst}
+	key := domain.NewAPIKey(user.ID, "SIEM", "a1b2c3d4e5f6", "hash", []domain.APIKeyScope{domain.ScopeIntelRead}, nil)
+
+	t.Run("valid key", func(t *testing.T) {
+		mockAPIKeys.On("Authenticate", "tik_valid").Return(user, key, nil).Once()
+
+		w := httptest.NewRecorder()
+		_, engine := gin.CreateTestContext(w)
+		engine.Use(middleware.Auth())
+		engine.GET("/test", func(c *gin.Context) {
+			assert.Equal(t, user.ID, c.MustGet("user_id"))
+			assert.Equal(t, domain.RoleAnalyst, c.MustGet("user_role"))
+			assert.Equal(t, key, c.MustGet("api_key"))
+			c.Status(http.StatusOK)
+		})
+
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("X-API-Key", "tik_valid")
+		engine.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		mockAPIKeys.On("Authenticate", "tik_invalid").Return(nil, nil, errors.New("invalid or expired API key")).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/test", nil)
+		c.Request.Header.Set("X-API-Key", "tik_invalid")
+
+		middleware.Auth()(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.True(t, c.IsAborted())
+	})
+}
+
+func TestRequireScope(t *testing.T) {
+	middleware, _ := setupMiddleware()
+	key := domain.NewAPIKey(uuid.New(), "SIEM", "a1b2c3d4e5f6", "hash", []domain.APIKeyScope{domain.ScopeIntelRead}, nil)
+
+	t.Run("key with scope", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/test", nil)
+		c.Set("api_key", key)
+
+		middleware.RequireScope(domain.ScopeIntelRead)(c)
+
+		assert.False(t, c.IsAborted())
+	})
+
+	t.Run("key without scope", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/test", nil)
+		c.Set("api_key", key)
+
+		middleware.RequireScope(domain.ScopeIntelWrite)(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.True(t, c.IsAborted())
+	})
+
+	t.Run("access token", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/test", nil)
+
+		middleware.RequireScope(domain.ScopeIntelWrite)(c)
+
+		assert.False(t, c.IsAborted())
+	})
+}
+
+func TestRequireSession(t *testing.T) {
+	middleware, _ := setupMiddleware()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/test", nil)
+	c.Set("api_key", &domain.APIKey{})
+
+	middleware.RequireSession()(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.True(t, c.IsAborted())
+}
+
 func TestRequireRole(t *testing.T) {
 	middleware, _ := setupMiddleware()
 
@@ -222,19 +466,123 @@ func TestRequireRole(t *testing.T) {
 	})
 }
 
+type MockRateLimiter struct {
+	mock.Mock
+}
+
+func (m *MockRateLimiter) Allow(key string, limit domain.RateLimit) (*domain.RateLimitResult, error) {
+	args := m.Called(key, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RateLimitResult), args.Error(1)
+}
+
 func TestRateLimit(t *testing.T) {
-	middleware, _ := setupMiddleware()
+	anonymous := domain.RateLimit{Limit: 10, Period: time.Minute}
+	analyst := domain.RateLimit{Limit: 300, Period: time.Minute}
+	policies := map[string]RateLimitPolicy{
+		RateLimitAPI: {
+			Anonymous: anonymous,
+			Roles:     map[domain.UserRole]domain.RateLimit{domain.RoleAnalyst: analyst},
+		},
+	}
 
-	t.Run("allows requests within limit", func(t *testing.T) {
-		w := httptest.NewRecorder()
-		c, engine := gin.CreateTestContext(w)
-		c.Request = httptest.NewRequest("GET", "/test", nil)
+	serve := func(limiter *MockRateLimiter, group string, setUser func(c *gin.Context)) *httptest.ResponseRecorder {
+		middleware, _ := setupMiddleware()
+		middleware.WithRateLimiter(limiter, policies)
 
-		engine.Use(middleware.RateLimit())
+		w := httptest.NewRecorder()
+		_, engine := gin.CreateTestContext(w)
+		engine.Use(func(c *gin.Context) {
+			if setUser != nil {
+				setUser(c)
+			}
+		}, middleware.RateLimit(group))
 		engine.GET("/test", func(c *gin.Context) {
 			c.Status(200)
 		})
 
-		engine.ServeHTTP(w, c.Request)
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.RemoteAddr = "203.0.113.7:4711"
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("counts anonymous requests per IP", func(t *testing.T) {
+		limiter := &MockRateLimiter{}
+		limiter.On("Allow", "api:ip:203.0.113.7", anonymous).
+			Return(&domain.RateLimitResult{Allowed: true, Limit: 10, Remaining: 9, ResetAfter: 5500 * time.Millisecond}, nil).Once()
+
+		w := serve(limiter, RateLimitAPI, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "10", w.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "9", w.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "6", w.Header().Get("RateLimit-Reset"))
+		assert.Equal(t, "10;w=60", w.Header().Get("RateLimit-Policy"))
+		assert.Empty(t, w.Header().Get("Retry-After"))
+		limiter.AssertExpectations(t)
+	})
+
+	t.Run("counts authenticated requests per user and role", func(t *testing.T) {
+		userID := uuid.New()
+		limiter := &MockRateLimiter{}
+		limiter.On("Allow", "api:user:"+userID.String(), analyst).
+			Return(&domain.RateLimitResult{Allowed: true, Limit: 300, Remaining: 299}, nil).Once()
+
+		w := serve(limiter, RateLimitAPI, func(c *gin.Context) {
+			c.Set("user_id", userID)
+			c.Set("user_role", domain.RoleAnalyst)
+		})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		limiter.AssertExpectations(t)
+	})
+
+	t.Run("roles without a limit fall back to anonymous", func(t *testing.T) {
+		userID := uuid.New()
+		limiter := &MockRateLimiter{}
+		limiter.On("Allow", "api:user:"+userID.String(), anonymous).
+			Return(&domain.RateLimitResult{Allowed: true, Limit: 10, Remaining: 9}, nil).Once()
+
+		w := serve(limiter, RateLimitAPI, func(c *gin.Context) {
+			c.Set("user_id", userID)
+			c.Set("user_role", domain.RoleViewer)
+		})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		limiter.AssertExpectations(t)
+	})
+
+	t.Run("rejects requests over the limit", func(t *testing.T) {
+		limiter := &MockRateLimiter{}
+		limiter.On("Allow", "api:ip:203.0.113.7", anonymous).
+			Return(&domain.RateLimitResult{Limit: 10, ResetAfter: time.Minute, RetryAfter: 2100 * time.Millisecond}, nil).Once()
+
+		w := serve(limiter, RateLimitAPI, nil)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "3", w.Header().Get("Retry-After"))
+		assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+		assert.Contains(t, w.Body.String(), "Rate limit exceeded")
+	})
+
+	t.Run("lets requests through when the limiter fails", func(t *testing.T) {
+		limiter := &MockRateLimiter{}
+		limiter.On("Allow", mock.Anything, mock.Anything).Return(nil, errors.New("connection refused")).Once()
+
+		w := serve(limiter, RateLimitAPI, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("groups without a policy are not limited", func(t *testing.T) {
+		limiter := &MockRateLimiter{}
+
+		w := serve(limiter, RateLimitTAXII, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		limiter.AssertNotCalled(t, "Allow", mock.Anything, mock.Anything)
 	})
 }
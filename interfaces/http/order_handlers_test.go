@@ -0,0 +1,96 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+	"threat-intel-backend/application"
+	"threat-intel-backend/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCancelOrder(t *testing.T) {
+	handler, _, mockOrder := setupHandler()
+	userID := uuid.New()
+
+	t.Run("cancels order", func(t *testing.T) {
+		order := &domain.Order{ID: uuid.New(), UserID: userID, Status: domain.OrderStatusCancelled}
+		mockOrder.On("CancelOrder", order.ID, userID).Return(order, nil).Once()
+
+		c, w := newAdminContext("POST", "/", nil, userID, order.ID.String())
+		handler.CancelOrder(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"cancelled"`)
+	})
+
+	t.Run("illegal transition", func(t *testing.T) {
+		id := uuid.New()
+		mockOrder.On("CancelOrder", id, userID).Return(nil, &domain.OrderTransitionError{
+			From: domain.OrderStatusCompleted,
+			To:   domain.OrderStatusCancelled,
+		}).Once()
+
+		c, w := newAdminContext("POST", "/", nil, userID, id.String())
+		handler.CancelOrder(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "cannot move order from completed to cancelled")
+	})
+
+	t.Run("other user's order", func(t *testing.T) {
+		id := uuid.New()
+		mockOrder.On("CancelOrder", id, userID).Return(nil, application.ErrOrderAccessDenied).Once()
+
+		c, w := newAdminContext("POST", "/", nil, userID, id.String())
+		handler.CancelOrder(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		c, w := newAdminContext("POST", "/", nil, userID, "not-a-uuid")
+		handler.CancelOrder(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestCompleteOrder(t *testing.T) {
+	handler, _, mockOrder := setupHandler()
+	actorID := uuid.New()
+
+	t.Run("completes order", func(t *testing.T) {
+		order := &domain.Order{ID: uuid.New(), Status: domain.OrderStatusCompleted}
+		mockOrder.On("CompleteOrder", actorID, order.ID).Return(order, nil).Once()
+
+		c, w := newAdminContext("POST", "/", nil, actorID, order.ID.String())
+		handler.CompleteOrder(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("illegal transition", func(t *testing.T) {
+		id := uuid.New()
+		mockOrder.On("CompleteOrder", actorID, id).Return(nil, &domain.OrderTransitionError{
+			From: domain.OrderStatusCancelled,
+			To:   domain.OrderStatusCompleted,
+		}).Once()
+
+		c, w := newAdminContext("POST", "/", nil, actorID, id.String())
+		handler.CompleteOrder(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("order not found", func(t *testing.T) {
+		id := uuid.New()
+		mockOrder.On("CompleteOrder", actorID, id).Return(nil, application.ErrOrderNotFound).Once()
+
+		c, w := newAdminContext("POST", "/", nil, actorID, id.String())
+		handler.CompleteOrder(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,221 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"threat-intel-backend/application"
+	"threat-intel-backend/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type ProductServiceInterface interface {
+	ListCatalog() ([]*domain.Product, error)
+	ListProducts(actorID uuid.UUID) ([]*domain.Product, error)
+	GetProduct(actorID uuid.UUID, id string) (*domain.Product, error)
+	CreateProduct(actorID uuid.UUID, req application.CreateProductRequest) (*domain.Product, error)
+	UpdateProduct(actorID uuid.UUID, id string, req application.UpdateProductRequest) (*domain.Product, error)
+	DeleteProduct(actorID uuid.UUID, id string) error
+}
+
+func (h *Handler) WithProductService(productService ProductServiceInterface) *Handler {
+	h.productService = productService
+	return h
+}
+
+// @Summary Get catalog
+// @Description List the products that can be ordered
+// @Tags catalog
+// @Produce json
+// @Success 200 {array} domain.Product
+// @Router /api/v1/catalog [get]
+func (h *Handler) GetCatalog(c *gin.Context) {
+	products, err := h.productService.ListCatalog()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, products)
+}
+
+// @Summary List products
+// @Description List every product, including inactive ones (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.Product
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/admin/products [get]
+func (h *Handler) ListProducts(c *gin.Context) {
+	actorID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	products, err := h.productService.ListProducts(actorID.(uuid.UUID))
+	if err != nil {
+		c.JSON(productErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, products)
+}
+
+// @Summary Get product
+// @Description Get a product by ID (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Success 200 {object} domain.Product
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/admin/products/{id} [get]
+func (h *Handler) GetProduct(c *gin.Context) {
+	actorID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	product, err := h.productService.GetProduct(actorID.(uuid.UUID), c.Param("id"))
+	if err != nil {
+		c.JSON(productErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// @Summary Create product
+// @Description Add a product to the catalog; prices are in the currency's minor unit (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body application.CreateProductRequest true "Product data"
+// @Success 201 {object} domain.Product
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/admin/products [post]
+func (h *Handler) CreateProduct(c *gin.Context) {
+	actorID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var req application.CreateProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	product, err := h.productService.CreateProduct(actorID.(uuid.UUID), req)
+	if err != nil {
+		h.logger.WithError(err).Error("Product creation failed")
+		c.JSON(productErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"actor_id":   actorID,
+		"product_id": product.ID,
+	}).Info("Product created")
+
+	c.JSON(http.StatusCreated, product)
+}
+
+// @Summary Update product
+// @Description Update name, description, price, currency, tier, max quantity or availability of a product (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param request body application.UpdateProductRequest true "Fields to update"
+// @Success 200 {object} domain.Product
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/admin/products/{id} [patch]
+func (h *Handler) UpdateProduct(c *gin.Context) {
+	actorID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var req application.UpdateProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	product, err := h.productService.UpdateProduct(actorID.(uuid.UUID), c.Param("id"), req)
+	if err != nil {
+		h.logger.WithError(err).Error("Product update failed")
+		c.JSON(productErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"actor_id":   actorID,
+		"product_id": product.ID,
+	}).Info("Product updated")
+
+	c.JSON(http.StatusOK, product)
+}
+
+// @Summary Delete product
+// @Description Remove a product from the catalog; past orders keep their price snapshot (admin only)
+// @Tags admin
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/admin/products/{id} [delete]
+func (h *Handler) DeleteProduct(c *gin.Context) {
+	actorID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	id := c.Param("id")
+	if err := h.productService.DeleteProduct(actorID.(uuid.UUID), id); err != nil {
+		h.logger.WithError(err).Error("Product deletion failed")
+		c.JSON(productErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"actor_id":   actorID,
+		"product_id": id,
+	}).Info("Product deleted")
+
+	c.Status(http.StatusNoContent)
+}
+
+func productErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, application.ErrProductNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, application.ErrProductExists):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrInvalidProductID),
+		errors.Is(err, domain.ErrInvalidProductName),
+		errors.Is(err, domain.ErrInvalidPrice),
+		errors.Is(err, domain.ErrInvalidCurrency),
+		errors.Is(err, domain.ErrInvalidTier),
+		errors.Is(err, domain.ErrInvalidMaxQuantity):
+		return http.StatusBadRequest
+	default:
+		return userErrorStatus(err)
+	}
+}
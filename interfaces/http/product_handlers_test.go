@@ -0,0 +1,159 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"threat-intel-backend/application"
+	"threat-intel-backend/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockProductService struct {
+	mock.Mock
+}
+
+func (m *MockProductService) ListCatalog() ([]*domain.Product, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Product), args.Error(1)
+}
+
+func (m *MockProductService) ListProducts(actorID uuid.UUID) ([]*domain.Product, error) {
+	args := m.Called(actorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Product), args.Error(1)
+}
+
+func (m *MockProductService) GetProduct(actorID uuid.UUID, id string) (*domain.Product, error) {
+	args := m.Called(actorID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Product), args.Error(1)
+}
+
+func (m *MockProductService) CreateProduct(actorID uuid.UUID, req application.CreateProductRequest) (*domain.Product, error) {
+	args := m.Called(actorID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Product), args.Error(1)
+}
+
+func (m *MockProductService) UpdateProduct(actorID uuid.UUID, id string, req application.UpdateProductRequest) (*domain.Product, error) {
+	args := m.Called(actorID, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Product), args.Error(1)
+}
+
+func (m *MockProductService) DeleteProduct(actorID uuid.UUID, id string) error {
+	args := m.Called(actorID, id)
+	return args.Error(0)
+}
+
+func setupProductHandler() (*Handler, *MockProductService) {
+	handler, _, _ := setupHandler()
+	mockProducts := &MockProductService{}
+	return handler.WithProductService(mockProducts), mockProducts
+}
+
+func TestGetCatalog(t *testing.T) {
+	handler, mockProducts := setupProductHandler()
+	mockProducts.On("ListCatalog").Return(domain.DefaultProducts(), nil).Once()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/catalog", nil)
+	handler.GetCatalog(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var products []domain.Product
+	_ = json.Unmarshal(w.Body.Bytes(), &products)
+	assert.Len(t, products, 3)
+	assert.Equal(t, "intel-basic", products[0].ID)
+}
+
+func TestCreateProduct(t *testing.T) {
+	handler, mockProducts := setupProductHandler()
+	actorID := uuid.New()
+
+	t.Run("creates product", func(t *testing.T) {
+		req := application.CreateProductRequest{ID: "intel-gov", Name: "Intel Government", Price: 100, Currency: "USD", Tier: domain.TierEnterprise, MaxQuantity: 5}
+		product, _ := domain.NewProduct(req.ID, req.Name, req.Price, req.Currency, req.Tier, req.MaxQuantity)
+		mockProducts.On("CreateProduct", actorID, req).Return(product, nil).Once()
+
+		body, _ := json.Marshal(req)
+		c, w := newAdminContext("POST", "/api/v1/admin/products", body, actorID, "")
+		handler.CreateProduct(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockProducts.AssertExpectations(t)
+	})
+
+	t.Run("maps duplicate", func(t *testing.T) {
+		req := application.CreateProductRequest{ID: "intel-basic", Name: "Intel Basic", Currency: "USD", Tier: domain.TierBasic, MaxQuantity: 1}
+		mockProducts.On("CreateProduct", actorID, req).Return(nil, application.ErrProductExists).Once()
+
+		body, _ := json.Marshal(req)
+		c, w := newAdminContext("POST", "/api/v1/admin/products", body, actorID, "")
+		handler.CreateProduct(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("rejects missing fields", func(t *testing.T) {
+		c, w := newAdminContext("POST", "/api/v1/admin/products", []byte(`{"id":"intel-x"}`), actorID, "")
+		handler.CreateProduct(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestUpdateProduct(t *testing.T) {
+	handler, mockProducts := setupProductHandler()
+	actorID := uuid.New()
+
+	currency := "euro"
+	mockProducts.On("UpdateProduct", actorID, "intel-basic", application.UpdateProductRequest{Currency: &currency}).
+		Return(nil, domain.ErrInvalidCurrency).Once()
+
+	c, w := newAdminContext("PATCH", "/api/v1/admin/products/intel-basic", []byte(`{"currency":"euro"}`), actorID, "intel-basic")
+	handler.UpdateProduct(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDeleteProduct(t *testing.T) {
+	handler, mockProducts := setupProductHandler()
+	actorID := uuid.New()
+
+	t.Run("deletes product", func(t *testing.T) {
+		mockProducts.On("DeleteProduct", actorID, "intel-basic").Return(nil).Once()
+
+		c, _ := newAdminContext("DELETE", "/api/v1/admin/products/intel-basic", nil, actorID, "intel-basic")
+		handler.DeleteProduct(c)
+
+		assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockProducts.On("DeleteProduct", actorID, "missing").Return(application.ErrProductNotFound).Once()
+
+		c, w := newAdminContext("DELETE", "/api/v1/admin/products/missing", nil, actorID, "missing")
+		handler.DeleteProduct(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
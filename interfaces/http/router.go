@@ -9,8 +9,9 @@ import (
 )
 
 type Router struct {
-	handler    *Handler
-	middleware *Middleware
+	handler        *Handler
+	middleware     *Middleware
+	trustedProxies []string
 }
 
 func NewRouter(handler *Handler, middleware *Middleware) *Router {
@@ -20,15 +21,27 @@ func NewRouter(handler *Handler, middleware *Middleware) *Router {
 	}
 }
 
+// WithTrustedProxies sets the addresses or CIDR ranges of the reverse
+// proxies whose X-Forwarded-For and X-Forwarded-Proto headers are believed.
+// Without any, the client IP is the address of the peer, so that callers
+// cannot pick their own IP to get around per-IP rate limits and lockouts.
+func (r *Router) WithTrustedProxies(proxies []string) *Router {
+	r.trustedProxies = proxies
+	r.handler.WithTrustedProxies(proxies)
+	return r
+}
+
 func (r *Router) Setup(app *newrelic.Application) *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.New()
+	if err := router.SetTrustedProxies(r.trustedProxies); err != nil {
+		_ = router.SetTrustedProxies(nil)
+	}
 
 	// Global middleware
 	router.Use(r.middleware.NewRelic(app))
 	router.Use(r.middleware.CORS())
 	router.Use(r.middleware.Logger())
-	router.Use(r.middleware.RateLimit())
 	router.Use(gin.Recovery())
 
 	// Swagger documentation
@@ -37,38 +50,148 @@ func (r *Router) Setup(app *newrelic.Application) *gin.Engine {
 	// Health check
 	router.GET("/health", r.handler.Health)
 
+	// Token verification keys
+	router.GET("/.well-known/jwks.json", r.middleware.RateLimit(RateLimitPublic), r.handler.JWKS)
+
 	// Auth routes
 	auth := router.Group("/auth")
+	auth.Use(r.middleware.RateLimit(RateLimitAuth))
 	{
 		auth.POST("/login", r.handler.Login)
 		auth.POST("/register", r.handler.Register)
+		auth.POST("/verify", r.handler.VerifyEmail)
+		auth.POST("/verify/resend", r.handler.ResendVerification)
+		auth.POST("/password/forgot", r.handler.ForgotPassword)
+		auth.POST("/password/reset", r.handler.ResetPassword)
 		auth.POST("/refresh", r.handler.RefreshToken)
+		auth.POST("/invitations/accept", r.handler.AcceptInvitation)
+		auth.POST("/mfa/verify", r.handler.VerifyMFA)
+		auth.POST("/mfa/enroll", r.handler.BeginMFAEnrollment)
+		auth.POST("/mfa/enroll/confirm", r.handler.CompleteMFAEnrollment)
+		auth.GET("/oidc/login", r.handler.SSOLogin)
+		auth.GET("/oidc/callback", r.handler.SSOCallback)
+
+		session := auth.Group("")
+		session.Use(r.middleware.Auth(), r.middleware.RequireSession())
+		{
+			session.POST("/logout", r.handler.Logout)
+			session.POST("/logout-all", r.handler.LogoutAll)
+		}
+	}
+
+	// Public catalog
+	router.GET("/api/v1/catalog", r.middleware.RateLimit(RateLimitPublic), r.handler.GetCatalog)
+
+	// TAXII 2.1 routes
+	taxii := router.Group("/taxii2")
+	taxii.Use(r.middleware.Auth(), r.middleware.RequireScope(domain.ScopeIntelRead), r.middleware.RateLimit(RateLimitTAXII))
+	{
+		taxii.GET("/", r.handler.TaxiiDiscovery)
+		taxii.GET("/api/", r.handler.TaxiiAPIRoot)
+		taxii.GET("/api/collections/", r.handler.ListTaxiiCollections)
+		taxii.GET("/api/collections/:id/", r.handler.GetTaxiiCollection)
+		taxii.GET("/api/collections/:id/objects/", r.handler.GetTaxiiObjects)
 	}
 
 	// Protected routes
 	api := router.Group("/api/v1")
-	api.Use(r.middleware.Auth())
+	api.Use(r.middleware.Auth(), r.middleware.RateLimit(RateLimitAPI))
 	{
 		// Order routes
 		orders := api.Group("/orders")
+		orders.Use(r.middleware.RequireSession())
 		{
 			orders.POST("", r.handler.CreateOrder)
 			orders.GET("", r.handler.GetUserOrders)
 			orders.GET("/:id", r.handler.GetOrder)
+			orders.POST("/:id/cancel", r.handler.CancelOrder)
+
+			fulfilment := orders.Group("")
+			fulfilment.Use(r.middleware.RequireRole(domain.RoleAdmin))
+			{
+				fulfilment.POST("/:id/complete", r.handler.CompleteOrder)
+			}
+		}
+
+		// Current user routes
+		me := api.Group("/me")
+		me.Use(r.middleware.RequireSession())
+		{
+			me.GET("", r.handler.GetProfile)
+			me.PATCH("", r.handler.UpdateProfile)
+			me.POST("/password", r.handler.ChangePassword)
+			me.GET("/entitlements", r.handler.GetMyEntitlements)
+			me.POST("/entitlements/:id/seats", r.handler.AssignEntitlementSeat)
+			me.DELETE("/entitlements/:id/seats/:user_id", r.handler.RemoveEntitlementSeat)
+			me.GET("/mfa", r.handler.GetMFAStatus)
+			me.POST("/mfa/enroll", r.handler.EnrollMFA)
+			me.POST("/mfa/enroll/confirm", r.handler.ConfirmMFAEnrollment)
+			me.POST("/mfa/recovery-codes", r.handler.RegenerateRecoveryCodes)
+			me.POST("/mfa/disable", r.handler.DisableMFA)
+			me.GET("/api-keys", r.handler.ListAPIKeys)
+			me.POST("/api-keys", r.handler.CreateAPIKey)
+			me.DELETE("/api-keys/:id", r.handler.RevokeAPIKey)
+		}
+
+		// Indicator routes
+		indicators := api.Group("/indicators")
+		{
+			reads := indicators.Group("")
+			reads.Use(r.middleware.RequireScope(domain.ScopeIntelRead))
+			{
+				reads.GET("", r.handler.ListIndicators)
+				reads.GET("/stix", r.handler.ExportStix)
+				reads.GET("/:id", r.handler.GetIndicator)
+				reads.GET("/:id/sightings", r.handler.ListIndicatorSightings)
+			}
+
+			writes := indicators.Group("")
+			writes.Use(r.middleware.RequireRole(domain.RoleAnalyst), r.middleware.RequireScope(domain.ScopeIntelWrite))
+			{
+				writes.POST("", r.handler.CreateIndicator)
+				writes.POST("/stix", r.handler.ImportStix)
+				writes.PATCH("/:id", r.handler.UpdateIndicator)
+				writes.DELETE("/:id", r.handler.DeleteIndicator)
+				writes.PUT("/:id/vote", r.handler.VoteIndicator)
+				writes.DELETE("/:id/vote", r.handler.ClearIndicatorVote)
+			}
 		}
 
+		// Bulk lookup for SIEM enrichment
+		api.POST("/lookup", r.middleware.RequireScope(domain.ScopeIntelRead), r.handler.Lookup)
+
 		// Admin routes
 		admin := api.Group("/admin")
-		admin.Use(r.middleware.RequireRole(domain.RoleAdmin))
+		admin.Use(r.middleware.RequireSession(), r.middleware.RequireRole(domain.RoleAdmin))
 		{
-			admin.GET("/users", func(c *gin.Context) {
-				c.JSON(200, gin.H{"message": "Admin endpoint - list users"})
-			})
+			admin.GET("/users", r.handler.ListUsers)
+			admin.GET("/users/:id", r.handler.GetUser)
+			admin.PUT("/users/:id/role", r.handler.UpdateUserRole)
+			admin.POST("/users/:id/deactivate", r.handler.DeactivateUser)
+			admin.POST("/users/:id/reactivate", r.handler.ReactivateUser)
+			admin.POST("/users/:id/unlock", r.handler.UnlockUser)
+			admin.DELETE("/users/:id/mfa", r.handler.ResetUserMFA)
+			admin.DELETE("/users/:id", r.handler.DeleteUser)
+			admin.POST("/invitations", r.handler.CreateInvitation)
+			admin.GET("/security-events", r.handler.ListSecurityEvents)
+			admin.GET("/mfa-policies", r.handler.ListMFAPolicies)
+			admin.PUT("/mfa-policies/:role", r.handler.SetMFAPolicy)
+			admin.GET("/products", r.handler.ListProducts)
+			admin.POST("/products", r.handler.CreateProduct)
+			admin.GET("/products/:id", r.handler.GetProduct)
+			admin.PATCH("/products/:id", r.handler.UpdateProduct)
+			admin.DELETE("/products/:id", r.handler.DeleteProduct)
+			admin.GET("/feeds", r.handler.ListFeeds)
+			admin.POST("/feeds", r.handler.CreateFeed)
+			admin.GET("/feeds/:id", r.handler.GetFeed)
+			admin.POST("/feeds/:id/pause", r.handler.PauseFeed)
+			admin.POST("/feeds/:id/resume", r.handler.ResumeFeed)
+			admin.POST("/feeds/:id/trigger", r.handler.TriggerFeed)
 		}
 
 		// Analyst routes
 		analyst := api.Group("/analyst")
-		analyst.Use(r.middleware.RequireRole(domain.RoleAnalyst))
+		analyst.Use(r.middleware.RequireSession(), r.middleware.RequireRole(domain.RoleAnalyst))
 		{
 			analyst.GET("/reports", func(c *gin.Context) {
 				c.JSON(200, gin.H{"message": "Analyst endpoint - view reports"})
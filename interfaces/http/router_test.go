@@ -4,20 +4,44 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"threat-intel-backend/application"
+	"threat-intel-backend/domain"
+	"threat-intel-backend/infrastructure/jwt"
+	"time"
 
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func setupRouter() *Router {
 	mockAuth := &MockAuthService{}
 	mockOrder := &MockOrderService{}
 	mockJWT := &MockJWTService{}
+	mockDenylist := &MockAccessTokenDenylist{}
 	logger := logrus.New()
 	logger.SetLevel(logrus.FatalLevel)
 
-	handler := NewHandler(mockAuth, mockOrder, logger)
-	middleware := NewMiddleware(mockJWT, logger)
+	handler := NewHandler(mockAuth, mockOrder, logger).
+		WithUserService(&MockUserService{}).
+		WithInvitationService(&MockInvitationService{}).
+		WithIndicatorService(&MockIndicatorService{}).
+		WithStixService(&MockStixService{}).
+		WithTaxiiService(&MockTaxiiService{}).
+		WithEntitlementService(&MockEntitlementService{}).
+		WithProductService(&MockProductService{}).
+		WithSecurityEventService(&MockSecurityEventService{}).
+		WithMFAService(&MockMFAService{}).
+		WithAPIKeyService(&MockAPIKeyService{}).
+		WithSSOService(&MockSSOService{}).
+		WithAccountService(&MockAccountService{}).
+		WithFeedService(&MockFeedService{}).
+		WithScoringService(&MockScoringService{}).
+		WithLookupService(&MockLookupService{}).
+		WithJWKS(jwt.NewService("test-secret"))
+	middleware := NewMiddleware(mockJWT, mockDenylist, logger)
 
 	return NewRouter(handler, middleware)
 }
@@ -37,6 +61,36 @@ func TestRouterSetup(t *testing.T) {
 	assert.NotNil(t, engine)
 }
 
+func TestRouterTrustedProxies(t *testing.T) {
+	clientIP := func(engine *gin.Engine, remoteAddr string) string {
+		engine.GET("/client-ip", func(c *gin.Context) { c.String(http.StatusOK, c.ClientIP()) })
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/client-ip", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", "203.0.113.9")
+		engine.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	t.Run("ignores forwarded headers by default", func(t *testing.T) {
+		engine := setupRouter().Setup(nil)
+
+		assert.Equal(t, "192.0.2.1", clientIP(engine, "192.0.2.1:4321"))
+	})
+
+	t.Run("believes configured proxies only", func(t *testing.T) {
+		engine := setupRouter().WithTrustedProxies([]string{"10.0.0.0/8"}).Setup(nil)
+
+		assert.Equal(t, "203.0.113.9", clientIP(engine, "10.1.2.3:4321"))
+	})
+
+	t.Run("ignores forwarded headers from other peers", func(t *testing.T) {
+		engine := setupRouter().WithTrustedProxies([]string{"10.0.0.0/8"}).Setup(nil)
+
+		assert.Equal(t, "192.0.2.1", clientIP(engine, "192.0.2.1:4321"))
+	})
+}
+
 func TestHealthRoute(t *testing.T) {
 	router := setupRouter()
 	engine := router.Setup(nil)
@@ -49,6 +103,36 @@ func TestHealthRoute(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 }
 
+func TestJWKSRoute(t *testing.T) {
+	router := setupRouter()
+	engine := router.Setup(nil)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/.well-known/jwks.json", nil)
+
+	engine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestCatalogRouteIsPublic(t *testing.T) {
+	mockProducts := &MockProductService{}
+	mockProducts.On("ListCatalog").Return([]*domain.Product{}, nil)
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	handler := NewHandler(&MockAuthService{}, &MockOrderService{}, logger).
+		WithProductService(mockProducts)
+	engine := NewRouter(handler, NewMiddleware(&MockJWTService{}, &MockAccessTokenDenylist{}, logger)).Setup(nil)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/v1/catalog", nil)
+
+	engine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
 func TestSwaggerRoute(t *testing.T) {
 	router := setupRouter()
 	engine := router.Setup(nil)
@@ -71,7 +155,16 @@ func TestAuthRoutes(t *testing.T) {
 	}{
 		{"POST", "/auth/login"},
 		{"POST", "/auth/register"},
+		{"POST", "/auth/verify"},
+		{"POST", "/auth/verify/resend"},
+		{"POST", "/auth/password/forgot"},
+		{"POST", "/auth/password/reset"},
 		{"POST", "/auth/refresh"},
+		{"POST", "/auth/invitations/accept"},
+		{"POST", "/auth/mfa/verify"},
+		{"POST", "/auth/mfa/enroll"},
+		{"POST", "/auth/mfa/enroll/confirm"},
+		{"GET", "/auth/oidc/callback"},
 	}
 
 	for _, route := range routes {
@@ -95,6 +188,36 @@ func TestProtectedRoutes(t *testing.T) {
 		{"POST", "/api/v1/orders"},
 		{"GET", "/api/v1/orders"},
 		{"GET", "/api/v1/orders/123"},
+		{"POST", "/api/v1/orders/123/cancel"},
+		{"POST", "/api/v1/orders/123/complete"},
+		{"GET", "/api/v1/me"},
+		{"PATCH", "/api/v1/me"},
+		{"POST", "/api/v1/me/password"},
+		{"GET", "/api/v1/me/entitlements"},
+		{"GET", "/api/v1/me/mfa"},
+		{"POST", "/api/v1/me/mfa/enroll"},
+		{"POST", "/api/v1/me/mfa/enroll/confirm"},
+		{"POST", "/api/v1/me/mfa/recovery-codes"},
+		{"POST", "/api/v1/me/mfa/disable"},
+		{"GET", "/api/v1/me/api-keys"},
+		{"POST", "/api/v1/me/api-keys"},
+		{"DELETE", "/api/v1/me/api-keys/123"},
+		{"GET", "/api/v1/indicators"},
+		{"POST", "/api/v1/indicators"},
+		{"GET", "/api/v1/indicators/123"},
+		{"GET", "/api/v1/indicators/123/sightings"},
+		{"PATCH", "/api/v1/indicators/123"},
+		{"DELETE", "/api/v1/indicators/123"},
+		{"PUT", "/api/v1/indicators/123/vote"},
+		{"DELETE", "/api/v1/indicators/123/vote"},
+		{"GET", "/api/v1/indicators/stix"},
+		{"POST", "/api/v1/indicators/stix"},
+		{"POST", "/api/v1/lookup"},
+		{"GET", "/taxii2/"},
+		{"GET", "/taxii2/api/"},
+		{"GET", "/taxii2/api/collections/"},
+		{"GET", "/taxii2/api/collections/123/"},
+		{"GET", "/taxii2/api/collections/123/objects/"},
 	}
 
 	for _, route := range routes {
@@ -107,16 +230,61 @@ func TestProtectedRoutes(t *testing.T) {
 	}
 }
 
+func TestSessionRoutes(t *testing.T) {
+	router := setupRouter()
+	engine := router.Setup(nil)
+
+	for _, path := range []string{"/auth/logout", "/auth/logout-all"} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", path, nil)
+
+		engine.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	}
+}
+
 func TestAdminRoutes(t *testing.T) {
 	router := setupRouter()
 	engine := router.Setup(nil)
 
-	w := httptest.NewRecorder()
-	req := httptest.NewRequest("GET", "/api/v1/admin/users", nil)
+	routes := []struct {
+		method string
+		path   string
+	}{
+		{"GET", "/api/v1/admin/users"},
+		{"GET", "/api/v1/admin/users/123"},
+		{"PUT", "/api/v1/admin/users/123/role"},
+		{"POST", "/api/v1/admin/users/123/deactivate"},
+		{"POST", "/api/v1/admin/users/123/reactivate"},
+		{"DELETE", "/api/v1/admin/users/123"},
+		{"POST", "/api/v1/admin/invitations"},
+		{"POST", "/api/v1/admin/users/123/unlock"},
+		{"GET", "/api/v1/admin/security-events"},
+		{"DELETE", "/api/v1/admin/users/123/mfa"},
+		{"GET", "/api/v1/admin/mfa-policies"},
+		{"PUT", "/api/v1/admin/mfa-policies/admin"},
+		{"GET", "/api/v1/admin/products"},
+		{"POST", "/api/v1/admin/products"},
+		{"GET", "/api/v1/admin/products/intel-basic"},
+		{"PATCH", "/api/v1/admin/products/intel-basic"},
+		{"DELETE", "/api/v1/admin/products/intel-basic"},
+		{"GET", "/api/v1/admin/feeds"},
+		{"POST", "/api/v1/admin/feeds"},
+		{"GET", "/api/v1/admin/feeds/00000000-0000-0000-0000-000000000001"},
+		{"POST", "/api/v1/admin/feeds/00000000-0000-0000-0000-000000000001/pause"},
+		{"POST", "/api/v1/admin/feeds/00000000-0000-0000-0000-000000000001/resume"},
+		{"POST", "/api/v1/admin/feeds/00000000-0000-0000-0000-000000000001/trigger"},
+	}
 
-	engine.ServeHTTP(w, req)
+	for _, route := range routes {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(route.method, route.path, nil)
 
-	assert.Equal(t, http.StatusUnauthorized, w.Code)
+		engine.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	}
 }
 
 func TestAnalystRoutes(t *testing.T) {
@@ -130,3 +298,137 @@ func TestAnalystRoutes(t *testing.T) {
 
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 }
+
+func TestIndicatorRoutePermissions(t *testing.T) {
+	mockJWT := &MockJWTService{}
+	mockDenylist := &MockAccessTokenDenylist{}
+	mockDenylist.On("IsRevoked", mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+	mockIndicators := &MockIndicatorService{}
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	handler := NewHandler(&MockAuthService{}, &MockOrderService{}, logger).
+		WithIndicatorService(mockIndicators)
+	engine := NewRouter(handler, NewMiddleware(mockJWT, mockDenylist, logger)).Setup(nil)
+
+	mockJWT.On("ValidateAccessToken", "viewer").Return(&jwt.Claims{UserID: uuid.New(), Role: domain.RoleViewer}, nil)
+	mockJWT.On("ValidateAccessToken", "analyst").Return(&jwt.Claims{UserID: uuid.New(), Role: domain.RoleAnalyst}, nil)
+	mockIndicators.On("ListIndicators", mock.Anything, mock.Anything).Return(&application.IndicatorListResponse{}, nil)
+
+	serve := func(method, token string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/api/v1/indicators", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		engine.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("GET", "viewer"))
+	assert.Equal(t, http.StatusForbidden, serve("POST", "viewer"))
+	assert.Equal(t, http.StatusBadRequest, serve("POST", "analyst"))
+}
+
+func TestAPIKeyRoutePermissions(t *testing.T) {
+	mockAPIKeys := &MockAPIKeyAuthenticator{}
+	mockIndicators := &MockIndicatorService{}
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	handler := NewHandler(&MockAuthService{}, &MockOrderService{}, logger).
+		WithIndicatorService(mockIndicators)
+	middleware := NewMiddleware(&MockJWTService{}, &MockAccessTokenDenylist{}, logger).WithAPIKeys(mockAPIKeys)
+	engine := NewRouter(handler, middleware).Setup(nil)
+
+	analyst := &domain.User{ID: uuid.New(), Role: domain.RoleAnalyst}
+	readKey := domain.NewAPIKey(analyst.ID, "SIEM", "read", "hash", []domain.APIKeyScope{domain.ScopeIntelRead}, nil)
+	mockAPIKeys.On("Authenticate", "read-key").Return(analyst, readKey, nil)
+	mockIndicators.On("ListIndicators", analyst.ID, mock.Anything).Return(&application.IndicatorListResponse{}, nil)
+
+	serve := func(method, path string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("X-API-Key", "read-key")
+		engine.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("GET", "/api/v1/indicators"))
+	assert.Equal(t, http.StatusForbidden, serve("POST", "/api/v1/indicators"))
+	assert.Equal(t, http.StatusForbidden, serve("POST", "/api/v1/me/api-keys"))
+	assert.Equal(t, http.StatusForbidden, serve("GET", "/api/v1/orders"))
+	assert.Equal(t, http.StatusForbidden, serve("POST", "/auth/logout"))
+}
+
+func TestOrderRoutePermissions(t *testing.T) {
+	mockJWT := &MockJWTService{}
+	mockDenylist := &MockAccessTokenDenylist{}
+	mockDenylist.On("IsRevoked", mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+	mockOrder := &MockOrderService{}
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	handler := NewHandler(&MockAuthService{}, mockOrder, logger)
+	engine := NewRouter(handler, NewMiddleware(mockJWT, mockDenylist, logger)).Setup(nil)
+
+	orderID := uuid.New()
+	mockJWT.On("ValidateAccessToken", "viewer").Return(&jwt.Claims{UserID: uuid.New(), Role: domain.RoleViewer}, nil)
+	mockJWT.On("ValidateAccessToken", "admin").Return(&jwt.Claims{UserID: uuid.New(), Role: domain.RoleAdmin}, nil)
+	mockOrder.On("CancelOrder", orderID, mock.Anything).Return(&domain.Order{ID: orderID}, nil)
+	mockOrder.On("CompleteOrder", mock.Anything, orderID).Return(&domain.Order{ID: orderID}, nil)
+
+	serve := func(action, token string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/v1/orders/"+orderID.String()+"/"+action, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		engine.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("cancel", "viewer"))
+	assert.Equal(t, http.StatusForbidden, serve("complete", "viewer"))
+	assert.Equal(t, http.StatusOK, serve("complete", "admin"))
+}
+
+func TestRateLimitedRoutes(t *testing.T) {
+	mockJWT := &MockJWTService{}
+	mockDenylist := &MockAccessTokenDenylist{}
+	mockDenylist.On("IsRevoked", mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+	mockOrder := &MockOrderService{}
+	mockLimiter := &MockRateLimiter{}
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	login := domain.RateLimit{Limit: 10, Period: time.Minute}
+	viewer := domain.RateLimit{Limit: 60, Period: time.Minute}
+	middleware := NewMiddleware(mockJWT, mockDenylist, logger).WithRateLimiter(mockLimiter, map[string]RateLimitPolicy{
+		RateLimitAuth: {Anonymous: login},
+		RateLimitAPI:  {Roles: map[domain.UserRole]domain.RateLimit{domain.RoleViewer: viewer}},
+	})
+	engine := NewRouter(NewHandler(&MockAuthService{}, mockOrder, logger), middleware).Setup(nil)
+
+	userID := uuid.New()
+	mockJWT.On("ValidateAccessToken", "viewer").Return(&jwt.Claims{UserID: userID, Role: domain.RoleViewer}, nil)
+	mockOrder.On("GetUserOrders", userID).Return([]*domain.Order{}, nil)
+	mockLimiter.On("Allow", "api:user:"+userID.String(), viewer).Return(&domain.RateLimitResult{Allowed: true, Limit: 60, Remaining: 59}, nil).Once()
+	mockLimiter.On("Allow", "auth:ip:192.0.2.1", login).Return(&domain.RateLimitResult{Limit: 10, RetryAfter: time.Second}, nil).Once()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/v1/orders", nil)
+	req.Header.Set("Authorization", "Bearer viewer")
+	engine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "59", w.Header().Get("RateLimit-Remaining"))
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest("POST", "/auth/login", nil))
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockLimiter.AssertExpectations(t)
+}
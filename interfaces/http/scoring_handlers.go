@@ -0,0 +1,93 @@
+package http
+
+import (
+	"net/http"
+	"threat-intel-backend/application"
+	"threat-intel-backend/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type ScoringServiceInterface interface {
+	VoteIndicator(actorID, id uuid.UUID, value int) (*domain.Indicator, error)
+	ClearVote(actorID, id uuid.UUID) (*domain.Indicator, error)
+}
+
+func (h *Handler) WithScoringService(scoringService ScoringServiceInterface) *Handler {
+	h.scoringService = scoringService
+	return h
+}
+
+// @Summary Vote on indicator
+// @Description Vote 1 when an indicator is malicious or -1 when it is a false positive, replacing your earlier vote; the indicator is rescored right away and expires when its score drops below the threshold (analyst+)
+// @Tags indicators
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Indicator ID"
+// @Param request body application.VoteRequest true "Vote"
+// @Success 200 {object} domain.Indicator
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/indicators/{id}/vote [put]
+func (h *Handler) VoteIndicator(c *gin.Context) {
+	actorID, id, ok := h.indicatorTarget(c)
+	if !ok {
+		return
+	}
+
+	var req application.VoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	indicator, err := h.scoringService.VoteIndicator(actorID, id, req.Value)
+	if err != nil {
+		c.JSON(indicatorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"actor_id":     actorID,
+		"indicator_id": id,
+		"vote":         req.Value,
+		"score":        indicator.Score,
+	}).Info("Indicator voted")
+
+	c.JSON(http.StatusOK, indicator)
+}
+
+// @Summary Withdraw indicator vote
+// @Description Withdraw your vote on an indicator, which is rescored right away (analyst+)
+// @Tags indicators
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Indicator ID"
+// @Success 200 {object} domain.Indicator
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/indicators/{id}/vote [delete]
+func (h *Handler) ClearIndicatorVote(c *gin.Context) {
+	actorID, id, ok := h.indicatorTarget(c)
+	if !ok {
+		return
+	}
+
+	indicator, err := h.scoringService.ClearVote(actorID, id)
+	if err != nil {
+		c.JSON(indicatorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"actor_id":     actorID,
+		"indicator_id": id,
+		"score":        indicator.Score,
+	}).Info("Indicator vote withdrawn")
+
+	c.JSON(http.StatusOK, indicator)
+}
@@ -0,0 +1,91 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"threat-intel-backend/application"
+	"threat-intel-backend/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockScoringService struct {
+	mock.Mock
+}
+
+func (m *MockScoringService) VoteIndicator(actorID, id uuid.UUID, value int) (*domain.Indicator, error) {
+	args := m.Called(actorID, id, value)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Indicator), args.Error(1)
+}
+
+func (m *MockScoringService) ClearVote(actorID, id uuid.UUID) (*domain.Indicator, error) {
+	args := m.Called(actorID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Indicator), args.Error(1)
+}
+
+func setupScoringHandler() (*Handler, *MockScoringService) {
+	handler, _, _ := setupHandler()
+	mockScoring := &MockScoringService{}
+	return handler.WithScoringService(mockScoring), mockScoring
+}
+
+func TestVoteIndicator(t *testing.T) {
+	handler, mockScoring := setupScoringHandler()
+	actorID := uuid.New()
+	indicator := &domain.Indicator{ID: uuid.New(), Type: domain.IndicatorDomain, Value: "evil.example.com", Score: 39}
+
+	t.Run("records vote", func(t *testing.T) {
+		mockScoring.On("VoteIndicator", actorID, indicator.ID, -1).Return(indicator, nil).Once()
+
+		c, w := newAdminContext("PUT", "/", []byte(`{"value":-1}`), actorID, indicator.ID.String())
+		handler.VoteIndicator(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var voted domain.Indicator
+		_ = json.Unmarshal(w.Body.Bytes(), &voted)
+		assert.Equal(t, 39, voted.Score)
+	})
+
+	t.Run("rejects other values", func(t *testing.T) {
+		for _, body := range []string{`{"value":0}`, `{"value":2}`, `{}`} {
+			c, w := newAdminContext("PUT", "/", []byte(body), actorID, indicator.ID.String())
+			handler.VoteIndicator(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		id := uuid.New()
+		mockScoring.On("VoteIndicator", actorID, id, 1).Return(nil, application.ErrIndicatorNotFound).Once()
+
+		c, w := newAdminContext("PUT", "/", []byte(`{"value":1}`), actorID, id.String())
+		handler.VoteIndicator(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	mockScoring.AssertExpectations(t)
+}
+
+func TestClearIndicatorVote(t *testing.T) {
+	handler, mockScoring := setupScoringHandler()
+	actorID := uuid.New()
+	indicator := &domain.Indicator{ID: uuid.New(), Type: domain.IndicatorDomain, Value: "evil.example.com", Score: 24}
+	mockScoring.On("ClearVote", actorID, indicator.ID).Return(indicator, nil).Once()
+
+	c, w := newAdminContext("DELETE", "/", nil, actorID, indicator.ID.String())
+	handler.ClearIndicatorVote(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockScoring.AssertExpectations(t)
+}
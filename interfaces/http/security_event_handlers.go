@@ -0,0 +1,58 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"threat-intel-backend/application"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SecurityEventServiceInterface interface {
+	ListSecurityEvents(actorID uuid.UUID, req application.ListSecurityEventsRequest) (*application.SecurityEventListResponse, error)
+}
+
+func (h *Handler) WithSecurityEventService(securityService SecurityEventServiceInterface) *Handler {
+	h.securityService = securityService
+	return h
+}
+
+// @Summary List security events
+// @Description List lockouts and other security events, newest first (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param type query string false "Filter by event type"
+// @Param user_id query string false "Filter by user ID"
+// @Param email query string false "Filter by email"
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} application.SecurityEventListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/admin/security-events [get]
+func (h *Handler) ListSecurityEvents(c *gin.Context) {
+	actorID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var req application.ListSecurityEventsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.securityService.ListSecurityEvents(actorID.(uuid.UUID), req)
+	if err != nil {
+		status := userErrorStatus(err)
+		if errors.Is(err, application.ErrInvalidSecurityEventType) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"threat-intel-backend/application"
+	"threat-intel-backend/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSecurityEventService struct {
+	mock.Mock
+}
+
+func (m *MockSecurityEventService) ListSecurityEvents(actorID uuid.UUID, req application.ListSecurityEventsRequest) (*application.SecurityEventListResponse, error) {
+	args := m.Called(actorID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*application.SecurityEventListResponse), args.Error(1)
+}
+
+func TestListSecurityEvents(t *testing.T) {
+	handler, _, _ := setupHandler()
+	mockEvents := &MockSecurityEventService{}
+	handler.WithSecurityEventService(mockEvents)
+	actorID := uuid.New()
+
+	t.Run("lists events", func(t *testing.T) {
+		event := domain.NewSecurityEvent(domain.SecurityEventAccountLocked, "a@example.com", "192.0.2.1", "")
+		mockEvents.On("ListSecurityEvents", actorID, application.ListSecurityEventsRequest{Type: domain.SecurityEventAccountLocked, Page: 2}).
+			Return(&application.SecurityEventListResponse{Events: []*domain.SecurityEvent{event}, Total: 1, Page: 2, PageSize: 20}, nil).Once()
+
+		c, w := newAdminContext("GET", "/api/v1/admin/security-events?type=account_locked&page=2", nil, actorID, "")
+		handler.ListSecurityEvents(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp application.SecurityEventListResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Equal(t, int64(1), resp.Total)
+		assert.Equal(t, "192.0.2.1", resp.Events[0].IPAddress)
+	})
+
+	t.Run("rejects malformed user ID", func(t *testing.T) {
+		c, w := newAdminContext("GET", "/api/v1/admin/security-events?user_id=nope", nil, actorID, "")
+		handler.ListSecurityEvents(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("maps invalid type", func(t *testing.T) {
+		mockEvents.On("ListSecurityEvents", actorID, application.ListSecurityEventsRequest{Type: "bogus"}).
+			Return(nil, application.ErrInvalidSecurityEventType).Once()
+
+		c, w := newAdminContext("GET", "/api/v1/admin/security-events?type=bogus", nil, actorID, "")
+		handler.ListSecurityEvents(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
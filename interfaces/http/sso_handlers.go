@@ -0,0 +1,104 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"threat-intel-backend/application"
+	"github.com/gin-gonic/gin"
+)
+
+type SSOServiceInterface interface {
+	BeginLogin() (string, error)
+	CompleteLogin(ctx context.Context, req application.SSOCallbackRequest) (*application.LoginResponse, error)
+}
+
+// WithSSOService enables the OIDC login endpoints. Without it they answer
+// 404.
+func (h *Handler) WithSSOService(ssoService SSOServiceInterface) *Handler {
+	h.ssoService = ssoService
+	return h
+}
+
+// @Summary Start SSO login
+// @Description Redirect to the OpenID Connect provider to log in with the authorization code flow and PKCE
+// @Tags auth
+// @Success 302
+// @Failure 404 {object} map[string]string
+// @Router /auth/oidc/login [get]
+func (h *Handler) SSOLogin(c *gin.Context) {
+	if h.ssoService == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": application.ErrSSODisabled.Error()})
+		return
+	}
+
+	authURL, err := h.ssoService.BeginLogin()
+	if err != nil {
+		h.logger.WithError(err).Error("SSO login failed to start")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// @Summary SSO callback
+// @Description Complete an SSO login with the authorization code the provider redirected back with, and return JWT tokens, or an MFA challenge if a second factor is required
+// @Tags auth
+// @Produce json
+// @Param code query string true "Authorization code"
+// @Param state query string true "State issued by the login endpoint"
+// @Success 200 {object} application.LoginResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /auth/oidc/callback [get]
+func (h *Handler) SSOCallback(c *gin.Context) {
+	if h.ssoService == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": application.ErrSSODisabled.Error()})
+		return
+	}
+
+	if providerError := c.Query("error"); providerError != "" {
+		h.logger.WithField("error", providerError).Warn("SSO login refused by provider")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": application.ErrSSOFailed.Error() + ": " + providerError})
+		return
+	}
+
+	var req application.SSOCallbackRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.ssoService.CompleteLogin(c.Request.Context(), req)
+	if err != nil {
+		h.logger.WithError(err).Error("SSO login failed")
+		c.JSON(ssoErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	if response.MFAChallenge != nil {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	h.logger.WithField("user_id", response.User.ID).Info("User logged in with SSO")
+	c.JSON(http.StatusOK, response)
+}
+
+func ssoErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, application.ErrInvalidSSOState):
+		return http.StatusBadRequest
+	case errors.Is(err, application.ErrSSOFailed), errors.Is(err, application.ErrSSOEmailNotVerified):
+		return http.StatusUnauthorized
+	case errors.Is(err, application.ErrSSONoRole), errors.Is(err, application.ErrAccountInactive):
+		return http.StatusForbidden
+	default:
+		return userErrorStatus(err)
+	}
+}
@@ -0,0 +1,129 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"threat-intel-backend/application"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSSOService struct {
+	mock.Mock
+}
+
+func (m *MockSSOService) BeginLogin() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockSSOService) CompleteLogin(ctx context.Context, req application.SSOCallbackRequest) (*application.LoginResponse, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*application.LoginResponse), args.Error(1)
+}
+
+func setupSSOHandler() (*Handler, *MockSSOService) {
+	handler, _, _ := setupHandler()
+	mockSSO := &MockSSOService{}
+	handler.WithSSOService(mockSSO)
+	return handler, mockSSO
+}
+
+func newSSORequest(target string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", target, nil)
+	return c, w
+}
+
+func TestSSOLogin(t *testing.T) {
+	t.Run("redirects to the provider", func(t *testing.T) {
+		handler, mockSSO := setupSSOHandler()
+		mockSSO.On("BeginLogin").Return("https://idp.example.com/authorize?state=abc", nil).Once()
+
+		c, w := newSSORequest("/auth/oidc/login")
+		handler.SSOLogin(c)
+
+		assert.Equal(t, http.StatusFound, w.Code)
+		assert.Equal(t, "https://idp.example.com/authorize?state=abc", w.Header().Get("Location"))
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		handler, _, _ := setupHandler()
+
+		c, w := newSSORequest("/auth/oidc/login")
+		handler.SSOLogin(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestSSOCallback(t *testing.T) {
+	handler, mockSSO := setupSSOHandler()
+	req := application.SSOCallbackRequest{Code: "code-1", State: "state-1"}
+
+	t.Run("returns tokens", func(t *testing.T) {
+		user := &application.UserResponse{ID: uuid.New()}
+		mockSSO.On("CompleteLogin", req).Return(&application.LoginResponse{
+			AuthResponse: &application.AuthResponse{AccessToken: "token", User: user},
+		}, nil).Once()
+
+		c, w := newSSORequest("/auth/oidc/callback?code=code-1&state=state-1")
+		handler.SSOCallback(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "access_token")
+	})
+
+	t.Run("returns MFA challenge", func(t *testing.T) {
+		mockSSO.On("CompleteLogin", req).Return(&application.LoginResponse{
+			MFAChallenge: &application.MFAChallenge{MFARequired: true, MFAToken: "mfa-token"},
+		}, nil).Once()
+
+		c, w := newSSORequest("/auth/oidc/callback?code=code-1&state=state-1")
+		handler.SSOCallback(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "mfa_token")
+		assert.NotContains(t, w.Body.String(), "access_token")
+	})
+
+	t.Run("maps errors", func(t *testing.T) {
+		cases := map[error]int{
+			application.ErrInvalidSSOState: http.StatusBadRequest,
+			application.ErrSSOFailed:       http.StatusUnauthorized,
+			application.ErrSSONoRole:       http.StatusForbidden,
+		}
+		for err, status := range cases {
+			mockSSO.On("CompleteLogin", req).Return(nil, err).Once()
+
+			c, w := newSSORequest("/auth/oidc/callback?code=code-1&state=state-1")
+			handler.SSOCallback(c)
+
+			assert.Equal(t, status, w.Code, err.Error())
+		}
+	})
+
+	t.Run("provider error", func(t *testing.T) {
+		c, w := newSSORequest("/auth/oidc/callback?error=access_denied&state=state-1")
+		handler.SSOCallback(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "access_denied")
+	})
+
+	t.Run("requires code and state", func(t *testing.T) {
+		c, w := newSSORequest("/auth/oidc/callback?code=code-1")
+		handler.SSOCallback(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
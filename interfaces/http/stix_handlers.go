@@ -0,0 +1,110 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"threat-intel-backend/application"
+	"threat-intel-backend/infrastructure/stix"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// maxBundleSize bounds the request body of a STIX import.
+const maxBundleSize = 10 << 20
+
+type StixServiceInterface interface {
+	ImportBundle(actorID uuid.UUID, bundle *stix.Bundle) (*application.StixImportResult, error)
+	ExportBundle(userID uuid.UUID, req application.ExportStixRequest) (*stix.Bundle, error)
+}
+
+func (h *Handler) WithStixService(stixService StixServiceInterface) *Handler {
+	h.stixService = stixService
+	return h
+}
+
+// @Summary Import STIX bundle
+// @Description Upsert the indicators, malware, threat actors and relationships of a STIX 2.1 bundle (analyst+)
+// @Tags indicators
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body stix.Bundle true "STIX 2.1 bundle"
+// @Success 200 {object} application.StixImportResult
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/indicators/stix [post]
+func (h *Handler) ImportStix(c *gin.Context) {
+	actorID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	bundle, err := stix.Decode(http.MaxBytesReader(c.Writer, c.Request.Body, maxBundleSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.stixService.ImportBundle(actorID.(uuid.UUID), bundle)
+	if err != nil {
+		h.logger.WithError(err).Error("STIX import failed")
+		c.JSON(stixErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"actor_id":  actorID,
+		"bundle_id": bundle.ID,
+		"created":   result.Created,
+		"updated":   result.Updated,
+		"skipped":   result.Skipped,
+	}).Info("STIX bundle imported")
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Export STIX bundle
+// @Description Export the matching indicators covered by the caller's entitlement with their relationships as a STIX 2.1 bundle (viewer+)
+// @Tags indicators
+// @Produce json
+// @Security BearerAuth
+// @Param type query string false "Filter by indicator type"
+// @Param severity query string false "Filter by severity"
+// @Param tag query string false "Filter by tag"
+// @Param source query string false "Filter by source"
+// @Param include_expired query bool false "Include expired indicators"
+// @Success 200 {object} stix.Bundle
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/indicators/stix [get]
+func (h *Handler) ExportStix(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var req application.ExportStixRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bundle, err := h.stixService.ExportBundle(userID.(uuid.UUID), req)
+	if err != nil {
+		c.JSON(stixErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", stix.MediaType)
+	c.JSON(http.StatusOK, bundle)
+}
+
+func stixErrorStatus(err error) int {
+	if errors.Is(err, stix.ErrInvalidBundle) {
+		return http.StatusBadRequest
+	}
+	return indicatorErrorStatus(err)
+}
@@ -0,0 +1,112 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"threat-intel-backend/application"
+	"threat-intel-backend/domain"
+	"threat-intel-backend/infrastructure/stix"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockStixService struct {
+	mock.Mock
+}
+
+func (m *MockStixService) ImportBundle(actorID uuid.UUID, bundle *stix.Bundle) (*application.StixImportResult, error) {
+	args := m.Called(actorID, bundle)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*application.StixImportResult), args.Error(1)
+}
+
+func (m *MockStixService) ExportBundle(userID uuid.UUID, req application.ExportStixRequest) (*stix.Bundle, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*stix.Bundle), args.Error(1)
+}
+
+func setupStixHandler() (*Handler, *MockStixService) {
+	handler, _, _ := setupHandler()
+	mockStix := &MockStixService{}
+	return handler.WithStixService(mockStix), mockStix
+}
+
+func TestImportStix(t *testing.T) {
+	handler, mockStix := setupStixHandler()
+	actorID := uuid.New()
+
+	t.Run("imports bundle", func(t *testing.T) {
+		bundle := stix.NewBundle(nil)
+		mockStix.On("ImportBundle", actorID, mock.MatchedBy(func(b *stix.Bundle) bool {
+			return b.ID == bundle.ID
+		})).Return(&application.StixImportResult{Created: 1}, nil).Once()
+
+		body, _ := json.Marshal(bundle)
+		c, w := newAdminContext("POST", "/api/v1/indicators/stix", body, actorID, "")
+		handler.ImportStix(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"created":1`)
+		mockStix.AssertExpectations(t)
+	})
+
+	t.Run("rejects invalid bundle", func(t *testing.T) {
+		c, w := newAdminContext("POST", "/api/v1/indicators/stix", []byte(`{"type":"indicator"}`), actorID, "")
+		handler.ImportStix(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("maps permission error", func(t *testing.T) {
+		mockStix.On("ImportBundle", actorID, mock.Anything).Return(nil, application.ErrInsufficientPermissions).Once()
+
+		body, _ := json.Marshal(stix.NewBundle(nil))
+		c, w := newAdminContext("POST", "/api/v1/indicators/stix", body, actorID, "")
+		handler.ImportStix(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestExportStix(t *testing.T) {
+	handler, mockStix := setupStixHandler()
+	actorID := uuid.New()
+
+	t.Run("exports bundle", func(t *testing.T) {
+		bundle := stix.NewBundle(nil)
+		mockStix.On("ExportBundle", actorID, application.ExportStixRequest{Type: domain.IndicatorURL}).Return(bundle, nil).Once()
+
+		c, w := newAdminContext("GET", "/api/v1/indicators/stix?type=url", nil, actorID, "")
+		handler.ExportStix(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, stix.MediaType, w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), bundle.ID)
+	})
+
+	t.Run("maps invalid filter", func(t *testing.T) {
+		mockStix.On("ExportBundle", actorID, application.ExportStixRequest{Type: "mutex"}).Return(nil, domain.ErrInvalidIndicatorType).Once()
+
+		c, w := newAdminContext("GET", "/api/v1/indicators/stix?type=mutex", nil, actorID, "")
+		handler.ExportStix(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("maps missing entitlement", func(t *testing.T) {
+		mockStix.On("ExportBundle", actorID, application.ExportStixRequest{}).Return(nil, application.ErrEntitlementRequired).Once()
+
+		c, w := newAdminContext("GET", "/api/v1/indicators/stix", nil, actorID, "")
+		handler.ExportStix(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
@@ -0,0 +1,242 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"threat-intel-backend/application"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	taxiiMediaType = "application/taxii+json;version=2.1"
+	taxiiAPIRoot   = "/taxii2/api/"
+)
+
+type TaxiiServiceInterface interface {
+	ListCollections(userID uuid.UUID) ([]application.TaxiiCollection, error)
+	GetCollection(userID uuid.UUID, collectionID string) (*application.TaxiiCollection, error)
+	GetObjects(userID uuid.UUID, collectionID string, req application.TaxiiObjectsRequest) (*application.TaxiiEnvelope, error)
+}
+
+func (h *Handler) WithTaxiiService(taxiiService TaxiiServiceInterface) *Handler {
+	h.taxiiService = taxiiService
+	return h
+}
+
+// TaxiiError is the error message resource of TAXII 2.1.
+type TaxiiError struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	HTTPStatus  string `json:"http_status"`
+}
+
+// @Summary TAXII discovery
+// @Description List the TAXII 2.1 API roots of this server (viewer+)
+// @Tags taxii
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 406 {object} TaxiiError
+// @Router /taxii2/ [get]
+func (h *Handler) TaxiiDiscovery(c *gin.Context) {
+	if !acceptsTaxii(c) {
+		return
+	}
+
+	apiRoot := h.requestScheme(c) + "://" + c.Request.Host + taxiiAPIRoot
+	taxiiJSON(c, http.StatusOK, gin.H{
+		"title":       "Zentara Threat Intelligence TAXII Server",
+		"description": "Indicators from the Zentara threat intelligence store",
+		"default":     apiRoot,
+		"api_roots":   []string{apiRoot},
+	})
+}
+
+// @Summary TAXII API root
+// @Description Describe the TAXII 2.1 API root (viewer+)
+// @Tags taxii
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 406 {object} TaxiiError
+// @Router /taxii2/api/ [get]
+func (h *Handler) TaxiiAPIRoot(c *gin.Context) {
+	if !acceptsTaxii(c) {
+		return
+	}
+
+	taxiiJSON(c, http.StatusOK, gin.H{
+		"title":              "Indicators",
+		"versions":           []string{taxiiMediaType},
+		"max_content_length": maxBundleSize,
+	})
+}
+
+// @Summary List TAXII collections
+// @Description List the collections readable with the caller's role and purchased tier (viewer+)
+// @Tags taxii
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 406 {object} TaxiiError
+// @Router /taxii2/api/collections/ [get]
+func (h *Handler) ListTaxiiCollections(c *gin.Context) {
+	if !acceptsTaxii(c) {
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		taxiiError(c, http.StatusUnauthorized, errors.New("user ID not found"))
+		return
+	}
+
+	collections, err := h.taxiiService.ListCollections(userID.(uuid.UUID))
+	if err != nil {
+		taxiiError(c, taxiiErrorStatus(err), err)
+		return
+	}
+
+	taxiiJSON(c, http.StatusOK, gin.H{"collections": collections})
+}
+
+// @Summary Get TAXII collection
+// @Description Get a collection readable with the caller's role and purchased tier (viewer+)
+// @Tags taxii
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Collection ID"
+// @Success 200 {object} application.TaxiiCollection
+// @Failure 404 {object} TaxiiError
+// @Failure 406 {object} TaxiiError
+// @Router /taxii2/api/collections/{id}/ [get]
+func (h *Handler) GetTaxiiCollection(c *gin.Context) {
+	if !acceptsTaxii(c) {
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		taxiiError(c, http.StatusUnauthorized, errors.New("user ID not found"))
+		return
+	}
+
+	collection, err := h.taxiiService.GetCollection(userID.(uuid.UUID), c.Param("id"))
+	if err != nil {
+		taxiiError(c, taxiiErrorStatus(err), err)
+		return
+	}
+
+	taxiiJSON(c, http.StatusOK, collection)
+}
+
+// @Summary Get TAXII objects
+// @Description Page through the indicators of a collection as STIX 2.1 objects in the order they were added (viewer+)
+// @Tags taxii
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Collection ID"
+// @Param added_after query string false "Only objects added after this RFC 3339 timestamp"
+// @Param limit query int false "Page size (max 1000)"
+// @Param next query string false "Cursor returned by the previous page"
+// @Success 200 {object} application.TaxiiEnvelope
+// @Failure 400 {object} TaxiiError
+// @Failure 404 {object} TaxiiError
+// @Failure 406 {object} TaxiiError
+// @Router /taxii2/api/collections/{id}/objects/ [get]
+func (h *Handler) GetTaxiiObjects(c *gin.Context) {
+	if !acceptsTaxii(c) {
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		taxiiError(c, http.StatusUnauthorized, errors.New("user ID not found"))
+		return
+	}
+
+	var req application.TaxiiObjectsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		taxiiError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	envelope, err := h.taxiiService.GetObjects(userID.(uuid.UUID), c.Param("id"), req)
+	if err != nil {
+		taxiiError(c, taxiiErrorStatus(err), err)
+		return
+	}
+
+	if len(envelope.Objects) > 0 {
+		c.Header("X-TAXII-Date-Added-First", envelope.DateAddedFirst.UTC().Format(time.RFC3339Nano))
+		c.Header("X-TAXII-Date-Added-Last", envelope.DateAddedLast.UTC().Format(time.RFC3339Nano))
+	}
+	taxiiJSON(c, http.StatusOK, envelope)
+}
+
+// acceptsTaxii answers 406 unless the Accept header allows TAXII 2.1.
+func acceptsTaxii(c *gin.Context) bool {
+	accept := c.GetHeader("Accept")
+	if accept == "" {
+		return true
+	}
+
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(strings.ReplaceAll(mediaRange, " ", ""), ";")
+		switch strings.ToLower(mediaType) {
+		case "*/*", "application/*":
+			return true
+		case "application/taxii+json":
+			if params == "" || strings.Contains(strings.ToLower(params), "version=2.1") {
+				return true
+			}
+		}
+	}
+
+	taxiiError(c, http.StatusNotAcceptable, errors.New("only "+taxiiMediaType+" is supported"))
+	return false
+}
+
+func taxiiJSON(c *gin.Context, status int, body interface{}) {
+	c.Header("Content-Type", taxiiMediaType)
+	c.JSON(status, body)
+}
+
+func taxiiError(c *gin.Context, status int, err error) {
+	taxiiJSON(c, status, TaxiiError{
+		Title:       http.StatusText(status),
+		Description: err.Error(),
+		HTTPStatus:  strconv.Itoa(status),
+	})
+}
+
+func taxiiErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, application.ErrCollectionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, application.ErrInvalidTaxiiQuery):
+		return http.StatusBadRequest
+	}
+	return indicatorErrorStatus(err)
+}
+
+// requestScheme is the scheme the client used. Behind a trusted proxy that
+// terminates TLS, that is the proxy's X-Forwarded-Proto; the header is
+// ignored from anyone else, so that clients cannot choose the scheme of the
+// URLs they are given.
+func (h *Handler) requestScheme(c *gin.Context) string {
+	if h.fromTrustedProxy(c) {
+		switch proto := c.GetHeader("X-Forwarded-Proto"); proto {
+		case "http", "https":
+			return proto
+		}
+	}
+	if c.Request.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
@@ -0,0 +1,156 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"threat-intel-backend/application"
+	"threat-intel-backend/infrastructure/stix"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTaxiiService struct {
+	mock.Mock
+}
+
+func (m *MockTaxiiService) ListCollections(userID uuid.UUID) ([]application.TaxiiCollection, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]application.TaxiiCollection), args.Error(1)
+}
+
+func (m *MockTaxiiService) GetCollection(userID uuid.UUID, collectionID string) (*application.TaxiiCollection, error) {
+	args := m.Called(userID, collectionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*application.TaxiiCollection), args.Error(1)
+}
+
+func (m *MockTaxiiService) GetObjects(userID uuid.UUID, collectionID string, req application.TaxiiObjectsRequest) (*application.TaxiiEnvelope, error) {
+	args := m.Called(userID, collectionID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*application.TaxiiEnvelope), args.Error(1)
+}
+
+func setupTaxiiHandler() (*Handler, *MockTaxiiService) {
+	handler, _, _ := setupHandler()
+	mockTaxii := &MockTaxiiService{}
+	return handler.WithTaxiiService(mockTaxii), mockTaxii
+}
+
+func TestTaxiiDiscovery(t *testing.T) {
+	handler, _ := setupTaxiiHandler()
+
+	t.Run("lists api root", func(t *testing.T) {
+		c, w := newAdminContext("GET", "/taxii2/", nil, uuid.New(), "")
+		c.Request.Host = "intel.example.com"
+		c.Request.Header.Set("Accept", taxiiMediaType)
+		handler.TaxiiDiscovery(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, taxiiMediaType, w.Header().Get("Content-Type"))
+		var response map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, "http://intel.example.com/taxii2/api/", response["default"])
+	})
+
+	t.Run("believes the forwarded scheme of trusted proxies only", func(t *testing.T) {
+		handler.WithTrustedProxies([]string{"10.0.0.0/8"})
+		defer handler.WithTrustedProxies(nil)
+		cases := map[string]string{
+			"10.1.2.3:40000":    "https://intel.example.com/taxii2/api/",
+			"198.51.100.9:4000": "http://intel.example.com/taxii2/api/",
+		}
+		for remoteAddr, apiRoot := range cases {
+			c, w := newAdminContext("GET", "/taxii2/", nil, uuid.New(), "")
+			c.Request.Host = "intel.example.com"
+			c.Request.RemoteAddr = remoteAddr
+			c.Request.Header.Set("Accept", taxiiMediaType)
+			c.Request.Header.Set("X-Forwarded-Proto", "https")
+			handler.TaxiiDiscovery(c)
+
+			var response map[string]interface{}
+			_ = json.Unmarshal(w.Body.Bytes(), &response)
+			assert.Equal(t, apiRoot, response["default"], remoteAddr)
+		}
+	})
+
+	t.Run("rejects unacceptable media type", func(t *testing.T) {
+		c, w := newAdminContext("GET", "/taxii2/", nil, uuid.New(), "")
+		c.Request.Header.Set("Accept", "application/taxii+json;version=2.0")
+		handler.TaxiiDiscovery(c)
+
+		assert.Equal(t, http.StatusNotAcceptable, w.Code)
+		assert.Contains(t, w.Body.String(), `"http_status":"406"`)
+	})
+}
+
+func TestListTaxiiCollections(t *testing.T) {
+	handler, mockTaxii := setupTaxiiHandler()
+	userID := uuid.New()
+
+	mockTaxii.On("ListCollections", userID).Return([]application.TaxiiCollection{{ID: "c1", Title: "Network Indicators", CanRead: true}}, nil).Once()
+
+	c, w := newAdminContext("GET", "/taxii2/api/collections/", nil, userID, "")
+	handler.ListTaxiiCollections(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"collections":[{"id":"c1"`)
+	mockTaxii.AssertExpectations(t)
+}
+
+func TestGetTaxiiCollection(t *testing.T) {
+	handler, mockTaxii := setupTaxiiHandler()
+	userID := uuid.New()
+
+	mockTaxii.On("GetCollection", userID, "c2").Return(nil, application.ErrCollectionNotFound).Once()
+
+	c, w := newAdminContext("GET", "/taxii2/api/collections/c2/", nil, userID, "c2")
+	handler.GetTaxiiCollection(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), `"title":"Not Found"`)
+}
+
+func TestGetTaxiiObjects(t *testing.T) {
+	handler, mockTaxii := setupTaxiiHandler()
+	userID := uuid.New()
+
+	t.Run("returns envelope with date headers", func(t *testing.T) {
+		added := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		req := application.TaxiiObjectsRequest{AddedAfter: "2023-12-31T00:00:00Z", Limit: 1}
+		mockTaxii.On("GetObjects", userID, "c1", req).Return(&application.TaxiiEnvelope{
+			More:           true,
+			Next:           "abc",
+			Objects:        []stix.Object{{Type: stix.TypeIndicator, ID: "indicator--1"}},
+			DateAddedFirst: added,
+			DateAddedLast:  added,
+		}, nil).Once()
+
+		c, w := newAdminContext("GET", "/taxii2/api/collections/c1/objects/?added_after=2023-12-31T00:00:00Z&limit=1", nil, userID, "c1")
+		handler.GetTaxiiObjects(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "2024-01-01T00:00:00Z", w.Header().Get("X-TAXII-Date-Added-First"))
+		assert.Contains(t, w.Body.String(), `"more":true,"next":"abc"`)
+		mockTaxii.AssertExpectations(t)
+	})
+
+	t.Run("maps invalid query", func(t *testing.T) {
+		mockTaxii.On("GetObjects", userID, "c1", mock.Anything).Return(nil, application.ErrInvalidTaxiiQuery).Once()
+
+		c, w := newAdminContext("GET", "/taxii2/api/collections/c1/objects/?next=bad", nil, userID, "c1")
+		handler.GetTaxiiObjects(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
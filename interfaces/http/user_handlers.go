@@ -0,0 +1,271 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"threat-intel-backend/application"
+	"threat-intel-backend/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type UserServiceInterface interface {
+	ListUsers(actorID uuid.UUID, req application.ListUsersRequest) (*application.UserListResponse, error)
+	GetUser(actorID, userID uuid.UUID) (*application.UserResponse, error)
+	UpdateRole(actorID, userID uuid.UUID, req application.UpdateRoleRequest) (*application.UserResponse, error)
+	SetActive(actorID, userID uuid.UUID, active bool) (*application.UserResponse, error)
+	DeleteUser(actorID, userID uuid.UUID) error
+	UnlockUser(actorID, userID uuid.UUID) (*application.UserResponse, error)
+}
+
+func (h *Handler) WithUserService(userService UserServiceInterface) *Handler {
+	h.userService = userService
+	return h
+}
+
+// @Summary List users
+// @Description List users with optional role, active and email prefix filters (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param role query string false "Filter by role"
+// @Param is_active query bool false "Filter by active flag"
+// @Param email_prefix query string false "Filter by email prefix"
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} application.UserListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/admin/users [get]
+func (h *Handler) ListUsers(c *gin.Context) {
+	actorID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var req application.ListUsersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.userService.ListUsers(actorID.(uuid.UUID), req)
+	if err != nil {
+		c.JSON(userErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Get user
+// @Description Get a user by ID (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} application.UserResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/admin/users/{id} [get]
+func (h *Handler) GetUser(c *gin.Context) {
+	actorID, userID, ok := h.adminTarget(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.GetUser(actorID, userID)
+	if err != nil {
+		c.JSON(userErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// @Summary Update user role
+// @Description Change the role of a user (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param request body application.UpdateRoleRequest true "New role"
+// @Success 200 {object} application.UserResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/admin/users/{id}/role [put]
+func (h *Handler) UpdateUserRole(c *gin.Context) {
+	actorID, userID, ok := h.adminTarget(c)
+	if !ok {
+		return
+	}
+
+	var req application.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.userService.UpdateRole(actorID, userID, req)
+	if err != nil {
+		h.logger.WithError(err).Error("Role update failed")
+		c.JSON(userErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"actor_id": actorID,
+		"user_id":  userID,
+		"role":     user.Role,
+	}).Info("User role updated")
+
+	c.JSON(http.StatusOK, user)
+}
+
+// @Summary Deactivate user
+// @Description Deactivate a user and revoke their sessions (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} application.UserResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/admin/users/{id}/deactivate [post]
+func (h *Handler) DeactivateUser(c *gin.Context) {
+	h.setUserActive(c, false)
+}
+
+// @Summary Reactivate user
+// @Description Reactivate a deactivated user (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} application.UserResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/admin/users/{id}/reactivate [post]
+func (h *Handler) ReactivateUser(c *gin.Context) {
+	h.setUserActive(c, true)
+}
+
+// @Summary Unlock user
+// @Description Clear the failed login attempts and lockout of a user (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} application.UserResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/admin/users/{id}/unlock [post]
+func (h *Handler) UnlockUser(c *gin.Context) {
+	actorID, userID, ok := h.adminTarget(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.UnlockUser(actorID, userID)
+	if err != nil {
+		h.logger.WithError(err).Error("User unlock failed")
+		c.JSON(userErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"actor_id": actorID,
+		"user_id":  userID,
+	}).Info("User unlocked")
+
+	c.JSON(http.StatusOK, user)
+}
+
+// @Summary Delete user
+// @Description Delete a user (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/admin/users/{id} [delete]
+func (h *Handler) DeleteUser(c *gin.Context) {
+	actorID, userID, ok := h.adminTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := h.userService.DeleteUser(actorID, userID); err != nil {
+		h.logger.WithError(err).Error("User deletion failed")
+		c.JSON(userErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"actor_id": actorID,
+		"user_id":  userID,
+	}).Info("User deleted")
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) setUserActive(c *gin.Context, active bool) {
+	actorID, userID, ok := h.adminTarget(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.SetActive(actorID, userID, active)
+	if err != nil {
+		h.logger.WithError(err).Error("User activation change failed")
+		c.JSON(userErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"actor_id":  actorID,
+		"user_id":   userID,
+		"is_active": active,
+	}).Info("User activation changed")
+
+	c.JSON(http.StatusOK, user)
+}
+
+// adminTarget extracts the acting user and the user ID path parameter,
+// writing an error response when either is missing or malformed.
+func (h *Handler) adminTarget(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	actorID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return actorID.(uuid.UUID), userID, true
+}
+
+func userErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, application.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, application.ErrInsufficientPermissions):
+		return http.StatusForbidden
+	case errors.Is(err, application.ErrInvalidRole), errors.Is(err, application.ErrSelfModification):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrUserHasOrders):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,275 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"threat-intel-backend/application"
+	"threat-intel-backend/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUserService struct {
+	mock.Mock
+}
+
+func (m *MockUserService) ListUsers(actorID uuid.UUID, req application.ListUsersRequest) (*application.UserListResponse, error) {
+	args := m.Called(actorID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*application.UserListResponse), args.Error(1)
+}
+
+func (m *MockUserService) GetUser(actorID, userID uuid.UUID) (*application.UserResponse, error) {
+	args := m.Called(actorID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*application.UserResponse), args.Error(1)
+}
+
+func (m *MockUserService) UpdateRole(actorID, userID uuid.UUID, req application.UpdateRoleRequest) (*application.UserResponse, error) {
+	args := m.Called(actorID, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*application.UserResponse), args.Error(1)
+}
+
+func (m *MockUserService) SetActive(actorID, userID uuid.UUID, active bool) (*application.UserResponse, error) {
+	args := m.Called(actorID, userID, active)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*application.UserResponse), args.Error(1)
+}
+
+func (m *MockUserService) DeleteUser(actorID, userID uuid.UUID) error {
+	args := m.Called(actorID, userID)
+	return args.Error(0)
+}
+
+func (m *MockUserService) UnlockUser(actorID, userID uuid.UUID) (*application.UserResponse, error) {
+	args := m.Called(actorID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*application.UserResponse), args.Error(1)
+}
+
+func setupUserHandler() (*Handler, *MockUserService) {
+	handler, _, _ := setupHandler()
+	mockUsers := &MockUserService{}
+	return handler.WithUserService(mockUsers), mockUsers
+}
+
+func newAdminContext(method, path string, body []byte, actorID uuid.UUID, targetID string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, path, bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: targetID}}
+	c.Set("user_id", actorID)
+	return c, w
+}
+
+func TestListUsers(t *testing.T) {
+	handler, mockUsers := setupUserHandler()
+	actorID := uuid.New()
+
+	t.Run("binds query filters", func(t *testing.T) {
+		active := false
+		req := application.ListUsersRequest{Role: domain.RoleViewer, IsActive: &active, EmailPrefix: "bob", Page: 2, PageSize: 5}
+		response := &application.UserListResponse{Users: []*application.UserResponse{}, Page: 2, PageSize: 5}
+		mockUsers.On("ListUsers", actorID, req).Return(response, nil).Once()
+
+		c, w := newAdminContext("GET", "/api/v1/admin/users?role=viewer&is_active=false&email_prefix=bob&page=2&page_size=5", nil, actorID, "")
+		handler.ListUsers(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockUsers.AssertExpectations(t)
+	})
+
+	t.Run("rejects oversized page", func(t *testing.T) {
+		c, w := newAdminContext("GET", "/api/v1/admin/users?page_size=1000", nil, actorID, "")
+		handler.ListUsers(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("maps permission error", func(t *testing.T) {
+		mockUsers.On("ListUsers", actorID, application.ListUsersRequest{}).Return(nil, application.ErrInsufficientPermissions).Once()
+
+		c, w := newAdminContext("GET", "/api/v1/admin/users", nil, actorID, "")
+		handler.ListUsers(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestGetUser(t *testing.T) {
+	handler, mockUsers := setupUserHandler()
+	actorID := uuid.New()
+
+	t.Run("returns user", func(t *testing.T) {
+		user := &application.UserResponse{ID: uuid.New(), Email: "user@example.com"}
+		mockUsers.On("GetUser", actorID, user.ID).Return(user, nil).Once()
+
+		c, w := newAdminContext("GET", "/", nil, actorID, user.ID.String())
+		handler.GetUser(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("invalid user ID", func(t *testing.T) {
+		c, w := newAdminContext("GET", "/", nil, actorID, "not-a-uuid")
+		handler.GetUser(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		userID := uuid.New()
+		mockUsers.On("GetUser", actorID, userID).Return(nil, application.ErrUserNotFound).Once()
+
+		c, w := newAdminContext("GET", "/", nil, actorID, userID.String())
+		handler.GetUser(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestUpdateUserRole(t *testing.T) {
+	handler, mockUsers := setupUserHandler()
+	actorID := uuid.New()
+	userID := uuid.New()
+
+	t.Run("updates role", func(t *testing.T) {
+		req := application.UpdateRoleRequest{Role: domain.RoleAnalyst}
+		mockUsers.On("UpdateRole", actorID, userID, req).Return(&application.UserResponse{ID: userID, Role: domain.RoleAnalyst}, nil).Once()
+
+		body, _ := json.Marshal(req)
+		c, w := newAdminContext("PUT", "/", body, actorID, userID.String())
+		handler.UpdateUserRole(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockUsers.AssertExpectations(t)
+	})
+
+	t.Run("invalid role", func(t *testing.T) {
+		req := application.UpdateRoleRequest{Role: "root"}
+		mockUsers.On("UpdateRole", actorID, userID, req).Return(nil, application.ErrInvalidRole).Once()
+
+		body, _ := json.Marshal(req)
+		c, w := newAdminContext("PUT", "/", body, actorID, userID.String())
+		handler.UpdateUserRole(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("missing role", func(t *testing.T) {
+		c, w := newAdminContext("PUT", "/", []byte(`{}`), actorID, userID.String())
+		handler.UpdateUserRole(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestSetUserActive(t *testing.T) {
+	handler, mockUsers := setupUserHandler()
+	actorID := uuid.New()
+	userID := uuid.New()
+
+	t.Run("deactivate", func(t *testing.T) {
+		mockUsers.On("SetActive", actorID, userID, false).Return(&application.UserResponse{ID: userID}, nil).Once()
+
+		c, w := newAdminContext("POST", "/", nil, actorID, userID.String())
+		handler.DeactivateUser(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("reactivate", func(t *testing.T) {
+		mockUsers.On("SetActive", actorID, userID, true).Return(&application.UserResponse{ID: userID, IsActive: true}, nil).Once()
+
+		c, w := newAdminContext("POST", "/", nil, actorID, userID.String())
+		handler.ReactivateUser(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("self modification", func(t *testing.T) {
+		mockUsers.On("SetActive", actorID, actorID, false).Return(nil, application.ErrSelfModification).Once()
+
+		c, w := newAdminContext("POST", "/", nil, actorID, actorID.String())
+		handler.DeactivateUser(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestUnlockUser(t *testing.T) {
+	handler, mockUsers := setupUserHandler()
+	actorID := uuid.New()
+	userID := uuid.New()
+
+	t.Run("unlocks user", func(t *testing.T) {
+		mockUsers.On("UnlockUser", actorID, userID).Return(&application.UserResponse{ID: userID}, nil).Once()
+
+		c, w := newAdminContext("POST", "/", nil, actorID, userID.String())
+		handler.UnlockUser(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockUsers.On("UnlockUser", actorID, userID).Return(nil, application.ErrUserNotFound).Once()
+
+		c, w := newAdminContext("POST", "/", nil, actorID, userID.String())
+		handler.UnlockUser(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestDeleteUser(t *testing.T) {
+	handler, mockUsers := setupUserHandler()
+	actorID := uuid.New()
+	userID := uuid.New()
+
+	t.Run("deletes user", func(t *testing.T) {
+		mockUsers.On("DeleteUser", actorID, userID).Return(nil).Once()
+
+		c, _ := newAdminContext("DELETE", "/", nil, actorID, userID.String())
+		handler.DeleteUser(c)
+
+		assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+	})
+
+	t.Run("user with orders", func(t *testing.T) {
+		mockUsers.On("DeleteUser", actorID, userID).Return(domain.ErrUserHasOrders).Once()
+
+		c, w := newAdminContext("DELETE", "/", nil, actorID, userID.String())
+		handler.DeleteUser(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "deactivate the account instead")
+	})
+
+	t.Run("repository failure", func(t *testing.T) {
+		mockUsers.On("DeleteUser", actorID, userID).Return(errors.New("connection refused")).Once()
+
+		c, w := newAdminContext("DELETE", "/", nil, actorID, userID.String())
+		handler.DeleteUser(c)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}